@@ -36,8 +36,37 @@ type ManagedClusterModuleSpec struct {
 	Selector map[string]string `json:"selector"`
 }
 
+// ClusterModuleStatus is the status of the Module on a managed cluster, as reported by its work agent.
+type ClusterModuleStatus struct {
+	// ClusterName is the name of the managed cluster.
+	ClusterName string `json:"clusterName"`
+	// ModuleLoader contains the status of the ModuleLoader daemonset on the managed cluster.
+	// +optional
+	ModuleLoader kmmv1beta1.DaemonSetStatus `json:"moduleLoader,omitempty"`
+	// Phase is the phase of the Module on the managed cluster.
+	// +optional
+	Phase kmmv1beta1.KernelPhase `json:"phase,omitempty"`
+	// ReadyKernels is the number of kernels in the Ready phase on the managed cluster.
+	// +optional
+	ReadyKernels int32 `json:"readyKernels"`
+	// TotalKernels is the number of kernels running on the targeted nodes of the managed cluster.
+	// +optional
+	TotalKernels int32 `json:"totalKernels"`
+}
+
 // ManagedClusterModuleStatus defines the observed state of ManagedClusterModule.
 type ManagedClusterModuleStatus struct {
+	// Clusters contains the status of the Module on each managed cluster it was deployed to.
+	// +optional
+	// +listType=map
+	// +listMapKey=clusterName
+	Clusters []ClusterModuleStatus `json:"clusters,omitempty"`
+	// ReadyClusters is the number of managed clusters on which the Module is in the Ready phase.
+	// +optional
+	ReadyClusters int32 `json:"readyClusters"`
+	// TotalClusters is the number of managed clusters the Module was deployed to.
+	// +optional
+	TotalClusters int32 `json:"totalClusters"`
 }
 
 //+kubebuilder:object:root=true
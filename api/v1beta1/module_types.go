@@ -79,9 +79,17 @@ type Sign struct {
 	// a secret containing the private key used to sign kernel modules for secureboot
 	KeySecret *v1.LocalObjectReference `json:"keySecret"`
 
+	// +optional
+	// KeySecretKey is the key of the private key in KeySecret; defaults to "key".
+	KeySecretKey string `json:"keySecretKey,omitempty"`
+
 	// a secret containing the public key used to sign kernel modules for secureboot
 	CertSecret *v1.LocalObjectReference `json:"certSecret"`
 
+	// +optional
+	// CertSecretKey is the key of the public key in CertSecret; defaults to "cert".
+	CertSecretKey string `json:"certSecretKey,omitempty"`
+
 	// +optional
 	// paths inside the image for the kernel modules to sign (if ommited all kmods are signed)
 	FilesToSign []string `json:"filesToSign,omitempty"`
@@ -106,6 +114,17 @@ type KernelMapping struct {
 	// Literal defines a literal target kernel version to be matched exactly against node kernels.
 	Literal string `json:"literal"`
 
+	// +optional
+	// NodeSelector restricts this mapping to the nodes that have all these labels, for instance labels set by Node
+	// Feature Discovery, so that the image is selected by hardware in addition to the kernel.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// +optional
+	// PageSize restricts this mapping to the kernels built with that page size, as indicated by the suffix of their
+	// version (e.g. +64k or -64k); kernels without such a suffix use 4k pages.
+	// If not set, the mapping matches kernels of all page sizes.
+	PageSize KernelPageSize `json:"pageSize,omitempty"`
+
 	// +optional
 	// RegistryTLS set the TLS configs for accessing the registry of the module-loader's image.
 	RegistryTLS *TLSOptions `json:"registryTLS"`
@@ -115,6 +134,17 @@ type KernelMapping struct {
 	Regexp string `json:"regexp"`
 }
 
+// KernelPageSize is the size of the memory pages of a kernel.
+// Modules built for a page size cannot be loaded by kernels of another page size.
+// +kubebuilder:validation:Enum=4k;16k;64k
+type KernelPageSize string
+
+const (
+	KernelPageSize4k  KernelPageSize = "4k"
+	KernelPageSize16k KernelPageSize = "16k"
+	KernelPageSize64k KernelPageSize = "64k"
+)
+
 type ModprobeArgs struct {
 	// Load is an optional list of arguments to be used when loading the kernel module.
 	// +kubebuilder:validation:MinItems=1
@@ -156,6 +186,24 @@ type ModprobeSpec struct {
 	FirmwarePath string `json:"firmwarePath,omitempty"`
 }
 
+// ProvenancePolicy describes the in-toto attestation that a pre-built image must carry.
+// The attestation is looked up in the image's repository under the tag used by cosign (sha256-<digest>.att).
+type ProvenancePolicy struct {
+	// PublicKeySecret references a Secret whose "key" entry holds the PEM-encoded public key that the attestation
+	// must be signed with.
+	PublicKeySecret v1.LocalObjectReference `json:"publicKeySecret"`
+
+	// +optional
+	// BuilderID is the builder identity that the SLSA provenance must carry.
+	BuilderID string `json:"builderID,omitempty"`
+
+	// +optional
+	// SourceRepository is the URI of the repository that one of the provenance's materials must come from, for
+	// example git+https://github.com/org/repo. A material matches if its URI is SourceRepository, optionally followed
+	// by a ref (@refs/heads/main), a path, a query or a fragment.
+	SourceRepository string `json:"sourceRepository,omitempty"`
+}
+
 type ModuleLoaderContainerSpec struct {
 	// Build contains build instructions.
 	// +optional
@@ -186,15 +234,38 @@ type ModuleLoaderContainerSpec struct {
 	// Modprobe is a set of properties to customize which module modprobe loads and with which properties.
 	Modprobe ModprobeSpec `json:"modprobe"`
 
+	// +optional
+	// Provenance is the policy that the SLSA provenance of pre-built module-loader images must satisfy before the
+	// module-loader is deployed. Images built or signed in-cluster are not verified.
+	Provenance *ProvenancePolicy `json:"provenance,omitempty"`
+
 	// +optional
 	// RegistryTLS set the TLS configs for accessing the registry of the module-loader's image.
 	RegistryTLS TLSOptions `json:"registryTLS"`
+
+	// +optional
+	// Resources are the compute resources required by the module-loader container.
+	// If neither requests nor limits are set, KMM requests 10m of CPU and 32Mi of memory, and limits the container to
+	// 500m of CPU and 256Mi of memory.
+	Resources v1.ResourceRequirements `json:"resources,omitempty"`
+
+	// +optional
+	// SELinuxOptions are the SELinux options applied to the module-loader container.
+	// If not set, the SELinux type configured in the operator is used (spc_t by default).
+	SELinuxOptions *v1.SELinuxOptions `json:"seLinuxOptions,omitempty"`
 }
 
 type ModuleLoaderSpec struct {
 	// Container holds the properties for the module loader container that runs modprobe.
 	Container ModuleLoaderContainerSpec `json:"container"`
 
+	// RebootRequired means that the module only takes full effect after a reboot, for instance because it replaces an
+	// in-tree module loaded from the initramfs.
+	// Module-loader pods create the /run/reboot-required sentinel file watched by reboot daemons such as kured the
+	// first time they run an image on a node.
+	// +optional
+	RebootRequired bool `json:"rebootRequired,omitempty"`
+
 	// +optional
 	// ServiceAccountName is the name of the ServiceAccount to use to run this pod.
 	// More info: https://kubernetes.io/docs/tasks/configure-pod-container/configure-service-account/
@@ -243,7 +314,8 @@ type DevicePluginContainerSpec struct {
 	ImagePullPolicy v1.PullPolicy `json:"imagePullPolicy,omitempty" protobuf:"bytes,14,opt,name=imagePullPolicy,casttype=PullPolicy"`
 
 	// Compute Resources required by this container.
-	// Cannot be updated.
+	// If neither requests nor limits are set, KMM requests 10m of CPU and 64Mi of memory, and limits the container to
+	// 500m of CPU and 512Mi of memory.
 	// More info: https://kubernetes.io/docs/concepts/configuration/manage-resources-containers/
 	// +optional
 	Resources v1.ResourceRequirements `json:"resources,omitempty" protobuf:"bytes,8,opt,name=resources"`
@@ -264,6 +336,95 @@ type DevicePluginSpec struct {
 	Volumes []v1.Volume `json:"volumes,omitempty"`
 }
 
+type CompanionContainerSpec struct {
+	// Entrypoint array. Not executed within a shell.
+	// The container image's ENTRYPOINT is used if this is not provided.
+	// +optional
+	Command []string `json:"command,omitempty"`
+
+	// Arguments to the entrypoint.
+	// The container image's CMD is used if this is not provided.
+	// +optional
+	Args []string `json:"args,omitempty"`
+
+	// List of environment variables to set in the container.
+	// +optional
+	// +patchMergeKey=name
+	// +patchStrategy=merge
+	Env []v1.EnvVar `json:"env,omitempty" patchStrategy:"merge" patchMergeKey:"name"`
+
+	// Image is the name of the container image that the companion container will run.
+	Image string `json:"image"`
+
+	// Image pull policy.
+	// One of Always, Never, IfNotPresent.
+	// Defaults to Always if :latest tag is specified, or IfNotPresent otherwise.
+	// +optional
+	ImagePullPolicy v1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// Compute Resources required by this container.
+	// +optional
+	Resources v1.ResourceRequirements `json:"resources,omitempty"`
+
+	// VolumeMounts is a list of volume mounts of the container.
+	// +optional
+	VolumeMounts []v1.VolumeMount `json:"volumeMounts,omitempty"`
+}
+
+// CompanionSpec describes a userspace component of the driver that runs once per cluster rather than on every node,
+// such as a licensing or telemetry service.
+// KMM runs it in a Deployment named after the Module and the companion, which is deleted along with the Module.
+type CompanionSpec struct {
+	// Name identifies the companion within the Module.
+	// +kubebuilder:validation:MaxLength=40
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	Container CompanionContainerSpec `json:"container"`
+
+	// Replicas is the number of pods of the companion.
+	// Defaults to 1.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// NodeSelector restricts the nodes on which the companion runs.
+	// Unlike the module-loader and the device plugin, it does not need to run on the nodes targeted by the Module.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// +optional
+	// ServiceAccountName is the name of the ServiceAccount to use to run the companion's pods.
+	// The namespace's default ServiceAccount is used if this is not provided.
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// +optional
+	Volumes []v1.Volume `json:"volumes,omitempty"`
+}
+
+// WorkloadIdentityProvider is a cloud provider offering workload identities.
+// +kubebuilder:validation:Enum=AWS;Azure;GCP
+type WorkloadIdentityProvider string
+
+const (
+	WorkloadIdentityProviderAWS   WorkloadIdentityProvider = "AWS"
+	WorkloadIdentityProviderAzure WorkloadIdentityProvider = "Azure"
+	WorkloadIdentityProviderGCP   WorkloadIdentityProvider = "GCP"
+)
+
+// WorkloadIdentity describes the cloud identity that the build and sign pods use to push images to ECR, ACR or
+// GCR / Artifact Registry.
+type WorkloadIdentity struct {
+	// Provider is the cloud provider of the identity: IAM Roles for Service Accounts on AWS, Azure Workload Identity
+	// or GKE Workload Identity.
+	Provider WorkloadIdentityProvider `json:"provider"`
+
+	// Identity is the ARN of an IAM role on AWS, the client ID of a managed identity on Azure or the e-mail address
+	// of a service account on GCP.
+	// +kubebuilder:validation:MinLength=1
+	Identity string `json:"identity"`
+}
+
 // ModuleSpec describes how the KMM operator should deploy a Module on those nodes that need it.
 type ModuleSpec struct {
 	// DevicePlugin allows overriding some properties of the container that deploys the device plugin on the node.
@@ -271,6 +432,12 @@ type ModuleSpec struct {
 	// +optional
 	DevicePlugin *DevicePluginSpec `json:"devicePlugin"`
 
+	// Companions are the userspace components of the driver that run once per cluster, in a Deployment each.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Companions []CompanionSpec `json:"companions,omitempty"`
+
 	// ModuleLoader allows overriding some properties of the container that loads the kernel module on the node.
 	// Name and image are ignored and are set automatically by the KMM Operator.
 	ModuleLoader ModuleLoaderSpec `json:"moduleLoader"`
@@ -280,6 +447,11 @@ type ModuleSpec struct {
 	// +optional
 	ImageRepoSecret *v1.LocalObjectReference `json:"imageRepoSecret,omitempty"`
 
+	// WorkloadIdentity allows authenticating to the registries of a cloud provider with a workload identity instead
+	// of ImageRepoSecret, which takes precedence if both are set.
+	// +optional
+	WorkloadIdentity *WorkloadIdentity `json:"workloadIdentity,omitempty"`
+
 	// Selector describes on which nodes the Module should be loaded and optionally built.
 	Selector map[string]string `json:"selector"`
 }
@@ -295,6 +467,76 @@ type DaemonSetStatus struct {
 	AvailableNumber int32 `json:"availableNumber"`
 }
 
+// KernelPhase is the progress of a Module for one kernel version.
+// +kubebuilder:validation:Enum=Pending;Building;Signing;Deploying;Ready;Failed
+type KernelPhase string
+
+const (
+	// KernelPhasePending means that the operator could not handle the kernel yet.
+	KernelPhasePending KernelPhase = "Pending"
+	// KernelPhaseBuilding means that the module-loader image is being built.
+	KernelPhaseBuilding KernelPhase = "Building"
+	// KernelPhaseSigning means that the kernel module is being signed.
+	KernelPhaseSigning KernelPhase = "Signing"
+	// KernelPhaseDeploying means that the module-loader pods are not all available yet.
+	KernelPhaseDeploying KernelPhase = "Deploying"
+	// KernelPhaseReady means that the module-loader pods are available on all nodes running the kernel.
+	KernelPhaseReady KernelPhase = "Ready"
+	// KernelPhaseFailed means that the build or the signing failed, or that the image could not be verified.
+	KernelPhaseFailed KernelPhase = "Failed"
+)
+
+// KernelStatus is the progress of a Module for one kernel version running on the targeted nodes.
+type KernelStatus struct {
+	// KernelVersion is the kernel version that the nodes report.
+	KernelVersion string `json:"kernelVersion"`
+	// Phase is the current step of the build, sign and deploy pipeline for the kernel.
+	Phase KernelPhase `json:"phase"`
+	// Message explains the phase, typically why it is Pending or Failed.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// LastTransitionTime is the last time the phase changed.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+	// Conditions are the state of each step of the pipeline for the kernel: Built, Signed and RolledOut.
+	// Their last transition time tells for how long a step has been in progress or failing.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+const (
+	// KernelConditionBuilt is true when the image was built for the kernel, or when the kernel mapping does not
+	// build it.
+	KernelConditionBuilt = "Built"
+
+	// KernelConditionSigned is true when the kernel modules of the image were signed, or when the kernel mapping
+	// does not sign them.
+	KernelConditionSigned = "Signed"
+
+	// KernelConditionRolledOut is true when the module-loader pods are up-to-date and available on all the nodes
+	// running the kernel.
+	KernelConditionRolledOut = "RolledOut"
+)
+
+// JobFailure records a failed build or sign Job, so that it can be investigated once the Job is deleted.
+type JobFailure struct {
+	// KernelVersion is the kernel version for which the Job ran.
+	KernelVersion string `json:"kernelVersion"`
+	// JobType is build or sign.
+	JobType string `json:"jobType"`
+	// JobName is the name of the Job, which may not exist anymore.
+	JobName string `json:"jobName"`
+	// Reason is the reason of the Failed condition of the Job, for example BackoffLimitExceeded.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// FailureTime is when the Job failed.
+	FailureTime metav1.Time `json:"failureTime"`
+	// Logs are the last lines of the logs of the Job.
+	// +optional
+	Logs string `json:"logs,omitempty"`
+}
+
 // ModuleStatus defines the observed state of Module.
 type ModuleStatus struct {
 	// DevicePlugin contains the status of the Device Plugin daemonset
@@ -302,11 +544,68 @@ type ModuleStatus struct {
 	DevicePlugin DaemonSetStatus `json:"devicePlugin,omitempty"`
 	// ModuleLoader contains the status of the ModuleLoader daemonset
 	ModuleLoader DaemonSetStatus `json:"moduleLoader"`
+	// Kernels contains the progress of the Module for each kernel version running on the targeted nodes.
+	// +optional
+	// +listType=map
+	// +listMapKey=kernelVersion
+	Kernels []KernelStatus `json:"kernels,omitempty"`
+	// JobFailures are the last failures of build and sign Jobs, most recent first, at most 3 per kernel version.
+	// They are kept once the Jobs are deleted.
+	// +optional
+	JobFailures []JobFailure `json:"jobFailures,omitempty"`
+	// NodesPendingReboot are the nodes that have not rebooted since the module-loader requested it, for Modules that
+	// require a reboot.
+	// +optional
+	NodesPendingReboot []string `json:"nodesPendingReboot,omitempty"`
+	// Phase is the least advanced phase of the kernels, Failed taking precedence over all others.
+	// +optional
+	Phase KernelPhase `json:"phase,omitempty"`
+	// ReadyKernels is the number of kernels in the Ready phase.
+	// +optional
+	ReadyKernels int32 `json:"readyKernels"`
+	// TotalKernels is the number of kernels running on the targeted nodes that a kernel mapping matches.
+	// +optional
+	TotalKernels int32 `json:"totalKernels"`
+	// LastTransitionTime is the last time the phase of a kernel changed.
+	// +optional
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Conditions describe the current state of the Module.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+const (
+	// ModuleConditionDegraded is true when the Module could not be deployed on some kernels.
+	ModuleConditionDegraded = "Degraded"
+
+	// ModuleConditionSpecRejected is true when the Module's spec is not allowed by the operator, which then does
+	// not deploy it.
+	ModuleConditionSpecRejected = "SpecRejected"
+
+	// ModuleConditionFIPS is true when the Module is handled by an operator running in FIPS mode.
+	ModuleConditionFIPS = "FIPS"
+
+	// ModuleConditionProgressing is true when the operator is waiting for build or sign Jobs of the Module to
+	// complete. Its message lists the kernels for which it is waiting.
+	ModuleConditionProgressing = "Progressing"
+
+	// ModuleConditionMappingConflict is true when some nodes are skipped because they select another kernel mapping
+	// than the one used for other nodes running the same kernel. Its message lists those nodes.
+	ModuleConditionMappingConflict = "MappingConflict"
+)
+
 //+kubebuilder:object:root=true
 //+kubebuilder:resource:scope=Namespaced
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Ready Kernels",type=integer,JSONPath=`.status.readyKernels`
+//+kubebuilder:printcolumn:name="Kernels",type=integer,JSONPath=`.status.totalKernels`
+//+kubebuilder:printcolumn:name="Available Nodes",type=integer,JSONPath=`.status.moduleLoader.availableNumber`
+//+kubebuilder:printcolumn:name="Desired Nodes",type=integer,JSONPath=`.status.moduleLoader.desiredNumber`
+//+kubebuilder:printcolumn:name="Last Transition",type=date,JSONPath=`.status.lastTransitionTime`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // Module is the Schema for the modules API
 type Module struct {
@@ -82,8 +82,19 @@ type PreflightValidationStatus struct {
 	// +patchStrategy=merge
 	// +optional
 	CRStatuses map[string]*CRStatus `json:"crStatuses,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// Conditions describe the current state of the PreflightValidation, such as whether all Modules are verified.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+const (
+	// PreflightValidationConditionReady is true when all Modules are verified against the kernel of the
+	// PreflightValidation, with their images available for it.
+	PreflightValidationConditionReady = "Ready"
+)
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/kubernetes-sigs/kernel-module-management/internal/cli"
+)
+
+func runApply(ctx context.Context, args []string) error {
+	var (
+		cf       clusterFlags
+		filename string
+		rewrites []cli.RegistryRewrite
+	)
+
+	fs := flag.NewFlagSet("apply", flag.ContinueOnError)
+	cf.bind(fs)
+	fs.StringVar(&filename, "f", "", "The file written by kubectl kmm export. - for stdin.")
+	bindRegistryRewrites(fs, &rewrites)
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 0 {
+		return errors.New("unexpected arguments")
+	}
+
+	if filename == "" {
+		return errors.New("-f is required")
+	}
+
+	objs, err := readObjects(filename)
+	if err != nil {
+		return err
+	}
+
+	c, namespace, err := cf.client()
+	if err != nil {
+		return err
+	}
+
+	warnings, err := cli.Apply(ctx, c, scheme, namespace, objs, rewrites)
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range objs {
+		fmt.Printf("%s %s/%s applied\n", obj.GetObjectKind().GroupVersionKind().Kind, namespace, obj.GetName())
+	}
+
+	for _, w := range warnings {
+		fmt.Fprintln(os.Stderr, "Warning:", w)
+	}
+
+	return nil
+}
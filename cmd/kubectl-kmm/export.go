@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/kubernetes-sigs/kernel-module-management/internal/cli"
+)
+
+// bindRegistryRewrites registers the repeatable -rewrite-registry flag, appending the parsed rewrites to rewrites.
+func bindRegistryRewrites(fs *flag.FlagSet, rewrites *[]cli.RegistryRewrite) {
+	fs.Func(
+		"rewrite-registry",
+		"Replace an image prefix, as from=to, e.g. quay.io/staging=quay.io/prod. Can be repeated.",
+		func(s string) error {
+			r, err := cli.ParseRegistryRewrite(s)
+			if err != nil {
+				return err
+			}
+
+			*rewrites = append(*rewrites, r)
+
+			return nil
+		},
+	)
+}
+
+func runExport(ctx context.Context, args []string) error {
+	var (
+		cf       clusterFlags
+		rewrites []cli.RegistryRewrite
+	)
+
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	cf.bind(fs)
+	bindRegistryRewrites(fs, &rewrites)
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	c, namespace, err := cf.client()
+	if err != nil {
+		return err
+	}
+
+	objs, warnings, err := cli.Export(ctx, c, namespace, positional, rewrites)
+	if err != nil {
+		return err
+	}
+
+	for _, w := range warnings {
+		fmt.Fprintln(os.Stderr, "Warning:", w)
+	}
+
+	return cli.EncodeObjects(os.Stdout, objs, scheme)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubernetes-sigs/kernel-module-management/internal/cli"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/registry"
+)
+
+func runInit(_ context.Context, args []string) error {
+	var (
+		image     string
+		name      string
+		namespace string
+	)
+
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	fs.StringVar(&image, "image", "", "The driver image to inspect.")
+	fs.StringVar(&name, "name", "", "The name of the Module. Defaults to the name of the kernel module.")
+	fs.StringVar(&namespace, "n", "default", "The namespace of the Module.")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 0 {
+		return errors.New("unexpected arguments")
+	}
+
+	if image == "" {
+		return errors.New("-image is required")
+	}
+
+	reg := registry.NewRegistry()
+
+	img, err := pullImage(reg, image)
+	if err != nil {
+		return err
+	}
+
+	contents, err := cli.InspectImage(reg, img)
+	if err != nil {
+		return err
+	}
+
+	mod, warnings, err := cli.GenerateModule(name, namespace, image, contents)
+	if err != nil {
+		return err
+	}
+
+	for _, w := range warnings {
+		fmt.Fprintln(os.Stderr, "Warning:", w)
+	}
+
+	return cli.EncodeObjects(os.Stdout, []client.Object{mod}, scheme)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kubernetes-sigs/kernel-module-management/internal/cli"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/validation"
+)
+
+func runLint(ctx context.Context, args []string) error {
+	var (
+		allNamespaces         bool
+		cf                    clusterFlags
+		devicePluginHostPaths string
+		moduleNamespaces      string
+		imageRepositories     string
+	)
+
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	cf.bind(fs)
+	fs.BoolVar(&allNamespaces, "all-namespaces", false, "Check the Modules of all namespaces.")
+	fs.BoolVar(&allNamespaces, "A", false, "Shorthand for -all-namespaces.")
+	fs.StringVar(&devicePluginHostPaths, "device-plugin-allowed-host-paths", "", "The value of the operator's flag.")
+	fs.StringVar(&moduleNamespaces, "allowed-module-namespaces", "", "The value of the operator's flag.")
+	fs.StringVar(&imageRepositories, "allowed-image-repositories", "", "The value of the operator's flag.")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 0 {
+		return errors.New("unexpected arguments")
+	}
+
+	c, namespace, err := cf.client()
+	if err != nil {
+		return err
+	}
+
+	if allNamespaces {
+		namespace = ""
+	}
+
+	validator := validation.NewValidator(
+		commaSeparatedList(devicePluginHostPaths),
+		commaSeparatedList(moduleNamespaces),
+		commaSeparatedList(imageRepositories),
+	)
+
+	problems, err := cli.Lint(ctx, c, validator, module.NewKernelMapper(), namespace)
+	if err != nil {
+		return err
+	}
+
+	if len(problems) == 0 {
+		fmt.Println("No problem found")
+		return nil
+	}
+
+	if err = cli.PrintLintProblems(os.Stdout, problems); err != nil {
+		return err
+	}
+
+	return fmt.Errorf("found %d problems", len(problems))
+}
+
+// commaSeparatedList returns the non-empty elements of s, like the operator does for its list flags.
+func commaSeparatedList(s string) []string {
+	list := make([]string, 0)
+
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			list = append(list, e)
+		}
+	}
+
+	return list
+}
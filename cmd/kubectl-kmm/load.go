@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/kubernetes-sigs/kernel-module-management/internal/cli"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/registry"
+)
+
+// The directory to which the module-loader container copies firmware on the node.
+const nodeFirmwarePath = "/var/lib/firmware"
+
+func runLoad(ctx context.Context, args []string) error {
+	var (
+		image        string
+		dirName      string
+		firmwarePath string
+		kernel       string
+		dryRun       bool
+	)
+
+	fs := flag.NewFlagSet("load", flag.ContinueOnError)
+	fs.StringVar(&image, "image", "", "The module-loader image.")
+	fs.StringVar(&dirName, "dir-name", "/opt", "The modprobe dirName of the Module.")
+	fs.StringVar(&firmwarePath, "firmware-path", "", "The modprobe firmwarePath of the Module, if any.")
+	fs.StringVar(&kernel, "kernel", "", "The kernel version to load the module for. Defaults to the running kernel.")
+	fs.BoolVar(&dryRun, "dry-run", false, "Extract the files and run modprobe --dry-run, without changing the node.")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) == 0 {
+		return errors.New("expected a kernel module name")
+	}
+
+	if image == "" {
+		return errors.New("-image is required")
+	}
+
+	if kernel == "" {
+		b, err := os.ReadFile("/proc/sys/kernel/osrelease")
+		if err != nil {
+			return fmt.Errorf("could not determine the running kernel: %v", err)
+		}
+
+		kernel = strings.TrimSpace(string(b))
+	}
+
+	reg := registry.NewRegistry()
+
+	fmt.Println("Pulling", image)
+
+	img, err := pullImage(reg, image)
+	if err != nil {
+		return err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "kmm-load-")
+	if err != nil {
+		return fmt.Errorf("could not create a temporary directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	n, err := cli.ExtractModules(reg, img, dirName, kernel, tmpDir, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Extracted %d files for kernel %s\n", n, kernel)
+
+	if firmwarePath != "" {
+		fwDest := nodeFirmwarePath
+
+		if dryRun {
+			fwDest = filepath.Join(tmpDir, "firmware")
+			fmt.Println("Dry run: not copying the firmware to", nodeFirmwarePath)
+		}
+
+		n, err = cli.ExtractFirmware(reg, img, firmwarePath, fwDest, os.Stdout)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Extracted %d firmware files\n", n)
+	}
+
+	modprobe := cli.ModprobeCommand(tmpDir, kernel, positional[0], positional[1:], dryRun)
+
+	fmt.Println("Running", strings.Join(modprobe, " "))
+
+	cmd := exec.CommandContext(ctx, modprobe[0], modprobe[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err = cmd.Run(); err != nil {
+		return fmt.Errorf("modprobe failed: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,193 @@
+// kubectl-kmm is a kubectl plugin that inspects the objects managed by KMM.
+// Install it in the PATH and run it as `kubectl kmm <command>`.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/cli"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/registry"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(kmmv1beta1.AddToScheme(scheme))
+}
+
+type command struct {
+	usage string
+	run   func(ctx context.Context, args []string) error
+}
+
+var commands = map[string]command{
+	"apply":       {usage: "apply -f <file> [-n namespace] [-rewrite-registry from=to]", run: runApply},
+	"export":      {usage: "export [-n namespace] [-rewrite-registry from=to] [module...]", run: runExport},
+	"init":        {usage: "init -image <image> [-name name] [-n namespace]", run: runInit},
+	"lint":        {usage: "lint [-n namespace | -A]", run: runLint},
+	"load":        {usage: "load -image <image> [-kernel version] [-dry-run] <module> [parameters]", run: runLoad},
+	"must-gather": {usage: "must-gather [-o file] [-operator-namespace namespace]", run: runMustGather},
+	"nodes":       {usage: "nodes [-n namespace] <module>", run: runNodes},
+	"preflight":   {usage: "preflight -kernel <version> [-n namespace | -A] [-strict]", run: runPreflight},
+	"prepull":     {usage: "prepull [-n namespace] [-l key=value] [-timeout duration] <module>", run: runPrePull},
+	"rebuild":     {usage: "rebuild -kernel <version> [-n namespace] <module>", run: runRebuild},
+	"render":      {usage: "render -f <file> -kernel <version> [-n namespace]", run: runRender},
+	"status":      {usage: "status [-n namespace] <module>", run: runStatus},
+	"validate":    {usage: "validate -f <file> [-n namespace]", run: runValidate},
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: kubectl kmm <command> [flags]")
+	fmt.Fprintln(os.Stderr, "Commands:")
+
+	names := make([]string, 0, len(commands))
+
+	for name := range commands {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintln(os.Stderr, "  "+commands[name].usage)
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+
+	if err := cmd.run(context.Background(), os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+// clusterFlags are the flags common to all commands that access the cluster.
+type clusterFlags struct {
+	kubeconfig string
+	namespace  string
+}
+
+func (cf *clusterFlags) bind(fs *flag.FlagSet) {
+	cf.bindKubeconfig(fs)
+	fs.StringVar(&cf.namespace, "namespace", "", "The namespace of the Modules. Defaults to the kubeconfig's namespace.")
+	fs.StringVar(&cf.namespace, "n", "", "Shorthand for -namespace.")
+}
+
+// bindKubeconfig only registers the kubeconfig flag, for commands that are not restricted to a namespace.
+func (cf *clusterFlags) bindKubeconfig(fs *flag.FlagSet) {
+	fs.StringVar(&cf.kubeconfig, "kubeconfig", "", "The path to the kubeconfig file.")
+}
+
+// restConfig returns the configuration of the current kubeconfig context, along with the namespace to use.
+func (cf *clusterFlags) restConfig() (*rest.Config, string, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = cf.kubeconfig
+
+	cc := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{})
+
+	namespace := cf.namespace
+
+	if namespace == "" {
+		ns, _, err := cc.Namespace()
+		if err != nil {
+			return nil, "", fmt.Errorf("could not determine the namespace: %v", err)
+		}
+
+		namespace = ns
+	}
+
+	cfg, err := cc.ClientConfig()
+	if err != nil {
+		return nil, "", fmt.Errorf("could not load the kubeconfig: %v", err)
+	}
+
+	return cfg, namespace, nil
+}
+
+// client returns a client for the cluster of the current kubeconfig context, along with the namespace to use.
+func (cf *clusterFlags) client() (client.Client, string, error) {
+	cfg, namespace, err := cf.restConfig()
+	if err != nil {
+		return nil, "", err
+	}
+
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, "", fmt.Errorf("could not create the client: %v", err)
+	}
+
+	return c, namespace, nil
+}
+
+// readObjects decodes the objects of filename, or of stdin if filename is "-".
+func readObjects(filename string) ([]client.Object, error) {
+	if filename == "-" {
+		return cli.DecodeObjects(os.Stdin, scheme)
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not open %s: %v", filename, err)
+	}
+	defer f.Close()
+
+	return cli.DecodeObjects(f, scheme)
+}
+
+// parseArgs parses args with fs, allowing flags after positional arguments as kubectl does.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	positional := make([]string, 0)
+
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// pullImage fetches image with the credentials of docker login or podman login.
+func pullImage(reg registry.Registry, image string) (v1.Image, error) {
+	ref, err := reg.ParseReference(image)
+	if err != nil {
+		return nil, err
+	}
+
+	auth, err := authn.DefaultKeychain.Resolve(ref.Context())
+	if err != nil {
+		return nil, fmt.Errorf("could not get the credentials of %s: %v", ref.Context(), err)
+	}
+
+	return reg.GetImageByName(image, auth)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kubernetes-sigs/kernel-module-management/internal/cli"
+)
+
+func runMustGather(ctx context.Context, args []string) error {
+	var (
+		cf                clusterFlags
+		operatorNamespace string
+		output            string
+	)
+
+	fs := flag.NewFlagSet("must-gather", flag.ContinueOnError)
+	cf.bindKubeconfig(fs)
+	fs.StringVar(&operatorNamespace, "operator-namespace", "kmm-operator-system", "The namespace in which the operator runs.")
+	fs.StringVar(
+		&output,
+		"o",
+		fmt.Sprintf("kmm-must-gather-%s.tar.gz", time.Now().UTC().Format("20060102-150405")),
+		"The path of the tarball to write.",
+	)
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 0 {
+		return errors.New("unexpected arguments")
+	}
+
+	c, _, err := cf.client()
+	if err != nil {
+		return err
+	}
+
+	cfg, _, err := cf.restConfig()
+	if err != nil {
+		return err
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("could not create the clientset: %v", err)
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("could not create %s: %v", output, err)
+	}
+
+	if err = cli.MustGather(ctx, c, cli.NewPodLogGetter(clientset), operatorNamespace, f); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("could not write %s: %v", output, err)
+	}
+
+	fmt.Println("Wrote", output)
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/kubernetes-sigs/kernel-module-management/internal/cli"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+)
+
+func runNodes(ctx context.Context, args []string) error {
+	var cf clusterFlags
+
+	fs := flag.NewFlagSet("nodes", flag.ContinueOnError)
+	cf.bind(fs)
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		return errors.New("expected exactly one Module name")
+	}
+
+	c, namespace, err := cf.client()
+	if err != nil {
+		return err
+	}
+
+	nodes, err := cli.GetMissingNodes(ctx, c, module.NewKernelMapper(), positional[0], namespace)
+	if err != nil {
+		return err
+	}
+
+	if len(nodes) == 0 {
+		fmt.Println("The module is loaded on all targeted nodes")
+		return nil
+	}
+
+	return cli.PrintMissingNodes(os.Stdout, nodes)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+
+	"github.com/kubernetes-sigs/kernel-module-management/internal/cli"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/registry"
+)
+
+func runPreflight(ctx context.Context, args []string) error {
+	var (
+		allNamespaces bool
+		cf            clusterFlags
+		kernel        string
+		strict        bool
+	)
+
+	fs := flag.NewFlagSet("preflight", flag.ContinueOnError)
+	cf.bind(fs)
+	fs.BoolVar(&allNamespaces, "all-namespaces", false, "Check the Modules of all namespaces.")
+	fs.BoolVar(&allNamespaces, "A", false, "Shorthand for -all-namespaces.")
+	fs.StringVar(&kernel, "kernel", "", "The kernel version to check the Modules against.")
+	fs.BoolVar(&strict, "strict", false, "Also fail if images still need to be built or signed.")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 0 {
+		return errors.New("unexpected arguments")
+	}
+
+	if kernel == "" {
+		return errors.New("-kernel is required")
+	}
+
+	c, namespace, err := cf.client()
+	if err != nil {
+		return err
+	}
+
+	if allNamespaces {
+		namespace = ""
+	}
+
+	results, err := cli.Preflight(ctx, c, registry.NewRegistry(), module.NewKernelMapper(), kernel, namespace)
+	if err != nil {
+		return err
+	}
+
+	if err = cli.PrintPreflightResults(os.Stdout, kernel, results); err != nil {
+		return err
+	}
+
+	if !cli.PreflightPassed(results, strict) {
+		return errors.New("some Modules cannot be loaded on this kernel")
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubernetes-sigs/kernel-module-management/internal/cli"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+)
+
+func runPrePull(ctx context.Context, args []string) error {
+	var (
+		cf       clusterFlags
+		selector string
+		timeout  time.Duration
+	)
+
+	fs := flag.NewFlagSet("prepull", flag.ContinueOnError)
+	cf.bind(fs)
+	fs.StringVar(&selector, "l", "", "The labels of the nodes to pre-pull the images on, as key=value[,key=value], on top of the Module's selector.")
+	fs.DurationVar(&timeout, "timeout", 10*time.Minute, "How long to wait for the images to be pulled.")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		return errors.New("expected exactly one Module name")
+	}
+
+	nodeSelector, err := labels.ConvertSelectorToLabelsMap(selector)
+	if err != nil {
+		return fmt.Errorf("invalid node selector %q: %v", selector, err)
+	}
+
+	c, namespace, err := cf.client()
+	if err != nil {
+		return err
+	}
+
+	pods, warnings, err := cli.PrePullPods(ctx, c, module.NewKernelMapper(), positional[0], namespace, nodeSelector)
+	if err != nil {
+		return err
+	}
+
+	for _, w := range warnings {
+		fmt.Fprintln(os.Stderr, "Warning:", w)
+	}
+
+	// The pods are only needed until the images are pulled: delete them whatever the outcome.
+	defer func() {
+		for i := 0; i < len(pods); i++ {
+			if pods[i].Name == "" {
+				continue
+			}
+
+			if err := c.Delete(context.Background(), &pods[i], client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not delete pod %s: %v\n", pods[i].Name, err)
+			}
+		}
+	}()
+
+	for i := 0; i < len(pods); i++ {
+		if err = c.Create(ctx, &pods[i]); err != nil {
+			return fmt.Errorf("could not create the pre-pull pod for node %s: %v", pods[i].Spec.NodeName, err)
+		}
+	}
+
+	fmt.Printf("Pulling the images on %d nodes\n", len(pods))
+
+	err = wait.PollImmediateWithContext(ctx, 2*time.Second, timeout, func(ctx context.Context) (bool, error) {
+		for i := 0; i < len(pods); i++ {
+			if state, _ := cli.PrePullState(&pods[i]); state != cli.PrePullPending {
+				continue
+			}
+
+			if err := c.Get(ctx, client.ObjectKeyFromObject(&pods[i]), &pods[i]); err != nil {
+				return false, fmt.Errorf("could not get pod %s: %v", pods[i].Name, err)
+			}
+
+			if state, _ := cli.PrePullState(&pods[i]); state == cli.PrePullPending {
+				return false, nil
+			}
+		}
+
+		return true, nil
+	})
+	if err != nil && !errors.Is(err, wait.ErrWaitTimeout) {
+		return err
+	}
+
+	if printErr := cli.PrintPrePullStates(os.Stdout, pods); printErr != nil {
+		return printErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("the images were not pulled within %s", timeout)
+	}
+
+	for i := 0; i < len(pods); i++ {
+		if state, _ := cli.PrePullState(&pods[i]); state == cli.PrePullFailed {
+			return errors.New("some images could not be pulled")
+		}
+	}
+
+	return nil
+}
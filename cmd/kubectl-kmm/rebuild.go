@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/kubernetes-sigs/kernel-module-management/internal/cli"
+)
+
+func runRebuild(ctx context.Context, args []string) error {
+	var (
+		cf     clusterFlags
+		kernel string
+	)
+
+	fs := flag.NewFlagSet("rebuild", flag.ContinueOnError)
+	cf.bind(fs)
+	fs.StringVar(&kernel, "kernel", "", "The kernel version whose failed Jobs should be created again.")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		return errors.New("expected exactly one Module name")
+	}
+
+	if kernel == "" {
+		return errors.New("-kernel is required")
+	}
+
+	c, namespace, err := cf.client()
+	if err != nil {
+		return err
+	}
+
+	jobs, err := cli.RequestRebuild(ctx, c, positional[0], namespace, kernel)
+	if err != nil {
+		return err
+	}
+
+	for _, j := range jobs {
+		fmt.Printf("Job %s will be created again\n", j)
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/kubernetes-sigs/kernel-module-management/internal/cli"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/daemonset"
+)
+
+func runRender(ctx context.Context, args []string) error {
+	var (
+		filename string
+		opts     cli.RenderOptions
+	)
+
+	fs := flag.NewFlagSet("render", flag.ContinueOnError)
+	fs.StringVar(&filename, "f", "", "The file containing the Modules, and optionally the ConfigMaps and Secrets they reference. - for stdin.")
+	fs.StringVar(&opts.Kernel, "kernel", "", "The kernel version to render the objects for.")
+	fs.StringVar(&opts.Namespace, "n", "default", "The namespace of the objects that do not have one.")
+	fs.StringVar(
+		&opts.ModuleLoaderSELinuxType,
+		"module-loader-selinux-type",
+		daemonset.DefaultModuleLoaderSELinuxType,
+		"The -module-loader-selinux-type flag of the operator.",
+	)
+	fs.BoolVar(&opts.RestrictedPodSecurity, "restricted-pod-security", false, "The -restricted-pod-security flag of the operator.")
+	fs.StringVar(&opts.SeccompProfile, "module-loader-seccomp-profile", "", "The -module-loader-seccomp-profile flag of the operator.")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 0 {
+		return errors.New("unexpected arguments")
+	}
+
+	if filename == "" || opts.Kernel == "" {
+		return errors.New("-f and -kernel are required")
+	}
+
+	objs, err := readObjects(filename)
+	if err != nil {
+		return err
+	}
+
+	rendered, warnings, err := cli.Render(ctx, scheme, objs, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, w := range warnings {
+		fmt.Fprintln(os.Stderr, "Warning:", w)
+	}
+
+	return cli.EncodeObjects(os.Stdout, rendered, scheme)
+}
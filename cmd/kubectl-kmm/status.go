@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+
+	"github.com/kubernetes-sigs/kernel-module-management/internal/cli"
+)
+
+func runStatus(ctx context.Context, args []string) error {
+	var cf clusterFlags
+
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	cf.bind(fs)
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		return errors.New("expected exactly one Module name")
+	}
+
+	c, namespace, err := cf.client()
+	if err != nil {
+		return err
+	}
+
+	ms, err := cli.GetModuleStatus(ctx, c, positional[0], namespace)
+	if err != nil {
+		return err
+	}
+
+	return cli.PrintModuleStatus(os.Stdout, ms)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/kubernetes-sigs/kernel-module-management/internal/cli"
+)
+
+func runValidate(_ context.Context, args []string) error {
+	var (
+		filename  string
+		namespace string
+	)
+
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	fs.StringVar(&filename, "f", "", "The file containing the Modules and the Dockerfile ConfigMaps they reference. - for stdin.")
+	fs.StringVar(&namespace, "n", "default", "The namespace of the objects that do not have one.")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 0 {
+		return errors.New("unexpected arguments")
+	}
+
+	if filename == "" {
+		return errors.New("-f is required")
+	}
+
+	objs, err := readObjects(filename)
+	if err != nil {
+		return err
+	}
+
+	problems := cli.ValidateModules(objs, namespace)
+
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("found %d problems", len(problems))
+	}
+
+	return nil
+}
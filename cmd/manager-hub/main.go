@@ -20,10 +20,13 @@ import (
 	"errors"
 	"flag"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/kubernetes-sigs/kernel-module-management/api-hub/v1beta1"
 	"github.com/kubernetes-sigs/kernel-module-management/controllers/hub"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/cmd"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -31,19 +34,23 @@ import (
 	"k8s.io/klog/v2/klogr"
 	workv1 "open-cluster-management.io/api/work/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	"github.com/kubernetes-sigs/kernel-module-management/internal/auth"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/build"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/build/job"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/cluster"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/filter"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/fips"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/manifestwork"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/metrics"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/rbac"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/registry"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/sign"
 	signjob "github.com/kubernetes-sigs/kernel-module-management/internal/sign/job"
@@ -51,6 +58,9 @@ import (
 	//+kubebuilder:scaffold:imports
 )
 
+// imageExistenceCacheTTL is how long an image found in its registry is assumed to still exist there.
+const imageExistenceCacheTTL = 5 * time.Minute
+
 var scheme = runtime.NewScheme()
 
 func init() {
@@ -67,30 +77,84 @@ func main() {
 
 	setupLogger := logger.WithName("setup")
 
-	var configFile string
+	var (
+		clientOpts            cmd.ClientOptions
+		configFile            string
+		controllerOpts        cmd.ControllerOptions
+		fipsMode              bool
+		jobQueueName          string
+		kernelRules           string
+		restrictedPodSecurity bool
+		wiNamespaces          string
+	)
 
 	flag.StringVar(&configFile, "config", "", "The path to the configuration file.")
+	flag.StringVar(
+		&jobQueueName,
+		"job-queue-name",
+		"",
+		"The Kueue LocalQueue through which build and sign Jobs are admitted, in the namespace of each Module. Empty to run them immediately.",
+	)
+	flag.StringVar(
+		&kernelRules,
+		"kernel-normalization-rules",
+		"",
+		"The path to a file of rules normalizing the kernel versions of managed clouds before they are matched against kernel mappings.",
+	)
+	flag.StringVar(
+		&wiNamespaces,
+		"workload-identity-namespaces",
+		"",
+		"The comma-separated namespaces whose ManagedClusterModules may access registries with the operator's workload identity.",
+	)
+	flag.BoolVar(
+		&restrictedPodSecurity,
+		"restricted-pod-security",
+		false,
+		"Make sign pods satisfy the restricted Pod Security Standard.",
+	)
+	flag.BoolVar(
+		&fipsMode,
+		"fips",
+		false,
+		"Run in FIPS mode: refuse to start unless built with FIPS-validated cryptography, and refuse signing certificates that are not FIPS-compliant.",
+	)
+	clientOpts.BindFlags(flag.CommandLine)
+	controllerOpts.BindFlags(flag.CommandLine)
 
 	klog.InitFlags(flag.CommandLine)
 
 	flag.Parse()
 
+	if fipsMode && !fips.Enabled() {
+		cmd.FatalError(setupLogger, errors.New("not built with the BoringCrypto module"), "cannot run in FIPS mode")
+	}
+
 	commit, err := cmd.GitCommit()
 	if err != nil {
 		setupLogger.Error(err, "Could not get the git commit; using <undefined>")
 		commit = "<undefined>"
 	}
 
+	auth.RestrictWorkloadIdentities(commaSeparatedList(wiNamespaces)...)
+
 	setupLogger.Info("Creating manager", "git commit", commit)
 
-	options := ctrl.Options{Scheme: scheme}
+	options := ctrl.Options{
+		Scheme: scheme,
+		// Secrets and ConfigMaps are read directly from the API server, so that their contents are not cached.
+		ClientDisableCacheFor: []ctrlclient.Object{&v1.Secret{}, &v1.ConfigMap{}},
+	}
 
 	options, err = options.AndFrom(ctrl.ConfigFile().AtPath(configFile))
 	if err != nil {
 		cmd.FatalError(setupLogger, err, "unable to load the config file")
 	}
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), options)
+	restConfig := ctrl.GetConfigOrDie()
+	clientOpts.Apply(restConfig)
+
+	mgr, err := ctrl.NewManager(restConfig, options)
 	if err != nil {
 		cmd.FatalError(setupLogger, err, "unable to create manager")
 	}
@@ -102,8 +166,8 @@ func main() {
 	metricsAPI := metrics.New()
 	metricsAPI.Register()
 
-	registryAPI := registry.NewRegistry()
-	jobHelperAPI := utils.NewJobHelper(client)
+	registryAPI := registry.NewCachingRegistry(registry.NewRegistry(), imageExistenceCacheTTL)
+	jobHelperAPI := utils.NewJobHelper(client, jobQueueName)
 
 	buildAPI := job.NewBuildManager(
 		client,
@@ -114,7 +178,7 @@ func main() {
 
 	signAPI := signjob.NewSignJobManager(
 		client,
-		signjob.NewSigner(client, scheme, sign.NewSignerHelper(), jobHelperAPI),
+		signjob.NewSigner(client, scheme, sign.NewSignerHelper(), jobHelperAPI, restrictedPodSecurity, fipsMode),
 		jobHelperAPI,
 		registryAPI,
 	)
@@ -129,17 +193,32 @@ func main() {
 		cmd.FatalError(ctrlLogger, errors.New("empty value"), "Could not determine the current namespace", "name", operatorNamespace)
 	}
 
+	kernelAPI := module.NewKernelMapper()
+
+	if kernelRules != "" {
+		rules, err := module.LoadKernelNormalizationRules(kernelRules)
+		if err != nil {
+			cmd.FatalError(setupLogger, err, "unable to load the kernel normalization rules")
+		}
+
+		kernelAPI = module.NewNormalizingKernelMapper(rules)
+	}
+
 	mcmr := hub.NewManagedClusterModuleReconciler(
 		client,
 		manifestwork.NewCreator(client, scheme),
-		cluster.NewClusterAPI(client, module.NewKernelMapper(), buildAPI, signAPI, operatorNamespace),
+		cluster.NewClusterAPI(client, kernelAPI, buildAPI, signAPI, rbac.NewCreator(client, scheme), operatorNamespace),
 		filterAPI,
 	)
 
-	if err = mcmr.SetupWithManager(mgr); err != nil {
+	if err = mcmr.SetupWithManager(mgr, controllerOpts.ControllerOptions()); err != nil {
 		cmd.FatalError(ctrlLogger, err, "unable to create controller")
 	}
 
+	if err = hub.NewManagedClusterModuleStatusReconciler(client).SetupWithManager(mgr, controllerOpts.ControllerOptions()); err != nil {
+		cmd.FatalError(ctrlLogger, err, "unable to create controller", "name", hub.ManagedClusterModuleStatusReconcilerName)
+	}
+
 	//+kubebuilder:scaffold:builder
 
 	if err = mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -154,3 +233,15 @@ func main() {
 		cmd.FatalError(setupLogger, err, "problem running manager")
 	}
 }
+
+func commaSeparatedList(s string) []string {
+	items := make([]string, 0)
+
+	for _, i := range strings.Split(s, ",") {
+		if i = strings.TrimSpace(i); i != "" {
+			items = append(items, i)
+		}
+	}
+
+	return items
+}
@@ -17,20 +17,29 @@ limitations under the License.
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/google/go-containerregistry/pkg/name"
 	v1beta12 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/cmd"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/klog/v2"
 	"k8s.io/klog/v2/klogr"
 	clusterv1 "open-cluster-management.io/api/cluster/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
@@ -38,23 +47,77 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	"github.com/kubernetes-sigs/kernel-module-management/controllers"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/auth"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/build"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/build/job"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/daemonset"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/decisions"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/dryrun"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/filter"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/fips"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/imagestream"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/metrics"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/networkpolicy"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/nodepool"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/orphans"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/pinning"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/podsecurity"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/preflight"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/provenance"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/rbac"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/registry"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/registryca"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/retention"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/shard"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/sign"
 	signjob "github.com/kubernetes-sigs/kernel-module-management/internal/sign/job"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/statusupdater"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/utils"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/validation"
 	//+kubebuilder:scaffold:imports
 )
 
+const (
+	// imageExistenceCacheTTL is how long an image found in its registry is assumed to still exist there.
+	imageExistenceCacheTTL = 5 * time.Minute
+
+	// moduleCountPeriod is how often the number of existing Modules is refreshed in the metrics.
+	moduleCountPeriod = time.Minute
+
+	// singleNodeImageExistenceCacheTTL and singleNodeModuleCountPeriod replace imageExistenceCacheTTL and
+	// moduleCountPeriod in single-node mode.
+	singleNodeImageExistenceCacheTTL = time.Hour
+	singleNodeModuleCountPeriod      = 10 * time.Minute
+
+	// nodePoolImagesConfigMap is the ConfigMap of the operator's namespace in which the images of each node pool are
+	// published, every nodePoolImagesPeriod.
+	nodePoolImagesConfigMap = "kmm-node-pool-images"
+	nodePoolImagesPeriod    = 5 * time.Minute
+
+	// pinnedImagesPeriod is how often the images to pin on nodes are published.
+	pinnedImagesPeriod = 5 * time.Minute
+
+	// registryCAUninstallPeriod is how often the removal of the registry CA bundles from nodes is checked.
+	registryCAUninstallPeriod = time.Minute
+
+	// imagePruningPeriod is how often the image retention policy is applied.
+	imagePruningPeriod = time.Hour
+
+	// orphanSweepPeriod is how often DaemonSets and Jobs without a controller are looked for.
+	orphanSweepPeriod = 10 * time.Minute
+
+	// operatorNamespaceEnvVar is the environment variable set to the namespace of the operator pod.
+	operatorNamespaceEnvVar = "OPERATOR_NAMESPACE"
+	// operatorConditionNameEnvVar is the environment variable set by OLM to the name of the operator's
+	// OperatorCondition.
+	operatorConditionNameEnvVar = "OPERATOR_CONDITION_NAME"
+
+	// decisionsPath is the path of the metrics server on which the decisions of Module reconciliations are served.
+	decisionsPath = "/debug/modules"
+)
+
 var scheme = runtime.NewScheme()
 
 func init() {
@@ -70,14 +133,265 @@ func main() {
 
 	setupLogger := logger.WithName("setup")
 
-	var configFile string
+	var (
+		buildSignNetworkPolicy  bool
+		clusterAPIPreflight     bool
+		clientOpts              cmd.ClientOptions
+		devicePluginHostPaths   string
+		moduleNamespaces        string
+		nodeCleanupJobs         bool
+		nodePoolImages          bool
+		pinImages               bool
+		nodeInstallerImage      string
+		imageRepositories       string
+		imageRetention          retention.Policy
+		jobQueueName            string
+		kernelRules             string
+		loadRejectionAction     string
+		loadFailureCordon       bool
+		loadFailureThreshold    int
+		configFile              string
+		controllerOpts          cmd.ControllerOptions
+		egressPorts             string
+		extraKernelLabels       string
+		dryRun                  bool
+		fipsMode                bool
+		recordDecisions         bool
+		registryCAConfigMap     string
+		resolveImageStreams     bool
+		privilegedPodNamespaces string
+		metricsMonitoring       bool
+		restrictedPodSecurity   bool
+		seccompProfile          string
+		seLinuxType             string
+		shardCount              int
+		singleNode              bool
+		startupTaint            bool
+		sweepOrphans            bool
+		shardIndex              int
+		sriovPolicies           bool
+		wiNamespaces            string
+	)
 
 	flag.StringVar(&configFile, "config", "", "The path to the configuration file.")
+	flag.IntVar(&shardCount, "shard-count", 1, "The number of replicas across which namespaces are sharded.")
+	flag.IntVar(&shardIndex, "shard-index", 0, "The index of the shard handled by this replica, starting at 0.")
+	flag.StringVar(
+		&seLinuxType,
+		"module-loader-selinux-type",
+		daemonset.DefaultModuleLoaderSELinuxType,
+		"The SELinux type of module-loader containers for Modules that do not set SELinux options. Empty to leave unset.",
+	)
+	flag.BoolVar(
+		&buildSignNetworkPolicy,
+		"build-sign-network-policy",
+		false,
+		"Create a NetworkPolicy for each Module that restricts its build and sign pods to DNS and the egress ports.",
+	)
+	flag.StringVar(
+		&egressPorts,
+		"build-sign-egress-ports",
+		"80,443,5000",
+		"The comma-separated TCP ports to which build and sign pods may connect, typically those of registries and proxies.",
+	)
+	flag.StringVar(
+		&jobQueueName,
+		"job-queue-name",
+		"",
+		"The Kueue LocalQueue through which build and sign Jobs are admitted, in the namespace of each Module. Empty to run them immediately.",
+	)
+	flag.BoolVar(
+		&restrictedPodSecurity,
+		"restricted-pod-security",
+		false,
+		"Make sign pods satisfy the restricted Pod Security Standard, and module-loader pods as far as loading modules allows.",
+	)
+	flag.StringVar(
+		&seccompProfile,
+		"module-loader-seccomp-profile",
+		"",
+		"The localhost seccomp profile of module-loader pods, relative to the kubelet's seccomp directory. Empty to use the default profile.",
+	)
+	flag.StringVar(
+		&privilegedPodNamespaces,
+		"pod-security-label-namespaces",
+		"",
+		"Comma-separated list of namespaces that the operator labels while they have Modules, so that the privileged Pod Security Standard is enforced there. Empty to label none.",
+	)
+	flag.StringVar(
+		&devicePluginHostPaths,
+		"device-plugin-allowed-host-paths",
+		"",
+		"The comma-separated host directories below which device-plugin pods may mount host paths. Empty to allow all host paths.",
+	)
+	flag.StringVar(
+		&moduleNamespaces,
+		"allowed-module-namespaces",
+		"",
+		"The comma-separated namespaces in which Modules are deployed. Empty to allow all namespaces.",
+	)
+	flag.StringVar(
+		&imageRepositories,
+		"allowed-image-repositories",
+		"",
+		"The comma-separated registries or repositories from which Modules may use images. Empty to allow all images.",
+	)
+	flag.StringVar(
+		&wiNamespaces,
+		"workload-identity-namespaces",
+		"",
+		"The comma-separated namespaces whose Modules may access registries with the operator's workload identity.",
+	)
+	flag.StringVar(
+		&kernelRules,
+		"kernel-normalization-rules",
+		"",
+		"The path to a file of rules normalizing the kernel versions of managed clouds before they are matched against kernel mappings.",
+	)
+	flag.StringVar(
+		&loadRejectionAction,
+		"module-load-rejection-action",
+		"",
+		"How to mark the nodes on which the kernel rejects a module, in addition to Events: \"label\", \"taint\" or empty.",
+	)
+	flag.BoolVar(
+		&fipsMode,
+		"fips",
+		false,
+		"Run in FIPS mode: refuse to start unless built with FIPS-validated cryptography, and refuse signing certificates that are not FIPS-compliant.",
+	)
+	flag.BoolVar(
+		&singleNode,
+		"single-node",
+		false,
+		"Reduce the footprint of the operator on single-node clusters: disable leader election and sharding, and run periodic work less often.",
+	)
+	flag.BoolVar(
+		&clusterAPIPreflight,
+		"cluster-api-preflight",
+		false,
+		"Create a PreflightValidation for the kernel announced by each Cluster API MachineDeployment, before it rolls out.",
+	)
+	flag.BoolVar(
+		&sriovPolicies,
+		"sriov-network-node-policies",
+		false,
+		"Restrict the SriovNetworkNodePolicies that list Modules in the "+constants.WaitForModulesAnnotation+" annotation to the nodes where these Modules are ready.",
+	)
+	flag.BoolVar(
+		&resolveImageStreams,
+		"resolve-imagestreams",
+		false,
+		"Resolve the kernel mapping images starting with "+imagestream.ReferencePrefix+" to the digest of the OpenShift ImageStreamTag they reference.",
+	)
+	flag.IntVar(
+		&loadFailureThreshold,
+		"module-load-failure-threshold",
+		0,
+		"The number of times a module-loader must fail on a node for its "+constants.ModuleLoadFailureNodeCondition+
+			" condition to become true, for instance 3. 0 to disable the condition.",
+	)
+	flag.BoolVar(
+		&loadFailureCordon,
+		"module-load-failure-cordon",
+		false,
+		"Cordon the nodes whose "+constants.ModuleLoadFailureNodeCondition+" condition is true.",
+	)
+	flag.BoolVar(
+		&startupTaint,
+		"remove-startup-taint",
+		false,
+		"Remove the "+constants.ModulesNotReadyTaint+" taint from nodes once all the Modules targeting them are ready.",
+	)
+	flag.BoolVar(
+		&nodePoolImages,
+		"publish-node-pool-images",
+		false,
+		"Publish the images run on the nodes of each node pool in the "+nodePoolImagesConfigMap+" ConfigMap, for node bootstrap tooling to pre-pull them.",
+	)
+	flag.StringVar(
+		&registryCAConfigMap,
+		"registry-ca-configmap",
+		"",
+		"The ConfigMap in the operator's namespace holding the CA bundles of registries, one per registry host, to install on all nodes. Empty to install none.",
+	)
+	flag.BoolVar(
+		&pinImages,
+		"pin-module-images",
+		false,
+		"Pin the module-loader and device-plugin images in CRI-O on all nodes, so that the kubelet never garbage-collects them.",
+	)
+	flag.StringVar(
+		&nodeInstallerImage,
+		"node-installer-image",
+		"",
+		"The image, referenced by digest and providing bash, of the privileged DaemonSets installing files on nodes. Required by -pin-module-images and -registry-ca-configmap.",
+	)
+	flag.DurationVar(
+		&imageRetention.MaxAbsence,
+		"image-retention-period",
+		0,
+		"Delete from their registry the images built or signed by KMM for kernels that no node targeted by their Module has run for this long. 0 to keep all images.",
+	)
+	flag.IntVar(
+		&imageRetention.Keep,
+		"image-retention-keep",
+		3,
+		"The number of most recently removed kernels of each Module whose images are kept regardless of -image-retention-period.",
+	)
+	flag.BoolVar(
+		&imageRetention.DryRun,
+		"image-retention-dry-run",
+		false,
+		"Only log and count in metrics the images that -image-retention-period would delete.",
+	)
+	flag.StringVar(
+		&extraKernelLabels,
+		"extra-kernel-labels",
+		"",
+		"The comma-separated node labels in which other tooling records the kernel of nodes, for instance normalized. Modules are reconciled when they change, like the "+constants.KernelLabel+" label.",
+	)
+	flag.BoolVar(
+		&nodeCleanupJobs,
+		"node-cleanup-jobs",
+		false,
+		"When a module-loader DaemonSet is deleted, run on each of its nodes a Job in the Module's namespace that unloads the kernel module and removes its firmware, in case its pre-stop hook did not.",
+	)
+	flag.BoolVar(
+		&sweepOrphans,
+		"sweep-orphans",
+		false,
+		"Periodically make Modules adopt again the DaemonSets and Jobs created for them that lost their owner reference, and delete those whose Module does not exist.",
+	)
+	flag.BoolVar(
+		&metricsMonitoring,
+		"metrics-monitoring",
+		false,
+		"Create the Service exposing the metrics of the operator and, if the Prometheus Operator is installed, a ServiceMonitor for it.",
+	)
+	flag.BoolVar(
+		&dryRun,
+		"dry-run",
+		false,
+		"Only report, in status, Events and metrics, the objects that the operator would create, update or delete.",
+	)
+	flag.BoolVar(
+		&recordDecisions,
+		"record-reconcile-decisions",
+		false,
+		"Serve the decisions of the last reconciliation of each Module as JSON on "+decisionsPath+" of the metrics server.",
+	)
+	clientOpts.BindFlags(flag.CommandLine)
+	controllerOpts.BindFlags(flag.CommandLine)
 
 	klog.InitFlags(flag.CommandLine)
 
 	flag.Parse()
 
+	if fipsMode && !fips.Enabled() {
+		cmd.FatalError(setupLogger, errors.New("not built with the BoringCrypto module"), "cannot run in FIPS mode")
+	}
+
 	commit, err := cmd.GitCommit()
 	if err != nil {
 		setupLogger.Error(err, "Could not get the git commit; using <undefined>")
@@ -90,16 +404,71 @@ func main() {
 		managed = false
 	}
 
-	setupLogger.Info("Creating manager", "git commit", commit)
+	s, err := shard.New(shardIndex, shardCount)
+	if err != nil {
+		cmd.FatalError(setupLogger, err, "invalid shard configuration")
+	}
+
+	auth.RestrictWorkloadIdentities(commaSeparatedList(wiNamespaces)...)
+
+	ports, err := networkpolicy.ParsePorts(egressPorts)
+	if err != nil {
+		cmd.FatalError(setupLogger, err, "invalid build and sign egress ports")
+	}
+
+	if pinImages || registryCAConfigMap != "" {
+		if _, err = name.NewDigest(nodeInstallerImage); err != nil {
+			cmd.FatalError(setupLogger, err, "-node-installer-image must be an image referenced by digest")
+		}
+	}
 
-	options := ctrl.Options{Scheme: scheme}
+	setupLogger.Info("Creating manager", "git commit", commit, "shard index", shardIndex, "shard count", shardCount)
+
+	options := ctrl.Options{
+		Scheme: scheme,
+		// Secrets and ConfigMaps are read directly from the API server, so that their contents are not cached.
+		// The only Service read is the metrics one, which is not worth watching all Services.
+		ClientDisableCacheFor: []ctrlclient.Object{&v1.Secret{}, &v1.ConfigMap{}, &v1.Service{}},
+		NewCache: cache.BuilderWithOptions(cache.Options{
+			TransformByObject: cache.TransformByObject{
+				&v1.Node{}: utils.StripNodeForCache,
+			},
+		}),
+	}
 
 	options, err = options.AndFrom(ctrl.ConfigFile().AtPath(configFile))
 	if err != nil {
 		cmd.FatalError(setupLogger, err, "unable to load the config file")
 	}
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), options)
+	countPeriod := moduleCountPeriod
+	cacheTTL := imageExistenceCacheTTL
+
+	if singleNode {
+		if s.IsSharded() {
+			cmd.FatalError(setupLogger, errors.New("sharding is not supported in single-node mode"), "invalid shard configuration")
+		}
+
+		if metricsMonitoring {
+			cmd.FatalError(setupLogger, errors.New("the metrics proxy is not deployed in single-node mode"), "cannot create the metrics Service")
+		}
+
+		// There is no other replica to take over.
+		options.LeaderElection = false
+
+		countPeriod = singleNodeModuleCountPeriod
+		cacheTTL = singleNodeImageExistenceCacheTTL
+	}
+
+	if s.IsSharded() {
+		// Each shard has its own leader.
+		options.LeaderElectionID = fmt.Sprintf("%s-shard-%d", options.LeaderElectionID, shardIndex)
+	}
+
+	restConfig := ctrl.GetConfigOrDie()
+	clientOpts.Apply(restConfig)
+
+	mgr, err := ctrl.NewManager(restConfig, options)
 	if err != nil {
 		cmd.FatalError(setupLogger, err, "unable to create manager")
 	}
@@ -111,8 +480,47 @@ func main() {
 	metricsAPI := metrics.New()
 	metricsAPI.Register()
 
-	registryAPI := registry.NewRegistry()
-	jobHelperAPI := utils.NewJobHelper(client)
+	if err = mgr.Add(metrics.NewModuleCounter(client, metricsAPI, countPeriod, logger.WithName("module-counter"))); err != nil {
+		cmd.FatalError(setupLogger, err, "unable to add the Module counter")
+	}
+
+	// Like the Service it creates, the MonitoringCreator is shared by all shards.
+	if metricsMonitoring && s.IsFirst() {
+		operatorNamespace := os.Getenv(operatorNamespaceEnvVar)
+		if operatorNamespace == "" {
+			cmd.FatalError(setupLogger, errors.New("empty value"), "Could not determine the current namespace", "env", operatorNamespaceEnvVar)
+		}
+
+		monitoringCreator := metrics.NewMonitoringCreator(
+			client,
+			scheme,
+			operatorNamespace,
+			map[string]string{"control-plane": "controller-manager"},
+			logger.WithName("monitoring-creator"),
+		)
+
+		if err = mgr.Add(monitoringCreator); err != nil {
+			cmd.FatalError(setupLogger, err, "unable to add the monitoring creator")
+		}
+	}
+
+	// Statuses are written even in dry-run mode, as they report what the operator would do.
+	statusClient := client
+
+	if dryRun {
+		setupLogger.Info("Running in dry-run mode: no object will be created, updated or deleted")
+
+		client = dryrun.NewClient(client, mgr.GetEventRecorderFor("kmm-dry-run"), metricsAPI)
+	}
+
+	registryAPI := registry.NewCachingRegistry(registry.NewRegistry(), cacheTTL)
+	jobHelperAPI := utils.NewJobHelper(client, jobQueueName)
+
+	// The controller-runtime client cannot read pod logs.
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		cmd.FatalError(setupLogger, err, "unable to create the Kubernetes clientset")
+	}
 
 	buildAPI := job.NewBuildManager(
 		client,
@@ -123,55 +531,280 @@ func main() {
 
 	signAPI := signjob.NewSignJobManager(
 		client,
-		signjob.NewSigner(client, scheme, sign.NewSignerHelper(), jobHelperAPI),
+		signjob.NewSigner(client, scheme, sign.NewSignerHelper(), jobHelperAPI, restrictedPodSecurity, fipsMode),
 		jobHelperAPI,
 		registryAPI,
 	)
 
-	daemonAPI := daemonset.NewCreator(client, constants.KernelLabel, scheme)
+	daemonAPI := daemonset.NewCreator(
+		client,
+		constants.KernelLabel,
+		scheme,
+		seLinuxType,
+		restrictedPodSecurity,
+		seccompProfile,
+	)
 	kernelAPI := module.NewKernelMapper()
 
+	if kernelRules != "" {
+		rules, err := module.LoadKernelNormalizationRules(kernelRules)
+		if err != nil {
+			cmd.FatalError(setupLogger, err, "unable to load the kernel normalization rules")
+		}
+
+		kernelAPI = module.NewNormalizingKernelMapper(rules)
+	}
+
+	// nil leaves the network access of build and sign pods unrestricted.
+	var networkPolicyAPI networkpolicy.NetworkPolicyCreator
+
+	if buildSignNetworkPolicy {
+		networkPolicyAPI = networkpolicy.NewCreator(client, scheme, ports)
+	}
+
+	// nil leaves the Pod Security Admission labels of namespaces untouched.
+	var namespaceLabelerAPI podsecurity.NamespaceLabeler
+
+	if privilegedPodNamespaces != "" {
+		namespaceLabelerAPI = podsecurity.NewNamespaceLabeler(client, commaSeparatedList(privilegedPodNamespaces)...)
+	}
+
+	// nil does not record the decisions of Module reconciliations.
+	var decisionsAPI decisions.Recorder
+
+	if recordDecisions {
+		decisionsAPI = decisions.NewRecorder()
+
+		// The metrics server is only reachable through the authenticating proxy.
+		if err = mgr.AddMetricsExtraHandler(decisionsPath, decisionsAPI); err != nil {
+			cmd.FatalError(setupLogger, err, "unable to serve the reconcile decisions")
+		}
+	}
+
+	// nil does not resolve ImageStreamTags in kernel mappings.
+	var imageStreamAPI imagestream.Resolver
+
+	if resolveImageStreams {
+		imageStreamAPI = imagestream.NewResolver(client)
+	}
+
 	mc := controllers.NewModuleReconciler(
 		client,
 		buildAPI,
 		signAPI,
 		rbac.NewCreator(client, scheme),
+		networkPolicyAPI,
+		namespaceLabelerAPI,
 		daemonAPI,
+		provenance.NewVerifier(client, registryAPI),
+		validation.NewValidator(
+			commaSeparatedList(devicePluginHostPaths),
+			commaSeparatedList(moduleNamespaces),
+			commaSeparatedList(imageRepositories),
+		),
 		kernelAPI,
 		metricsAPI,
 		filterAPI,
-		statusupdater.NewModuleStatusUpdater(client, metricsAPI),
+		statusupdater.NewModuleStatusUpdater(statusClient, metricsAPI),
+		utils.NewJobLogTailer(client, clientset),
+		mgr.GetEventRecorderFor(controllers.ModuleReconcilerName),
+		decisionsAPI,
+		imageStreamAPI,
+		fipsMode,
+		dryRun,
 	)
 
-	if err = mc.SetupWithManager(mgr, constants.KernelLabel); err != nil {
+	kernelLabels := append([]string{constants.KernelLabel}, commaSeparatedList(extraKernelLabels)...)
+
+	if err = mc.SetupWithManager(mgr, kernelLabels, s, controllerOpts.ControllerOptions()); err != nil {
 		cmd.FatalError(setupLogger, err, "unable to create controller", "name", controllers.ModuleReconcilerName)
 	}
 
-	nodeKernelReconciler := controllers.NewNodeKernelReconciler(client, constants.KernelLabel, filterAPI)
+	// Nodes are not namespaced: they are handled by the first shard only.
+	if s.IsFirst() {
+		nodeKernelReconciler := controllers.NewNodeKernelReconciler(client, constants.KernelLabel, filterAPI)
 
-	if err = nodeKernelReconciler.SetupWithManager(mgr); err != nil {
-		cmd.FatalError(setupLogger, err, "unable to create controller", "name", controllers.NodeKernelReconcilerName)
+		if err = nodeKernelReconciler.SetupWithManager(mgr, controllerOpts.ControllerOptions()); err != nil {
+			cmd.FatalError(setupLogger, err, "unable to create controller", "name", controllers.NodeKernelReconcilerName)
+		}
+
+		if err = controllers.NewNodeReadinessGroupReconciler(client, filterAPI).SetupWithManager(mgr, controllerOpts.ControllerOptions()); err != nil {
+			cmd.FatalError(setupLogger, err, "unable to create controller", "name", controllers.NodeReadinessGroupReconcilerName)
+		}
+
+		if nodePoolImages {
+			imagePublisher := nodepool.NewImagePublisher(
+				client,
+				kernelAPI,
+				types.NamespacedName{Namespace: os.Getenv(operatorNamespaceEnvVar), Name: nodePoolImagesConfigMap},
+				nodePoolImagesPeriod,
+				logger.WithName("node-pool-images"),
+			)
+
+			if err = mgr.Add(imagePublisher); err != nil {
+				cmd.FatalError(setupLogger, err, "unable to add the node pool image publisher")
+			}
+		}
+
+		if pinImages {
+			imagePinner := pinning.NewImagePinner(
+				client,
+				kernelAPI,
+				os.Getenv(operatorNamespaceEnvVar),
+				nodeInstallerImage,
+				pinnedImagesPeriod,
+				logger.WithName("image-pinner"),
+			)
+
+			if err = mgr.Add(imagePinner); err != nil {
+				cmd.FatalError(setupLogger, err, "unable to add the image pinner")
+			}
+		} else {
+			// Lift the pins of a previous run of the operator, if its ConfigMap is left.
+			uninstaller := pinning.NewUninstaller(
+				client,
+				os.Getenv(operatorNamespaceEnvVar),
+				pinnedImagesPeriod,
+				logger.WithName("image-pinner"),
+			)
+
+			if err = mgr.Add(uninstaller); err != nil {
+				cmd.FatalError(setupLogger, err, "unable to add the pinned images uninstaller")
+			}
+		}
+
+		if imageRetention.MaxAbsence > 0 {
+			// Registries are not affected by the dry-run client.
+			imageRetention.DryRun = imageRetention.DryRun || dryRun
+
+			// The retention records are written even in dry-run mode, so that the absence of kernels is measured.
+			imagePruner := retention.NewImagePruner(
+				statusClient,
+				kernelAPI,
+				registryAPI,
+				metricsAPI,
+				os.Getenv(operatorNamespaceEnvVar),
+				imageRetention,
+				imagePruningPeriod,
+				logger.WithName("image-pruner"),
+			)
+
+			if err = mgr.Add(imagePruner); err != nil {
+				cmd.FatalError(setupLogger, err, "unable to add the image pruner")
+			}
+		}
+
+		if sweepOrphans {
+			if err = mgr.Add(orphans.NewSweeper(client, scheme, orphanSweepPeriod, logger.WithName("orphan-sweeper"))); err != nil {
+				cmd.FatalError(setupLogger, err, "unable to add the orphan sweeper")
+			}
+		}
+
+		if registryCAConfigMap != "" {
+			installerCreator := registryca.NewInstallerCreator(
+				client,
+				os.Getenv(operatorNamespaceEnvVar),
+				registryCAConfigMap,
+				nodeInstallerImage,
+				logger.WithName("registry-ca-installer"),
+			)
+
+			if err = mgr.Add(installerCreator); err != nil {
+				cmd.FatalError(setupLogger, err, "unable to add the registry CA installer creator")
+			}
+		} else {
+			// Remove the bundles installed by a previous run of the operator, if any.
+			uninstaller := registryca.NewUninstaller(
+				client,
+				os.Getenv(operatorNamespaceEnvVar),
+				registryCAUninstallPeriod,
+				logger.WithName("registry-ca-installer"),
+			)
+
+			if err = mgr.Add(uninstaller); err != nil {
+				cmd.FatalError(setupLogger, err, "unable to add the registry CA uninstaller")
+			}
+		}
+
+		if startupTaint {
+			if err = controllers.NewNodeStartupTaintReconciler(client, kernelAPI, filterAPI).SetupWithManager(mgr, controllerOpts.ControllerOptions()); err != nil {
+				cmd.FatalError(setupLogger, err, "unable to create controller", "name", controllers.NodeStartupTaintReconcilerName)
+			}
+		}
+
+		if loadFailureThreshold > 0 {
+			nmlfr := controllers.NewNodeModuleLoadFailureReconciler(client, int32(loadFailureThreshold), loadFailureCordon)
+
+			if err = nmlfr.SetupWithManager(mgr, controllerOpts.ControllerOptions()); err != nil {
+				cmd.FatalError(setupLogger, err, "unable to create controller", "name", controllers.NodeModuleLoadFailureReconcilerName)
+			}
+		}
 	}
 
-	if err = controllers.NewPodNodeModuleReconciler(client, daemonAPI).SetupWithManager(mgr); err != nil {
+	mlrr, err := controllers.NewModuleLoadRejectionReconciler(
+		client,
+		mgr.GetEventRecorderFor(controllers.ModuleLoadRejectionReconcilerName),
+		loadRejectionAction,
+	)
+	if err != nil {
+		cmd.FatalError(setupLogger, err, "invalid module load rejection action")
+	}
+
+	if err = mlrr.SetupWithManager(mgr, s, controllerOpts.ControllerOptions()); err != nil {
+		cmd.FatalError(setupLogger, err, "unable to create controller", "name", controllers.ModuleLoadRejectionReconcilerName)
+	}
+
+	if err = controllers.NewPodNodeModuleReconciler(client, daemonAPI, nodeCleanupJobs).SetupWithManager(mgr, s, controllerOpts.ControllerOptions()); err != nil {
 		cmd.FatalError(setupLogger, err, "unable to create controller", "name", controllers.PodNodeModuleReconcilerName)
 	}
 
-	preflightStatusUpdaterAPI := statusupdater.NewPreflightStatusUpdater(client)
+	if err = controllers.NewModuleRebootReconciler(client).SetupWithManager(mgr, s, controllerOpts.ControllerOptions()); err != nil {
+		cmd.FatalError(setupLogger, err, "unable to create controller", "name", controllers.ModuleRebootReconcilerName)
+	}
+
+	if err = controllers.NewDependentDaemonSetReconciler(client).SetupWithManager(mgr, s, controllerOpts.ControllerOptions()); err != nil {
+		cmd.FatalError(setupLogger, err, "unable to create controller", "name", controllers.DependentDaemonSetReconcilerName)
+	}
+
+	if sriovPolicies {
+		if err = controllers.NewDependentSriovPolicyReconciler(client).SetupWithManager(mgr, s, controllerOpts.ControllerOptions()); err != nil {
+			cmd.FatalError(setupLogger, err, "unable to create controller", "name", controllers.DependentSriovPolicyReconcilerName)
+		}
+	}
+
+	preflightStatusUpdaterAPI := statusupdater.NewPreflightStatusUpdater(statusClient)
 	preflightAPI := preflight.NewPreflightAPI(client, buildAPI, signAPI, registryAPI, preflightStatusUpdaterAPI, kernelAPI)
 
-	if err = controllers.NewPreflightValidationReconciler(client, filterAPI, preflightStatusUpdaterAPI, preflightAPI).SetupWithManager(mgr); err != nil {
+	if err = controllers.NewPreflightValidationReconciler(client, filterAPI, preflightStatusUpdaterAPI, preflightAPI).SetupWithManager(mgr, s, controllerOpts.ControllerOptions()); err != nil {
 		cmd.FatalError(setupLogger, err, "unable to create controller", "name", controllers.PreflightValidationReconcilerName)
 	}
 
-	if managed {
+	if clusterAPIPreflight {
+		if err = controllers.NewMachineDeploymentReconciler(client).SetupWithManager(mgr, s, controllerOpts.ControllerOptions()); err != nil {
+			cmd.FatalError(setupLogger, err, "unable to create controller", "name", controllers.MachineDeploymentReconcilerName)
+		}
+	}
+
+	// The operator runs under OLM if it has an OperatorCondition.
+	if operatorConditionName := os.Getenv(operatorConditionNameEnvVar); operatorConditionName != "" && s.IsFirst() {
+		operatorConditionNSN := types.NamespacedName{
+			Namespace: os.Getenv(operatorNamespaceEnvVar),
+			Name:      operatorConditionName,
+		}
+
+		if err = controllers.NewOperatorConditionReconciler(client, operatorConditionNSN).SetupWithManager(mgr, controllerOpts.ControllerOptions()); err != nil {
+			cmd.FatalError(setupLogger, err, "unable to create controller", "name", controllers.OperatorConditionReconcilerName)
+		}
+	}
+
+	if managed && s.IsFirst() {
 		setupLogger.Info("Starting as managed")
 
 		if err = clusterv1.Install(scheme); err != nil {
 			cmd.FatalError(setupLogger, err, "could not add the Cluster API to the scheme")
 		}
 
-		if err = controllers.NewNodeKernelClusterClaimReconciler(client).SetupWithManager(mgr); err != nil {
+		if err = controllers.NewNodeKernelClusterClaimReconciler(client).SetupWithManager(mgr, controllerOpts.ControllerOptions()); err != nil {
 			cmd.FatalError(setupLogger, err, "unable to create controller", "name", controllers.NodeKernelClusterClaimReconcilerName)
 		}
 	}
@@ -205,3 +838,16 @@ func GetBoolEnv(s string) (bool, error) {
 
 	return managed, nil
 }
+
+// commaSeparatedList splits the comma-separated list s, ignoring empty items.
+func commaSeparatedList(s string) []string {
+	items := make([]string, 0)
+
+	for _, i := range strings.Split(s, ",") {
+		if i = strings.TrimSpace(i); i != "" {
+			items = append(items, i)
+		}
+	}
+
+	return items
+}
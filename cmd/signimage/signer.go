@@ -0,0 +1,313 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+/*
+** The signer keeps the private key away from the container that pulls and unpacks the (user-provided) image.
+** It runs in its own container, reads the key from an environment variable into an anonymous in-memory file, and
+** signs the kmods extracted to the shared workdir when asked to over a unix socket.
+** The key is handed to sign-file as an inherited file descriptor, so it is never written to the pod's filesystem.
+** The signing container pings the signer while it runs; the signer gives up if it is not contacted for
+** signerIdleTimeout, so that it does not outlive a signing container that died without asking it to shut down.
+ */
+
+const (
+	signingKeyEnvVar = "SIGNING_KEY"
+
+	// the in-memory key file is passed to sign-file as its first extra file descriptor
+	signingKeyPath = "/proc/self/fd/3"
+
+	signerReadyTimeout = 2 * time.Minute
+
+	signerIdleTimeout       = 5 * time.Minute
+	signerKeepaliveInterval = 30 * time.Second
+)
+
+type signRequest struct {
+	Path string `json:"path"`
+}
+
+type signerServer struct {
+	workdir    string
+	pubKeyFile string
+	key        *os.File
+	done       chan bool
+}
+
+func loadSigningKey() (*os.File, error) {
+	keyData := os.Getenv(signingKeyEnvVar)
+	if keyData == "" {
+		return nil, fmt.Errorf("%s is not set", signingKeyEnvVar)
+	}
+
+	if err := os.Unsetenv(signingKeyEnvVar); err != nil {
+		return nil, fmt.Errorf("could not unset %s: %v", signingKeyEnvVar, err)
+	}
+
+	fd, err := unix.MemfdCreate("signing-key", unix.MFD_CLOEXEC)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the in-memory key file: %v", err)
+	}
+
+	f := os.NewFile(uintptr(fd), "signing-key")
+
+	if _, err = f.WriteString(keyData); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("could not write the in-memory key file: %v", err)
+	}
+
+	return f, nil
+}
+
+func serveSigner(socketPath string, workdir string, pubKeyFile string) error {
+	key, err := loadSigningKey()
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+
+	// a previous instance of this container may have left its socket behind
+	if err = os.Remove(socketPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("could not remove stale socket %s: %v", socketPath, err)
+	}
+
+	l, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("could not listen on %s: %v", socketPath, err)
+	}
+
+	s := &signerServer{
+		workdir:    filepath.Clean(workdir),
+		pubKeyFile: pubKeyFile,
+		key:        key,
+		done:       make(chan bool, 1),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {})
+	mux.HandleFunc("/sign", s.handleSign)
+	mux.HandleFunc("/shutdown", s.handleShutdown)
+
+	idleTimer := time.AfterFunc(signerIdleTimeout, func() {
+		logger.Info("Signer not contacted in time; giving up", "timeout", signerIdleTimeout)
+
+		select {
+		case s.done <- true:
+		default:
+		}
+	})
+	defer idleTimer.Stop()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idleTimer.Reset(signerIdleTimeout)
+		mux.ServeHTTP(w, r)
+	})
+
+	srv := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := srv.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error(err, "signer stopped serving")
+			s.done <- true
+		}
+	}()
+
+	logger.Info("Signer listening", "socket", socketPath)
+
+	failed := <-s.done
+
+	if err = srv.Shutdown(context.Background()); err != nil {
+		logger.Error(err, "could not shut the signer down")
+	}
+
+	if failed {
+		return errors.New("signimage did not complete successfully")
+	}
+
+	return nil
+}
+
+func (s *signerServer) handleSign(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req signRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("could not decode the request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	filename, err := s.checkPath(req.Path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err = signFileWithKeyFile(filename, s.pubKeyFile, s.key); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (s *signerServer) handleShutdown(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	select {
+	case s.done <- r.URL.Query().Get("failed") == "true":
+	default:
+	}
+}
+
+// checkPath makes sure that only regular files extracted to the workdir are signed.
+func (s *signerServer) checkPath(p string) (string, error) {
+	p = filepath.Clean(p)
+
+	if !strings.HasPrefix(p, s.workdir+"/") {
+		return "", fmt.Errorf("%s is not in %s", p, s.workdir)
+	}
+
+	resolved, err := filepath.EvalSymlinks(p)
+	if err != nil {
+		return "", fmt.Errorf("could not resolve %s: %v", p, err)
+	}
+
+	if resolved != p {
+		return "", fmt.Errorf("%s must not contain symbolic links", p)
+	}
+
+	fi, err := os.Lstat(p)
+	if err != nil {
+		return "", fmt.Errorf("could not stat %s: %v", p, err)
+	}
+
+	if !fi.Mode().IsRegular() {
+		return "", fmt.Errorf("%s is not a regular file", p)
+	}
+
+	return p, nil
+}
+
+func signFileWithKeyFile(filename string, publickey string, key *os.File) error {
+	logger.Info("running /sign-file", "algo", "sha256", "publickey", publickey, "filename", filepath.Base(filename))
+
+	cmd := exec.Command("/sign-file", "sha256", signingKeyPath, publickey, filename)
+	cmd.ExtraFiles = []*os.File{key}
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("signing %s returned: %s\n error: %v\n", filename, out, err)
+	}
+	return nil
+}
+
+// signerClient asks the signer container to sign kmods.
+type signerClient struct {
+	httpClient *http.Client
+}
+
+func newSignerClient(socketPath string) *signerClient {
+	return &signerClient{
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", socketPath)
+				},
+			},
+		},
+	}
+}
+
+func (sc *signerClient) post(path string, body io.Reader) error {
+	res, err := sc.httpClient.Post("http://signer"+path, "application/json", body)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("signer returned %s: %s", res.Status, strings.TrimSpace(string(msg)))
+	}
+
+	return nil
+}
+
+// waitReady waits for the signer container to listen on its socket.
+func (sc *signerClient) waitReady(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		res, err := sc.httpClient.Get("http://signer/healthz")
+		if err == nil {
+			res.Body.Close()
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("signer not ready after %v: %v", timeout, err)
+		}
+
+		time.Sleep(time.Second)
+	}
+}
+
+// keepalive pings the signer until stop is closed, so that it does not time out while this container pulls or walks
+// the image.
+func (sc *signerClient) keepalive(stop <-chan struct{}) {
+	ticker := time.NewTicker(signerKeepaliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			res, err := sc.httpClient.Get("http://signer/healthz")
+			if err != nil {
+				logger.Error(err, "could not ping the signer")
+				continue
+			}
+			res.Body.Close()
+		}
+	}
+}
+
+func (sc *signerClient) sign(filename string) error {
+	b, err := json.Marshal(signRequest{Path: filename})
+	if err != nil {
+		return err
+	}
+
+	return sc.post("/sign", bytes.NewReader(b))
+}
+
+// shutdown stops the signer container; it exits with an error if failed is true, so that it is restarted along with
+// this container.
+func (sc *signerClient) shutdown(failed bool) {
+	if err := sc.post(fmt.Sprintf("/shutdown?failed=%t", failed), nil); err != nil {
+		logger.Error(err, "could not stop the signer")
+	}
+}
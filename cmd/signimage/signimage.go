@@ -3,12 +3,15 @@ package main
 import (
 	"archive/tar"
 	"bytes"
+	"context"
 	"flag"
 	"fmt"
 	"github.com/docker/cli/cli/config"
 	dockertypes "github.com/docker/cli/cli/config/types"
 	"github.com/go-logr/logr"
 	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/auth"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/registry"
 	"io"
 	"k8s.io/klog/v2/klogr"
@@ -51,6 +54,20 @@ func signFile(filename string, publickey string, privatekey string) error {
 	return nil
 }
 
+func getAuthFromWorkloadIdentity(repo string) (authn.Authenticator, error) {
+	reg, err := name.NewRegistry(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	keychain, err := auth.NewWorkloadIdentityAuthGetter().GetKeyChain(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	return keychain.Resolve(reg)
+}
+
 func getAuthFromFile(configfile string, repo string) (authn.Authenticator, error) {
 
 	if configfile == "" {
@@ -85,7 +102,11 @@ func getAuthFromFile(configfile string, repo string) (authn.Authenticator, error
 
 }
 
+// stopSigner is called before exiting, to stop the signer container if there is one.
+var stopSigner = func(failed bool) {}
+
 func die(exitval int, message string, err error) {
+	stopSigner(true)
 	fmt.Fprintf(os.Stderr, "\n%s\n", message)
 	logger.Info("ERROR "+message, "err", err)
 	logger.Error(err, message)
@@ -97,9 +118,8 @@ func processFile(filename string, header *tar.Header, tarreader io.Reader, data
 	registryObj := data[0].(registry.Registry)
 	extractionDir := data[1].(string)
 	filesList := data[2].(string)
-	privKeyFile := data[3].(string)
-	pubKeyFile := data[4].(string)
-	kmodsToSign := data[5].(map[string]string)
+	sign := data[3].(func(string) error)
+	kmodsToSign := data[4].(map[string]string)
 
 	canonfilename := canonicalisePath(filename)
 
@@ -120,7 +140,7 @@ func processFile(filename string, header *tar.Header, tarreader io.Reader, data
 		logger.Info("Signing kmod", "kmod", canonfilename)
 
 		//sign it
-		err = signFile(kmodsToSign[canonfilename], pubKeyFile, privKeyFile)
+		err = sign(kmodsToSign[canonfilename])
 		if err != nil {
 			return fmt.Errorf("error signing file %s", canonfilename)
 		}
@@ -178,6 +198,10 @@ func main() {
 	var privKeyFile string
 	var pubKeyFile string
 	var nopush bool
+	var workloadIdentity bool
+	var serve bool
+	var signerSocket string
+	var workdir string
 
 	logger = klogr.New()
 
@@ -189,20 +213,57 @@ func main() {
 	flag.StringVar(&pullSecret, "pullsecret", "", "path to file containing credentials for pulling images")
 	flag.StringVar(&pullSecret, "pushsecret", "", "path to file containing credentials for pushing images")
 	flag.BoolVar(&nopush, "no-push", false, "do not push the resulting image")
+	flag.BoolVar(&workloadIdentity, "workload-identity", false, "authenticate to ECR, ACR or GCR with the pod's workload identity")
+	flag.BoolVar(&serve, "serve", false, "run as a signer, reading the private key from $"+signingKeyEnvVar+" and serving on -signer-socket")
+	flag.StringVar(&signerSocket, "signer-socket", "", "path to the unix socket of the signer, used instead of -key")
+	flag.StringVar(&workdir, "workdir", "/tmp/", "directory to extract the kmods to")
 
 	flag.Parse()
 
+	if serve {
+		checkArg(&signerSocket, "signer-socket", "")
+		checkArg(&pubKeyFile, "cert", "")
+
+		if err = serveSigner(signerSocket, workdir, pubKeyFile); err != nil {
+			logger.Error(err, "signer failed")
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	checkArg(&unsignedImageName, "unsignedimage", "")
 	checkArg(&signedImageName, "signedimage", unsignedImageName+"signed")
 	checkArg(&filesList, "filestosign", "")
-	checkArg(&privKeyFile, "key", "")
-	checkArg(&pubKeyFile, "cert", "")
-	checkArg(&pullSecret, "pullsecret", "")
-	checkArg(&pushSecret, "pushsecret", pullSecret)
+
+	var sign func(string) error
+
+	if signerSocket != "" {
+		sc := newSignerClient(signerSocket)
+		if err = sc.waitReady(signerReadyTimeout); err != nil {
+			die(1, "could not reach the signer", err)
+		}
+		stopKeepalive := make(chan struct{})
+		go sc.keepalive(stopKeepalive)
+		stopSigner = func(failed bool) {
+			close(stopKeepalive)
+			sc.shutdown(failed)
+		}
+		sign = sc.sign
+	} else {
+		checkArg(&privKeyFile, "key", "")
+		checkArg(&pubKeyFile, "cert", "")
+		sign = func(filename string) error {
+			return signFile(filename, pubKeyFile, privKeyFile)
+		}
+	}
+	if !workloadIdentity {
+		checkArg(&pullSecret, "pullsecret", "")
+		checkArg(&pushSecret, "pushsecret", pullSecret)
+	}
 	// if we've made it this far the arguments are sane
 
 	// get a temp dir to copy kmods into for signing
-	extractionDir, err = os.MkdirTemp("/tmp/", "kmod_signer")
+	extractionDir, err = os.MkdirTemp(workdir, "kmod_signer")
 	if err != nil {
 		die(1, "could not create temp dir", err)
 	}
@@ -222,7 +283,14 @@ func main() {
 		kmodsToSign[x] = "not found"
 	}
 
-	a, err := getAuthFromFile(pullSecret, strings.Split(unsignedImageName, "/")[0])
+	getAuth := getAuthFromFile
+	if workloadIdentity {
+		getAuth = func(_ string, repo string) (authn.Authenticator, error) {
+			return getAuthFromWorkloadIdentity(repo)
+		}
+	}
+
+	a, err := getAuth(pullSecret, strings.Split(unsignedImageName, "/")[0])
 	if err != nil {
 		die(2, "failed to get auth", err)
 	}
@@ -240,7 +308,7 @@ func main() {
 	/*
 	** loop through all the layers in the image from the top down
 	 */
-	err = r.WalkFilesInImage(img, processFile, r, extractionDir, filesList, privKeyFile, pubKeyFile, kmodsToSign)
+	err = r.WalkFilesInImage(img, processFile, r, extractionDir, filesList, sign, kmodsToSign)
 	if err != nil {
 		die(9, "failed to search image", err)
 	}
@@ -280,7 +348,7 @@ func main() {
 	logger.Info("Appended new layer to image", "image", signedImageName)
 
 	if !nopush {
-		a, err = getAuthFromFile(pushSecret, strings.Split(signedImageName, "/")[0])
+		a, err = getAuth(pushSecret, strings.Split(signedImageName, "/")[0])
 		if err != nil {
 			die(7, "failed to get push auth", err)
 		}
@@ -293,5 +361,6 @@ func main() {
 		// we're done successfully, so we need a nice friendly message to say that
 		logger.Info("Pushed image back to repo", "image", signedImageName)
 	}
+	stopSigner(false)
 	os.Exit(0)
 }
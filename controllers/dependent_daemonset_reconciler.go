@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/daemonset"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/filter"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/shard"
+)
+
+const DependentDaemonSetReconcilerName = "DependentDaemonSet"
+
+// DependentDaemonSetReconciler restricts the DaemonSets that list Modules in their WaitForModulesAnnotation to the
+// nodes on which the module-loaders of these Modules are ready, so that their pods only start once the kernel modules
+// are loaded.
+type DependentDaemonSetReconciler struct {
+	client client.Client
+}
+
+func NewDependentDaemonSetReconciler(client client.Client) *DependentDaemonSetReconciler {
+	return &DependentDaemonSetReconciler{client: client}
+}
+
+func (r *DependentDaemonSetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	ds := appsv1.DaemonSet{}
+
+	if err := r.client.Get(ctx, req.NamespacedName, &ds); err != nil {
+		if k8serrors.IsNotFound(err) {
+			logger.Info("DaemonSet not found")
+			return ctrl.Result{}, nil
+		}
+
+		return ctrl.Result{}, fmt.Errorf("could not get DaemonSet %s: %v", req.NamespacedName, err)
+	}
+
+	nodeSelector := dependentNodeSelector(ds.Spec.Template.Spec.NodeSelector, ds.Annotations[constants.WaitForModulesAnnotation])
+
+	if labels.Equals(nodeSelector, ds.Spec.Template.Spec.NodeSelector) {
+		return ctrl.Result{}, nil
+	}
+
+	logger.Info("Patching the node selector", "node selector", nodeSelector)
+
+	p := client.MergeFrom(ds.DeepCopy())
+
+	ds.Spec.Template.Spec.NodeSelector = nodeSelector
+
+	if err := r.client.Patch(ctx, &ds, p); err != nil {
+		return ctrl.Result{}, fmt.Errorf("could not patch DaemonSet %s: %v", req.NamespacedName, err)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// dependentNodeSelector returns nodeSelector with the ready labels of the Modules listed in modules, separated by
+// commas, instead of the ready labels that it already has.
+func dependentNodeSelector(nodeSelector map[string]string, modules string) map[string]string {
+	ns := make(map[string]string, len(nodeSelector))
+
+	for k, v := range nodeSelector {
+		if !daemonset.IsModuleReadyNodeLabel(k) {
+			ns[k] = v
+		}
+	}
+
+	for _, name := range strings.Split(modules, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			ns[daemonset.ModuleReadyNodeLabel(name)] = ""
+		}
+	}
+
+	return ns
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// Only the DaemonSets in the namespaces of s are reconciled.
+func (r *DependentDaemonSetReconciler) SetupWithManager(mgr ctrl.Manager, s *shard.Shard, opts controller.Options) error {
+	return ctrl.
+		NewControllerManagedBy(mgr).
+		Named(DependentDaemonSetReconcilerName).
+		For(&appsv1.DaemonSet{}).
+		WithEventFilter(
+			predicate.And(
+				filter.HasAnnotation(constants.WaitForModulesAnnotation),
+				s.Predicate(),
+			),
+		).
+		WithOptions(opts).
+		Complete(r)
+}
@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mock_client "github.com/kubernetes-sigs/kernel-module-management/internal/client"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+)
+
+var _ = Describe("DependentDaemonSetReconciler", func() {
+	var (
+		kubeClient *mock_client.MockClient
+		r          *DependentDaemonSetReconciler
+	)
+
+	BeforeEach(func() {
+		ctrl := gomock.NewController(GinkgoT())
+		kubeClient = mock_client.NewMockClient(ctrl)
+		r = NewDependentDaemonSetReconciler(kubeClient)
+	})
+
+	ctx := context.Background()
+	nn := types.NamespacedName{Namespace: "gpu-operator", Name: "nvidia-device-plugin"}
+	req := ctrl.Request{NamespacedName: nn}
+
+	expectGet := func(modules string, nodeSelector map[string]string) *gomock.Call {
+		return kubeClient.
+			EXPECT().
+			Get(ctx, nn, gomock.AssignableToTypeOf(&appsv1.DaemonSet{})).
+			Do(func(_ context.Context, _ types.NamespacedName, ds *appsv1.DaemonSet, _ ...client.GetOption) {
+				ds.ObjectMeta = metav1.ObjectMeta{
+					Name:        nn.Name,
+					Namespace:   nn.Namespace,
+					Annotations: map[string]string{constants.WaitForModulesAnnotation: modules},
+				}
+				ds.Spec.Template.Spec.NodeSelector = nodeSelector
+			})
+	}
+
+	It("should add the ready labels of the listed Modules to the node selector", func() {
+		gomock.InOrder(
+			expectGet("nvidia, nvidia-peermem", map[string]string{"nvidia.com/gpu.present": "true"}),
+			kubeClient.EXPECT().Patch(ctx, gomock.Any(), gomock.Any()).Do(
+				func(_ context.Context, ds *appsv1.DaemonSet, _ client.Patch, _ ...client.PatchOption) {
+					Expect(ds.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{
+						"nvidia.com/gpu.present":                      "true",
+						"kmm.node.kubernetes.io/nvidia.ready":         "",
+						"kmm.node.kubernetes.io/nvidia-peermem.ready": "",
+					}))
+				},
+			),
+		)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should remove the ready labels of the Modules that are not listed anymore", func() {
+		gomock.InOrder(
+			expectGet(
+				"nvidia",
+				map[string]string{
+					"kmm.node.kubernetes.io/nvidia.ready":         "",
+					"kmm.node.kubernetes.io/nvidia-peermem.ready": "",
+				},
+			),
+			kubeClient.EXPECT().Patch(ctx, gomock.Any(), gomock.Any()).Do(
+				func(_ context.Context, ds *appsv1.DaemonSet, _ client.Patch, _ ...client.PatchOption) {
+					Expect(ds.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{
+						"kmm.node.kubernetes.io/nvidia.ready": "",
+					}))
+				},
+			),
+		)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should not patch the DaemonSet if its node selector is up to date", func() {
+		expectGet("nvidia", map[string]string{"kmm.node.kubernetes.io/nvidia.ready": ""})
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
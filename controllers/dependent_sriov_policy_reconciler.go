@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/filter"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/shard"
+)
+
+const DependentSriovPolicyReconcilerName = "DependentSriovNetworkNodePolicy"
+
+// sriovNetworkNodePolicyGVK is the GroupVersionKind of the node policies of the SR-IOV network operator.
+var sriovNetworkNodePolicyGVK = schema.GroupVersionKind{
+	Group:   "sriovnetwork.openshift.io",
+	Version: "v1",
+	Kind:    "SriovNetworkNodePolicy",
+}
+
+// DependentSriovPolicyReconciler restricts the SriovNetworkNodePolicies that list Modules in their
+// WaitForModulesAnnotation to the nodes on which the module-loaders of these Modules are ready, so that the SR-IOV
+// network operator only configures virtual functions once the NIC driver is loaded.
+type DependentSriovPolicyReconciler struct {
+	client client.Client
+}
+
+func NewDependentSriovPolicyReconciler(client client.Client) *DependentSriovPolicyReconciler {
+	return &DependentSriovPolicyReconciler{client: client}
+}
+
+//+kubebuilder:rbac:groups=sriovnetwork.openshift.io,resources=sriovnetworknodepolicies,verbs=get;list;watch;patch
+
+func (r *DependentSriovPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	policy := unstructured.Unstructured{}
+	policy.SetGroupVersionKind(sriovNetworkNodePolicyGVK)
+
+	if err := r.client.Get(ctx, req.NamespacedName, &policy); err != nil {
+		if k8serrors.IsNotFound(err) {
+			logger.Info("SriovNetworkNodePolicy not found")
+			return ctrl.Result{}, nil
+		}
+
+		return ctrl.Result{}, fmt.Errorf("could not get SriovNetworkNodePolicy %s: %v", req.NamespacedName, err)
+	}
+
+	currentSelector, _, err := unstructured.NestedStringMap(policy.Object, "spec", "nodeSelector")
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("could not read the node selector of SriovNetworkNodePolicy %s: %v", req.NamespacedName, err)
+	}
+
+	nodeSelector := dependentNodeSelector(currentSelector, policy.GetAnnotations()[constants.WaitForModulesAnnotation])
+
+	if labels.Equals(nodeSelector, currentSelector) {
+		return ctrl.Result{}, nil
+	}
+
+	logger.Info("Patching the node selector", "node selector", nodeSelector)
+
+	p := client.MergeFrom(policy.DeepCopy())
+
+	if err = unstructured.SetNestedStringMap(policy.Object, nodeSelector, "spec", "nodeSelector"); err != nil {
+		return ctrl.Result{}, fmt.Errorf("could not set the node selector of SriovNetworkNodePolicy %s: %v", req.NamespacedName, err)
+	}
+
+	if err = r.client.Patch(ctx, &policy, p); err != nil {
+		return ctrl.Result{}, fmt.Errorf("could not patch SriovNetworkNodePolicy %s: %v", req.NamespacedName, err)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// Only the SriovNetworkNodePolicies in the namespaces of s are reconciled.
+func (r *DependentSriovPolicyReconciler) SetupWithManager(mgr ctrl.Manager, s *shard.Shard, opts controller.Options) error {
+	policy := unstructured.Unstructured{}
+	policy.SetGroupVersionKind(sriovNetworkNodePolicyGVK)
+
+	return ctrl.
+		NewControllerManagedBy(mgr).
+		Named(DependentSriovPolicyReconcilerName).
+		For(&policy).
+		WithEventFilter(
+			predicate.And(
+				filter.HasAnnotation(constants.WaitForModulesAnnotation),
+				s.Predicate(),
+			),
+		).
+		WithOptions(opts).
+		Complete(r)
+}
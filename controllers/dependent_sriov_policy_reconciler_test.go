@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mock_client "github.com/kubernetes-sigs/kernel-module-management/internal/client"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+)
+
+var _ = Describe("DependentSriovPolicyReconciler", func() {
+	var (
+		kubeClient *mock_client.MockClient
+		r          *DependentSriovPolicyReconciler
+	)
+
+	BeforeEach(func() {
+		ctrl := gomock.NewController(GinkgoT())
+		kubeClient = mock_client.NewMockClient(ctrl)
+		r = NewDependentSriovPolicyReconciler(kubeClient)
+	})
+
+	ctx := context.Background()
+	nn := types.NamespacedName{Namespace: "sriov-network-operator", Name: "policy-mlx"}
+	req := ctrl.Request{NamespacedName: nn}
+
+	expectGet := func(modules string, nodeSelector map[string]interface{}) *gomock.Call {
+		return kubeClient.
+			EXPECT().
+			Get(ctx, nn, gomock.AssignableToTypeOf(&unstructured.Unstructured{})).
+			Do(func(_ context.Context, _ types.NamespacedName, policy *unstructured.Unstructured, _ ...client.GetOption) {
+				policy.Object["spec"] = map[string]interface{}{
+					"nodeSelector": nodeSelector,
+					"resourceName": "mlxnics",
+				}
+				policy.SetName(nn.Name)
+				policy.SetNamespace(nn.Namespace)
+				policy.SetAnnotations(map[string]string{constants.WaitForModulesAnnotation: modules})
+			})
+	}
+
+	It("should add the ready labels of the listed Modules to the node selector", func() {
+		gomock.InOrder(
+			expectGet("mlx5-core", map[string]interface{}{"feature.node.kubernetes.io/network-sriov.capable": "true"}),
+			kubeClient.EXPECT().Patch(ctx, gomock.Any(), gomock.Any()).Do(
+				func(_ context.Context, policy *unstructured.Unstructured, _ client.Patch, _ ...client.PatchOption) {
+					nodeSelector, _, err := unstructured.NestedStringMap(policy.Object, "spec", "nodeSelector")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(nodeSelector).To(Equal(map[string]string{
+						"feature.node.kubernetes.io/network-sriov.capable": "true",
+						"kmm.node.kubernetes.io/mlx5-core.ready":           "",
+					}))
+				},
+			),
+		)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should not patch the policy if its node selector is up to date", func() {
+		expectGet("mlx5-core", map[string]interface{}{"kmm.node.kubernetes.io/mlx5-core.ready": ""})
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
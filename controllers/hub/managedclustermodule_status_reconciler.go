@@ -0,0 +1,105 @@
+package hub
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	hubv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api-hub/v1beta1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	workv1 "open-cluster-management.io/api/work/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/manifestwork"
+)
+
+//+kubebuilder:rbac:groups=hub.kmm.sigs.x-k8s.io,resources=managedclustermodules,verbs=get;list;watch
+//+kubebuilder:rbac:groups=hub.kmm.sigs.x-k8s.io,resources=managedclustermodules/status,verbs=get;patch
+//+kubebuilder:rbac:groups=work.open-cluster-management.io,resources=manifestworks,verbs=list;watch
+
+const ManagedClusterModuleStatusReconcilerName = "ManagedClusterModuleStatus"
+
+// ManagedClusterModuleStatusReconciler aggregates in the status of ManagedClusterModules the status of their Module on
+// each managed cluster, which the work agents report through the status feedback of the ManifestWorks.
+type ManagedClusterModuleStatusReconciler struct {
+	client client.Client
+}
+
+func NewManagedClusterModuleStatusReconciler(client client.Client) *ManagedClusterModuleStatusReconciler {
+	return &ManagedClusterModuleStatusReconciler{client: client}
+}
+
+func (r *ManagedClusterModuleStatusReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	mcm := hubv1beta1.ManagedClusterModule{}
+
+	if err := r.client.Get(ctx, req.NamespacedName, &mcm); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+
+		return ctrl.Result{}, fmt.Errorf("could not get ManagedClusterModule %s: %v", req.Name, err)
+	}
+
+	mwList := workv1.ManifestWorkList{}
+
+	if err := r.client.List(ctx, &mwList, client.MatchingLabels{constants.ManagedClusterModuleNameLabel: mcm.Name}); err != nil {
+		return ctrl.Result{}, fmt.Errorf("could not list the ManifestWorks: %v", err)
+	}
+
+	status := hubv1beta1.ManagedClusterModuleStatus{
+		TotalClusters: int32(len(mwList.Items)),
+	}
+
+	for i := 0; i < len(mwList.Items); i++ {
+		cms, ok := manifestwork.ClusterModuleStatus(&mwList.Items[i])
+		if !ok {
+			continue
+		}
+
+		if cms.Phase == kmmv1beta1.KernelPhaseReady {
+			status.ReadyClusters++
+		}
+
+		status.Clusters = append(status.Clusters, cms)
+	}
+
+	sort.Slice(status.Clusters, func(i, j int) bool {
+		return status.Clusters[i].ClusterName < status.Clusters[j].ClusterName
+	})
+
+	if reflect.DeepEqual(status, mcm.Status) {
+		return ctrl.Result{}, nil
+	}
+
+	logger.Info("Updating the status", "ready clusters", status.ReadyClusters, "total clusters", status.TotalClusters)
+
+	mcmCopy := mcm.DeepCopy()
+	mcm.Status = status
+
+	if err := r.client.Status().Patch(ctx, &mcm, client.MergeFrom(mcmCopy)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("could not patch the status of ManagedClusterModule %s: %v", req.Name, err)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// ManagedClusterModules are reconciled when they are created and when their ManifestWorks change.
+func (r *ManagedClusterModuleStatusReconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&hubv1beta1.ManagedClusterModule{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Owns(&workv1.ManifestWork{}).
+		Named(ManagedClusterModuleStatusReconcilerName).
+		WithOptions(opts).
+		Complete(r)
+}
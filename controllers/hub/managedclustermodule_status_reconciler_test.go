@@ -0,0 +1,121 @@
+package hub
+
+import (
+	"context"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	workv1 "open-cluster-management.io/api/work/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/kubernetes-sigs/kernel-module-management/api-hub/v1beta1"
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/client"
+)
+
+var _ = Describe("ManagedClusterModuleStatusReconciler_Reconcile", func() {
+	const mcmName = "test-module"
+
+	var (
+		clnt        *client.MockClient
+		statusWrite *client.MockStatusWriter
+		r           *ManagedClusterModuleStatusReconciler
+	)
+
+	BeforeEach(func() {
+		ctrl := gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+		statusWrite = client.NewMockStatusWriter(ctrl)
+		r = NewManagedClusterModuleStatusReconciler(clnt)
+	})
+
+	ctx := context.Background()
+	nsn := types.NamespacedName{Name: mcmName}
+	req := reconcile.Request{NamespacedName: nsn}
+
+	manifestWork := func(clusterName, phase string) workv1.ManifestWork {
+		mw := workv1.ManifestWork{
+			ObjectMeta: metav1.ObjectMeta{Name: mcmName, Namespace: clusterName},
+		}
+
+		if phase != "" {
+			mw.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{
+				{
+					ResourceMeta: workv1.ManifestResourceMeta{Kind: "Module"},
+					StatusFeedbacks: workv1.StatusFeedbackResult{
+						Values: []workv1.FeedbackValue{
+							{Name: "phase", Value: workv1.FieldValue{Type: workv1.String, String: &phase}},
+						},
+					},
+				},
+			}
+		}
+
+		return mw
+	}
+
+	expectGet := func(status v1beta1.ManagedClusterModuleStatus) *gomock.Call {
+		return clnt.
+			EXPECT().
+			Get(ctx, nsn, gomock.AssignableToTypeOf(&v1beta1.ManagedClusterModule{})).
+			Do(func(_ context.Context, _ types.NamespacedName, mcm *v1beta1.ManagedClusterModule, _ ...ctrlclient.GetOption) {
+				mcm.Name = mcmName
+				mcm.Status = status
+			})
+	}
+
+	expectList := func(mws ...workv1.ManifestWork) *gomock.Call {
+		return clnt.
+			EXPECT().
+			List(ctx, gomock.AssignableToTypeOf(&workv1.ManifestWorkList{}), gomock.Any()).
+			DoAndReturn(func(_ interface{}, list *workv1.ManifestWorkList, _ ...interface{}) error {
+				list.Items = mws
+				return nil
+			})
+	}
+
+	It("should aggregate the status reported for each managed cluster", func() {
+		gomock.InOrder(
+			expectGet(v1beta1.ManagedClusterModuleStatus{}),
+			expectList(
+				manifestWork("cluster-2", "Deploying"),
+				manifestWork("cluster-1", "Ready"),
+				manifestWork("cluster-3", ""),
+			),
+			clnt.EXPECT().Status().Return(statusWrite),
+			statusWrite.EXPECT().Patch(ctx, gomock.Any(), gomock.Any()).Do(
+				func(_ context.Context, mcm *v1beta1.ManagedClusterModule, _ ctrlclient.Patch, _ ...ctrlclient.PatchOption) {
+					Expect(mcm.Status).To(Equal(v1beta1.ManagedClusterModuleStatus{
+						Clusters: []v1beta1.ClusterModuleStatus{
+							{ClusterName: "cluster-1", Phase: kmmv1beta1.KernelPhaseReady},
+							{ClusterName: "cluster-2", Phase: kmmv1beta1.KernelPhaseDeploying},
+						},
+						ReadyClusters: 1,
+						TotalClusters: 3,
+					}))
+				},
+			),
+		)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should not patch the status if it did not change", func() {
+		gomock.InOrder(
+			expectGet(v1beta1.ManagedClusterModuleStatus{
+				Clusters:      []v1beta1.ClusterModuleStatus{{ClusterName: "cluster-1", Phase: kmmv1beta1.KernelPhaseReady}},
+				ReadyClusters: 1,
+				TotalClusters: 1,
+			}),
+			expectList(manifestWork("cluster-1", "Ready")),
+		)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
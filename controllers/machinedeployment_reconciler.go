@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/shard"
+)
+
+const MachineDeploymentReconcilerName = "MachineDeployment"
+
+// machineDeploymentGVK is the GroupVersionKind of Cluster API MachineDeployments.
+var machineDeploymentGVK = schema.GroupVersionKind{Group: "cluster.x-k8s.io", Version: "v1beta1", Kind: "MachineDeployment"}
+
+// MachineDeploymentReconciler creates a PreflightValidation for the kernel that the
+// MachineDeploymentKernelVersionAnnotation of a Cluster API MachineDeployment announces, so that the Modules are
+// verified and their images built for the incoming machine image before the rollout proceeds.
+// Rollout automation can wait for the Ready condition of that PreflightValidation.
+type MachineDeploymentReconciler struct {
+	client client.Client
+}
+
+func NewMachineDeploymentReconciler(client client.Client) *MachineDeploymentReconciler {
+	return &MachineDeploymentReconciler{client: client}
+}
+
+//+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinedeployments,verbs=get;list;watch
+
+func (r *MachineDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	pvName := MachineDeploymentPreflightName(req.Namespace, req.Name)
+
+	md := unstructured.Unstructured{}
+	md.SetGroupVersionKind(machineDeploymentGVK)
+
+	if err := r.client.Get(ctx, req.NamespacedName, &md); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return ctrl.Result{}, fmt.Errorf("could not get MachineDeployment %s: %v", req.NamespacedName, err)
+		}
+
+		logger.Info("MachineDeployment not found; deleting its PreflightValidation", "name", pvName)
+
+		return ctrl.Result{}, r.deletePreflight(ctx, pvName)
+	}
+
+	kernelVersion := md.GetAnnotations()[constants.MachineDeploymentKernelVersionAnnotation]
+	if kernelVersion == "" {
+		return ctrl.Result{}, r.deletePreflight(ctx, pvName)
+	}
+
+	pv := kmmv1beta1.PreflightValidation{}
+
+	err := r.client.Get(ctx, client.ObjectKey{Name: pvName}, &pv)
+	switch {
+	case k8serrors.IsNotFound(err):
+	case err != nil:
+		return ctrl.Result{}, fmt.Errorf("could not get PreflightValidation %s: %v", pvName, err)
+	case pv.Spec.KernelVersion == kernelVersion:
+		return ctrl.Result{}, nil
+	default:
+		// The statuses of the PreflightValidation are about the previous kernel; start over.
+		logger.Info("Kernel version changed; replacing the PreflightValidation", "name", pvName, "kernel", kernelVersion)
+
+		if err = r.deletePreflight(ctx, pvName); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	pv = kmmv1beta1.PreflightValidation{
+		ObjectMeta: metav1.ObjectMeta{Name: pvName},
+		Spec: kmmv1beta1.PreflightValidationSpec{
+			KernelVersion:  kernelVersion,
+			PushBuiltImage: true,
+		},
+	}
+
+	logger.Info("Creating the PreflightValidation", "name", pvName, "kernel", kernelVersion)
+
+	if err = r.client.Create(ctx, &pv); err != nil {
+		return ctrl.Result{}, fmt.Errorf("could not create PreflightValidation %s: %v", pvName, err)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *MachineDeploymentReconciler) deletePreflight(ctx context.Context, name string) error {
+	pv := kmmv1beta1.PreflightValidation{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+	}
+
+	if err := r.client.Delete(ctx, &pv); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("could not delete PreflightValidation %s: %v", name, err)
+	}
+
+	return nil
+}
+
+// MachineDeploymentPreflightName returns the name of the PreflightValidation of the MachineDeployment namespace/name.
+// Namespace names cannot contain dots, so separating the namespace with dots makes the name unique across namespaces.
+func MachineDeploymentPreflightName(namespace, name string) string {
+	return "machinedeployment." + namespace + "." + name
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// Only the MachineDeployments in the namespaces of s are reconciled.
+func (r *MachineDeploymentReconciler) SetupWithManager(mgr ctrl.Manager, s *shard.Shard, opts controller.Options) error {
+	md := unstructured.Unstructured{}
+	md.SetGroupVersionKind(machineDeploymentGVK)
+
+	return ctrl.
+		NewControllerManagedBy(mgr).
+		Named(MachineDeploymentReconcilerName).
+		For(&md).
+		WithEventFilter(s.Predicate()).
+		WithOptions(opts).
+		Complete(r)
+}
@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	mock_client "github.com/kubernetes-sigs/kernel-module-management/internal/client"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+)
+
+var _ = Describe("MachineDeploymentReconciler", func() {
+	const (
+		kernelVersion = "5.14.0-284.11.1.el9_2.x86_64"
+		pvName        = "machinedeployment.capi.workers"
+	)
+
+	var (
+		kubeClient *mock_client.MockClient
+		r          *MachineDeploymentReconciler
+	)
+
+	BeforeEach(func() {
+		ctrl := gomock.NewController(GinkgoT())
+		kubeClient = mock_client.NewMockClient(ctrl)
+		r = NewMachineDeploymentReconciler(kubeClient)
+	})
+
+	ctx := context.Background()
+	nn := types.NamespacedName{Namespace: "capi", Name: "workers"}
+	req := ctrl.Request{NamespacedName: nn}
+	pvKey := client.ObjectKey{Name: pvName}
+
+	expectGetMachineDeployment := func(kernel string) *gomock.Call {
+		return kubeClient.
+			EXPECT().
+			Get(ctx, nn, gomock.AssignableToTypeOf(&unstructured.Unstructured{})).
+			Do(func(_ context.Context, _ types.NamespacedName, md *unstructured.Unstructured, _ ...client.GetOption) {
+				md.SetAnnotations(map[string]string{constants.MachineDeploymentKernelVersionAnnotation: kernel})
+			})
+	}
+
+	expectGetPreflight := func(kernel string) *gomock.Call {
+		return kubeClient.
+			EXPECT().
+			Get(ctx, pvKey, &kmmv1beta1.PreflightValidation{}).
+			Do(func(_ context.Context, _ types.NamespacedName, pv *kmmv1beta1.PreflightValidation, _ ...client.GetOption) {
+				pv.Name = pvName
+				pv.Spec.KernelVersion = kernel
+			})
+	}
+
+	expectedPreflight := &kmmv1beta1.PreflightValidation{
+		ObjectMeta: metav1.ObjectMeta{Name: pvName},
+		Spec: kmmv1beta1.PreflightValidationSpec{
+			KernelVersion:  kernelVersion,
+			PushBuiltImage: true,
+		},
+	}
+
+	It("should create a PreflightValidation for the announced kernel", func() {
+		gomock.InOrder(
+			expectGetMachineDeployment(kernelVersion),
+			kubeClient.
+				EXPECT().
+				Get(ctx, pvKey, &kmmv1beta1.PreflightValidation{}).
+				Return(k8serrors.NewNotFound(schema.GroupResource{}, pvName)),
+			kubeClient.EXPECT().Create(ctx, expectedPreflight),
+		)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should do nothing if the PreflightValidation is for the announced kernel", func() {
+		gomock.InOrder(
+			expectGetMachineDeployment(kernelVersion),
+			expectGetPreflight(kernelVersion),
+		)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should replace the PreflightValidation if the announced kernel changed", func() {
+		gomock.InOrder(
+			expectGetMachineDeployment(kernelVersion),
+			expectGetPreflight("5.14.0-70.13.1.el9_0.x86_64"),
+			kubeClient.EXPECT().Delete(ctx, &kmmv1beta1.PreflightValidation{ObjectMeta: metav1.ObjectMeta{Name: pvName}}),
+			kubeClient.EXPECT().Create(ctx, expectedPreflight),
+		)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should delete the PreflightValidation if no kernel is announced", func() {
+		gomock.InOrder(
+			expectGetMachineDeployment(""),
+			kubeClient.
+				EXPECT().
+				Delete(ctx, &kmmv1beta1.PreflightValidation{ObjectMeta: metav1.ObjectMeta{Name: pvName}}).
+				Return(k8serrors.NewNotFound(schema.GroupResource{}, pvName)),
+		)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should delete the PreflightValidation if the MachineDeployment was deleted", func() {
+		gomock.InOrder(
+			kubeClient.
+				EXPECT().
+				Get(ctx, nn, gomock.Any()).
+				Return(k8serrors.NewNotFound(schema.GroupResource{}, nn.Name)),
+			kubeClient.EXPECT().Delete(ctx, &kmmv1beta1.PreflightValidation{ObjectMeta: metav1.ObjectMeta{Name: pvName}}),
+		)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
+
+var _ = Describe("MachineDeploymentPreflightName", func() {
+	It("should not return the same name for different MachineDeployments", func() {
+		Expect(
+			MachineDeploymentPreflightName("capi-a", "workers"),
+		).NotTo(
+			Equal(MachineDeploymentPreflightName("capi", "a-workers")),
+		)
+	})
+})
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/daemonset"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/filter"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/shard"
+)
+
+//+kubebuilder:rbac:groups="core",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups="core",resources=nodes,verbs=get;patch
+//+kubebuilder:rbac:groups="core",resources=pods,verbs=get;list;watch
+
+const (
+	ModuleLoadRejectionReconcilerName = "ModuleLoadRejection"
+
+	// ModuleLoadRejectionActionLabel labels the nodes on which the kernel rejected a module.
+	ModuleLoadRejectionActionLabel = "label"
+	// ModuleLoadRejectionActionTaint taints the nodes on which the kernel rejected a module.
+	ModuleLoadRejectionActionTaint = "taint"
+
+	moduleLoadRejectedEventReason = "ModuleLoadRejected"
+)
+
+// ModuleLoadRejectionReconciler reports the modules rejected by the kernel, as seen in the termination message of
+// module-loader containers, through Events on the node and the module-loader Pod.
+// Depending on its action, it also labels or taints the node.
+type ModuleLoadRejectionReconciler struct {
+	client   client.Client
+	recorder record.EventRecorder
+	action   string
+}
+
+// NewModuleLoadRejectionReconciler returns a ModuleLoadRejectionReconciler.
+// action is either empty, ModuleLoadRejectionActionLabel or ModuleLoadRejectionActionTaint.
+func NewModuleLoadRejectionReconciler(client client.Client, recorder record.EventRecorder, action string) (*ModuleLoadRejectionReconciler, error) {
+	switch action {
+	case "", ModuleLoadRejectionActionLabel, ModuleLoadRejectionActionTaint:
+	default:
+		return nil, fmt.Errorf("%q: invalid module load rejection action", action)
+	}
+
+	return &ModuleLoadRejectionReconciler{client: client, recorder: recorder, action: action}, nil
+}
+
+func (r *ModuleLoadRejectionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := ctrl.LoggerFrom(ctx)
+
+	pod := v1.Pod{}
+
+	if err := r.client.Get(ctx, req.NamespacedName, &pod); err != nil {
+		if k8serrors.IsNotFound(err) {
+			logger.Info("Pod not found")
+			return ctrl.Result{}, nil
+		}
+
+		return ctrl.Result{}, fmt.Errorf("could not get pod %s: %v", req.NamespacedName, err)
+	}
+
+	reason, message := daemonset.ModuleLoadRejection(&pod)
+	if reason == "" {
+		return ctrl.Result{}, nil
+	}
+
+	nodeName := pod.Spec.NodeName
+	moduleName := pod.Labels[constants.ModuleNameLabel]
+
+	logger = logger.WithValues("node name", nodeName, "module name", moduleName, "reason", reason)
+	logger.Info("The kernel rejected the module", "message", message)
+
+	node := v1.Node{}
+
+	if err := r.client.Get(ctx, types.NamespacedName{Name: nodeName}, &node); err != nil {
+		return ctrl.Result{}, fmt.Errorf("could not get node %s: %v", nodeName, err)
+	}
+
+	r.recorder.Eventf(
+		&node,
+		v1.EventTypeWarning,
+		moduleLoadRejectedEventReason,
+		"%s: the kernel rejected the module of Module %s/%s: %s",
+		reason,
+		pod.Namespace,
+		moduleName,
+		message,
+	)
+	r.recorder.Eventf(&pod, v1.EventTypeWarning, moduleLoadRejectedEventReason, "%s: %s", reason, message)
+
+	nodeCopy := node.DeepCopy()
+
+	switch r.action {
+	case ModuleLoadRejectionActionLabel:
+		if node.Labels == nil {
+			node.Labels = make(map[string]string, 1)
+		}
+
+		node.Labels[constants.ModuleLoadRejectedLabel] = reason
+	case ModuleLoadRejectionActionTaint:
+		taint := v1.Taint{Key: constants.ModuleLoadRejectedLabel, Value: reason, Effect: v1.TaintEffectNoSchedule}
+
+		for _, t := range node.Spec.Taints {
+			if t.MatchTaint(&taint) {
+				return ctrl.Result{}, nil
+			}
+		}
+
+		node.Spec.Taints = append(node.Spec.Taints, taint)
+	default:
+		return ctrl.Result{}, nil
+	}
+
+	logger.Info("Marking the node", "action", r.action)
+
+	if err := r.client.Patch(ctx, &node, client.MergeFrom(nodeCopy)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("could not patch node %s: %v", nodeName, err)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// Only the Pods in the namespaces of s are reconciled.
+func (r *ModuleLoadRejectionReconciler) SetupWithManager(mgr ctrl.Manager, s *shard.Shard, opts controller.Options) error {
+	p := predicate.And(
+		filter.ModuleLoadRejectedPredicate(),
+		filter.HasLabel(constants.ModuleNameLabel),
+		filter.PodHasSpecNodeName(),
+		s.Predicate(),
+	)
+
+	return ctrl.
+		NewControllerManagedBy(mgr).
+		Named(ModuleLoadRejectionReconcilerName).
+		For(&v1.Pod{}).
+		WithEventFilter(p).
+		WithOptions(opts).
+		Complete(r)
+}
@@ -0,0 +1,199 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mock_client "github.com/kubernetes-sigs/kernel-module-management/internal/client"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+)
+
+var _ = Describe("NewModuleLoadRejectionReconciler", func() {
+	It("should refuse invalid actions", func() {
+		_, err := NewModuleLoadRejectionReconciler(nil, nil, "reboot")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("ModuleLoadRejectionReconciler", func() {
+	const (
+		moduleName   = "module-name"
+		nodeName     = "node-name"
+		podName      = "pod-name"
+		podNamespace = "pod-namespace"
+	)
+
+	var (
+		kubeClient *mock_client.MockClient
+		recorder   *record.FakeRecorder
+	)
+
+	BeforeEach(func() {
+		ctrl := gomock.NewController(GinkgoT())
+		kubeClient = mock_client.NewMockClient(ctrl)
+		recorder = record.NewFakeRecorder(10)
+	})
+
+	ctx := context.Background()
+	nn := types.NamespacedName{Namespace: podNamespace, Name: podName}
+	req := ctrl.Request{NamespacedName: nn}
+
+	getPod := func(message string) *gomock.Call {
+		return kubeClient.
+			EXPECT().
+			Get(ctx, nn, gomock.AssignableToTypeOf(&v1.Pod{})).
+			Do(func(_ context.Context, _ types.NamespacedName, o client.Object, _ ...client.GetOption) {
+				pod := o.(*v1.Pod)
+				pod.Namespace = podNamespace
+				pod.Labels = map[string]string{constants.ModuleNameLabel: moduleName}
+				pod.Spec.NodeName = nodeName
+				pod.Status.ContainerStatuses = []v1.ContainerStatus{
+					{
+						Name: "module-loader",
+						LastTerminationState: v1.ContainerState{
+							Terminated: &v1.ContainerStateTerminated{Message: message},
+						},
+					},
+				}
+			})
+	}
+
+	getNode := func(taints ...v1.Taint) *gomock.Call {
+		return kubeClient.
+			EXPECT().
+			Get(ctx, types.NamespacedName{Name: nodeName}, gomock.AssignableToTypeOf(&v1.Node{})).
+			Do(func(_ context.Context, _ types.NamespacedName, o client.Object, _ ...client.GetOption) {
+				o.SetName(nodeName)
+				o.(*v1.Node).Spec.Taints = taints
+			})
+	}
+
+	It("should do nothing if the module was not rejected", func() {
+		r, err := NewModuleLoadRejectionReconciler(kubeClient, recorder, ModuleLoadRejectionActionTaint)
+		Expect(err).NotTo(HaveOccurred())
+
+		getPod("modprobe: FATAL: Module kmm_ci_a not found")
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("should only emit Events without an action", func() {
+		r, err := NewModuleLoadRejectionReconciler(kubeClient, recorder, "")
+		Expect(err).NotTo(HaveOccurred())
+
+		gomock.InOrder(
+			getPod("modprobe: ERROR: could not insert 'kmm_ci_a': Key was rejected by service"),
+			getNode(),
+		)
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Events).To(HaveLen(2))
+		Expect(<-recorder.Events).To(
+			Equal(
+				"Warning ModuleLoadRejected SignatureRejected: the kernel rejected the module of Module " +
+					"pod-namespace/module-name: modprobe: ERROR: could not insert 'kmm_ci_a': Key was rejected by service",
+			),
+		)
+	})
+
+	It("should label the node", func() {
+		r, err := NewModuleLoadRejectionReconciler(kubeClient, recorder, ModuleLoadRejectionActionLabel)
+		Expect(err).NotTo(HaveOccurred())
+
+		gomock.InOrder(
+			getPod("modprobe: ERROR: could not insert 'kmm_ci_a': Exec format error"),
+			getNode(),
+			kubeClient.
+				EXPECT().
+				Patch(ctx, gomock.AssignableToTypeOf(&v1.Node{}), gomock.Any()).
+				Do(func(_ context.Context, n client.Object, p client.Patch, _ ...client.PatchOption) {
+					Expect(p.Data(n)).To(
+						BeEquivalentTo(`{"metadata":{"labels":{"kmm.node.kubernetes.io/module-load-rejected":"InvalidModuleFormat"}}}`),
+					)
+				}),
+		)
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should taint the node", func() {
+		r, err := NewModuleLoadRejectionReconciler(kubeClient, recorder, ModuleLoadRejectionActionTaint)
+		Expect(err).NotTo(HaveOccurred())
+
+		gomock.InOrder(
+			getPod("modprobe: ERROR: could not insert 'kmm_ci_a': Required key not available"),
+			getNode(),
+			kubeClient.
+				EXPECT().
+				Patch(ctx, gomock.AssignableToTypeOf(&v1.Node{}), gomock.Any()).
+				Do(func(_ context.Context, n client.Object, _ client.Patch, _ ...client.PatchOption) {
+					Expect(n.(*v1.Node).Spec.Taints).To(
+						ConsistOf(v1.Taint{
+							Key:    constants.ModuleLoadRejectedLabel,
+							Value:  "SignatureRejected",
+							Effect: v1.TaintEffectNoSchedule,
+						}),
+					)
+				}).
+				Return(errors.New("some error")),
+		)
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should not taint the node twice", func() {
+		r, err := NewModuleLoadRejectionReconciler(kubeClient, recorder, ModuleLoadRejectionActionTaint)
+		Expect(err).NotTo(HaveOccurred())
+
+		gomock.InOrder(
+			getPod("modprobe: ERROR: could not insert 'kmm_ci_a': Required key not available"),
+			getNode(v1.Taint{Key: constants.ModuleLoadRejectedLabel, Effect: v1.TaintEffectNoSchedule}),
+		)
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should return an error if the node cannot be fetched", func() {
+		r, err := NewModuleLoadRejectionReconciler(kubeClient, recorder, "")
+		Expect(err).NotTo(HaveOccurred())
+
+		gomock.InOrder(
+			getPod("modprobe: ERROR: could not insert 'kmm_ci_a': Exec format error"),
+			getNode().Return(errors.New("some error")),
+		)
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).To(HaveOccurred())
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("should ignore deleted pods", func() {
+		r, err := NewModuleLoadRejectionReconciler(kubeClient, recorder, "")
+		Expect(err).NotTo(HaveOccurred())
+
+		kubeClient.
+			EXPECT().
+			Get(ctx, nn, gomock.AssignableToTypeOf(&v1.Pod{})).
+			Return(apierrors.NewNotFound(schema.GroupResource{}, podName))
+
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kubectl/pkg/util/podutils"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/filter"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/shard"
+)
+
+//+kubebuilder:rbac:groups=kmm.sigs.x-k8s.io,resources=modules,verbs=get;list;watch
+//+kubebuilder:rbac:groups=kmm.sigs.x-k8s.io,resources=modules/status,verbs=get;patch
+//+kubebuilder:rbac:groups="core",resources=nodes,verbs=get;patch
+//+kubebuilder:rbac:groups="core",resources=pods,verbs=get;list;watch
+
+const ModuleRebootReconcilerName = "ModuleReboot"
+
+// ModuleRebootReconciler tracks the reboots requested by the module-loaders of the Modules that require one.
+// When the module-loader of such a Module is first ready on a node with an image, the boot ID of the node is recorded
+// in an annotation; the node has rebooted once its boot ID differs, and the image is then recorded in another
+// annotation so that no further reboot is awaited for it.
+// The nodes still waiting for a reboot are listed in the status of the Module.
+type ModuleRebootReconciler struct {
+	client client.Client
+}
+
+func NewModuleRebootReconciler(client client.Client) *ModuleRebootReconciler {
+	return &ModuleRebootReconciler{client: client}
+}
+
+func (r *ModuleRebootReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := ctrl.LoggerFrom(ctx)
+
+	mod := kmmv1beta1.Module{}
+
+	if err := r.client.Get(ctx, req.NamespacedName, &mod); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+
+		return ctrl.Result{}, fmt.Errorf("could not get Module %s: %v", req.NamespacedName, err)
+	}
+
+	var pending []string
+
+	if mod.Spec.ModuleLoader.RebootRequired {
+		pods := v1.PodList{}
+
+		opts := []client.ListOption{
+			client.InNamespace(mod.Namespace),
+			client.MatchingLabels{constants.ModuleNameLabel: mod.Name, constants.DaemonSetRole: "module-loader"},
+		}
+
+		if err := r.client.List(ctx, &pods, opts...); err != nil {
+			return ctrl.Result{}, fmt.Errorf("could not list the module-loader pods: %v", err)
+		}
+
+		for i := 0; i < len(pods.Items); i++ {
+			pod := &pods.Items[i]
+
+			if pod.Spec.NodeName == "" || !podutils.IsPodReady(pod) || !pod.DeletionTimestamp.IsZero() {
+				continue
+			}
+
+			rebooted, err := r.trackReboot(ctx, pod.Spec.NodeName, mod.Namespace, mod.Name, pod.Spec.Containers[0].Image)
+			if err != nil {
+				return ctrl.Result{}, fmt.Errorf("could not track the reboot of node %s: %v", pod.Spec.NodeName, err)
+			}
+
+			if !rebooted {
+				pending = append(pending, pod.Spec.NodeName)
+			}
+		}
+
+		sort.Strings(pending)
+	}
+
+	if reflect.DeepEqual(pending, mod.Status.NodesPendingReboot) {
+		return ctrl.Result{}, nil
+	}
+
+	logger.Info("Updating the nodes pending reboot", "nodes", pending)
+
+	modCopy := mod.DeepCopy()
+	mod.Status.NodesPendingReboot = pending
+
+	if err := r.client.Status().Patch(ctx, &mod, client.MergeFrom(modCopy)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("could not patch the status of Module %s: %v", req.NamespacedName, err)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// trackReboot returns true if the node has rebooted since the module-loader running image was first ready on it.
+func (r *ModuleRebootReconciler) trackReboot(ctx context.Context, nodeName, namespace, moduleName, image string) (bool, error) {
+	node := v1.Node{}
+
+	if err := r.client.Get(ctx, types.NamespacedName{Name: nodeName}, &node); err != nil {
+		return false, fmt.Errorf("could not get node: %v", err)
+	}
+
+	rebootedImageAnnotation := rebootedImageNodeAnnotation(namespace, moduleName)
+	bootIDAnnotation := rebootRequestedBootIDNodeAnnotation(namespace, moduleName)
+
+	if node.Annotations[rebootedImageAnnotation] == image {
+		return true, nil
+	}
+
+	nodeCopy := node.DeepCopy()
+	bootID, requested := node.Annotations[bootIDAnnotation]
+
+	switch {
+	case !requested:
+		if node.Annotations == nil {
+			node.Annotations = make(map[string]string, 1)
+		}
+
+		node.Annotations[bootIDAnnotation] = node.Status.NodeInfo.BootID
+	case bootID != node.Status.NodeInfo.BootID:
+		delete(node.Annotations, bootIDAnnotation)
+		node.Annotations[rebootedImageAnnotation] = image
+	default:
+		return false, nil
+	}
+
+	if err := r.client.Patch(ctx, &node, client.MergeFrom(nodeCopy)); err != nil {
+		return false, fmt.Errorf("could not patch node: %v", err)
+	}
+
+	return requested, nil
+}
+
+// rebootRequestedBootIDNodeAnnotation returns the annotation holding the boot ID of the node when the module-loader
+// of the Module namespace/moduleName requested a reboot.
+// Like the reboot marker on the host, it includes the namespace, so that Modules of the same name do not share it.
+func rebootRequestedBootIDNodeAnnotation(namespace, moduleName string) string {
+	return fmt.Sprintf("kmm.node.kubernetes.io/%s.%s.reboot-requested-boot-id", namespace, moduleName)
+}
+
+// rebootedImageNodeAnnotation returns the annotation holding the last module-loader image of the Module
+// namespace/moduleName after which the node rebooted.
+func rebootedImageNodeAnnotation(namespace, moduleName string) string {
+	return fmt.Sprintf("kmm.node.kubernetes.io/%s.%s.rebooted-image", namespace, moduleName)
+}
+
+func moduleForPod(pod client.Object) []reconcile.Request {
+	return []reconcile.Request{
+		{
+			NamespacedName: types.NamespacedName{Namespace: pod.GetNamespace(), Name: pod.GetLabels()[constants.ModuleNameLabel]},
+		},
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// Modules are reconciled when their module-loader pods become ready or not ready, which they do after a reboot.
+// Only the Modules in the namespaces of s are reconciled.
+func (r *ModuleRebootReconciler) SetupWithManager(mgr ctrl.Manager, s *shard.Shard, opts controller.Options) error {
+	return ctrl.
+		NewControllerManagedBy(mgr).
+		Named(ModuleRebootReconcilerName).
+		For(&kmmv1beta1.Module{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(
+			&source.Kind{Type: &v1.Pod{}},
+			handler.EnqueueRequestsFromMapFunc(moduleForPod),
+			builder.WithPredicates(
+				filter.HasLabel(constants.ModuleNameLabel),
+				filter.PodReadinessChangedPredicate(mgr.GetLogger().WithName("pod-readiness-changed")),
+			),
+		).
+		WithEventFilter(s.Predicate()).
+		WithOptions(opts).
+		Complete(r)
+}
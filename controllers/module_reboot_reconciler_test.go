@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	mock_client "github.com/kubernetes-sigs/kernel-module-management/internal/client"
+)
+
+var _ = Describe("ModuleRebootReconciler", func() {
+	const (
+		image      = "example.com/kmod:v1"
+		moduleName = "kmod"
+		namespace  = "default"
+		nodeName   = "worker-0"
+	)
+
+	var (
+		kubeClient  *mock_client.MockClient
+		statusWrite *mock_client.MockStatusWriter
+		r           *ModuleRebootReconciler
+	)
+
+	BeforeEach(func() {
+		ctrl := gomock.NewController(GinkgoT())
+		kubeClient = mock_client.NewMockClient(ctrl)
+		statusWrite = mock_client.NewMockStatusWriter(ctrl)
+		r = NewModuleRebootReconciler(kubeClient)
+	})
+
+	ctx := context.Background()
+	nn := types.NamespacedName{Namespace: namespace, Name: moduleName}
+	req := ctrl.Request{NamespacedName: nn}
+
+	expectGetModule := func(rebootRequired bool, pending ...string) *gomock.Call {
+		return kubeClient.
+			EXPECT().
+			Get(ctx, nn, gomock.AssignableToTypeOf(&kmmv1beta1.Module{})).
+			Do(func(_ context.Context, _ types.NamespacedName, mod *kmmv1beta1.Module, _ ...client.GetOption) {
+				mod.Name = moduleName
+				mod.Namespace = namespace
+				mod.Spec.ModuleLoader.RebootRequired = rebootRequired
+				mod.Status.NodesPendingReboot = pending
+			})
+	}
+
+	expectListPods := func() *gomock.Call {
+		return kubeClient.
+			EXPECT().
+			List(ctx, gomock.AssignableToTypeOf(&v1.PodList{}), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ interface{}, list *v1.PodList, _ ...interface{}) error {
+				list.Items = []v1.Pod{
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "ready"},
+						Spec: v1.PodSpec{
+							Containers: []v1.Container{{Image: image}},
+							NodeName:   nodeName,
+						},
+						Status: v1.PodStatus{
+							Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}},
+						},
+					},
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "not-ready"},
+						Spec: v1.PodSpec{
+							Containers: []v1.Container{{Image: image}},
+							NodeName:   "worker-1",
+						},
+					},
+				}
+				return nil
+			})
+	}
+
+	expectGetNode := func(bootID string, annotations map[string]string) *gomock.Call {
+		return kubeClient.
+			EXPECT().
+			Get(ctx, types.NamespacedName{Name: nodeName}, gomock.AssignableToTypeOf(&v1.Node{})).
+			Do(func(_ context.Context, _ types.NamespacedName, node *v1.Node, _ ...client.GetOption) {
+				node.Name = nodeName
+				node.Annotations = annotations
+				node.Status.NodeInfo.BootID = bootID
+			})
+	}
+
+	expectPatchStatus := func(pending []string) *gomock.Call {
+		kubeClient.EXPECT().Status().Return(statusWrite)
+
+		return statusWrite.EXPECT().Patch(ctx, gomock.Any(), gomock.Any()).Do(
+			func(_ context.Context, mod *kmmv1beta1.Module, _ client.Patch, _ ...client.PatchOption) {
+				Expect(mod.Status.NodesPendingReboot).To(Equal(pending))
+			},
+		)
+	}
+
+	It("should record the boot ID of nodes on which a reboot was requested", func() {
+		gomock.InOrder(
+			expectGetModule(true),
+			expectListPods(),
+			expectGetNode("boot-0", nil),
+			kubeClient.EXPECT().Patch(ctx, gomock.Any(), gomock.Any()).Do(
+				func(_ context.Context, node *v1.Node, _ client.Patch, _ ...client.PatchOption) {
+					Expect(node.Annotations).To(Equal(map[string]string{
+						"kmm.node.kubernetes.io/default.kmod.reboot-requested-boot-id": "boot-0",
+					}))
+				},
+			),
+			expectPatchStatus([]string{nodeName}),
+		)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should keep the node pending until its boot ID changes", func() {
+		gomock.InOrder(
+			expectGetModule(true, nodeName),
+			expectListPods(),
+			expectGetNode("boot-0", map[string]string{"kmm.node.kubernetes.io/default.kmod.reboot-requested-boot-id": "boot-0"}),
+		)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should record the image once the node has rebooted", func() {
+		gomock.InOrder(
+			expectGetModule(true, nodeName),
+			expectListPods(),
+			expectGetNode("boot-1", map[string]string{"kmm.node.kubernetes.io/default.kmod.reboot-requested-boot-id": "boot-0"}),
+			kubeClient.EXPECT().Patch(ctx, gomock.Any(), gomock.Any()).Do(
+				func(_ context.Context, node *v1.Node, _ client.Patch, _ ...client.PatchOption) {
+					Expect(node.Annotations).To(Equal(map[string]string{
+						"kmm.node.kubernetes.io/default.kmod.rebooted-image": image,
+					}))
+				},
+			),
+			expectPatchStatus(nil),
+		)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should not wait for a reboot if the node rebooted after the current image", func() {
+		gomock.InOrder(
+			expectGetModule(true),
+			expectListPods(),
+			expectGetNode("boot-1", map[string]string{"kmm.node.kubernetes.io/default.kmod.rebooted-image": image}),
+		)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should clear the pending nodes if the Module no longer requires a reboot", func() {
+		gomock.InOrder(
+			expectGetModule(false, nodeName),
+			expectPatchStatus(nil),
+		)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
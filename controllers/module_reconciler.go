@@ -18,37 +18,83 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/build"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/daemonset"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/decisions"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/filter"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/imagestream"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/metrics"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/networkpolicy"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/podsecurity"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/provenance"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/rbac"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/shard"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/sign"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/statusupdater"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/utils"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/validation"
+	"golang.org/x/sync/errgroup"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
-const ModuleReconcilerName = "Module"
+const (
+	ModuleReconcilerName = "Module"
+
+	buildFailedEventReason = "BuildFailed"
+	signFailedEventReason  = "SignFailed"
+
+	// maxConcurrentKernelMappings is the maximum number of kernel mappings handled in parallel within a single
+	// reconciliation of a Module.
+	maxConcurrentKernelMappings = 5
+
+	// nodeEventsCoalescingDelay is how long Module reconciliations triggered by Node events are delayed, so that the
+	// events sent by many nodes at once are handled in a single reconciliation.
+	nodeEventsCoalescingDelay = time.Second
+
+	// jobsInProgressRequeueAfter is how long to wait before reconciling a Module again while some of its build or sign
+	// Jobs are in progress.
+	// Job events normally trigger the reconciliation earlier; this is a safety net in case one was missed.
+	jobsInProgressRequeueAfter = time.Minute
+)
+
+// errSecretNotFound is returned for kernels that cannot be handled because a Secret referenced by the Module does not
+// exist yet, for instance because another controller has not created it yet.
+// The Module is reconciled again when the Secret is created.
+var errSecretNotFound = errors.New("waiting for Secret")
+
+var jobFailedEventReasons = map[string]string{
+	utils.JobTypeBuild: buildFailedEventReason,
+	utils.JobTypeSign:  signFailedEventReason,
+}
 
 // ModuleReconciler reconciles a Module object
 type ModuleReconciler struct {
@@ -57,11 +103,21 @@ type ModuleReconciler struct {
 	buildAPI         build.Manager
 	signAPI          sign.SignManager
 	rbacAPI          rbac.RBACCreator
+	networkPolicyAPI networkpolicy.NetworkPolicyCreator
+	nsLabelerAPI     podsecurity.NamespaceLabeler
 	daemonAPI        daemonset.DaemonSetCreator
+	provenanceAPI    provenance.Verifier
+	validatorAPI     validation.Validator
 	kernelAPI        module.KernelMapper
 	metricsAPI       metrics.Metrics
 	filter           *filter.Filter
 	statusUpdaterAPI statusupdater.ModuleStatusUpdater
+	jobLogAPI        utils.JobLogTailer
+	recorder         record.EventRecorder
+	decisionsAPI     decisions.Recorder
+	imageStreamAPI   imagestream.Resolver
+	fipsMode         bool
+	dryRun           bool
 }
 
 func NewModuleReconciler(
@@ -69,37 +125,80 @@ func NewModuleReconciler(
 	buildAPI build.Manager,
 	signAPI sign.SignManager,
 	rbacAPI rbac.RBACCreator,
+	networkPolicyAPI networkpolicy.NetworkPolicyCreator,
+	nsLabelerAPI podsecurity.NamespaceLabeler,
 	daemonAPI daemonset.DaemonSetCreator,
+	provenanceAPI provenance.Verifier,
+	validatorAPI validation.Validator,
 	kernelAPI module.KernelMapper,
 	metricsAPI metrics.Metrics,
 	filter *filter.Filter,
-	statusUpdaterAPI statusupdater.ModuleStatusUpdater) *ModuleReconciler {
+	statusUpdaterAPI statusupdater.ModuleStatusUpdater,
+	jobLogAPI utils.JobLogTailer,
+	recorder record.EventRecorder,
+	decisionsAPI decisions.Recorder,
+	imageStreamAPI imagestream.Resolver,
+	fipsMode bool,
+	dryRun bool) *ModuleReconciler {
 	return &ModuleReconciler{
 		Client:           client,
 		buildAPI:         buildAPI,
 		signAPI:          signAPI,
 		rbacAPI:          rbacAPI,
+		networkPolicyAPI: networkPolicyAPI,
+		nsLabelerAPI:     nsLabelerAPI,
 		daemonAPI:        daemonAPI,
+		provenanceAPI:    provenanceAPI,
+		validatorAPI:     validatorAPI,
 		kernelAPI:        kernelAPI,
 		metricsAPI:       metricsAPI,
 		filter:           filter,
 		statusUpdaterAPI: statusUpdaterAPI,
+		jobLogAPI:        jobLogAPI,
+		recorder:         recorder,
+		decisionsAPI:     decisionsAPI,
+		imageStreamAPI:   imageStreamAPI,
+		fipsMode:         fipsMode,
+		dryRun:           dryRun,
 	}
 }
 
 //+kubebuilder:rbac:groups=kmm.sigs.x-k8s.io,resources=modules,verbs=get;list;watch;update;patch
 //+kubebuilder:rbac:groups=kmm.sigs.x-k8s.io,resources=modules/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=create;delete;get;list;patch;watch
+//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=create;delete;get;list;patch;watch
+//+kubebuilder:rbac:groups="core",resources=namespaces,verbs=get;list;patch;watch
 //+kubebuilder:rbac:groups="core",resources=nodes,verbs=get;list;watch
 //+kubebuilder:rbac:groups="core",resources=secrets,verbs=get;list;watch
 //+kubebuilder:rbac:groups="core",resources=configmaps,verbs=get;list;watch
 //+kubebuilder:rbac:groups="core",resources=serviceaccounts,verbs=create;delete;get;list;patch;watch
 //+kubebuilder:rbac:groups="batch",resources=jobs,verbs=create;list;watch;delete
+//+kubebuilder:rbac:groups="core",resources=pods,verbs=get;list;watch
+//+kubebuilder:rbac:groups="core",resources=pods/log,verbs=get
+//+kubebuilder:rbac:groups="core",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups="networking.k8s.io",resources=networkpolicies,verbs=create;delete;get;list;patch;watch
 
 // Reconcile lists all nodes and looks for kernels that match its mappings.
 // For each mapping that matches at least one node in the cluster, it creates a DaemonSet running the container image
 // on the nodes with a compatible kernel.
 func (r *ModuleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	// decisionsAPI is nil when the operator does not record the decisions of reconciliations.
+	if r.decisionsAPI == nil {
+		return r.reconcileModule(ctx, req, nil)
+	}
+
+	d := decisions.New(req.NamespacedName)
+
+	res, err := r.reconcileModule(ctx, req, d)
+
+	d.Finish(res, err)
+	r.decisionsAPI.Record(d)
+
+	return res, err
+}
+
+// reconcileModule reconciles a Module, recording its decisions in d, which may be nil.
+func (r *ModuleReconciler) reconcileModule(ctx context.Context, req ctrl.Request, d *decisions.Module) (ctrl.Result, error) {
 	res := ctrl.Result{}
 
 	logger := log.FromContext(ctx)
@@ -108,13 +207,50 @@ func (r *ModuleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
 			logger.Info("Module deleted")
+			d.SetDeleted()
+
+			if r.nsLabelerAPI != nil {
+				if err = r.nsLabelerAPI.RestorePrevious(ctx, req.Namespace); err != nil {
+					return res, fmt.Errorf("could not restore the labels of the Module's namespace: %w", err)
+				}
+			}
+
 			return ctrl.Result{}, nil
 		}
 
 		return res, fmt.Errorf("failed to get the requested %s KMMO CR: %w", req.NamespacedName, err)
 	}
 
-	r.setKMMOMetrics(ctx)
+	d.Step("validate the spec")
+
+	if err = r.validatorAPI.ValidateModule(mod); err != nil {
+		logger.Info("Module spec rejected; not deploying it", "reason", err.Error())
+
+		return res, r.statusUpdaterAPI.ModuleSetCondition(ctx, mod, specRejectedCondition(mod, err))
+	}
+	if meta.IsStatusConditionTrue(mod.Status.Conditions, kmmv1beta1.ModuleConditionSpecRejected) {
+		if err = r.statusUpdaterAPI.ModuleSetCondition(ctx, mod, specRejectedCondition(mod, nil)); err != nil {
+			return res, fmt.Errorf("could not clear the %s condition: %w", kmmv1beta1.ModuleConditionSpecRejected, err)
+		}
+	}
+
+	// The FIPS condition is only set on Modules that are, or were handled in FIPS mode.
+	if r.fipsMode || meta.IsStatusConditionTrue(mod.Status.Conditions, kmmv1beta1.ModuleConditionFIPS) {
+		if err = r.statusUpdaterAPI.ModuleSetCondition(ctx, mod, fipsCondition(mod, r.fipsMode)); err != nil {
+			return res, fmt.Errorf("could not set the %s condition: %w", kmmv1beta1.ModuleConditionFIPS, err)
+		}
+	}
+
+	// nsLabelerAPI is nil when the operator does not manage the Pod Security Admission labels of namespaces.
+	if r.nsLabelerAPI != nil {
+		d.Step("label the namespace")
+
+		if err := r.nsLabelerAPI.SetPrivileged(ctx, mod.Namespace); err != nil {
+			return res, fmt.Errorf("could not label the Module's namespace: %w", err)
+		}
+	}
+
+	d.Step("create the ServiceAccounts")
 
 	if mod.Spec.ModuleLoader.ServiceAccountName == "" {
 		if err := r.rbacAPI.CreateModuleLoaderServiceAccount(ctx, *mod); err != nil {
@@ -126,61 +262,193 @@ func (r *ModuleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 			return res, fmt.Errorf("could not create device-plugin's ServiceAccount: %w", err)
 		}
 	}
+	if buildsOrSigns(mod) {
+		if err := r.rbacAPI.CreateBuildServiceAccount(ctx, *mod, mod); err != nil {
+			return res, fmt.Errorf("could not create the build ServiceAccount: %w", err)
+		}
+	}
+	// networkPolicyAPI is nil when the operator does not restrict the network access of build and sign pods.
+	if r.networkPolicyAPI != nil && buildsOrSigns(mod) {
+		d.Step("create the NetworkPolicy")
+
+		if err := r.networkPolicyAPI.CreateBuildSignNetworkPolicy(ctx, *mod); err != nil {
+			return res, fmt.Errorf("could not create the build and sign NetworkPolicy: %w", err)
+		}
+	}
+
+	// In dry-run mode, the annotations could never be removed or marked as handled, so the Module would be requeued
+	// forever; they are left for the operator to handle once it leaves dry-run mode.
+	_, rebuildRequested := mod.Annotations[constants.RebuildKernelsAnnotation]
+
+	if rebuildRequested && !r.dryRun {
+		d.Step("handle the rebuild request")
+
+		if err = r.handleRebuildRequest(ctx, mod); err != nil {
+			return res, fmt.Errorf("could not handle the rebuild request: %w", err)
+		}
+
+		// The deleted Jobs may still be in the cache; they are created again on the next reconciliation.
+		res.Requeue = true
+		return res, nil
+	}
+
+	if retryBuildPending(mod) && !r.dryRun {
+		d.Step("handle the retry-build request")
+
+		if err = r.handleRetryBuild(ctx, mod); err != nil {
+			return res, fmt.Errorf("could not handle the retry-build request: %w", err)
+		}
+
+		res.Requeue = true
+		return res, nil
+	}
 
 	targetedNodes, err := r.getNodesListBySelector(ctx, mod)
 	if err != nil {
 		return res, fmt.Errorf("could get targeted nodes for module %s: %w", mod.Name, err)
 	}
 
-	mappings, nodesWithMapping, err := r.getRelevantKernelMappingsAndNodes(ctx, mod, targetedNodes)
+	mappings, nodesWithMapping, conflictingNodes, err := r.getRelevantKernelMappingsAndNodes(ctx, mod, targetedNodes)
 	if err != nil {
 		return res, fmt.Errorf("could get kernel mappings and nodes for modules %s: %w", mod.Name, err)
 	}
 
+	if len(conflictingNodes) > 0 || meta.IsStatusConditionTrue(mod.Status.Conditions, kmmv1beta1.ModuleConditionMappingConflict) {
+		if err = r.statusUpdaterAPI.ModuleSetCondition(ctx, mod, mappingConflictCondition(mod, conflictingNodes)); err != nil {
+			return res, fmt.Errorf("could not set the %s condition: %w", kmmv1beta1.ModuleConditionMappingConflict, err)
+		}
+	}
+
+	recordNodeDecisions(d, targetedNodes, nodesWithMapping, conflictingNodes, mappings)
+
+	osProfiles := kernelOSProfiles(nodesWithMapping)
+
 	dsByKernelVersion, err := r.daemonAPI.ModuleDaemonSetsByKernelVersion(ctx, mod.Name, mod.Namespace)
 	if err != nil {
 		return res, fmt.Errorf("could get DaemonSets for module %s: %v", mod.Name, err)
 	}
 
+	d.Step("handle the kernel mappings")
+
+	var (
+		g             errgroup.Group
+		requeueNeeded atomic.Bool
+
+		// mu protects unverified, the provenance verification failures of kernel mappings, kernelStatuses and
+		// jobFailures.
+		mu             sync.Mutex
+		unverified     []string
+		kernelStatuses = make([]kmmv1beta1.KernelStatus, 0, len(mappings))
+		jobFailures    []kmmv1beta1.JobFailure
+	)
+
+	g.SetLimit(maxConcurrentKernelMappings)
+
 	for kernelVersion, m := range mappings {
-		requeue, err := r.handleBuild(ctx, mod, m, kernelVersion)
-		if err != nil {
-			return res, fmt.Errorf("failed to handle build for kernel version %s: %v", kernelVersion, err)
-		}
-		if requeue {
-			logger.Info("Build requires a requeue; skipping handling driver container for now", "kernelVersion", kernelVersion, "image", m)
-			res.Requeue = true
-			continue
-		}
+		kernelVersion, m := kernelVersion, m
+
+		g.Go(func() error {
+			phase, jf, err := r.handleKernelMapping(ctx, mod, m, dsByKernelVersion, kernelVersion, osProfiles[kernelVersion])
+			if phase == kmmv1beta1.KernelPhaseBuilding || phase == kmmv1beta1.KernelPhaseSigning {
+				requeueNeeded.Store(true)
+			}
+
+			ks := kmmv1beta1.KernelStatus{
+				KernelVersion: kernelVersion,
+				Phase:         phase,
+				Conditions: kernelConditions(
+					mod.Generation,
+					phase,
+					module.ShouldBeBuilt(mod.Spec, *m),
+					module.ShouldBeSigned(mod.Spec, *m),
+					jf,
+				),
+			}
+			if err != nil {
+				ks.Message = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			kernelStatuses = append(kernelStatuses, ks)
+
+			if jf != nil {
+				jobFailures = append(jobFailures, *jf)
+			}
+
+			// The Secret watch triggers a new reconciliation once the Secret exists; retrying earlier is useless.
+			if errors.Is(err, errSecretNotFound) {
+				logger.Info(err.Error())
+				return nil
+			}
+
+			// The module-loader is not deployed for that kernel, but the other kernels are still handled.
+			if errors.Is(err, provenance.ErrVerificationFailed) {
+				logger.Info(utils.WarnString(err.Error()))
+				unverified = append(unverified, err.Error())
+				return nil
+			}
+
+			return err
+		})
+	}
 
-		signrequeue, err := r.handleSigning(ctx, mod, m, kernelVersion)
-		if err != nil {
-			return res, fmt.Errorf("failed to handle signing for kernel version %s: %v", kernelVersion, err)
-		}
-		if signrequeue {
-			logger.Info("Signing requires a requeue; skipping handling driver container for now", "kernelVersion", kernelVersion, "image", m)
-			res.Requeue = true
-			continue
+	err = g.Wait()
+
+	recordKernelDecisions(d, mod, mappings, kernelStatuses)
+
+	// The phases are also written when a kernel failed, so that users can see which one did.
+	if statusErr := r.statusUpdaterAPI.ModuleSetKernelStatuses(ctx, mod, kernelStatuses); statusErr != nil && err == nil {
+		err = fmt.Errorf("could not set the kernel statuses: %w", statusErr)
+	}
+
+	// Failed Jobs may be deleted before the next reconciliation, so they are recorded even if another kernel failed.
+	if len(jobFailures) > 0 {
+		if statusErr := r.statusUpdaterAPI.ModuleRecordJobFailures(ctx, mod, jobFailures); statusErr != nil && err == nil {
+			err = fmt.Errorf("could not record the Job failures: %w", statusErr)
 		}
+	}
 
-		err = r.handleDriverContainer(ctx, mod, m, dsByKernelVersion, kernelVersion)
-		if err != nil {
-			return res, fmt.Errorf("failed to handle driver container for kernel version %s: %v", kernelVersion, err)
+	if err != nil {
+		return res, err
+	}
+
+	if requeueNeeded.Load() {
+		// The Progressing condition set along with the kernel statuses explains what the Module is waiting for.
+		res.RequeueAfter = jobsInProgressRequeueAfter
+	}
+
+	if mod.Spec.ModuleLoader.Container.Provenance != nil {
+		if err = r.statusUpdaterAPI.ModuleSetCondition(ctx, mod, provenanceCondition(mod, unverified)); err != nil {
+			return res, fmt.Errorf("could not set the %s condition: %w", kmmv1beta1.ModuleConditionDegraded, err)
 		}
 	}
 
+	d.Step("handle the device plugin")
+
 	logger.Info("Handle device plugin")
 	err = r.handleDevicePlugin(ctx, mod)
 	if err != nil {
 		return res, fmt.Errorf("could handle device plugin: %w", err)
 	}
 
+	d.Step("handle the companions")
+
+	if err = r.handleCompanions(ctx, mod); err != nil {
+		return res, fmt.Errorf("could not handle the companions: %w", err)
+	}
+
+	d.Step("collect garbage")
+
 	logger.Info("Run garbage collection")
 	err = r.garbageCollect(ctx, mod, mappings, dsByKernelVersion)
 	if err != nil {
 		return res, fmt.Errorf("failed to run garbage collection: %v", err)
 	}
 
+	d.Step("update the status")
+
 	err = r.statusUpdaterAPI.ModuleUpdateStatus(ctx, mod, nodesWithMapping, targetedNodes, dsByKernelVersion)
 	if err != nil {
 		return res, fmt.Errorf("failed to update status of the module: %w", err)
@@ -191,17 +459,187 @@ func (r *ModuleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 	return res, nil
 }
 
+// handleKernelMapping builds and signs the image for a kernel mapping if needed, and then creates or patches the
+// module-loader DaemonSet for that kernel.
+// It returns the phase of the kernel: Building or Signing if the Module should be requeued, Failed if a Job failed or
+// the image could not be verified, and Pending on other errors, along with the failure of the build or sign Job if any.
+// It is called concurrently for different kernels and must therefore not write to mod or dsByKernelVersion.
+func (r *ModuleReconciler) handleKernelMapping(ctx context.Context,
+	mod *kmmv1beta1.Module,
+	m *kmmv1beta1.KernelMapping,
+	dsByKernelVersion map[string]*appsv1.DaemonSet,
+	kernelVersion string,
+	osProfile daemonset.OSProfile) (kmmv1beta1.KernelPhase, *kmmv1beta1.JobFailure, error) {
+
+	logger := log.FromContext(ctx)
+
+	requeue, err := r.handleBuild(ctx, mod, m, kernelVersion)
+	if err != nil {
+		if missingErr := r.checkSecrets(ctx, mod, m); missingErr != nil {
+			return kmmv1beta1.KernelPhasePending, nil, fmt.Errorf("kernel version %s: %w", kernelVersion, missingErr)
+		}
+
+		jf, jobErr := r.reportJobFailure(ctx, mod, utils.JobTypeBuild, kernelVersion, err)
+		return failedPhase(jobErr, utils.ErrJobFailed), jf, fmt.Errorf("failed to handle build for kernel version %s: %w", kernelVersion, jobErr)
+	}
+	if requeue {
+		logger.Info("Build requires a requeue; skipping handling driver container for now", "kernelVersion", kernelVersion, "image", m)
+		return kmmv1beta1.KernelPhaseBuilding, nil, nil
+	}
+
+	signrequeue, err := r.handleSigning(ctx, mod, m, kernelVersion)
+	if err != nil {
+		if missingErr := r.checkSecrets(ctx, mod, m); missingErr != nil {
+			return kmmv1beta1.KernelPhasePending, nil, fmt.Errorf("kernel version %s: %w", kernelVersion, missingErr)
+		}
+
+		jf, jobErr := r.reportJobFailure(ctx, mod, utils.JobTypeSign, kernelVersion, err)
+		return failedPhase(jobErr, utils.ErrJobFailed), jf, fmt.Errorf("failed to handle signing for kernel version %s: %w", kernelVersion, jobErr)
+	}
+	if signrequeue {
+		logger.Info("Signing requires a requeue; skipping handling driver container for now", "kernelVersion", kernelVersion, "image", m)
+		return kmmv1beta1.KernelPhaseSigning, nil, nil
+	}
+
+	if m, err = r.verifyProvenance(ctx, mod, m); err != nil {
+		return failedPhase(err, provenance.ErrVerificationFailed), nil, fmt.Errorf("kernel version %s: %w", kernelVersion, err)
+	}
+
+	if err = r.handleDriverContainer(ctx, mod, m, dsByKernelVersion, kernelVersion, osProfile); err != nil {
+		return kmmv1beta1.KernelPhasePending, nil, fmt.Errorf("failed to handle driver container for kernel version %s: %v", kernelVersion, err)
+	}
+
+	if ds := dsByKernelVersion[kernelVersion]; ds != nil && daemonSetReady(ds) {
+		return kmmv1beta1.KernelPhaseReady, nil, nil
+	}
+
+	return kmmv1beta1.KernelPhaseDeploying, nil, nil
+}
+
+// kernelOSProfiles returns the OS profile of the first of nodes running each kernel.
+// Nodes running the same kernel are expected to run the same operating system.
+func kernelOSProfiles(nodes []v1.Node) map[string]daemonset.OSProfile {
+	profiles := make(map[string]daemonset.OSProfile)
+
+	for _, n := range nodes {
+		kernelVersion := strings.TrimSuffix(n.Status.NodeInfo.KernelVersion, "+")
+
+		if _, ok := profiles[kernelVersion]; !ok {
+			profiles[kernelVersion] = daemonset.OSProfileForImage(n.Status.NodeInfo.OSImage)
+		}
+	}
+
+	return profiles
+}
+
+// recordNodeDecisions records in d the nodes targeted by a Module and why it is not deployed on some of them.
+func recordNodeDecisions(
+	d *decisions.Module,
+	targetedNodes []v1.Node,
+	nodesWithMapping []v1.Node,
+	conflictingNodes []v1.Node,
+	mappings map[string]*kmmv1beta1.KernelMapping) {
+	if d == nil {
+		return
+	}
+
+	withMapping := sets.NewString()
+
+	for _, n := range nodesWithMapping {
+		withMapping.Insert(n.Name)
+	}
+
+	conflicting := sets.NewString()
+
+	for _, n := range conflictingNodes {
+		conflicting.Insert(n.Name)
+	}
+
+	for _, n := range targetedNodes {
+		kernelVersion := strings.TrimSuffix(n.Status.NodeInfo.KernelVersion, "+")
+		reason := ""
+
+		if conflicting.Has(n.Name) {
+			reason = "another kernel mapping is used for the kernel on other nodes"
+		} else if !withMapping.Has(n.Name) {
+			reason = "no kernel mapping matches the kernel"
+		} else if _, ok := mappings[kernelVersion]; !ok {
+			reason = "the kernel variables could not be substituted in the kernel mapping, or its image could not be resolved"
+		}
+
+		d.AddNode(n.Name, kernelVersion, reason)
+	}
+}
+
+// recordKernelDecisions records in d the kernels for which a Module has a kernel mapping, and their phase.
+func recordKernelDecisions(
+	d *decisions.Module,
+	mod *kmmv1beta1.Module,
+	mappings map[string]*kmmv1beta1.KernelMapping,
+	kernelStatuses []kmmv1beta1.KernelStatus) {
+	if d == nil {
+		return
+	}
+
+	for _, ks := range kernelStatuses {
+		m := mappings[ks.KernelVersion]
+
+		d.AddKernel(decisions.Kernel{
+			KernelVersion:  ks.KernelVersion,
+			ContainerImage: m.ContainerImage,
+			Build:          module.ShouldBeBuilt(mod.Spec, *m),
+			Sign:           module.ShouldBeSigned(mod.Spec, *m),
+			Phase:          string(ks.Phase),
+			Error:          ks.Message,
+		})
+	}
+}
+
+// getRelevantKernelMappingsAndNodes returns the kernel mapping of each kernel run by targetedNodes, the nodes on which
+// the Module is deployed and those that are skipped because they select another mapping than the one used for their
+// kernel.
 func (r *ModuleReconciler) getRelevantKernelMappingsAndNodes(ctx context.Context,
 	mod *kmmv1beta1.Module,
-	targetedNodes []v1.Node) (map[string]*kmmv1beta1.KernelMapping, []v1.Node, error) {
+	targetedNodes []v1.Node) (map[string]*kmmv1beta1.KernelMapping, []v1.Node, []v1.Node, error) {
 
 	mappings := make(map[string]*kmmv1beta1.KernelMapping)
 	logger := log.FromContext(ctx)
 
-	nodes := make([]v1.Node, 0, len(targetedNodes))
+	kernelMappings := mod.Spec.ModuleLoader.Container.KernelMappings
+
+	// The DaemonSet, build and signing of a kernel can only use one mapping. The first mapping of the spec that is
+	// selected by a node running the kernel is used, so that the choice does not depend on the order of the nodes.
+	type nodeMapping struct {
+		mapping  *kmmv1beta1.KernelMapping
+		index    int
+		osConfig *module.NodeOSConfig
+	}
+
+	nodeMappings := make(map[string]nodeMapping, len(targetedNodes))
+	kernelMappingIndexes := make(map[string]int)
 
 	for _, node := range targetedNodes {
 		osConfig := r.kernelAPI.GetNodeOSConfig(&node)
+
+		m, err := r.kernelAPI.FindMappingForNode(kernelMappings, &node)
+		if err != nil {
+			continue
+		}
+
+		idx := mappingIndex(kernelMappings, m)
+		kernelVersion := strings.TrimSuffix(node.Status.NodeInfo.KernelVersion, "+")
+
+		nodeMappings[node.Name] = nodeMapping{mapping: m, index: idx, osConfig: osConfig}
+
+		if cur, ok := kernelMappingIndexes[kernelVersion]; !ok || idx < cur {
+			kernelMappingIndexes[kernelVersion] = idx
+		}
+	}
+
+	nodes := make([]v1.Node, 0, len(targetedNodes))
+	conflictingNodes := make([]v1.Node, 0)
+
+	for _, node := range targetedNodes {
 		kernelVersion := strings.TrimSuffix(node.Status.NodeInfo.KernelVersion, "+")
 
 		nodeLogger := logger.WithValues(
@@ -209,25 +647,40 @@ func (r *ModuleReconciler) getRelevantKernelMappingsAndNodes(ctx context.Context
 			"kernel version", kernelVersion,
 		)
 
-		if image, ok := mappings[kernelVersion]; ok {
-			nodes = append(nodes, node)
-			nodeLogger.V(1).Info("Using cached image", "image", image)
+		nm, ok := nodeMappings[node.Name]
+		if !ok {
+			nodeLogger.Info("no suitable container image found; skipping node")
 			continue
 		}
 
-		m, err := r.kernelAPI.FindMappingForKernel(mod.Spec.ModuleLoader.Container.KernelMappings, kernelVersion)
-		if err != nil {
-			nodeLogger.Info("no suitable container image found; skipping node")
+		if nm.index != kernelMappingIndexes[kernelVersion] {
+			nodeLogger.Info("another kernel mapping is used for this kernel on other nodes; skipping node")
+			conflictingNodes = append(conflictingNodes, node)
 			continue
 		}
 
-		m, err = r.kernelAPI.PrepareKernelMapping(m, osConfig)
+		if image, ok := mappings[kernelVersion]; ok {
+			nodes = append(nodes, node)
+			nodeLogger.V(1).Info("Using cached image", "image", image)
+			continue
+		}
+
+		m, err := r.kernelAPI.PrepareKernelMapping(nm.mapping, nm.osConfig)
 		if err != nil {
 			nodes = append(nodes, node)
 			nodeLogger.Info("failed to substitute the template variables in the mapping", "error", err)
 			continue
 		}
 
+		// imageStreamAPI is nil when the operator does not resolve ImageStreamTags.
+		if r.imageStreamAPI != nil {
+			if m.ContainerImage, err = r.imageStreamAPI.Resolve(ctx, m.ContainerImage, mod.Namespace); err != nil {
+				nodes = append(nodes, node)
+				nodeLogger.Info("failed to resolve the image of the mapping", "error", err)
+				continue
+			}
+		}
+
 		nodeLogger.V(1).Info("Found a valid mapping",
 			"image", m.ContainerImage,
 			"build", m.Build != nil,
@@ -236,7 +689,18 @@ func (r *ModuleReconciler) getRelevantKernelMappingsAndNodes(ctx context.Context
 		mappings[kernelVersion] = m
 		nodes = append(nodes, node)
 	}
-	return mappings, nodes, nil
+	return mappings, nodes, conflictingNodes, nil
+}
+
+// mappingIndex returns the index of m in mappings, or -1 if it is not one of them.
+func mappingIndex(mappings []kmmv1beta1.KernelMapping, m *kmmv1beta1.KernelMapping) int {
+	for i := range mappings {
+		if reflect.DeepEqual(&mappings[i], m) {
+			return i
+		}
+	}
+
+	return -1
 }
 
 func (r *ModuleReconciler) getNodesListBySelector(ctx context.Context, mod *kmmv1beta1.Module) ([]v1.Node, error) {
@@ -327,11 +791,171 @@ func (r *ModuleReconciler) handleSigning(ctx context.Context,
 	return signRes.Requeue, nil
 }
 
+// checkSecrets returns an error wrapping errSecretNotFound if one of the Secrets needed to build, sign or pull the
+// image of m does not exist.
+// It is only called when building or signing failed, so that Modules can be created before their Secrets without
+// their reconciliation failing repeatedly.
+func (r *ModuleReconciler) checkSecrets(ctx context.Context, mod *kmmv1beta1.Module, m *kmmv1beta1.KernelMapping) error {
+	names := make([]string, 0)
+
+	if mod.Spec.ImageRepoSecret != nil {
+		names = append(names, mod.Spec.ImageRepoSecret.Name)
+	}
+
+	if module.ShouldBeBuilt(mod.Spec, *m) {
+		for _, s := range build.NewHelper().GetRelevantBuild(mod.Spec, *m).Secrets {
+			names = append(names, s.Name)
+		}
+	}
+
+	if module.ShouldBeSigned(mod.Spec, *m) {
+		signConfig := sign.NewSignerHelper().GetRelevantSign(mod.Spec, *m)
+
+		for _, ref := range []*v1.LocalObjectReference{signConfig.KeySecret, signConfig.CertSecret} {
+			if ref != nil {
+				names = append(names, ref.Name)
+			}
+		}
+	}
+
+	for _, name := range names {
+		nn := types.NamespacedName{Namespace: mod.Namespace, Name: name}
+
+		if err := r.Get(ctx, nn, &v1.Secret{}); k8serrors.IsNotFound(err) {
+			return fmt.Errorf("%w %s", errSecretNotFound, nn)
+		}
+	}
+
+	return nil
+}
+
+// handleRebuildRequest deletes the failed build and sign Jobs of mod for the kernels listed in its
+// RebuildKernelsAnnotation, so that they are created again, and then removes the annotation.
+func (r *ModuleReconciler) handleRebuildRequest(ctx context.Context, mod *kmmv1beta1.Module) error {
+	if err := r.deleteFailedJobs(ctx, mod, mod.Annotations[constants.RebuildKernelsAnnotation]); err != nil {
+		return err
+	}
+
+	modCopy := mod.DeepCopy()
+
+	delete(mod.Annotations, constants.RebuildKernelsAnnotation)
+
+	if err := r.Client.Patch(ctx, mod, client.MergeFrom(modCopy)); err != nil {
+		return fmt.Errorf("could not remove the %s annotation: %v", constants.RebuildKernelsAnnotation, err)
+	}
+
+	return nil
+}
+
+// retryBuildPending returns true if the RetryBuildAnnotation of mod was set, changed or removed since the operator
+// last acted upon it.
+func retryBuildPending(mod *kmmv1beta1.Module) bool {
+	retry, ok := mod.Annotations[constants.RetryBuildAnnotation]
+	handled, handledOK := mod.Annotations[constants.RetryBuildHandledAnnotation]
+
+	return ok != handledOK || retry != handled
+}
+
+// handleRetryBuild deletes the failed build and sign Jobs of mod for the kernels listed in its RetryBuildAnnotation and
+// records the value of the annotation in the RetryBuildHandledAnnotation, so that the Jobs are created again exactly
+// once even though GitOps tools keep the annotation on the Module.
+// If RetryBuildAnnotation was removed, it only removes the RetryBuildHandledAnnotation, so that setting the same value
+// again triggers another retry.
+func (r *ModuleReconciler) handleRetryBuild(ctx context.Context, mod *kmmv1beta1.Module) error {
+	modCopy := mod.DeepCopy()
+
+	if retry, ok := mod.Annotations[constants.RetryBuildAnnotation]; ok {
+		if err := r.deleteFailedJobs(ctx, mod, retry); err != nil {
+			return err
+		}
+
+		mod.Annotations[constants.RetryBuildHandledAnnotation] = retry
+	} else {
+		delete(mod.Annotations, constants.RetryBuildHandledAnnotation)
+	}
+
+	if err := r.Client.Patch(ctx, mod, client.MergeFrom(modCopy)); err != nil {
+		return fmt.Errorf("could not update the %s annotation: %v", constants.RetryBuildHandledAnnotation, err)
+	}
+
+	return nil
+}
+
+// deleteFailedJobs deletes the failed build and sign Jobs of mod for kernels, a comma-separated list of kernel
+// versions.
+func (r *ModuleReconciler) deleteFailedJobs(ctx context.Context, mod *kmmv1beta1.Module, kernels string) error {
+	logger := log.FromContext(ctx)
+
+	for _, kernelVersion := range strings.Split(kernels, ",") {
+		kernelVersion = strings.TrimSuffix(strings.TrimSpace(kernelVersion), "+")
+		if kernelVersion == "" {
+			continue
+		}
+
+		jobs := batchv1.JobList{}
+
+		opts := []client.ListOption{
+			client.InNamespace(mod.Namespace),
+			client.MatchingLabels{
+				constants.ModuleNameLabel:    mod.Name,
+				constants.TargetKernelTarget: kernelVersion,
+			},
+		}
+
+		if err := r.Client.List(ctx, &jobs, opts...); err != nil {
+			return fmt.Errorf("could not list the Jobs for kernel version %s: %v", kernelVersion, err)
+		}
+
+		for i := 0; i < len(jobs.Items); i++ {
+			job := &jobs.Items[i]
+
+			if job.Status.Failed == 0 || !metav1.IsControlledBy(job, mod) {
+				continue
+			}
+
+			logger.Info("Deleting failed Job on request", "name", job.Name, "kernel version", kernelVersion)
+
+			err := r.Client.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+			if client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("could not delete Job %s: %v", job.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// verifyProvenance verifies the provenance of pre-built module-loader images if the Module has a provenance policy.
+// It returns the kernel mapping to deploy: a copy of km whose image is pinned to the verified digest, or km itself if
+// its image is not verified.
+func (r *ModuleReconciler) verifyProvenance(
+	ctx context.Context,
+	mod *kmmv1beta1.Module,
+	km *kmmv1beta1.KernelMapping,
+) (*kmmv1beta1.KernelMapping, error) {
+	if mod.Spec.ModuleLoader.Container.Provenance == nil ||
+		module.ShouldBeBuilt(mod.Spec, *km) ||
+		module.ShouldBeSigned(mod.Spec, *km) {
+		return km, nil
+	}
+
+	image, err := r.provenanceAPI.Verify(ctx, mod, km)
+	if err != nil {
+		return nil, err
+	}
+
+	pinned := km.DeepCopy()
+	pinned.ContainerImage = image
+
+	return pinned, nil
+}
+
 func (r *ModuleReconciler) handleDriverContainer(ctx context.Context,
 	mod *kmmv1beta1.Module,
 	km *kmmv1beta1.KernelMapping,
 	dsByKernelVersion map[string]*appsv1.DaemonSet,
-	kernelVersion string) error {
+	kernelVersion string,
+	osProfile daemonset.OSProfile) error {
 	ds := &appsv1.DaemonSet{
 		ObjectMeta: metav1.ObjectMeta{Namespace: mod.Namespace},
 	}
@@ -342,11 +966,11 @@ func (r *ModuleReconciler) handleDriverContainer(ctx context.Context,
 		ds = existingDS
 	} else {
 		logger.Info("creating new driver container DS", "kernel version", kernelVersion, "image", km)
-		ds.GenerateName = mod.Name + "-"
+		ds.Name = daemonset.DriverContainerName(mod.Name, kernelVersion)
 	}
 
 	opRes, err := controllerutil.CreateOrPatch(ctx, r.Client, ds, func() error {
-		return r.daemonAPI.SetDriverContainerAsDesired(ctx, ds, km.ContainerImage, *mod, kernelVersion)
+		return r.daemonAPI.SetDriverContainerAsDesired(ctx, ds, *km, *mod, kernelVersion, osProfile)
 	})
 
 	if err == nil {
@@ -389,6 +1013,33 @@ func (r *ModuleReconciler) handleDevicePlugin(ctx context.Context, mod *kmmv1bet
 	return err
 }
 
+// handleCompanions creates or patches the Deployment of each companion of mod.
+func (r *ModuleReconciler) handleCompanions(ctx context.Context, mod *kmmv1beta1.Module) error {
+	logger := log.FromContext(ctx)
+
+	for i := range mod.Spec.Companions {
+		companion := &mod.Spec.Companions[i]
+
+		deploy := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      daemonset.CompanionDeploymentName(mod.Name, companion.Name),
+				Namespace: mod.Namespace,
+			},
+		}
+
+		opRes, err := controllerutil.CreateOrPatch(ctx, r.Client, deploy, func() error {
+			return r.daemonAPI.SetCompanionAsDesired(ctx, deploy, mod, companion)
+		})
+		if err != nil {
+			return fmt.Errorf("could not reconcile the Deployment of companion %s: %v", companion.Name, err)
+		}
+
+		logger.Info("Reconciled companion", "name", deploy.Name, "result", opRes)
+	}
+
+	return nil
+}
+
 func (r *ModuleReconciler) garbageCollect(ctx context.Context,
 	mod *kmmv1beta1.Module,
 	mappings map[string]*kmmv1beta1.KernelMapping,
@@ -404,6 +1055,14 @@ func (r *ModuleReconciler) garbageCollect(ctx context.Context,
 
 	logger.Info("Garbage-collected DaemonSets", "names", deleted)
 
+	// Garbage collect the Deployments of companions removed from the Module.
+	deleted, err = r.daemonAPI.GarbageCollectCompanions(ctx, mod)
+	if err != nil {
+		return fmt.Errorf("could not garbage collect companion Deployments: %v", err)
+	}
+
+	logger.Info("Garbage-collected companion Deployments", "names", deleted)
+
 	// Garbage collect for successfully finished build jobs
 	deleted, err = r.buildAPI.GarbageCollect(ctx, mod.Name, mod.Namespace, mod)
 	if err != nil {
@@ -412,19 +1071,14 @@ func (r *ModuleReconciler) garbageCollect(ctx context.Context,
 
 	logger.Info("Garbage-collected Build objects", "names", deleted)
 
-	return nil
-}
-
-func (r *ModuleReconciler) setKMMOMetrics(ctx context.Context) {
-	logger := log.FromContext(ctx)
-
-	mods := kmmv1beta1.ModuleList{}
-	err := r.Client.List(ctx, &mods)
-	if err != nil {
-		logger.V(1).Info("failed to list KMMomodules for metrics", "error", err)
+	// The build and sign NetworkPolicy is only needed as long as the Module builds or signs.
+	if r.networkPolicyAPI != nil && !buildsOrSigns(mod) {
+		if err = r.networkPolicyAPI.DeleteBuildSignNetworkPolicy(ctx, *mod); err != nil {
+			return fmt.Errorf("could not garbage collect the build and sign NetworkPolicy: %v", err)
+		}
 	}
 
-	r.metricsAPI.SetExistingKMMOModules(len(mods.Items))
+	return nil
 }
 
 func (r *ModuleReconciler) getRequestedModule(ctx context.Context, namespacedName types.NamespacedName) (*kmmv1beta1.Module, error) {
@@ -437,28 +1091,291 @@ func (r *ModuleReconciler) getRequestedModule(ctx context.Context, namespacedNam
 }
 
 // SetupWithManager sets up the controller with the Manager.
-func (r *ModuleReconciler) SetupWithManager(mgr ctrl.Manager, kernelLabel string) error {
+// Only the Modules in the namespaces of s are reconciled.
+// Modules are reconciled when any of kernelLabels changes on the nodes.
+func (r *ModuleReconciler) SetupWithManager(mgr ctrl.Manager, kernelLabels []string, s *shard.Shard, opts controller.Options) error {
+	inShard := builder.WithPredicates(s.Predicate())
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&kmmv1beta1.Module{}).
-		Owns(&appsv1.DaemonSet{}).
-		Owns(&v1.ServiceAccount{}).
-		Owns(&batchv1.Job{}).
+		For(&kmmv1beta1.Module{}, inShard).
+		Owns(&appsv1.DaemonSet{}, inShard).
+		Owns(&appsv1.Deployment{}, inShard).
+		Owns(&v1.ServiceAccount{}, inShard).
+		Owns(&batchv1.Job{}, inShard).
+		// Only the metadata of Secrets and ConfigMaps is cached; the resource version is enough to know that they
+		// changed.
+		Watches(
+			&source.Kind{Type: &v1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(s.MapFunc(r.filter.FindModulesForSecret)),
+			builder.OnlyMetadata,
+		).
+		Watches(
+			&source.Kind{Type: &v1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(s.MapFunc(r.filter.FindModulesForConfigMap)),
+			builder.OnlyMetadata,
+		).
 		Watches(
 			&source.Kind{Type: &v1.Node{}},
-			handler.EnqueueRequestsFromMapFunc(r.filter.FindModulesForNode),
+			filter.EnqueueRequestsFromMapFuncAfter(s.MapFunc(r.filter.FindModulesForNode), nodeEventsCoalescingDelay),
 			builder.WithPredicates(
-				r.filter.ModuleReconcilerNodePredicate(kernelLabel),
+				r.filter.ModuleReconcilerNodePredicate(kernelLabels...),
 			),
 		).
 		Named(ModuleReconcilerName).
+		WithOptions(opts).
 		Complete(r)
 }
 
+// reportJobFailure emits a Warning Event on mod with the last lines of the logs of the Job if err is a
+// utils.JobFailedError, and returns err with those lines appended so that they also show in the status of mod, along
+// with the failure to record in the status of mod.
+// Other errors are returned unchanged, with a nil failure.
+func (r *ModuleReconciler) reportJobFailure(
+	ctx context.Context,
+	mod *kmmv1beta1.Module,
+	jobType string,
+	kernelVersion string,
+	err error,
+) (*kmmv1beta1.JobFailure, error) {
+	var jfe *utils.JobFailedError
+
+	if !errors.As(err, &jfe) {
+		return nil, err
+	}
+
+	logs, logErr := r.jobLogAPI.LogTail(ctx, mod.Namespace, jfe.JobName)
+	if logErr != nil {
+		log.FromContext(ctx).Info(utils.WarnString("could not get the logs of the failed Job"), "job", jfe.JobName, "error", logErr)
+		logs = "logs unavailable: " + logErr.Error()
+	}
+
+	reason := jobFailedEventReasons[jobType]
+
+	r.recorder.Eventf(mod, v1.EventTypeWarning, reason, "%s Job %s failed for kernel %s:\n%s", jobType, jfe.JobName, kernelVersion, logs)
+
+	jf := kmmv1beta1.JobFailure{
+		KernelVersion: kernelVersion,
+		JobType:       jobType,
+		JobName:       jfe.JobName,
+		Reason:        jfe.Reason,
+		FailureTime:   jfe.FailureTime,
+		Logs:          logs,
+	}
+
+	return &jf, fmt.Errorf("%w; last lines of the logs:\n%s", err, logs)
+}
+
+// kernelConditions returns the Built, Signed and RolledOut conditions of a kernel in phase, given whether its kernel
+// mapping builds and signs the image and the failure of its build or sign Job, if any.
+// The step in progress or that failed is False, the previous ones are True and the next ones Unknown; steps that the
+// kernel mapping does not require are True.
+// Pending kernels have no conditions, as the step that is pending is not known; their previous conditions are kept.
+func kernelConditions(generation int64, phase kmmv1beta1.KernelPhase, build, sign bool, jf *kmmv1beta1.JobFailure) []metav1.Condition {
+	// current is the index in steps of the step in progress or that failed; len(steps) once all steps are done.
+	var (
+		current int
+		reason  = string(phase)
+		message string
+	)
+
+	switch phase {
+	case kmmv1beta1.KernelPhaseBuilding:
+		current, message = 0, "The build Job is running"
+	case kmmv1beta1.KernelPhaseSigning:
+		current, message = 1, "The sign Job is running"
+	case kmmv1beta1.KernelPhaseDeploying:
+		current, message = 2, "The module-loader pods are not all up-to-date and available"
+	case kmmv1beta1.KernelPhaseReady:
+		current = 3
+	case kmmv1beta1.KernelPhaseFailed:
+		// Only the verification of the image fails without a Job failure.
+		current, reason, message = 2, "VerificationFailed", "The image could not be verified"
+
+		if jf != nil {
+			current, reason, message = 1, "JobFailed", fmt.Sprintf("Job %s failed", jf.JobName)
+
+			if jf.JobType == utils.JobTypeBuild {
+				current = 0
+			}
+		}
+	default:
+		return nil
+	}
+
+	steps := []struct {
+		conditionType string
+		required      bool
+	}{
+		{conditionType: kmmv1beta1.KernelConditionBuilt, required: build},
+		{conditionType: kmmv1beta1.KernelConditionSigned, required: sign},
+		{conditionType: kmmv1beta1.KernelConditionRolledOut, required: true},
+	}
+
+	conditions := make([]metav1.Condition, 0, len(steps))
+
+	for i, step := range steps {
+		c := metav1.Condition{Type: step.conditionType, ObservedGeneration: generation}
+
+		switch {
+		case !step.required:
+			c.Status, c.Reason, c.Message = metav1.ConditionTrue, "NotRequired", "The kernel mapping does not require this step"
+		case i < current:
+			c.Status, c.Reason = metav1.ConditionTrue, "Succeeded"
+		case i == current:
+			c.Status, c.Reason, c.Message = metav1.ConditionFalse, reason, message
+		default:
+			c.Status, c.Reason, c.Message = metav1.ConditionUnknown, "WaitingForPreviousSteps", "Waiting for the previous steps to succeed"
+		}
+
+		conditions = append(conditions, c)
+	}
+
+	return conditions
+}
+
+// failedPhase returns Failed if err is target, and Pending otherwise.
+func failedPhase(err, target error) kmmv1beta1.KernelPhase {
+	if errors.Is(err, target) {
+		return kmmv1beta1.KernelPhaseFailed
+	}
+
+	return kmmv1beta1.KernelPhasePending
+}
+
+// daemonSetReady returns true if the pods of ds are up-to-date and available on all the nodes it targets.
+func daemonSetReady(ds *appsv1.DaemonSet) bool {
+	desired := ds.Status.DesiredNumberScheduled
+
+	return desired > 0 && ds.Status.UpdatedNumberScheduled == desired && ds.Status.NumberAvailable == desired
+}
+
+// isNodeSchedulable returns false if node has a NoSchedule taint other than the startup taint, which module-loader
+// pods tolerate.
 func isNodeSchedulable(node *v1.Node) bool {
 	for _, taint := range node.Spec.Taints {
-		if taint.Effect == v1.TaintEffectNoSchedule {
+		if taint.Effect == v1.TaintEffectNoSchedule && taint.Key != constants.ModulesNotReadyTaint {
 			return false
 		}
 	}
 	return true
 }
+
+// buildsOrSigns returns true if any of mod's kernel mappings is built or signed in-cluster.
+func buildsOrSigns(mod *kmmv1beta1.Module) bool {
+	for _, km := range mod.Spec.ModuleLoader.Container.KernelMappings {
+		if module.ShouldBeBuilt(mod.Spec, km) || module.ShouldBeSigned(mod.Spec, km) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// specRejectedCondition returns the SpecRejected condition of mod given the error returned by its validation.
+func specRejectedCondition(mod *kmmv1beta1.Module, err error) metav1.Condition {
+	if err == nil {
+		return metav1.Condition{
+			Type:               kmmv1beta1.ModuleConditionSpecRejected,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: mod.Generation,
+			Reason:             "SpecAllowed",
+			Message:            "The Module's spec is allowed",
+		}
+	}
+
+	return metav1.Condition{
+		Type:               kmmv1beta1.ModuleConditionSpecRejected,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: mod.Generation,
+		Reason:             "SpecNotAllowed",
+		Message:            err.Error(),
+	}
+}
+
+// fipsCondition returns the FIPS condition of mod.
+func fipsCondition(mod *kmmv1beta1.Module, fipsMode bool) metav1.Condition {
+	if !fipsMode {
+		return metav1.Condition{
+			Type:               kmmv1beta1.ModuleConditionFIPS,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: mod.Generation,
+			Reason:             "FIPSModeDisabled",
+			Message:            "The operator does not run in FIPS mode",
+		}
+	}
+
+	return metav1.Condition{
+		Type:               kmmv1beta1.ModuleConditionFIPS,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: mod.Generation,
+		Reason:             "FIPSModeEnabled",
+		Message:            "The operator uses FIPS-validated cryptography and only accepts FIPS-compliant signing certificates",
+	}
+}
+
+// maxMappingConflictNodes is the number of nodes listed in the message of the MappingConflict condition.
+const maxMappingConflictNodes = 10
+
+// mappingConflictCondition returns the MappingConflict condition of mod given the nodes that are skipped because they
+// select another kernel mapping than the one used for their kernel.
+func mappingConflictCondition(mod *kmmv1beta1.Module, conflictingNodes []v1.Node) metav1.Condition {
+	if len(conflictingNodes) == 0 {
+		return metav1.Condition{
+			Type:               kmmv1beta1.ModuleConditionMappingConflict,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: mod.Generation,
+			Reason:             "NoMappingConflict",
+			Message:            "All nodes running the same kernel select the same kernel mapping",
+		}
+	}
+
+	names := make([]string, 0, len(conflictingNodes))
+
+	for _, n := range conflictingNodes {
+		names = append(names, fmt.Sprintf("%s (%s)", n.Name, strings.TrimSuffix(n.Status.NodeInfo.KernelVersion, "+")))
+	}
+
+	// The nodes are listed in no particular order; sort them so that the message is stable.
+	sort.Strings(names)
+
+	if len(names) > maxMappingConflictNodes {
+		names = append(names[:maxMappingConflictNodes], fmt.Sprintf("and %d more", len(names)-maxMappingConflictNodes))
+	}
+
+	return metav1.Condition{
+		Type:               kmmv1beta1.ModuleConditionMappingConflict,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: mod.Generation,
+		Reason:             "NodesSkipped",
+		Message: fmt.Sprintf(
+			"%d node(s) select another kernel mapping than the one used for their kernel and are skipped: %s",
+			len(conflictingNodes),
+			strings.Join(names, ", "),
+		),
+	}
+}
+
+// provenanceCondition returns the Degraded condition of mod given the provenance verification failures of its kernel
+// mappings.
+func provenanceCondition(mod *kmmv1beta1.Module, unverified []string) metav1.Condition {
+	if len(unverified) == 0 {
+		return metav1.Condition{
+			Type:               kmmv1beta1.ModuleConditionDegraded,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: mod.Generation,
+			Reason:             "ProvenanceVerified",
+			Message:            "The provenance of all pre-built images satisfies the policy",
+		}
+	}
+
+	// Kernel mappings are handled concurrently; sort the failures so that the message is stable.
+	sort.Strings(unverified)
+
+	return metav1.Condition{
+		Type:               kmmv1beta1.ModuleConditionDegraded,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: mod.Generation,
+		Reason:             "ProvenanceVerificationFailed",
+		Message:            strings.Join(unverified, "\n"),
+	}
+}
@@ -2,27 +2,39 @@ package controllers
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/golang/mock/gomock"
 	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/build"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/client"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/daemonset"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/decisions"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/imagestream"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/metrics"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/networkpolicy"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/podsecurity"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/provenance"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/rbac"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/sign"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/statusupdater"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/utils"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/validation"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -75,7 +87,7 @@ var _ = Describe("ModuleReconciler_Reconcile", func() {
 				apierrors.NewNotFound(schema.GroupResource{}, moduleName),
 			)
 
-		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, mockDC, mockKM, mockMetrics, nil, mockSU)
+		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, nil, nil, mockDC, nil, validation.NewValidator(nil, nil, nil), mockKM, mockMetrics, nil, mockSU, nil, nil, nil, nil, false, false)
 		Expect(
 			mr.Reconcile(ctx, req),
 		).To(
@@ -83,6 +95,173 @@ var _ = Describe("ModuleReconciler_Reconcile", func() {
 		)
 	})
 
+	It("should restore the labels of the namespace if the Module is not available anymore", func() {
+		mockNL := podsecurity.NewMockNamespaceLabeler(ctrl)
+
+		gomock.InOrder(
+			clnt.
+				EXPECT().
+				Get(ctx, nsn, &kmmv1beta1.Module{}).
+				Return(
+					apierrors.NewNotFound(schema.GroupResource{}, moduleName),
+				),
+			mockNL.EXPECT().RestorePrevious(ctx, namespace),
+		)
+
+		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, nil, mockNL, mockDC, nil, validation.NewValidator(nil, nil, nil), mockKM, mockMetrics, nil, mockSU, nil, nil, nil, nil, false, false)
+		Expect(
+			mr.Reconcile(ctx, req),
+		).To(
+			Equal(reconcile.Result{}),
+		)
+	})
+
+	It("should not deploy a Module whose spec is rejected", func() {
+		mod := kmmv1beta1.Module{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      moduleName,
+				Namespace: namespace,
+			},
+		}
+
+		mockV := validation.NewMockValidator(ctrl)
+
+		gomock.InOrder(
+			clnt.EXPECT().Get(ctx, req.NamespacedName, gomock.Any()).DoAndReturn(
+				func(_ interface{}, _ interface{}, m *kmmv1beta1.Module, _ ...ctrlclient.GetOption) error {
+					m.ObjectMeta = mod.ObjectMeta
+					return nil
+				},
+			),
+			mockV.EXPECT().ValidateModule(&mod).Return(errors.New("some error")),
+			mockSU.EXPECT().ModuleSetCondition(ctx, &mod, specRejectedCondition(&mod, errors.New("some error"))),
+		)
+
+		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, nil, nil, mockDC, nil, mockV, mockKM, mockMetrics, nil, mockSU, nil, nil, nil, nil, false, false)
+
+		res, err := mr.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res).To(Equal(reconcile.Result{}))
+	})
+
+	It("should clear the SpecRejected condition once the spec is allowed", func() {
+		mod := kmmv1beta1.Module{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      moduleName,
+				Namespace: namespace,
+			},
+			Status: kmmv1beta1.ModuleStatus{
+				Conditions: []metav1.Condition{
+					{Type: kmmv1beta1.ModuleConditionSpecRejected, Status: metav1.ConditionTrue},
+				},
+			},
+		}
+
+		gomock.InOrder(
+			clnt.EXPECT().Get(ctx, req.NamespacedName, gomock.Any()).DoAndReturn(
+				func(_ interface{}, _ interface{}, m *kmmv1beta1.Module, _ ...ctrlclient.GetOption) error {
+					m.ObjectMeta = mod.ObjectMeta
+					m.Status = mod.Status
+					return nil
+				},
+			),
+			mockSU.EXPECT().ModuleSetCondition(ctx, &mod, specRejectedCondition(&mod, nil)).Return(errors.New("some error")),
+		)
+
+		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, nil, nil, mockDC, nil, validation.NewValidator(nil, nil, nil), mockKM, mockMetrics, nil, mockSU, nil, nil, nil, nil, false, false)
+
+		_, err := mr.Reconcile(ctx, req)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return an error if the FIPS condition cannot be set in FIPS mode", func() {
+		mod := kmmv1beta1.Module{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      moduleName,
+				Namespace: namespace,
+			},
+		}
+
+		gomock.InOrder(
+			clnt.EXPECT().Get(ctx, req.NamespacedName, gomock.Any()).DoAndReturn(
+				func(_ interface{}, _ interface{}, m *kmmv1beta1.Module, _ ...ctrlclient.GetOption) error {
+					m.ObjectMeta = mod.ObjectMeta
+					return nil
+				},
+			),
+			mockSU.EXPECT().ModuleSetCondition(ctx, &mod, fipsCondition(&mod, true)).Return(errors.New("some error")),
+		)
+
+		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, nil, nil, mockDC, nil, validation.NewValidator(nil, nil, nil), mockKM, mockMetrics, nil, mockSU, nil, nil, nil, nil, true, false)
+
+		_, err := mr.Reconcile(ctx, req)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return an error if the Module's namespace cannot be labeled", func() {
+		mod := kmmv1beta1.Module{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      moduleName,
+				Namespace: namespace,
+			},
+		}
+
+		mockNL := podsecurity.NewMockNamespaceLabeler(ctrl)
+
+		gomock.InOrder(
+			clnt.EXPECT().Get(ctx, req.NamespacedName, gomock.Any()).DoAndReturn(
+				func(_ interface{}, _ interface{}, m *kmmv1beta1.Module, _ ...ctrlclient.GetOption) error {
+					m.ObjectMeta = mod.ObjectMeta
+					return nil
+				},
+			),
+			mockNL.EXPECT().SetPrivileged(ctx, namespace).Return(errors.New("some error")),
+		)
+
+		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, nil, mockNL, mockDC, nil, validation.NewValidator(nil, nil, nil), mockKM, mockMetrics, nil, mockSU, nil, nil, nil, nil, false, false)
+
+		_, err := mr.Reconcile(ctx, req)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return an error if the build and sign NetworkPolicy cannot be created", func() {
+		mod := kmmv1beta1.Module{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      moduleName,
+				Namespace: namespace,
+			},
+			Spec: kmmv1beta1.ModuleSpec{
+				ModuleLoader: kmmv1beta1.ModuleLoaderSpec{
+					Container: kmmv1beta1.ModuleLoaderContainerSpec{
+						KernelMappings: []kmmv1beta1.KernelMapping{
+							{Build: &kmmv1beta1.Build{}},
+						},
+					},
+					ServiceAccountName: "some-sa",
+				},
+			},
+		}
+
+		mockNP := networkpolicy.NewMockNetworkPolicyCreator(ctrl)
+
+		gomock.InOrder(
+			clnt.EXPECT().Get(ctx, req.NamespacedName, gomock.Any()).DoAndReturn(
+				func(_ interface{}, _ interface{}, m *kmmv1beta1.Module, _ ...ctrlclient.GetOption) error {
+					m.ObjectMeta = mod.ObjectMeta
+					m.Spec = mod.Spec
+					return nil
+				},
+			),
+			mockRC.EXPECT().CreateBuildServiceAccount(ctx, mod, gomock.Any()).Return(nil),
+			mockNP.EXPECT().CreateBuildSignNetworkPolicy(ctx, mod).Return(errors.New("some error")),
+		)
+
+		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, mockNP, nil, mockDC, nil, validation.NewValidator(nil, nil, nil), mockKM, mockMetrics, nil, mockSU, nil, nil, nil, nil, false, false)
+
+		_, err := mr.Reconcile(ctx, req)
+		Expect(err).To(HaveOccurred())
+	})
+
 	It("should add the module loader and device plugin ServiceAccounts if they are not set", func() {
 		mod := kmmv1beta1.Module{
 			ObjectMeta: metav1.ObjectMeta{
@@ -114,13 +293,6 @@ var _ = Describe("ModuleReconciler_Reconcile", func() {
 					return nil
 				},
 			),
-			clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
-				func(_ interface{}, list *kmmv1beta1.ModuleList, _ ...interface{}) error {
-					list.Items = []kmmv1beta1.Module{mod}
-					return nil
-				},
-			),
-			mockMetrics.EXPECT().SetExistingKMMOModules(1),
 			mockRC.EXPECT().CreateModuleLoaderServiceAccount(ctx, gomock.Any()).Return(nil),
 			mockRC.EXPECT().CreateDevicePluginServiceAccount(ctx, gomock.Any()).Return(nil),
 			clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
@@ -136,13 +308,15 @@ var _ = Describe("ModuleReconciler_Reconcile", func() {
 			mockMetrics.EXPECT().SetCompletedStage(moduleName, namespace, "", metrics.DevicePluginStage, false),
 		)
 
-		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, mockDC, mockKM, mockMetrics, nil, mockSU)
+		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, nil, nil, mockDC, nil, validation.NewValidator(nil, nil, nil), mockKM, mockMetrics, nil, mockSU, nil, nil, nil, nil, false, false)
 
 		dsByKernelVersion := make(map[string]*appsv1.DaemonSet)
 
 		gomock.InOrder(
 			mockDC.EXPECT().ModuleDaemonSetsByKernelVersion(ctx, moduleName, namespace).Return(dsByKernelVersion, nil),
+			mockSU.EXPECT().ModuleSetKernelStatuses(ctx, &mod, []kmmv1beta1.KernelStatus{}),
 			mockDC.EXPECT().GarbageCollect(ctx, dsByKernelVersion, sets.NewString()),
+			mockDC.EXPECT().GarbageCollectCompanions(ctx, &mod),
 			mockBM.EXPECT().GarbageCollect(ctx, mod.Name, mod.Namespace, &mod),
 			mockSU.EXPECT().ModuleUpdateStatus(ctx, &mod, []v1.Node{}, []v1.Node{}, dsByKernelVersion).Return(nil),
 		)
@@ -177,12 +351,59 @@ var _ = Describe("ModuleReconciler_Reconcile", func() {
 				},
 			),
 			clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
-				func(_ interface{}, list *kmmv1beta1.ModuleList, _ ...interface{}) error {
-					list.Items = []kmmv1beta1.Module{mod}
+				func(_ interface{}, list *v1.NodeList, _ ...interface{}) error {
+					list.Items = []v1.Node{}
+					return nil
+				},
+			),
+		)
+
+		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, nil, nil, mockDC, nil, validation.NewValidator(nil, nil, nil), mockKM, mockMetrics, nil, mockSU, nil, nil, nil, nil, false, false)
+
+		dsByKernelVersion := make(map[string]*appsv1.DaemonSet)
+
+		gomock.InOrder(
+			mockDC.EXPECT().ModuleDaemonSetsByKernelVersion(ctx, moduleName, namespace).Return(dsByKernelVersion, nil),
+			mockSU.EXPECT().ModuleSetKernelStatuses(ctx, &mod, []kmmv1beta1.KernelStatus{}),
+			mockDC.EXPECT().GarbageCollect(ctx, dsByKernelVersion, sets.NewString()),
+			mockDC.EXPECT().GarbageCollectCompanions(ctx, &mod),
+			mockBM.EXPECT().GarbageCollect(ctx, mod.Name, mod.Namespace, &mod),
+			mockSU.EXPECT().ModuleUpdateStatus(ctx, &mod, []v1.Node{}, []v1.Node{}, dsByKernelVersion).Return(nil),
+		)
+
+		res, err := mr.Reconcile(context.Background(), req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res).To(Equal(reconcile.Result{}))
+	})
+
+	It("should leave the rebuild and retry-build requests alone in dry-run mode", func() {
+		const serviceAccountName = "module-loader-service-account"
+
+		mod := kmmv1beta1.Module{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      moduleName,
+				Namespace: namespace,
+				Annotations: map[string]string{
+					constants.RebuildKernelsAnnotation: "1.2.3",
+					constants.RetryBuildAnnotation:     "1.2.3",
+				},
+			},
+			Spec: kmmv1beta1.ModuleSpec{
+				Selector: map[string]string{"key": "value"},
+				ModuleLoader: kmmv1beta1.ModuleLoaderSpec{
+					ServiceAccountName: serviceAccountName,
+				},
+			},
+		}
+
+		gomock.InOrder(
+			clnt.EXPECT().Get(ctx, req.NamespacedName, gomock.Any()).DoAndReturn(
+				func(_ interface{}, _ interface{}, m *kmmv1beta1.Module, _ ...ctrlclient.GetOption) error {
+					m.ObjectMeta = mod.ObjectMeta
+					m.Spec = mod.Spec
 					return nil
 				},
 			),
-			mockMetrics.EXPECT().SetExistingKMMOModules(1),
 			clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
 				func(_ interface{}, list *v1.NodeList, _ ...interface{}) error {
 					list.Items = []v1.Node{}
@@ -191,13 +412,85 @@ var _ = Describe("ModuleReconciler_Reconcile", func() {
 			),
 		)
 
-		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, mockDC, mockKM, mockMetrics, nil, mockSU)
+		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, nil, nil, mockDC, nil, validation.NewValidator(nil, nil, nil), mockKM, mockMetrics, nil, mockSU, nil, nil, nil, nil, false, true)
+
+		dsByKernelVersion := make(map[string]*appsv1.DaemonSet)
+
+		gomock.InOrder(
+			mockDC.EXPECT().ModuleDaemonSetsByKernelVersion(ctx, moduleName, namespace).Return(dsByKernelVersion, nil),
+			mockSU.EXPECT().ModuleSetKernelStatuses(ctx, &mod, []kmmv1beta1.KernelStatus{}),
+			mockDC.EXPECT().GarbageCollect(ctx, dsByKernelVersion, sets.NewString()),
+			mockDC.EXPECT().GarbageCollectCompanions(ctx, &mod),
+			mockBM.EXPECT().GarbageCollect(ctx, mod.Name, mod.Namespace, &mod),
+			mockSU.EXPECT().ModuleUpdateStatus(ctx, &mod, []v1.Node{}, []v1.Node{}, dsByKernelVersion).Return(nil),
+		)
+
+		res, err := mr.Reconcile(context.Background(), req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res).To(Equal(reconcile.Result{}))
+	})
+
+	It("should not build or sign for kernels that only run on unschedulable nodes", func() {
+		const serviceAccountName = "module-loader-service-account"
+
+		mod := kmmv1beta1.Module{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      moduleName,
+				Namespace: namespace,
+			},
+			Spec: kmmv1beta1.ModuleSpec{
+				Selector: map[string]string{"key": "value"},
+				ModuleLoader: kmmv1beta1.ModuleLoaderSpec{
+					ServiceAccountName: serviceAccountName,
+					Container: kmmv1beta1.ModuleLoaderContainerSpec{
+						Build: &kmmv1beta1.Build{},
+						KernelMappings: []kmmv1beta1.KernelMapping{
+							{Regexp: ".*", ContainerImage: "some-image"},
+						},
+					},
+				},
+			},
+		}
+
+		unschedulableNode := v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "node1",
+				Labels: map[string]string{"key": "value"},
+			},
+			Spec: v1.NodeSpec{
+				Taints: []v1.Taint{{Key: "some-taint", Effect: v1.TaintEffectNoSchedule}},
+			},
+			Status: v1.NodeStatus{
+				NodeInfo: v1.NodeSystemInfo{KernelVersion: "1.2.3"},
+			},
+		}
+
+		gomock.InOrder(
+			clnt.EXPECT().Get(ctx, req.NamespacedName, gomock.Any()).DoAndReturn(
+				func(_ interface{}, _ interface{}, m *kmmv1beta1.Module, _ ...ctrlclient.GetOption) error {
+					m.ObjectMeta = mod.ObjectMeta
+					m.Spec = mod.Spec
+					return nil
+				},
+			),
+			mockRC.EXPECT().CreateBuildServiceAccount(ctx, mod, gomock.Any()).Return(nil),
+			clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ interface{}, list *v1.NodeList, _ ...interface{}) error {
+					list.Items = []v1.Node{unschedulableNode}
+					return nil
+				},
+			),
+		)
+
+		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, nil, nil, mockDC, nil, validation.NewValidator(nil, nil, nil), mockKM, mockMetrics, nil, mockSU, nil, nil, nil, nil, false, false)
 
 		dsByKernelVersion := make(map[string]*appsv1.DaemonSet)
 
 		gomock.InOrder(
 			mockDC.EXPECT().ModuleDaemonSetsByKernelVersion(ctx, moduleName, namespace).Return(dsByKernelVersion, nil),
+			mockSU.EXPECT().ModuleSetKernelStatuses(ctx, &mod, []kmmv1beta1.KernelStatus{}),
 			mockDC.EXPECT().GarbageCollect(ctx, dsByKernelVersion, sets.NewString()),
+			mockDC.EXPECT().GarbageCollectCompanions(ctx, &mod),
 			mockBM.EXPECT().GarbageCollect(ctx, mod.Name, mod.Namespace, &mod),
 			mockSU.EXPECT().ModuleUpdateStatus(ctx, &mod, []v1.Node{}, []v1.Node{}, dsByKernelVersion).Return(nil),
 		)
@@ -241,13 +534,6 @@ var _ = Describe("ModuleReconciler_Reconcile", func() {
 					return nil
 				},
 			),
-			clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
-				func(_ interface{}, list *kmmv1beta1.ModuleList, _ ...interface{}) error {
-					list.Items = []kmmv1beta1.Module{mod}
-					return nil
-				},
-			),
-			mockMetrics.EXPECT().SetExistingKMMOModules(1),
 			clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
 				func(_ interface{}, list *v1.NodeList, _ ...interface{}) error {
 					list.Items = []v1.Node{}
@@ -256,13 +542,15 @@ var _ = Describe("ModuleReconciler_Reconcile", func() {
 			),
 		)
 
-		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, mockDC, mockKM, mockMetrics, nil, mockSU)
+		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, nil, nil, mockDC, nil, validation.NewValidator(nil, nil, nil), mockKM, mockMetrics, nil, mockSU, nil, nil, nil, nil, false, false)
 
 		dsByKernelVersion := map[string]*appsv1.DaemonSet{kernelVersion: &ds}
 
 		gomock.InOrder(
 			mockDC.EXPECT().ModuleDaemonSetsByKernelVersion(ctx, moduleName, namespace).Return(dsByKernelVersion, nil),
+			mockSU.EXPECT().ModuleSetKernelStatuses(ctx, &mod, []kmmv1beta1.KernelStatus{}),
 			mockDC.EXPECT().GarbageCollect(ctx, dsByKernelVersion, sets.NewString()),
+			mockDC.EXPECT().GarbageCollectCompanions(ctx, &mod),
 			mockBM.EXPECT().GarbageCollect(ctx, mod.Name, mod.Namespace, &mod),
 			mockSU.EXPECT().ModuleUpdateStatus(ctx, &mod, []v1.Node{}, []v1.Node{}, dsByKernelVersion).Return(nil),
 		)
@@ -320,12 +608,12 @@ var _ = Describe("ModuleReconciler_Reconcile", func() {
 
 		dsByKernelVersion := make(map[string]*appsv1.DaemonSet)
 
-		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, mockDC, mockKM, mockMetrics, nil, mockSU)
+		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, nil, nil, mockDC, nil, validation.NewValidator(nil, nil, nil), mockKM, mockMetrics, nil, mockSU, nil, nil, nil, nil, false, false)
 
 		ds := appsv1.DaemonSet{
 			ObjectMeta: metav1.ObjectMeta{
-				GenerateName: moduleName + "-",
-				Namespace:    namespace,
+				Name:      daemonset.DriverContainerName(moduleName, kernelVersion),
+				Namespace: namespace,
 			},
 		}
 
@@ -337,12 +625,6 @@ var _ = Describe("ModuleReconciler_Reconcile", func() {
 					return nil
 				},
 			),
-			clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
-				func(_ interface{}, list *kmmv1beta1.ModuleList, _ ...interface{}) error {
-					return nil
-				},
-			),
-			mockMetrics.EXPECT().SetExistingKMMOModules(0),
 			clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
 				func(_ interface{}, list *v1.NodeList, _ ...interface{}) error {
 					list.Items = nodeList.Items
@@ -350,7 +632,7 @@ var _ = Describe("ModuleReconciler_Reconcile", func() {
 				},
 			),
 			mockKM.EXPECT().GetNodeOSConfig(&nodeList.Items[0]).Return(&osConfig),
-			mockKM.EXPECT().FindMappingForKernel(mappings, kernelVersion).Return(&mappings[0], nil),
+			mockKM.EXPECT().FindMappingForNode(mappings, &nodeList.Items[0]).Return(&mappings[0], nil),
 			mockKM.EXPECT().PrepareKernelMapping(&mappings[0], &osConfig).Return(&mappings[0], nil),
 			mockDC.EXPECT().ModuleDaemonSetsByKernelVersion(ctx, moduleName, namespace).Return(dsByKernelVersion, nil),
 			mockBM.EXPECT().ShouldSync(gomock.Any(), mod, mappings[0]).Return(true, nil),
@@ -358,10 +640,18 @@ var _ = Describe("ModuleReconciler_Reconcile", func() {
 			mockSM.EXPECT().ShouldSync(gomock.Any(), mod, mappings[0]).Return(true, nil),
 			mockSM.EXPECT().Sync(gomock.Any(), mod, mappings[0], kernelVersion, "", true, &mod),
 			clnt.EXPECT().Get(ctx, gomock.Any(), gomock.Any()).Return(apierrors.NewNotFound(schema.GroupResource{}, "whatever")),
-			mockDC.EXPECT().SetDriverContainerAsDesired(context.Background(), &ds, imageName, gomock.AssignableToTypeOf(mod), kernelVersion),
+			mockDC.EXPECT().SetDriverContainerAsDesired(context.Background(), &ds, mappings[0], gomock.AssignableToTypeOf(mod), kernelVersion, daemonset.OSProfileDefault),
 			clnt.EXPECT().Create(ctx, gomock.Any()).Return(nil),
 			mockMetrics.EXPECT().SetCompletedStage(moduleName, namespace, kernelVersion, metrics.ModuleLoaderStage, false),
+			mockSU.EXPECT().ModuleSetKernelStatuses(ctx, &mod, []kmmv1beta1.KernelStatus{
+				{
+					KernelVersion: kernelVersion,
+					Phase:         kmmv1beta1.KernelPhaseDeploying,
+					Conditions:    kernelConditions(0, kmmv1beta1.KernelPhaseDeploying, false, false, nil),
+				},
+			}),
 			mockDC.EXPECT().GarbageCollect(ctx, dsByKernelVersion, sets.NewString(kernelVersion)),
+			mockDC.EXPECT().GarbageCollectCompanions(ctx, &mod),
 			mockBM.EXPECT().GarbageCollect(ctx, mod.Name, mod.Namespace, &mod),
 			mockSU.EXPECT().ModuleUpdateStatus(ctx, &mod, nodeList.Items, nodeList.Items, dsByKernelVersion).Return(nil),
 		)
@@ -439,13 +729,6 @@ var _ = Describe("ModuleReconciler_Reconcile", func() {
 					return nil
 				},
 			),
-			clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
-				func(_ interface{}, list *kmmv1beta1.ModuleList, _ ...interface{}) error {
-					list.Items = []kmmv1beta1.Module{mod}
-					return nil
-				},
-			),
-			mockMetrics.EXPECT().SetExistingKMMOModules(1),
 			clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
 				func(_ interface{}, list *v1.NodeList, _ ...interface{}) error {
 					list.Items = nodeList.Items
@@ -456,24 +739,32 @@ var _ = Describe("ModuleReconciler_Reconcile", func() {
 			clnt.EXPECT().Patch(ctx, gomock.Any(), gomock.Any()),
 		)
 
-		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, mockDC, mockKM, mockMetrics, nil, mockSU)
+		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, nil, nil, mockDC, nil, validation.NewValidator(nil, nil, nil), mockKM, mockMetrics, nil, mockSU, nil, nil, nil, nil, false, false)
 
 		dsByKernelVersion := map[string]*appsv1.DaemonSet{kernelVersion: &ds}
 
 		gomock.InOrder(
 			mockKM.EXPECT().GetNodeOSConfig(&nodeList.Items[0]).Return(&osConfig),
-			mockKM.EXPECT().FindMappingForKernel(mappings, kernelVersion).Return(&mappings[0], nil),
+			mockKM.EXPECT().FindMappingForNode(mappings, &nodeList.Items[0]).Return(&mappings[0], nil),
 			mockKM.EXPECT().PrepareKernelMapping(&mappings[0], &osConfig).Return(&mappings[0], nil),
 			mockDC.EXPECT().ModuleDaemonSetsByKernelVersion(ctx, moduleName, namespace).Return(dsByKernelVersion, nil),
 			mockBM.EXPECT().ShouldSync(gomock.Any(), mod, mappings[0]).Return(true, nil),
 			mockBM.EXPECT().Sync(gomock.Any(), mod, mappings[0], kernelVersion, true, &mod),
 			mockSM.EXPECT().ShouldSync(gomock.Any(), mod, mappings[0]).Return(true, nil),
 			mockSM.EXPECT().Sync(gomock.Any(), mod, mappings[0], kernelVersion, "", true, &mod),
-			mockDC.EXPECT().SetDriverContainerAsDesired(context.Background(), &ds, imageName, gomock.AssignableToTypeOf(mod), kernelVersion).Do(
-				func(ctx context.Context, d *appsv1.DaemonSet, _ string, _ kmmv1beta1.Module, _ string) {
+			mockDC.EXPECT().SetDriverContainerAsDesired(context.Background(), &ds, mappings[0], gomock.AssignableToTypeOf(mod), kernelVersion, daemonset.OSProfileDefault).Do(
+				func(ctx context.Context, d *appsv1.DaemonSet, _ kmmv1beta1.KernelMapping, _ kmmv1beta1.Module, _ string, _ daemonset.OSProfile) {
 					d.SetLabels(map[string]string{"test": "test"})
 				}),
+			mockSU.EXPECT().ModuleSetKernelStatuses(ctx, &mod, []kmmv1beta1.KernelStatus{
+				{
+					KernelVersion: kernelVersion,
+					Phase:         kmmv1beta1.KernelPhaseDeploying,
+					Conditions:    kernelConditions(0, kmmv1beta1.KernelPhaseDeploying, false, false, nil),
+				},
+			}),
 			mockDC.EXPECT().GarbageCollect(ctx, dsByKernelVersion, sets.NewString(kernelVersion)),
+			mockDC.EXPECT().GarbageCollectCompanions(ctx, &mod),
 			mockBM.EXPECT().GarbageCollect(ctx, mod.Name, mod.Namespace, &mod),
 			mockSU.EXPECT().ModuleUpdateStatus(ctx, &mod, nodeList.Items, nodeList.Items, dsByKernelVersion).Return(nil),
 		)
@@ -483,50 +774,77 @@ var _ = Describe("ModuleReconciler_Reconcile", func() {
 		Expect(res).To(Equal(reconcile.Result{}))
 	})
 
-	It("should create a Device plugin if defined in the module", func() {
+	It("should handle all kernel mappings and requeue if one of them is still being built", func() {
 		const (
-			imageName     = "test-image"
-			kernelVersion = "1.2.3"
-
-			moduleLoaderServiceAccountName = "module-loader-service-account"
-			devicePluginServiceAccountName = "device-plugin-service-account"
+			imageName          = "test-image"
+			kernelVersion1     = "1.2.3"
+			kernelVersion2     = "4.5.6"
+			serviceAccountName = "module-loader-service-account"
 		)
 
+		osConfig := module.NodeOSConfig{}
+
 		mappings := []kmmv1beta1.KernelMapping{
 			{
 				ContainerImage: imageName,
-				Literal:        kernelVersion,
+				Literal:        kernelVersion1,
+			},
+			{
+				ContainerImage: imageName,
+				Literal:        kernelVersion2,
 			},
 		}
 
+		nodeLabels := map[string]string{"key": "value"}
+
 		mod := kmmv1beta1.Module{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      moduleName,
 				Namespace: namespace,
 			},
 			Spec: kmmv1beta1.ModuleSpec{
-				DevicePlugin: &kmmv1beta1.DevicePluginSpec{
-					ServiceAccountName: devicePluginServiceAccountName,
-				},
 				ModuleLoader: kmmv1beta1.ModuleLoaderSpec{
-					ServiceAccountName: moduleLoaderServiceAccountName,
+					ServiceAccountName: serviceAccountName,
 					Container: kmmv1beta1.ModuleLoaderContainerSpec{
 						KernelMappings: mappings,
 					},
 				},
-				Selector: map[string]string{"key": "value"},
+				Selector: nodeLabels,
 			},
 		}
 
-		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, mockDC, mockKM, mockMetrics, nil, mockSU)
+		nodeList := v1.NodeList{
+			Items: []v1.Node{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "node1",
+						Labels: nodeLabels,
+					},
+					Status: v1.NodeStatus{
+						NodeInfo: v1.NodeSystemInfo{KernelVersion: kernelVersion1},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "node2",
+						Labels: nodeLabels,
+					},
+					Status: v1.NodeStatus{
+						NodeInfo: v1.NodeSystemInfo{KernelVersion: kernelVersion2},
+					},
+				},
+			},
+		}
 
 		ds := appsv1.DaemonSet{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      moduleName + "-device-plugin",
+				Name:      "some-daemonset",
 				Namespace: namespace,
 			},
 		}
 
+		dsByKernelVersion := map[string]*appsv1.DaemonSet{kernelVersion2: &ds}
+
 		gomock.InOrder(
 			clnt.EXPECT().Get(ctx, req.NamespacedName, gomock.Any()).DoAndReturn(
 				func(_ interface{}, _ interface{}, m *kmmv1beta1.Module, _ ...ctrlclient.GetOption) error {
@@ -536,342 +854,1343 @@ var _ = Describe("ModuleReconciler_Reconcile", func() {
 				},
 			),
 			clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
-				func(_ interface{}, list *kmmv1beta1.ModuleList, _ ...interface{}) error {
+				func(_ interface{}, list *v1.NodeList, _ ...interface{}) error {
+					list.Items = nodeList.Items
 					return nil
 				},
 			),
-			mockMetrics.EXPECT().SetExistingKMMOModules(0),
-			clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
-				func(_ interface{}, list *v1.NodeList, _ ...interface{}) error {
-					list.Items = []v1.Node{}
+		)
+
+		gomock.InOrder(
+			mockKM.EXPECT().GetNodeOSConfig(&nodeList.Items[0]).Return(&osConfig),
+			mockKM.EXPECT().FindMappingForNode(mappings, &nodeList.Items[0]).Return(&mappings[0], nil),
+			mockKM.EXPECT().GetNodeOSConfig(&nodeList.Items[1]).Return(&osConfig),
+			mockKM.EXPECT().FindMappingForNode(mappings, &nodeList.Items[1]).Return(&mappings[1], nil),
+			mockKM.EXPECT().PrepareKernelMapping(&mappings[0], &osConfig).Return(&mappings[0], nil),
+			mockKM.EXPECT().PrepareKernelMapping(&mappings[1], &osConfig).Return(&mappings[1], nil),
+			mockDC.EXPECT().ModuleDaemonSetsByKernelVersion(ctx, moduleName, namespace).Return(dsByKernelVersion, nil),
+		)
+
+		gomock.InOrder(
+			mockBM.EXPECT().ShouldSync(gomock.Any(), mod, mappings[0]).Return(true, nil),
+			mockBM.EXPECT().
+				Sync(gomock.Any(), mod, mappings[0], kernelVersion1, true, &mod).
+				Return(build.Result{Requeue: true, Status: build.StatusInProgress}, nil),
+		)
+
+		gomock.InOrder(
+			mockBM.EXPECT().ShouldSync(gomock.Any(), mod, mappings[1]).Return(false, nil),
+			mockSM.EXPECT().ShouldSync(gomock.Any(), mod, mappings[1]).Return(false, nil),
+			clnt.EXPECT().Get(ctx, gomock.Any(), gomock.Any()),
+			mockDC.EXPECT().SetDriverContainerAsDesired(context.Background(), &ds, mappings[1], gomock.AssignableToTypeOf(mod), kernelVersion2, daemonset.OSProfileDefault),
+		)
+
+		gomock.InOrder(
+			mockSU.EXPECT().ModuleSetKernelStatuses(ctx, &mod, gomock.Any()).DoAndReturn(
+				func(_ context.Context, _ *kmmv1beta1.Module, kernels []kmmv1beta1.KernelStatus) error {
+					Expect(kernels).To(ConsistOf(
+						kmmv1beta1.KernelStatus{
+							KernelVersion: kernelVersion1,
+							Phase:         kmmv1beta1.KernelPhaseBuilding,
+							Conditions:    kernelConditions(0, kmmv1beta1.KernelPhaseBuilding, false, false, nil),
+						},
+						kmmv1beta1.KernelStatus{
+							KernelVersion: kernelVersion2,
+							Phase:         kmmv1beta1.KernelPhaseDeploying,
+							Conditions:    kernelConditions(0, kmmv1beta1.KernelPhaseDeploying, false, false, nil),
+						},
+					))
 					return nil
 				},
 			),
-			mockDC.EXPECT().ModuleDaemonSetsByKernelVersion(ctx, moduleName, namespace).Return(nil, nil),
-			clnt.EXPECT().Get(ctx, gomock.Any(), gomock.Any()).Return(apierrors.NewNotFound(schema.GroupResource{}, "whatever")),
-			clnt.EXPECT().Get(ctx, gomock.Any(), gomock.Any()).Return(apierrors.NewNotFound(schema.GroupResource{}, "whatever")),
-			mockDC.EXPECT().SetDevicePluginAsDesired(context.Background(), &ds, gomock.AssignableToTypeOf(&mod)),
-			clnt.EXPECT().Create(ctx, gomock.Any()).Return(nil),
-			mockMetrics.EXPECT().SetCompletedStage(moduleName, namespace, "", metrics.DevicePluginStage, false),
-			mockDC.EXPECT().GarbageCollect(ctx, nil, sets.NewString()),
+			mockDC.EXPECT().GarbageCollect(ctx, dsByKernelVersion, sets.NewString(kernelVersion1, kernelVersion2)),
+			mockDC.EXPECT().GarbageCollectCompanions(ctx, &mod),
 			mockBM.EXPECT().GarbageCollect(ctx, mod.Name, mod.Namespace, &mod),
-			mockSU.EXPECT().ModuleUpdateStatus(ctx, &mod, []v1.Node{}, []v1.Node{}, nil).Return(nil),
+			mockSU.EXPECT().ModuleUpdateStatus(ctx, &mod, nodeList.Items, nodeList.Items, dsByKernelVersion).Return(nil),
 		)
 
+		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, nil, nil, mockDC, nil, validation.NewValidator(nil, nil, nil), mockKM, mockMetrics, nil, mockSU, nil, nil, nil, nil, false, false)
+
 		res, err := mr.Reconcile(context.Background(), req)
 		Expect(err).NotTo(HaveOccurred())
-		Expect(res).To(Equal(reconcile.Result{}))
+		Expect(res).To(Equal(reconcile.Result{RequeueAfter: jobsInProgressRequeueAfter}))
 	})
-})
 
-var _ = Describe("ModuleReconciler_handleBuild", func() {
-	var (
-		ctrl        *gomock.Controller
-		clnt        *client.MockClient
-		mockBM      *build.MockManager
-		mockSM      *sign.MockSignManager
-		mockRC      *rbac.MockRBACCreator
-		mockDC      *daemonset.MockDaemonSetCreator
-		mockKM      *module.MockKernelMapper
-		mockMetrics *metrics.MockMetrics
-		mockSU      *statusupdater.MockModuleStatusUpdater
-	)
+	It("should record the Failed phase and the logs of a kernel whose build failed", func() {
+		const (
+			imageName          = "test-image"
+			kernelVersion      = "1.2.3"
+			serviceAccountName = "module-loader-service-account"
+		)
 
-	BeforeEach(func() {
-		ctrl = gomock.NewController(GinkgoT())
-		clnt = client.NewMockClient(ctrl)
-		mockBM = build.NewMockManager(ctrl)
-		mockSM = sign.NewMockSignManager(ctrl)
-		mockRC = rbac.NewMockRBACCreator(ctrl)
-		mockDC = daemonset.NewMockDaemonSetCreator(ctrl)
-		mockKM = module.NewMockKernelMapper(ctrl)
-		mockMetrics = metrics.NewMockMetrics(ctrl)
-		mockSU = statusupdater.NewMockModuleStatusUpdater(ctrl)
-	})
+		osConfig := module.NodeOSConfig{}
 
-	const (
-		moduleName    = "test-module"
-		kernelVersion = "1.2.3"
-		imageName     = "test-image"
-	)
+		mappings := []kmmv1beta1.KernelMapping{
+			{
+				ContainerImage: imageName,
+				Literal:        kernelVersion,
+			},
+		}
 
-	It("should do nothing when build is skipped", func() {
-		km := &kmmv1beta1.KernelMapping{
-			ContainerImage: imageName,
-			Literal:        kernelVersion,
+		mod := kmmv1beta1.Module{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      moduleName,
+				Namespace: namespace,
+			},
+			Spec: kmmv1beta1.ModuleSpec{
+				ModuleLoader: kmmv1beta1.ModuleLoaderSpec{
+					ServiceAccountName: serviceAccountName,
+					Container: kmmv1beta1.ModuleLoaderContainerSpec{
+						KernelMappings: mappings,
+					},
+				},
+				Selector: map[string]string{"key": "value"},
+			},
 		}
 
-		mod := &kmmv1beta1.Module{}
+		nodeList := v1.NodeList{
+			Items: []v1.Node{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "node1",
+						Labels: map[string]string{"key": "value"},
+					},
+					Status: v1.NodeStatus{
+						NodeInfo: v1.NodeSystemInfo{KernelVersion: kernelVersion},
+					},
+				},
+			},
+		}
+
+		mockJobLogs := utils.NewMockJobLogTailer(ctrl)
+		recorder := record.NewFakeRecorder(1)
+		failureTime := metav1.Unix(1000, 0)
+
+		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, nil, nil, mockDC, nil, validation.NewValidator(nil, nil, nil), mockKM, mockMetrics, nil, mockSU, mockJobLogs, recorder, nil, nil, false, false)
 
 		gomock.InOrder(
-			mockBM.EXPECT().ShouldSync(gomock.Any(), *mod, *km).Return(false, nil),
+			clnt.EXPECT().Get(ctx, req.NamespacedName, gomock.Any()).DoAndReturn(
+				func(_ interface{}, _ interface{}, m *kmmv1beta1.Module, _ ...ctrlclient.GetOption) error {
+					m.ObjectMeta = mod.ObjectMeta
+					m.Spec = mod.Spec
+					return nil
+				},
+			),
+			clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ interface{}, list *v1.NodeList, _ ...interface{}) error {
+					list.Items = nodeList.Items
+					return nil
+				},
+			),
+			mockKM.EXPECT().GetNodeOSConfig(&nodeList.Items[0]).Return(&osConfig),
+			mockKM.EXPECT().FindMappingForNode(mappings, &nodeList.Items[0]).Return(&mappings[0], nil),
+			mockKM.EXPECT().PrepareKernelMapping(&mappings[0], &osConfig).Return(&mappings[0], nil),
+			mockDC.EXPECT().ModuleDaemonSetsByKernelVersion(ctx, moduleName, namespace).Return(nil, nil),
+			mockBM.EXPECT().ShouldSync(gomock.Any(), mod, mappings[0]).Return(true, nil),
+			mockBM.EXPECT().
+				Sync(gomock.Any(), mod, mappings[0], kernelVersion, true, &mod).
+				Return(build.Result{}, &utils.JobFailedError{JobName: "some-job", Reason: "BackoffLimitExceeded", FailureTime: failureTime}),
+			mockJobLogs.EXPECT().LogTail(ctx, namespace, "some-job").Return("error: some compiler error", nil),
+			mockSU.EXPECT().ModuleSetKernelStatuses(ctx, &mod, gomock.Any()).DoAndReturn(
+				func(_ context.Context, _ *kmmv1beta1.Module, kernels []kmmv1beta1.KernelStatus) error {
+					Expect(kernels).To(HaveLen(1))
+					Expect(kernels[0].KernelVersion).To(Equal(kernelVersion))
+					Expect(kernels[0].Phase).To(Equal(kmmv1beta1.KernelPhaseFailed))
+					Expect(kernels[0].Message).To(ContainSubstring("job failed: some-job"))
+					Expect(kernels[0].Message).To(ContainSubstring("error: some compiler error"))
+					return nil
+				},
+			),
+			mockSU.EXPECT().ModuleRecordJobFailures(ctx, &mod, []kmmv1beta1.JobFailure{
+				{
+					KernelVersion: kernelVersion,
+					JobType:       utils.JobTypeBuild,
+					JobName:       "some-job",
+					Reason:        "BackoffLimitExceeded",
+					FailureTime:   failureTime,
+					Logs:          "error: some compiler error",
+				},
+			}),
 		)
 
-		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, mockDC, mockKM, mockMetrics, nil, mockSU)
+		_, err := mr.Reconcile(context.Background(), req)
+		Expect(err).To(HaveOccurred())
+		Expect(recorder.Events).To(Receive(Equal(
+			"Warning BuildFailed build Job some-job failed for kernel 1.2.3:\nerror: some compiler error",
+		)))
+	})
 
-		res, err := mr.handleBuild(context.Background(), mod, km, kernelVersion)
+	It("should wait for a missing Secret instead of failing", func() {
+		const (
+			imageName          = "test-image"
+			kernelVersion      = "1.2.3"
+			serviceAccountName = "module-loader-service-account"
+		)
+
+		osConfig := module.NodeOSConfig{}
+
+		mappings := []kmmv1beta1.KernelMapping{
+			{
+				ContainerImage: imageName,
+				Literal:        kernelVersion,
+			},
+		}
+
+		mod := kmmv1beta1.Module{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      moduleName,
+				Namespace: namespace,
+			},
+			Spec: kmmv1beta1.ModuleSpec{
+				ImageRepoSecret: &v1.LocalObjectReference{Name: "pull-secret"},
+				ModuleLoader: kmmv1beta1.ModuleLoaderSpec{
+					ServiceAccountName: serviceAccountName,
+					Container: kmmv1beta1.ModuleLoaderContainerSpec{
+						KernelMappings: mappings,
+					},
+				},
+				Selector: map[string]string{"key": "value"},
+			},
+		}
+
+		nodeList := v1.NodeList{
+			Items: []v1.Node{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "node1",
+						Labels: map[string]string{"key": "value"},
+					},
+					Status: v1.NodeStatus{
+						NodeInfo: v1.NodeSystemInfo{KernelVersion: kernelVersion},
+					},
+				},
+			},
+		}
+
+		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, nil, nil, mockDC, nil, validation.NewValidator(nil, nil, nil), mockKM, mockMetrics, nil, mockSU, nil, nil, nil, nil, false, false)
+
+		gomock.InOrder(
+			clnt.EXPECT().Get(ctx, req.NamespacedName, gomock.Any()).DoAndReturn(
+				func(_ interface{}, _ interface{}, m *kmmv1beta1.Module, _ ...ctrlclient.GetOption) error {
+					m.ObjectMeta = mod.ObjectMeta
+					m.Spec = mod.Spec
+					return nil
+				},
+			),
+			clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ interface{}, list *v1.NodeList, _ ...interface{}) error {
+					list.Items = nodeList.Items
+					return nil
+				},
+			),
+			mockKM.EXPECT().GetNodeOSConfig(&nodeList.Items[0]).Return(&osConfig),
+			mockKM.EXPECT().FindMappingForNode(mappings, &nodeList.Items[0]).Return(&mappings[0], nil),
+			mockKM.EXPECT().PrepareKernelMapping(&mappings[0], &osConfig).Return(&mappings[0], nil),
+			mockDC.EXPECT().ModuleDaemonSetsByKernelVersion(ctx, moduleName, namespace).Return(nil, nil),
+			mockBM.EXPECT().ShouldSync(gomock.Any(), mod, mappings[0]).Return(false, errors.New("could not get the pull secret")),
+			clnt.EXPECT().
+				Get(ctx, types.NamespacedName{Namespace: namespace, Name: "pull-secret"}, &v1.Secret{}).
+				Return(apierrors.NewNotFound(schema.GroupResource{}, "pull-secret")),
+			mockSU.EXPECT().ModuleSetKernelStatuses(ctx, &mod, gomock.Any()).DoAndReturn(
+				func(_ context.Context, _ *kmmv1beta1.Module, kernels []kmmv1beta1.KernelStatus) error {
+					Expect(kernels).To(HaveLen(1))
+					Expect(kernels[0].Phase).To(Equal(kmmv1beta1.KernelPhasePending))
+					Expect(kernels[0].Message).To(ContainSubstring("waiting for Secret " + namespace + "/pull-secret"))
+					return nil
+				},
+			),
+			mockDC.EXPECT().GarbageCollect(ctx, nil, sets.NewString(kernelVersion)),
+			mockDC.EXPECT().GarbageCollectCompanions(ctx, &mod),
+			mockBM.EXPECT().GarbageCollect(ctx, mod.Name, mod.Namespace, &mod),
+			mockSU.EXPECT().ModuleUpdateStatus(ctx, &mod, nodeList.Items, nodeList.Items, nil).Return(nil),
+		)
+
+		res, err := mr.Reconcile(context.Background(), req)
 		Expect(err).NotTo(HaveOccurred())
-		Expect(res).To(BeFalse())
+		Expect(res).To(Equal(reconcile.Result{}))
 	})
 
-	It("should record that a job was created when the build sync returns StatusCreated", func() {
-		km := &kmmv1beta1.KernelMapping{
-			ContainerImage: imageName,
-			Literal:        kernelVersion,
-			Build:          &kmmv1beta1.Build{},
+	It("should create a Device plugin if defined in the module", func() {
+		const (
+			imageName     = "test-image"
+			kernelVersion = "1.2.3"
+
+			moduleLoaderServiceAccountName = "module-loader-service-account"
+			devicePluginServiceAccountName = "device-plugin-service-account"
+		)
+
+		mappings := []kmmv1beta1.KernelMapping{
+			{
+				ContainerImage: imageName,
+				Literal:        kernelVersion,
+			},
 		}
-		mod := &kmmv1beta1.Module{
+
+		mod := kmmv1beta1.Module{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      moduleName,
+				Namespace: namespace,
+			},
+			Spec: kmmv1beta1.ModuleSpec{
+				DevicePlugin: &kmmv1beta1.DevicePluginSpec{
+					ServiceAccountName: devicePluginServiceAccountName,
+				},
+				ModuleLoader: kmmv1beta1.ModuleLoaderSpec{
+					ServiceAccountName: moduleLoaderServiceAccountName,
+					Container: kmmv1beta1.ModuleLoaderContainerSpec{
+						KernelMappings: mappings,
+					},
+				},
+				Selector: map[string]string{"key": "value"},
+			},
+		}
+
+		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, nil, nil, mockDC, nil, validation.NewValidator(nil, nil, nil), mockKM, mockMetrics, nil, mockSU, nil, nil, nil, nil, false, false)
+
+		ds := appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      moduleName + "-device-plugin",
+				Namespace: namespace,
+			},
+		}
+
+		gomock.InOrder(
+			clnt.EXPECT().Get(ctx, req.NamespacedName, gomock.Any()).DoAndReturn(
+				func(_ interface{}, _ interface{}, m *kmmv1beta1.Module, _ ...ctrlclient.GetOption) error {
+					m.ObjectMeta = mod.ObjectMeta
+					m.Spec = mod.Spec
+					return nil
+				},
+			),
+			clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ interface{}, list *v1.NodeList, _ ...interface{}) error {
+					list.Items = []v1.Node{}
+					return nil
+				},
+			),
+			mockDC.EXPECT().ModuleDaemonSetsByKernelVersion(ctx, moduleName, namespace).Return(nil, nil),
+			mockSU.EXPECT().ModuleSetKernelStatuses(ctx, &mod, []kmmv1beta1.KernelStatus{}),
+			clnt.EXPECT().Get(ctx, gomock.Any(), gomock.Any()).Return(apierrors.NewNotFound(schema.GroupResource{}, "whatever")),
+			clnt.EXPECT().Get(ctx, gomock.Any(), gomock.Any()).Return(apierrors.NewNotFound(schema.GroupResource{}, "whatever")),
+			mockDC.EXPECT().SetDevicePluginAsDesired(context.Background(), &ds, gomock.AssignableToTypeOf(&mod)),
+			clnt.EXPECT().Create(ctx, gomock.Any()).Return(nil),
+			mockMetrics.EXPECT().SetCompletedStage(moduleName, namespace, "", metrics.DevicePluginStage, false),
+			mockDC.EXPECT().GarbageCollect(ctx, nil, sets.NewString()),
+			mockDC.EXPECT().GarbageCollectCompanions(ctx, &mod),
+			mockBM.EXPECT().GarbageCollect(ctx, mod.Name, mod.Namespace, &mod),
+			mockSU.EXPECT().ModuleUpdateStatus(ctx, &mod, []v1.Node{}, []v1.Node{}, nil).Return(nil),
+		)
+
+		res, err := mr.Reconcile(context.Background(), req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res).To(Equal(reconcile.Result{}))
+	})
+
+	It("should create a Deployment for each companion defined in the module", func() {
+		const moduleLoaderServiceAccountName = "module-loader-service-account"
+
+		mod := kmmv1beta1.Module{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      moduleName,
+				Namespace: namespace,
+			},
+			Spec: kmmv1beta1.ModuleSpec{
+				Companions: []kmmv1beta1.CompanionSpec{
+					{
+						Name:      "licensing",
+						Container: kmmv1beta1.CompanionContainerSpec{Image: "licensing-image"},
+					},
+				},
+				ModuleLoader: kmmv1beta1.ModuleLoaderSpec{
+					ServiceAccountName: moduleLoaderServiceAccountName,
+					Container: kmmv1beta1.ModuleLoaderContainerSpec{
+						KernelMappings: []kmmv1beta1.KernelMapping{{ContainerImage: "test-image", Literal: "1.2.3"}},
+					},
+				},
+				Selector: map[string]string{"key": "value"},
+			},
+		}
+
+		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, nil, nil, mockDC, nil, validation.NewValidator(nil, nil, nil), mockKM, mockMetrics, nil, mockSU, nil, nil, nil, nil, false, false)
+
+		deploy := appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      moduleName + "-companion-licensing",
+				Namespace: namespace,
+			},
+		}
+
+		gomock.InOrder(
+			clnt.EXPECT().Get(ctx, req.NamespacedName, gomock.Any()).DoAndReturn(
+				func(_ interface{}, _ interface{}, m *kmmv1beta1.Module, _ ...ctrlclient.GetOption) error {
+					m.ObjectMeta = mod.ObjectMeta
+					m.Spec = mod.Spec
+					return nil
+				},
+			),
+			clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ interface{}, list *v1.NodeList, _ ...interface{}) error {
+					list.Items = []v1.Node{}
+					return nil
+				},
+			),
+			mockDC.EXPECT().ModuleDaemonSetsByKernelVersion(ctx, moduleName, namespace).Return(nil, nil),
+			mockSU.EXPECT().ModuleSetKernelStatuses(ctx, &mod, []kmmv1beta1.KernelStatus{}),
+			clnt.EXPECT().Get(ctx, gomock.Any(), gomock.Any()).Return(apierrors.NewNotFound(schema.GroupResource{}, "whatever")),
+			mockDC.EXPECT().SetCompanionAsDesired(context.Background(), &deploy, gomock.AssignableToTypeOf(&mod), &mod.Spec.Companions[0]),
+			clnt.EXPECT().Create(ctx, gomock.Any()).Return(nil),
+			mockDC.EXPECT().GarbageCollect(ctx, nil, sets.NewString()),
+			mockDC.EXPECT().GarbageCollectCompanions(ctx, &mod),
+			mockBM.EXPECT().GarbageCollect(ctx, mod.Name, mod.Namespace, &mod),
+			mockSU.EXPECT().ModuleUpdateStatus(ctx, &mod, []v1.Node{}, []v1.Node{}, nil).Return(nil),
+		)
+
+		res, err := mr.Reconcile(context.Background(), req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res).To(Equal(reconcile.Result{}))
+	})
+})
+
+var _ = Describe("ModuleReconciler_handleBuild", func() {
+	var (
+		ctrl        *gomock.Controller
+		clnt        *client.MockClient
+		mockBM      *build.MockManager
+		mockSM      *sign.MockSignManager
+		mockRC      *rbac.MockRBACCreator
+		mockDC      *daemonset.MockDaemonSetCreator
+		mockKM      *module.MockKernelMapper
+		mockMetrics *metrics.MockMetrics
+		mockSU      *statusupdater.MockModuleStatusUpdater
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+		mockBM = build.NewMockManager(ctrl)
+		mockSM = sign.NewMockSignManager(ctrl)
+		mockRC = rbac.NewMockRBACCreator(ctrl)
+		mockDC = daemonset.NewMockDaemonSetCreator(ctrl)
+		mockKM = module.NewMockKernelMapper(ctrl)
+		mockMetrics = metrics.NewMockMetrics(ctrl)
+		mockSU = statusupdater.NewMockModuleStatusUpdater(ctrl)
+	})
+
+	const (
+		moduleName    = "test-module"
+		kernelVersion = "1.2.3"
+		imageName     = "test-image"
+	)
+
+	It("should do nothing when build is skipped", func() {
+		km := &kmmv1beta1.KernelMapping{
+			ContainerImage: imageName,
+			Literal:        kernelVersion,
+		}
+
+		mod := &kmmv1beta1.Module{}
+
+		gomock.InOrder(
+			mockBM.EXPECT().ShouldSync(gomock.Any(), *mod, *km).Return(false, nil),
+		)
+
+		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, nil, nil, mockDC, nil, validation.NewValidator(nil, nil, nil), mockKM, mockMetrics, nil, mockSU, nil, nil, nil, nil, false, false)
+
+		res, err := mr.handleBuild(context.Background(), mod, km, kernelVersion)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res).To(BeFalse())
+	})
+
+	It("should record that a job was created when the build sync returns StatusCreated", func() {
+		km := &kmmv1beta1.KernelMapping{
+			ContainerImage: imageName,
+			Literal:        kernelVersion,
+			Build:          &kmmv1beta1.Build{},
+		}
+		mod := &kmmv1beta1.Module{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      moduleName,
+				Namespace: namespace,
+			},
+			Spec: kmmv1beta1.ModuleSpec{},
+		}
+		buildRes := build.Result{Requeue: true, Status: build.StatusCreated}
+		gomock.InOrder(
+			mockBM.EXPECT().ShouldSync(gomock.Any(), *mod, *km).Return(true, nil),
+			mockBM.EXPECT().Sync(gomock.Any(), *mod, *km, gomock.Any(), true, mod).Return(buildRes, nil),
+			mockMetrics.EXPECT().SetCompletedStage(mod.Name, mod.Namespace, kernelVersion, metrics.BuildStage, false),
+		)
+
+		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, nil, nil, mockDC, nil, validation.NewValidator(nil, nil, nil), mockKM, mockMetrics, nil, mockSU, nil, nil, nil, nil, false, false)
+		res, err := mr.handleBuild(context.Background(), mod, km, kernelVersion)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res).To(BeTrue())
+	})
+
+	It("should record that a job was completed, when the build sync returns StatusCompleted", func() {
+		km := &kmmv1beta1.KernelMapping{
+			ContainerImage: imageName,
+			Literal:        kernelVersion,
+			Build:          &kmmv1beta1.Build{},
+		}
+		mod := &kmmv1beta1.Module{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      moduleName,
+				Namespace: namespace,
+			},
+			Spec: kmmv1beta1.ModuleSpec{},
+		}
+		buildRes := build.Result{Requeue: false, Status: build.StatusCompleted}
+		gomock.InOrder(
+			mockBM.EXPECT().ShouldSync(gomock.Any(), *mod, *km).Return(true, nil),
+			mockBM.EXPECT().Sync(gomock.Any(), *mod, *km, gomock.Any(), true, mod).Return(buildRes, nil),
+			mockMetrics.EXPECT().SetCompletedStage(mod.Name, mod.Namespace, kernelVersion, metrics.BuildStage, true),
+		)
+
+		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, nil, nil, mockDC, nil, validation.NewValidator(nil, nil, nil), mockKM, mockMetrics, nil, mockSU, nil, nil, nil, nil, false, false)
+		res, err := mr.handleBuild(context.Background(), mod, km, kernelVersion)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res).To(BeFalse())
+	})
+})
+
+/***************** signing ***********************/
+var _ = Describe("ModuleReconciler_handleSigning", func() {
+	var (
+		ctrl        *gomock.Controller
+		clnt        *client.MockClient
+		mockBM      *build.MockManager
+		mockSM      *sign.MockSignManager
+		mockRC      *rbac.MockRBACCreator
+		mockDC      *daemonset.MockDaemonSetCreator
+		mockKM      *module.MockKernelMapper
+		mockMetrics *metrics.MockMetrics
+		mockSU      *statusupdater.MockModuleStatusUpdater
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+		mockBM = build.NewMockManager(ctrl)
+		mockSM = sign.NewMockSignManager(ctrl)
+		mockRC = rbac.NewMockRBACCreator(ctrl)
+		mockDC = daemonset.NewMockDaemonSetCreator(ctrl)
+		mockKM = module.NewMockKernelMapper(ctrl)
+		mockMetrics = metrics.NewMockMetrics(ctrl)
+		mockSU = statusupdater.NewMockModuleStatusUpdater(ctrl)
+	})
+
+	const (
+		moduleName    = "test-module"
+		kernelVersion = "1.2.3"
+		imageName     = "test-image"
+	)
+
+	It("should do nothing when build is skipped", func() {
+		km := &kmmv1beta1.KernelMapping{
+			ContainerImage: imageName,
+			Literal:        kernelVersion,
+		}
+
+		mod := &kmmv1beta1.Module{}
+
+		gomock.InOrder(
+			mockSM.EXPECT().ShouldSync(gomock.Any(), *mod, *km).Return(false, nil),
+		)
+
+		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, nil, nil, mockDC, nil, validation.NewValidator(nil, nil, nil), mockKM, mockMetrics, nil, mockSU, nil, nil, nil, nil, false, false)
+
+		res, err := mr.handleSigning(context.Background(), mod, km, kernelVersion)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res).To(BeFalse())
+	})
+
+	It("should record that a job was created when the sign sync returns StatusCreated", func() {
+		km := &kmmv1beta1.KernelMapping{
+			ContainerImage: imageName,
+			Literal:        kernelVersion,
+			Sign:           &kmmv1beta1.Sign{},
+		}
+		mod := &kmmv1beta1.Module{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      moduleName,
+				Namespace: namespace,
+			},
+			Spec: kmmv1beta1.ModuleSpec{},
+		}
+
+		signRes := utils.Result{Requeue: true, Status: utils.StatusCreated}
+		gomock.InOrder(
+			mockSM.EXPECT().ShouldSync(gomock.Any(), *mod, *km).Return(true, nil),
+			mockSM.EXPECT().Sync(gomock.Any(), *mod, *km, gomock.Any(), "", true, mod).Return(signRes, nil),
+			mockMetrics.EXPECT().SetCompletedStage(mod.Name, mod.Namespace, kernelVersion, metrics.SignStage, false),
+		)
+
+		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, nil, nil, mockDC, nil, validation.NewValidator(nil, nil, nil), mockKM, mockMetrics, nil, mockSU, nil, nil, nil, nil, false, false)
+
+		res, err := mr.handleSigning(context.Background(), mod, km, kernelVersion)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res).To(BeTrue())
+	})
+
+	It("should record that a job was completed when the sign sync returns StatusCompleted", func() {
+		km := &kmmv1beta1.KernelMapping{
+			ContainerImage: imageName,
+			Literal:        kernelVersion,
+			Sign:           &kmmv1beta1.Sign{},
+		}
+		mod := &kmmv1beta1.Module{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      moduleName,
+				Namespace: namespace,
+			},
+			Spec: kmmv1beta1.ModuleSpec{},
+		}
+
+		signRes := utils.Result{Requeue: false, Status: build.StatusCompleted}
+		gomock.InOrder(
+			mockSM.EXPECT().ShouldSync(gomock.Any(), *mod, *km).Return(true, nil),
+			mockSM.EXPECT().Sync(gomock.Any(), *mod, *km, gomock.Any(), "", true, mod).Return(signRes, nil),
+			mockMetrics.EXPECT().SetCompletedStage(mod.Name, mod.Namespace, kernelVersion, metrics.SignStage, true),
+		)
+
+		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, nil, nil, mockDC, nil, validation.NewValidator(nil, nil, nil), mockKM, mockMetrics, nil, mockSU, nil, nil, nil, nil, false, false)
+
+		res, err := mr.handleSigning(context.Background(), mod, km, kernelVersion)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res).To(BeFalse())
+	})
+
+	It("should run sign sync with the previous image as well when module build and sign are specified", func() {
+		km := &kmmv1beta1.KernelMapping{
+			ContainerImage: imageName,
+			Literal:        kernelVersion,
+			Sign:           &kmmv1beta1.Sign{},
+			Build:          &kmmv1beta1.Build{},
+		}
+		mod := &kmmv1beta1.Module{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      moduleName,
+				Namespace: namespace,
+			},
+			Spec: kmmv1beta1.ModuleSpec{},
+		}
+
+		signRes := utils.Result{Requeue: false, Status: build.StatusCompleted}
+		gomock.InOrder(
+			mockSM.EXPECT().ShouldSync(gomock.Any(), *mod, *km).Return(true, nil),
+			mockSM.EXPECT().Sync(gomock.Any(), *mod, *km, gomock.Any(), imageName+":"+namespace+"_"+moduleName+"_kmm_unsigned", true, mod).Return(signRes, nil),
+			mockMetrics.EXPECT().SetCompletedStage(mod.Name, mod.Namespace, kernelVersion, metrics.SignStage, true),
+		)
+
+		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, nil, nil, mockDC, nil, validation.NewValidator(nil, nil, nil), mockKM, mockMetrics, nil, mockSU, nil, nil, nil, nil, false, false)
+
+		res, err := mr.handleSigning(context.Background(), mod, km, kernelVersion)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res).To(BeFalse())
+	})
+})
+
+/***************** end signing ***********************/
+
+var _ = Describe("ModuleReconciler_getNodesListBySelector", func() {
+	var (
+		ctrl *gomock.Controller
+		clnt *client.MockClient
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+	})
+
+	ctx := context.Background()
+	mod := kmmv1beta1.Module{
+		Spec: kmmv1beta1.ModuleSpec{
+			Selector: map[string]string{"key": "value"},
+		},
+	}
+
+	It("no nodes with matching labels", func() {
+		clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, list *v1.NodeList, _ ...interface{}) error {
+				list.Items = []v1.Node{}
+				return nil
+			},
+		)
+		mr := NewModuleReconciler(clnt, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, false, false)
+		nodeList, err := mr.getNodesListBySelector(context.Background(), &mod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(nodeList)).To(Equal(0))
+	})
+
+	It("2 nodes with matching labels, all schedulable", func() {
+		clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, list *v1.NodeList, _ ...interface{}) error {
+				list.Items = []v1.Node{v1.Node{}, v1.Node{}}
+				return nil
+			},
+		)
+		mr := NewModuleReconciler(clnt, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, false, false)
+		nodeList, err := mr.getNodesListBySelector(context.Background(), &mod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(nodeList)).To(Equal(2))
+	})
+
+	It("2 nodes with matching labels, 1 not schedulable", func() {
+		notSchedulableNode := v1.Node{
+			Spec: v1.NodeSpec{
+				Taints: []v1.Taint{
+					{
+						Effect: v1.TaintEffectNoSchedule,
+					},
+				},
+			},
+		}
+		clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, list *v1.NodeList, _ ...interface{}) error {
+				list.Items = []v1.Node{notSchedulableNode, v1.Node{}}
+				return nil
+			},
+		)
+		mr := NewModuleReconciler(clnt, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, false, false)
+		nodeList, err := mr.getNodesListBySelector(context.Background(), &mod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(nodeList)).To(Equal(1))
+	})
+
+	It("should keep the nodes that only have the startup taint", func() {
+		startingNode := v1.Node{
+			Spec: v1.NodeSpec{
+				Taints: []v1.Taint{
+					{
+						Key:    constants.ModulesNotReadyTaint,
+						Effect: v1.TaintEffectNoSchedule,
+					},
+				},
+			},
+		}
+		clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, list *v1.NodeList, _ ...interface{}) error {
+				list.Items = []v1.Node{startingNode}
+				return nil
+			},
+		)
+		mr := NewModuleReconciler(clnt, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, false, false)
+		nodeList, err := mr.getNodesListBySelector(context.Background(), &mod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nodeList).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("ModuleReconciler_getRelevantKernelMappingsAndNodes", func() {
+	It("should use a single kernel mapping per kernel and skip the nodes that select another one", func() {
+		const gpuLabel = "feature.node.kubernetes.io/pci-10de.present"
+
+		mod := kmmv1beta1.Module{}
+		mod.Spec.ModuleLoader.Container.KernelMappings = []kmmv1beta1.KernelMapping{
+			{Regexp: "^.+$", ContainerImage: "nvidia-image", NodeSelector: map[string]string{gpuLabel: "true"}},
+			{Regexp: "^.+$", ContainerImage: "default-image"},
+		}
+
+		node := func(name, kernelVersion string, labels map[string]string) v1.Node {
+			return v1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+				Status:     v1.NodeStatus{NodeInfo: v1.NodeSystemInfo{KernelVersion: kernelVersion}},
+			}
+		}
+
+		targetedNodes := []v1.Node{
+			node("gpu-1", "5.14.0-1", map[string]string{gpuLabel: "true"}),
+			node("cpu-1", "5.14.0-1", nil),
+			node("gpu-2", "5.14.0-1", map[string]string{gpuLabel: "true"}),
+			node("cpu-2", "5.14.0-2", nil),
+		}
+
+		mr := NewModuleReconciler(nil, nil, nil, nil, nil, nil, nil, nil, nil, module.NewKernelMapper(), nil, nil, nil, nil, nil, nil, nil, false, false)
+
+		mappings, nodes, conflictingNodes, err := mr.getRelevantKernelMappingsAndNodes(context.Background(), &mod, targetedNodes)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mappings).To(HaveLen(2))
+		Expect(mappings["5.14.0-1"].ContainerImage).To(Equal("nvidia-image"))
+		Expect(mappings["5.14.0-2"].ContainerImage).To(Equal("default-image"))
+		Expect(nodes).To(Equal([]v1.Node{targetedNodes[0], targetedNodes[2], targetedNodes[3]}))
+		Expect(conflictingNodes).To(Equal([]v1.Node{targetedNodes[1]}))
+	})
+
+	It("should use the first mapping of the spec whatever the order of the nodes", func() {
+		const gpuLabel = "feature.node.kubernetes.io/pci-10de.present"
+
+		mod := kmmv1beta1.Module{}
+		mod.Spec.ModuleLoader.Container.KernelMappings = []kmmv1beta1.KernelMapping{
+			{Regexp: "^.+$", ContainerImage: "nvidia-image", NodeSelector: map[string]string{gpuLabel: "true"}},
+			{Regexp: "^.+$", ContainerImage: "default-image"},
+		}
+
+		cpuNode := v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "cpu"},
+			Status:     v1.NodeStatus{NodeInfo: v1.NodeSystemInfo{KernelVersion: "5.14.0-1"}},
+		}
+		gpuNode := v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "gpu", Labels: map[string]string{gpuLabel: "true"}},
+			Status:     v1.NodeStatus{NodeInfo: v1.NodeSystemInfo{KernelVersion: "5.14.0-1"}},
+		}
+
+		mr := NewModuleReconciler(nil, nil, nil, nil, nil, nil, nil, nil, nil, module.NewKernelMapper(), nil, nil, nil, nil, nil, nil, nil, false, false)
+
+		for _, targetedNodes := range [][]v1.Node{{cpuNode, gpuNode}, {gpuNode, cpuNode}} {
+			mappings, nodes, conflictingNodes, err := mr.getRelevantKernelMappingsAndNodes(context.Background(), &mod, targetedNodes)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mappings["5.14.0-1"].ContainerImage).To(Equal("nvidia-image"))
+			Expect(nodes).To(Equal([]v1.Node{gpuNode}))
+			Expect(conflictingNodes).To(Equal([]v1.Node{cpuNode}))
+		}
+	})
+
+	It("should resolve the images of the mappings", func() {
+		ctrl := gomock.NewController(GinkgoT())
+		mockResolver := imagestream.NewMockResolver(ctrl)
+
+		mod := kmmv1beta1.Module{ObjectMeta: metav1.ObjectMeta{Namespace: namespace}}
+		mod.Spec.ModuleLoader.Container.KernelMappings = []kmmv1beta1.KernelMapping{
+			{Regexp: "^.+$", ContainerImage: "imagestreamtag:kmod:${KERNEL_FULL_VERSION}"},
+		}
+
+		nodes := []v1.Node{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "resolved"},
+				Status:     v1.NodeStatus{NodeInfo: v1.NodeSystemInfo{KernelVersion: "5.14.0-1"}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "unresolved"},
+				Status:     v1.NodeStatus{NodeInfo: v1.NodeSystemInfo{KernelVersion: "5.14.0-2"}},
+			},
+		}
+
+		ctx := context.Background()
+
+		mockResolver.
+			EXPECT().
+			Resolve(ctx, "imagestreamtag:kmod:5.14.0-1", namespace).
+			Return("quay.io/vendor/kmod@sha256:0123", nil)
+		mockResolver.
+			EXPECT().
+			Resolve(ctx, "imagestreamtag:kmod:5.14.0-2", namespace).
+			Return("", errors.New("some error"))
+
+		mr := NewModuleReconciler(nil, nil, nil, nil, nil, nil, nil, nil, nil, module.NewKernelMapper(), nil, nil, nil, nil, nil, nil, mockResolver, false, false)
+
+		mappings, relevantNodes, conflictingNodes, err := mr.getRelevantKernelMappingsAndNodes(ctx, &mod, nodes)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(conflictingNodes).To(BeEmpty())
+		Expect(mappings).To(HaveLen(1))
+		Expect(mappings["5.14.0-1"].ContainerImage).To(Equal("quay.io/vendor/kmod@sha256:0123"))
+		Expect(relevantNodes).To(Equal(nodes))
+	})
+})
+
+var _ = Describe("recordNodeDecisions", func() {
+	It("should record why the Module is not deployed on some nodes", func() {
+		node := func(name, kernelVersion string) v1.Node {
+			return v1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Status:     v1.NodeStatus{NodeInfo: v1.NodeSystemInfo{KernelVersion: kernelVersion}},
+			}
+		}
+
+		deployed := node("deployed", "1.2.3+")
+		conflicting := node("conflicting", "1.2.3")
+		noMapping := node("no-mapping", "4.5.6")
+		noSubstitution := node("no-substitution", "7.8.9")
+
+		d := decisions.New(types.NamespacedName{Name: "test-module", Namespace: namespace})
+
+		recordNodeDecisions(
+			d,
+			[]v1.Node{deployed, conflicting, noMapping, noSubstitution},
+			[]v1.Node{deployed, noSubstitution},
+			[]v1.Node{conflicting},
+			map[string]*kmmv1beta1.KernelMapping{"1.2.3": {}},
+		)
+
+		Expect(d.Nodes).To(Equal([]decisions.Node{
+			{Name: "deployed", KernelVersion: "1.2.3"},
+			{Name: "conflicting", KernelVersion: "1.2.3", SkipReason: "another kernel mapping is used for the kernel on other nodes"},
+			{Name: "no-mapping", KernelVersion: "4.5.6", SkipReason: "no kernel mapping matches the kernel"},
+			{
+				Name:          "no-substitution",
+				KernelVersion: "7.8.9",
+				SkipReason:    "the kernel variables could not be substituted in the kernel mapping, or its image could not be resolved",
+			},
+		}))
+	})
+})
+
+var _ = Describe("ModuleReconciler_handleRebuildRequest", func() {
+	var (
+		ctrl *gomock.Controller
+		clnt *client.MockClient
+		mr   *ModuleReconciler
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+		mr = NewModuleReconciler(clnt, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, false, false)
+	})
+
+	ctx := context.Background()
+
+	newModule := func(kernels string) *kmmv1beta1.Module {
+		return &kmmv1beta1.Module{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "test-module",
+				Namespace:   namespace,
+				UID:         "some-uid",
+				Annotations: map[string]string{constants.RebuildKernelsAnnotation: kernels},
+			},
+		}
+	}
+
+	It("should delete the failed Jobs of the requested kernels and remove the annotation", func() {
+		mod := newModule("1.2.3, 4.5.6+")
+
+		controlled := func(name string, failed int32) batchv1.Job {
+			return batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            name,
+					Namespace:       namespace,
+					OwnerReferences: []metav1.OwnerReference{{UID: mod.UID, Controller: pointer.Bool(true)}},
+				},
+				Status: batchv1.JobStatus{Failed: failed},
+			}
+		}
+
+		failedBuild := controlled("failed-build", 1)
+		failedSign := controlled("failed-sign", 1)
+		notOwned := batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "not-owned"},
+			Status:     batchv1.JobStatus{Failed: 1},
+		}
+
+		gomock.InOrder(
+			clnt.EXPECT().List(
+				ctx,
+				gomock.Any(),
+				ctrlclient.InNamespace(namespace),
+				ctrlclient.MatchingLabels{constants.ModuleNameLabel: mod.Name, constants.TargetKernelTarget: "1.2.3"},
+			).DoAndReturn(
+				func(_ interface{}, list *batchv1.JobList, _ ...interface{}) error {
+					list.Items = []batchv1.Job{failedBuild, controlled("running-sign", 0), notOwned}
+					return nil
+				},
+			),
+			clnt.EXPECT().Delete(ctx, &failedBuild, gomock.Any()),
+			clnt.EXPECT().List(
+				ctx,
+				gomock.Any(),
+				ctrlclient.InNamespace(namespace),
+				ctrlclient.MatchingLabels{constants.ModuleNameLabel: mod.Name, constants.TargetKernelTarget: "4.5.6"},
+			).DoAndReturn(
+				func(_ interface{}, list *batchv1.JobList, _ ...interface{}) error {
+					list.Items = []batchv1.Job{failedSign}
+					return nil
+				},
+			),
+			clnt.EXPECT().Delete(ctx, &failedSign, gomock.Any()),
+			clnt.EXPECT().Patch(ctx, mod, gomock.Any()),
+		)
+
+		Expect(
+			mr.handleRebuildRequest(ctx, mod),
+		).NotTo(
+			HaveOccurred(),
+		)
+
+		Expect(mod.Annotations).NotTo(HaveKey(constants.RebuildKernelsAnnotation))
+	})
+
+	It("should return an error if the Jobs cannot be listed", func() {
+		mod := newModule("1.2.3")
+
+		clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).Return(errors.New("some error"))
+
+		Expect(
+			mr.handleRebuildRequest(ctx, mod),
+		).To(
+			HaveOccurred(),
+		)
+
+		Expect(mod.Annotations).To(HaveKey(constants.RebuildKernelsAnnotation))
+	})
+
+	It("should only remove the annotation if it lists no kernel", func() {
+		mod := newModule("")
+
+		clnt.EXPECT().Patch(ctx, mod, gomock.Any())
+
+		Expect(
+			mr.handleRebuildRequest(ctx, mod),
+		).NotTo(
+			HaveOccurred(),
+		)
+	})
+})
+
+var _ = Describe("ModuleReconciler_handleRetryBuild", func() {
+	var (
+		ctrl *gomock.Controller
+		clnt *client.MockClient
+		mr   *ModuleReconciler
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+		mr = NewModuleReconciler(clnt, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, false, false)
+	})
+
+	ctx := context.Background()
+
+	newModule := func(annotations map[string]string) *kmmv1beta1.Module {
+		return &kmmv1beta1.Module{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      moduleName,
-				Namespace: namespace,
+				Name:        "test-module",
+				Namespace:   namespace,
+				UID:         "some-uid",
+				Annotations: annotations,
 			},
-			Spec: kmmv1beta1.ModuleSpec{},
 		}
-		buildRes := build.Result{Requeue: true, Status: build.StatusCreated}
-		gomock.InOrder(
-			mockBM.EXPECT().ShouldSync(gomock.Any(), *mod, *km).Return(true, nil),
-			mockBM.EXPECT().Sync(gomock.Any(), *mod, *km, gomock.Any(), true, mod).Return(buildRes, nil),
-			mockMetrics.EXPECT().SetCompletedStage(mod.Name, mod.Namespace, kernelVersion, metrics.BuildStage, false),
-		)
+	}
 
-		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, mockDC, mockKM, mockMetrics, nil, mockSU)
-		res, err := mr.handleBuild(context.Background(), mod, km, kernelVersion)
-		Expect(err).NotTo(HaveOccurred())
-		Expect(res).To(BeTrue())
-	})
+	DescribeTable("retryBuildPending",
+		func(annotations map[string]string, expected bool) {
+			Expect(
+				retryBuildPending(newModule(annotations)),
+			).To(
+				Equal(expected),
+			)
+		},
+		Entry("no annotation", nil, false),
+		Entry("new request", map[string]string{constants.RetryBuildAnnotation: "1.2.3"}, true),
+		Entry(
+			"request already handled",
+			map[string]string{constants.RetryBuildAnnotation: "1.2.3", constants.RetryBuildHandledAnnotation: "1.2.3"},
+			false,
+		),
+		Entry(
+			"request changed",
+			map[string]string{constants.RetryBuildAnnotation: "4.5.6", constants.RetryBuildHandledAnnotation: "1.2.3"},
+			true,
+		),
+		Entry("request removed", map[string]string{constants.RetryBuildHandledAnnotation: "1.2.3"}, true),
+	)
 
-	It("should record that a job was completed, when the build sync returns StatusCompleted", func() {
-		km := &kmmv1beta1.KernelMapping{
-			ContainerImage: imageName,
-			Literal:        kernelVersion,
-			Build:          &kmmv1beta1.Build{},
-		}
-		mod := &kmmv1beta1.Module{
+	It("should delete the failed Jobs and record the request as handled", func() {
+		mod := newModule(map[string]string{constants.RetryBuildAnnotation: "1.2.3"})
+
+		failedBuild := batchv1.Job{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      moduleName,
-				Namespace: namespace,
+				Name:            "failed-build",
+				Namespace:       namespace,
+				OwnerReferences: []metav1.OwnerReference{{UID: mod.UID, Controller: pointer.Bool(true)}},
 			},
-			Spec: kmmv1beta1.ModuleSpec{},
+			Status: batchv1.JobStatus{Failed: 1},
 		}
-		buildRes := build.Result{Requeue: false, Status: build.StatusCompleted}
+
 		gomock.InOrder(
-			mockBM.EXPECT().ShouldSync(gomock.Any(), *mod, *km).Return(true, nil),
-			mockBM.EXPECT().Sync(gomock.Any(), *mod, *km, gomock.Any(), true, mod).Return(buildRes, nil),
-			mockMetrics.EXPECT().SetCompletedStage(mod.Name, mod.Namespace, kernelVersion, metrics.BuildStage, true),
+			clnt.EXPECT().List(
+				ctx,
+				gomock.Any(),
+				ctrlclient.InNamespace(namespace),
+				ctrlclient.MatchingLabels{constants.ModuleNameLabel: mod.Name, constants.TargetKernelTarget: "1.2.3"},
+			).DoAndReturn(
+				func(_ interface{}, list *batchv1.JobList, _ ...interface{}) error {
+					list.Items = []batchv1.Job{failedBuild}
+					return nil
+				},
+			),
+			clnt.EXPECT().Delete(ctx, &failedBuild, gomock.Any()),
+			clnt.EXPECT().Patch(ctx, mod, gomock.Any()),
 		)
 
-		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, mockDC, mockKM, mockMetrics, nil, mockSU)
-		res, err := mr.handleBuild(context.Background(), mod, km, kernelVersion)
-		Expect(err).NotTo(HaveOccurred())
-		Expect(res).To(BeFalse())
+		Expect(
+			mr.handleRetryBuild(ctx, mod),
+		).NotTo(
+			HaveOccurred(),
+		)
+
+		Expect(mod.Annotations).To(HaveKeyWithValue(constants.RetryBuildAnnotation, "1.2.3"))
+		Expect(mod.Annotations).To(HaveKeyWithValue(constants.RetryBuildHandledAnnotation, "1.2.3"))
+		Expect(retryBuildPending(mod)).To(BeFalse())
+	})
+
+	It("should not record the request as handled if the Jobs cannot be deleted", func() {
+		mod := newModule(map[string]string{constants.RetryBuildAnnotation: "1.2.3"})
+
+		clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).Return(errors.New("some error"))
+
+		Expect(
+			mr.handleRetryBuild(ctx, mod),
+		).To(
+			HaveOccurred(),
+		)
+
+		Expect(mod.Annotations).NotTo(HaveKey(constants.RetryBuildHandledAnnotation))
+	})
+
+	It("should only forget the handled request once the annotation is removed", func() {
+		mod := newModule(map[string]string{constants.RetryBuildHandledAnnotation: "1.2.3"})
+
+		clnt.EXPECT().Patch(ctx, mod, gomock.Any())
+
+		Expect(
+			mr.handleRetryBuild(ctx, mod),
+		).NotTo(
+			HaveOccurred(),
+		)
+
+		Expect(mod.Annotations).NotTo(HaveKey(constants.RetryBuildHandledAnnotation))
 	})
 })
 
-/***************** signing ***********************/
-var _ = Describe("ModuleReconciler_handleSigning", func() {
+var _ = Describe("ModuleReconciler_verifyProvenance", func() {
 	var (
-		ctrl        *gomock.Controller
-		clnt        *client.MockClient
-		mockBM      *build.MockManager
-		mockSM      *sign.MockSignManager
-		mockRC      *rbac.MockRBACCreator
-		mockDC      *daemonset.MockDaemonSetCreator
-		mockKM      *module.MockKernelMapper
-		mockMetrics *metrics.MockMetrics
-		mockSU      *statusupdater.MockModuleStatusUpdater
+		ctrl           *gomock.Controller
+		mockProvenance *provenance.MockVerifier
+		mr             *ModuleReconciler
 	)
 
 	BeforeEach(func() {
 		ctrl = gomock.NewController(GinkgoT())
-		clnt = client.NewMockClient(ctrl)
-		mockBM = build.NewMockManager(ctrl)
-		mockSM = sign.NewMockSignManager(ctrl)
-		mockRC = rbac.NewMockRBACCreator(ctrl)
-		mockDC = daemonset.NewMockDaemonSetCreator(ctrl)
-		mockKM = module.NewMockKernelMapper(ctrl)
-		mockMetrics = metrics.NewMockMetrics(ctrl)
-		mockSU = statusupdater.NewMockModuleStatusUpdater(ctrl)
+		mockProvenance = provenance.NewMockVerifier(ctrl)
+		mr = NewModuleReconciler(nil, nil, nil, nil, nil, nil, nil, mockProvenance, nil, nil, nil, nil, nil, nil, nil, nil, nil, false, false)
 	})
 
-	const (
-		moduleName    = "test-module"
-		kernelVersion = "1.2.3"
-		imageName     = "test-image"
-	)
+	ctx := context.Background()
 
-	It("should do nothing when build is skipped", func() {
-		km := &kmmv1beta1.KernelMapping{
-			ContainerImage: imageName,
-			Literal:        kernelVersion,
-		}
+	policy := &kmmv1beta1.ProvenancePolicy{
+		PublicKeySecret: v1.LocalObjectReference{Name: "some-secret"},
+	}
 
-		mod := &kmmv1beta1.Module{}
+	It("should do nothing if the Module has no provenance policy", func() {
+		km := &kmmv1beta1.KernelMapping{}
 
-		gomock.InOrder(
-			mockSM.EXPECT().ShouldSync(gomock.Any(), *mod, *km).Return(false, nil),
-		)
+		res, err := mr.verifyProvenance(ctx, &kmmv1beta1.Module{}, km)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res).To(BeIdenticalTo(km))
+	})
 
-		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, mockDC, mockKM, mockMetrics, nil, mockSU)
+	It("should not verify images built in-cluster", func() {
+		mod := &kmmv1beta1.Module{}
+		mod.Spec.ModuleLoader.Container.Provenance = policy
+		km := &kmmv1beta1.KernelMapping{Build: &kmmv1beta1.Build{}}
 
-		res, err := mr.handleSigning(context.Background(), mod, km, kernelVersion)
+		res, err := mr.verifyProvenance(ctx, mod, km)
 		Expect(err).NotTo(HaveOccurred())
-		Expect(res).To(BeFalse())
+		Expect(res).To(BeIdenticalTo(km))
 	})
 
-	It("should record that a job was created when the sign sync returns StatusCreated", func() {
-		km := &kmmv1beta1.KernelMapping{
-			ContainerImage: imageName,
-			Literal:        kernelVersion,
-			Sign:           &kmmv1beta1.Sign{},
-		}
-		mod := &kmmv1beta1.Module{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      moduleName,
-				Namespace: namespace,
-			},
-			Spec: kmmv1beta1.ModuleSpec{},
-		}
+	It("should return an error if a pre-built image cannot be verified", func() {
+		mod := &kmmv1beta1.Module{}
+		mod.Spec.ModuleLoader.Container.Provenance = policy
+		km := &kmmv1beta1.KernelMapping{ContainerImage: "some-image"}
 
-		signRes := utils.Result{Requeue: true, Status: utils.StatusCreated}
-		gomock.InOrder(
-			mockSM.EXPECT().ShouldSync(gomock.Any(), *mod, *km).Return(true, nil),
-			mockSM.EXPECT().Sync(gomock.Any(), *mod, *km, gomock.Any(), "", true, mod).Return(signRes, nil),
-			mockMetrics.EXPECT().SetCompletedStage(mod.Name, mod.Namespace, kernelVersion, metrics.SignStage, false),
-		)
+		mockProvenance.EXPECT().Verify(ctx, mod, km).Return("", provenance.ErrVerificationFailed)
 
-		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, mockDC, mockKM, mockMetrics, nil, mockSU)
+		_, err := mr.verifyProvenance(ctx, mod, km)
+		Expect(err).To(MatchError(provenance.ErrVerificationFailed))
+	})
 
-		res, err := mr.handleSigning(context.Background(), mod, km, kernelVersion)
+	It("should pin pre-built images to the verified digest", func() {
+		mod := &kmmv1beta1.Module{}
+		mod.Spec.ModuleLoader.Container.Provenance = policy
+		km := &kmmv1beta1.KernelMapping{ContainerImage: "example.com/org/kmod:v1"}
 
+		mockProvenance.EXPECT().Verify(ctx, mod, km).Return("example.com/org/kmod@sha256:0123", nil)
+
+		res, err := mr.verifyProvenance(ctx, mod, km)
 		Expect(err).NotTo(HaveOccurred())
-		Expect(res).To(BeTrue())
+		Expect(res.ContainerImage).To(Equal("example.com/org/kmod@sha256:0123"))
+		Expect(km.ContainerImage).To(Equal("example.com/org/kmod:v1"))
 	})
+})
 
-	It("should record that a job was completed when the sign sync returns StatusCompleted", func() {
-		km := &kmmv1beta1.KernelMapping{
-			ContainerImage: imageName,
-			Literal:        kernelVersion,
-			Sign:           &kmmv1beta1.Sign{},
-		}
-		mod := &kmmv1beta1.Module{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      moduleName,
-				Namespace: namespace,
-			},
-			Spec: kmmv1beta1.ModuleSpec{},
-		}
+var _ = Describe("specRejectedCondition", func() {
+	mod := &kmmv1beta1.Module{
+		ObjectMeta: metav1.ObjectMeta{Generation: 3},
+	}
 
-		signRes := utils.Result{Requeue: false, Status: build.StatusCompleted}
-		gomock.InOrder(
-			mockSM.EXPECT().ShouldSync(gomock.Any(), *mod, *km).Return(true, nil),
-			mockSM.EXPECT().Sync(gomock.Any(), *mod, *km, gomock.Any(), "", true, mod).Return(signRes, nil),
-			mockMetrics.EXPECT().SetCompletedStage(mod.Name, mod.Namespace, kernelVersion, metrics.SignStage, true),
-		)
+	It("should return a false condition if the spec is allowed", func() {
+		cond := specRejectedCondition(mod, nil)
 
-		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, mockDC, mockKM, mockMetrics, nil, mockSU)
+		Expect(cond.Type).To(Equal(kmmv1beta1.ModuleConditionSpecRejected))
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.ObservedGeneration).To(BeEquivalentTo(3))
+	})
 
-		res, err := mr.handleSigning(context.Background(), mod, km, kernelVersion)
+	It("should return a true condition with the validation error", func() {
+		cond := specRejectedCondition(mod, errors.New("some error"))
 
-		Expect(err).NotTo(HaveOccurred())
-		Expect(res).To(BeFalse())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal("SpecNotAllowed"))
+		Expect(cond.Message).To(Equal("some error"))
 	})
+})
 
-	It("should run sign sync with the previous image as well when module build and sign are specified", func() {
-		km := &kmmv1beta1.KernelMapping{
-			ContainerImage: imageName,
-			Literal:        kernelVersion,
-			Sign:           &kmmv1beta1.Sign{},
-			Build:          &kmmv1beta1.Build{},
-		}
-		mod := &kmmv1beta1.Module{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      moduleName,
-				Namespace: namespace,
-			},
-			Spec: kmmv1beta1.ModuleSpec{},
+var _ = Describe("fipsCondition", func() {
+	mod := &kmmv1beta1.Module{
+		ObjectMeta: metav1.ObjectMeta{Generation: 3},
+	}
+
+	It("should return a true condition in FIPS mode", func() {
+		cond := fipsCondition(mod, true)
+
+		Expect(cond.Type).To(Equal(kmmv1beta1.ModuleConditionFIPS))
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.ObservedGeneration).To(BeEquivalentTo(3))
+	})
+
+	It("should return a false condition outside of FIPS mode", func() {
+		Expect(fipsCondition(mod, false).Status).To(Equal(metav1.ConditionFalse))
+	})
+})
+
+var _ = Describe("mappingConflictCondition", func() {
+	mod := &kmmv1beta1.Module{ObjectMeta: metav1.ObjectMeta{Generation: 3}}
+
+	node := func(name string) v1.Node {
+		return v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     v1.NodeStatus{NodeInfo: v1.NodeSystemInfo{KernelVersion: "5.14.0-1+"}},
 		}
+	}
 
-		signRes := utils.Result{Requeue: false, Status: build.StatusCompleted}
-		gomock.InOrder(
-			mockSM.EXPECT().ShouldSync(gomock.Any(), *mod, *km).Return(true, nil),
-			mockSM.EXPECT().Sync(gomock.Any(), *mod, *km, gomock.Any(), imageName+":"+namespace+"_"+moduleName+"_kmm_unsigned", true, mod).Return(signRes, nil),
-			mockMetrics.EXPECT().SetCompletedStage(mod.Name, mod.Namespace, kernelVersion, metrics.SignStage, true),
-		)
+	It("should be false if no node is skipped", func() {
+		cond := mappingConflictCondition(mod, nil)
+		Expect(cond.Type).To(Equal(kmmv1beta1.ModuleConditionMappingConflict))
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.ObservedGeneration).To(BeEquivalentTo(3))
+	})
 
-		mr := NewModuleReconciler(clnt, mockBM, mockSM, mockRC, mockDC, mockKM, mockMetrics, nil, mockSU)
+	It("should list the skipped nodes in a stable order", func() {
+		cond := mappingConflictCondition(mod, []v1.Node{node("node-b"), node("node-a")})
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal("NodesSkipped"))
+		Expect(cond.Message).To(Equal(
+			"2 node(s) select another kernel mapping than the one used for their kernel and are skipped: node-a (5.14.0-1), node-b (5.14.0-1)",
+		))
+	})
 
-		res, err := mr.handleSigning(context.Background(), mod, km, kernelVersion)
+	It("should only list the first nodes", func() {
+		nodes := make([]v1.Node, 0, maxMappingConflictNodes+2)
 
-		Expect(err).NotTo(HaveOccurred())
-		Expect(res).To(BeFalse())
+		for i := 0; i < maxMappingConflictNodes+2; i++ {
+			nodes = append(nodes, node(fmt.Sprintf("node-%02d", i)))
+		}
+
+		cond := mappingConflictCondition(mod, nodes)
+		Expect(cond.Message).To(HavePrefix("12 node(s) "))
+		Expect(cond.Message).To(HaveSuffix("node-09 (5.14.0-1), and 2 more"))
 	})
 })
 
-/***************** end signing ***********************/
+var _ = Describe("provenanceCondition", func() {
+	mod := &kmmv1beta1.Module{
+		ObjectMeta: metav1.ObjectMeta{Generation: 3},
+	}
 
-var _ = Describe("ModuleReconciler_getNodesListBySelector", func() {
-	var (
-		ctrl *gomock.Controller
-		clnt *client.MockClient
-	)
+	It("should not be degraded if all images were verified", func() {
+		cond := provenanceCondition(mod, nil)
+		Expect(cond.Type).To(Equal(kmmv1beta1.ModuleConditionDegraded))
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.ObservedGeneration).To(Equal(int64(3)))
+	})
 
-	BeforeEach(func() {
-		ctrl = gomock.NewController(GinkgoT())
-		clnt = client.NewMockClient(ctrl)
+	It("should be degraded and list the failures in order if some images were not verified", func() {
+		cond := provenanceCondition(mod, []string{"kernel version 2: failure", "kernel version 1: failure"})
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal("ProvenanceVerificationFailed"))
+		Expect(cond.Message).To(Equal("kernel version 1: failure\nkernel version 2: failure"))
 	})
+})
 
-	ctx := context.Background()
-	mod := kmmv1beta1.Module{
-		Spec: kmmv1beta1.ModuleSpec{
-			Selector: map[string]string{"key": "value"},
-		},
+var _ = Describe("kernelConditions", func() {
+	statuses := func(conds []metav1.Condition) map[string]metav1.ConditionStatus {
+		m := make(map[string]metav1.ConditionStatus, len(conds))
+
+		for _, c := range conds {
+			m[c.Type] = c.Status
+		}
+
+		return m
 	}
 
-	It("no nodes with matching labels", func() {
-		clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
-			func(_ interface{}, list *v1.NodeList, _ ...interface{}) error {
-				list.Items = []v1.Node{}
-				return nil
-			},
+	It("should have no conditions for pending kernels", func() {
+		Expect(
+			kernelConditions(3, kmmv1beta1.KernelPhasePending, true, true, nil),
+		).To(
+			BeEmpty(),
 		)
-		mr := NewModuleReconciler(clnt, nil, nil, nil, nil, nil, nil, nil, nil)
-		nodeList, err := mr.getNodesListBySelector(context.Background(), &mod)
-		Expect(err).NotTo(HaveOccurred())
-		Expect(len(nodeList)).To(Equal(0))
 	})
 
-	It("2 nodes with matching labels, all schedulable", func() {
-		clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
-			func(_ interface{}, list *v1.NodeList, _ ...interface{}) error {
-				list.Items = []v1.Node{v1.Node{}, v1.Node{}}
-				return nil
-			},
-		)
-		mr := NewModuleReconciler(clnt, nil, nil, nil, nil, nil, nil, nil, nil)
-		nodeList, err := mr.getNodesListBySelector(context.Background(), &mod)
-		Expect(err).NotTo(HaveOccurred())
-		Expect(len(nodeList)).To(Equal(2))
+	It("should report the step in progress and the previous ones", func() {
+		conds := kernelConditions(3, kmmv1beta1.KernelPhaseSigning, true, true, nil)
+
+		Expect(statuses(conds)).To(Equal(map[string]metav1.ConditionStatus{
+			kmmv1beta1.KernelConditionBuilt:     metav1.ConditionTrue,
+			kmmv1beta1.KernelConditionSigned:    metav1.ConditionFalse,
+			kmmv1beta1.KernelConditionRolledOut: metav1.ConditionUnknown,
+		}))
+		Expect(conds[1].Reason).To(Equal("Signing"))
+
+		for _, c := range conds {
+			Expect(c.ObservedGeneration).To(Equal(int64(3)))
+		}
 	})
 
-	It("2 nodes with matching labels, 1 not schedulable", func() {
-		notSchedulableNode := v1.Node{
-			Spec: v1.NodeSpec{
-				Taints: []v1.Taint{
-					{
-						Effect: v1.TaintEffectNoSchedule,
-					},
-				},
-			},
+	It("should report the steps that are not required as true", func() {
+		conds := kernelConditions(3, kmmv1beta1.KernelPhaseDeploying, false, false, nil)
+
+		Expect(statuses(conds)).To(Equal(map[string]metav1.ConditionStatus{
+			kmmv1beta1.KernelConditionBuilt:     metav1.ConditionTrue,
+			kmmv1beta1.KernelConditionSigned:    metav1.ConditionTrue,
+			kmmv1beta1.KernelConditionRolledOut: metav1.ConditionFalse,
+		}))
+		Expect(conds[0].Reason).To(Equal("NotRequired"))
+	})
+
+	It("should report the failed Job", func() {
+		jf := &kmmv1beta1.JobFailure{JobType: utils.JobTypeBuild, JobName: "some-job"}
+
+		conds := kernelConditions(3, kmmv1beta1.KernelPhaseFailed, true, false, jf)
+
+		Expect(statuses(conds)).To(Equal(map[string]metav1.ConditionStatus{
+			kmmv1beta1.KernelConditionBuilt:     metav1.ConditionFalse,
+			kmmv1beta1.KernelConditionSigned:    metav1.ConditionTrue,
+			kmmv1beta1.KernelConditionRolledOut: metav1.ConditionUnknown,
+		}))
+		Expect(conds[0].Reason).To(Equal("JobFailed"))
+		Expect(conds[0].Message).To(Equal("Job some-job failed"))
+	})
+
+	It("should report the failed verification as a rollout failure", func() {
+		conds := kernelConditions(3, kmmv1beta1.KernelPhaseFailed, false, true, nil)
+
+		Expect(statuses(conds)).To(Equal(map[string]metav1.ConditionStatus{
+			kmmv1beta1.KernelConditionBuilt:     metav1.ConditionTrue,
+			kmmv1beta1.KernelConditionSigned:    metav1.ConditionTrue,
+			kmmv1beta1.KernelConditionRolledOut: metav1.ConditionFalse,
+		}))
+		Expect(conds[2].Reason).To(Equal("VerificationFailed"))
+	})
+
+	It("should report all steps as true for ready kernels", func() {
+		for _, c := range kernelConditions(3, kmmv1beta1.KernelPhaseReady, true, true, nil) {
+			Expect(c.Status).To(Equal(metav1.ConditionTrue))
 		}
-		clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
-			func(_ interface{}, list *v1.NodeList, _ ...interface{}) error {
-				list.Items = []v1.Node{notSchedulableNode, v1.Node{}}
-				return nil
-			},
-		)
-		mr := NewModuleReconciler(clnt, nil, nil, nil, nil, nil, nil, nil, nil)
-		nodeList, err := mr.getNodesListBySelector(context.Background(), &mod)
-		Expect(err).NotTo(HaveOccurred())
-		Expect(len(nodeList)).To(Equal(1))
 	})
 })
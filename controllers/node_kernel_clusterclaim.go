@@ -13,6 +13,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -87,7 +88,7 @@ func (r *NodeKernelClusterClaimReconciler) Reconcile(ctx context.Context, req ct
 }
 
 // SetupWithManager sets up the controller with the Manager.
-func (r *NodeKernelClusterClaimReconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (r *NodeKernelClusterClaimReconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
 	return ctrl.
 		NewControllerManagedBy(mgr).
 		Named(NodeKernelClusterClaimReconcilerName).
@@ -113,5 +114,6 @@ func (r *NodeKernelClusterClaimReconciler) SetupWithManager(mgr ctrl.Manager) er
 				}),
 			),
 		).
+		WithOptions(opts).
 		Complete(r)
 }
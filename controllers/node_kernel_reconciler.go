@@ -10,6 +10,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -65,7 +66,7 @@ func (r *NodeKernelReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 }
 
 // SetupWithManager sets up the controller with the Manager.
-func (r *NodeKernelReconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (r *NodeKernelReconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
 	return ctrl.
 		NewControllerManagedBy(mgr).
 		Named(NodeKernelReconcilerName).
@@ -73,5 +74,6 @@ func (r *NodeKernelReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		WithEventFilter(
 			r.filter.NodeKernelReconcilerPredicate(r.labelName),
 		).
+		WithOptions(opts).
 		Complete(r)
 }
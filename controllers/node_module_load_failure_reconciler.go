@@ -0,0 +1,227 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kubectl/pkg/util/podutils"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/daemonset"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/filter"
+)
+
+//+kubebuilder:rbac:groups="core",resources=nodes,verbs=get;patch
+//+kubebuilder:rbac:groups="core",resources=nodes/status,verbs=patch
+//+kubebuilder:rbac:groups="core",resources=pods,verbs=list;watch
+
+const (
+	NodeModuleLoadFailureReconcilerName = "NodeModuleLoadFailure"
+
+	moduleLoadFailedConditionReason    = "ModuleLoadFailed"
+	noModuleLoadFailureConditionReason = "NoModuleLoadFailure"
+)
+
+// NodeModuleLoadFailureReconciler reports the nodes on which module-loaders failed to load their module at least
+// threshold times through the constants.ModuleLoadFailureNodeCondition condition, which remediation systems watching
+// the conditions of the Node Problem Detector can act upon.
+// If cordon is true, it also cordons those nodes, and uncordons them once no module-loader fails there anymore.
+type NodeModuleLoadFailureReconciler struct {
+	client    client.Client
+	threshold int32
+	cordon    bool
+}
+
+func NewNodeModuleLoadFailureReconciler(client client.Client, threshold int32, cordon bool) *NodeModuleLoadFailureReconciler {
+	return &NodeModuleLoadFailureReconciler{
+		client:    client,
+		threshold: threshold,
+		cordon:    cordon,
+	}
+}
+
+func (r *NodeModuleLoadFailureReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := ctrl.LoggerFrom(ctx)
+
+	node := v1.Node{}
+
+	if err := r.client.Get(ctx, req.NamespacedName, &node); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+
+		return ctrl.Result{}, fmt.Errorf("could not get node: %v", err)
+	}
+
+	failing, err := r.failingModules(ctx, node.Name)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err = r.setCondition(ctx, &node, failing); err != nil {
+		return ctrl.Result{}, fmt.Errorf("could not set the %s condition: %v", constants.ModuleLoadFailureNodeCondition, err)
+	}
+
+	if !r.cordon {
+		return ctrl.Result{}, nil
+	}
+
+	nodeCopy := node.DeepCopy()
+	_, cordoned := node.Annotations[constants.CordonedForModuleLoadFailureAnnotation]
+
+	switch {
+	case len(failing) > 0 && !node.Spec.Unschedulable:
+		logger.Info("Cordoning the node", "modules", failing)
+
+		if node.Annotations == nil {
+			node.Annotations = make(map[string]string, 1)
+		}
+
+		node.Annotations[constants.CordonedForModuleLoadFailureAnnotation] = ""
+		node.Spec.Unschedulable = true
+	case len(failing) == 0 && cordoned:
+		logger.Info("Uncordoning the node")
+
+		delete(node.Annotations, constants.CordonedForModuleLoadFailureAnnotation)
+		node.Spec.Unschedulable = false
+	default:
+		return ctrl.Result{}, nil
+	}
+
+	if err = r.client.Patch(ctx, &node, client.MergeFrom(nodeCopy)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("could not patch the node: %v", err)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// failingModules returns the sorted namespaced names of the Modules whose module-loader is not ready on the node and
+// restarted at least threshold times.
+func (r *NodeModuleLoadFailureReconciler) failingModules(ctx context.Context, nodeName string) ([]string, error) {
+	pods := v1.PodList{}
+
+	if err := r.client.List(ctx, &pods, client.MatchingLabels{constants.DaemonSetRole: "module-loader"}); err != nil {
+		return nil, fmt.Errorf("could not list the module-loader pods: %v", err)
+	}
+
+	failing := make([]string, 0)
+
+	for i := 0; i < len(pods.Items); i++ {
+		pod := &pods.Items[i]
+
+		if pod.Spec.NodeName != nodeName || !pod.DeletionTimestamp.IsZero() || podutils.IsPodReady(pod) {
+			continue
+		}
+
+		if daemonset.ModuleLoaderRestarts(pod) >= r.threshold {
+			failing = append(failing, pod.Namespace+"/"+pod.Labels[constants.ModuleNameLabel])
+		}
+	}
+
+	sort.Strings(failing)
+
+	return failing, nil
+}
+
+// setCondition sets the constants.ModuleLoadFailureNodeCondition condition of node to true if Modules are failing.
+// The condition is only set to false if the node already has it.
+func (r *NodeModuleLoadFailureReconciler) setCondition(ctx context.Context, node *v1.Node, failing []string) error {
+	cond := v1.NodeCondition{
+		Type:    constants.ModuleLoadFailureNodeCondition,
+		Status:  v1.ConditionFalse,
+		Reason:  noModuleLoadFailureConditionReason,
+		Message: "All module-loaders loaded their module",
+	}
+
+	if len(failing) > 0 {
+		cond.Status = v1.ConditionTrue
+		cond.Reason = moduleLoadFailedConditionReason
+		cond.Message = fmt.Sprintf(
+			"The module-loaders of the following Modules failed at least %d times: %s",
+			r.threshold,
+			strings.Join(failing, ", "),
+		)
+	}
+
+	idx := -1
+
+	for i, c := range node.Status.Conditions {
+		if c.Type == cond.Type {
+			idx = i
+			break
+		}
+	}
+
+	switch {
+	case idx == -1 && len(failing) == 0:
+		return nil
+	case idx != -1:
+		existing := node.Status.Conditions[idx]
+
+		if existing.Status == cond.Status && existing.Message == cond.Message {
+			return nil
+		}
+	}
+
+	nodeCopy := node.DeepCopy()
+	now := metav1.Now()
+
+	cond.LastHeartbeatTime = now
+	cond.LastTransitionTime = now
+
+	if idx == -1 {
+		node.Status.Conditions = append(node.Status.Conditions, cond)
+	} else {
+		if node.Status.Conditions[idx].Status == cond.Status {
+			cond.LastTransitionTime = node.Status.Conditions[idx].LastTransitionTime
+		}
+
+		node.Status.Conditions[idx] = cond
+	}
+
+	// Node conditions are merged by type, so that the conditions set by the kubelet and others are kept.
+	return r.client.Status().Patch(ctx, node, client.StrategicMergeFrom(nodeCopy))
+}
+
+func nodeForPod(pod client.Object) []reconcile.Request {
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: pod.(*v1.Pod).Spec.NodeName}},
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// Nodes are reconciled when module-loader pods running there restart, become ready or not ready, or are deleted.
+func (r *NodeModuleLoadFailureReconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
+	return ctrl.
+		NewControllerManagedBy(mgr).
+		Named(NodeModuleLoadFailureReconcilerName).
+		Watches(
+			&source.Kind{Type: &v1.Pod{}},
+			handler.EnqueueRequestsFromMapFunc(nodeForPod),
+			builder.WithPredicates(
+				filter.HasLabel(constants.ModuleNameLabel),
+				filter.PodHasSpecNodeName(),
+				predicate.Or(
+					filter.ModuleLoaderRestartedPredicate(),
+					filter.PodReadinessChangedPredicate(mgr.GetLogger().WithName("pod-readiness-changed")),
+					filter.DeletingPredicate(),
+				),
+			),
+		).
+		WithOptions(opts).
+		Complete(r)
+}
@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mock_client "github.com/kubernetes-sigs/kernel-module-management/internal/client"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+)
+
+var _ = Describe("NodeModuleLoadFailureReconciler", func() {
+	const nodeName = "worker-0"
+
+	var (
+		kubeClient  *mock_client.MockClient
+		statusWrite *mock_client.MockStatusWriter
+	)
+
+	BeforeEach(func() {
+		ctrl := gomock.NewController(GinkgoT())
+		kubeClient = mock_client.NewMockClient(ctrl)
+		statusWrite = mock_client.NewMockStatusWriter(ctrl)
+	})
+
+	ctx := context.Background()
+	nn := types.NamespacedName{Name: nodeName}
+	req := ctrl.Request{NamespacedName: nn}
+
+	failedCondition := v1.NodeCondition{
+		Type:    constants.ModuleLoadFailureNodeCondition,
+		Status:  v1.ConditionTrue,
+		Reason:  "ModuleLoadFailed",
+		Message: "The module-loaders of the following Modules failed at least 3 times: default/kmod-a",
+	}
+
+	expectGetNode := func(unschedulable bool, annotations map[string]string, conditions ...v1.NodeCondition) *gomock.Call {
+		return kubeClient.
+			EXPECT().
+			Get(ctx, nn, gomock.AssignableToTypeOf(&v1.Node{})).
+			Do(func(_ context.Context, _ types.NamespacedName, node *v1.Node, _ ...client.GetOption) {
+				node.Name = nodeName
+				node.Annotations = annotations
+				node.Spec.Unschedulable = unschedulable
+				node.Status.Conditions = conditions
+			})
+	}
+
+	modLoaderPod := func(moduleName, nodeName string, restarts int32) v1.Pod {
+		return v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      moduleName + "-" + nodeName,
+				Namespace: "default",
+				Labels:    map[string]string{constants.ModuleNameLabel: moduleName},
+			},
+			Spec: v1.PodSpec{NodeName: nodeName},
+			Status: v1.PodStatus{
+				ContainerStatuses: []v1.ContainerStatus{{Name: "module-loader", RestartCount: restarts}},
+			},
+		}
+	}
+
+	expectListPods := func(pods ...v1.Pod) *gomock.Call {
+		return kubeClient.
+			EXPECT().
+			List(ctx, gomock.AssignableToTypeOf(&v1.PodList{}), gomock.Any()).
+			DoAndReturn(func(_ interface{}, list *v1.PodList, _ ...interface{}) error {
+				list.Items = pods
+				return nil
+			})
+	}
+
+	It("should set the condition on nodes where module-loaders repeatedly fail", func() {
+		r := NewNodeModuleLoadFailureReconciler(kubeClient, 3, false)
+
+		gomock.InOrder(
+			expectGetNode(false, nil),
+			expectListPods(
+				modLoaderPod("kmod-a", nodeName, 3),
+				modLoaderPod("kmod-b", nodeName, 1),
+				modLoaderPod("kmod-c", "worker-1", 5),
+			),
+			kubeClient.EXPECT().Status().Return(statusWrite),
+			statusWrite.EXPECT().Patch(ctx, gomock.Any(), gomock.Any()).Do(
+				func(_ context.Context, node *v1.Node, _ client.Patch, _ ...client.PatchOption) {
+					Expect(node.Status.Conditions).To(HaveLen(1))
+
+					cond := node.Status.Conditions[0]
+					cond.LastHeartbeatTime = metav1.Time{}
+					cond.LastTransitionTime = metav1.Time{}
+
+					Expect(cond).To(Equal(failedCondition))
+				},
+			),
+		)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should not add the condition to nodes where no module-loader fails", func() {
+		r := NewNodeModuleLoadFailureReconciler(kubeClient, 3, true)
+
+		gomock.InOrder(
+			expectGetNode(false, nil),
+			expectListPods(modLoaderPod("kmod-b", nodeName, 1)),
+		)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should cordon the node if the policy requires it", func() {
+		r := NewNodeModuleLoadFailureReconciler(kubeClient, 3, true)
+
+		gomock.InOrder(
+			expectGetNode(false, nil, failedCondition),
+			expectListPods(modLoaderPod("kmod-a", nodeName, 4)),
+			kubeClient.EXPECT().Patch(ctx, gomock.Any(), gomock.Any()).Do(
+				func(_ context.Context, node *v1.Node, _ client.Patch, _ ...client.PatchOption) {
+					Expect(node.Spec.Unschedulable).To(BeTrue())
+					Expect(node.Annotations).To(HaveKey(constants.CordonedForModuleLoadFailureAnnotation))
+				},
+			),
+		)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should clear the condition and uncordon the node once module-loaders no longer fail", func() {
+		r := NewNodeModuleLoadFailureReconciler(kubeClient, 3, true)
+
+		gomock.InOrder(
+			expectGetNode(true, map[string]string{constants.CordonedForModuleLoadFailureAnnotation: ""}, failedCondition),
+			expectListPods(),
+			kubeClient.EXPECT().Status().Return(statusWrite),
+			statusWrite.EXPECT().Patch(ctx, gomock.Any(), gomock.Any()).Do(
+				func(_ context.Context, node *v1.Node, _ client.Patch, _ ...client.PatchOption) {
+					Expect(node.Status.Conditions[0].Status).To(Equal(v1.ConditionFalse))
+				},
+			),
+			kubeClient.EXPECT().Patch(ctx, gomock.Any(), gomock.Any()).Do(
+				func(_ context.Context, node *v1.Node, _ client.Patch, _ ...client.PatchOption) {
+					Expect(node.Spec.Unschedulable).To(BeFalse())
+					Expect(node.Annotations).NotTo(HaveKey(constants.CordonedForModuleLoadFailureAnnotation))
+				},
+			),
+		)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should not uncordon nodes that it did not cordon", func() {
+		r := NewNodeModuleLoadFailureReconciler(kubeClient, 3, true)
+
+		gomock.InOrder(
+			expectGetNode(true, nil),
+			expectListPods(),
+		)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
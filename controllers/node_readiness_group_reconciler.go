@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/daemonset"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/filter"
+)
+
+const NodeReadinessGroupReconcilerName = "NodeReadinessGroup"
+
+// NodeReadinessGroupReconciler labels each node with the ready label of the readiness groups whose Modules are all
+// ready on that node, so that workloads such as KubeVirt VMs can wait for several Modules with a single node selector.
+// Only the Modules whose selector matches a node are considered for that node.
+type NodeReadinessGroupReconciler struct {
+	client client.Client
+	filter *filter.Filter
+}
+
+func NewNodeReadinessGroupReconciler(client client.Client, filter *filter.Filter) *NodeReadinessGroupReconciler {
+	return &NodeReadinessGroupReconciler{
+		client: client,
+		filter: filter,
+	}
+}
+
+func (r *NodeReadinessGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	node := v1.Node{}
+
+	if err := r.client.Get(ctx, req.NamespacedName, &node); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+
+		return ctrl.Result{}, fmt.Errorf("could not get node: %v", err)
+	}
+
+	mods := kmmv1beta1.ModuleList{}
+
+	if err := r.client.List(ctx, &mods, client.HasLabels{constants.ReadinessGroupLabel}); err != nil {
+		return ctrl.Result{}, fmt.Errorf("could not list Modules in readiness groups: %v", err)
+	}
+
+	readyLabels := readyGroupLabels(&node, mods.Items)
+
+	p := client.MergeFrom(node.DeepCopy())
+	changed := false
+
+	for k := range node.Labels {
+		if daemonset.IsGroupReadyNodeLabel(k) && !readyLabels.Has(k) {
+			delete(node.Labels, k)
+			changed = true
+		}
+	}
+
+	for _, k := range readyLabels.UnsortedList() {
+		if _, ok := node.Labels[k]; ok {
+			continue
+		}
+
+		if node.Labels == nil {
+			node.Labels = make(map[string]string)
+		}
+
+		node.Labels[k] = ""
+		changed = true
+	}
+
+	if !changed {
+		return ctrl.Result{}, nil
+	}
+
+	logger.Info("Patching the group ready labels", "labels", readyLabels.List())
+
+	if err := r.client.Patch(ctx, &node, p); err != nil {
+		return ctrl.Result{}, fmt.Errorf("could not patch the node: %v", err)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// readyGroupLabels returns the ready labels of the groups of which at least one Module targets node, and all the
+// Modules targeting node are ready on it.
+func readyGroupLabels(node *v1.Node, mods []kmmv1beta1.Module) sets.String {
+	nodeLabels := labels.Set(node.Labels)
+	groupReady := make(map[string]bool)
+
+	for _, mod := range mods {
+		group := mod.Labels[constants.ReadinessGroupLabel]
+
+		if group == "" || mod.DeletionTimestamp != nil || !labels.SelectorFromSet(mod.Spec.Selector).Matches(nodeLabels) {
+			continue
+		}
+
+		_, ready := node.Labels[daemonset.ModuleReadyNodeLabel(mod.Name)]
+
+		if r, ok := groupReady[group]; ok {
+			ready = ready && r
+		}
+
+		groupReady[group] = ready
+	}
+
+	readyLabels := sets.NewString()
+
+	for group, ready := range groupReady {
+		if ready {
+			readyLabels.Insert(daemonset.GroupReadyNodeLabel(group))
+		}
+	}
+
+	return readyLabels
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// Nodes are reconciled when their labels change, and all nodes when a Module in a readiness group changes.
+func (r *NodeReadinessGroupReconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
+	return ctrl.
+		NewControllerManagedBy(mgr).
+		Named(NodeReadinessGroupReconcilerName).
+		For(&v1.Node{}, builder.WithPredicates(predicate.LabelChangedPredicate{})).
+		Watches(
+			&source.Kind{Type: &kmmv1beta1.Module{}},
+			handler.EnqueueRequestsFromMapFunc(r.filter.EnqueueAllNodes),
+			builder.WithPredicates(filter.ModuleReadinessGroupPredicate()),
+		).
+		WithOptions(opts).
+		Complete(r)
+}
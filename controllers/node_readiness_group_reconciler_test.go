@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	mock_client "github.com/kubernetes-sigs/kernel-module-management/internal/client"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+)
+
+var _ = Describe("NodeReadinessGroupReconciler", func() {
+	const (
+		nodeName       = "worker-0"
+		groupReadyName = "kmm.node.kubernetes.io/kubevirt.group-ready"
+	)
+
+	var (
+		kubeClient *mock_client.MockClient
+		r          *NodeReadinessGroupReconciler
+	)
+
+	BeforeEach(func() {
+		ctrl := gomock.NewController(GinkgoT())
+		kubeClient = mock_client.NewMockClient(ctrl)
+		r = NewNodeReadinessGroupReconciler(kubeClient, nil)
+	})
+
+	ctx := context.Background()
+	nn := types.NamespacedName{Name: nodeName}
+	req := ctrl.Request{NamespacedName: nn}
+
+	groupModule := func(name string, selector map[string]string) kmmv1beta1.Module {
+		return kmmv1beta1.Module{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{constants.ReadinessGroupLabel: "kubevirt"},
+			},
+			Spec: kmmv1beta1.ModuleSpec{Selector: selector},
+		}
+	}
+
+	mods := []kmmv1beta1.Module{
+		groupModule("vfio-pci", nil),
+		groupModule("kvm-intel", map[string]string{"cpu-vendor": "intel"}),
+	}
+
+	expectGetNode := func(nodeLabels map[string]string) *gomock.Call {
+		return kubeClient.
+			EXPECT().
+			Get(ctx, nn, gomock.AssignableToTypeOf(&v1.Node{})).
+			Do(func(_ context.Context, _ types.NamespacedName, node *v1.Node, _ ...client.GetOption) {
+				node.Name = nodeName
+				node.Labels = nodeLabels
+			}).
+			Return(nil)
+	}
+
+	expectList := func() *gomock.Call {
+		return kubeClient.
+			EXPECT().
+			List(ctx, gomock.AssignableToTypeOf(&kmmv1beta1.ModuleList{}), client.HasLabels{constants.ReadinessGroupLabel}).
+			DoAndReturn(func(_ interface{}, list *kmmv1beta1.ModuleList, _ ...interface{}) error {
+				list.Items = mods
+				return nil
+			})
+	}
+
+	It("should label the node once all the Modules of the group targeting it are ready", func() {
+		gomock.InOrder(
+			expectGetNode(map[string]string{
+				"cpu-vendor":                             "intel",
+				"kmm.node.kubernetes.io/vfio-pci.ready":  "",
+				"kmm.node.kubernetes.io/kvm-intel.ready": "",
+			}),
+			expectList(),
+			kubeClient.EXPECT().Patch(ctx, gomock.Any(), gomock.Any()).Do(
+				func(_ context.Context, node *v1.Node, _ client.Patch, _ ...client.PatchOption) {
+					Expect(node.Labels).To(HaveKeyWithValue(groupReadyName, ""))
+				},
+			),
+		)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should ignore the Modules of the group that do not target the node", func() {
+		gomock.InOrder(
+			expectGetNode(map[string]string{"kmm.node.kubernetes.io/vfio-pci.ready": ""}),
+			expectList(),
+			kubeClient.EXPECT().Patch(ctx, gomock.Any(), gomock.Any()).Do(
+				func(_ context.Context, node *v1.Node, _ client.Patch, _ ...client.PatchOption) {
+					Expect(node.Labels).To(HaveKeyWithValue(groupReadyName, ""))
+				},
+			),
+		)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should remove the label if a Module of the group is not ready anymore", func() {
+		gomock.InOrder(
+			expectGetNode(map[string]string{
+				"cpu-vendor":                            "intel",
+				"kmm.node.kubernetes.io/vfio-pci.ready": "",
+				groupReadyName:                          "",
+			}),
+			expectList(),
+			kubeClient.EXPECT().Patch(ctx, gomock.Any(), gomock.Any()).Do(
+				func(_ context.Context, node *v1.Node, _ client.Patch, _ ...client.PatchOption) {
+					Expect(node.Labels).NotTo(HaveKey(groupReadyName))
+				},
+			),
+		)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should not patch the node if its labels are up to date", func() {
+		gomock.InOrder(
+			expectGetNode(map[string]string{
+				"kmm.node.kubernetes.io/vfio-pci.ready": "",
+				groupReadyName:                          "",
+			}),
+			expectList(),
+		)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
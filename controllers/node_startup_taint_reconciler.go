@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/daemonset"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/filter"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+)
+
+//+kubebuilder:rbac:groups="core",resources=nodes,verbs=get;list;patch;watch
+
+const NodeStartupTaintReconcilerName = "NodeStartupTaint"
+
+// NodeStartupTaintReconciler removes the constants.ModulesNotReadyTaint startup taint of nodes once the module-loaders
+// of all the Modules targeting them are ready.
+// Nodes are expected to register with the taint, for instance through their autoscaler's node template, so that no
+// workload is scheduled onto them before their modules are loaded.
+type NodeStartupTaintReconciler struct {
+	client    client.Client
+	kernelAPI module.KernelMapper
+	filter    *filter.Filter
+}
+
+func NewNodeStartupTaintReconciler(
+	client client.Client,
+	kernelAPI module.KernelMapper,
+	filter *filter.Filter,
+) *NodeStartupTaintReconciler {
+	return &NodeStartupTaintReconciler{
+		client:    client,
+		kernelAPI: kernelAPI,
+		filter:    filter,
+	}
+}
+
+func (r *NodeStartupTaintReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	node := v1.Node{}
+
+	if err := r.client.Get(ctx, req.NamespacedName, &node); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+
+		return ctrl.Result{}, fmt.Errorf("could not get node: %v", err)
+	}
+
+	if !hasTaint(&node, constants.ModulesNotReadyTaint) {
+		return ctrl.Result{}, nil
+	}
+
+	mods := kmmv1beta1.ModuleList{}
+
+	if err := r.client.List(ctx, &mods); err != nil {
+		return ctrl.Result{}, fmt.Errorf("could not list Modules: %v", err)
+	}
+
+	if notReady := r.notReadyModules(&node, mods.Items); len(notReady) > 0 {
+		logger.Info("Keeping the startup taint until Modules are ready", "modules", notReady)
+		return ctrl.Result{}, nil
+	}
+
+	nodeCopy := node.DeepCopy()
+	taints := make([]v1.Taint, 0, len(node.Spec.Taints))
+
+	for _, t := range node.Spec.Taints {
+		if t.Key != constants.ModulesNotReadyTaint {
+			taints = append(taints, t)
+		}
+	}
+
+	node.Spec.Taints = taints
+
+	logger.Info("Removing the startup taint", "key", constants.ModulesNotReadyTaint)
+
+	if err := r.client.Patch(ctx, &node, client.MergeFrom(nodeCopy)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("could not patch the node: %v", err)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// notReadyModules returns the namespaced names of the Modules that target node, have a kernel mapping for it, and
+// whose module-loader is not ready on it.
+// Modules without a mapping for the node never run there, so they do not hold the taint.
+func (r *NodeStartupTaintReconciler) notReadyModules(node *v1.Node, mods []kmmv1beta1.Module) []string {
+	nodeLabels := labels.Set(node.Labels)
+	notReady := make([]string, 0)
+
+	for _, mod := range mods {
+		if mod.DeletionTimestamp != nil || !labels.SelectorFromSet(mod.Spec.Selector).Matches(nodeLabels) {
+			continue
+		}
+
+		if _, err := r.kernelAPI.FindMappingForNode(mod.Spec.ModuleLoader.Container.KernelMappings, node); err != nil {
+			continue
+		}
+
+		if _, ok := node.Labels[daemonset.ModuleReadyNodeLabel(mod.Name)]; !ok {
+			notReady = append(notReady, mod.Namespace+"/"+mod.Name)
+		}
+	}
+
+	return notReady
+}
+
+func hasTaint(node *v1.Node, key string) bool {
+	for _, t := range node.Spec.Taints {
+		if t.Key == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// Nodes are reconciled when they are created or their labels change, and all nodes when a Module changes.
+func (r *NodeStartupTaintReconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
+	return ctrl.
+		NewControllerManagedBy(mgr).
+		Named(NodeStartupTaintReconcilerName).
+		For(&v1.Node{}, builder.WithPredicates(predicate.LabelChangedPredicate{})).
+		Watches(
+			&source.Kind{Type: &kmmv1beta1.Module{}},
+			handler.EnqueueRequestsFromMapFunc(r.filter.EnqueueAllNodes),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		WithOptions(opts).
+		Complete(r)
+}
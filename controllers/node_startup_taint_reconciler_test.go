@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	mock_client "github.com/kubernetes-sigs/kernel-module-management/internal/client"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+)
+
+var _ = Describe("NodeStartupTaintReconciler", func() {
+	const nodeName = "worker-0"
+
+	var (
+		kubeClient *mock_client.MockClient
+		r          *NodeStartupTaintReconciler
+	)
+
+	BeforeEach(func() {
+		ctrl := gomock.NewController(GinkgoT())
+		kubeClient = mock_client.NewMockClient(ctrl)
+		r = NewNodeStartupTaintReconciler(kubeClient, module.NewKernelMapper(), nil)
+	})
+
+	ctx := context.Background()
+	nn := types.NamespacedName{Name: nodeName}
+	req := ctrl.Request{NamespacedName: nn}
+
+	startupTaint := v1.Taint{Key: constants.ModulesNotReadyTaint, Effect: v1.TaintEffectNoSchedule}
+	otherTaint := v1.Taint{Key: "other", Effect: v1.TaintEffectNoExecute}
+
+	mappedModule := func(name string, selector map[string]string, kernelRegexp string) kmmv1beta1.Module {
+		mod := kmmv1beta1.Module{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       kmmv1beta1.ModuleSpec{Selector: selector},
+		}
+
+		mod.Spec.ModuleLoader.Container.KernelMappings = []kmmv1beta1.KernelMapping{{Regexp: kernelRegexp}}
+
+		return mod
+	}
+
+	mods := []kmmv1beta1.Module{
+		mappedModule("vfio-pci", nil, "^.+$"),
+		mappedModule("kvm-intel", map[string]string{"cpu-vendor": "intel"}, "^.+$"),
+		mappedModule("old-kmod", nil, "^4\\..+$"),
+	}
+
+	expectGetNode := func(nodeLabels map[string]string, taints ...v1.Taint) *gomock.Call {
+		return kubeClient.
+			EXPECT().
+			Get(ctx, nn, gomock.AssignableToTypeOf(&v1.Node{})).
+			Do(func(_ context.Context, _ types.NamespacedName, node *v1.Node, _ ...client.GetOption) {
+				node.Name = nodeName
+				node.Labels = nodeLabels
+				node.Spec.Taints = taints
+				node.Status.NodeInfo.KernelVersion = "5.14.0-1"
+			}).
+			Return(nil)
+	}
+
+	expectList := func() *gomock.Call {
+		return kubeClient.
+			EXPECT().
+			List(ctx, gomock.AssignableToTypeOf(&kmmv1beta1.ModuleList{})).
+			DoAndReturn(func(_ interface{}, list *kmmv1beta1.ModuleList, _ ...interface{}) error {
+				list.Items = mods
+				return nil
+			})
+	}
+
+	It("should do nothing if the node does not have the startup taint", func() {
+		expectGetNode(nil, otherTaint)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should keep the taint while a Module targeting the node is not ready", func() {
+		gomock.InOrder(
+			expectGetNode(
+				map[string]string{
+					"cpu-vendor":                            "intel",
+					"kmm.node.kubernetes.io/vfio-pci.ready": "",
+				},
+				startupTaint,
+			),
+			expectList(),
+		)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should remove the taint once all the Modules targeting the node are ready", func() {
+		gomock.InOrder(
+			expectGetNode(
+				map[string]string{
+					"cpu-vendor":                             "intel",
+					"kmm.node.kubernetes.io/vfio-pci.ready":  "",
+					"kmm.node.kubernetes.io/kvm-intel.ready": "",
+				},
+				startupTaint,
+				otherTaint,
+			),
+			expectList(),
+			kubeClient.EXPECT().Patch(ctx, gomock.Any(), gomock.Any()).Do(
+				func(_ context.Context, node *v1.Node, _ client.Patch, _ ...client.PatchOption) {
+					Expect(node.Spec.Taints).To(Equal([]v1.Taint{otherTaint}))
+				},
+			),
+		)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should ignore the Modules that do not select the node or have no mapping for its kernel", func() {
+		gomock.InOrder(
+			expectGetNode(map[string]string{"kmm.node.kubernetes.io/vfio-pci.ready": ""}, startupTaint),
+			expectList(),
+			kubeClient.EXPECT().Patch(ctx, gomock.Any(), gomock.Any()).Do(
+				func(_ context.Context, node *v1.Node, _ client.Patch, _ ...client.PatchOption) {
+					Expect(node.Spec.Taints).To(BeEmpty())
+				},
+			),
+		)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
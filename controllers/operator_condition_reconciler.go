@@ -0,0 +1,197 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/filter"
+)
+
+const (
+	OperatorConditionReconcilerName = "OperatorCondition"
+
+	// OperatorConditionUpgradeable is the type of the OperatorCondition condition that OLM checks before upgrading
+	// the operator.
+	OperatorConditionUpgradeable = "Upgradeable"
+
+	operatorConditionReasonJobsInProgress   = "JobsInProgress"
+	operatorConditionReasonNoJobsInProgress = "NoJobsInProgress"
+)
+
+var operatorConditionGVK = schema.GroupVersionKind{
+	Group:   "operators.coreos.com",
+	Version: "v2",
+	Kind:    "OperatorCondition",
+}
+
+//+kubebuilder:rbac:groups=operators.coreos.com,resources=operatorconditions,verbs=get;list;patch;watch
+
+// OperatorConditionReconciler reports in the OperatorCondition that OLM created for the operator that the latter is
+// not upgradeable while build or sign Jobs are in progress, so that OLM does not restart it in the middle of them.
+// All Jobs are considered on each reconciliation, whichever Job triggered it.
+type OperatorConditionReconciler struct {
+	client client.Client
+	name   types.NamespacedName
+}
+
+// NewOperatorConditionReconciler returns an OperatorConditionReconciler updating the OperatorCondition name.
+func NewOperatorConditionReconciler(client client.Client, name types.NamespacedName) *OperatorConditionReconciler {
+	return &OperatorConditionReconciler{
+		client: client,
+		name:   name,
+	}
+}
+
+func (r *OperatorConditionReconciler) Reconcile(ctx context.Context, _ ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	jobs := batchv1.JobList{}
+
+	if err := r.client.List(ctx, &jobs, client.HasLabels{constants.JobType}); err != nil {
+		return ctrl.Result{}, fmt.Errorf("could not list build and sign Jobs: %v", err)
+	}
+
+	cond := upgradeableCondition(jobs.Items)
+
+	oc := &unstructured.Unstructured{}
+	oc.SetGroupVersionKind(operatorConditionGVK)
+
+	if err := r.client.Get(ctx, r.name, oc); err != nil {
+		return ctrl.Result{}, fmt.Errorf("could not get the OperatorCondition %s: %v", r.name, err)
+	}
+
+	rawConditions, _, err := unstructured.NestedSlice(oc.Object, "spec", "conditions")
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("could not read the conditions of the OperatorCondition: %v", err)
+	}
+
+	conditions := make([]metav1.Condition, len(rawConditions))
+
+	for i, rc := range rawConditions {
+		m, ok := rc.(map[string]interface{})
+		if !ok {
+			return ctrl.Result{}, fmt.Errorf("condition %d of the OperatorCondition is not an object", i)
+		}
+
+		if err = runtime.DefaultUnstructuredConverter.FromUnstructured(m, &conditions[i]); err != nil {
+			return ctrl.Result{}, fmt.Errorf("could not decode condition %d of the OperatorCondition: %v", i, err)
+		}
+	}
+
+	if existing := meta.FindStatusCondition(conditions, OperatorConditionUpgradeable); existing != nil &&
+		existing.Status == cond.Status &&
+		existing.Reason == cond.Reason &&
+		existing.Message == cond.Message {
+		return ctrl.Result{}, nil
+	}
+
+	meta.SetStatusCondition(&conditions, cond)
+
+	rawConditions = make([]interface{}, 0, len(conditions))
+
+	for _, c := range conditions {
+		m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&c)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("could not encode condition %s: %v", c.Type, err)
+		}
+
+		rawConditions = append(rawConditions, m)
+	}
+
+	p := client.MergeFrom(oc.DeepCopy())
+
+	if err = unstructured.SetNestedSlice(oc.Object, rawConditions, "spec", "conditions"); err != nil {
+		return ctrl.Result{}, fmt.Errorf("could not set the conditions of the OperatorCondition: %v", err)
+	}
+
+	logger.Info("Patching the OperatorCondition", "status", cond.Status, "reason", cond.Reason)
+
+	if err = r.client.Patch(ctx, oc, p); err != nil {
+		return ctrl.Result{}, fmt.Errorf("could not patch the OperatorCondition: %v", err)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// upgradeableCondition returns the Upgradeable condition matching jobs.
+func upgradeableCondition(jobs []batchv1.Job) metav1.Condition {
+	inProgress := 0
+
+	for _, j := range jobs {
+		if !jobFinished(&j) {
+			inProgress++
+		}
+	}
+
+	if inProgress > 0 {
+		return metav1.Condition{
+			Type:    OperatorConditionUpgradeable,
+			Status:  metav1.ConditionFalse,
+			Reason:  operatorConditionReasonJobsInProgress,
+			Message: fmt.Sprintf("%d build or sign Jobs in progress", inProgress),
+		}
+	}
+
+	return metav1.Condition{
+		Type:    OperatorConditionUpgradeable,
+		Status:  metav1.ConditionTrue,
+		Reason:  operatorConditionReasonNoJobsInProgress,
+		Message: "No build or sign Job in progress",
+	}
+}
+
+// jobFinished returns true if job completed or failed for good; a failed pod may still be retried otherwise.
+func jobFinished(job *batchv1.Job) bool {
+	for _, c := range job.Status.Conditions {
+		if (c.Type == batchv1.JobComplete || c.Type == batchv1.JobFailed) && c.Status == v1.ConditionTrue {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// Build and sign Jobs of all namespaces are watched, and reconciled one at a time as they all update the same
+// OperatorCondition.
+// The OperatorCondition is watched as well, so that it is updated when the operator starts even if no Job changed.
+func (r *OperatorConditionReconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
+	opts.MaxConcurrentReconciles = 1
+
+	oc := &unstructured.Unstructured{}
+	oc.SetGroupVersionKind(operatorConditionGVK)
+
+	isOperatorCondition := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetNamespace() == r.name.Namespace && obj.GetName() == r.name.Name
+	})
+
+	return ctrl.
+		NewControllerManagedBy(mgr).
+		Named(OperatorConditionReconcilerName).
+		For(&batchv1.Job{}, builder.WithPredicates(filter.HasLabel(constants.JobType))).
+		Watches(
+			&source.Kind{Type: oc},
+			&handler.EnqueueRequestForObject{},
+			builder.WithPredicates(isOperatorCondition, predicate.GenerationChangedPredicate{}),
+		).
+		WithOptions(opts).
+		Complete(r)
+}
@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mock_client "github.com/kubernetes-sigs/kernel-module-management/internal/client"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+)
+
+var _ = Describe("OperatorConditionReconciler", func() {
+	var (
+		kubeClient *mock_client.MockClient
+		r          *OperatorConditionReconciler
+	)
+
+	nn := types.NamespacedName{Namespace: "kmm-operator-system", Name: "kernel-module-management.v1.0.0"}
+
+	BeforeEach(func() {
+		ctrl := gomock.NewController(GinkgoT())
+		kubeClient = mock_client.NewMockClient(ctrl)
+		r = NewOperatorConditionReconciler(kubeClient, nn)
+	})
+
+	ctx := context.Background()
+
+	finishedJob := batchv1.Job{
+		Status: batchv1.JobStatus{
+			Conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobComplete, Status: v1.ConditionTrue},
+			},
+		},
+	}
+
+	expectList := func(jobs ...batchv1.Job) *gomock.Call {
+		return kubeClient.
+			EXPECT().
+			List(ctx, gomock.AssignableToTypeOf(&batchv1.JobList{}), client.HasLabels{constants.JobType}).
+			DoAndReturn(func(_ interface{}, list *batchv1.JobList, _ ...interface{}) error {
+				list.Items = jobs
+				return nil
+			})
+	}
+
+	expectGet := func(conditions ...metav1.Condition) *gomock.Call {
+		return kubeClient.
+			EXPECT().
+			Get(ctx, nn, gomock.AssignableToTypeOf(&unstructured.Unstructured{})).
+			Do(func(_ context.Context, _ types.NamespacedName, oc *unstructured.Unstructured, _ ...client.GetOption) {
+				raw := make([]interface{}, 0, len(conditions))
+
+				for _, c := range conditions {
+					m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&c)
+					Expect(err).NotTo(HaveOccurred())
+
+					raw = append(raw, m)
+				}
+
+				Expect(unstructured.SetNestedSlice(oc.Object, raw, "spec", "conditions")).To(Succeed())
+			}).
+			Return(nil)
+	}
+
+	It("should not be upgradeable while a Job is in progress", func() {
+		gomock.InOrder(
+			expectList(finishedJob, batchv1.Job{}),
+			expectGet(),
+			kubeClient.
+				EXPECT().
+				Patch(ctx, gomock.AssignableToTypeOf(&unstructured.Unstructured{}), gomock.Any()).
+				Do(func(_ context.Context, oc *unstructured.Unstructured, _ client.Patch, _ ...client.PatchOption) {
+					raw, _, err := unstructured.NestedSlice(oc.Object, "spec", "conditions")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(raw).To(HaveLen(1))
+
+					cond := metav1.Condition{}
+					Expect(
+						runtime.DefaultUnstructuredConverter.FromUnstructured(raw[0].(map[string]interface{}), &cond),
+					).To(
+						Succeed(),
+					)
+					Expect(cond.Type).To(Equal(OperatorConditionUpgradeable))
+					Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+					Expect(cond.Message).To(Equal("1 build or sign Jobs in progress"))
+				}),
+		)
+
+		Expect(r.Reconcile(ctx, ctrl.Request{})).To(Equal(ctrl.Result{}))
+	})
+
+	It("should not patch the OperatorCondition if the condition did not change", func() {
+		gomock.InOrder(
+			expectList(finishedJob),
+			expectGet(metav1.Condition{
+				Type:    OperatorConditionUpgradeable,
+				Status:  metav1.ConditionTrue,
+				Reason:  "NoJobsInProgress",
+				Message: "No build or sign Job in progress",
+			}),
+		)
+
+		Expect(r.Reconcile(ctx, ctrl.Request{})).To(Equal(ctrl.Result{}))
+	})
+
+	It("should keep the other conditions", func() {
+		other := metav1.Condition{Type: "Other", Status: metav1.ConditionTrue, Reason: "SomeReason"}
+
+		gomock.InOrder(
+			expectList(),
+			expectGet(other),
+			kubeClient.
+				EXPECT().
+				Patch(ctx, gomock.AssignableToTypeOf(&unstructured.Unstructured{}), gomock.Any()).
+				Do(func(_ context.Context, oc *unstructured.Unstructured, _ client.Patch, _ ...client.PatchOption) {
+					raw, _, err := unstructured.NestedSlice(oc.Object, "spec", "conditions")
+					Expect(err).NotTo(HaveOccurred())
+
+					conditions := make([]metav1.Condition, len(raw))
+
+					for i := range raw {
+						Expect(
+							runtime.DefaultUnstructuredConverter.FromUnstructured(raw[i].(map[string]interface{}), &conditions[i]),
+						).To(
+							Succeed(),
+						)
+					}
+
+					Expect(meta.IsStatusConditionTrue(conditions, "Other")).To(BeTrue())
+					Expect(meta.IsStatusConditionTrue(conditions, OperatorConditionUpgradeable)).To(BeTrue())
+				}),
+		)
+
+		Expect(r.Reconcile(ctx, ctrl.Request{})).To(Equal(ctrl.Result{}))
+	})
+
+	It("should return an error if the OperatorCondition could not be fetched", func() {
+		gomock.InOrder(
+			expectList(),
+			kubeClient.EXPECT().Get(ctx, nn, gomock.Any()).Return(errors.New("some error")),
+		)
+
+		_, err := r.Reconcile(ctx, ctrl.Request{})
+		Expect(err).To(HaveOccurred())
+	})
+})
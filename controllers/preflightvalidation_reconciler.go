@@ -19,11 +19,14 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	v1beta12 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
 	batchv1 "k8s.io/api/batch/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -35,6 +38,7 @@ import (
 
 	"github.com/kubernetes-sigs/kernel-module-management/internal/filter"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/preflight"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/shard"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/statusupdater"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/utils"
 )
@@ -65,19 +69,25 @@ func NewPreflightValidationReconciler(
 		preflight:     preflight}
 }
 
-func (r *PreflightValidationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+// SetupWithManager sets up the controller with the Manager.
+// Only the PreflightValidations in the namespaces of s are reconciled.
+func (r *PreflightValidationReconciler) SetupWithManager(mgr ctrl.Manager, s *shard.Shard, opts controller.Options) error {
+	// PreflightValidations are always processed one at a time.
+	opts.MaxConcurrentReconciles = 1
+
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(PreflightValidationReconcilerName).
-		For(&v1beta12.PreflightValidation{}, builder.WithPredicates(filter.PreflightReconcilerUpdatePredicate())).
-		Owns(&batchv1.Job{}).
+		For(
+			&v1beta12.PreflightValidation{},
+			builder.WithPredicates(filter.PreflightReconcilerUpdatePredicate(), s.Predicate()),
+		).
+		Owns(&batchv1.Job{}, builder.WithPredicates(s.Predicate())).
 		Watches(
 			&source.Kind{Type: &v1beta12.Module{}},
-			handler.EnqueueRequestsFromMapFunc(r.filter.EnqueueAllPreflightValidations),
+			handler.EnqueueRequestsFromMapFunc(s.MapFunc(r.filter.EnqueueAllPreflightValidations)),
 			builder.WithPredicates(filter.PreflightReconcilerUpdatePredicate()),
 		).
-		WithOptions(controller.Options{
-			MaxConcurrentReconciles: 1,
-		}).
+		WithOptions(opts).
 		Complete(r)
 }
 
@@ -212,12 +222,44 @@ func (r *PreflightValidationReconciler) checkPreflightCompletion(ctx context.Con
 		return false, fmt.Errorf("failed to get preflight validation object in checkPreflightCompletion: %w", err)
 	}
 
+	notVerified := make([]string, 0)
+
 	for modName, crStatus := range pv.Status.CRStatuses {
 		if crStatus.VerificationStatus != v1beta12.VerificationTrue {
-			ctrl.LoggerFrom(ctx).Info("at least one Module is not verified yet", "module", modName, "status", crStatus.VerificationStatus)
-			return false, nil
+			notVerified = append(notVerified, modName)
 		}
 	}
 
+	sort.Strings(notVerified)
+
+	if err = r.statusUpdater.PreflightSetCondition(ctx, &pv, readyCondition(notVerified)); err != nil {
+		return false, fmt.Errorf("failed to set the %s condition: %w", v1beta12.PreflightValidationConditionReady, err)
+	}
+
+	if len(notVerified) > 0 {
+		ctrl.LoggerFrom(ctx).Info("at least one Module is not verified yet", "modules", notVerified)
+		return false, nil
+	}
+
 	return true, nil
 }
+
+// readyCondition returns the Ready condition of a PreflightValidation given the names of the Modules that are not
+// verified yet.
+func readyCondition(notVerified []string) metav1.Condition {
+	if len(notVerified) > 0 {
+		return metav1.Condition{
+			Type:    v1beta12.PreflightValidationConditionReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  "NotVerified",
+			Message: "Modules not verified yet: " + strings.Join(notVerified, ", "),
+		}
+	}
+
+	return metav1.Condition{
+		Type:    v1beta12.PreflightValidationConditionReady,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Verified",
+		Message: "All Modules are verified",
+	}
+}
@@ -112,6 +112,7 @@ var _ = Describe("PreflightValidationReconciler_Reconcile", func() {
 					return nil
 				},
 			),
+			mockSU.EXPECT().PreflightSetCondition(ctx, gomock.Any(), readyCondition([]string{})).Return(nil),
 		)
 
 		res, err := pr.Reconcile(ctx, req)
@@ -163,6 +164,7 @@ var _ = Describe("PreflightValidationReconciler_Reconcile", func() {
 					return nil
 				},
 			),
+			mockSU.EXPECT().PreflightSetCondition(ctx, gomock.Any(), readyCondition([]string{mod.Name})).Return(nil),
 		)
 
 		res, err := pr.Reconcile(ctx, req)
@@ -424,6 +426,12 @@ var _ = Describe("PreflightValidationReconciler_checkPreflightCompletion", func(
 				return nil
 			},
 		)
+		mockSU.EXPECT().PreflightSetCondition(context.Background(), gomock.Any(), gomock.Any()).Do(
+			func(_ context.Context, _ *v1beta12.PreflightValidation, cond metav1.Condition) {
+				Expect(cond.Type).To(Equal(v1beta12.PreflightValidationConditionReady))
+				Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+			},
+		)
 		res, err := pr.checkPreflightCompletion(context.Background(), nsn.Name, nsn.Namespace)
 		Expect(err).ToNot(HaveOccurred())
 		Expect(res).To(BeTrue())
@@ -442,6 +450,12 @@ var _ = Describe("PreflightValidationReconciler_checkPreflightCompletion", func(
 				return nil
 			},
 		)
+		mockSU.EXPECT().PreflightSetCondition(context.Background(), gomock.Any(), gomock.Any()).Do(
+			func(_ context.Context, _ *v1beta12.PreflightValidation, cond metav1.Condition) {
+				Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+				Expect(cond.Message).To(Equal("Modules not verified yet: module2"))
+			},
+		)
 		res, err := pr.checkPreflightCompletion(context.Background(), nsn.Name, nsn.Namespace)
 		Expect(err).ToNot(HaveOccurred())
 		Expect(res).To(BeFalse())
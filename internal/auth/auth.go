@@ -16,6 +16,9 @@ import (
 
 type RegistryAuthGetter interface {
 	GetKeyChain(ctx context.Context) (authn.Keychain, error)
+	// Identity returns a string that is only shared by the RegistryAuthGetters using the same credentials, so that
+	// what is learnt with some credentials is not reused with others.
+	Identity() string
 }
 
 type registrySecretAuthGetter struct {
@@ -45,6 +48,10 @@ func (rsag *registrySecretAuthGetter) GetKeyChain(ctx context.Context) (authn.Ke
 	return keychain, nil
 }
 
+func (rsag *registrySecretAuthGetter) Identity() string {
+	return "secret:" + rsag.namespacedName.String()
+}
+
 func NewRegistryAuthGetterFrom(client client.Client, mod *kmmv1beta1.Module) RegistryAuthGetter {
 	if mod.Spec.ImageRepoSecret != nil {
 		namespacedName := types.NamespacedName{
@@ -53,5 +60,8 @@ func NewRegistryAuthGetterFrom(client client.Client, mod *kmmv1beta1.Module) Reg
 		}
 		return NewRegistryAuthGetter(client, namespacedName)
 	}
+	if mod.Spec.WorkloadIdentity != nil {
+		return NewModuleWorkloadIdentityAuthGetter(mod.Namespace)
+	}
 	return nil
 }
@@ -49,3 +49,17 @@ func (mr *MockRegistryAuthGetterMockRecorder) GetKeyChain(ctx interface{}) *gomo
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeyChain", reflect.TypeOf((*MockRegistryAuthGetter)(nil).GetKeyChain), ctx)
 }
+
+// Identity mocks base method.
+func (m *MockRegistryAuthGetter) Identity() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Identity")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Identity indicates an expected call of Identity.
+func (mr *MockRegistryAuthGetterMockRecorder) Identity() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Identity", reflect.TypeOf((*MockRegistryAuthGetter)(nil).Identity))
+}
@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+)
+
+const (
+	awsRoleARNAnnotation        = "eks.amazonaws.com/role-arn"
+	azureClientIDAnnotation     = "azure.workload.identity/client-id"
+	azureUseLabel               = "azure.workload.identity/use"
+	gcpServiceAccountAnnotation = "iam.gke.io/gcp-service-account"
+)
+
+// cloudProviderKeychain authenticates to ECR, ACR and GCR / Artifact Registry through the docker credential helpers
+// shipped in the operator's image, like the kubelet does with its credential providers.
+// Registries of other providers are accessed anonymously.
+var cloudProviderKeychain = authn.NewKeychainFromHelper(&credentialHelper{run: runCredentialHelper})
+
+// workloadIdentityNamespaces holds the namespaces whose Modules may use the workload identity of the process.
+// It is nil, allowing all namespaces, until RestrictWorkloadIdentities is called.
+var workloadIdentityNamespaces sets.String
+
+// RestrictWorkloadIdentities only lets the Modules of namespaces use the workload identity of the process through
+// NewModuleWorkloadIdentityAuthGetter.
+// The operator calls it at startup, so that tenants cannot access registries with its identity.
+func RestrictWorkloadIdentities(namespaces ...string) {
+	workloadIdentityNamespaces = sets.NewString(namespaces...)
+}
+
+type keychainAuthGetter struct {
+	keychain authn.Keychain
+}
+
+// NewWorkloadIdentityAuthGetter returns a RegistryAuthGetter authenticating to ECR, ACR and GCR / Artifact Registry
+// with the workload identity of the operator, through the docker credential helpers shipped in its image.
+// Registries of other providers are accessed anonymously.
+func NewWorkloadIdentityAuthGetter() RegistryAuthGetter {
+	return &keychainAuthGetter{keychain: cloudProviderKeychain}
+}
+
+// NewModuleWorkloadIdentityAuthGetter returns the RegistryAuthGetter of a Module of namespace that sets a workload
+// identity.
+// Unless namespace was trusted with RestrictWorkloadIdentities, its GetKeyChain method returns an error.
+func NewModuleWorkloadIdentityAuthGetter(namespace string) RegistryAuthGetter {
+	if workloadIdentityNamespaces != nil && !workloadIdentityNamespaces.Has(namespace) {
+		return &untrustedNamespaceAuthGetter{namespace: namespace}
+	}
+
+	return NewWorkloadIdentityAuthGetter()
+}
+
+func (kag *keychainAuthGetter) GetKeyChain(_ context.Context) (authn.Keychain, error) {
+	return kag.keychain, nil
+}
+
+func (kag *keychainAuthGetter) Identity() string {
+	return "workload-identity"
+}
+
+type untrustedNamespaceAuthGetter struct {
+	namespace string
+}
+
+func (unag *untrustedNamespaceAuthGetter) GetKeyChain(_ context.Context) (authn.Keychain, error) {
+	return nil, fmt.Errorf("namespace %s is not allowed to use the operator's workload identity", unag.namespace)
+}
+
+func (unag *untrustedNamespaceAuthGetter) Identity() string {
+	return "untrusted:" + unag.namespace
+}
+
+// credentialHelper implements authn.Helper by calling the docker credential helper matching the registry.
+type credentialHelper struct {
+	run func(helper, serverURL string) ([]byte, error)
+}
+
+func (ch *credentialHelper) Get(serverURL string) (string, string, error) {
+	helper := credentialHelperName(serverURL)
+	if helper == "" {
+		// authn falls back to anonymous access on errors
+		return "", "", fmt.Errorf("no credential helper for %s", serverURL)
+	}
+
+	out, err := ch.run(helper, serverURL)
+	if err != nil {
+		return "", "", fmt.Errorf("could not get the credentials for %s from %s: %v", serverURL, helper, err)
+	}
+
+	creds := struct {
+		Username string
+		Secret   string
+	}{}
+
+	if err = json.Unmarshal(out, &creds); err != nil {
+		return "", "", fmt.Errorf("could not decode the output of %s: %v", helper, err)
+	}
+
+	return creds.Username, creds.Secret, nil
+}
+
+// credentialHelperName returns the name of the docker credential helper for the registry, or an empty string if the
+// registry does not belong to a supported cloud provider.
+func credentialHelperName(serverURL string) string {
+	host := strings.SplitN(serverURL, "/", 2)[0]
+
+	switch {
+	case strings.Contains(host, ".dkr.ecr.") && strings.HasSuffix(host, ".amazonaws.com"):
+		return "docker-credential-ecr-login"
+	case strings.HasSuffix(host, ".azurecr.io"):
+		return "docker-credential-acr-env"
+	case host == "gcr.io" || strings.HasSuffix(host, ".gcr.io") || strings.HasSuffix(host, "-docker.pkg.dev"):
+		return "docker-credential-gcr"
+	default:
+		return ""
+	}
+}
+
+func runCredentialHelper(helper, serverURL string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command(helper, "get")
+	cmd.Stdin = strings.NewReader(serverURL)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// WorkloadIdentityServiceAccountAnnotations returns the annotations binding a ServiceAccount to wi.
+func WorkloadIdentityServiceAccountAnnotations(wi *kmmv1beta1.WorkloadIdentity) map[string]string {
+	if wi == nil {
+		return nil
+	}
+
+	switch wi.Provider {
+	case kmmv1beta1.WorkloadIdentityProviderAWS:
+		return map[string]string{awsRoleARNAnnotation: wi.Identity}
+	case kmmv1beta1.WorkloadIdentityProviderAzure:
+		return map[string]string{azureClientIDAnnotation: wi.Identity}
+	case kmmv1beta1.WorkloadIdentityProviderGCP:
+		return map[string]string{gcpServiceAccountAnnotation: wi.Identity}
+	default:
+		return nil
+	}
+}
+
+// WorkloadIdentityPodLabels returns the labels of a pod using wi: labels, plus those required by wi's provider.
+// labels is never modified.
+func WorkloadIdentityPodLabels(wi *kmmv1beta1.WorkloadIdentity, labels map[string]string) map[string]string {
+	if wi == nil || wi.Provider != kmmv1beta1.WorkloadIdentityProviderAzure {
+		return labels
+	}
+
+	podLabels := make(map[string]string, len(labels)+1)
+
+	for k, v := range labels {
+		podLabels[k] = v
+	}
+
+	podLabels[azureUseLabel] = "true"
+
+	return podLabels
+}
@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+)
+
+var _ = Describe("credentialHelperName", func() {
+	DescribeTable("should return the right credential helper",
+		func(serverURL, expected string) {
+			Expect(credentialHelperName(serverURL)).To(Equal(expected))
+		},
+		Entry("ECR", "123456789012.dkr.ecr.eu-west-1.amazonaws.com", "docker-credential-ecr-login"),
+		Entry("ACR", "example.azurecr.io", "docker-credential-acr-env"),
+		Entry("GCR", "gcr.io", "docker-credential-gcr"),
+		Entry("regional GCR", "eu.gcr.io", "docker-credential-gcr"),
+		Entry("Artifact Registry", "europe-west1-docker.pkg.dev", "docker-credential-gcr"),
+		Entry("other registry", "quay.io", ""),
+		Entry("lookalike registry", "gcr.io.example.com", ""),
+	)
+})
+
+var _ = Describe("credentialHelper", func() {
+	It("should return an error for registries without a credential helper", func() {
+		ch := credentialHelper{
+			run: func(_, _ string) ([]byte, error) {
+				Fail("no credential helper should be run")
+				return nil, nil
+			},
+		}
+
+		_, _, err := ch.Get("quay.io")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return an error if the credential helper fails", func() {
+		ch := credentialHelper{
+			run: func(_, _ string) ([]byte, error) {
+				return nil, errors.New("some error")
+			},
+		}
+
+		_, _, err := ch.Get("gcr.io")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return the credentials printed by the credential helper", func() {
+		ch := credentialHelper{
+			run: func(helper, serverURL string) ([]byte, error) {
+				Expect(helper).To(Equal("docker-credential-acr-env"))
+				Expect(serverURL).To(Equal("example.azurecr.io"))
+
+				return []byte(`{"ServerURL":"example.azurecr.io","Username":"<token>","Secret":"some-token"}`), nil
+			},
+		}
+
+		username, secret, err := ch.Get("example.azurecr.io")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(username).To(Equal("<token>"))
+		Expect(secret).To(Equal("some-token"))
+	})
+})
+
+var _ = Describe("NewModuleWorkloadIdentityAuthGetter", func() {
+	AfterEach(func() {
+		workloadIdentityNamespaces = nil
+	})
+
+	It("should allow all namespaces if workload identities are not restricted", func() {
+		_, err := NewModuleWorkloadIdentityAuthGetter("some-namespace").GetKeyChain(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should only allow the trusted namespaces", func() {
+		RestrictWorkloadIdentities("trusted")
+
+		_, err := NewModuleWorkloadIdentityAuthGetter("trusted").GetKeyChain(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = NewModuleWorkloadIdentityAuthGetter("tenant").GetKeyChain(context.Background())
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should allow no namespace if the trusted namespaces are empty", func() {
+		RestrictWorkloadIdentities()
+
+		_, err := NewModuleWorkloadIdentityAuthGetter("some-namespace").GetKeyChain(context.Background())
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("WorkloadIdentityServiceAccountAnnotations", func() {
+	It("should return nothing without a workload identity", func() {
+		Expect(WorkloadIdentityServiceAccountAnnotations(nil)).To(BeEmpty())
+	})
+
+	DescribeTable("should return the provider's annotation",
+		func(provider kmmv1beta1.WorkloadIdentityProvider, annotation string) {
+			wi := &kmmv1beta1.WorkloadIdentity{Provider: provider, Identity: "some-identity"}
+
+			Expect(
+				WorkloadIdentityServiceAccountAnnotations(wi),
+			).To(
+				Equal(map[string]string{annotation: "some-identity"}),
+			)
+		},
+		Entry("AWS", kmmv1beta1.WorkloadIdentityProviderAWS, "eks.amazonaws.com/role-arn"),
+		Entry("Azure", kmmv1beta1.WorkloadIdentityProviderAzure, "azure.workload.identity/client-id"),
+		Entry("GCP", kmmv1beta1.WorkloadIdentityProviderGCP, "iam.gke.io/gcp-service-account"),
+	)
+})
+
+var _ = Describe("WorkloadIdentityPodLabels", func() {
+	labels := map[string]string{"key": "value"}
+
+	It("should return the labels unchanged for other providers than Azure", func() {
+		wi := &kmmv1beta1.WorkloadIdentity{Provider: kmmv1beta1.WorkloadIdentityProviderGCP}
+
+		Expect(WorkloadIdentityPodLabels(nil, labels)).To(Equal(labels))
+		Expect(WorkloadIdentityPodLabels(wi, labels)).To(Equal(labels))
+	})
+
+	It("should add the Azure label without modifying the labels", func() {
+		wi := &kmmv1beta1.WorkloadIdentity{Provider: kmmv1beta1.WorkloadIdentityProviderAzure}
+
+		Expect(
+			WorkloadIdentityPodLabels(wi, labels),
+		).To(
+			Equal(map[string]string{"key": "value", "azure.workload.identity/use": "true"}),
+		)
+		Expect(labels).To(HaveLen(1))
+	})
+})
@@ -16,9 +16,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/auth"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/build"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/rbac"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/utils"
 )
 
@@ -81,15 +83,27 @@ func (m *maker) MakeJobTemplate(
 		containerImage = module.IntermediateImageName(mod.Name, mod.Namespace, containerImage)
 	}
 
+	nodeSelector, err := module.JobNodeSelector(ctx, m.client, mod.Spec.Selector, targetKernel)
+	if err != nil {
+		return nil, err
+	}
+
 	registryTLS := module.TLSOptions(mod.Spec, km)
 	specTemplate := m.specTemplate(
 		mod.Spec,
 		buildConfig,
+		nodeSelector,
 		targetKernel,
 		containerImage,
 		registryTLS,
 		pushImage)
 
+	labels := m.jobHelper.JobLabels(mod.Name, targetKernel, utils.JobTypeBuild)
+
+	// The Pod is labeled like the Job, so that it is selected by the build and sign NetworkPolicy.
+	specTemplate.Labels = auth.WorkloadIdentityPodLabels(mod.Spec.WorkloadIdentity, labels)
+	specTemplate.Spec.ServiceAccountName = rbac.GenerateBuildServiceAccountName(mod)
+
 	specTemplateHash, err := m.getHashAnnotationValue(ctx, buildConfig.DockerfileConfigMap.Name, mod.Namespace, &specTemplate)
 	if err != nil {
 		return nil, fmt.Errorf("could not hash job's definitions: %v", err)
@@ -99,7 +113,7 @@ func (m *maker) MakeJobTemplate(
 		ObjectMeta: metav1.ObjectMeta{
 			GenerateName: mod.Name + "-build-",
 			Namespace:    mod.Namespace,
-			Labels:       m.jobHelper.JobLabels(mod.Name, targetKernel, utils.JobTypeBuild),
+			Labels:       labels,
 			Annotations:  map[string]string{constants.JobHashAnnotation: fmt.Sprintf("%d", specTemplateHash)},
 		},
 		Spec: batchv1.JobSpec{
@@ -118,6 +132,7 @@ func (m *maker) MakeJobTemplate(
 func (m *maker) specTemplate(
 	modSpec kmmv1beta1.ModuleSpec,
 	buildConfig *kmmv1beta1.Build,
+	nodeSelector map[string]string,
 	targetKernel string,
 	containerImage string,
 	registryTLS *kmmv1beta1.TLSOptions,
@@ -138,7 +153,7 @@ func (m *maker) specTemplate(
 					VolumeMounts: volumeMounts(modSpec, buildConfig),
 				},
 			},
-			NodeSelector:  modSpec.Selector,
+			NodeSelector:  nodeSelector,
 			RestartPolicy: v1.RestartPolicyOnFailure,
 			Volumes:       volumes(modSpec, buildConfig),
 		},
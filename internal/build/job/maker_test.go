@@ -103,6 +103,7 @@ var _ = Describe("MakeJobTemplate", func() {
 			Spec: batchv1.JobSpec{
 				Completions: pointer.Int32(1),
 				Template: v1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
 					Spec: v1.PodSpec{
 						Containers: []v1.Container{
 							{
@@ -122,8 +123,9 @@ var _ = Describe("MakeJobTemplate", func() {
 								},
 							},
 						},
-						NodeSelector:  nodeSelector,
-						RestartPolicy: v1.RestartPolicyOnFailure,
+						NodeSelector:       nodeSelector,
+						RestartPolicy:      v1.RestartPolicyOnFailure,
+						ServiceAccountName: moduleName + "-build",
 						Volumes: []v1.Volume{
 							{
 								Name: "dockerfile",
@@ -210,16 +212,17 @@ var _ = Describe("MakeJobTemplate", func() {
 		mod.Spec.Selector = nodeSelector
 
 		override := kmmv1beta1.BuildArg{Name: "KERNEL_VERSION", Value: kernelVersion}
+		clnt.EXPECT().List(ctx, &v1.NodeList{}, gomock.Any())
 		gomock.InOrder(
 			mh.EXPECT().GetRelevantBuild(mod.Spec, km).Return(km.Build),
 			mh.EXPECT().ApplyBuildArgOverrides(buildArgs, override).Return(append(slices.Clone(buildArgs), override)),
+			jobhelper.EXPECT().JobLabels(mod.Name, kernelVersion, utils.JobTypeBuild).Return(labels),
 			clnt.EXPECT().Get(ctx, types.NamespacedName{Name: dockerfileConfigMap.Name, Namespace: mod.Namespace}, gomock.Any()).DoAndReturn(
 				func(_ interface{}, _ interface{}, cm *v1.ConfigMap, _ ...ctrlclient.GetOption) error {
 					cm.Data = dockerfileCMData
 					return nil
 				},
 			),
-			jobhelper.EXPECT().JobLabels(mod.Name, kernelVersion, utils.JobTypeBuild).Return(labels),
 		)
 
 		actual, err := m.MakeJobTemplate(ctx, *mod, km, kernelVersion, mod, true)
@@ -262,16 +265,17 @@ var _ = Describe("MakeJobTemplate", func() {
 			RegistryTLS:    tls,
 		}
 
+		clnt.EXPECT().List(ctx, &v1.NodeList{}, gomock.Any())
 		gomock.InOrder(
 			mh.EXPECT().GetRelevantBuild(mod.Spec, km).Return(km.Build),
 			mh.EXPECT().ApplyBuildArgOverrides(nil, kmmv1beta1.BuildArg{Name: "KERNEL_VERSION", Value: kernelVersion}),
+			jobhelper.EXPECT().JobLabels(mod.Name, kernelVersion, utils.JobTypeBuild).Return(map[string]string{}),
 			clnt.EXPECT().Get(ctx, types.NamespacedName{Name: dockerfileConfigMap.Name, Namespace: mod.Namespace}, gomock.Any()).DoAndReturn(
 				func(_ interface{}, _ interface{}, cm *v1.ConfigMap, _ ...ctrlclient.GetOption) error {
 					cm.Data = dockerfileCMData
 					return nil
 				},
 			),
-			jobhelper.EXPECT().JobLabels(mod.Name, kernelVersion, utils.JobTypeBuild).Return(map[string]string{}),
 		)
 
 		actual, err := m.MakeJobTemplate(ctx, mod, km, kernelVersion, &mod, pushImage)
@@ -335,16 +339,17 @@ var _ = Describe("MakeJobTemplate", func() {
 		}
 
 		override := kmmv1beta1.BuildArg{Name: "KERNEL_VERSION", Value: kernelVersion}
+		clnt.EXPECT().List(ctx, &v1.NodeList{}, gomock.Any())
 		gomock.InOrder(
 			mh.EXPECT().GetRelevantBuild(mod.Spec, km).Return(km.Build),
 			mh.EXPECT().ApplyBuildArgOverrides(buildArgs, override),
+			jobhelper.EXPECT().JobLabels(mod.Name, kernelVersion, utils.JobTypeBuild).Return(map[string]string{}),
 			clnt.EXPECT().Get(ctx, types.NamespacedName{Name: dockerfileConfigMap.Name, Namespace: mod.Namespace}, gomock.Any()).DoAndReturn(
 				func(_ interface{}, _ interface{}, cm *v1.ConfigMap, _ ...ctrlclient.GetOption) error {
 					cm.Data = dockerfileCMData
 					return nil
 				},
 			),
-			jobhelper.EXPECT().JobLabels(mod.Name, kernelVersion, utils.JobTypeBuild).Return(map[string]string{}),
 		)
 
 		actual, err := m.MakeJobTemplate(ctx, mod, km, kernelVersion, &mod, false)
@@ -368,16 +373,17 @@ var _ = Describe("MakeJobTemplate", func() {
 
 		expectedImageName := km.ContainerImage + ":" + mod.Namespace + "_" + mod.Name + "_kmm_unsigned"
 
+		clnt.EXPECT().List(ctx, &v1.NodeList{}, gomock.Any())
 		gomock.InOrder(
 			mh.EXPECT().GetRelevantBuild(mod.Spec, km).Return(km.Build),
 			mh.EXPECT().ApplyBuildArgOverrides(buildArgs, override),
+			jobhelper.EXPECT().JobLabels(mod.Name, kernelVersion, utils.JobTypeBuild).Return(map[string]string{}),
 			clnt.EXPECT().Get(ctx, types.NamespacedName{Name: dockerfileConfigMap.Name, Namespace: mod.Namespace}, gomock.Any()).DoAndReturn(
 				func(_ interface{}, _ interface{}, cm *v1.ConfigMap, _ ...ctrlclient.GetOption) error {
 					cm.Data = dockerfileCMData
 					return nil
 				},
 			),
-			jobhelper.EXPECT().JobLabels(mod.Name, kernelVersion, utils.JobTypeBuild).Return(map[string]string{}),
 		)
 
 		actual, err := m.MakeJobTemplate(ctx, mod, km, kernelVersion, &mod, true)
@@ -386,4 +392,59 @@ var _ = Describe("MakeJobTemplate", func() {
 		Expect(actual.Spec.Template.Spec.Containers[0].Args).To(ContainElement("--destination"))
 		Expect(actual.Spec.Template.Spec.Containers[0].Args).To(ContainElement(expectedImageName))
 	})
+	It("should schedule the Job on nodes of the kernel's architecture", func() {
+		const s390xKernel = "4.18.0-372.el8.s390x"
+
+		ctx := context.Background()
+
+		mod := mod.DeepCopy()
+		mod.Spec.Selector = map[string]string{"zone": "z1"}
+
+		km := kmmv1beta1.KernelMapping{
+			Build: &kmmv1beta1.Build{
+				BuildArgs:           buildArgs,
+				DockerfileConfigMap: &dockerfileConfigMap,
+			},
+			ContainerImage: image,
+		}
+
+		override := kmmv1beta1.BuildArg{Name: "KERNEL_VERSION", Value: s390xKernel}
+		clnt.EXPECT().List(ctx, &v1.NodeList{}, ctrlclient.MatchingLabels(mod.Spec.Selector)).DoAndReturn(
+			func(_ interface{}, nl *v1.NodeList, _ ...ctrlclient.ListOption) error {
+				nl.Items = []v1.Node{
+					{
+						Status: v1.NodeStatus{
+							NodeInfo: v1.NodeSystemInfo{KernelVersion: s390xKernel, Architecture: "s390x"},
+						},
+					},
+					{
+						Status: v1.NodeStatus{
+							NodeInfo: v1.NodeSystemInfo{KernelVersion: kernelVersion, Architecture: "amd64"},
+						},
+					},
+				}
+				return nil
+			},
+		)
+		gomock.InOrder(
+			mh.EXPECT().GetRelevantBuild(mod.Spec, km).Return(km.Build),
+			mh.EXPECT().ApplyBuildArgOverrides(buildArgs, override),
+			jobhelper.EXPECT().JobLabels(mod.Name, s390xKernel, utils.JobTypeBuild).Return(map[string]string{}),
+			clnt.EXPECT().Get(ctx, types.NamespacedName{Name: dockerfileConfigMap.Name, Namespace: mod.Namespace}, gomock.Any()).DoAndReturn(
+				func(_ interface{}, _ interface{}, cm *v1.ConfigMap, _ ...ctrlclient.GetOption) error {
+					cm.Data = dockerfileCMData
+					return nil
+				},
+			),
+		)
+
+		actual, err := m.MakeJobTemplate(ctx, *mod, km, s390xKernel, mod, true)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actual.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{
+			"zone":               "z1",
+			"kubernetes.io/arch": "s390x",
+		}))
+		Expect(mod.Spec.Selector).To(HaveLen(1))
+	})
 })
@@ -138,7 +138,7 @@ func (jbm *jobManager) Sync(
 	case job.Status.Active == 1:
 		return build.Result{Status: build.StatusInProgress, Requeue: true}, nil
 	case job.Status.Failed == 1:
-		return build.Result{}, fmt.Errorf("job failed: %v", err)
+		return build.Result{}, &utils.JobFailedError{JobName: job.Name}
 	default:
 		return build.Result{}, fmt.Errorf("unknown status: %v", job.Status)
 	}
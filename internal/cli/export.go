@@ -0,0 +1,331 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+)
+
+// ApplyFieldManager is the field manager of the objects applied by Apply.
+const ApplyFieldManager = "kubectl-kmm"
+
+// RegistryRewrite replaces the From prefix of image references with To.
+type RegistryRewrite struct {
+	From string
+	To   string
+}
+
+// ParseRegistryRewrite parses a rewrite written as from=to, for instance quay.io/staging=quay.io/prod.
+func ParseRegistryRewrite(s string) (RegistryRewrite, error) {
+	from, to, found := strings.Cut(s, "=")
+	if !found || from == "" || to == "" {
+		return RegistryRewrite{}, fmt.Errorf("invalid registry rewrite %q: expected from=to", s)
+	}
+
+	return RegistryRewrite{From: strings.TrimSuffix(from, "/"), To: strings.TrimSuffix(to, "/")}, nil
+}
+
+// rewriteImage returns image with the prefix of the first matching rewrite replaced.
+// A prefix only matches whole path components, so that quay.io/org does not match quay.io/organization/image.
+func rewriteImage(image string, rewrites []RegistryRewrite) string {
+	for _, r := range rewrites {
+		if image == r.From {
+			return r.To
+		}
+
+		rest := strings.TrimPrefix(image, r.From)
+		if rest != image && strings.ContainsAny(rest[:1], "/:@") {
+			return r.To + rest
+		}
+	}
+
+	return image
+}
+
+// rewriteDockerfile rewrites the images of the FROM instructions of dockerfile.
+func rewriteDockerfile(dockerfile string, rewrites []RegistryRewrite) string {
+	lines := strings.Split(dockerfile, "\n")
+
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "FROM") {
+			continue
+		}
+
+		// skip flags such as --platform
+		j := 1
+		for j < len(fields)-1 && strings.HasPrefix(fields[j], "--") {
+			j++
+		}
+
+		if image := rewriteImage(fields[j], rewrites); image != fields[j] {
+			fields[j] = image
+			lines[i] = strings.Join(fields, " ")
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// RewriteRegistries rewrites the images referenced by the Modules in objs, and the FROM instructions of the
+// Dockerfiles held by the ConfigMaps in objs.
+func RewriteRegistries(objs []client.Object, rewrites []RegistryRewrite) {
+	if len(rewrites) == 0 {
+		return
+	}
+
+	for _, obj := range objs {
+		switch o := obj.(type) {
+		case *kmmv1beta1.Module:
+			for _, img := range moduleImageFields(o) {
+				*img = rewriteImage(*img, rewrites)
+			}
+		case *v1.ConfigMap:
+			if dockerfile, ok := o.Data[constants.DockerfileCMKey]; ok {
+				o.Data[constants.DockerfileCMKey] = rewriteDockerfile(dockerfile, rewrites)
+			}
+		}
+	}
+}
+
+// moduleImageFields returns pointers to the non-empty image fields of mod.
+func moduleImageFields(mod *kmmv1beta1.Module) []*string {
+	images := make([]*string, 0)
+
+	add := func(image *string) {
+		if *image != "" {
+			images = append(images, image)
+		}
+	}
+
+	container := &mod.Spec.ModuleLoader.Container
+
+	add(&container.ContainerImage)
+
+	if container.Sign != nil {
+		add(&container.Sign.UnsignedImage)
+	}
+
+	for i := 0; i < len(container.KernelMappings); i++ {
+		km := &container.KernelMappings[i]
+
+		add(&km.ContainerImage)
+
+		if km.Sign != nil {
+			add(&km.Sign.UnsignedImage)
+		}
+	}
+
+	if mod.Spec.DevicePlugin != nil {
+		add(&mod.Spec.DevicePlugin.Container.Image)
+	}
+
+	return images
+}
+
+// moduleReferences returns the names of the Dockerfile ConfigMaps and of the Secrets that mod references.
+func moduleReferences(mod *kmmv1beta1.Module) (sets.String, sets.String) {
+	configMaps := sets.NewString()
+	secrets := sets.NewString()
+
+	addSecret := func(ref *v1.LocalObjectReference) {
+		if ref != nil && ref.Name != "" {
+			secrets.Insert(ref.Name)
+		}
+	}
+
+	addBuild := func(b *kmmv1beta1.Build) {
+		if b == nil {
+			return
+		}
+
+		if b.DockerfileConfigMap != nil && b.DockerfileConfigMap.Name != "" {
+			configMaps.Insert(b.DockerfileConfigMap.Name)
+		}
+
+		for i := 0; i < len(b.Secrets); i++ {
+			addSecret(&b.Secrets[i])
+		}
+	}
+
+	addSign := func(s *kmmv1beta1.Sign) {
+		if s != nil {
+			addSecret(s.KeySecret)
+			addSecret(s.CertSecret)
+		}
+	}
+
+	container := mod.Spec.ModuleLoader.Container
+
+	addBuild(container.Build)
+	addSign(container.Sign)
+
+	for _, km := range container.KernelMappings {
+		addBuild(km.Build)
+		addSign(km.Sign)
+	}
+
+	if container.Provenance != nil {
+		addSecret(&container.Provenance.PublicKeySecret)
+	}
+
+	addSecret(mod.Spec.ImageRepoSecret)
+
+	return configMaps, secrets
+}
+
+// Export returns the Modules of namespace named in names, or all of them if names is empty, along with the ConfigMaps
+// holding their Dockerfiles, without the fields set by the cluster nor a namespace, so that they can be applied to
+// another cluster.
+// The images and Dockerfiles are rewritten with rewrites.
+// Secrets are not exported: the returned warnings list those that must exist in the target namespace.
+func Export(
+	ctx context.Context,
+	c client.Client,
+	namespace string,
+	names []string,
+	rewrites []RegistryRewrite) ([]client.Object, []string, error) {
+	mods := make([]kmmv1beta1.Module, 0, len(names))
+
+	if len(names) == 0 {
+		list := kmmv1beta1.ModuleList{}
+
+		if err := c.List(ctx, &list, client.InNamespace(namespace)); err != nil {
+			return nil, nil, fmt.Errorf("could not list Modules: %v", err)
+		}
+
+		mods = list.Items
+	}
+
+	for _, name := range names {
+		mod := kmmv1beta1.Module{}
+
+		if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &mod); err != nil {
+			return nil, nil, fmt.Errorf("could not get Module %s/%s: %v", namespace, name, err)
+		}
+
+		mods = append(mods, mod)
+	}
+
+	if len(mods) == 0 {
+		return nil, nil, fmt.Errorf("no Module found in namespace %s", namespace)
+	}
+
+	configMaps := sets.NewString()
+	warnings := make([]string, 0)
+	objs := make([]client.Object, 0, len(mods))
+
+	for i := 0; i < len(mods); i++ {
+		mod := &mods[i]
+
+		cms, secrets := moduleReferences(mod)
+		configMaps = configMaps.Union(cms)
+
+		for _, s := range secrets.List() {
+			warnings = append(warnings, fmt.Sprintf("Module %s references Secret %s, which is not exported", mod.Name, s))
+		}
+
+		objs = append(objs, &kmmv1beta1.Module{ObjectMeta: exportedMeta(mod.ObjectMeta), Spec: mod.Spec})
+	}
+
+	for _, name := range configMaps.List() {
+		cm := v1.ConfigMap{}
+
+		if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &cm); err != nil {
+			return nil, nil, fmt.Errorf("could not get ConfigMap %s/%s: %v", namespace, name, err)
+		}
+
+		objs = append(objs, &v1.ConfigMap{ObjectMeta: exportedMeta(cm.ObjectMeta), Data: cm.Data, BinaryData: cm.BinaryData})
+	}
+
+	RewriteRegistries(objs, rewrites)
+
+	return objs, warnings, nil
+}
+
+// exportedMeta only keeps the name, labels and annotations of meta, without those set by kubectl apply or by the
+// operator.
+func exportedMeta(meta metav1.ObjectMeta) metav1.ObjectMeta {
+	annotations := make(map[string]string, len(meta.Annotations))
+
+	for k, v := range meta.Annotations {
+		switch k {
+		case v1.LastAppliedConfigAnnotation, constants.RebuildKernelsAnnotation, constants.RetryBuildHandledAnnotation:
+		default:
+			annotations[k] = v
+		}
+	}
+
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+
+	return metav1.ObjectMeta{Name: meta.Name, Labels: meta.Labels, Annotations: annotations}
+}
+
+// Apply creates or updates objs, which must be Modules or ConfigMaps, in namespace with server-side apply.
+// The images and Dockerfiles are first rewritten with rewrites.
+// The returned warnings list the Secrets referenced by the Modules that do not exist in namespace.
+func Apply(
+	ctx context.Context,
+	c client.Client,
+	scheme *runtime.Scheme,
+	namespace string,
+	objs []client.Object,
+	rewrites []RegistryRewrite) ([]string, error) {
+	secrets := sets.NewString()
+
+	for _, obj := range objs {
+		switch o := obj.(type) {
+		case *kmmv1beta1.Module:
+			_, s := moduleReferences(o)
+			secrets = secrets.Union(s)
+		case *v1.ConfigMap:
+		default:
+			return nil, fmt.Errorf("%s: only Modules and ConfigMaps can be applied, not %T", obj.GetName(), obj)
+		}
+	}
+
+	RewriteRegistries(objs, rewrites)
+
+	for _, obj := range objs {
+		gvk, err := apiutil.GVKForObject(obj, scheme)
+		if err != nil {
+			return nil, fmt.Errorf("could not determine the kind of %s: %v", obj.GetName(), err)
+		}
+
+		obj.GetObjectKind().SetGroupVersionKind(gvk)
+		obj.SetNamespace(namespace)
+		obj.SetResourceVersion("")
+		obj.SetManagedFields(nil)
+
+		if err = c.Patch(ctx, obj, client.Apply, client.FieldOwner(ApplyFieldManager), client.ForceOwnership); err != nil {
+			return nil, fmt.Errorf("could not apply %s %s/%s: %v", gvk.Kind, namespace, obj.GetName(), err)
+		}
+	}
+
+	warnings := make([]string, 0)
+
+	for _, name := range secrets.List() {
+		err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &v1.Secret{})
+		if k8serrors.IsNotFound(err) {
+			warnings = append(warnings, fmt.Sprintf("Secret %s/%s does not exist", namespace, name))
+		} else if err != nil {
+			return nil, fmt.Errorf("could not get Secret %s/%s: %v", namespace, name, err)
+		}
+	}
+
+	return warnings, nil
+}
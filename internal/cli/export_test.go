@@ -0,0 +1,232 @@
+package cli
+
+import (
+	"context"
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/client"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+)
+
+var _ = Describe("ParseRegistryRewrite", func() {
+	It("should parse from=to", func() {
+		Expect(
+			ParseRegistryRewrite("quay.io/staging/=quay.io/prod"),
+		).To(
+			Equal(RegistryRewrite{From: "quay.io/staging", To: "quay.io/prod"}),
+		)
+	})
+
+	DescribeTable("should return an error",
+		func(s string) {
+			_, err := ParseRegistryRewrite(s)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("no equal sign", "quay.io/staging"),
+		Entry("empty from", "=quay.io/prod"),
+		Entry("empty to", "quay.io/staging="),
+	)
+})
+
+var _ = DescribeTable("rewriteImage",
+	func(image, expected string) {
+		rewrites := []RegistryRewrite{
+			{From: "quay.io/org", To: "registry.example.com/prod"},
+			{From: "quay.io", To: "mirror.example.com"},
+		}
+
+		Expect(rewriteImage(image, rewrites)).To(Equal(expected))
+	},
+	Entry("first matching rewrite", "quay.io/org/kmod:1.0", "registry.example.com/prod/kmod:1.0"),
+	Entry("whole path components only", "quay.io/organization/kmod", "mirror.example.com/organization/kmod"),
+	Entry("tag right after the prefix", "quay.io/org:1.0", "registry.example.com/prod:1.0"),
+	Entry("exact match", "quay.io/org", "registry.example.com/prod"),
+	Entry("no match", "docker.io/library/ubuntu", "docker.io/library/ubuntu"),
+)
+
+var _ = Describe("rewriteDockerfile", func() {
+	It("should only rewrite the images of the FROM instructions", func() {
+		dockerfile := `ARG KERNEL_VERSION
+FROM quay.io/org/builder:${KERNEL_VERSION} AS builder
+RUN echo quay.io/org
+from --platform=linux/amd64 quay.io/org/base
+COPY --from=builder /opt /opt
+`
+
+		Expect(
+			rewriteDockerfile(dockerfile, []RegistryRewrite{{From: "quay.io/org", To: "registry.example.com/prod"}}),
+		).To(
+			Equal(`ARG KERNEL_VERSION
+FROM registry.example.com/prod/builder:${KERNEL_VERSION} AS builder
+RUN echo quay.io/org
+from --platform=linux/amd64 registry.example.com/prod/base
+COPY --from=builder /opt /opt
+`),
+		)
+	})
+})
+
+var _ = Describe("Export", func() {
+	const (
+		moduleName = "some-module"
+		namespace  = "some-namespace"
+		cmName     = "some-dockerfile"
+	)
+
+	var (
+		ctrl *gomock.Controller
+		clnt *client.MockClient
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+	})
+
+	ctx := context.Background()
+	rewrites := []RegistryRewrite{{From: "quay.io/staging", To: "quay.io/prod"}}
+
+	It("should return an error if no Module is found", func() {
+		clnt.EXPECT().List(ctx, gomock.Any(), ctrlclient.InNamespace(namespace))
+
+		_, _, err := Export(ctx, clnt, namespace, nil, rewrites)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return an error if a Module cannot be fetched", func() {
+		clnt.EXPECT().Get(ctx, gomock.Any(), gomock.Any()).Return(errors.New("some error"))
+
+		_, _, err := Export(ctx, clnt, namespace, []string{moduleName}, rewrites)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should export the Module and its Dockerfile without the cluster fields", func() {
+		gomock.InOrder(
+			clnt.EXPECT().Get(ctx, types.NamespacedName{Name: moduleName, Namespace: namespace}, gomock.Any()).DoAndReturn(
+				func(_ interface{}, _ interface{}, m *kmmv1beta1.Module, _ ...ctrlclient.GetOption) error {
+					m.ObjectMeta = metav1.ObjectMeta{
+						Name:            moduleName,
+						Namespace:       namespace,
+						UID:             "some-uid",
+						ResourceVersion: "123",
+						Labels:          map[string]string{"app": "kmod"},
+						Annotations: map[string]string{
+							v1.LastAppliedConfigAnnotation:        "{}",
+							constants.RetryBuildHandledAnnotation: "5.14.0",
+						},
+					}
+					m.Spec.ImageRepoSecret = &v1.LocalObjectReference{Name: "pull-secret"}
+					m.Spec.ModuleLoader.Container.KernelMappings = []kmmv1beta1.KernelMapping{
+						{
+							Regexp:         "^.+$",
+							ContainerImage: "quay.io/staging/kmod:${KERNEL_FULL_VERSION}",
+							Build: &kmmv1beta1.Build{
+								DockerfileConfigMap: &v1.LocalObjectReference{Name: cmName},
+							},
+							Sign: &kmmv1beta1.Sign{
+								KeySecret:  &v1.LocalObjectReference{Name: "sign-key"},
+								CertSecret: &v1.LocalObjectReference{Name: "sign-cert"},
+							},
+						},
+					}
+					m.Status.ReadyKernels = 1
+					return nil
+				},
+			),
+			clnt.EXPECT().Get(ctx, types.NamespacedName{Name: cmName, Namespace: namespace}, gomock.Any()).DoAndReturn(
+				func(_ interface{}, _ interface{}, cm *v1.ConfigMap, _ ...ctrlclient.GetOption) error {
+					cm.Name = cmName
+					cm.Namespace = namespace
+					cm.UID = "some-other-uid"
+					cm.Data = map[string]string{constants.DockerfileCMKey: "FROM quay.io/staging/base"}
+					return nil
+				},
+			),
+		)
+
+		objs, warnings, err := Export(ctx, clnt, namespace, []string{moduleName}, rewrites)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs).To(HaveLen(2))
+
+		mod := objs[0].(*kmmv1beta1.Module)
+		Expect(mod.ObjectMeta).To(Equal(metav1.ObjectMeta{Name: moduleName, Labels: map[string]string{"app": "kmod"}}))
+		Expect(mod.Status).To(Equal(kmmv1beta1.ModuleStatus{}))
+		Expect(mod.Spec.ModuleLoader.Container.KernelMappings[0].ContainerImage).To(Equal("quay.io/prod/kmod:${KERNEL_FULL_VERSION}"))
+
+		cm := objs[1].(*v1.ConfigMap)
+		Expect(cm.ObjectMeta).To(Equal(metav1.ObjectMeta{Name: cmName}))
+		Expect(cm.Data).To(HaveKeyWithValue(constants.DockerfileCMKey, "FROM quay.io/prod/base"))
+
+		Expect(warnings).To(Equal([]string{
+			"Module some-module references Secret pull-secret, which is not exported",
+			"Module some-module references Secret sign-cert, which is not exported",
+			"Module some-module references Secret sign-key, which is not exported",
+		}))
+	})
+})
+
+var _ = Describe("Apply", func() {
+	const namespace = "some-namespace"
+
+	var (
+		ctrl *gomock.Controller
+		clnt *client.MockClient
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+	})
+
+	ctx := context.Background()
+
+	It("should refuse objects other than Modules and ConfigMaps", func() {
+		objs := []ctrlclient.Object{&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "some-secret"}}}
+
+		_, err := Apply(ctx, clnt, scheme, namespace, objs, nil)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should apply the objects in the namespace and report the missing Secrets", func() {
+		mod := &kmmv1beta1.Module{ObjectMeta: metav1.ObjectMeta{Name: "some-module"}}
+		mod.Spec.ImageRepoSecret = &v1.LocalObjectReference{Name: "pull-secret"}
+		mod.Spec.ModuleLoader.Container.ContainerImage = "quay.io/staging/kmod"
+
+		cm := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "some-dockerfile"}}
+
+		gomock.InOrder(
+			clnt.EXPECT().Patch(ctx, mod, ctrlclient.Apply, gomock.Any()),
+			clnt.EXPECT().Patch(ctx, cm, ctrlclient.Apply, gomock.Any()),
+			clnt.EXPECT().
+				Get(ctx, types.NamespacedName{Name: "pull-secret", Namespace: namespace}, gomock.Any()).
+				Return(k8serrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, "pull-secret")),
+		)
+
+		warnings, err := Apply(
+			ctx,
+			clnt,
+			scheme,
+			namespace,
+			[]ctrlclient.Object{mod, cm},
+			[]RegistryRewrite{{From: "quay.io/staging", To: "quay.io/prod"}},
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(Equal([]string{"Secret some-namespace/pull-secret does not exist"}))
+
+		Expect(mod.Namespace).To(Equal(namespace))
+		Expect(mod.Kind).To(Equal("Module"))
+		Expect(mod.Spec.ModuleLoader.Container.ContainerImage).To(Equal("quay.io/prod/kmod"))
+		Expect(cm.Namespace).To(Equal(namespace))
+	})
+})
@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/registry"
+)
+
+const (
+	// The default modprobe dirName: module-loader images hold modules under /opt/lib/modules/<kernel version>.
+	modulesDir  = "/opt"
+	modulesPath = modulesDir + "/lib/modules"
+)
+
+var (
+	// The directories that module-loader images conventionally copy firmware to.
+	firmwareDirs = []string{"/firmware", "/opt/lib/firmware"}
+
+	kernelModuleRegexp = regexp.MustCompile(`^(.+)\.ko(\.xz|\.gz|\.zst)?$`)
+)
+
+// ImageContents is the part of a driver image that a Module depends on.
+type ImageContents struct {
+	// Modules maps the kernel versions found under /opt/lib/modules to the names of the kernel modules built for them.
+	Modules map[string][]string
+
+	// FirmwarePath is the directory holding firmware files, if any.
+	FirmwarePath string
+}
+
+// InspectImage lists the kernel modules and the firmware that img holds.
+func InspectImage(reg registry.Registry, img v1.Image) (*ImageContents, error) {
+	modules := make(map[string]sets.String)
+	firmwarePath := ""
+
+	walk := func(filename string, header *tar.Header, _ io.Reader, _ []interface{}) error {
+		if header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeSymlink {
+			return nil
+		}
+
+		p := path.Clean("/" + filename)
+
+		// whiteout files mark deletions in upper layers
+		if strings.HasPrefix(path.Base(p), whiteoutPrefix) {
+			return nil
+		}
+
+		if kernel, module, ok := kernelModule(p); ok {
+			if modules[kernel] == nil {
+				modules[kernel] = sets.NewString()
+			}
+
+			modules[kernel].Insert(module)
+
+			return nil
+		}
+
+		for _, dir := range firmwareDirs {
+			if firmwarePath == "" && strings.HasPrefix(p, dir+"/") {
+				firmwarePath = dir
+			}
+		}
+
+		return nil
+	}
+
+	if err := reg.WalkFilesInImage(img, walk); err != nil {
+		return nil, fmt.Errorf("could not read the image: %v", err)
+	}
+
+	contents := ImageContents{
+		Modules:      make(map[string][]string, len(modules)),
+		FirmwarePath: firmwarePath,
+	}
+
+	for kernel, names := range modules {
+		contents.Modules[kernel] = names.List()
+	}
+
+	return &contents, nil
+}
+
+// kernelModule returns the kernel version and the module name of p if it is a kernel module under
+// /opt/lib/modules/<kernel version>.
+func kernelModule(p string) (string, string, bool) {
+	rel := strings.TrimPrefix(p, modulesPath+"/")
+	if rel == p {
+		return "", "", false
+	}
+
+	kernel, rest, ok := strings.Cut(rel, "/")
+	if !ok || !kernelVersionRegexp.MatchString(kernel) {
+		return "", "", false
+	}
+
+	m := kernelModuleRegexp.FindStringSubmatch(path.Base(rest))
+	if m == nil {
+		return "", "", false
+	}
+
+	return kernel, m[1], true
+}
+
+// GenerateModule returns a starter Module loading the kernel modules of contents from image on the worker nodes
+// running one of the kernels that image was built for.
+// The returned warnings list the fields that the user should review.
+func GenerateModule(name, namespace, image string, contents *ImageContents) (*kmmv1beta1.Module, []string, error) {
+	if len(contents.Modules) == 0 {
+		return nil, nil, fmt.Errorf("no kernel module found under %s", modulesPath)
+	}
+
+	kernels := make([]string, 0, len(contents.Modules))
+	moduleNames := sets.NewString()
+
+	for kernel, names := range contents.Modules {
+		// nodes report kernel versions without the trailing +
+		kernels = append(kernels, strings.TrimSuffix(kernel, "+"))
+		moduleNames.Insert(names...)
+	}
+
+	sort.Strings(kernels)
+
+	warnings := make([]string, 0)
+
+	moduleName := moduleNames.List()[0]
+
+	if moduleNames.Len() > 1 {
+		warnings = append(
+			warnings,
+			fmt.Sprintf(
+				"the image holds several kernel modules (%s): set spec.moduleLoader.container.modprobe.moduleName to the "+
+					"one to load; modprobe loads its dependencies",
+				strings.Join(moduleNames.List(), ", "),
+			),
+		)
+	}
+
+	if name == "" {
+		name = strings.ReplaceAll(moduleName, "_", "-")
+	}
+
+	km := kmmv1beta1.KernelMapping{ContainerImage: image}
+
+	if len(kernels) == 1 {
+		km.Literal = kernels[0]
+	} else {
+		quoted := make([]string, 0, len(kernels))
+
+		for _, k := range kernels {
+			quoted = append(quoted, regexp.QuoteMeta(k))
+		}
+
+		km.Regexp = "^(" + strings.Join(quoted, "|") + ")$"
+	}
+
+	warnings = append(
+		warnings,
+		"spec.selector targets all worker nodes: restrict it to the nodes that have the hardware",
+	)
+
+	mod := kmmv1beta1.Module{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: kmmv1beta1.ModuleSpec{
+			ModuleLoader: kmmv1beta1.ModuleLoaderSpec{
+				Container: kmmv1beta1.ModuleLoaderContainerSpec{
+					Modprobe: kmmv1beta1.ModprobeSpec{
+						ModuleName:   moduleName,
+						DirName:      modulesDir,
+						FirmwarePath: contents.FirmwarePath,
+					},
+					KernelMappings: []kmmv1beta1.KernelMapping{km},
+				},
+			},
+			Selector: map[string]string{"node-role.kubernetes.io/worker": ""},
+		},
+	}
+
+	return &mod, warnings, nil
+}
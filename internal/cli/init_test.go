@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"archive/tar"
+	"errors"
+	"io"
+
+	"github.com/golang/mock/gomock"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubernetes-sigs/kernel-module-management/internal/registry"
+)
+
+var _ = Describe("InspectImage", func() {
+	var (
+		ctrl *gomock.Controller
+		reg  *registry.MockRegistry
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		reg = registry.NewMockRegistry(ctrl)
+	})
+
+	It("should return an error if the image cannot be read", func() {
+		reg.EXPECT().WalkFilesInImage(nil, gomock.Any()).Return(errors.New("some error"))
+
+		_, err := InspectImage(reg, nil)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should list the kernel modules and the firmware", func() {
+		files := map[string]byte{
+			"opt/lib/modules/5.14.0-1.el9.x86_64/extra/kmm_ci_a.ko":    tar.TypeReg,
+			"opt/lib/modules/5.14.0-1.el9.x86_64/extra/kmm_ci_b.ko.xz": tar.TypeReg,
+			"opt/lib/modules/5.14.0-1.el9.x86_64/modules.dep":          tar.TypeReg,
+			"./opt/lib/modules/5.14.0-2.el9.x86_64/kmm_ci_a.ko":        tar.TypeReg,
+			"opt/lib/modules/5.14.0-3.el9.x86_64/":                     tar.TypeDir,
+			"opt/lib/modules/5.14.0-3.el9.x86_64/.wh.kmm_ci_a.ko":      tar.TypeReg,
+			"lib/modules/5.14.0-4.el9.x86_64/kernel/other.ko":          tar.TypeReg,
+			"firmware/kmm_ci_a.bin":                                    tar.TypeReg,
+		}
+
+		reg.EXPECT().WalkFilesInImage(nil, gomock.Any()).DoAndReturn(
+			func(_ v1.Image, fn func(string, *tar.Header, io.Reader, []interface{}) error, _ ...interface{}) error {
+				for name, typeflag := range files {
+					if err := fn(name, &tar.Header{Name: name, Typeflag: typeflag}, nil, nil); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			},
+		)
+
+		contents, err := InspectImage(reg, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(contents).To(Equal(&ImageContents{
+			Modules: map[string][]string{
+				"5.14.0-1.el9.x86_64": {"kmm_ci_a", "kmm_ci_b"},
+				"5.14.0-2.el9.x86_64": {"kmm_ci_a"},
+			},
+			FirmwarePath: "/firmware",
+		}))
+	})
+})
+
+var _ = Describe("GenerateModule", func() {
+	const image = "quay.io/org/kmm-ci:v1"
+
+	It("should return an error if the image holds no kernel module", func() {
+		_, _, err := GenerateModule("", "default", image, &ImageContents{Modules: map[string][]string{}})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should map the only kernel literally and name the Module after the kernel module", func() {
+		contents := &ImageContents{
+			Modules:      map[string][]string{"5.14.0-1.el9.x86_64": {"kmm_ci_a"}},
+			FirmwarePath: "/firmware",
+		}
+
+		mod, warnings, err := GenerateModule("", "some-namespace", image, contents)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(HaveLen(1))
+
+		Expect(mod.Name).To(Equal("kmm-ci-a"))
+		Expect(mod.Namespace).To(Equal("some-namespace"))
+
+		container := mod.Spec.ModuleLoader.Container
+		Expect(container.Modprobe.ModuleName).To(Equal("kmm_ci_a"))
+		Expect(container.Modprobe.DirName).To(Equal("/opt"))
+		Expect(container.Modprobe.FirmwarePath).To(Equal("/firmware"))
+		Expect(container.KernelMappings).To(HaveLen(1))
+		Expect(container.KernelMappings[0].Literal).To(Equal("5.14.0-1.el9.x86_64"))
+		Expect(container.KernelMappings[0].ContainerImage).To(Equal(image))
+	})
+
+	It("should match all kernels with a regexp and warn about several kernel modules", func() {
+		contents := &ImageContents{
+			Modules: map[string][]string{
+				"5.14.0-2.el9.x86_64": {"kmm_ci_a"},
+				"5.14.0-1.el9.x86_64": {"kmm_ci_a", "kmm_ci_b"},
+			},
+		}
+
+		mod, warnings, err := GenerateModule("my-module", "default", image, contents)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(HaveLen(2))
+		Expect(warnings[0]).To(ContainSubstring("kmm_ci_a, kmm_ci_b"))
+
+		Expect(mod.Name).To(Equal("my-module"))
+
+		container := mod.Spec.ModuleLoader.Container
+		Expect(container.Modprobe.ModuleName).To(Equal("kmm_ci_a"))
+		Expect(container.Modprobe.FirmwarePath).To(BeEmpty())
+		Expect(container.KernelMappings[0].Regexp).To(Equal(`^(5\.14\.0-1\.el9\.x86_64|5\.14\.0-2\.el9\.x86_64)$`))
+	})
+})
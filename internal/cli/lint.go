@@ -0,0 +1,229 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/sign"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/validation"
+)
+
+// LintProblem is a likely mistake in a Module.
+type LintProblem struct {
+	Namespace string
+	Name      string
+	Message   string
+}
+
+// Lint checks the Modules in namespace, or in all namespaces if namespace is empty, against the cluster.
+// On top of the checks of validator, which the operator runs before deploying a Module, it reports selectors that
+// match no node, kernel mappings that match none of the kernels of the selected nodes, images without a tag or digest,
+// signing keys that cannot be found, and device plugins without resource requests or limits.
+func Lint(
+	ctx context.Context,
+	c client.Client,
+	validator validation.Validator,
+	kernelAPI module.KernelMapper,
+	namespace string) ([]LintProblem, error) {
+	mods := kmmv1beta1.ModuleList{}
+
+	if err := c.List(ctx, &mods, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("could not list Modules: %v", err)
+	}
+
+	nodes := v1.NodeList{}
+
+	if err := c.List(ctx, &nodes); err != nil {
+		return nil, fmt.Errorf("could not list nodes: %v", err)
+	}
+
+	problems := make([]LintProblem, 0)
+
+	for i := 0; i < len(mods.Items); i++ {
+		mod := &mods.Items[i]
+
+		msgs := validationMessages(validator.ValidateModule(mod))
+		msgs = append(msgs, lintKernelMappings(kernelAPI, mod, nodes.Items)...)
+		msgs = append(msgs, lintImages(mod)...)
+		msgs = append(msgs, lintSigning(ctx, c, mod)...)
+
+		if dp := mod.Spec.DevicePlugin; dp != nil && len(dp.Container.Resources.Requests) == 0 && len(dp.Container.Resources.Limits) == 0 {
+			msgs = append(msgs, "spec.devicePlugin.container.resources: no requests or limits are set")
+		}
+
+		for _, m := range msgs {
+			problems = append(problems, LintProblem{Namespace: mod.Namespace, Name: mod.Name, Message: m})
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Namespace != problems[j].Namespace {
+			return problems[i].Namespace < problems[j].Namespace
+		}
+
+		return problems[i].Name < problems[j].Name
+	})
+
+	return problems, nil
+}
+
+func validationMessages(err error) []string {
+	if err == nil {
+		return nil
+	}
+
+	errs := []error{err}
+
+	if agg, ok := err.(utilerrors.Aggregate); ok {
+		errs = agg.Errors()
+	}
+
+	msgs := make([]string, 0, len(errs))
+
+	for _, e := range errs {
+		msgs = append(msgs, "rejected by the operator: "+e.Error())
+	}
+
+	return msgs
+}
+
+// lintKernelMappings reports a selector that matches no node, and the kernel mappings that match none of
+// the kernels of the selected nodes.
+func lintKernelMappings(kernelAPI module.KernelMapper, mod *kmmv1beta1.Module, nodes []v1.Node) []string {
+	selector := labels.SelectorFromSet(mod.Spec.Selector)
+
+	kernels := sets.NewString()
+
+	for _, n := range nodes {
+		if selector.Matches(labels.Set(n.Labels)) {
+			kernels.Insert(strings.TrimSuffix(n.Status.NodeInfo.KernelVersion, "+"))
+		}
+	}
+
+	if kernels.Len() == 0 {
+		return []string{"spec.selector: matches no node"}
+	}
+
+	msgs := make([]string, 0)
+
+	for i, km := range mod.Spec.ModuleLoader.Container.KernelMappings {
+		field := fmt.Sprintf("spec.moduleLoader.container.kernelMappings[%d]", i)
+		matched := false
+
+		for _, k := range kernels.List() {
+			m, err := kernelAPI.FindMappingForKernel([]kmmv1beta1.KernelMapping{km}, k)
+			if err == nil && m != nil {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			msgs = append(msgs, fmt.Sprintf("%s: matches none of the kernels of the selected nodes (%s)", field, strings.Join(kernels.List(), ", ")))
+		}
+	}
+
+	return msgs
+}
+
+// lintImages reports the images that have neither a tag nor a digest, and therefore use the latest tag.
+func lintImages(mod *kmmv1beta1.Module) []string {
+	msgs := make([]string, 0)
+
+	for _, img := range validation.ModuleImages(mod) {
+		// registries may have a port: only look for a tag after the last slash
+		name := img.Image[strings.LastIndex(img.Image, "/")+1:]
+
+		if !strings.ContainsAny(name, ":@") {
+			msgs = append(msgs, fmt.Sprintf("%s: image %s has no tag", img.Path, img.Image))
+		}
+	}
+
+	return msgs
+}
+
+// lintSigning reports the missing signing key and certificate Secrets.
+func lintSigning(ctx context.Context, c client.Client, mod *kmmv1beta1.Module) []string {
+	type fieldSign struct {
+		field string
+		sign  *kmmv1beta1.Sign
+	}
+
+	container := mod.Spec.ModuleLoader.Container
+
+	signs := make([]fieldSign, 0)
+
+	if container.Sign != nil {
+		signs = append(signs, fieldSign{field: "spec.moduleLoader.container.sign", sign: container.Sign})
+	}
+
+	for i, km := range container.KernelMappings {
+		if km.Sign != nil {
+			field := fmt.Sprintf("spec.moduleLoader.container.kernelMappings[%d].sign", i)
+			signs = append(signs, fieldSign{field: field, sign: km.Sign})
+		}
+	}
+
+	msgs := make([]string, 0)
+
+	for _, s := range signs {
+		if msg := lintSignSecret(ctx, c, mod.Namespace, s.sign.KeySecret, sign.KeySecretKey(s.sign)); msg != "" {
+			msgs = append(msgs, s.field+".keySecret: "+msg)
+		}
+
+		if msg := lintSignSecret(ctx, c, mod.Namespace, s.sign.CertSecret, sign.CertSecretKey(s.sign)); msg != "" {
+			msgs = append(msgs, s.field+".certSecret: "+msg)
+		}
+	}
+
+	return msgs
+}
+
+func lintSignSecret(ctx context.Context, c client.Client, namespace string, ref *v1.LocalObjectReference, key string) string {
+	if ref == nil || ref.Name == "" {
+		return "not set"
+	}
+
+	secret := v1.Secret{}
+
+	if err := c.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, &secret); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return fmt.Sprintf("Secret %s not found", ref.Name)
+		}
+
+		return fmt.Sprintf("could not get Secret %s: %v", ref.Name, err)
+	}
+
+	if len(secret.Data[key]) == 0 {
+		return fmt.Sprintf("Secret %s has no %q key", ref.Name, key)
+	}
+
+	return ""
+}
+
+// PrintLintProblems writes problems to w as a table.
+func PrintLintProblems(w io.Writer, problems []LintProblem) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "NAMESPACE\tMODULE\tPROBLEM")
+
+	for _, p := range problems {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", p.Namespace, p.Name, p.Message)
+	}
+
+	return tw.Flush()
+}
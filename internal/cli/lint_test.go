@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/client"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/validation"
+)
+
+var _ = Describe("Lint", func() {
+	const namespace = "some-namespace"
+
+	var (
+		ctrl      *gomock.Controller
+		clnt      *client.MockClient
+		validator *validation.MockValidator
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+		validator = validation.NewMockValidator(ctrl)
+	})
+
+	ctx := context.Background()
+
+	expectList := func(mods []kmmv1beta1.Module, nodes []v1.Node) {
+		gomock.InOrder(
+			clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ interface{}, list *kmmv1beta1.ModuleList, _ ...interface{}) error {
+					list.Items = mods
+					return nil
+				},
+			),
+			clnt.EXPECT().List(ctx, gomock.Any()).DoAndReturn(
+				func(_ interface{}, list *v1.NodeList, _ ...interface{}) error {
+					list.Items = nodes
+					return nil
+				},
+			),
+		)
+	}
+
+	node := func(kernel string, labels map[string]string) v1.Node {
+		return v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Labels: labels},
+			Status:     v1.NodeStatus{NodeInfo: v1.NodeSystemInfo{KernelVersion: kernel}},
+		}
+	}
+
+	It("should return an error if the Modules cannot be listed", func() {
+		clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).Return(errors.New("some error"))
+
+		_, err := Lint(ctx, clnt, validator, module.NewKernelMapper(), namespace)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should not report anything for a correct Module", func() {
+		mod := kmmv1beta1.Module{ObjectMeta: metav1.ObjectMeta{Name: "good", Namespace: namespace}}
+		mod.Spec.Selector = map[string]string{"role": "worker"}
+		mod.Spec.ModuleLoader.Container.KernelMappings = []kmmv1beta1.KernelMapping{
+			{Regexp: `^.+$`, ContainerImage: "quay.io/org/kmod:${KERNEL_FULL_VERSION}"},
+		}
+
+		expectList([]kmmv1beta1.Module{mod}, []v1.Node{node("5.14.0-1+", map[string]string{"role": "worker"})})
+		validator.EXPECT().ValidateModule(gomock.Any())
+
+		problems, err := Lint(ctx, clnt, validator, module.NewKernelMapper(), namespace)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(problems).To(BeEmpty())
+	})
+
+	It("should report a selector that matches no node", func() {
+		mod := kmmv1beta1.Module{ObjectMeta: metav1.ObjectMeta{Name: "no-node", Namespace: namespace}}
+		mod.Spec.Selector = map[string]string{"role": "gpu"}
+		mod.Spec.ModuleLoader.Container.KernelMappings = []kmmv1beta1.KernelMapping{
+			{Literal: "4.18.0", ContainerImage: "quay.io/org/kmod:v1"},
+		}
+
+		expectList([]kmmv1beta1.Module{mod}, []v1.Node{node("5.14.0-1", map[string]string{"role": "worker"})})
+		validator.EXPECT().ValidateModule(gomock.Any())
+
+		problems, err := Lint(ctx, clnt, validator, module.NewKernelMapper(), namespace)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(problems).To(Equal([]LintProblem{
+			{Namespace: namespace, Name: "no-node", Message: "spec.selector: matches no node"},
+		}))
+	})
+
+	It("should report all problems of a Module", func() {
+		mod := kmmv1beta1.Module{ObjectMeta: metav1.ObjectMeta{Name: "bad", Namespace: namespace}}
+		mod.Spec.ModuleLoader.Container.KernelMappings = []kmmv1beta1.KernelMapping{
+			{Regexp: `^5\.14\..+$`, ContainerImage: "registry:5000/org/kmod"},
+			{
+				Literal:        "4.18.0",
+				ContainerImage: "quay.io/org/kmod@sha256:abcd",
+				Sign: &kmmv1beta1.Sign{
+					KeySecret:  &v1.LocalObjectReference{Name: "signing-key"},
+					CertSecret: &v1.LocalObjectReference{Name: "signing-cert"},
+				},
+			},
+		}
+		mod.Spec.DevicePlugin = &kmmv1beta1.DevicePluginSpec{
+			Container: kmmv1beta1.DevicePluginContainerSpec{Image: "quay.io/org/device-plugin:v1"},
+		}
+
+		expectList([]kmmv1beta1.Module{mod}, []v1.Node{node("5.14.0-1", nil)})
+
+		validator.EXPECT().ValidateModule(gomock.Any()).Return(
+			utilerrors.NewAggregate([]error{errors.New("error 1"), errors.New("error 2")}),
+		)
+
+		gomock.InOrder(
+			clnt.EXPECT().Get(ctx, types.NamespacedName{Name: "signing-key", Namespace: namespace}, gomock.Any()).DoAndReturn(
+				func(_ interface{}, _ interface{}, s *v1.Secret, _ ...ctrlclient.GetOption) error {
+					s.Data = map[string][]byte{"cert": []byte("some-cert")}
+					return nil
+				},
+			),
+			clnt.EXPECT().Get(ctx, types.NamespacedName{Name: "signing-cert", Namespace: namespace}, gomock.Any()).
+				Return(k8serrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, "signing-cert")),
+		)
+
+		problems, err := Lint(ctx, clnt, validator, module.NewKernelMapper(), namespace)
+		Expect(err).NotTo(HaveOccurred())
+
+		messages := make([]string, 0, len(problems))
+
+		for _, p := range problems {
+			Expect(p.Namespace).To(Equal(namespace))
+			Expect(p.Name).To(Equal("bad"))
+
+			messages = append(messages, p.Message)
+		}
+
+		Expect(messages).To(Equal([]string{
+			"rejected by the operator: error 1",
+			"rejected by the operator: error 2",
+			"spec.moduleLoader.container.kernelMappings[1]: matches none of the kernels of the selected nodes (5.14.0-1)",
+			"spec.moduleLoader.container.kernelMappings[0].containerImage: image registry:5000/org/kmod has no tag",
+			"spec.moduleLoader.container.kernelMappings[1].sign.keySecret: Secret signing-key has no \"key\" key",
+			"spec.moduleLoader.container.kernelMappings[1].sign.certSecret: Secret signing-cert not found",
+			"spec.devicePlugin.container.resources: no requests or limits are set",
+		}))
+	})
+})
+
+var _ = Describe("PrintLintProblems", func() {
+	It("should print a table", func() {
+		var buf bytes.Buffer
+
+		problems := []LintProblem{
+			{Namespace: "ns", Name: "mod", Message: "spec.selector: matches no node"},
+		}
+
+		Expect(
+			PrintLintProblems(&buf, problems),
+		).NotTo(
+			HaveOccurred(),
+		)
+
+		Expect(buf.String()).To(Equal(
+			"NAMESPACE  MODULE  PROBLEM\n" +
+				"ns         mod     spec.selector: matches no node\n",
+		))
+	})
+})
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kubernetes-sigs/kernel-module-management/internal/registry"
+)
+
+const whiteoutPrefix = ".wh."
+
+// ExtractModules extracts the files that img holds under dirName/lib/modules/kernel to dest/lib/modules/kernel, so that
+// modprobe -d dest finds them as the module-loader container finds them under dirName.
+// It writes the path of each extracted file to w and returns the number of extracted files.
+func ExtractModules(reg registry.Registry, img v1.Image, dirName, kernel, dest string, w io.Writer) (int, error) {
+	src := path.Join("/", dirName, "lib/modules", kernel)
+
+	n, err := extractDir(reg, img, src, filepath.Join(dest, "lib/modules", kernel), w)
+	if err != nil {
+		return 0, err
+	}
+
+	if n == 0 {
+		return 0, fmt.Errorf("the image has no file under %s", src)
+	}
+
+	return n, nil
+}
+
+// ExtractFirmware extracts the files that img holds under firmwarePath to dest, as the module-loader container copies
+// them to /var/lib/firmware on the node.
+// It writes the path of each extracted file to w and returns the number of extracted files.
+func ExtractFirmware(reg registry.Registry, img v1.Image, firmwarePath, dest string, w io.Writer) (int, error) {
+	return extractDir(reg, img, path.Join("/", firmwarePath), dest, w)
+}
+
+// extractDir extracts the regular files of img under the src directory to dest.
+func extractDir(reg registry.Registry, img v1.Image, src, dest string, w io.Writer) (int, error) {
+	// WalkFilesInImage visits the upper layers first: skip the files they replace or delete
+	seen := sets.NewString()
+	n := 0
+
+	walk := func(filename string, header *tar.Header, tarreader io.Reader, _ []interface{}) error {
+		p := path.Clean("/" + filename)
+
+		dir, base := path.Split(p)
+
+		if strings.HasPrefix(base, whiteoutPrefix) {
+			seen.Insert(path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix)))
+			return nil
+		}
+
+		rel := strings.TrimPrefix(p, src+"/")
+		if rel == p || header.Typeflag != tar.TypeReg || seen.Has(p) {
+			return nil
+		}
+
+		seen.Insert(p)
+
+		destination := filepath.Join(dest, filepath.FromSlash(rel))
+
+		fmt.Fprintf(w, "Extracting %s to %s\n", p, destination)
+
+		if err := reg.ExtractFileToFile(destination, header, tarreader); err != nil {
+			return err
+		}
+
+		n++
+
+		return nil
+	}
+
+	if err := reg.WalkFilesInImage(img, walk); err != nil {
+		return 0, fmt.Errorf("could not extract %s from the image: %v", src, err)
+	}
+
+	return n, nil
+}
+
+// ModprobeCommand returns the modprobe command loading moduleName from the modules extracted under root for kernel.
+// With dryRun, modprobe prints the modules it would load without loading them.
+func ModprobeCommand(root, kernel, moduleName string, parameters []string, dryRun bool) []string {
+	cmd := []string{"modprobe", "-v", "-d", root, "-S", kernel}
+
+	if dryRun {
+		cmd = append(cmd, "--dry-run")
+	}
+
+	cmd = append(cmd, moduleName)
+
+	return append(cmd, parameters...)
+}
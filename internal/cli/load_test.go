@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"archive/tar"
+	"errors"
+	"io"
+
+	"github.com/golang/mock/gomock"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubernetes-sigs/kernel-module-management/internal/registry"
+)
+
+var _ = Describe("ExtractModules", func() {
+	const kernel = "5.14.0-1.el9.x86_64"
+
+	var (
+		ctrl *gomock.Controller
+		reg  *registry.MockRegistry
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		reg = registry.NewMockRegistry(ctrl)
+	})
+
+	// walkFiles makes WalkFilesInImage visit headers in order, as if they were in layers from the top one down
+	walkFiles := func(headers ...*tar.Header) {
+		reg.EXPECT().WalkFilesInImage(nil, gomock.Any()).DoAndReturn(
+			func(_ v1.Image, fn func(string, *tar.Header, io.Reader, []interface{}) error, _ ...interface{}) error {
+				for _, h := range headers {
+					if err := fn(h.Name, h, nil, nil); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			},
+		)
+	}
+
+	It("should return an error if the image holds no file for the kernel", func() {
+		walkFiles(&tar.Header{Name: "opt/lib/modules/5.14.0-2.el9.x86_64/kmm_ci_a.ko", Typeflag: tar.TypeReg})
+
+		_, err := ExtractModules(reg, nil, "/opt", kernel, "/tmp/dest", io.Discard)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return an error if a file cannot be extracted", func() {
+		walkFiles(&tar.Header{Name: "opt/lib/modules/" + kernel + "/kmm_ci_a.ko", Typeflag: tar.TypeReg})
+		reg.EXPECT().ExtractFileToFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("some error"))
+
+		_, err := ExtractModules(reg, nil, "/opt", kernel, "/tmp/dest", io.Discard)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should extract the files of the upper layers only", func() {
+		modulesDep := &tar.Header{Name: "opt/lib/modules/" + kernel + "/modules.dep", Typeflag: tar.TypeReg}
+		moduleA := &tar.Header{Name: "./opt/lib/modules/" + kernel + "/extra/kmm_ci_a.ko", Typeflag: tar.TypeReg}
+
+		walkFiles(
+			modulesDep,
+			moduleA,
+			&tar.Header{Name: "opt/lib/modules/" + kernel + "/extra/.wh.kmm_ci_b.ko", Typeflag: tar.TypeReg},
+			&tar.Header{Name: "opt/lib/modules/" + kernel + "/extra/", Typeflag: tar.TypeDir},
+			// lower layer
+			&tar.Header{Name: "opt/lib/modules/" + kernel + "/modules.dep", Typeflag: tar.TypeReg},
+			&tar.Header{Name: "opt/lib/modules/" + kernel + "/extra/kmm_ci_b.ko", Typeflag: tar.TypeReg},
+			&tar.Header{Name: "lib/modules/" + kernel + "/kernel/other.ko", Typeflag: tar.TypeReg},
+		)
+
+		gomock.InOrder(
+			reg.EXPECT().ExtractFileToFile("/tmp/dest/lib/modules/"+kernel+"/modules.dep", modulesDep, nil),
+			reg.EXPECT().ExtractFileToFile("/tmp/dest/lib/modules/"+kernel+"/extra/kmm_ci_a.ko", moduleA, nil),
+		)
+
+		n, err := ExtractModules(reg, nil, "/opt", kernel, "/tmp/dest", io.Discard)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(2))
+	})
+})
+
+var _ = Describe("ExtractFirmware", func() {
+	It("should extract the files under the firmware path", func() {
+		ctrl := gomock.NewController(GinkgoT())
+		reg := registry.NewMockRegistry(ctrl)
+
+		firmware := &tar.Header{Name: "firmware/kmm_ci_a.bin", Typeflag: tar.TypeReg}
+
+		reg.EXPECT().WalkFilesInImage(nil, gomock.Any()).DoAndReturn(
+			func(_ v1.Image, fn func(string, *tar.Header, io.Reader, []interface{}) error, _ ...interface{}) error {
+				return fn(firmware.Name, firmware, nil, nil)
+			},
+		)
+		reg.EXPECT().ExtractFileToFile("/var/lib/firmware/kmm_ci_a.bin", firmware, nil)
+
+		n, err := ExtractFirmware(reg, nil, "/firmware", "/var/lib/firmware", io.Discard)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(1))
+	})
+})
+
+var _ = Describe("ModprobeCommand", func() {
+	It("should load the module from the extracted files", func() {
+		Expect(
+			ModprobeCommand("/tmp/root", "5.14.0", "kmm_ci_a", []string{"param=1"}, false),
+		).To(
+			Equal([]string{"modprobe", "-v", "-d", "/tmp/root", "-S", "5.14.0", "kmm_ci_a", "param=1"}),
+		)
+	})
+
+	It("should only print the modules to load with dryRun", func() {
+		Expect(
+			ModprobeCommand("/tmp/root", "5.14.0", "kmm_ci_a", nil, true),
+		).To(
+			Equal([]string{"modprobe", "-v", "-d", "/tmp/root", "-S", "5.14.0", "--dry-run", "kmm_ci_a"}),
+		)
+	})
+})
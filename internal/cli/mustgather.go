@@ -0,0 +1,306 @@
+package cli
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+)
+
+const (
+	mustGatherDir = "kmm-must-gather"
+
+	// maxLogBytes caps the size of each container log in the bundle.
+	maxLogBytes = 10 * 1024 * 1024
+)
+
+//go:generate mockgen -source=mustgather.go -package=cli -destination=mock_mustgather.go
+
+// PodLogGetter returns the logs of pod containers.
+type PodLogGetter interface {
+	GetPodLogs(ctx context.Context, namespace, pod, container string, previous bool) ([]byte, error)
+}
+
+type podLogGetter struct {
+	clientset kubernetes.Interface
+}
+
+// NewPodLogGetter returns a PodLogGetter reading logs through the API server.
+func NewPodLogGetter(clientset kubernetes.Interface) PodLogGetter {
+	return &podLogGetter{clientset: clientset}
+}
+
+func (plg *podLogGetter) GetPodLogs(ctx context.Context, namespace, pod, container string, previous bool) ([]byte, error) {
+	opts := v1.PodLogOptions{
+		Container:  container,
+		Previous:   previous,
+		LimitBytes: pointer.Int64(maxLogBytes),
+	}
+
+	return plg.clientset.CoreV1().Pods(namespace).GetLogs(pod, &opts).DoRaw(ctx)
+}
+
+// nodeInfo is the part of a Node relevant to KMM.
+type nodeInfo struct {
+	Name              string             `json:"name"`
+	CreationTimestamp metav1.Time        `json:"creationTimestamp"`
+	Labels            map[string]string  `json:"labels,omitempty"`
+	Unschedulable     bool               `json:"unschedulable,omitempty"`
+	Taints            []v1.Taint         `json:"taints,omitempty"`
+	NodeInfo          v1.NodeSystemInfo  `json:"nodeInfo"`
+	Conditions        []v1.NodeCondition `json:"conditions,omitempty"`
+}
+
+type mustGatherer struct {
+	client client.Client
+	logs   PodLogGetter
+	tw     *tar.Writer
+	now    time.Time
+	errs   []string
+}
+
+// MustGather writes to w a gzipped tarball containing the Modules and PreflightValidations of all namespaces, the
+// kernel and OS of all nodes, the DaemonSets, Jobs, pods and Events of the namespaces holding Modules, and the logs of
+// the pods created by KMM and of those running in operatorNamespace.
+// Errors do not stop the collection: they are listed in the errors.txt file of the tarball.
+func MustGather(ctx context.Context, c client.Client, logs PodLogGetter, operatorNamespace string, w io.Writer) error {
+	gzw := gzip.NewWriter(w)
+
+	mg := &mustGatherer{
+		client: c,
+		logs:   logs,
+		tw:     tar.NewWriter(gzw),
+		now:    time.Now(),
+	}
+
+	if err := mg.gather(ctx, operatorNamespace); err != nil {
+		return err
+	}
+
+	if len(mg.errs) > 0 {
+		if err := mg.writeFile("errors.txt", []byte(strings.Join(mg.errs, "\n")+"\n")); err != nil {
+			return err
+		}
+	}
+
+	if err := mg.tw.Close(); err != nil {
+		return fmt.Errorf("could not write the tarball: %v", err)
+	}
+
+	if err := gzw.Close(); err != nil {
+		return fmt.Errorf("could not compress the tarball: %v", err)
+	}
+
+	return nil
+}
+
+func (mg *mustGatherer) gather(ctx context.Context, operatorNamespace string) error {
+	mods := kmmv1beta1.ModuleList{}
+
+	if err := mg.list(ctx, "modules.json", &mods); err != nil {
+		return err
+	}
+
+	if err := mg.list(ctx, "preflightvalidations.json", &kmmv1beta1.PreflightValidationList{}); err != nil {
+		return err
+	}
+
+	if err := mg.gatherNodes(ctx); err != nil {
+		return err
+	}
+
+	namespaces := make(map[string]bool)
+
+	for _, m := range mods.Items {
+		namespaces[m.Namespace] = true
+	}
+
+	sorted := make([]string, 0, len(namespaces))
+
+	for ns := range namespaces {
+		sorted = append(sorted, ns)
+	}
+
+	sort.Strings(sorted)
+
+	for _, ns := range sorted {
+		if err := mg.gatherNamespace(ctx, ns, client.HasLabels{constants.ModuleNameLabel}); err != nil {
+			return err
+		}
+	}
+
+	if operatorNamespace != "" && !namespaces[operatorNamespace] {
+		if err := mg.gatherNamespace(ctx, operatorNamespace); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (mg *mustGatherer) gatherNodes(ctx context.Context) error {
+	nodes := v1.NodeList{}
+
+	if err := mg.client.List(ctx, &nodes); err != nil {
+		mg.addError("could not list nodes: %v", err)
+		return nil
+	}
+
+	infos := make([]nodeInfo, 0, len(nodes.Items))
+
+	for _, n := range nodes.Items {
+		infos = append(infos, nodeInfo{
+			Name:              n.Name,
+			CreationTimestamp: n.CreationTimestamp,
+			Labels:            n.Labels,
+			Unschedulable:     n.Spec.Unschedulable,
+			Taints:            n.Spec.Taints,
+			NodeInfo:          n.Status.NodeInfo,
+			Conditions:        n.Status.Conditions,
+		})
+	}
+
+	return mg.writeJSON("nodes.json", infos)
+}
+
+// gatherNamespace collects the workloads of namespace matching opts, and the logs of their pods.
+func (mg *mustGatherer) gatherNamespace(ctx context.Context, namespace string, opts ...client.ListOption) error {
+	opts = append(opts, client.InNamespace(namespace))
+
+	dir := path.Join("namespaces", namespace)
+
+	if err := mg.list(ctx, path.Join(dir, "daemonsets.json"), &appsv1.DaemonSetList{}, opts...); err != nil {
+		return err
+	}
+
+	if err := mg.list(ctx, path.Join(dir, "deployments.json"), &appsv1.DeploymentList{}, opts...); err != nil {
+		return err
+	}
+
+	if err := mg.list(ctx, path.Join(dir, "jobs.json"), &batchv1.JobList{}, opts...); err != nil {
+		return err
+	}
+
+	if err := mg.list(ctx, path.Join(dir, "events.json"), &v1.EventList{}, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+
+	pods := v1.PodList{}
+
+	if err := mg.list(ctx, path.Join(dir, "pods.json"), &pods, opts...); err != nil {
+		return err
+	}
+
+	for _, p := range pods.Items {
+		containers := make([]string, 0, len(p.Spec.InitContainers)+len(p.Spec.Containers))
+
+		for _, c := range p.Spec.InitContainers {
+			containers = append(containers, c.Name)
+		}
+
+		for _, c := range p.Spec.Containers {
+			containers = append(containers, c.Name)
+		}
+
+		for _, c := range containers {
+			if err := mg.gatherLogs(ctx, path.Join(dir, "logs", p.Name), &p, c); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (mg *mustGatherer) gatherLogs(ctx context.Context, dir string, pod *v1.Pod, container string) error {
+	logs, err := mg.logs.GetPodLogs(ctx, pod.Namespace, pod.Name, container, false)
+	if err != nil {
+		mg.addError("could not get the logs of %s/%s, container %s: %v", pod.Namespace, pod.Name, container, err)
+	} else if err = mg.writeFile(path.Join(dir, container+".log"), logs); err != nil {
+		return err
+	}
+
+	if !restarted(pod, container) {
+		return nil
+	}
+
+	logs, err = mg.logs.GetPodLogs(ctx, pod.Namespace, pod.Name, container, true)
+	if err != nil {
+		mg.addError("could not get the previous logs of %s/%s, container %s: %v", pod.Namespace, pod.Name, container, err)
+		return nil
+	}
+
+	return mg.writeFile(path.Join(dir, container+".previous.log"), logs)
+}
+
+func restarted(pod *v1.Pod, container string) bool {
+	for _, statuses := range [][]v1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for _, cs := range statuses {
+			if cs.Name == container {
+				return cs.RestartCount > 0
+			}
+		}
+	}
+
+	return false
+}
+
+// list lists objects into list and writes them to name.
+// Listing errors are recorded in the bundle; only write errors are returned.
+func (mg *mustGatherer) list(ctx context.Context, name string, list client.ObjectList, opts ...client.ListOption) error {
+	if err := mg.client.List(ctx, list, opts...); err != nil {
+		mg.addError("could not list %s: %v", name, err)
+		return nil
+	}
+
+	return mg.writeJSON(name, list)
+}
+
+func (mg *mustGatherer) addError(format string, args ...interface{}) {
+	mg.errs = append(mg.errs, fmt.Sprintf(format, args...))
+}
+
+func (mg *mustGatherer) writeJSON(name string, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode %s: %v", name, err)
+	}
+
+	return mg.writeFile(name, append(b, '\n'))
+}
+
+func (mg *mustGatherer) writeFile(name string, data []byte) error {
+	hdr := tar.Header{
+		Name:    path.Join(mustGatherDir, name),
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: mg.now,
+	}
+
+	if err := mg.tw.WriteHeader(&hdr); err != nil {
+		return fmt.Errorf("could not write the header of %s: %v", name, err)
+	}
+
+	if _, err := mg.tw.Write(data); err != nil {
+		return fmt.Errorf("could not write %s: %v", name, err)
+	}
+
+	return nil
+}
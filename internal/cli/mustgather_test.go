@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/client"
+)
+
+var _ = Describe("MustGather", func() {
+	var (
+		ctrl *gomock.Controller
+		clnt *client.MockClient
+		logs *MockPodLogGetter
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+		logs = NewMockPodLogGetter(ctrl)
+	})
+
+	readTarball := func(b []byte) map[string]string {
+		gzr, err := gzip.NewReader(bytes.NewReader(b))
+		Expect(err).NotTo(HaveOccurred())
+
+		tr := tar.NewReader(gzr)
+		files := make(map[string]string)
+
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				return files
+			}
+			Expect(err).NotTo(HaveOccurred())
+
+			data, err := io.ReadAll(tr)
+			Expect(err).NotTo(HaveOccurred())
+
+			files[hdr.Name] = string(data)
+		}
+	}
+
+	It("should collect objects and logs, and record errors", func() {
+		ctx := context.Background()
+
+		clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, list interface{}, opts ...interface{}) error {
+				switch l := list.(type) {
+				case *kmmv1beta1.ModuleList:
+					l.Items = []kmmv1beta1.Module{
+						{ObjectMeta: metav1.ObjectMeta{Name: "mod", Namespace: "ns"}},
+					}
+				case *v1.NodeList:
+					l.Items = []v1.Node{
+						{
+							ObjectMeta: metav1.ObjectMeta{Name: "node"},
+							Status: v1.NodeStatus{
+								NodeInfo: v1.NodeSystemInfo{KernelVersion: "5.14.0"},
+								Images:   []v1.ContainerImage{{Names: []string{"some-image"}}},
+							},
+						},
+					}
+				case *v1.PodList:
+					l.Items = []v1.Pod{
+						{
+							ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "ns"},
+							Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "module-loader"}}},
+							Status: v1.PodStatus{
+								ContainerStatuses: []v1.ContainerStatus{{Name: "module-loader", RestartCount: 1}},
+							},
+						},
+					}
+				case *v1.EventList:
+					return errors.New("some error")
+				}
+
+				return nil
+			},
+		).AnyTimes()
+
+		gomock.InOrder(
+			logs.EXPECT().GetPodLogs(ctx, "ns", "pod", "module-loader", false).Return([]byte("current logs"), nil),
+			logs.EXPECT().GetPodLogs(ctx, "ns", "pod", "module-loader", true).Return(nil, errors.New("no previous logs")),
+		)
+
+		var buf bytes.Buffer
+
+		Expect(MustGather(ctx, clnt, logs, "", &buf)).To(Succeed())
+
+		files := readTarball(buf.Bytes())
+
+		Expect(files).To(HaveKey("kmm-must-gather/preflightvalidations.json"))
+		Expect(files).To(HaveKey("kmm-must-gather/namespaces/ns/daemonsets.json"))
+		Expect(files).To(HaveKey("kmm-must-gather/namespaces/ns/jobs.json"))
+		Expect(files).To(HaveKey("kmm-must-gather/namespaces/ns/pods.json"))
+		Expect(files).NotTo(HaveKey("kmm-must-gather/namespaces/ns/events.json"))
+		Expect(files["kmm-must-gather/modules.json"]).To(ContainSubstring(`"name": "mod"`))
+		Expect(files["kmm-must-gather/nodes.json"]).To(ContainSubstring(`"kernelVersion": "5.14.0"`))
+		Expect(files["kmm-must-gather/nodes.json"]).NotTo(ContainSubstring("some-image"))
+		Expect(files["kmm-must-gather/namespaces/ns/logs/pod/module-loader.log"]).To(Equal("current logs"))
+		Expect(files["kmm-must-gather/errors.txt"]).To(ContainSubstring("events.json"))
+		Expect(files["kmm-must-gather/errors.txt"]).To(ContainSubstring("no previous logs"))
+	})
+
+	It("should also collect the operator's namespace", func() {
+		ctx := context.Background()
+
+		clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).AnyTimes()
+
+		var buf bytes.Buffer
+
+		Expect(MustGather(ctx, clnt, logs, "kmm-operator-system", &buf)).To(Succeed())
+
+		files := readTarball(buf.Bytes())
+
+		Expect(files).To(HaveKey("kmm-must-gather/namespaces/kmm-operator-system/deployments.json"))
+		Expect(files).NotTo(HaveKey("kmm-must-gather/errors.txt"))
+	})
+})
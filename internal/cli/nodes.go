@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+)
+
+const (
+	// ReasonNoMapping means that no kernel mapping matches the kernel of the node.
+	ReasonNoMapping = "NoMapping"
+	// ReasonBuildPending means that the image for the kernel of the node is being built.
+	ReasonBuildPending = "BuildPending"
+	// ReasonBuildFailed means that the image for the kernel of the node could not be built.
+	ReasonBuildFailed = "BuildFailed"
+	// ReasonSignPending means that the image for the kernel of the node is being signed.
+	ReasonSignPending = "SignPending"
+	// ReasonSignFailed means that the image for the kernel of the node could not be signed.
+	ReasonSignFailed = "SignFailed"
+	// ReasonPodNotReady means that the module-loader pod of the node is not ready.
+	ReasonPodNotReady = "PodNotReady"
+)
+
+// MissingNode is a node targeted by a Module on which the kernel module is not loaded.
+type MissingNode struct {
+	Node    string
+	Kernel  string
+	Reason  string
+	Message string
+}
+
+// GetMissingNodes returns the nodes targeted by a Module on which its kernel module is not loaded, along with the
+// reason: no kernel mapping for the kernel of the node, build or signing pending or failed, module-loader pod missing
+// or not ready, or module rejected by the kernel.
+func GetMissingNodes(
+	ctx context.Context,
+	c client.Client,
+	kernelAPI module.KernelMapper,
+	name string,
+	namespace string) ([]MissingNode, error) {
+	mod := kmmv1beta1.Module{}
+
+	if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &mod); err != nil {
+		return nil, fmt.Errorf("could not get Module %s/%s: %v", namespace, name, err)
+	}
+
+	ms, err := moduleStatus(ctx, c, &mod)
+	if err != nil {
+		return nil, err
+	}
+
+	kernels := make(map[string]KernelStatus, len(ms.Kernels))
+
+	for _, k := range ms.Kernels {
+		kernels[k.Kernel] = k
+	}
+
+	missing := make([]MissingNode, 0)
+
+	for _, ns := range ms.Nodes {
+		if ns.State == StateLoaded {
+			continue
+		}
+
+		mn := MissingNode{Node: ns.Node, Kernel: ns.Kernel}
+		mn.Reason, mn.Message = missingReason(kernelAPI, &mod, ns, kernels[ns.Kernel])
+
+		missing = append(missing, mn)
+	}
+
+	return missing, nil
+}
+
+func missingReason(kernelAPI module.KernelMapper, mod *kmmv1beta1.Module, ns NodeStatus, ks KernelStatus) (string, string) {
+	kernel := strings.TrimSuffix(ns.Kernel, "+")
+
+	if _, err := kernelAPI.FindMappingForKernel(mod.Spec.ModuleLoader.Container.KernelMappings, kernel); err != nil {
+		return ReasonNoMapping, fmt.Sprintf("no kernel mapping matches kernel %s", kernel)
+	}
+
+	switch ks.Build {
+	case jobRunning, jobPending:
+		return ReasonBuildPending, "the image is being built"
+	case jobFailed:
+		return ReasonBuildFailed, fmt.Sprintf("the build failed; run kubectl kmm rebuild -kernel %s %s once fixed", kernel, mod.Name)
+	}
+
+	switch ks.Sign {
+	case jobRunning, jobPending:
+		return ReasonSignPending, "the image is being signed"
+	case jobFailed:
+		return ReasonSignFailed, fmt.Sprintf("the signing failed; run kubectl kmm rebuild -kernel %s %s once fixed", kernel, mod.Name)
+	}
+
+	switch ns.State {
+	case StateNoPod:
+		if ks.DaemonSet == none {
+			return StateNoPod, "no module-loader DaemonSet exists for the kernel yet"
+		}
+
+		return StateNoPod, "no module-loader pod is scheduled on the node"
+	case StateNotReady:
+		return ReasonPodNotReady, ns.Message
+	default:
+		// the kernel rejected the module
+		return ns.State, ns.Message
+	}
+}
+
+// PrintMissingNodes writes nodes to w as a table.
+func PrintMissingNodes(w io.Writer, nodes []MissingNode) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "NODE\tKERNEL\tREASON\tMESSAGE")
+
+	for _, n := range nodes {
+		msg := n.Message
+		if msg == "" {
+			msg = none
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", n.Node, n.Kernel, n.Reason, msg)
+	}
+
+	return tw.Flush()
+}
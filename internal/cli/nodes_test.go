@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/client"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+)
+
+var _ = Describe("GetMissingNodes", func() {
+	const (
+		moduleName = "some-module"
+		namespace  = "some-namespace"
+		kernel1    = "5.14.0-1"
+		kernel2    = "5.14.0-2"
+		kernel3    = "5.14.0-3"
+	)
+
+	var (
+		ctrl *gomock.Controller
+		clnt *client.MockClient
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+	})
+
+	ctx := context.Background()
+	nsn := types.NamespacedName{Name: moduleName, Namespace: namespace}
+
+	It("should return an error if the Module cannot be fetched", func() {
+		clnt.EXPECT().Get(ctx, nsn, gomock.Any()).Return(errors.New("some error"))
+
+		_, err := GetMissingNodes(ctx, clnt, module.NewKernelMapper(), moduleName, namespace)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should explain why the module is not loaded on each node", func() {
+		node := func(name, kernel string, labels map[string]string) v1.Node {
+			return v1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+				Status:     v1.NodeStatus{NodeInfo: v1.NodeSystemInfo{KernelVersion: kernel}},
+			}
+		}
+
+		readyLabel := map[string]string{"kmm.node.kubernetes.io/some-module.ready": ""}
+
+		nodes := []v1.Node{
+			node("loaded", kernel1, readyLabel),
+			node("crash-looping", kernel1, nil),
+			node("no-mapping", "4.18.0-1", nil),
+			node("build-failed", kernel2, nil),
+			node("no-daemonset", kernel3, nil),
+		}
+
+		ds := appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{constants.KernelLabel: kernel1}},
+			Status:     appsv1.DaemonSetStatus{NumberReady: 1, DesiredNumberScheduled: 2},
+		}
+
+		failedBuild := batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{constants.JobType: "build", constants.TargetKernelTarget: kernel2},
+			},
+			Status: batchv1.JobStatus{Failed: 1},
+		}
+
+		ready := []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}}
+
+		pods := []v1.Pod{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "pod-loaded"},
+				Spec:       v1.PodSpec{NodeName: "loaded"},
+				Status:     v1.PodStatus{Phase: v1.PodRunning, Conditions: ready},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "pod-crash-looping"},
+				Spec:       v1.PodSpec{NodeName: "crash-looping"},
+				Status: v1.PodStatus{
+					Phase: v1.PodRunning,
+					ContainerStatuses: []v1.ContainerStatus{
+						{
+							Name:  "module-loader",
+							State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+						},
+					},
+				},
+			},
+		}
+
+		gomock.InOrder(
+			clnt.EXPECT().Get(ctx, nsn, gomock.Any()).DoAndReturn(
+				func(_ interface{}, _ interface{}, m *kmmv1beta1.Module, _ ...ctrlclient.GetOption) error {
+					m.Name = moduleName
+					m.Namespace = namespace
+					m.Spec.ModuleLoader.Container.KernelMappings = []kmmv1beta1.KernelMapping{
+						{Regexp: `^5\.14\.0-.+$`, ContainerImage: "some-image"},
+					}
+					return nil
+				},
+			),
+			clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ interface{}, list *v1.NodeList, _ ...interface{}) error {
+					list.Items = nodes
+					return nil
+				},
+			),
+			clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ interface{}, list *appsv1.DaemonSetList, _ ...interface{}) error {
+					list.Items = []appsv1.DaemonSet{ds}
+					return nil
+				},
+			),
+			clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ interface{}, list *batchv1.JobList, _ ...interface{}) error {
+					list.Items = []batchv1.Job{failedBuild}
+					return nil
+				},
+			),
+			clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ interface{}, list *v1.PodList, _ ...interface{}) error {
+					list.Items = pods
+					return nil
+				},
+			),
+		)
+
+		missing, err := GetMissingNodes(ctx, clnt, module.NewKernelMapper(), moduleName, namespace)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(missing).To(Equal([]MissingNode{
+			{
+				Node:    "build-failed",
+				Kernel:  kernel2,
+				Reason:  ReasonBuildFailed,
+				Message: "the build failed; run kubectl kmm rebuild -kernel 5.14.0-2 some-module once fixed",
+			},
+			{
+				Node:    "crash-looping",
+				Kernel:  kernel1,
+				Reason:  ReasonPodNotReady,
+				Message: "container module-loader is waiting: CrashLoopBackOff",
+			},
+			{
+				Node:    "no-daemonset",
+				Kernel:  kernel3,
+				Reason:  StateNoPod,
+				Message: "no module-loader DaemonSet exists for the kernel yet",
+			},
+			{
+				Node:    "no-mapping",
+				Kernel:  "4.18.0-1",
+				Reason:  ReasonNoMapping,
+				Message: "no kernel mapping matches kernel 4.18.0-1",
+			},
+		}))
+	})
+})
+
+var _ = Describe("PrintMissingNodes", func() {
+	It("should print a table", func() {
+		var buf bytes.Buffer
+
+		nodes := []MissingNode{{Node: "node", Kernel: "5.14.0", Reason: ReasonBuildPending, Message: "the image is being built"}}
+
+		Expect(PrintMissingNodes(&buf, nodes)).To(Succeed())
+		Expect(buf.String()).To(MatchRegexp(`(?m)^NODE\s+KERNEL\s+REASON\s+MESSAGE$`))
+		Expect(buf.String()).To(MatchRegexp(`(?m)^node\s+5\.14\.0\s+BuildPending\s+the image is being built$`))
+	})
+})
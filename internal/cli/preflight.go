@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"text/tabwriter"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/registry"
+)
+
+const (
+	// PreflightReady means that the module-loader image for the kernel already exists.
+	PreflightReady = "Ready"
+	// PreflightBuildRequired means that the image does not exist, but KMM will build it.
+	PreflightBuildRequired = "BuildRequired"
+	// PreflightSignRequired means that the image does not exist, but KMM will sign it.
+	PreflightSignRequired = "SignRequired"
+	// PreflightMissing means that the Module cannot be loaded on the kernel.
+	PreflightMissing = "Missing"
+	// PreflightError means that the image could not be checked.
+	PreflightError = "Error"
+)
+
+// The kernel variables substituted in images need at least three numbers.
+var kernelVersionRegexp = regexp.MustCompile(`^\d+\.\d+\.\d+`)
+
+// PreflightResult is the readiness of one Module for a kernel.
+type PreflightResult struct {
+	Namespace string
+	Name      string
+	Image     string
+	State     string
+	Message   string
+}
+
+// Preflight checks whether all Modules in namespace, or in all namespaces if namespace is empty, can be loaded on
+// nodes running kernel.
+// Unlike the PreflightValidation resource, it does not start any build or sign Job: it only checks whether the
+// images exist in their registry and whether KMM would build or sign the missing ones.
+func Preflight(
+	ctx context.Context,
+	c client.Client,
+	reg registry.Registry,
+	kernelAPI module.KernelMapper,
+	kernel string,
+	namespace string) ([]PreflightResult, error) {
+	if !kernelVersionRegexp.MatchString(kernel) {
+		return nil, fmt.Errorf("invalid kernel version %q", kernel)
+	}
+
+	mods := kmmv1beta1.ModuleList{}
+
+	if err := c.List(ctx, &mods, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("could not list Modules: %v", err)
+	}
+
+	osConfig := kernelAPI.GetNodeOSConfigFromKernelVersion(kernel)
+
+	results := make([]PreflightResult, 0, len(mods.Items))
+
+	for i := 0; i < len(mods.Items); i++ {
+		results = append(results, preflightModule(ctx, c, reg, kernelAPI, &mods.Items[i], kernel, osConfig))
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Namespace != results[j].Namespace {
+			return results[i].Namespace < results[j].Namespace
+		}
+
+		return results[i].Name < results[j].Name
+	})
+
+	return results, nil
+}
+
+func preflightModule(
+	ctx context.Context,
+	c client.Client,
+	reg registry.Registry,
+	kernelAPI module.KernelMapper,
+	mod *kmmv1beta1.Module,
+	kernel string,
+	osConfig *module.NodeOSConfig) PreflightResult {
+	res := PreflightResult{Namespace: mod.Namespace, Name: mod.Name, Image: none}
+
+	m, err := kernelAPI.FindMappingForKernel(mod.Spec.ModuleLoader.Container.KernelMappings, kernel)
+	if err != nil {
+		res.State = PreflightMissing
+		res.Message = "no kernel mapping matches the kernel"
+		return res
+	}
+
+	m, err = kernelAPI.PrepareKernelMapping(m, osConfig)
+	if err != nil {
+		res.State = PreflightError
+		res.Message = fmt.Sprintf("could not substitute the kernel variables: %v", err)
+		return res
+	}
+
+	res.Image = m.ContainerImage
+
+	exists, err := module.ImageExists(ctx, c, reg, mod.Spec, mod.Namespace, *m, m.ContainerImage)
+	if err != nil {
+		res.State = PreflightError
+		res.Message = err.Error()
+		return res
+	}
+
+	switch {
+	case exists:
+		res.State = PreflightReady
+	case module.ShouldBeBuilt(mod.Spec, *m):
+		res.State = PreflightBuildRequired
+		res.Message = "the image does not exist; KMM will build it"
+	case module.ShouldBeSigned(mod.Spec, *m):
+		res.State = PreflightSignRequired
+		res.Message = "the image does not exist; KMM will sign it"
+	default:
+		res.State = PreflightMissing
+		res.Message = "the image does not exist and no build or sign is configured"
+	}
+
+	return res
+}
+
+// PreflightPassed returns true if none of results prevents the Modules from being loaded on the kernel.
+// If strict is true, Modules whose image still needs to be built or signed are not considered ready either.
+func PreflightPassed(results []PreflightResult, strict bool) bool {
+	for _, r := range results {
+		switch r.State {
+		case PreflightReady:
+		case PreflightBuildRequired, PreflightSignRequired:
+			if strict {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// PrintPreflightResults writes results to w as a table.
+func PrintPreflightResults(w io.Writer, kernel string, results []PreflightResult) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Kernel:\t%s\n", kernel)
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "NAMESPACE\tMODULE\tIMAGE\tSTATE\tMESSAGE")
+
+	for _, r := range results {
+		msg := r.Message
+		if msg == "" {
+			msg = none
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.Namespace, r.Name, r.Image, r.State, msg)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/client"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/registry"
+)
+
+var _ = Describe("Preflight", func() {
+	const kernel = "5.14.0-1.el9.x86_64"
+
+	var (
+		ctrl *gomock.Controller
+		clnt *client.MockClient
+		reg  *registry.MockRegistry
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+		reg = registry.NewMockRegistry(ctrl)
+	})
+
+	It("should reject invalid kernel versions", func() {
+		_, err := Preflight(context.Background(), clnt, reg, module.NewKernelMapper(), "5.14", "")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return an error if the Modules cannot be listed", func() {
+		clnt.EXPECT().List(context.Background(), gomock.Any(), gomock.Any()).Return(errors.New("some error"))
+
+		_, err := Preflight(context.Background(), clnt, reg, module.NewKernelMapper(), kernel, "")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should report the state of each Module", func() {
+		mod := func(namespace, name string, km kmmv1beta1.KernelMapping) kmmv1beta1.Module {
+			m := kmmv1beta1.Module{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+			m.Spec.ModuleLoader.Container.KernelMappings = []kmmv1beta1.KernelMapping{km}
+			return m
+		}
+
+		mods := []kmmv1beta1.Module{
+			mod("ns-b", "ready", kmmv1beta1.KernelMapping{Literal: kernel, ContainerImage: "ready:${KERNEL_FULL_VERSION}"}),
+			mod("ns-a", "build", kmmv1beta1.KernelMapping{Regexp: ".*", ContainerImage: "build", Build: &kmmv1beta1.Build{}}),
+			mod("ns-a", "no-mapping", kmmv1beta1.KernelMapping{Literal: "4.18.0", ContainerImage: "no-mapping"}),
+			mod("ns-a", "missing", kmmv1beta1.KernelMapping{Regexp: ".*", ContainerImage: "missing"}),
+			mod("ns-a", "error", kmmv1beta1.KernelMapping{Regexp: ".*", ContainerImage: "error"}),
+		}
+
+		ctx := context.Background()
+
+		clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, list *kmmv1beta1.ModuleList, _ ...interface{}) error {
+				list.Items = mods
+				return nil
+			},
+		)
+		reg.EXPECT().ImageExists(ctx, "ready:"+kernel, gomock.Any(), nil).Return(true, nil)
+		reg.EXPECT().ImageExists(ctx, "build", gomock.Any(), nil).Return(false, nil)
+		reg.EXPECT().ImageExists(ctx, "missing", gomock.Any(), nil).Return(false, nil)
+		reg.EXPECT().ImageExists(ctx, "error", gomock.Any(), nil).Return(false, errors.New("some error"))
+
+		results, err := Preflight(ctx, clnt, reg, module.NewKernelMapper(), kernel, "")
+		Expect(err).NotTo(HaveOccurred())
+
+		states := make(map[string]string, len(results))
+		names := make([]string, 0, len(results))
+
+		for _, r := range results {
+			states[r.Name] = r.State
+			names = append(names, r.Namespace+"/"+r.Name)
+		}
+
+		Expect(names).To(Equal([]string{"ns-a/build", "ns-a/error", "ns-a/missing", "ns-a/no-mapping", "ns-b/ready"}))
+		Expect(states).To(Equal(map[string]string{
+			"build":      PreflightBuildRequired,
+			"error":      PreflightError,
+			"missing":    PreflightMissing,
+			"no-mapping": PreflightMissing,
+			"ready":      PreflightReady,
+		}))
+		Expect(results[4].Image).To(Equal("ready:" + kernel))
+	})
+})
+
+var _ = Describe("PreflightPassed", func() {
+	DescribeTable("should only pass if all Modules can be loaded",
+		func(states []string, strict, expected bool) {
+			results := make([]PreflightResult, 0, len(states))
+
+			for _, s := range states {
+				results = append(results, PreflightResult{State: s})
+			}
+
+			Expect(PreflightPassed(results, strict)).To(Equal(expected))
+		},
+		Entry("no Modules", nil, true, true),
+		Entry("all ready", []string{PreflightReady, PreflightReady}, true, true),
+		Entry("build required", []string{PreflightReady, PreflightBuildRequired}, false, true),
+		Entry("build required, strict", []string{PreflightReady, PreflightBuildRequired}, true, false),
+		Entry("sign required, strict", []string{PreflightSignRequired}, true, false),
+		Entry("missing", []string{PreflightReady, PreflightMissing}, false, false),
+		Entry("error", []string{PreflightError}, false, false),
+	)
+})
+
+var _ = Describe("PrintPreflightResults", func() {
+	It("should print a table", func() {
+		results := []PreflightResult{
+			{Namespace: "ns", Name: "mod", Image: "img", State: PreflightReady},
+		}
+
+		var buf bytes.Buffer
+
+		Expect(PrintPreflightResults(&buf, "5.14.0", results)).To(Succeed())
+		Expect(buf.String()).To(MatchRegexp(`(?m)^NAMESPACE\s+MODULE\s+IMAGE\s+STATE\s+MESSAGE$`))
+		Expect(buf.String()).To(MatchRegexp(`(?m)^ns\s+mod\s+img\s+Ready\s+-$`))
+	})
+})
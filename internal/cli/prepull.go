@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/daemonset"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+)
+
+const (
+	// PrePullRole is the value of the DaemonSetRole label of the pre-pull pods.
+	PrePullRole = "prepull"
+
+	// PrePullPending means that the images are still being pulled.
+	PrePullPending = "Pulling"
+	// PrePullDone means that all the images were pulled.
+	PrePullDone = "Pulled"
+	// PrePullFailed means that an image could not be pulled.
+	PrePullFailed = "Failed"
+)
+
+// pullErrorReasons are the reasons of waiting containers whose image cannot be pulled.
+var pullErrorReasons = map[string]bool{
+	"ErrImagePull":     true,
+	"ImagePullBackOff": true,
+	"InvalidImageName": true,
+}
+
+// PrePullPods returns the pods pulling the module-loader and device-plugin images of a Module on each node that it
+// targets and that nodeSelector selects, so that the images are in the node's cache before the Module is deployed
+// there.
+// The pods are not created. They are bound to their node, tolerate all taints and run true in each image; whether true
+// exists in the image does not matter, as the image is pulled anyway.
+// The returned warnings list the selected nodes for which no kernel mapping matches.
+func PrePullPods(
+	ctx context.Context,
+	c client.Client,
+	kernelAPI module.KernelMapper,
+	name string,
+	namespace string,
+	nodeSelector map[string]string) ([]v1.Pod, []string, error) {
+	mod := kmmv1beta1.Module{}
+
+	if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &mod); err != nil {
+		return nil, nil, fmt.Errorf("could not get Module %s/%s: %v", namespace, name, err)
+	}
+
+	selector := labels.Merge(mod.Spec.Selector, nodeSelector)
+
+	nodes := v1.NodeList{}
+
+	if err := c.List(ctx, &nodes, client.MatchingLabels(selector)); err != nil {
+		return nil, nil, fmt.Errorf("could not list nodes: %v", err)
+	}
+
+	if len(nodes.Items) == 0 {
+		return nil, nil, fmt.Errorf("no node matches %s", labels.SelectorFromSet(selector))
+	}
+
+	pods := make([]v1.Pod, 0, len(nodes.Items))
+	warnings := make([]string, 0)
+
+	for i := 0; i < len(nodes.Items); i++ {
+		node := &nodes.Items[i]
+		kernel := strings.TrimSuffix(node.Status.NodeInfo.KernelVersion, "+")
+
+		m, err := kernelAPI.FindMappingForNode(mod.Spec.ModuleLoader.Container.KernelMappings, node)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("node %s: no kernel mapping matches kernel %s", node.Name, kernel))
+			continue
+		}
+
+		m, err = kernelAPI.PrepareKernelMapping(m, kernelAPI.GetNodeOSConfig(node))
+		if err != nil {
+			return nil, nil, fmt.Errorf("node %s: could not substitute the kernel variables: %v", node.Name, err)
+		}
+
+		pods = append(pods, prePullPod(&mod, node.Name, m.ContainerImage))
+	}
+
+	return pods, warnings, nil
+}
+
+func prePullPod(mod *kmmv1beta1.Module, nodeName, image string) v1.Pod {
+	containers := []v1.Container{
+		{
+			Name:            "module-loader",
+			Image:           image,
+			ImagePullPolicy: mod.Spec.ModuleLoader.Container.ImagePullPolicy,
+			Command:         []string{"true"},
+		},
+	}
+
+	if dp := mod.Spec.DevicePlugin; dp != nil {
+		containers = append(containers, v1.Container{
+			Name:            "device-plugin",
+			Image:           dp.Container.Image,
+			ImagePullPolicy: dp.Container.ImagePullPolicy,
+			Command:         []string{"true"},
+		})
+	}
+
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: mod.Name + "-prepull-",
+			Namespace:    mod.Namespace,
+			Labels: map[string]string{
+				constants.ModuleNameLabel: mod.Name,
+				constants.DaemonSetRole:   PrePullRole,
+			},
+		},
+		Spec: v1.PodSpec{
+			NodeName:         nodeName,
+			Containers:       containers,
+			ImagePullSecrets: daemonset.GetPodPullSecrets(mod.Spec.ImageRepoSecret),
+			RestartPolicy:    v1.RestartPolicyNever,
+			Tolerations:      []v1.Toleration{{Operator: v1.TolerationOpExists}},
+		},
+	}
+}
+
+// PrePullState returns the state of a pre-pull pod, along with the reason why an image could not be pulled.
+func PrePullState(pod *v1.Pod) (string, string) {
+	if len(pod.Status.ContainerStatuses) < len(pod.Spec.Containers) {
+		return PrePullPending, ""
+	}
+
+	for _, cs := range pod.Status.ContainerStatuses {
+		if w := cs.State.Waiting; w != nil {
+			if pullErrorReasons[w.Reason] {
+				return PrePullFailed, fmt.Sprintf("%s: %s", cs.Image, w.Message)
+			}
+
+			return PrePullPending, ""
+		}
+	}
+
+	// all containers are running or terminated, which means that their images were pulled
+	return PrePullDone, ""
+}
+
+// PrintPrePullStates writes the state of each pre-pull pod to w as a table.
+func PrintPrePullStates(w io.Writer, pods []v1.Pod) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "NODE\tSTATE\tMESSAGE")
+
+	for i := 0; i < len(pods); i++ {
+		state, msg := PrePullState(&pods[i])
+		if msg == "" {
+			msg = none
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", pods[i].Spec.NodeName, state, msg)
+	}
+
+	return tw.Flush()
+}
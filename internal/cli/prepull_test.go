@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"context"
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/client"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+)
+
+var _ = Describe("PrePullPods", func() {
+	const (
+		moduleName = "some-module"
+		namespace  = "some-namespace"
+	)
+
+	var (
+		ctrl *gomock.Controller
+		clnt *client.MockClient
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+	})
+
+	ctx := context.Background()
+	nsn := types.NamespacedName{Name: moduleName, Namespace: namespace}
+
+	expectGetModule := func() *gomock.Call {
+		return clnt.EXPECT().Get(ctx, nsn, gomock.Any()).DoAndReturn(
+			func(_ interface{}, _ interface{}, m *kmmv1beta1.Module, _ ...ctrlclient.GetOption) error {
+				m.Name = moduleName
+				m.Namespace = namespace
+				m.Spec.Selector = map[string]string{"has-gpu": "true"}
+				m.Spec.ImageRepoSecret = &v1.LocalObjectReference{Name: "pull-secret"}
+				m.Spec.ModuleLoader.Container.KernelMappings = []kmmv1beta1.KernelMapping{
+					{Regexp: `^5\.14\.0-.+$`, ContainerImage: "quay.io/org/kmod:${KERNEL_FULL_VERSION}"},
+				}
+				m.Spec.DevicePlugin = &kmmv1beta1.DevicePluginSpec{
+					Container: kmmv1beta1.DevicePluginContainerSpec{Image: "quay.io/org/device-plugin"},
+				}
+				return nil
+			},
+		)
+	}
+
+	It("should return an error if the Module cannot be fetched", func() {
+		clnt.EXPECT().Get(ctx, nsn, gomock.Any()).Return(errors.New("some error"))
+
+		_, _, err := PrePullPods(ctx, clnt, module.NewKernelMapper(), moduleName, namespace, nil)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return an error if no node is selected", func() {
+		gomock.InOrder(
+			expectGetModule(),
+			clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()),
+		)
+
+		_, _, err := PrePullPods(ctx, clnt, module.NewKernelMapper(), moduleName, namespace, nil)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return a pod per selected node with a kernel mapping", func() {
+		node := func(name, kernel string) v1.Node {
+			return v1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Status:     v1.NodeStatus{NodeInfo: v1.NodeSystemInfo{KernelVersion: kernel}},
+			}
+		}
+
+		gomock.InOrder(
+			expectGetModule(),
+			clnt.EXPECT().List(
+				ctx,
+				gomock.Any(),
+				ctrlclient.MatchingLabels{"has-gpu": "true", "pool": "new"},
+			).DoAndReturn(
+				func(_ interface{}, list *v1.NodeList, _ ...interface{}) error {
+					list.Items = []v1.Node{node("node-1", "5.14.0-1"), node("node-2", "4.18.0-1")}
+					return nil
+				},
+			),
+		)
+
+		pods, warnings, err := PrePullPods(
+			ctx,
+			clnt,
+			module.NewKernelMapper(),
+			moduleName,
+			namespace,
+			map[string]string{"pool": "new"},
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(Equal([]string{"node node-2: no kernel mapping matches kernel 4.18.0-1"}))
+		Expect(pods).To(HaveLen(1))
+
+		pod := pods[0]
+		Expect(pod.Namespace).To(Equal(namespace))
+		Expect(pod.Labels).To(HaveKeyWithValue(constants.DaemonSetRole, PrePullRole))
+		Expect(pod.Spec.NodeName).To(Equal("node-1"))
+		Expect(pod.Spec.ImagePullSecrets).To(Equal([]v1.LocalObjectReference{{Name: "pull-secret"}}))
+		Expect(pod.Spec.Containers).To(HaveLen(2))
+		Expect(pod.Spec.Containers[0].Image).To(Equal("quay.io/org/kmod:5.14.0-1"))
+		Expect(pod.Spec.Containers[1].Image).To(Equal("quay.io/org/device-plugin"))
+	})
+})
+
+var _ = DescribeTable("PrePullState",
+	func(statuses []v1.ContainerStatus, expected string) {
+		pod := v1.Pod{
+			Spec:   v1.PodSpec{Containers: []v1.Container{{Name: "module-loader"}}},
+			Status: v1.PodStatus{ContainerStatuses: statuses},
+		}
+
+		state, _ := PrePullState(&pod)
+		Expect(state).To(Equal(expected))
+	},
+	Entry("no container status yet", nil, PrePullPending),
+	Entry(
+		"image being pulled",
+		[]v1.ContainerStatus{{State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ContainerCreating"}}}},
+		PrePullPending,
+	),
+	Entry(
+		"image cannot be pulled",
+		[]v1.ContainerStatus{{State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}}},
+		PrePullFailed,
+	),
+	Entry(
+		"container terminated",
+		[]v1.ContainerStatus{{State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{Reason: "StartError"}}}},
+		PrePullDone,
+	),
+)
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+)
+
+// RequestRebuild asks the operator to delete and create again the failed build and sign Jobs of a Module for kernel,
+// by adding kernel to the RebuildKernelsAnnotation of the Module.
+// It returns the names of the failed Jobs, and an error if there are none.
+func RequestRebuild(ctx context.Context, c client.Client, name, namespace, kernel string) ([]string, error) {
+	kernel = strings.TrimSuffix(kernel, "+")
+
+	if !kernelVersionRegexp.MatchString(kernel) {
+		return nil, fmt.Errorf("invalid kernel version %q", kernel)
+	}
+
+	mod := kmmv1beta1.Module{}
+
+	if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &mod); err != nil {
+		return nil, fmt.Errorf("could not get Module %s/%s: %v", namespace, name, err)
+	}
+
+	jobs := batchv1.JobList{}
+
+	opts := []client.ListOption{
+		client.InNamespace(namespace),
+		client.MatchingLabels{constants.ModuleNameLabel: name, constants.TargetKernelTarget: kernel},
+	}
+
+	if err := c.List(ctx, &jobs, opts...); err != nil {
+		return nil, fmt.Errorf("could not list Jobs: %v", err)
+	}
+
+	failed := make([]string, 0)
+
+	for i := 0; i < len(jobs.Items); i++ {
+		j := &jobs.Items[i]
+
+		if j.Status.Failed > 0 && metav1.IsControlledBy(j, &mod) {
+			failed = append(failed, j.Name)
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil, fmt.Errorf("no failed build or sign Job for Module %s/%s and kernel %s", namespace, name, kernel)
+	}
+
+	sort.Strings(failed)
+
+	kernels := sets.NewString(kernel)
+
+	for _, k := range strings.Split(mod.Annotations[constants.RebuildKernelsAnnotation], ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			kernels.Insert(k)
+		}
+	}
+
+	modCopy := mod.DeepCopy()
+
+	if mod.Annotations == nil {
+		mod.Annotations = make(map[string]string)
+	}
+
+	mod.Annotations[constants.RebuildKernelsAnnotation] = strings.Join(kernels.List(), ",")
+
+	if err := c.Patch(ctx, &mod, client.MergeFrom(modCopy)); err != nil {
+		return nil, fmt.Errorf("could not annotate Module %s/%s: %v", namespace, name, err)
+	}
+
+	return failed, nil
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"context"
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/client"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+)
+
+var _ = Describe("RequestRebuild", func() {
+	const (
+		moduleName = "some-module"
+		namespace  = "some-namespace"
+		moduleUID  = "some-uid"
+		kernel     = "5.14.0-1"
+	)
+
+	var (
+		ctrl *gomock.Controller
+		clnt *client.MockClient
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+	})
+
+	ctx := context.Background()
+	nsn := types.NamespacedName{Name: moduleName, Namespace: namespace}
+
+	job := func(name string, failed int32) batchv1.Job {
+		return batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				OwnerReferences: []metav1.OwnerReference{{UID: moduleUID, Controller: pointer.Bool(true)}},
+			},
+			Status: batchv1.JobStatus{Failed: failed},
+		}
+	}
+
+	expectGetModule := func(annotations map[string]string) {
+		clnt.EXPECT().Get(ctx, nsn, gomock.Any()).DoAndReturn(
+			func(_ interface{}, _ interface{}, mod *kmmv1beta1.Module, _ ...ctrlclient.GetOption) error {
+				mod.Name = moduleName
+				mod.Namespace = namespace
+				mod.UID = moduleUID
+				mod.Annotations = annotations
+				return nil
+			},
+		)
+	}
+
+	expectListJobs := func(jobs ...batchv1.Job) {
+		clnt.EXPECT().List(
+			ctx,
+			gomock.Any(),
+			ctrlclient.InNamespace(namespace),
+			ctrlclient.MatchingLabels{constants.ModuleNameLabel: moduleName, constants.TargetKernelTarget: kernel},
+		).DoAndReturn(
+			func(_ interface{}, list *batchv1.JobList, _ ...interface{}) error {
+				list.Items = jobs
+				return nil
+			},
+		)
+	}
+
+	It("should reject invalid kernel versions", func() {
+		_, err := RequestRebuild(ctx, clnt, moduleName, namespace, "latest")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return an error if the Module cannot be fetched", func() {
+		clnt.EXPECT().Get(ctx, nsn, gomock.Any()).Return(errors.New("some error"))
+
+		_, err := RequestRebuild(ctx, clnt, moduleName, namespace, kernel)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should not annotate the Module if no Job failed", func() {
+		expectGetModule(nil)
+		expectListJobs(job("running", 0))
+
+		_, err := RequestRebuild(ctx, clnt, moduleName, namespace, kernel)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should add the kernel to the annotation and return the failed Jobs", func() {
+		expectGetModule(map[string]string{constants.RebuildKernelsAnnotation: "4.18.0-1"})
+		expectListJobs(job("sign", 1), job("build", 1), job("running", 0))
+
+		clnt.EXPECT().Patch(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, mod *kmmv1beta1.Module, _ ctrlclient.Patch, _ ...ctrlclient.PatchOption) error {
+				Expect(mod.Annotations).To(HaveKeyWithValue(constants.RebuildKernelsAnnotation, "4.18.0-1,"+kernel))
+				return nil
+			},
+		)
+
+		jobs, err := RequestRebuild(ctx, clnt, moduleName, namespace, kernel+"+")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(jobs).To(Equal([]string{"build", "sign"}))
+	})
+})
@@ -0,0 +1,278 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/runtime/serializer/json"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/build"
+	buildjob "github.com/kubernetes-sigs/kernel-module-management/internal/build/job"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/daemonset"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/sign"
+	signjob "github.com/kubernetes-sigs/kernel-module-management/internal/sign/job"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/utils"
+)
+
+// RenderOptions holds the kernel to render objects for, and the operator settings that change those objects.
+type RenderOptions struct {
+	Kernel string
+
+	// Namespace is used for the Modules that do not have one.
+	Namespace string
+
+	ModuleLoaderSELinuxType string
+	RestrictedPodSecurity   bool
+	SeccompProfile          string
+}
+
+// Render returns the DaemonSets, build Jobs and sign Jobs that the operator would create for the Modules in objs on
+// nodes running opts.Kernel, without accessing any cluster.
+// The ConfigMaps and Secrets referenced by the Modules are read from objs; those that are missing are replaced by
+// empty ones, and reported in the returned warnings.
+func Render(ctx context.Context, scheme *runtime.Scheme, objs []client.Object, opts RenderOptions) ([]client.Object, []string, error) {
+	kernel := strings.TrimSuffix(opts.Kernel, "+")
+
+	if !kernelVersionRegexp.MatchString(kernel) {
+		return nil, nil, fmt.Errorf("invalid kernel version %q", opts.Kernel)
+	}
+
+	oc := newOfflineClient(objs, opts.Namespace)
+
+	kernelAPI := module.NewKernelMapper()
+	jobHelper := utils.NewJobHelper(oc, "")
+	maker := buildjob.NewMaker(oc, build.NewHelper(), jobHelper, scheme)
+	signer := signjob.NewSigner(oc, scheme, sign.NewSignerHelper(), jobHelper, opts.RestrictedPodSecurity, false)
+	dsAPI := daemonset.NewCreator(
+		oc,
+		constants.KernelLabel,
+		scheme,
+		opts.ModuleLoaderSELinuxType,
+		opts.RestrictedPodSecurity,
+		opts.SeccompProfile,
+	)
+
+	rendered := make([]client.Object, 0)
+
+	for _, obj := range objs {
+		mod, ok := obj.(*kmmv1beta1.Module)
+		if !ok {
+			continue
+		}
+
+		if mod.Namespace == "" {
+			mod.Namespace = opts.Namespace
+		}
+
+		m, err := kernelAPI.FindMappingForKernel(mod.Spec.ModuleLoader.Container.KernelMappings, kernel)
+		if err != nil {
+			return nil, nil, fmt.Errorf("module %s: no kernel mapping matches kernel %s", mod.Name, kernel)
+		}
+
+		m, err = kernelAPI.PrepareKernelMapping(m, kernelAPI.GetNodeOSConfigFromKernelVersion(kernel))
+		if err != nil {
+			return nil, nil, fmt.Errorf("module %s: could not substitute the kernel variables: %v", mod.Name, err)
+		}
+
+		if module.ShouldBeBuilt(mod.Spec, *m) {
+			job, err := maker.MakeJobTemplate(ctx, *mod, *m, kernel, mod, true)
+			if err != nil {
+				return nil, nil, fmt.Errorf("module %s: could not render the build Job: %v", mod.Name, err)
+			}
+
+			rendered = append(rendered, job)
+		}
+
+		if module.ShouldBeSigned(mod.Spec, *m) {
+			previousImage := ""
+			if module.ShouldBeBuilt(mod.Spec, *m) {
+				previousImage = module.IntermediateImageName(mod.Name, mod.Namespace, m.ContainerImage)
+			}
+
+			labels := jobHelper.JobLabels(mod.Name, kernel, utils.JobTypeSign)
+
+			job, err := signer.MakeJobTemplate(ctx, *mod, *m, kernel, labels, previousImage, true, mod)
+			if err != nil {
+				return nil, nil, fmt.Errorf("module %s: could not render the sign Job: %v", mod.Name, err)
+			}
+
+			rendered = append(rendered, job)
+		}
+
+		ds := &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      daemonset.DriverContainerName(mod.Name, kernel),
+				Namespace: mod.Namespace,
+			},
+		}
+
+		if err = dsAPI.SetDriverContainerAsDesired(ctx, ds, *m, *mod, kernel, daemonset.OSProfileDefault); err != nil {
+			return nil, nil, fmt.Errorf("module %s: could not render the module-loader DaemonSet: %v", mod.Name, err)
+		}
+
+		rendered = append(rendered, ds)
+
+		if mod.Spec.DevicePlugin != nil {
+			ds = &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      mod.Name + "-device-plugin",
+					Namespace: mod.Namespace,
+				},
+			}
+
+			if err = dsAPI.SetDevicePluginAsDesired(ctx, ds, mod); err != nil {
+				return nil, nil, fmt.Errorf("module %s: could not render the device-plugin DaemonSet: %v", mod.Name, err)
+			}
+
+			rendered = append(rendered, ds)
+		}
+	}
+
+	if len(rendered) == 0 {
+		return nil, nil, errors.New("no Module found")
+	}
+
+	return rendered, oc.warnings, nil
+}
+
+// offlineClient serves the ConfigMaps and Secrets needed to render Jobs from a list of objects.
+// Only Get and List are implemented: the embedded client is nil.
+type offlineClient struct {
+	client.Client
+
+	configMaps map[client.ObjectKey]*v1.ConfigMap
+	secrets    map[client.ObjectKey]*v1.Secret
+	warnings   []string
+}
+
+// Objects without a namespace are considered to be in defaultNamespace, like Modules.
+func newOfflineClient(objs []client.Object, defaultNamespace string) *offlineClient {
+	oc := offlineClient{
+		configMaps: make(map[client.ObjectKey]*v1.ConfigMap),
+		secrets:    make(map[client.ObjectKey]*v1.Secret),
+	}
+
+	for _, obj := range objs {
+		key := client.ObjectKeyFromObject(obj)
+		if key.Namespace == "" {
+			key.Namespace = defaultNamespace
+		}
+
+		switch o := obj.(type) {
+		case *v1.ConfigMap:
+			oc.configMaps[key] = o
+		case *v1.Secret:
+			oc.secrets[key] = o
+		}
+	}
+
+	return &oc
+}
+
+func (oc *offlineClient) Get(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+	switch o := obj.(type) {
+	case *v1.ConfigMap:
+		if cm := oc.configMaps[key]; cm != nil {
+			cm.DeepCopyInto(o)
+			return nil
+		}
+
+		oc.warnings = append(oc.warnings, fmt.Sprintf("ConfigMap %s not found in the input; using an empty Dockerfile", key))
+		*o = v1.ConfigMap{Data: map[string]string{constants.DockerfileCMKey: ""}}
+	case *v1.Secret:
+		if s := oc.secrets[key]; s != nil {
+			s.DeepCopyInto(o)
+			return nil
+		}
+
+		oc.warnings = append(oc.warnings, fmt.Sprintf("Secret %s not found in the input; using empty keys", key))
+		*o = v1.Secret{Data: map[string][]byte{constants.PrivateSignDataKey: nil, constants.PublicSignDataKey: nil}}
+	default:
+		return fmt.Errorf("%T objects cannot be read offline", obj)
+	}
+
+	return nil
+}
+
+// List returns no nodes, so that rendered Jobs are not restricted to an architecture.
+func (oc *offlineClient) List(_ context.Context, list client.ObjectList, _ ...client.ListOption) error {
+	if _, ok := list.(*v1.NodeList); !ok {
+		return fmt.Errorf("%T objects cannot be listed offline", list)
+	}
+
+	return nil
+}
+
+// DecodeObjects decodes the YAML or JSON documents read from r.
+func DecodeObjects(r io.Reader, scheme *runtime.Scheme) ([]client.Object, error) {
+	decoder := serializer.NewCodecFactory(scheme).UniversalDeserializer()
+	reader := yaml.NewYAMLReader(bufio.NewReader(r))
+
+	objs := make([]client.Object, 0)
+
+	for i := 0; ; i++ {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			return objs, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read document %d: %v", i, err)
+		}
+
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+
+		obj, _, err := decoder.Decode(doc, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode document %d: %v", i, err)
+		}
+
+		cObj, ok := obj.(client.Object)
+		if !ok {
+			return nil, fmt.Errorf("document %d: unexpected type %T", i, obj)
+		}
+
+		objs = append(objs, cObj)
+	}
+}
+
+// EncodeObjects writes objs to w as a YAML stream.
+func EncodeObjects(w io.Writer, objs []client.Object, scheme *runtime.Scheme) error {
+	s := json.NewSerializerWithOptions(json.DefaultMetaFactory, scheme, scheme, json.SerializerOptions{Yaml: true})
+
+	for _, obj := range objs {
+		gvk, err := apiutil.GVKForObject(obj, scheme)
+		if err != nil {
+			return fmt.Errorf("could not determine the kind of %s: %v", obj.GetName(), err)
+		}
+
+		obj.GetObjectKind().SetGroupVersionKind(gvk)
+
+		if _, err = io.WriteString(w, "---\n"); err != nil {
+			return err
+		}
+
+		if err = s.Encode(obj, w); err != nil {
+			return fmt.Errorf("could not encode %s: %v", obj.GetName(), err)
+		}
+	}
+
+	return nil
+}
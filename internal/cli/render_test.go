@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+)
+
+const renderInput = `
+apiVersion: kmm.sigs.x-k8s.io/v1beta1
+kind: Module
+metadata:
+  name: some-module
+spec:
+  moduleLoader:
+    container:
+      modprobe:
+        moduleName: some-module
+      kernelMappings:
+        - regexp: '^.+$'
+          containerImage: example.com/some-module:${KERNEL_FULL_VERSION}
+          build:
+            dockerfileConfigMap:
+              name: some-dockerfile
+          sign:
+            keySecret:
+              name: some-key
+            certSecret:
+              name: some-cert
+  devicePlugin:
+    container:
+      image: example.com/some-device-plugin
+  selector:
+    some-label: ""
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: some-dockerfile
+data:
+  dockerfile: FROM scratch
+`
+
+var _ = Describe("Render", func() {
+	const kernel = "5.14.0-1.el9.x86_64"
+
+	decode := func(s string) []client.Object {
+		objs, err := DecodeObjects(strings.NewReader(s), scheme)
+		Expect(err).NotTo(HaveOccurred())
+
+		return objs
+	}
+
+	It("should reject invalid kernel versions", func() {
+		_, _, err := Render(context.Background(), scheme, decode(renderInput), RenderOptions{Kernel: "5"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return an error if there is no Module", func() {
+		_, _, err := Render(context.Background(), scheme, nil, RenderOptions{Kernel: kernel})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return an error if no mapping matches the kernel", func() {
+		input := strings.Replace(renderInput, "'^.+$'", "'^4\\.18.+$'", 1)
+
+		_, _, err := Render(context.Background(), scheme, decode(input), RenderOptions{Kernel: kernel})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should render the Jobs and DaemonSets", func() {
+		opts := RenderOptions{Kernel: kernel, Namespace: "some-namespace"}
+
+		objs, warnings, err := Render(context.Background(), scheme, decode(renderInput), opts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(HaveLen(2))
+		Expect(warnings[0]).To(ContainSubstring("some-namespace/some-key"))
+		Expect(objs).To(HaveLen(4))
+
+		build, ok := objs[0].(*batchv1.Job)
+		Expect(ok).To(BeTrue())
+		Expect(build.Namespace).To(Equal("some-namespace"))
+		Expect(build.Labels).To(HaveKeyWithValue(constants.JobType, "build"))
+		Expect(build.Spec.Template.Spec.Containers[0].Args).To(
+			ContainElement("example.com/some-module:" + kernel + "_some-namespace_some-module_kmm_unsigned"),
+		)
+
+		sign, ok := objs[1].(*batchv1.Job)
+		Expect(ok).To(BeTrue())
+		Expect(sign.Labels).To(HaveKeyWithValue(constants.JobType, "sign"))
+
+		moduleLoader, ok := objs[2].(*appsv1.DaemonSet)
+		Expect(ok).To(BeTrue())
+		Expect(moduleLoader.Labels).To(HaveKeyWithValue(constants.KernelLabel, kernel))
+		Expect(moduleLoader.Spec.Template.Spec.Containers[0].Image).To(Equal("example.com/some-module:" + kernel))
+
+		devicePlugin, ok := objs[3].(*appsv1.DaemonSet)
+		Expect(ok).To(BeTrue())
+		Expect(devicePlugin.Name).To(Equal("some-module-device-plugin"))
+	})
+})
+
+var _ = Describe("EncodeObjects", func() {
+	It("should write objects that can be decoded again", func() {
+		objs, err := DecodeObjects(strings.NewReader(renderInput), scheme)
+		Expect(err).NotTo(HaveOccurred())
+
+		var buf bytes.Buffer
+
+		Expect(EncodeObjects(&buf, objs, scheme)).To(Succeed())
+		Expect(buf.String()).To(HavePrefix("---\napiVersion: kmm.sigs.x-k8s.io/v1beta1\nkind: Module\n"))
+
+		decoded, err := DecodeObjects(&buf, scheme)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decoded).To(HaveLen(2))
+		Expect(decoded[1].GetName()).To(Equal("some-dockerfile"))
+	})
+})
@@ -0,0 +1,302 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/daemonset"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/utils"
+)
+
+const (
+	// StateLoaded means that the module-loader pod of a node is ready.
+	StateLoaded = "Loaded"
+	// StateNoPod means that no module-loader pod runs on a node targeted by the Module.
+	StateNoPod = "NoPod"
+	// StateNotReady means that the module-loader pod of a node is not ready yet.
+	StateNotReady = "NotReady"
+
+	none = "-"
+
+	jobCompleted = "Completed"
+	jobRunning   = "Running"
+	jobFailed    = "Failed"
+	jobPending   = "Pending"
+)
+
+// KernelStatus is the state of the objects that KMM creates for one kernel version.
+type KernelStatus struct {
+	Kernel    string
+	Build     string
+	Sign      string
+	DaemonSet string
+	Nodes     int
+}
+
+// NodeStatus is the state of the module on one node.
+type NodeStatus struct {
+	Node    string
+	Kernel  string
+	Pod     string
+	State   string
+	Message string
+}
+
+// ModuleStatus is the state of a Module across kernels and nodes.
+type ModuleStatus struct {
+	Name       string
+	Namespace  string
+	Conditions []string
+	Kernels    []KernelStatus
+	Nodes      []NodeStatus
+}
+
+// GetModuleStatus assembles the status of a Module from the Module itself, the nodes it targets, and the DaemonSets,
+// Jobs and pods that KMM created for it.
+func GetModuleStatus(ctx context.Context, c client.Client, name, namespace string) (*ModuleStatus, error) {
+	mod := kmmv1beta1.Module{}
+
+	if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &mod); err != nil {
+		return nil, fmt.Errorf("could not get Module %s/%s: %v", namespace, name, err)
+	}
+
+	return moduleStatus(ctx, c, &mod)
+}
+
+func moduleStatus(ctx context.Context, c client.Client, mod *kmmv1beta1.Module) (*ModuleStatus, error) {
+	name := mod.Name
+	namespace := mod.Namespace
+
+	nodes := v1.NodeList{}
+
+	if err := c.List(ctx, &nodes, client.MatchingLabels(mod.Spec.Selector)); err != nil {
+		return nil, fmt.Errorf("could not list nodes: %v", err)
+	}
+
+	moduleLabels := client.MatchingLabels{constants.ModuleNameLabel: name}
+
+	dsList := appsv1.DaemonSetList{}
+
+	if err := c.List(ctx, &dsList, client.InNamespace(namespace), moduleLabels); err != nil {
+		return nil, fmt.Errorf("could not list DaemonSets: %v", err)
+	}
+
+	jobs := batchv1.JobList{}
+
+	if err := c.List(ctx, &jobs, client.InNamespace(namespace), moduleLabels); err != nil {
+		return nil, fmt.Errorf("could not list Jobs: %v", err)
+	}
+
+	pods := v1.PodList{}
+
+	podLabels := client.MatchingLabels{constants.ModuleNameLabel: name, constants.DaemonSetRole: "module-loader"}
+
+	if err := c.List(ctx, &pods, client.InNamespace(namespace), podLabels); err != nil {
+		return nil, fmt.Errorf("could not list pods: %v", err)
+	}
+
+	ms := ModuleStatus{Name: name, Namespace: namespace}
+
+	for _, cond := range mod.Status.Conditions {
+		ms.Conditions = append(ms.Conditions, fmt.Sprintf("%s=%s (%s)", cond.Type, cond.Status, cond.Reason))
+	}
+
+	dsByKernel := make(map[string]*appsv1.DaemonSet)
+
+	for i := 0; i < len(dsList.Items); i++ {
+		ds := &dsList.Items[i]
+
+		// the device-plugin DaemonSet is not specific to a kernel
+		if kernel := ds.Labels[constants.KernelLabel]; kernel != "" {
+			dsByKernel[kernel] = ds
+		}
+	}
+
+	podByNode := make(map[string]*v1.Pod)
+
+	for i := 0; i < len(pods.Items); i++ {
+		p := &pods.Items[i]
+		podByNode[p.Spec.NodeName] = p
+	}
+
+	nodesByKernel := make(map[string]int)
+
+	for _, n := range nodes.Items {
+		kernel := n.Status.NodeInfo.KernelVersion
+
+		nodesByKernel[kernel]++
+
+		ms.Nodes = append(ms.Nodes, nodeStatus(&n, podByNode[n.Name], name))
+	}
+
+	kernels := make([]string, 0, len(nodesByKernel))
+
+	for k := range nodesByKernel {
+		kernels = append(kernels, k)
+	}
+
+	for k := range dsByKernel {
+		if _, ok := nodesByKernel[k]; !ok {
+			kernels = append(kernels, k)
+		}
+	}
+
+	sort.Strings(kernels)
+
+	for _, k := range kernels {
+		ms.Kernels = append(ms.Kernels, KernelStatus{
+			Kernel:    k,
+			Build:     jobState(jobs.Items, utils.JobTypeBuild, k),
+			Sign:      jobState(jobs.Items, utils.JobTypeSign, k),
+			DaemonSet: daemonSetState(dsByKernel[k]),
+			Nodes:     nodesByKernel[k],
+		})
+	}
+
+	sort.Slice(ms.Nodes, func(i, j int) bool {
+		return ms.Nodes[i].Node < ms.Nodes[j].Node
+	})
+
+	return &ms, nil
+}
+
+func nodeStatus(node *v1.Node, pod *v1.Pod, moduleName string) NodeStatus {
+	ns := NodeStatus{
+		Node:   node.Name,
+		Kernel: node.Status.NodeInfo.KernelVersion,
+		Pod:    none,
+	}
+
+	if pod == nil {
+		ns.State = StateNoPod
+		return ns
+	}
+
+	ns.Pod = pod.Name
+
+	if reason, msg := daemonset.ModuleLoadRejection(pod); reason != "" {
+		ns.State = reason
+		ns.Message = msg
+		return ns
+	}
+
+	if _, ok := node.Labels[daemonset.ModuleReadyNodeLabel(moduleName)]; ok && podReady(pod) {
+		ns.State = StateLoaded
+		return ns
+	}
+
+	ns.State = StateNotReady
+
+	if cs := waitingContainer(pod); cs != nil {
+		ns.Message = fmt.Sprintf("container %s is waiting: %s", cs.Name, cs.State.Waiting.Reason)
+	} else if pod.Status.Phase != "" {
+		ns.Message = "pod is " + string(pod.Status.Phase)
+	}
+
+	return ns
+}
+
+// waitingContainer returns the status of the first container of pod that is waiting with a reason, such as
+// CrashLoopBackOff or ImagePullBackOff.
+func waitingContainer(pod *v1.Pod) *v1.ContainerStatus {
+	for _, statuses := range [][]v1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for i := 0; i < len(statuses); i++ {
+			if w := statuses[i].State.Waiting; w != nil && w.Reason != "" {
+				return &statuses[i]
+			}
+		}
+	}
+
+	return nil
+}
+
+func podReady(pod *v1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == v1.PodReady {
+			return c.Status == v1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+// jobState returns the state of the most recent Job of jobType for kernel.
+func jobState(jobs []batchv1.Job, jobType, kernel string) string {
+	var latest *batchv1.Job
+
+	for i := 0; i < len(jobs); i++ {
+		j := &jobs[i]
+
+		if j.Labels[constants.JobType] != jobType || j.Labels[constants.TargetKernelTarget] != kernel {
+			continue
+		}
+
+		if latest == nil || latest.CreationTimestamp.Before(&j.CreationTimestamp) {
+			latest = j
+		}
+	}
+
+	switch {
+	case latest == nil:
+		return none
+	case latest.Status.Succeeded > 0:
+		return jobCompleted
+	case latest.Status.Active > 0:
+		return jobRunning
+	case latest.Status.Failed > 0:
+		return jobFailed
+	default:
+		return jobPending
+	}
+}
+
+func daemonSetState(ds *appsv1.DaemonSet) string {
+	if ds == nil {
+		return none
+	}
+
+	return fmt.Sprintf("%d/%d ready", ds.Status.NumberReady, ds.Status.DesiredNumberScheduled)
+}
+
+// PrintModuleStatus writes ms to w as tables.
+func PrintModuleStatus(w io.Writer, ms *ModuleStatus) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Module:\t%s/%s\n", ms.Namespace, ms.Name)
+
+	for _, c := range ms.Conditions {
+		fmt.Fprintf(tw, "Condition:\t%s\n", c)
+	}
+
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "KERNEL\tBUILD\tSIGN\tDAEMONSET\tNODES")
+
+	for _, k := range ms.Kernels {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\n", k.Kernel, k.Build, k.Sign, k.DaemonSet, k.Nodes)
+	}
+
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "NODE\tKERNEL\tPOD\tSTATE\tMESSAGE")
+
+	for _, n := range ms.Nodes {
+		msg := n.Message
+		if msg == "" {
+			msg = none
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", n.Node, n.Kernel, n.Pod, n.State, msg)
+	}
+
+	return tw.Flush()
+}
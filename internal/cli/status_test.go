@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/client"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+)
+
+var _ = Describe("GetModuleStatus", func() {
+	const (
+		moduleName = "some-module"
+		namespace  = "some-namespace"
+		kernel1    = "5.14.0-1"
+		kernel2    = "5.14.0-2"
+	)
+
+	var (
+		ctrl *gomock.Controller
+		clnt *client.MockClient
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+	})
+
+	It("should return an error if the Module cannot be fetched", func() {
+		clnt.EXPECT().Get(context.Background(), types.NamespacedName{Name: moduleName, Namespace: namespace}, gomock.Any()).
+			Return(errors.New("some error"))
+
+		_, err := GetModuleStatus(context.Background(), clnt, moduleName, namespace)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should assemble the status of all kernels and nodes", func() {
+		node := func(name, kernel string, labels map[string]string) v1.Node {
+			return v1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+				Status:     v1.NodeStatus{NodeInfo: v1.NodeSystemInfo{KernelVersion: kernel}},
+			}
+		}
+
+		readyLabel := map[string]string{"kmm.node.kubernetes.io/some-module.ready": ""}
+
+		nodes := []v1.Node{
+			node("node-c", kernel2, nil),
+			node("node-a", kernel1, readyLabel),
+			node("node-b", kernel1, nil),
+		}
+
+		ds := appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "ds-1",
+				Labels: map[string]string{constants.KernelLabel: kernel1},
+			},
+			Status: appsv1.DaemonSetStatus{NumberReady: 1, DesiredNumberScheduled: 2},
+		}
+
+		devicePluginDS := appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "device-plugin"}}
+
+		job := func(jobType, kernel string, created int64, status batchv1.JobStatus) batchv1.Job {
+			return batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:            map[string]string{constants.JobType: jobType, constants.TargetKernelTarget: kernel},
+					CreationTimestamp: metav1.Unix(created, 0),
+				},
+				Status: status,
+			}
+		}
+
+		jobs := []batchv1.Job{
+			job("build", kernel1, 1, batchv1.JobStatus{Failed: 1}),
+			job("build", kernel1, 2, batchv1.JobStatus{Succeeded: 1}),
+			job("sign", kernel1, 3, batchv1.JobStatus{Active: 1}),
+			job("build", kernel2, 4, batchv1.JobStatus{Failed: 1}),
+		}
+
+		pods := []v1.Pod{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "pod-a"},
+				Spec:       v1.PodSpec{NodeName: "node-a"},
+				Status: v1.PodStatus{
+					Phase:      v1.PodRunning,
+					Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "pod-b"},
+				Spec:       v1.PodSpec{NodeName: "node-b"},
+				Status: v1.PodStatus{
+					Phase: v1.PodRunning,
+					ContainerStatuses: []v1.ContainerStatus{
+						{
+							Name: "module-loader",
+							LastTerminationState: v1.ContainerState{
+								Terminated: &v1.ContainerStateTerminated{
+									Message: "modprobe: ERROR: could not insert 'mod': Key was rejected by service",
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		gomock.InOrder(
+			clnt.EXPECT().Get(context.Background(), types.NamespacedName{Name: moduleName, Namespace: namespace}, gomock.Any()).DoAndReturn(
+				func(_ interface{}, _ interface{}, m *kmmv1beta1.Module, _ ...ctrlclient.GetOption) error {
+					m.Name = moduleName
+					m.Namespace = namespace
+					m.Status.Conditions = []metav1.Condition{
+						{Type: kmmv1beta1.ModuleConditionDegraded, Status: metav1.ConditionTrue, Reason: "BuildFailed"},
+					}
+					return nil
+				},
+			),
+			clnt.EXPECT().List(context.Background(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ interface{}, list *v1.NodeList, _ ...interface{}) error {
+					list.Items = nodes
+					return nil
+				},
+			),
+			clnt.EXPECT().List(context.Background(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ interface{}, list *appsv1.DaemonSetList, _ ...interface{}) error {
+					list.Items = []appsv1.DaemonSet{ds, devicePluginDS}
+					return nil
+				},
+			),
+			clnt.EXPECT().List(context.Background(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ interface{}, list *batchv1.JobList, _ ...interface{}) error {
+					list.Items = jobs
+					return nil
+				},
+			),
+			clnt.EXPECT().List(context.Background(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ interface{}, list *v1.PodList, _ ...interface{}) error {
+					list.Items = pods
+					return nil
+				},
+			),
+		)
+
+		ms, err := GetModuleStatus(context.Background(), clnt, moduleName, namespace)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ms.Conditions).To(Equal([]string{"Degraded=True (BuildFailed)"}))
+		Expect(ms.Kernels).To(Equal([]KernelStatus{
+			{Kernel: kernel1, Build: "Completed", Sign: "Running", DaemonSet: "1/2 ready", Nodes: 2},
+			{Kernel: kernel2, Build: "Failed", Sign: "-", DaemonSet: "-", Nodes: 1},
+		}))
+		Expect(ms.Nodes).To(Equal([]NodeStatus{
+			{Node: "node-a", Kernel: kernel1, Pod: "pod-a", State: StateLoaded},
+			{
+				Node:    "node-b",
+				Kernel:  kernel1,
+				Pod:     "pod-b",
+				State:   "SignatureRejected",
+				Message: "modprobe: ERROR: could not insert 'mod': Key was rejected by service",
+			},
+			{Node: "node-c", Kernel: kernel2, Pod: "-", State: StateNoPod},
+		}))
+	})
+})
+
+var _ = Describe("PrintModuleStatus", func() {
+	It("should print the kernels and nodes tables", func() {
+		ms := ModuleStatus{
+			Name:      "some-module",
+			Namespace: "some-namespace",
+			Kernels:   []KernelStatus{{Kernel: "5.14.0", Build: "-", Sign: "-", DaemonSet: "1/1 ready", Nodes: 1}},
+			Nodes:     []NodeStatus{{Node: "node", Kernel: "5.14.0", Pod: "pod", State: StateLoaded}},
+		}
+
+		var buf bytes.Buffer
+
+		Expect(PrintModuleStatus(&buf, &ms)).To(Succeed())
+
+		out := buf.String()
+		Expect(out).To(ContainSubstring("some-namespace/some-module"))
+		Expect(out).To(MatchRegexp(`(?m)^KERNEL\s+BUILD\s+SIGN\s+DAEMONSET\s+NODES$`))
+		Expect(out).To(MatchRegexp(`(?m)^5\.14\.0\s+-\s+-\s+1/1 ready\s+1$`))
+		Expect(out).To(MatchRegexp(`(?m)^node\s+5\.14\.0\s+pod\s+Loaded\s+-$`))
+	})
+})
@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/build"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+)
+
+// The build argument that KMM always passes to builds.
+const kernelVersionBuildArg = "KERNEL_VERSION"
+
+var (
+	// variableRegexp matches ${VAR}, ${VAR:-default} and $VAR.
+	variableRegexp = regexp.MustCompile(`\$(?:\{([A-Za-z_][A-Za-z0-9_]*)[^}]*\}|([A-Za-z_][A-Za-z0-9_]*))`)
+
+	// The ARGs that Kaniko sets automatically.
+	predefinedArgs = sets.NewString(
+		"BUILDPLATFORM", "BUILDOS", "BUILDARCH", "BUILDVARIANT",
+		"TARGETPLATFORM", "TARGETOS", "TARGETARCH", "TARGETVARIANT",
+		"HTTP_PROXY", "http_proxy", "HTTPS_PROXY", "https_proxy", "FTP_PROXY", "ftp_proxy", "NO_PROXY", "no_proxy",
+	)
+)
+
+// kernelVariables returns the variables that KMM substitutes in kernel mapping images.
+func kernelVariables() sets.String {
+	vars := sets.NewString()
+
+	t := reflect.TypeOf(module.NodeOSConfig{})
+
+	for i := 0; i < t.NumField(); i++ {
+		vars.Insert(t.Field(i).Tag.Get("subst"))
+	}
+
+	return vars
+}
+
+// ValidateModules checks the Modules in objs without accessing any cluster: kernel mappings must have a valid
+// literal or regexp, images may only use the kernel variables, and the Dockerfile ConfigMaps of builds must be in
+// objs and declare all the build arguments they get, with a value for all those that have no default.
+// Objects without a namespace are considered to be in defaultNamespace.
+// It returns a description of each problem found.
+func ValidateModules(objs []client.Object, defaultNamespace string) []string {
+	configMaps := make(map[client.ObjectKey]*v1.ConfigMap)
+
+	for _, obj := range objs {
+		if cm, ok := obj.(*v1.ConfigMap); ok {
+			key := client.ObjectKeyFromObject(cm)
+			if key.Namespace == "" {
+				key.Namespace = defaultNamespace
+			}
+
+			configMaps[key] = cm
+		}
+	}
+
+	problems := make([]string, 0)
+
+	for _, obj := range objs {
+		mod, ok := obj.(*kmmv1beta1.Module)
+		if !ok {
+			continue
+		}
+
+		namespace := mod.Namespace
+		if namespace == "" {
+			namespace = defaultNamespace
+		}
+
+		for _, p := range validateModule(mod, namespace, configMaps) {
+			problems = append(problems, fmt.Sprintf("Module %s/%s: %s", namespace, mod.Name, p))
+		}
+	}
+
+	return problems
+}
+
+func validateModule(mod *kmmv1beta1.Module, namespace string, configMaps map[client.ObjectKey]*v1.ConfigMap) []string {
+	problems := sets.NewString()
+	helper := build.NewHelper()
+	vars := kernelVariables()
+
+	for i, km := range mod.Spec.ModuleLoader.Container.KernelMappings {
+		field := fmt.Sprintf("kernelMappings[%d]", i)
+
+		switch {
+		case km.Literal == "" && km.Regexp == "":
+			problems.Insert(field + ": one of literal or regexp must be set")
+		case km.Regexp != "":
+			if _, err := regexp.Compile(km.Regexp); err != nil {
+				problems.Insert(fmt.Sprintf("%s: invalid regexp: %v", field, err))
+			}
+		}
+
+		if km.ContainerImage == "" {
+			problems.Insert(field + ": containerImage is empty")
+		}
+
+		for _, v := range imageVariables(km.ContainerImage) {
+			if !vars.Has(v) {
+				problems.Insert(
+					fmt.Sprintf("%s: containerImage uses ${%s}, which is not one of %s", field, v, strings.Join(vars.List(), ", ")),
+				)
+			}
+		}
+
+		if !module.ShouldBeBuilt(mod.Spec, km) {
+			continue
+		}
+
+		for _, p := range validateBuild(helper.GetRelevantBuild(mod.Spec, km), namespace, configMaps) {
+			problems.Insert(field + ": build: " + p)
+		}
+	}
+
+	return problems.List()
+}
+
+func validateBuild(b *kmmv1beta1.Build, namespace string, configMaps map[client.ObjectKey]*v1.ConfigMap) []string {
+	if b.DockerfileConfigMap == nil || b.DockerfileConfigMap.Name == "" {
+		return []string{"dockerfileConfigMap is not set"}
+	}
+
+	key := client.ObjectKey{Name: b.DockerfileConfigMap.Name, Namespace: namespace}
+
+	cm := configMaps[key]
+	if cm == nil {
+		return []string{fmt.Sprintf("ConfigMap %s not found", key)}
+	}
+
+	dockerfile, ok := cm.Data[constants.DockerfileCMKey]
+	if !ok {
+		return []string{fmt.Sprintf("ConfigMap %s has no %s key", key, constants.DockerfileCMKey)}
+	}
+
+	declared := dockerfileArgs(dockerfile)
+
+	provided := sets.NewString(kernelVersionBuildArg)
+
+	for _, ba := range b.BuildArgs {
+		provided.Insert(ba.Name)
+	}
+
+	problems := make([]string, 0)
+
+	for _, name := range sortedKeys(declared) {
+		if !declared[name] && !provided.Has(name) && !predefinedArgs.Has(name) {
+			problems = append(problems, fmt.Sprintf("ARG %s has no default value and is not set in buildArgs", name))
+		}
+	}
+
+	for _, ba := range b.BuildArgs {
+		if _, ok := declared[ba.Name]; !ok {
+			problems = append(problems, fmt.Sprintf("build argument %s is not declared with ARG in the Dockerfile", ba.Name))
+		}
+	}
+
+	return problems
+}
+
+// dockerfileArgs returns the ARGs declared in dockerfile, and whether they have a default value.
+func dockerfileArgs(dockerfile string) map[string]bool {
+	args := make(map[string]bool)
+
+	// join continuation lines
+	dockerfile = strings.ReplaceAll(dockerfile, "\\\n", " ")
+
+	for _, line := range strings.Split(dockerfile, "\n") {
+		fields := strings.Fields(line)
+
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "ARG") {
+			continue
+		}
+
+		for _, f := range fields[1:] {
+			name, _, hasDefault := strings.Cut(f, "=")
+			args[name] = args[name] || hasDefault
+		}
+	}
+
+	return args
+}
+
+// imageVariables returns the names of the variables used in image.
+func imageVariables(image string) []string {
+	names := make([]string, 0)
+
+	for _, m := range variableRegexp.FindAllStringSubmatch(image, -1) {
+		if m[1] != "" {
+			names = append(names, m[1])
+		} else {
+			names = append(names, m[2])
+		}
+	}
+
+	return names
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
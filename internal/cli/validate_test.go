@@ -0,0 +1,111 @@
+package cli
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+)
+
+var _ = Describe("ValidateModules", func() {
+	const dockerfile = `ARG DTK_AUTO
+FROM ${DTK_AUTO} as builder
+ARG KERNEL_VERSION
+ARG MY_ARG=default
+ARG TARGETARCH
+`
+
+	dockerfileCM := func(namespace string, data map[string]string) *v1.ConfigMap {
+		return &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "dockerfile", Namespace: namespace},
+			Data:       data,
+		}
+	}
+
+	mod := func(mappings ...kmmv1beta1.KernelMapping) *kmmv1beta1.Module {
+		m := kmmv1beta1.Module{ObjectMeta: metav1.ObjectMeta{Name: "mod"}}
+		m.Spec.ModuleLoader.Container.KernelMappings = mappings
+		return &m
+	}
+
+	buildWithArgs := func(args ...string) *kmmv1beta1.Build {
+		b := kmmv1beta1.Build{DockerfileConfigMap: &v1.LocalObjectReference{Name: "dockerfile"}}
+
+		for _, a := range args {
+			b.BuildArgs = append(b.BuildArgs, kmmv1beta1.BuildArg{Name: a, Value: "value"})
+		}
+
+		return &b
+	}
+
+	It("should not report anything for valid Modules", func() {
+		objs := []client.Object{
+			mod(
+				kmmv1beta1.KernelMapping{Literal: "5.14.0", ContainerImage: "example.com/mod:${KERNEL_FULL_VERSION}"},
+				kmmv1beta1.KernelMapping{
+					Regexp:         `^.+\.el9\.x86_64$`,
+					ContainerImage: "example.com/mod:$KERNEL_X.$KERNEL_Y",
+					Build:          buildWithArgs("DTK_AUTO", "MY_ARG"),
+				},
+			),
+			dockerfileCM("ns", map[string]string{"dockerfile": dockerfile}),
+		}
+
+		Expect(ValidateModules(objs, "ns")).To(BeEmpty())
+	})
+
+	It("should report invalid kernel mappings", func() {
+		objs := []client.Object{
+			mod(
+				kmmv1beta1.KernelMapping{ContainerImage: "example.com/mod"},
+				kmmv1beta1.KernelMapping{Regexp: "(", ContainerImage: "example.com/mod:${KERNEL_VERSION}"},
+			),
+		}
+
+		Expect(ValidateModules(objs, "ns")).To(ConsistOf(
+			"Module ns/mod: kernelMappings[0]: one of literal or regexp must be set",
+			ContainSubstring("kernelMappings[1]: invalid regexp"),
+			ContainSubstring("kernelMappings[1]: containerImage uses ${KERNEL_VERSION}"),
+		))
+	})
+
+	It("should report missing Dockerfile ConfigMaps", func() {
+		objs := []client.Object{
+			mod(kmmv1beta1.KernelMapping{Literal: "5.14.0", ContainerImage: "example.com/mod", Build: buildWithArgs()}),
+			dockerfileCM("other-ns", map[string]string{"dockerfile": dockerfile}),
+		}
+
+		Expect(ValidateModules(objs, "ns")).To(Equal([]string{
+			"Module ns/mod: kernelMappings[0]: build: ConfigMap ns/dockerfile not found",
+		}))
+	})
+
+	It("should report ConfigMaps without a Dockerfile", func() {
+		objs := []client.Object{
+			mod(kmmv1beta1.KernelMapping{Literal: "5.14.0", ContainerImage: "example.com/mod", Build: buildWithArgs()}),
+			dockerfileCM("", map[string]string{"Dockerfile": dockerfile}),
+		}
+
+		Expect(ValidateModules(objs, "ns")).To(Equal([]string{
+			"Module ns/mod: kernelMappings[0]: build: ConfigMap ns/dockerfile has no dockerfile key",
+		}))
+	})
+
+	It("should report unresolved and undeclared build arguments", func() {
+		m := mod(kmmv1beta1.KernelMapping{Literal: "5.14.0", ContainerImage: "example.com/mod"})
+		m.Spec.ModuleLoader.Container.Build = buildWithArgs("UNKNOWN")
+
+		objs := []client.Object{
+			m,
+			dockerfileCM("ns", map[string]string{"dockerfile": dockerfile}),
+		}
+
+		Expect(ValidateModules(objs, "ns")).To(ConsistOf(
+			"Module ns/mod: kernelMappings[0]: build: ARG DTK_AUTO has no default value and is not set in buildArgs",
+			"Module ns/mod: kernelMappings[0]: build: build argument UNKNOWN is not declared with ARG in the Dockerfile",
+		))
+	})
+})
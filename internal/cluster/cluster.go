@@ -17,6 +17,7 @@ import (
 	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/build"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/rbac"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/sign"
 )
 
@@ -38,6 +39,7 @@ type clusterAPI struct {
 	kernelAPI           module.KernelMapper
 	buildAPI            build.Manager
 	signAPI             sign.SignManager
+	rbacAPI             rbac.RBACCreator
 	defaultJobNamespace string
 }
 
@@ -46,12 +48,14 @@ func NewClusterAPI(
 	kernelAPI module.KernelMapper,
 	buildAPI build.Manager,
 	signAPI sign.SignManager,
+	rbacAPI rbac.RBACCreator,
 	defaultJobNamespace string) ClusterAPI {
 	return &clusterAPI{
 		client:              client,
 		kernelAPI:           kernelAPI,
 		buildAPI:            buildAPI,
 		signAPI:             signAPI,
+		rbacAPI:             rbacAPI,
 		defaultJobNamespace: defaultJobNamespace,
 	}
 }
@@ -211,6 +215,10 @@ func (c *clusterAPI) build(
 		return false, nil
 	}
 
+	if err = c.rbacAPI.CreateBuildServiceAccount(ctx, mod, mcm); err != nil {
+		return false, fmt.Errorf("could not create the build ServiceAccount: %v", err)
+	}
+
 	logger := log.FromContext(ctx).WithValues(
 		"kernel version", kernelVersion,
 		"image", kernelMapping.ContainerImage)
@@ -239,6 +247,10 @@ func (c *clusterAPI) sign(
 		return false, nil
 	}
 
+	if err = c.rbacAPI.CreateBuildServiceAccount(ctx, mod, mcm); err != nil {
+		return false, fmt.Errorf("could not create the build ServiceAccount: %v", err)
+	}
+
 	// if we need to sign AND we've built, then we must have built
 	// the intermediate image so must figure out its name
 	previousImage := ""
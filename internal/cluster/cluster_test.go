@@ -17,6 +17,7 @@ import (
 	"github.com/kubernetes-sigs/kernel-module-management/internal/build"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/client"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/rbac"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/sign"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/utils"
 )
@@ -28,6 +29,7 @@ var _ = Describe("ClusterAPI", func() {
 		mockKM *module.MockKernelMapper
 		mockBM *build.MockManager
 		mockSM *sign.MockSignManager
+		mockRC *rbac.MockRBACCreator
 	)
 
 	BeforeEach(func() {
@@ -36,6 +38,7 @@ var _ = Describe("ClusterAPI", func() {
 		mockKM = module.NewMockKernelMapper(ctrl)
 		mockBM = build.NewMockManager(ctrl)
 		mockSM = sign.NewMockSignManager(ctrl)
+		mockRC = rbac.NewMockRBACCreator(ctrl)
 	})
 
 	const (
@@ -74,7 +77,7 @@ var _ = Describe("ClusterAPI", func() {
 				),
 			)
 
-			c := NewClusterAPI(clnt, mockKM, nil, nil, "")
+			c := NewClusterAPI(clnt, mockKM, nil, nil, nil, "")
 
 			res, err := c.RequestedManagedClusterModule(ctx, nsn)
 
@@ -94,7 +97,7 @@ var _ = Describe("ClusterAPI", func() {
 				clnt.EXPECT().Get(ctx, nsn, gomock.Any()).Return(errors.New("generic-error")),
 			)
 
-			c := NewClusterAPI(clnt, mockKM, nil, nil, "")
+			c := NewClusterAPI(clnt, mockKM, nil, nil, nil, "")
 
 			res, err := c.RequestedManagedClusterModule(ctx, nsn)
 
@@ -139,7 +142,7 @@ var _ = Describe("ClusterAPI", func() {
 				),
 			)
 
-			c := NewClusterAPI(clnt, mockKM, nil, nil, "")
+			c := NewClusterAPI(clnt, mockKM, nil, nil, nil, "")
 
 			res, err := c.SelectedManagedClusters(ctx, mcm)
 
@@ -154,7 +157,7 @@ var _ = Describe("ClusterAPI", func() {
 				clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any()).Return(errors.New("generic-error")),
 			)
 
-			c := NewClusterAPI(clnt, mockKM, nil, nil, "")
+			c := NewClusterAPI(clnt, mockKM, nil, nil, nil, "")
 
 			res, err := c.SelectedManagedClusters(ctx, &hubv1beta1.ManagedClusterModule{})
 
@@ -211,7 +214,7 @@ var _ = Describe("ClusterAPI", func() {
 				mockKM.EXPECT().FindMappingForKernel(mcm.Spec.ModuleSpec.ModuleLoader.Container.KernelMappings, kernelVersion).Return(nil, errors.New("generic-error")),
 			)
 
-			c := NewClusterAPI(clnt, mockKM, mockBM, mockSM, "")
+			c := NewClusterAPI(clnt, mockKM, mockBM, mockSM, mockRC, "")
 
 			requeue, err := c.BuildAndSign(ctx, *mcm, clusterList.Items[0])
 			Expect(err).ToNot(HaveOccurred())
@@ -256,7 +259,7 @@ var _ = Describe("ClusterAPI", func() {
 
 			ctx := context.Background()
 
-			c := NewClusterAPI(clnt, mockKM, mockBM, mockSM, "")
+			c := NewClusterAPI(clnt, mockKM, mockBM, mockSM, mockRC, "")
 
 			requeue, err := c.BuildAndSign(ctx, *mcm, clusterList.Items[0])
 			Expect(err).To(HaveOccurred())
@@ -327,7 +330,7 @@ var _ = Describe("ClusterAPI", func() {
 				mockSM.EXPECT().ShouldSync(gomock.Any(), mod, mappings[0]).Return(false, nil),
 			)
 
-			c := NewClusterAPI(clnt, mockKM, mockBM, mockSM, "")
+			c := NewClusterAPI(clnt, mockKM, mockBM, mockSM, mockRC, "")
 
 			requeue, err := c.BuildAndSign(ctx, *mcm, clusterList.Items[0])
 			Expect(err).ToNot(HaveOccurred())
@@ -395,11 +398,12 @@ var _ = Describe("ClusterAPI", func() {
 				mockKM.EXPECT().FindMappingForKernel(mcm.Spec.ModuleSpec.ModuleLoader.Container.KernelMappings, kernelVersion).Return(&mappings[0], nil),
 				mockKM.EXPECT().PrepareKernelMapping(&mappings[0], &osConfig).Return(&mappings[0], nil),
 				mockBM.EXPECT().ShouldSync(gomock.Any(), mod, mappings[0]).Return(true, nil),
+				mockRC.EXPECT().CreateBuildServiceAccount(gomock.Any(), mod, mcm).Return(nil),
 				mockBM.EXPECT().Sync(gomock.Any(), mod, mappings[0], kernelVersion, true, mcm),
 				mockSM.EXPECT().ShouldSync(gomock.Any(), mod, mappings[0]).Return(false, nil),
 			)
 
-			c := NewClusterAPI(clnt, mockKM, mockBM, mockSM, "")
+			c := NewClusterAPI(clnt, mockKM, mockBM, mockSM, mockRC, "")
 
 			requeue, err := c.BuildAndSign(ctx, *mcm, clusterList.Items[0])
 			Expect(err).ToNot(HaveOccurred())
@@ -467,10 +471,11 @@ var _ = Describe("ClusterAPI", func() {
 				mockKM.EXPECT().FindMappingForKernel(mcm.Spec.ModuleSpec.ModuleLoader.Container.KernelMappings, kernelVersion).Return(&mappings[0], nil),
 				mockKM.EXPECT().PrepareKernelMapping(&mappings[0], &osConfig).Return(&mappings[0], nil),
 				mockBM.EXPECT().ShouldSync(gomock.Any(), mod, mappings[0]).Return(true, nil),
+				mockRC.EXPECT().CreateBuildServiceAccount(gomock.Any(), mod, mcm).Return(nil),
 				mockBM.EXPECT().Sync(gomock.Any(), mod, mappings[0], kernelVersion, true, mcm).Return(build.Result{}, errors.New("test-error")),
 			)
 
-			c := NewClusterAPI(clnt, mockKM, mockBM, mockSM, "")
+			c := NewClusterAPI(clnt, mockKM, mockBM, mockSM, mockRC, "")
 
 			requeue, err := c.BuildAndSign(ctx, *mcm, clusterList.Items[0])
 			Expect(err).To(HaveOccurred())
@@ -539,10 +544,11 @@ var _ = Describe("ClusterAPI", func() {
 				mockKM.EXPECT().PrepareKernelMapping(&mappings[0], &osConfig).Return(&mappings[0], nil),
 				mockBM.EXPECT().ShouldSync(gomock.Any(), mod, mappings[0]).Return(false, nil),
 				mockSM.EXPECT().ShouldSync(gomock.Any(), mod, mappings[0]).Return(true, nil),
+				mockRC.EXPECT().CreateBuildServiceAccount(gomock.Any(), mod, mcm).Return(nil),
 				mockSM.EXPECT().Sync(gomock.Any(), mod, mappings[0], kernelVersion, "", true, mcm),
 			)
 
-			c := NewClusterAPI(clnt, mockKM, mockBM, mockSM, "")
+			c := NewClusterAPI(clnt, mockKM, mockBM, mockSM, mockRC, "")
 
 			requeue, err := c.BuildAndSign(ctx, *mcm, clusterList.Items[0])
 			Expect(err).ToNot(HaveOccurred())
@@ -611,10 +617,11 @@ var _ = Describe("ClusterAPI", func() {
 				mockKM.EXPECT().PrepareKernelMapping(&mappings[0], &osConfig).Return(&mappings[0], nil),
 				mockBM.EXPECT().ShouldSync(gomock.Any(), mod, mappings[0]).Return(false, nil),
 				mockSM.EXPECT().ShouldSync(gomock.Any(), mod, mappings[0]).Return(true, nil),
+				mockRC.EXPECT().CreateBuildServiceAccount(gomock.Any(), mod, mcm).Return(nil),
 				mockSM.EXPECT().Sync(gomock.Any(), mod, mappings[0], kernelVersion, "", true, mcm).Return(utils.Result{}, errors.New("test-error")),
 			)
 
-			c := NewClusterAPI(clnt, mockKM, mockBM, mockSM, "")
+			c := NewClusterAPI(clnt, mockKM, mockBM, mockSM, mockRC, "")
 
 			requeue, err := c.BuildAndSign(ctx, *mcm, clusterList.Items[0])
 			Expect(err).To(HaveOccurred())
@@ -682,12 +689,14 @@ var _ = Describe("ClusterAPI", func() {
 				mockKM.EXPECT().FindMappingForKernel(mcm.Spec.ModuleSpec.ModuleLoader.Container.KernelMappings, kernelVersion).Return(&mappings[0], nil),
 				mockKM.EXPECT().PrepareKernelMapping(&mappings[0], &osConfig).Return(&mappings[0], nil),
 				mockBM.EXPECT().ShouldSync(gomock.Any(), mod, mappings[0]).Return(true, nil),
+				mockRC.EXPECT().CreateBuildServiceAccount(gomock.Any(), mod, mcm).Return(nil),
 				mockBM.EXPECT().Sync(gomock.Any(), mod, mappings[0], kernelVersion, true, mcm),
 				mockSM.EXPECT().ShouldSync(gomock.Any(), mod, mappings[0]).Return(true, nil),
+				mockRC.EXPECT().CreateBuildServiceAccount(gomock.Any(), mod, mcm).Return(nil),
 				mockSM.EXPECT().Sync(gomock.Any(), mod, mappings[0], kernelVersion, "", true, mcm),
 			)
 
-			c := NewClusterAPI(clnt, mockKM, mockBM, mockSM, "")
+			c := NewClusterAPI(clnt, mockKM, mockBM, mockSM, mockRC, "")
 
 			requeue, err := c.BuildAndSign(ctx, *mcm, clusterList.Items[0])
 			Expect(err).ToNot(HaveOccurred())
@@ -756,12 +765,14 @@ var _ = Describe("ClusterAPI", func() {
 				mockKM.EXPECT().FindMappingForKernel(mcm.Spec.ModuleSpec.ModuleLoader.Container.KernelMappings, kernelVersion).Return(&mappings[0], nil),
 				mockKM.EXPECT().PrepareKernelMapping(&mappings[0], &osConfig).Return(&mappings[0], nil),
 				mockBM.EXPECT().ShouldSync(gomock.Any(), mod, mappings[0]).Return(true, nil),
+				mockRC.EXPECT().CreateBuildServiceAccount(gomock.Any(), mod, mcm).Return(nil),
 				mockBM.EXPECT().Sync(gomock.Any(), mod, mappings[0], kernelVersion, true, mcm),
 				mockSM.EXPECT().ShouldSync(gomock.Any(), mod, mappings[0]).Return(true, nil),
+				mockRC.EXPECT().CreateBuildServiceAccount(gomock.Any(), mod, mcm).Return(nil),
 				mockSM.EXPECT().Sync(gomock.Any(), mod, mappings[0], kernelVersion, "", true, mcm),
 			)
 
-			c := NewClusterAPI(clnt, mockKM, mockBM, mockSM, defaultJobNamespace)
+			c := NewClusterAPI(clnt, mockKM, mockBM, mockSM, mockRC, defaultJobNamespace)
 
 			requeue, err := c.BuildAndSign(ctx, *mcm, clusterList.Items[0])
 			Expect(err).ToNot(HaveOccurred())
@@ -788,7 +799,7 @@ var _ = Describe("ClusterAPI", func() {
 				mockBM.EXPECT().GarbageCollect(ctx, mcm.Name, mcm.Spec.JobNamespace, &mcm).Return(collectedBuilds, nil),
 			)
 
-			c := NewClusterAPI(clnt, nil, mockBM, nil, "")
+			c := NewClusterAPI(clnt, nil, mockBM, nil, nil, "")
 
 			collected, err := c.GarbageCollectBuilds(ctx, mcm)
 
@@ -811,7 +822,7 @@ var _ = Describe("ClusterAPI", func() {
 				mockBM.EXPECT().GarbageCollect(ctx, mcm.Name, mcm.Spec.JobNamespace, &mcm).Return(nil, errors.New("test")),
 			)
 
-			c := NewClusterAPI(clnt, nil, mockBM, nil, "")
+			c := NewClusterAPI(clnt, nil, mockBM, nil, nil, "")
 
 			_, err := c.GarbageCollectBuilds(ctx, mcm)
 
@@ -835,7 +846,7 @@ var _ = Describe("ClusterAPI", func() {
 				mockBM.EXPECT().GarbageCollect(ctx, mcm.Name, defaultJobNamespace, &mcm).Return(collectedBuilds, nil),
 			)
 
-			c := NewClusterAPI(clnt, nil, mockBM, nil, defaultJobNamespace)
+			c := NewClusterAPI(clnt, nil, mockBM, nil, nil, defaultJobNamespace)
 
 			collected, err := c.GarbageCollectBuilds(ctx, mcm)
 
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"flag"
+
+	"k8s.io/client-go/rest"
+)
+
+// ClientOptions holds the rate limiting settings of the Kubernetes API client used by a manager.
+// Their defaults match the ones used by controller-runtime.
+type ClientOptions struct {
+	QPS   float64
+	Burst int
+}
+
+// BindFlags registers the flags that set o's fields in fs.
+func (o *ClientOptions) BindFlags(fs *flag.FlagSet) {
+	fs.Float64Var(&o.QPS, "client-qps", 20, "The maximum number of queries per second sent to the API server.")
+	fs.IntVar(&o.Burst, "client-burst", 30, "The number of queries that may be sent to the API server in a burst above the QPS.")
+}
+
+// Apply sets the client rate limiting settings in cfg.
+func (o *ClientOptions) Apply(cfg *rest.Config) {
+	cfg.QPS = float32(o.QPS)
+	cfg.Burst = o.Burst
+}
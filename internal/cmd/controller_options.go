@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"flag"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+)
+
+// ControllerOptions holds the concurrency and rate limiting settings applied to all controllers started by a
+// manager.
+// Their defaults match the ones used by controller-runtime.
+type ControllerOptions struct {
+	MaxConcurrentReconciles int
+	RateLimiterBaseDelay    time.Duration
+	RateLimiterMaxDelay     time.Duration
+	RateLimiterQPS          float64
+	RateLimiterBurst        int
+}
+
+// BindFlags registers the flags that set o's fields in fs.
+func (o *ControllerOptions) BindFlags(fs *flag.FlagSet) {
+	fs.IntVar(
+		&o.MaxConcurrentReconciles,
+		"max-concurrent-reconciles",
+		0,
+		"The maximum number of concurrent reconciliations per controller. "+
+			"If 0, the value from controller.groupKindConcurrency in the configuration file is used, or 1 if unset.",
+	)
+
+	fs.DurationVar(
+		&o.RateLimiterBaseDelay,
+		"rate-limiter-base-delay",
+		5*time.Millisecond,
+		"The delay before requeuing an object after its first failed reconciliation; it doubles on each subsequent failure.",
+	)
+
+	fs.DurationVar(
+		&o.RateLimiterMaxDelay,
+		"rate-limiter-max-delay",
+		1000*time.Second,
+		"The maximum delay before requeuing an object after a failed reconciliation.",
+	)
+
+	fs.Float64Var(
+		&o.RateLimiterQPS,
+		"rate-limiter-qps",
+		10,
+		"The overall number of reconciliation requests per second each controller adds to its queue.",
+	)
+
+	fs.IntVar(
+		&o.RateLimiterBurst,
+		"rate-limiter-burst",
+		100,
+		"The number of reconciliation requests each controller may add to its queue in a burst above the QPS.",
+	)
+}
+
+// ControllerOptions returns the controller.Options that should be passed to all controllers.
+func (o *ControllerOptions) ControllerOptions() controller.Options {
+	return controller.Options{
+		MaxConcurrentReconciles: o.MaxConcurrentReconciles,
+		RateLimiter: workqueue.NewMaxOfRateLimiter(
+			workqueue.NewItemExponentialFailureRateLimiter(o.RateLimiterBaseDelay, o.RateLimiterMaxDelay),
+			&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(o.RateLimiterQPS), o.RateLimiterBurst)},
+		),
+	}
+}
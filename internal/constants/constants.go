@@ -9,6 +9,56 @@ const (
 	JobHashAnnotation    = "kmm.node.kubernetes.io/last-hash"
 	KernelLabel          = "kmm.node.kubernetes.io/kernel-version.full"
 
+	// ModuleLoadRejectedLabel is the key of the label or taint set on nodes on which the kernel rejected a module.
+	ModuleLoadRejectedLabel = "kmm.node.kubernetes.io/module-load-rejected"
+
+	// ModuleLoadFailureNodeCondition is the type of the node condition, in the style of the Node Problem Detector,
+	// reporting that module-loaders repeatedly failed to load their module on the node.
+	ModuleLoadFailureNodeCondition = "KernelModuleLoadFailure"
+	// CordonedForModuleLoadFailureAnnotation is the key of the annotation set on the nodes that the operator cordoned
+	// because of ModuleLoadFailureNodeCondition, so that it only uncordons those.
+	CordonedForModuleLoadFailureAnnotation = "kmm.node.kubernetes.io/cordoned-for-module-load-failure"
+
+	// RebuildKernelsAnnotation is the key of the Module annotation listing, separated by commas, the kernel versions
+	// for which the failed build and sign Jobs should be created again.
+	RebuildKernelsAnnotation = "kmm.node.kubernetes.io/rebuild-kernels"
+
+	// RetryBuildAnnotation is the key of the Module annotation listing, separated by commas, the kernel versions for
+	// which the failed build and sign Jobs should be created again once per value of the annotation.
+	// Unlike RebuildKernelsAnnotation, the operator does not remove it.
+	RetryBuildAnnotation = "kmm.node.kubernetes.io/retry-build"
+	// RetryBuildHandledAnnotation is the key of the Module annotation in which the operator records the last value of
+	// RetryBuildAnnotation that it acted upon.
+	RetryBuildHandledAnnotation = "kmm.node.kubernetes.io/retry-build-handled"
+
+	// JobQueueNameLabel is the key of the label naming the Kueue LocalQueue through which a Job is admitted.
+	JobQueueNameLabel = "kueue.x-k8s.io/queue-name"
+
+	// ModprobeAnnotation is the key of the module-loader pod annotation holding, in JSON, the modprobe spec of its
+	// Module, from which node cleanup Jobs build their unload command once the Module is gone.
+	ModprobeAnnotation = "kmm.node.kubernetes.io/modprobe"
+
+	// WaitForModulesAnnotation is the key of the DaemonSet annotation listing, separated by commas, the Modules whose
+	// module-loader must be ready on a node before the DaemonSet runs pods there.
+	WaitForModulesAnnotation = "kmm.node.kubernetes.io/wait-for-modules"
+
+	// MachineDeploymentKernelVersionAnnotation is the key of the Cluster API MachineDeployment annotation giving the
+	// kernel version of the machine image that the MachineDeployment rolls out.
+	MachineDeploymentKernelVersionAnnotation = "kmm.node.kubernetes.io/machine-kernel-version"
+
+	// ReadinessGroupLabel is the key of the Module label naming the readiness group of the Module: nodes get the
+	// ready label of a group once all Modules of that group targeting them are loaded.
+	ReadinessGroupLabel = "kmm.node.kubernetes.io/readiness-group"
+
+	// ModulesNotReadyTaint is the key of the NoSchedule taint with which nodes may register, for the operator to
+	// remove it once all the Modules targeting them are ready.
+	// module-loader pods tolerate it.
+	ModulesNotReadyTaint = "kmm.node.kubernetes.io/modules-not-ready"
+
+	// CompanionNameLabel is the key of the label naming the companion that a Deployment runs, along with
+	// ModuleNameLabel.
+	CompanionNameLabel = "kmm.node.kubernetes.io/companion.name"
+
 	ManagedClusterModuleNameLabel = "kmm.node.kubernetes.io/managedclustermodule.name"
 	DockerfileCMKey               = "dockerfile"
 	PublicSignDataKey             = "cert"
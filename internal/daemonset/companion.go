@@ -0,0 +1,128 @@
+package daemonset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/utils"
+)
+
+// CompanionRole is the value of the DaemonSetRole label of companion pods, which run neither a module-loader nor a
+// device-plugin.
+const CompanionRole = "companion"
+
+// CompanionDeploymentName returns the name of the Deployment running the companion companionName of a Module.
+func CompanionDeploymentName(moduleName, companionName string) string {
+	return fmt.Sprintf("%s-companion-%s", moduleName, companionName)
+}
+
+// SetCompanionAsDesired sets the spec of deploy so that it runs companion, and makes mod its controller.
+func (dc *daemonSetGenerator) SetCompanionAsDesired(
+	ctx context.Context,
+	deploy *appsv1.Deployment,
+	mod *kmmv1beta1.Module,
+	companion *kmmv1beta1.CompanionSpec,
+) error {
+	if deploy == nil {
+		return errors.New("deploy cannot be nil")
+	}
+
+	if companion == nil {
+		return errors.New("companion cannot be nil")
+	}
+
+	standardLabels := map[string]string{
+		constants.ModuleNameLabel:    mod.Name,
+		constants.CompanionNameLabel: companion.Name,
+	}
+
+	// The role is left out of the selector, which is immutable, so that Deployments created without it can be updated.
+	podLabels := OverrideLabels(
+		map[string]string{constants.DaemonSetRole: CompanionRole},
+		standardLabels,
+	)
+
+	deploy.SetLabels(
+		OverrideLabels(deploy.GetLabels(), podLabels),
+	)
+
+	deploy.Spec = appsv1.DeploymentSpec{
+		Replicas: companion.Replicas,
+		Selector: &metav1.LabelSelector{MatchLabels: standardLabels},
+		Template: v1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: podLabels},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Args:            companion.Container.Args,
+						Command:         companion.Container.Command,
+						Env:             companion.Container.Env,
+						Name:            companion.Name,
+						Image:           companion.Container.Image,
+						ImagePullPolicy: companion.Container.ImagePullPolicy,
+						Resources:       companion.Container.Resources,
+						VolumeMounts:    companion.Container.VolumeMounts,
+					},
+				},
+				ImagePullSecrets:   GetPodPullSecrets(mod.Spec.ImageRepoSecret),
+				NodeSelector:       companion.NodeSelector,
+				ServiceAccountName: companion.ServiceAccountName,
+				Volumes:            companion.Volumes,
+			},
+		},
+	}
+
+	utils.SetDeploymentDefaults(&deploy.Spec)
+
+	return controllerutil.SetControllerReference(mod, deploy, dc.scheme)
+}
+
+// GarbageCollectCompanions deletes the Deployments of the companions that mod does not declare anymore, and returns
+// their names.
+func (dc *daemonSetGenerator) GarbageCollectCompanions(ctx context.Context, mod *kmmv1beta1.Module) ([]string, error) {
+	deployList := appsv1.DeploymentList{}
+
+	opts := []client.ListOption{
+		client.MatchingLabels{constants.ModuleNameLabel: mod.Name},
+		client.HasLabels{constants.CompanionNameLabel},
+		client.InNamespace(mod.Namespace),
+	}
+
+	if err := dc.client.List(ctx, &deployList, opts...); err != nil {
+		return nil, fmt.Errorf("could not list Deployments: %v", err)
+	}
+
+	companions := sets.NewString()
+
+	for _, c := range mod.Spec.Companions {
+		companions.Insert(c.Name)
+	}
+
+	deleted := make([]string, 0)
+
+	for i := 0; i < len(deployList.Items); i++ {
+		deploy := &deployList.Items[i]
+
+		if companions.Has(deploy.Labels[constants.CompanionNameLabel]) {
+			continue
+		}
+
+		if err := dc.client.Delete(ctx, deploy); client.IgnoreNotFound(err) != nil {
+			return nil, fmt.Errorf("could not delete Deployment %s: %v", deploy.Name, err)
+		}
+
+		deleted = append(deleted, deploy.Name)
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,165 @@
+package daemonset
+
+import (
+	"context"
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/client"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+)
+
+var _ = Describe("SetCompanionAsDesired", func() {
+	const companionName = "licensing"
+
+	dg := NewCreator(nil, kernelLabel, scheme, DefaultModuleLoaderSELinuxType, false, "")
+
+	mod := kmmv1beta1.Module{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      moduleName,
+			Namespace: namespace,
+			UID:       "some-uid",
+		},
+		Spec: kmmv1beta1.ModuleSpec{
+			ImageRepoSecret: &v1.LocalObjectReference{Name: "pull-secret"},
+		},
+	}
+
+	It("should return an error if the Deployment is nil", func() {
+		Expect(
+			dg.SetCompanionAsDesired(context.Background(), nil, &mod, &kmmv1beta1.CompanionSpec{}),
+		).To(
+			HaveOccurred(),
+		)
+	})
+
+	It("should run the companion in a Deployment controlled by the Module", func() {
+		companion := kmmv1beta1.CompanionSpec{
+			Name: companionName,
+			Container: kmmv1beta1.CompanionContainerSpec{
+				Args:  []string{"--port", "8080"},
+				Image: "quay.io/vendor/licensing:1.0",
+			},
+			NodeSelector:       map[string]string{"node-role.kubernetes.io/infra": ""},
+			Replicas:           pointer.Int32(2),
+			ServiceAccountName: "licensing",
+		}
+
+		deploy := appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      CompanionDeploymentName(moduleName, companionName),
+				Namespace: namespace,
+				Labels:    map[string]string{"some-label": "some-value"},
+			},
+		}
+
+		err := dg.SetCompanionAsDesired(context.Background(), &deploy, &mod, &companion)
+		Expect(err).NotTo(HaveOccurred())
+
+		standardLabels := map[string]string{
+			constants.ModuleNameLabel:    moduleName,
+			constants.CompanionNameLabel: companionName,
+		}
+
+		Expect(deploy.Name).To(Equal("module-name-companion-licensing"))
+		Expect(deploy.Labels).To(HaveKeyWithValue("some-label", "some-value"))
+		Expect(deploy.Labels).To(HaveKeyWithValue(constants.CompanionNameLabel, companionName))
+		Expect(deploy.Spec.Replicas).To(Equal(pointer.Int32(2)))
+		Expect(deploy.Spec.Selector.MatchLabels).To(Equal(standardLabels))
+		Expect(deploy.Spec.Template.Labels).To(Equal(map[string]string{
+			constants.ModuleNameLabel:    moduleName,
+			constants.CompanionNameLabel: companionName,
+			constants.DaemonSetRole:      CompanionRole,
+		}))
+
+		podSpec := deploy.Spec.Template.Spec
+
+		Expect(podSpec.Containers).To(HaveLen(1))
+		Expect(podSpec.Containers[0].Name).To(Equal(companionName))
+		Expect(podSpec.Containers[0].Image).To(Equal("quay.io/vendor/licensing:1.0"))
+		Expect(podSpec.Containers[0].Args).To(Equal([]string{"--port", "8080"}))
+		Expect(podSpec.Containers[0].SecurityContext).To(BeNil())
+		Expect(podSpec.ImagePullSecrets).To(Equal([]v1.LocalObjectReference{{Name: "pull-secret"}}))
+		Expect(podSpec.NodeSelector).To(Equal(companion.NodeSelector))
+		Expect(podSpec.ServiceAccountName).To(Equal("licensing"))
+
+		Expect(deploy.OwnerReferences).To(HaveLen(1))
+		Expect(deploy.OwnerReferences[0].UID).To(Equal(mod.UID))
+		Expect(*deploy.OwnerReferences[0].Controller).To(BeTrue())
+	})
+
+	It("should default the number of replicas to 1", func() {
+		deploy := appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace},
+		}
+
+		err := dg.SetCompanionAsDesired(context.Background(), &deploy, &mod, &kmmv1beta1.CompanionSpec{Name: companionName})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deploy.Spec.Replicas).To(Equal(pointer.Int32(1)))
+	})
+})
+
+var _ = Describe("GarbageCollectCompanions", func() {
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+	})
+
+	mod := kmmv1beta1.Module{
+		ObjectMeta: metav1.ObjectMeta{Name: moduleName, Namespace: namespace},
+		Spec: kmmv1beta1.ModuleSpec{
+			Companions: []kmmv1beta1.CompanionSpec{{Name: "licensing"}},
+		},
+	}
+
+	companionDeployment := func(companionName string) appsv1.Deployment {
+		return appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      CompanionDeploymentName(moduleName, companionName),
+				Namespace: namespace,
+				Labels: map[string]string{
+					constants.ModuleNameLabel:    moduleName,
+					constants.CompanionNameLabel: companionName,
+				},
+			},
+		}
+	}
+
+	It("should only delete the Deployments of companions that the Module does not declare", func() {
+		licensing := companionDeployment("licensing")
+		telemetry := companionDeployment("telemetry")
+
+		gomock.InOrder(
+			clnt.EXPECT().List(context.Background(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ interface{}, list *appsv1.DeploymentList, _ ...interface{}) error {
+					list.Items = []appsv1.Deployment{licensing, telemetry}
+					return nil
+				},
+			),
+			clnt.EXPECT().Delete(context.Background(), &telemetry),
+		)
+
+		dc := NewCreator(clnt, kernelLabel, scheme, DefaultModuleLoaderSELinuxType, false, "")
+
+		res, err := dc.GarbageCollectCompanions(context.Background(), &mod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res).To(Equal([]string{telemetry.Name}))
+	})
+
+	It("should return an error if the Deployments cannot be listed", func() {
+		clnt.EXPECT().List(context.Background(), gomock.Any(), gomock.Any()).Return(errors.New("some error"))
+
+		dc := NewCreator(clnt, kernelLabel, scheme, DefaultModuleLoaderSELinuxType, false, "")
+
+		_, err := dc.GarbageCollectCompanions(context.Background(), &mod)
+		Expect(err).To(HaveOccurred())
+	})
+})
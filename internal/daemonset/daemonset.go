@@ -2,15 +2,19 @@ package daemonset
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"strings"
 
 	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/rbac"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/utils"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -26,29 +30,88 @@ const (
 	nodeVarLibFirmwarePath         = "/var/lib/firmware"
 	nodeVarLibFirmwareVolumeName   = "node-var-lib-firmware"
 	devicePluginKernelVersion      = ""
+	moduleLoaderContainerName      = "module-loader"
+	nodeRunVolumeName              = "node-run"
+	nodeRunMountPath               = "/host/run"
+	rebootMarkersPath              = "/var/lib/kmm/reboot"
+	rebootMarkersVolumeName        = "reboot-markers"
+	// rebootSentinelPath is where the module-loader sees /run/reboot-required, the file whose presence tells reboot
+	// daemons such as kured that the node must reboot.
+	rebootSentinelPath = nodeRunMountPath + "/reboot-required"
+
+	// DefaultModuleLoaderSELinuxType is the default SELinux type of module-loader containers.
+	// The default container type does not allow loading kernel modules.
+	DefaultModuleLoaderSELinuxType = "spc_t"
+)
+
+// The compute resources of the module-loader and device plugin containers of the Modules that set neither requests
+// nor limits, so that their pods are admitted in namespaces whose ResourceQuota requires them.
+var (
+	defaultModuleLoaderResources = v1.ResourceRequirements{
+		Requests: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("10m"),
+			v1.ResourceMemory: resource.MustParse("32Mi"),
+		},
+		Limits: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("500m"),
+			v1.ResourceMemory: resource.MustParse("256Mi"),
+		},
+	}
+
+	defaultDevicePluginResources = v1.ResourceRequirements{
+		Requests: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("10m"),
+			v1.ResourceMemory: resource.MustParse("64Mi"),
+		},
+		Limits: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("500m"),
+			v1.ResourceMemory: resource.MustParse("512Mi"),
+		},
+	}
 )
 
 //go:generate mockgen -source=daemonset.go -package=daemonset -destination=mock_daemonset.go
 
 type DaemonSetCreator interface {
 	GarbageCollect(ctx context.Context, existingDS map[string]*appsv1.DaemonSet, validKernels sets.String) ([]string, error)
+	GarbageCollectCompanions(ctx context.Context, mod *kmmv1beta1.Module) ([]string, error)
 	ModuleDaemonSetsByKernelVersion(ctx context.Context, name, namespace string) (map[string]*appsv1.DaemonSet, error)
-	SetDriverContainerAsDesired(ctx context.Context, ds *appsv1.DaemonSet, image string, mod kmmv1beta1.Module, kernelVersion string) error
+	SetDriverContainerAsDesired(ctx context.Context, ds *appsv1.DaemonSet, km kmmv1beta1.KernelMapping, mod kmmv1beta1.Module, kernelVersion string, osProfile OSProfile) error
 	SetDevicePluginAsDesired(ctx context.Context, ds *appsv1.DaemonSet, mod *kmmv1beta1.Module) error
+	SetCompanionAsDesired(ctx context.Context, deploy *appsv1.Deployment, mod *kmmv1beta1.Module, companion *kmmv1beta1.CompanionSpec) error
 	GetNodeLabelFromPod(pod *v1.Pod, moduleName string) string
 }
 
 type daemonSetGenerator struct {
-	client      client.Client
-	kernelLabel string
-	scheme      *runtime.Scheme
+	client             client.Client
+	kernelLabel        string
+	scheme             *runtime.Scheme
+	defaultSELinuxType string
+	restricted         bool
+	seccompProfile     string
 }
 
-func NewCreator(client client.Client, kernelLabel string, scheme *runtime.Scheme) DaemonSetCreator {
+// NewCreator returns a DaemonSetCreator.
+// defaultSELinuxType is the SELinux type of module-loader containers for Modules that do not specify SELinux options,
+// on nodes whose OSProfile has no type of its own; if it is empty, no SELinux options are set on those containers.
+// If restricted is true, module-loader pods only deviate from the "restricted" Pod Security Standard where loading
+// kernel modules requires it.
+// If seccompProfile is not empty, module-loader pods use that localhost seccomp profile, relative to the kubelet's
+// seccomp profile directory.
+func NewCreator(
+	client client.Client,
+	kernelLabel string,
+	scheme *runtime.Scheme,
+	defaultSELinuxType string,
+	restricted bool,
+	seccompProfile string) DaemonSetCreator {
 	return &daemonSetGenerator{
-		client:      client,
-		kernelLabel: kernelLabel,
-		scheme:      scheme,
+		client:             client,
+		kernelLabel:        kernelLabel,
+		scheme:             scheme,
+		defaultSELinuxType: defaultSELinuxType,
+		restricted:         restricted,
+		seccompProfile:     seccompProfile,
 	}
 }
 
@@ -90,12 +153,12 @@ func (dc *daemonSetGenerator) ModuleDaemonSetsByKernelVersion(ctx context.Contex
 	return dsByKernelVersion, nil
 }
 
-func (dc *daemonSetGenerator) SetDriverContainerAsDesired(ctx context.Context, ds *appsv1.DaemonSet, image string, mod kmmv1beta1.Module, kernelVersion string) error {
+func (dc *daemonSetGenerator) SetDriverContainerAsDesired(ctx context.Context, ds *appsv1.DaemonSet, km kmmv1beta1.KernelMapping, mod kmmv1beta1.Module, kernelVersion string, osProfile OSProfile) error {
 	if ds == nil {
 		return errors.New("ds cannot be nil")
 	}
 
-	if image == "" {
+	if km.ContainerImage == "" {
 		return errors.New("image cannot be empty")
 	}
 
@@ -116,20 +179,37 @@ func (dc *daemonSetGenerator) SetDriverContainerAsDesired(ctx context.Context, d
 	nodeSelector := CopyMapStringString(mod.Spec.Selector)
 	nodeSelector[dc.kernelLabel] = kernelVersion
 
+	// Nodes running kernelVersion that do not match the mapping's selector use another mapping, or none.
+	for k, v := range km.NodeSelector {
+		nodeSelector[k] = v
+	}
+
 	nodeLibModulesPath := "/lib/modules/" + kernelVersion
 
 	hostPathDirectory := v1.HostPathDirectory
 	hostPathDirectoryOrCreate := v1.HostPathDirectoryOrCreate
 
+	seLinuxOptions := mod.Spec.ModuleLoader.Container.SELinuxOptions
+	if seLinuxType := osProfile.seLinuxType(dc.defaultSELinuxType); seLinuxOptions == nil && seLinuxType != "" {
+		seLinuxOptions = &v1.SELinuxOptions{Type: seLinuxType}
+	}
+
+	loadCommand := MakeLoadCommand(mod.Spec.ModuleLoader.Container.Modprobe, mod.Name)
+
+	if mod.Spec.ModuleLoader.RebootRequired {
+		loadCommand[len(loadCommand)-1] += " && " + makeRebootRequestCommand(&mod, km.ContainerImage)
+	}
+
 	container := v1.Container{
 		Command:         []string{"sleep", "infinity"},
-		Name:            "module-loader",
-		Image:           image,
+		Name:            moduleLoaderContainerName,
+		Image:           km.ContainerImage,
 		ImagePullPolicy: mod.Spec.ModuleLoader.Container.ImagePullPolicy,
+		Resources:       containerResources(mod.Spec.ModuleLoader.Container.Resources, defaultModuleLoaderResources),
 		Lifecycle: &v1.Lifecycle{
 			PostStart: &v1.LifecycleHandler{
 				Exec: &v1.ExecAction{
-					Command: MakeLoadCommand(mod.Spec.ModuleLoader.Container.Modprobe, mod.Name),
+					Command: loadCommand,
 				},
 			},
 			PreStop: &v1.LifecycleHandler{
@@ -141,12 +221,11 @@ func (dc *daemonSetGenerator) SetDriverContainerAsDesired(ctx context.Context, d
 		SecurityContext: &v1.SecurityContext{
 			AllowPrivilegeEscalation: pointer.Bool(false),
 			Capabilities: &v1.Capabilities{
-				Add: []v1.Capability{"SYS_MODULE"},
-			},
-			RunAsUser: pointer.Int64(0),
-			SELinuxOptions: &v1.SELinuxOptions{
-				Type: "spc_t",
+				Add:  []v1.Capability{"SYS_MODULE"},
+				Drop: []v1.Capability{"ALL"},
 			},
+			RunAsUser:      pointer.Int64(0),
+			SELinuxOptions: seLinuxOptions,
 		},
 		VolumeMounts: []v1.VolumeMount{
 			{
@@ -189,29 +268,95 @@ func (dc *daemonSetGenerator) SetDriverContainerAsDesired(ctx context.Context, d
 		container.VolumeMounts = append(container.VolumeMounts, firmwareVolumeMount)
 	}
 
+	if mod.Spec.ModuleLoader.RebootRequired {
+		volumes = append(
+			volumes,
+			v1.Volume{
+				Name: nodeRunVolumeName,
+				VolumeSource: v1.VolumeSource{
+					HostPath: &v1.HostPathVolumeSource{
+						Path: "/run",
+						Type: &hostPathDirectory,
+					},
+				},
+			},
+			v1.Volume{
+				Name: rebootMarkersVolumeName,
+				VolumeSource: v1.VolumeSource{
+					HostPath: &v1.HostPathVolumeSource{
+						Path: rebootMarkersPath,
+						Type: &hostPathDirectoryOrCreate,
+					},
+				},
+			},
+		)
+
+		container.VolumeMounts = append(
+			container.VolumeMounts,
+			v1.VolumeMount{Name: nodeRunVolumeName, MountPath: nodeRunMountPath},
+			v1.VolumeMount{Name: rebootMarkersVolumeName, MountPath: rebootMarkersPath},
+		)
+	}
+
 	serviceAccountName := mod.Spec.ModuleLoader.ServiceAccountName
 	if serviceAccountName == "" {
 		serviceAccountName = rbac.GenerateModuleLoaderServiceAccountName(mod)
 	}
 
+	var podSecurityContext *v1.PodSecurityContext
+
+	if dc.restricted {
+		// The runtime's default profile allows loading kernel modules to containers that have CAP_SYS_MODULE.
+		podSecurityContext = &v1.PodSecurityContext{
+			SeccompProfile: &v1.SeccompProfile{Type: v1.SeccompProfileTypeRuntimeDefault},
+		}
+	}
+
+	if dc.seccompProfile != "" {
+		// Only allow the system calls needed by the load and unload commands.
+		podSecurityContext = &v1.PodSecurityContext{
+			SeccompProfile: &v1.SeccompProfile{
+				Type:             v1.SeccompProfileTypeLocalhost,
+				LocalhostProfile: pointer.String(dc.seccompProfile),
+			},
+		}
+	}
+
+	modprobe, err := json.Marshal(mod.Spec.ModuleLoader.Container.Modprobe)
+	if err != nil {
+		return fmt.Errorf("could not marshal the modprobe spec: %v", err)
+	}
+
 	ds.Spec = appsv1.DaemonSetSpec{
 		Template: v1.PodTemplateSpec{
 			ObjectMeta: metav1.ObjectMeta{
-				Labels:     standardLabels,
-				Finalizers: []string{constants.NodeLabelerFinalizer},
+				Annotations: map[string]string{constants.ModprobeAnnotation: string(modprobe)},
+				Labels:      standardLabels,
+				Finalizers:  []string{constants.NodeLabelerFinalizer},
 			},
 			Spec: v1.PodSpec{
 				Containers:         []v1.Container{container},
 				ImagePullSecrets:   GetPodPullSecrets(mod.Spec.ImageRepoSecret),
 				NodeSelector:       nodeSelector,
 				PriorityClassName:  "system-node-critical",
+				SecurityContext:    podSecurityContext,
 				ServiceAccountName: serviceAccountName,
-				Volumes:            volumes,
+				// module-loaders load the modules that nodes registered with the startup taint are waiting for.
+				Tolerations: []v1.Toleration{
+					{
+						Key:      constants.ModulesNotReadyTaint,
+						Operator: v1.TolerationOpExists,
+						Effect:   v1.TaintEffectNoSchedule,
+					},
+				},
+				Volumes: volumes,
 			},
 		},
 		Selector: &metav1.LabelSelector{MatchLabels: standardLabels},
 	}
 
+	utils.SetDaemonSetDefaults(&ds.Spec)
+
 	return controllerutil.SetControllerReference(&mod, ds, dc.scheme)
 }
 
@@ -273,7 +418,7 @@ func (dc *daemonSetGenerator) SetDevicePluginAsDesired(ctx context.Context, ds *
 						Name:            "device-plugin",
 						Image:           mod.Spec.DevicePlugin.Container.Image,
 						ImagePullPolicy: mod.Spec.DevicePlugin.Container.ImagePullPolicy,
-						Resources:       mod.Spec.DevicePlugin.Container.Resources,
+						Resources:       containerResources(mod.Spec.DevicePlugin.Container.Resources, defaultDevicePluginResources),
 						SecurityContext: &v1.SecurityContext{Privileged: pointer.Bool(true)},
 						VolumeMounts:    append(mod.Spec.DevicePlugin.Container.VolumeMounts, containerVolumeMounts...),
 					},
@@ -287,6 +432,8 @@ func (dc *daemonSetGenerator) SetDevicePluginAsDesired(ctx context.Context, ds *
 		},
 	}
 
+	utils.SetDaemonSetDefaults(&ds.Spec)
+
 	return controllerutil.SetControllerReference(mod, ds, dc.scheme)
 }
 
@@ -314,6 +461,15 @@ func (dc *daemonSetGenerator) isDevicePluginDaemonSet(ds *appsv1.DaemonSet) bool
 	return ds.Labels[dc.kernelLabel] == ""
 }
 
+// containerResources returns a copy of resources, or of defaults if resources set neither requests nor limits.
+func containerResources(resources, defaults v1.ResourceRequirements) v1.ResourceRequirements {
+	if len(resources.Requests) == 0 && len(resources.Limits) == 0 {
+		return *defaults.DeepCopy()
+	}
+
+	return *resources.DeepCopy()
+}
+
 // CopyMapStringString returns a deep copy of m.
 func CopyMapStringString(m map[string]string) map[string]string {
 	n := make(map[string]string, len(m))
@@ -329,10 +485,41 @@ func getDriverContainerNodeLabel(moduleName string) string {
 	return fmt.Sprintf("kmm.node.kubernetes.io/%s.ready", moduleName)
 }
 
+// ModuleReadyNodeLabel returns the label set on nodes on which the module-loader pod of moduleName is ready.
+func ModuleReadyNodeLabel(moduleName string) string {
+	return getDriverContainerNodeLabel(moduleName)
+}
+
+// IsModuleReadyNodeLabel returns true if label was returned by ModuleReadyNodeLabel.
+func IsModuleReadyNodeLabel(label string) bool {
+	return strings.HasPrefix(label, "kmm.node.kubernetes.io/") && strings.HasSuffix(label, ".ready")
+}
+
+// GroupReadyNodeLabel returns the label set on nodes on which all the Modules of the readiness group are ready.
+func GroupReadyNodeLabel(group string) string {
+	return fmt.Sprintf("kmm.node.kubernetes.io/%s.group-ready", group)
+}
+
+// IsGroupReadyNodeLabel returns true if label was returned by GroupReadyNodeLabel.
+func IsGroupReadyNodeLabel(label string) bool {
+	return strings.HasPrefix(label, "kmm.node.kubernetes.io/") && strings.HasSuffix(label, ".group-ready")
+}
+
 func getDevicePluginNodeLabel(moduleName string) string {
 	return fmt.Sprintf("kmm.node.kubernetes.io/%s.device-plugin-ready", moduleName)
 }
 
+// DriverContainerName returns the name of the module-loader DaemonSet for moduleName and kernelVersion.
+// The name only depends on that identity, so that any other change to the Module results in the same DaemonSet
+// being patched in place.
+// Kernel versions may contain characters that are not allowed in object names, hence the hash.
+func DriverContainerName(moduleName, kernelVersion string) string {
+	h := fnv.New32a()
+	h.Write([]byte(kernelVersion))
+
+	return fmt.Sprintf("%s-%08x", moduleName, h.Sum32())
+}
+
 func IsDevicePluginKernelVersion(kernelVersion string) bool {
 	return kernelVersion == devicePluginKernelVersion
 }
@@ -380,7 +567,7 @@ func MakeLoadCommand(spec kmmv1beta1.ModprobeSpec, modName string) []string {
 			loadCommand.WriteRune(' ')
 			loadCommand.WriteString(arg)
 		}
-		return append(loadCommandShell, loadCommand.String())
+		return append(loadCommandShell, reportLoadErrors(loadCommand.String()))
 	}
 
 	if args := spec.Args; args != nil && len(args.Load) > 0 {
@@ -405,7 +592,85 @@ func MakeLoadCommand(spec kmmv1beta1.ModprobeSpec, modName string) []string {
 		}
 	}
 
-	return append(loadCommandShell, loadCommand.String())
+	return append(loadCommandShell, reportLoadErrors(loadCommand.String()))
+}
+
+// makeRebootRequestCommand returns a command that creates the reboot sentinel file of the node the first time that image
+// runs there.
+// The last image that requested a reboot is recorded in a marker file on the host; unlike the sentinel file, which
+// lives in a tmpfs, it survives the reboot, so that the module-loader does not request another one once restarted.
+func makeRebootRequestCommand(mod *kmmv1beta1.Module, image string) string {
+	marker := fmt.Sprintf("%s/%s.%s", rebootMarkersPath, mod.Namespace, mod.Name)
+
+	return fmt.Sprintf(
+		`{ [ "$(cat %[1]s 2>/dev/null)" = %[2]q ] || { touch %[3]s && echo %[2]q > %[1]s; }; }`,
+		marker,
+		image,
+		rebootSentinelPath,
+	)
+}
+
+// reportLoadErrors makes the errors of the load command the termination message of the module-loader container, so
+// that modules rejected by the kernel can be reported by the operator. They are also printed, so that they appear in
+// the FailedPostStartHook event.
+func reportLoadErrors(cmd string) string {
+	return fmt.Sprintf(
+		"{ %s; } 2>%s || { cat %s >&2; exit 1; }",
+		cmd,
+		v1.TerminationMessagePathDefault,
+		v1.TerminationMessagePathDefault,
+	)
+}
+
+// ModuleLoaderRestarts returns the number of times the module-loader container of pod restarted.
+// The container restarts every time its load command fails.
+func ModuleLoaderRestarts(pod *v1.Pod) int32 {
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Name == moduleLoaderContainerName {
+			return cs.RestartCount
+		}
+	}
+
+	return 0
+}
+
+// ModuleLoadRejection returns the reason why the kernel rejected the module loaded by the module-loader pod, along with
+// the error reported by the load command.
+// The reason is empty if the module was not rejected by the kernel.
+func ModuleLoadRejection(pod *v1.Pod) (string, string) {
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Name != moduleLoaderContainerName {
+			continue
+		}
+
+		for _, t := range []*v1.ContainerStateTerminated{cs.State.Terminated, cs.LastTerminationState.Terminated} {
+			if t == nil {
+				continue
+			}
+
+			for _, r := range loadRejections {
+				if strings.Contains(t.Message, r.errorMessage) {
+					return r.reason, strings.TrimSpace(t.Message)
+				}
+			}
+		}
+	}
+
+	return "", ""
+}
+
+// The errors printed by modprobe when the kernel refuses to load a module.
+var loadRejections = []struct {
+	errorMessage string
+	reason       string
+}{
+	// EKEYREJECTED: the module's signature could not be verified
+	{errorMessage: "Key was rejected by service", reason: "SignatureRejected"},
+	// ENOKEY: the module is not signed, or signed by an unknown key, and the kernel requires valid signatures
+	{errorMessage: "Required key not available", reason: "SignatureRejected"},
+	// ENOEXEC: the module was not built for this kernel, e.g. vermagic mismatch
+	{errorMessage: "Exec format error", reason: "InvalidModuleFormat"},
+	{errorMessage: "Invalid module format", reason: "InvalidModuleFormat"},
 }
 
 func MakeUnloadCommand(spec kmmv1beta1.ModprobeSpec, modName string) []string {
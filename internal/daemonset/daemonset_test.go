@@ -16,7 +16,9 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/utils/pointer"
 )
 
@@ -34,7 +36,8 @@ var (
 )
 
 var _ = Describe("SetDriverContainerAsDesired", func() {
-	dg := NewCreator(nil, kernelLabel, scheme)
+	dg := NewCreator(nil, kernelLabel, scheme, DefaultModuleLoaderSELinuxType, false, "")
+	km := kmmv1beta1.KernelMapping{ContainerImage: "test-image"}
 
 	BeforeEach(func() {
 		ctrl = gomock.NewController(GinkgoT())
@@ -43,7 +46,7 @@ var _ = Describe("SetDriverContainerAsDesired", func() {
 
 	It("should return an error if the DaemonSet is nil", func() {
 		Expect(
-			dg.SetDriverContainerAsDesired(context.Background(), nil, "", kmmv1beta1.Module{}, ""),
+			dg.SetDriverContainerAsDesired(context.Background(), nil, kmmv1beta1.KernelMapping{}, kmmv1beta1.Module{}, "", OSProfileDefault),
 		).To(
 			HaveOccurred(),
 		)
@@ -51,7 +54,7 @@ var _ = Describe("SetDriverContainerAsDesired", func() {
 
 	It("should return an error if the image is empty", func() {
 		Expect(
-			dg.SetDriverContainerAsDesired(context.Background(), &appsv1.DaemonSet{}, "", kmmv1beta1.Module{}, ""),
+			dg.SetDriverContainerAsDesired(context.Background(), &appsv1.DaemonSet{}, kmmv1beta1.KernelMapping{}, kmmv1beta1.Module{}, "", OSProfileDefault),
 		).To(
 			HaveOccurred(),
 		)
@@ -59,7 +62,7 @@ var _ = Describe("SetDriverContainerAsDesired", func() {
 
 	It("should return an error if the kernel version is empty", func() {
 		Expect(
-			dg.SetDriverContainerAsDesired(context.Background(), &appsv1.DaemonSet{}, "", kmmv1beta1.Module{}, ""),
+			dg.SetDriverContainerAsDesired(context.Background(), &appsv1.DaemonSet{}, km, kmmv1beta1.Module{}, "", OSProfileDefault),
 		).To(
 			HaveOccurred(),
 		)
@@ -74,7 +77,7 @@ var _ = Describe("SetDriverContainerAsDesired", func() {
 
 		ds := appsv1.DaemonSet{}
 
-		err := dg.SetDriverContainerAsDesired(context.Background(), &ds, "test-image", mod, kernelVersion)
+		err := dg.SetDriverContainerAsDesired(context.Background(), &ds, km, mod, kernelVersion, OSProfileDefault)
 		Expect(err).NotTo(HaveOccurred())
 		Expect(ds.Spec.Template.Spec.Containers).To(HaveLen(1))
 		Expect(ds.Spec.Template.Spec.Volumes).To(HaveLen(1))
@@ -114,7 +117,7 @@ var _ = Describe("SetDriverContainerAsDesired", func() {
 
 		ds := appsv1.DaemonSet{}
 
-		err := dg.SetDriverContainerAsDesired(context.Background(), &ds, "test-image", mod, kernelVersion)
+		err := dg.SetDriverContainerAsDesired(context.Background(), &ds, km, mod, kernelVersion, OSProfileDefault)
 		Expect(err).NotTo(HaveOccurred())
 		Expect(ds.Spec.Template.Spec.Volumes).To(HaveLen(2))
 		Expect(ds.Spec.Template.Spec.Volumes[1]).To(Equal(vol))
@@ -122,6 +125,67 @@ var _ = Describe("SetDriverContainerAsDesired", func() {
 		Expect(ds.Spec.Template.Spec.Containers[0].VolumeMounts[1]).To(Equal(volm))
 	})
 
+	It("should request a reboot after loading the module if RebootRequired is set", func() {
+		mod := kmmv1beta1.Module{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      moduleName,
+				Namespace: namespace,
+			},
+			Spec: kmmv1beta1.ModuleSpec{
+				ModuleLoader: kmmv1beta1.ModuleLoaderSpec{
+					Container: kmmv1beta1.ModuleLoaderContainerSpec{
+						Modprobe: kmmv1beta1.ModprobeSpec{ModuleName: "some-kmod"},
+					},
+					RebootRequired: true,
+				},
+			},
+		}
+
+		ds := appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace},
+		}
+
+		err := dg.SetDriverContainerAsDesired(context.Background(), &ds, km, mod, kernelVersion, OSProfileDefault)
+		Expect(err).NotTo(HaveOccurred())
+
+		marker := "/var/lib/kmm/reboot/" + namespace + "." + moduleName
+		loadCommand := MakeLoadCommand(mod.Spec.ModuleLoader.Container.Modprobe, moduleName)
+		loadCommand[2] += ` && { [ "$(cat ` + marker + ` 2>/dev/null)" = "test-image" ] || ` +
+			`{ touch /host/run/reboot-required && echo "test-image" > ` + marker + `; }; }`
+
+		container := ds.Spec.Template.Spec.Containers[0]
+		Expect(container.Lifecycle.PostStart.Exec.Command).To(Equal(loadCommand))
+		Expect(container.VolumeMounts).To(ContainElements(
+			v1.VolumeMount{Name: "node-run", MountPath: "/host/run"},
+			v1.VolumeMount{Name: "reboot-markers", MountPath: "/var/lib/kmm/reboot"},
+		))
+		Expect(ds.Spec.Template.Spec.Volumes).To(HaveLen(3))
+	})
+
+	It("should only select the nodes that match the node selector of the kernel mapping", func() {
+		mod := kmmv1beta1.Module{
+			Spec: kmmv1beta1.ModuleSpec{
+				Selector: map[string]string{"has-feature-x": "true"},
+			},
+		}
+
+		nvidiaMapping := kmmv1beta1.KernelMapping{
+			ContainerImage: "nvidia-image",
+			NodeSelector:   map[string]string{"feature.node.kubernetes.io/pci-10de.present": "true"},
+		}
+
+		ds := appsv1.DaemonSet{}
+
+		err := dg.SetDriverContainerAsDesired(context.Background(), &ds, nvidiaMapping, mod, kernelVersion, OSProfileDefault)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ds.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{
+			"has-feature-x": "true",
+			kernelLabel:     kernelVersion,
+			"feature.node.kubernetes.io/pci-10de.present": "true",
+		}))
+		Expect(mod.Spec.Selector).To(HaveLen(1))
+	})
+
 	It("should add the default ServiceAccount to the module loader if it is not set in the spec", func() {
 		mod := kmmv1beta1.Module{
 			Spec: kmmv1beta1.ModuleSpec{
@@ -131,11 +195,101 @@ var _ = Describe("SetDriverContainerAsDesired", func() {
 
 		ds := appsv1.DaemonSet{}
 
-		err := dg.SetDriverContainerAsDesired(context.Background(), &ds, "test-image", mod, kernelVersion)
+		err := dg.SetDriverContainerAsDesired(context.Background(), &ds, km, mod, kernelVersion, OSProfileDefault)
 		Expect(err).NotTo(HaveOccurred())
 		Expect(ds.Spec.Template.Spec.ServiceAccountName).To(Equal(mod.Name + "-module-loader"))
 	})
 
+	It("should use the SELinux options from the spec if they are set", func() {
+		seLinuxOptions := &v1.SELinuxOptions{Type: "kmod_loader_t", Level: "s0"}
+
+		mod := kmmv1beta1.Module{
+			Spec: kmmv1beta1.ModuleSpec{
+				ModuleLoader: kmmv1beta1.ModuleLoaderSpec{
+					Container: kmmv1beta1.ModuleLoaderContainerSpec{SELinuxOptions: seLinuxOptions},
+				},
+			},
+		}
+
+		ds := appsv1.DaemonSet{}
+
+		err := dg.SetDriverContainerAsDesired(context.Background(), &ds, km, mod, kernelVersion, OSProfileDefault)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ds.Spec.Template.Spec.Containers[0].SecurityContext.SELinuxOptions).To(Equal(seLinuxOptions))
+	})
+
+	It("should use the resources from the spec if they are set", func() {
+		limits := v1.ResourceList{v1.ResourceMemory: resource.MustParse("1Gi")}
+
+		mod := kmmv1beta1.Module{
+			Spec: kmmv1beta1.ModuleSpec{
+				ModuleLoader: kmmv1beta1.ModuleLoaderSpec{
+					Container: kmmv1beta1.ModuleLoaderContainerSpec{
+						Resources: v1.ResourceRequirements{Limits: limits},
+					},
+				},
+			},
+		}
+
+		ds := appsv1.DaemonSet{}
+
+		err := dg.SetDriverContainerAsDesired(context.Background(), &ds, km, mod, kernelVersion, OSProfileDefault)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ds.Spec.Template.Spec.Containers[0].Resources).To(Equal(v1.ResourceRequirements{Limits: limits, Requests: limits}))
+		Expect(mod.Spec.ModuleLoader.Container.Resources.Requests).To(BeNil())
+	})
+
+	It("should not set SELinux options if none are set in the spec and there is no default type", func() {
+		ds := appsv1.DaemonSet{}
+
+		err := NewCreator(nil, kernelLabel, scheme, "", false, "").
+			SetDriverContainerAsDesired(context.Background(), &ds, km, kmmv1beta1.Module{}, kernelVersion, OSProfileDefault)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ds.Spec.Template.Spec.Containers[0].SecurityContext.SELinuxOptions).To(BeNil())
+	})
+
+	DescribeTable("should use the SELinux type of the OS profile if none is set in the spec",
+		func(osImage string, expected *v1.SELinuxOptions) {
+			ds := appsv1.DaemonSet{}
+
+			err := dg.SetDriverContainerAsDesired(context.Background(), &ds, km, kmmv1beta1.Module{}, kernelVersion, OSProfileForImage(osImage))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ds.Spec.Template.Spec.Containers[0].SecurityContext.SELinuxOptions).To(Equal(expected))
+		},
+		Entry("RHCOS", "Red Hat Enterprise Linux CoreOS 412.86.202301311551-0 (Ootpa)", &v1.SELinuxOptions{Type: DefaultModuleLoaderSELinuxType}),
+		Entry("Bottlerocket", "Bottlerocket OS 1.12.0 (aws-k8s-1.24)", &v1.SELinuxOptions{Type: "super_t"}),
+		Entry("Flatcar", "Flatcar Container Linux by Kinvolk 3374.2.4 (Oklo)", nil),
+	)
+
+	It("should use the runtime's default seccomp profile in restricted mode", func() {
+		ds := appsv1.DaemonSet{}
+
+		err := NewCreator(nil, kernelLabel, scheme, DefaultModuleLoaderSELinuxType, true, "").
+			SetDriverContainerAsDesired(context.Background(), &ds, km, kmmv1beta1.Module{}, kernelVersion, OSProfileDefault)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ds.Spec.Template.Spec.SecurityContext).To(
+			Equal(&v1.PodSecurityContext{
+				SeccompProfile: &v1.SeccompProfile{Type: v1.SeccompProfileTypeRuntimeDefault},
+			}),
+		)
+	})
+
+	It("should use the localhost seccomp profile if one is set", func() {
+		ds := appsv1.DaemonSet{}
+
+		err := NewCreator(nil, kernelLabel, scheme, DefaultModuleLoaderSELinuxType, true, "kmm/module-loader.json").
+			SetDriverContainerAsDesired(context.Background(), &ds, km, kmmv1beta1.Module{}, kernelVersion, OSProfileDefault)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ds.Spec.Template.Spec.SecurityContext).To(
+			Equal(&v1.PodSecurityContext{
+				SeccompProfile: &v1.SeccompProfile{
+					Type:             v1.SeccompProfileTypeLocalhost,
+					LocalhostProfile: pointer.String("kmm/module-loader.json"),
+				},
+			}),
+		)
+	})
+
 	It("should work as expected", func() {
 		const (
 			moduleLoaderImage   = "driver-image"
@@ -172,7 +326,7 @@ var _ = Describe("SetDriverContainerAsDesired", func() {
 			},
 		}
 
-		err := dg.SetDriverContainerAsDesired(context.Background(), &ds, moduleLoaderImage, mod, kernelVersion)
+		err := dg.SetDriverContainerAsDesired(context.Background(), &ds, kmmv1beta1.KernelMapping{ContainerImage: moduleLoaderImage}, mod, kernelVersion, OSProfileDefault)
 		Expect(err).NotTo(HaveOccurred())
 
 		podLabels := map[string]string{
@@ -182,6 +336,8 @@ var _ = Describe("SetDriverContainerAsDesired", func() {
 		}
 
 		directory := v1.HostPathDirectory
+		maxUnavailable := intstr.FromInt(1)
+		maxSurge := intstr.FromInt(0)
 
 		expected := appsv1.DaemonSet{
 			ObjectMeta: metav1.ObjectMeta{
@@ -200,11 +356,20 @@ var _ = Describe("SetDriverContainerAsDesired", func() {
 				},
 			},
 			Spec: appsv1.DaemonSetSpec{
-				Selector: &metav1.LabelSelector{MatchLabels: podLabels},
+				RevisionHistoryLimit: pointer.Int32(10),
+				Selector:             &metav1.LabelSelector{MatchLabels: podLabels},
+				UpdateStrategy: appsv1.DaemonSetUpdateStrategy{
+					Type: appsv1.RollingUpdateDaemonSetStrategyType,
+					RollingUpdate: &appsv1.RollingUpdateDaemonSet{
+						MaxUnavailable: &maxUnavailable,
+						MaxSurge:       &maxSurge,
+					},
+				},
 				Template: v1.PodTemplateSpec{
 					ObjectMeta: metav1.ObjectMeta{
-						Finalizers: []string{constants.NodeLabelerFinalizer},
-						Labels:     podLabels,
+						Annotations: map[string]string{constants.ModprobeAnnotation: `{"moduleName":"some-kmod"}`},
+						Finalizers:  []string{constants.NodeLabelerFinalizer},
+						Labels:      podLabels,
 					},
 					Spec: v1.PodSpec{
 						Containers: []v1.Container{
@@ -223,7 +388,9 @@ var _ = Describe("SetDriverContainerAsDesired", func() {
 										},
 									},
 								},
-								Command: []string{"sleep", "infinity"},
+								Command:         []string{"sleep", "infinity"},
+								ImagePullPolicy: v1.PullAlways,
+								Resources:       defaultModuleLoaderResources,
 								VolumeMounts: []v1.VolumeMount{
 									{
 										Name:      "node-lib-modules",
@@ -234,15 +401,19 @@ var _ = Describe("SetDriverContainerAsDesired", func() {
 								SecurityContext: &v1.SecurityContext{
 									AllowPrivilegeEscalation: pointer.Bool(false),
 									Capabilities: &v1.Capabilities{
-										Add: []v1.Capability{"SYS_MODULE"},
+										Add:  []v1.Capability{"SYS_MODULE"},
+										Drop: []v1.Capability{"ALL"},
 									},
 									RunAsUser: pointer.Int64(0),
 									SELinuxOptions: &v1.SELinuxOptions{
 										Type: "spc_t",
 									},
 								},
+								TerminationMessagePath:   v1.TerminationMessagePathDefault,
+								TerminationMessagePolicy: v1.TerminationMessageReadFile,
 							},
 						},
+						DNSPolicy: v1.DNSClusterFirst,
 						ImagePullSecrets: []v1.LocalObjectReference{
 							{Name: imageRepoSecretName},
 						},
@@ -250,8 +421,19 @@ var _ = Describe("SetDriverContainerAsDesired", func() {
 							"has-feature-x": "true",
 							kernelLabel:     kernelVersion,
 						},
-						PriorityClassName:  "system-node-critical",
-						ServiceAccountName: serviceAccountName,
+						PriorityClassName:             "system-node-critical",
+						RestartPolicy:                 v1.RestartPolicyAlways,
+						SchedulerName:                 v1.DefaultSchedulerName,
+						SecurityContext:               &v1.PodSecurityContext{},
+						ServiceAccountName:            serviceAccountName,
+						TerminationGracePeriodSeconds: pointer.Int64(30),
+						Tolerations: []v1.Toleration{
+							{
+								Key:      constants.ModulesNotReadyTaint,
+								Operator: v1.TolerationOpExists,
+								Effect:   v1.TaintEffectNoSchedule,
+							},
+						},
 						Volumes: []v1.Volume{
 							{
 								Name: "node-lib-modules",
@@ -279,7 +461,7 @@ var _ = Describe("SetDriverContainerAsDesired", func() {
 		It("should return an empty map if no DaemonSets are present", func() {
 			clnt.EXPECT().List(context.Background(), gomock.Any(), gomock.Any())
 
-			dc := NewCreator(clnt, kernelLabel, scheme)
+			dc := NewCreator(clnt, kernelLabel, scheme, DefaultModuleLoaderSELinuxType, false, "")
 
 			mod := kmmv1beta1.Module{
 				ObjectMeta: metav1.ObjectMeta{
@@ -296,7 +478,7 @@ var _ = Describe("SetDriverContainerAsDesired", func() {
 		It("should return an error if two DaemonSets are present for the same kernel", func() {
 			clnt.EXPECT().List(context.Background(), gomock.Any(), gomock.Any()).Return(errors.New("some error"))
 
-			dc := NewCreator(clnt, kernelLabel, scheme)
+			dc := NewCreator(clnt, kernelLabel, scheme, DefaultModuleLoaderSELinuxType, false, "")
 			mod := kmmv1beta1.Module{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      moduleName,
@@ -341,7 +523,7 @@ var _ = Describe("SetDriverContainerAsDesired", func() {
 				},
 			)
 
-			dc := NewCreator(clnt, kernelLabel, scheme)
+			dc := NewCreator(clnt, kernelLabel, scheme, DefaultModuleLoaderSELinuxType, false, "")
 			mod := kmmv1beta1.Module{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      moduleName,
@@ -359,7 +541,7 @@ var _ = Describe("SetDriverContainerAsDesired", func() {
 })
 
 var _ = Describe("SetDevicePluginAsDesired", func() {
-	dg := NewCreator(nil, kernelLabel, scheme)
+	dg := NewCreator(nil, kernelLabel, scheme, DefaultModuleLoaderSELinuxType, false, "")
 
 	It("should return an error if the DaemonSet is nil", func() {
 		Expect(
@@ -413,6 +595,22 @@ var _ = Describe("SetDevicePluginAsDesired", func() {
 		Expect(ds.Spec.Template.Spec.ServiceAccountName).To(Equal(mod.Name + "-device-plugin"))
 	})
 
+	It("should use the default resources if none are set in the spec", func() {
+		mod := kmmv1beta1.Module{
+			Spec: kmmv1beta1.ModuleSpec{
+				DevicePlugin: &kmmv1beta1.DevicePluginSpec{
+					Container: kmmv1beta1.DevicePluginContainerSpec{Image: devicePluginImage},
+				},
+			},
+		}
+
+		ds := appsv1.DaemonSet{}
+
+		err := dg.SetDevicePluginAsDesired(context.Background(), &ds, &mod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ds.Spec.Template.Spec.Containers[0].Resources).To(Equal(defaultDevicePluginResources))
+	})
+
 	It("should work as expected", func() {
 		const (
 			dsName             = "ds-name"
@@ -498,6 +696,8 @@ var _ = Describe("SetDevicePluginAsDesired", func() {
 		}
 
 		directory := v1.HostPathDirectory
+		maxUnavailable := intstr.FromInt(1)
+		maxSurge := intstr.FromInt(0)
 
 		expected := appsv1.DaemonSet{
 			ObjectMeta: metav1.ObjectMeta{
@@ -516,7 +716,15 @@ var _ = Describe("SetDevicePluginAsDesired", func() {
 				},
 			},
 			Spec: appsv1.DaemonSetSpec{
-				Selector: &metav1.LabelSelector{MatchLabels: podLabels},
+				RevisionHistoryLimit: pointer.Int32(10),
+				Selector:             &metav1.LabelSelector{MatchLabels: podLabels},
+				UpdateStrategy: appsv1.DaemonSetUpdateStrategy{
+					Type: appsv1.RollingUpdateDaemonSetStrategyType,
+					RollingUpdate: &appsv1.RollingUpdateDaemonSet{
+						MaxUnavailable: &maxUnavailable,
+						MaxSurge:       &maxSurge,
+					},
+				},
 				Template: v1.PodTemplateSpec{
 					ObjectMeta: metav1.ObjectMeta{
 						Labels:     podLabels,
@@ -535,6 +743,8 @@ var _ = Describe("SetDevicePluginAsDesired", func() {
 								SecurityContext: &v1.SecurityContext{
 									Privileged: pointer.Bool(true),
 								},
+								TerminationMessagePath:   v1.TerminationMessagePathDefault,
+								TerminationMessagePolicy: v1.TerminationMessageReadFile,
 								VolumeMounts: []v1.VolumeMount{
 									dpVolMount,
 									{
@@ -544,12 +754,17 @@ var _ = Describe("SetDevicePluginAsDesired", func() {
 								},
 							},
 						},
+						DNSPolicy:        v1.DNSClusterFirst,
 						ImagePullSecrets: []v1.LocalObjectReference{repoSecret},
 						NodeSelector: map[string]string{
 							getDriverContainerNodeLabel(mod.Name): "",
 						},
-						PriorityClassName:  "system-node-critical",
-						ServiceAccountName: serviceAccountName,
+						PriorityClassName:             "system-node-critical",
+						RestartPolicy:                 v1.RestartPolicyAlways,
+						SchedulerName:                 v1.DefaultSchedulerName,
+						SecurityContext:               &v1.PodSecurityContext{},
+						ServiceAccountName:            serviceAccountName,
+						TerminationGracePeriodSeconds: pointer.Int64(30),
 						Volumes: []v1.Volume{
 							{
 								Name: "kubelet-device-plugins",
@@ -599,7 +814,7 @@ var _ = Describe("GarbageCollect", func() {
 
 		clnt.EXPECT().Delete(context.Background(), &dsNotLegit).AnyTimes()
 
-		dc := NewCreator(clnt, kernelLabel, scheme)
+		dc := NewCreator(clnt, kernelLabel, scheme, DefaultModuleLoaderSELinuxType, false, "")
 
 		existingDS := map[string]*appsv1.DaemonSet{
 			legitKernelVersion:    &dsLegit,
@@ -618,7 +833,7 @@ var _ = Describe("GarbageCollect", func() {
 			errors.New("client returns some error"),
 		)
 
-		dc := NewCreator(clnt, kernelLabel, scheme)
+		dc := NewCreator(clnt, kernelLabel, scheme, DefaultModuleLoaderSELinuxType, false, "")
 
 		dsNotLegit := appsv1.DaemonSet{
 			ObjectMeta: metav1.ObjectMeta{Name: "name", Namespace: "namespace", Labels: map[string]string{kernelLabel: "kernel version"}},
@@ -642,7 +857,7 @@ var _ = Describe("ModuleDaemonSetsByKernelVersion", func() {
 	It("should return an empty map if no DaemonSets are present", func() {
 		clnt.EXPECT().List(context.Background(), gomock.Any(), gomock.Any())
 
-		dc := NewCreator(clnt, kernelLabel, scheme)
+		dc := NewCreator(clnt, kernelLabel, scheme, DefaultModuleLoaderSELinuxType, false, "")
 
 		m, err := dc.ModuleDaemonSetsByKernelVersion(context.Background(), moduleName, namespace)
 		Expect(err).NotTo(HaveOccurred())
@@ -678,7 +893,7 @@ var _ = Describe("ModuleDaemonSetsByKernelVersion", func() {
 				return nil
 			},
 		)
-		dc := NewCreator(clnt, kernelLabel, scheme)
+		dc := NewCreator(clnt, kernelLabel, scheme, DefaultModuleLoaderSELinuxType, false, "")
 
 		_, err := dc.ModuleDaemonSetsByKernelVersion(ctx, moduleName, namespace)
 		Expect(err).To(HaveOccurred())
@@ -718,7 +933,7 @@ var _ = Describe("ModuleDaemonSetsByKernelVersion", func() {
 			},
 		)
 
-		dc := NewCreator(clnt, kernelLabel, scheme)
+		dc := NewCreator(clnt, kernelLabel, scheme, DefaultModuleLoaderSELinuxType, false, "")
 
 		m, err := dc.ModuleDaemonSetsByKernelVersion(ctx, moduleName, namespace)
 		Expect(err).NotTo(HaveOccurred())
@@ -758,7 +973,7 @@ var _ = Describe("ModuleDaemonSetsByKernelVersion", func() {
 			},
 		)
 
-		dc := NewCreator(clnt, kernelLabel, scheme)
+		dc := NewCreator(clnt, kernelLabel, scheme, DefaultModuleLoaderSELinuxType, false, "")
 
 		m, err := dc.ModuleDaemonSetsByKernelVersion(context.Background(), moduleName, namespace)
 		Expect(err).NotTo(HaveOccurred())
@@ -768,6 +983,25 @@ var _ = Describe("ModuleDaemonSetsByKernelVersion", func() {
 	})
 })
 
+var _ = Describe("DriverContainerName", func() {
+	It("should only depend on the module name and kernel version", func() {
+		Expect(
+			DriverContainerName("some-module", "4.18.0-372.32.1.el8_6.x86_64"),
+		).To(
+			Equal(DriverContainerName("some-module", "4.18.0-372.32.1.el8_6.x86_64")),
+		)
+	})
+
+	It("should be a valid name that differs between kernels", func() {
+		name1 := DriverContainerName("some-module", "4.18.0-372.32.1.el8_6.x86_64")
+		name2 := DriverContainerName("some-module", "5.14.0-70.13.1.el9_0.x86_64")
+
+		Expect(name1).NotTo(Equal(name2))
+		Expect(validation.IsDNS1123Subdomain(name1)).To(BeEmpty())
+		Expect(validation.IsDNS1123Subdomain(name2)).To(BeEmpty())
+	})
+})
+
 var _ = Describe("GetPodPullSecrets", func() {
 	It("should return nil if the secret is nil", func() {
 		Expect(
@@ -815,7 +1049,7 @@ var _ = Describe("GetNodeLabelFromPod", func() {
 	var dc DaemonSetCreator
 
 	BeforeEach(func() {
-		dc = NewCreator(clnt, kernelLabel, scheme)
+		dc = NewCreator(clnt, kernelLabel, scheme, DefaultModuleLoaderSELinuxType, false, "")
 	})
 
 	It("should return a driver container label", func() {
@@ -864,7 +1098,7 @@ var _ = Describe("MakeLoadCommand", func() {
 			Equal([]string{
 				"/bin/sh",
 				"-c",
-				"modprobe load arguments",
+				"{ modprobe load arguments; } 2>/dev/termination-log || { cat /dev/termination-log >&2; exit 1; }",
 			}),
 		)
 	})
@@ -888,7 +1122,13 @@ var _ = Describe("MakeLoadCommand", func() {
 			Equal([]string{
 				"/bin/sh",
 				"-c",
-				fmt.Sprintf("modprobe -v -d %s %s %s %s", dir, kernelModuleName, arg1, arg2),
+				fmt.Sprintf(
+					"{ modprobe -v -d %s %s %s %s; } 2>/dev/termination-log || { cat /dev/termination-log >&2; exit 1; }",
+					dir,
+					kernelModuleName,
+					arg1,
+					arg2,
+				),
 			}),
 		)
 	})
@@ -907,7 +1147,10 @@ var _ = Describe("MakeLoadCommand", func() {
 			Equal([]string{
 				"/bin/sh",
 				"-c",
-				fmt.Sprintf("modprobe -z -k %s", kernelModuleName),
+				fmt.Sprintf(
+					"{ modprobe -z -k %s; } 2>/dev/termination-log || { cat /dev/termination-log >&2; exit 1; }",
+					kernelModuleName,
+				),
 			}),
 		)
 	})
@@ -924,12 +1167,74 @@ var _ = Describe("MakeLoadCommand", func() {
 			Equal([]string{
 				"/bin/sh",
 				"-c",
-				fmt.Sprintf("cp -r /kmm/firmware/mymodule/* /var/lib/firmware && modprobe -v %s", kernelModuleName),
+				fmt.Sprintf(
+					"{ cp -r /kmm/firmware/mymodule/* /var/lib/firmware && modprobe -v %s; } 2>/dev/termination-log || "+
+						"{ cat /dev/termination-log >&2; exit 1; }",
+					kernelModuleName,
+				),
 			}),
 		)
 	})
 })
 
+var _ = Describe("ModuleLoadRejection", func() {
+	podWithMessage := func(containerName, message string) *v1.Pod {
+		return &v1.Pod{
+			Status: v1.PodStatus{
+				ContainerStatuses: []v1.ContainerStatus{
+					{
+						Name: containerName,
+						LastTerminationState: v1.ContainerState{
+							Terminated: &v1.ContainerStateTerminated{Message: message},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	DescribeTable("should return the rejection reason",
+		func(pod *v1.Pod, expectedReason string) {
+			reason, _ := ModuleLoadRejection(pod)
+			Expect(reason).To(Equal(expectedReason))
+		},
+		Entry("no container status", &v1.Pod{}, ""),
+		Entry(
+			"invalid signature",
+			podWithMessage("module-loader", "modprobe: ERROR: could not insert 'kmm_ci_a': Key was rejected by service\n"),
+			"SignatureRejected",
+		),
+		Entry(
+			"unsigned module",
+			podWithMessage("module-loader", "modprobe: ERROR: could not insert 'kmm_ci_a': Required key not available"),
+			"SignatureRejected",
+		),
+		Entry(
+			"vermagic mismatch",
+			podWithMessage("module-loader", "modprobe: ERROR: could not insert 'kmm_ci_a': Exec format error"),
+			"InvalidModuleFormat",
+		),
+		Entry(
+			"other error",
+			podWithMessage("module-loader", "modprobe: FATAL: Module kmm_ci_a not found"),
+			"",
+		),
+		Entry(
+			"other container",
+			podWithMessage("device-plugin", "Key was rejected by service"),
+			"",
+		),
+	)
+
+	It("should return the trimmed error message", func() {
+		_, message := ModuleLoadRejection(
+			podWithMessage("module-loader", "modprobe: ERROR: could not insert 'a': Exec format error\n"),
+		)
+
+		Expect(message).To(Equal("modprobe: ERROR: could not insert 'a': Exec format error"))
+	})
+})
+
 var _ = Describe("MakeUnloadCommand", func() {
 	const (
 		kernelModuleName = "some-kmod"
@@ -53,6 +53,21 @@ func (mr *MockDaemonSetCreatorMockRecorder) GarbageCollect(ctx, existingDS, vali
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GarbageCollect", reflect.TypeOf((*MockDaemonSetCreator)(nil).GarbageCollect), ctx, existingDS, validKernels)
 }
 
+// GarbageCollectCompanions mocks base method.
+func (m *MockDaemonSetCreator) GarbageCollectCompanions(ctx context.Context, mod *v1beta1.Module) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GarbageCollectCompanions", ctx, mod)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GarbageCollectCompanions indicates an expected call of GarbageCollectCompanions.
+func (mr *MockDaemonSetCreatorMockRecorder) GarbageCollectCompanions(ctx, mod interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GarbageCollectCompanions", reflect.TypeOf((*MockDaemonSetCreator)(nil).GarbageCollectCompanions), ctx, mod)
+}
+
 // GetNodeLabelFromPod mocks base method.
 func (m *MockDaemonSetCreator) GetNodeLabelFromPod(pod *v10.Pod, moduleName string) string {
 	m.ctrl.T.Helper()
@@ -82,6 +97,20 @@ func (mr *MockDaemonSetCreatorMockRecorder) ModuleDaemonSetsByKernelVersion(ctx,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ModuleDaemonSetsByKernelVersion", reflect.TypeOf((*MockDaemonSetCreator)(nil).ModuleDaemonSetsByKernelVersion), ctx, name, namespace)
 }
 
+// SetCompanionAsDesired mocks base method.
+func (m *MockDaemonSetCreator) SetCompanionAsDesired(ctx context.Context, deploy *v1.Deployment, mod *v1beta1.Module, companion *v1beta1.CompanionSpec) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCompanionAsDesired", ctx, deploy, mod, companion)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCompanionAsDesired indicates an expected call of SetCompanionAsDesired.
+func (mr *MockDaemonSetCreatorMockRecorder) SetCompanionAsDesired(ctx, deploy, mod, companion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCompanionAsDesired", reflect.TypeOf((*MockDaemonSetCreator)(nil).SetCompanionAsDesired), ctx, deploy, mod, companion)
+}
+
 // SetDevicePluginAsDesired mocks base method.
 func (m *MockDaemonSetCreator) SetDevicePluginAsDesired(ctx context.Context, ds *v1.DaemonSet, mod *v1beta1.Module) error {
 	m.ctrl.T.Helper()
@@ -97,15 +126,15 @@ func (mr *MockDaemonSetCreatorMockRecorder) SetDevicePluginAsDesired(ctx, ds, mo
 }
 
 // SetDriverContainerAsDesired mocks base method.
-func (m *MockDaemonSetCreator) SetDriverContainerAsDesired(ctx context.Context, ds *v1.DaemonSet, image string, mod v1beta1.Module, kernelVersion string) error {
+func (m *MockDaemonSetCreator) SetDriverContainerAsDesired(ctx context.Context, ds *v1.DaemonSet, km v1beta1.KernelMapping, mod v1beta1.Module, kernelVersion string, osProfile OSProfile) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetDriverContainerAsDesired", ctx, ds, image, mod, kernelVersion)
+	ret := m.ctrl.Call(m, "SetDriverContainerAsDesired", ctx, ds, km, mod, kernelVersion, osProfile)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SetDriverContainerAsDesired indicates an expected call of SetDriverContainerAsDesired.
-func (mr *MockDaemonSetCreatorMockRecorder) SetDriverContainerAsDesired(ctx, ds, image, mod, kernelVersion interface{}) *gomock.Call {
+func (mr *MockDaemonSetCreatorMockRecorder) SetDriverContainerAsDesired(ctx, ds, km, mod, kernelVersion, osProfile interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDriverContainerAsDesired", reflect.TypeOf((*MockDaemonSetCreator)(nil).SetDriverContainerAsDesired), ctx, ds, image, mod, kernelVersion)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDriverContainerAsDesired", reflect.TypeOf((*MockDaemonSetCreator)(nil).SetDriverContainerAsDesired), ctx, ds, km, mod, kernelVersion, osProfile)
 }
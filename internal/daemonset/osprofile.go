@@ -0,0 +1,45 @@
+package daemonset
+
+import "strings"
+
+// OSProfile identifies the operating system of the nodes running a kernel, for the defaults of module-loader pods
+// that depend on it.
+type OSProfile string
+
+const (
+	// OSProfileDefault is the profile of the operating systems that need no specific default, such as RHCOS.
+	OSProfileDefault OSProfile = ""
+	// OSProfileBottlerocket is the profile of Bottlerocket, whose SELinux policy has its own type for containers
+	// that manage the host.
+	OSProfileBottlerocket OSProfile = "bottlerocket"
+	// OSProfileFlatcar is the profile of Flatcar Container Linux, whose SELinux policy has no spc_t type.
+	OSProfileFlatcar OSProfile = "flatcar"
+
+	bottlerocketSELinuxType = "super_t"
+)
+
+// OSProfileForImage returns the profile of the nodes whose status.nodeInfo.osImage is osImage.
+func OSProfileForImage(osImage string) OSProfile {
+	switch {
+	case strings.HasPrefix(osImage, "Bottlerocket"):
+		return OSProfileBottlerocket
+	case strings.HasPrefix(osImage, "Flatcar"):
+		return OSProfileFlatcar
+	default:
+		return OSProfileDefault
+	}
+}
+
+// seLinuxType returns the SELinux type of module-loader containers for Modules that do not set SELinux options, given
+// the type configured for the operator; it returns an empty string if no SELinux options should be set.
+func (p OSProfile) seLinuxType(defaultType string) string {
+	switch p {
+	case OSProfileBottlerocket:
+		return bottlerocketSELinuxType
+	case OSProfileFlatcar:
+		// Flatcar runs SELinux in permissive mode by default, and containers are started with the runtime's type.
+		return ""
+	default:
+		return defaultType
+	}
+}
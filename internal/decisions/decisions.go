@@ -0,0 +1,183 @@
+package decisions
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// Module holds the decisions made during the last reconciliation of a Module.
+// All methods can be called on a nil *Module, in which case they do nothing, so that the reconciler does not need to
+// check whether decisions are recorded.
+type Module struct {
+	Namespace    string    `json:"namespace"`
+	Name         string    `json:"name"`
+	StartTime    time.Time `json:"startTime"`
+	Duration     string    `json:"duration"`
+	Steps        []string  `json:"steps"`
+	Nodes        []Node    `json:"nodes"`
+	Kernels      []Kernel  `json:"kernels"`
+	RequeueAfter string    `json:"requeueAfter,omitempty"`
+	Requeue      bool      `json:"requeue,omitempty"`
+	Error        string    `json:"error,omitempty"`
+
+	deleted bool
+}
+
+// Node is a node targeted by a Module.
+type Node struct {
+	Name          string `json:"name"`
+	KernelVersion string `json:"kernelVersion"`
+	// SkipReason is why the Module is not deployed on the node, if it is not.
+	SkipReason string `json:"skipReason,omitempty"`
+}
+
+// Kernel is a kernel for which a Module has a kernel mapping.
+type Kernel struct {
+	KernelVersion  string `json:"kernelVersion"`
+	ContainerImage string `json:"containerImage"`
+	Build          bool   `json:"build"`
+	Sign           bool   `json:"sign"`
+	Phase          string `json:"phase"`
+	Error          string `json:"error,omitempty"`
+}
+
+// New returns the decisions of a reconciliation of the Module nsn starting now.
+func New(nsn types.NamespacedName) *Module {
+	return &Module{
+		Namespace: nsn.Namespace,
+		Name:      nsn.Name,
+		StartTime: time.Now(),
+		Steps:     make([]string, 0),
+		Nodes:     make([]Node, 0),
+		Kernels:   make([]Kernel, 0),
+	}
+}
+
+// Step records that the reconciliation ran a step.
+func (m *Module) Step(name string) {
+	if m != nil {
+		m.Steps = append(m.Steps, name)
+	}
+}
+
+// AddNode records a node targeted by the Module; skipReason is empty if the Module is deployed on the node.
+func (m *Module) AddNode(name, kernelVersion, skipReason string) {
+	if m != nil {
+		m.Nodes = append(m.Nodes, Node{Name: name, KernelVersion: kernelVersion, SkipReason: skipReason})
+	}
+}
+
+// AddKernel records a kernel for which the Module has a kernel mapping.
+func (m *Module) AddKernel(k Kernel) {
+	if m != nil {
+		m.Kernels = append(m.Kernels, k)
+	}
+}
+
+// SetDeleted records that the Module does not exist anymore.
+func (m *Module) SetDeleted() {
+	if m != nil {
+		m.deleted = true
+	}
+}
+
+// Finish records the outcome of the reconciliation.
+func (m *Module) Finish(res ctrl.Result, err error) {
+	if m == nil {
+		return
+	}
+
+	m.Duration = time.Since(m.StartTime).String()
+	m.Requeue = res.Requeue
+
+	if res.RequeueAfter > 0 {
+		m.RequeueAfter = res.RequeueAfter.String()
+	}
+
+	if err != nil {
+		m.Error = err.Error()
+	}
+
+	sort.Slice(m.Kernels, func(i, j int) bool {
+		return m.Kernels[i].KernelVersion < m.Kernels[j].KernelVersion
+	})
+}
+
+// Recorder keeps the decisions of the last reconciliation of each Module, and serves them over HTTP as JSON.
+type Recorder interface {
+	http.Handler
+
+	Record(m *Module)
+}
+
+type recorder struct {
+	mu      sync.RWMutex
+	modules map[types.NamespacedName]*Module
+}
+
+// NewRecorder returns an empty Recorder.
+func NewRecorder() Recorder {
+	return &recorder{modules: make(map[types.NamespacedName]*Module)}
+}
+
+// Record replaces the decisions of the Module of m, or forgets them if the Module was deleted.
+func (r *recorder) Record(m *Module) {
+	nsn := types.NamespacedName{Namespace: m.Namespace, Name: m.Name}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if m.deleted {
+		delete(r.modules, nsn)
+		return
+	}
+
+	r.modules[nsn] = m
+}
+
+// ServeHTTP writes the recorded decisions sorted by namespace and name.
+// The namespace and name query parameters restrict them to a namespace or to a Module.
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	namespace := req.URL.Query().Get("namespace")
+	name := req.URL.Query().Get("name")
+
+	r.mu.RLock()
+
+	modules := make([]*Module, 0, len(r.modules))
+
+	for nsn, m := range r.modules {
+		if (namespace == "" || nsn.Namespace == namespace) && (name == "" || nsn.Name == name) {
+			modules = append(modules, m)
+		}
+	}
+
+	r.mu.RUnlock()
+
+	sort.Slice(modules, func(i, j int) bool {
+		if modules[i].Namespace != modules[j].Namespace {
+			return modules[i].Namespace < modules[j].Namespace
+		}
+
+		return modules[i].Name < modules[j].Name
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(modules); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
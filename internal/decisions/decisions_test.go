@@ -0,0 +1,95 @@
+package decisions
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("Module", func() {
+	It("should do nothing when nil", func() {
+		var m *Module
+
+		m.Step("some step")
+		m.AddNode("some-node", "1.2.3", "")
+		m.AddKernel(Kernel{KernelVersion: "1.2.3"})
+		m.SetDeleted()
+		m.Finish(ctrl.Result{}, nil)
+	})
+
+	It("should record the outcome of the reconciliation", func() {
+		m := New(types.NamespacedName{Namespace: "some-namespace", Name: "some-module"})
+
+		m.AddKernel(Kernel{KernelVersion: "4.5.6"})
+		m.AddKernel(Kernel{KernelVersion: "1.2.3"})
+		m.Finish(ctrl.Result{RequeueAfter: time.Minute}, errors.New("some error"))
+
+		Expect(m.Kernels[0].KernelVersion).To(Equal("1.2.3"))
+		Expect(m.RequeueAfter).To(Equal("1m0s"))
+		Expect(m.Error).To(Equal("some error"))
+	})
+})
+
+var _ = Describe("Recorder", func() {
+	get := func(r Recorder, url string) []Module {
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+
+		modules := make([]Module, 0)
+		Expect(json.Unmarshal(rec.Body.Bytes(), &modules)).To(Succeed())
+
+		return modules
+	}
+
+	It("should serve the last decisions of each Module", func() {
+		r := NewRecorder()
+
+		a := New(types.NamespacedName{Namespace: "ns-b", Name: "module-a"})
+		a.Step("first reconciliation")
+		r.Record(a)
+
+		a = New(types.NamespacedName{Namespace: "ns-b", Name: "module-a"})
+		a.Step("second reconciliation")
+		r.Record(a)
+
+		r.Record(New(types.NamespacedName{Namespace: "ns-a", Name: "module-b"}))
+
+		modules := get(r, "/debug/modules")
+		Expect(modules).To(HaveLen(2))
+		Expect(modules[0].Namespace).To(Equal("ns-a"))
+		Expect(modules[1].Steps).To(Equal([]string{"second reconciliation"}))
+
+		modules = get(r, "/debug/modules?namespace=ns-b&name=module-a")
+		Expect(modules).To(HaveLen(1))
+		Expect(modules[0].Name).To(Equal("module-a"))
+	})
+
+	It("should forget deleted Modules", func() {
+		r := NewRecorder()
+		nsn := types.NamespacedName{Namespace: "some-namespace", Name: "some-module"}
+
+		r.Record(New(nsn))
+
+		m := New(nsn)
+		m.SetDeleted()
+		r.Record(m)
+
+		Expect(get(r, "/debug/modules")).To(BeEmpty())
+	})
+
+	It("should only accept GET requests", func() {
+		rec := httptest.NewRecorder()
+
+		NewRecorder().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/debug/modules", nil))
+		Expect(rec.Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+})
@@ -0,0 +1,144 @@
+package dryrun
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/metrics"
+)
+
+// EventReason is the reason of the Events reporting the operations that the operator would have run.
+const EventReason = "DryRun"
+
+const (
+	verbCreate      = "create"
+	verbUpdate      = "update"
+	verbPatch       = "patch"
+	verbDelete      = "delete"
+	verbDeleteAllOf = "deletecollection"
+)
+
+type dryRunClient struct {
+	client.Client
+
+	recorder   record.EventRecorder
+	metricsAPI metrics.Metrics
+}
+
+// NewClient returns a client reading through c, that sends its writes to the API server in dry-run mode so that they
+// are validated but never persisted.
+// Each write is logged, counted in metrics and, if it is about a Module or an object controlled by a Module, reported
+// in an Event on that Module.
+func NewClient(c client.Client, recorder record.EventRecorder, metricsAPI metrics.Metrics) client.Client {
+	return &dryRunClient{
+		Client:     client.NewDryRunClient(c),
+		recorder:   recorder,
+		metricsAPI: metricsAPI,
+	}
+}
+
+func (c *dryRunClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if err := c.Client.Create(ctx, obj, opts...); err != nil {
+		return err
+	}
+
+	c.report(ctx, verbCreate, obj)
+
+	return nil
+}
+
+func (c *dryRunClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if err := c.Client.Update(ctx, obj, opts...); err != nil {
+		return err
+	}
+
+	c.report(ctx, verbUpdate, obj)
+
+	return nil
+}
+
+func (c *dryRunClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if err := c.Client.Patch(ctx, obj, patch, opts...); err != nil {
+		return err
+	}
+
+	c.report(ctx, verbPatch, obj)
+
+	return nil
+}
+
+func (c *dryRunClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if err := c.Client.Delete(ctx, obj, opts...); err != nil {
+		return err
+	}
+
+	c.report(ctx, verbDelete, obj)
+
+	return nil
+}
+
+func (c *dryRunClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	if err := c.Client.DeleteAllOf(ctx, obj, opts...); err != nil {
+		return err
+	}
+
+	c.report(ctx, verbDeleteAllOf, obj)
+
+	return nil
+}
+
+// report logs the operation that would have been run on obj, counts it, and emits an Event on the Module that it is
+// about, if any.
+func (c *dryRunClient) report(ctx context.Context, verb string, obj client.Object) {
+	kind := fmt.Sprintf("%T", obj)
+
+	if gvk, err := apiutil.GVKForObject(obj, c.Scheme()); err == nil {
+		kind = gvk.Kind
+	}
+
+	name := obj.GetName()
+	if name == "" {
+		// objects created with GenerateName
+		name = obj.GetGenerateName() + "*"
+	}
+
+	log.FromContext(ctx).Info("Dry run: not persisting the operation", "verb", verb, "kind", kind, "namespace", obj.GetNamespace(), "name", name)
+
+	c.metricsAPI.AddDryRunOperation(kind, verb)
+
+	mod := owningModule(obj)
+	if mod == nil {
+		return
+	}
+
+	c.recorder.Eventf(mod, v1.EventTypeNormal, EventReason, "Would %s %s %s", verb, kind, name)
+}
+
+// owningModule returns obj if it is a Module, a Module with the name and UID of the controller of obj if that is a
+// Module, and nil otherwise.
+func owningModule(obj client.Object) *kmmv1beta1.Module {
+	if mod, ok := obj.(*kmmv1beta1.Module); ok {
+		return mod
+	}
+
+	owner := metav1.GetControllerOf(obj)
+	if owner == nil || owner.Kind != "Module" || owner.APIVersion != kmmv1beta1.GroupVersion.String() {
+		return nil
+	}
+
+	return &kmmv1beta1.Module{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      owner.Name,
+			Namespace: obj.GetNamespace(),
+			UID:       owner.UID,
+		},
+	}
+}
@@ -0,0 +1,106 @@
+package dryrun
+
+import (
+	"context"
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/client"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/metrics"
+)
+
+var _ = Describe("Client", func() {
+	const (
+		moduleName = "some-module"
+		namespace  = "some-namespace"
+	)
+
+	var (
+		ctrl        *gomock.Controller
+		clnt        *client.MockClient
+		mockMetrics *metrics.MockMetrics
+		recorder    *record.FakeRecorder
+		c           ctrlclient.Client
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+		mockMetrics = metrics.NewMockMetrics(ctrl)
+		recorder = record.NewFakeRecorder(1)
+		c = NewClient(clnt, recorder, mockMetrics)
+
+		clnt.EXPECT().Scheme().Return(scheme).AnyTimes()
+	})
+
+	ctx := context.Background()
+
+	It("should not report writes that fail", func() {
+		ds := &appsv1.DaemonSet{}
+
+		clnt.EXPECT().Create(ctx, ds, ctrlclient.DryRunAll).Return(errors.New("some error"))
+
+		Expect(c.Create(ctx, ds)).To(HaveOccurred())
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("should report the creation of an object controlled by a Module on that Module", func() {
+		ds := &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: moduleName + "-",
+				Namespace:    namespace,
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion: kmmv1beta1.GroupVersion.String(),
+						Kind:       "Module",
+						Name:       moduleName,
+						UID:        "some-uid",
+						Controller: pointer.Bool(true),
+					},
+				},
+			},
+		}
+
+		gomock.InOrder(
+			clnt.EXPECT().Create(ctx, ds, ctrlclient.DryRunAll),
+			mockMetrics.EXPECT().AddDryRunOperation("DaemonSet", "create"),
+		)
+
+		Expect(c.Create(ctx, ds)).NotTo(HaveOccurred())
+		Expect(recorder.Events).To(Receive(Equal("Normal DryRun Would create DaemonSet some-module-*")))
+	})
+
+	It("should report the update of a Module on that Module", func() {
+		mod := &kmmv1beta1.Module{ObjectMeta: metav1.ObjectMeta{Name: moduleName, Namespace: namespace}}
+
+		gomock.InOrder(
+			clnt.EXPECT().Update(ctx, mod, ctrlclient.DryRunAll),
+			mockMetrics.EXPECT().AddDryRunOperation("Module", "update"),
+		)
+
+		Expect(c.Update(ctx, mod)).NotTo(HaveOccurred())
+		Expect(recorder.Events).To(Receive(Equal("Normal DryRun Would update Module some-module")))
+	})
+
+	It("should only count the deletion of an object not controlled by a Module", func() {
+		cm := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "some-cm", Namespace: namespace}}
+
+		gomock.InOrder(
+			clnt.EXPECT().Delete(ctx, cm, ctrlclient.DryRunAll),
+			mockMetrics.EXPECT().AddDryRunOperation("ConfigMap", "delete"),
+		)
+
+		Expect(c.Delete(ctx, cm)).NotTo(HaveOccurred())
+		Expect(recorder.Events).To(BeEmpty())
+	})
+})
@@ -2,14 +2,18 @@ package filter
 
 import (
 	"context"
+	"reflect"
 
 	"github.com/go-logr/logr"
 	hubv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api-hub/v1beta1"
 	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/daemonset"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/kubectl/pkg/util/podutils"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -23,6 +27,32 @@ func HasLabel(label string) predicate.Predicate {
 	})
 }
 
+// LabelNotEqual returns a predicate for objects whose label is not set to value.
+func LabelNotEqual(label, value string) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(o client.Object) bool {
+		return o.GetLabels()[label] != value
+	})
+}
+
+// HasAnyLabel returns a predicate for objects that have at least one of labels.
+func HasAnyLabel(labels ...string) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(o client.Object) bool {
+		for _, l := range labels {
+			if o.GetLabels()[l] != "" {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+func HasAnnotation(annotation string) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(o client.Object) bool {
+		return o.GetAnnotations()[annotation] != ""
+	})
+}
+
 var skipDeletions predicate.Predicate = predicate.Funcs{
 	DeleteFunc: func(_ event.DeleteEvent) bool { return false },
 }
@@ -39,14 +69,110 @@ func New(client client.Client, logger logr.Logger) *Filter {
 	}
 }
 
-func (f *Filter) ModuleReconcilerNodePredicate(kernelLabel string) predicate.Predicate {
+// ModuleReconcilerNodePredicate returns a predicate for Node events that may change the set of nodes targeted by
+// Modules, on nodes having at least one of kernelLabels.
+// Updates are only let through if one of kernelLabels, the NoSchedule taints or a label used in at least one Module's
+// selector or in the node selector of one of its kernel mappings changed; status-only updates such as heartbeats are ignored.
+// kernelLabels typically holds the kernel label set by KMM, followed by the labels in which other tooling records the
+// kernel of nodes, for instance normalized, and that kernel mappings may select.
+func (f *Filter) ModuleReconcilerNodePredicate(kernelLabels ...string) predicate.Predicate {
 	return predicate.And(
 		skipDeletions,
-		HasLabel(kernelLabel),
-		predicate.LabelChangedPredicate{},
+		HasAnyLabel(kernelLabels...),
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				return f.nodeUpdateRelevantForModules(e, kernelLabels)
+			},
+		},
 	)
 }
 
+func (f *Filter) nodeUpdateRelevantForModules(e event.UpdateEvent, kernelLabels []string) bool {
+	oldNode, ok := e.ObjectOld.(*v1.Node)
+	if !ok {
+		return false
+	}
+
+	newNode, ok := e.ObjectNew.(*v1.Node)
+	if !ok {
+		return false
+	}
+
+	for _, l := range kernelLabels {
+		if oldNode.Labels[l] != newNode.Labels[l] {
+			return true
+		}
+	}
+
+	if !reflect.DeepEqual(noScheduleTaints(oldNode), noScheduleTaints(newNode)) {
+		return true
+	}
+
+	changedLabels := changedLabelKeys(oldNode.Labels, newNode.Labels)
+	if changedLabels.Len() == 0 {
+		return false
+	}
+
+	logger := f.logger.WithValues("node", newNode.Name)
+
+	mods := kmmv1beta1.ModuleList{}
+
+	if err := f.client.List(context.Background(), &mods); err != nil {
+		logger.Error(err, "could not list modules; assuming the label change is relevant")
+		return true
+	}
+
+	for _, mod := range mods.Items {
+		for k := range mod.Spec.Selector {
+			if changedLabels.Has(k) {
+				return true
+			}
+		}
+
+		for _, km := range mod.Spec.ModuleLoader.Container.KernelMappings {
+			for k := range km.NodeSelector {
+				if changedLabels.Has(k) {
+					return true
+				}
+			}
+		}
+	}
+
+	logger.V(1).Info("None of the changed labels are used in a Module or kernel mapping selector; skipping", "labels", changedLabels.List())
+
+	return false
+}
+
+func noScheduleTaints(node *v1.Node) []v1.Taint {
+	taints := make([]v1.Taint, 0)
+
+	for _, t := range node.Spec.Taints {
+		if t.Effect == v1.TaintEffectNoSchedule {
+			taints = append(taints, t)
+		}
+	}
+
+	return taints
+}
+
+func changedLabelKeys(oldLabels, newLabels map[string]string) sets.String {
+	keys := sets.NewString()
+
+	for k, v := range oldLabels {
+		if nv, ok := newLabels[k]; !ok || nv != v {
+			keys.Insert(k)
+		}
+	}
+
+	for k := range newLabels {
+		if _, ok := oldLabels[k]; !ok {
+			keys.Insert(k)
+		}
+	}
+
+	return keys
+}
+
 func (f *Filter) NodeKernelReconcilerPredicate(labelName string) predicate.Predicate {
 	labelMismatch := predicate.NewPredicateFuncs(func(o client.Object) bool {
 		return o.GetLabels()[labelName] != o.(*v1.Node).Status.NodeInfo.KernelVersion
@@ -126,6 +252,96 @@ func (f *Filter) FindModulesForNode(node client.Object) []reconcile.Request {
 	return reqs
 }
 
+// FindModulesForSecret returns a reconciliation request for each Module in the Secret's namespace that references it.
+func (f *Filter) FindModulesForSecret(secret client.Object) []reconcile.Request {
+	return f.findModulesReferencing(secret, referencedSecrets)
+}
+
+// FindModulesForConfigMap returns a reconciliation request for each Module in the ConfigMap's namespace that
+// references it.
+func (f *Filter) FindModulesForConfigMap(cm client.Object) []reconcile.Request {
+	return f.findModulesReferencing(cm, referencedConfigMaps)
+}
+
+func (f *Filter) findModulesReferencing(obj client.Object, references func(*kmmv1beta1.ModuleSpec) sets.String) []reconcile.Request {
+	logger := f.logger.WithValues("name", obj.GetName(), "namespace", obj.GetNamespace())
+
+	reqs := make([]reconcile.Request, 0)
+
+	mods := kmmv1beta1.ModuleList{}
+
+	if err := f.client.List(context.Background(), &mods, client.InNamespace(obj.GetNamespace())); err != nil {
+		logger.Error(err, "could not list modules")
+		return reqs
+	}
+
+	for _, mod := range mods.Items {
+		if references(&mod.Spec).Has(obj.GetName()) {
+			nsn := types.NamespacedName{Name: mod.Name, Namespace: mod.Namespace}
+
+			reqs = append(reqs, reconcile.Request{NamespacedName: nsn})
+		}
+	}
+
+	logger.V(1).Info("Modules referencing the object", "requests", reqs)
+
+	return reqs
+}
+
+func referencedSecrets(spec *kmmv1beta1.ModuleSpec) sets.String {
+	names := sets.NewString()
+
+	addRef := func(ref *v1.LocalObjectReference) {
+		if ref != nil {
+			names.Insert(ref.Name)
+		}
+	}
+
+	addBuild := func(b *kmmv1beta1.Build) {
+		if b != nil {
+			for _, s := range b.Secrets {
+				names.Insert(s.Name)
+			}
+		}
+	}
+
+	addSign := func(s *kmmv1beta1.Sign) {
+		if s != nil {
+			addRef(s.KeySecret)
+			addRef(s.CertSecret)
+		}
+	}
+
+	addRef(spec.ImageRepoSecret)
+	addBuild(spec.ModuleLoader.Container.Build)
+	addSign(spec.ModuleLoader.Container.Sign)
+
+	for _, km := range spec.ModuleLoader.Container.KernelMappings {
+		addBuild(km.Build)
+		addSign(km.Sign)
+	}
+
+	return names
+}
+
+func referencedConfigMaps(spec *kmmv1beta1.ModuleSpec) sets.String {
+	names := sets.NewString()
+
+	addBuild := func(b *kmmv1beta1.Build) {
+		if b != nil && b.DockerfileConfigMap != nil {
+			names.Insert(b.DockerfileConfigMap.Name)
+		}
+	}
+
+	addBuild(spec.ModuleLoader.Container.Build)
+
+	for _, km := range spec.ModuleLoader.Container.KernelMappings {
+		addBuild(km.Build)
+	}
+
+	return names
+}
+
 func (f *Filter) FindManagedClusterModulesForCluster(cluster client.Object) []reconcile.Request {
 	logger := f.logger.WithValues("managedcluster", cluster.GetName())
 
@@ -201,6 +417,47 @@ func (f *Filter) EnqueueAllPreflightValidations(mod client.Object) []reconcile.R
 	return reqs
 }
 
+// EnqueueAllNodes returns a request for each node of the cluster.
+func (f *Filter) EnqueueAllNodes(obj client.Object) []reconcile.Request {
+	reqs := make([]reconcile.Request, 0)
+
+	logger := f.logger.WithValues("object", obj.GetName())
+
+	nodes := v1.NodeList{}
+	if err := f.client.List(context.Background(), &nodes); err != nil {
+		logger.Error(err, "could not list nodes")
+		return reqs
+	}
+
+	for _, node := range nodes.Items {
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: node.Name}})
+	}
+
+	return reqs
+}
+
+// ModuleReadinessGroupPredicate returns a predicate that only returns true for the Modules that are or were in a
+// readiness group, on creation, deletion, and updates of their labels or spec.
+func ModuleReadinessGroupPredicate() predicate.Predicate {
+	hasGroup := func(o client.Object) bool {
+		return o.GetLabels()[constants.ReadinessGroupLabel] != ""
+	}
+
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return hasGroup(e.Object) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return hasGroup(e.Object) },
+		GenericFunc: func(e event.GenericEvent) bool { return hasGroup(e.Object) },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if !hasGroup(e.ObjectOld) && !hasGroup(e.ObjectNew) {
+				return false
+			}
+
+			return e.ObjectOld.GetLabels()[constants.ReadinessGroupLabel] != e.ObjectNew.GetLabels()[constants.ReadinessGroupLabel] ||
+				e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration()
+		},
+	}
+}
+
 // DeletingPredicate returns a predicate that returns true if the object is being deleted.
 func DeletingPredicate() predicate.Predicate {
 	return predicate.NewPredicateFuncs(func(object client.Object) bool {
@@ -239,6 +496,52 @@ func PodReadinessChangedPredicate(logger logr.Logger) predicate.Predicate {
 	}
 }
 
+// ModuleLoaderRestartedPredicate returns a predicate for Update events that only returns true if the module-loader
+// container of a Pod restarted.
+func ModuleLoaderRestartedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldPod, ok := e.ObjectOld.(*v1.Pod)
+			if !ok {
+				return false
+			}
+
+			newPod, ok := e.ObjectNew.(*v1.Pod)
+			if !ok {
+				return false
+			}
+
+			return daemonset.ModuleLoaderRestarts(oldPod) != daemonset.ModuleLoaderRestarts(newPod)
+		},
+	}
+}
+
+// ModuleLoadRejectedPredicate returns a predicate that only returns true for Update events in which the kernel newly
+// rejected the module loaded by a module-loader Pod.
+func ModuleLoadRejectedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc:  func(_ event.CreateEvent) bool { return false },
+		DeleteFunc:  func(_ event.DeleteEvent) bool { return false },
+		GenericFunc: func(_ event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldPod, ok := e.ObjectOld.(*v1.Pod)
+			if !ok {
+				return false
+			}
+
+			newPod, ok := e.ObjectNew.(*v1.Pod)
+			if !ok {
+				return false
+			}
+
+			_, oldMessage := daemonset.ModuleLoadRejection(oldPod)
+			reason, newMessage := daemonset.ModuleLoadRejection(newPod)
+
+			return reason != "" && newMessage != oldMessage
+		},
+	}
+}
+
 func PreflightReconcilerUpdatePredicate() predicate.Predicate {
 	return predicate.GenerationChangedPredicate{}
 }
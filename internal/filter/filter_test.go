@@ -2,12 +2,14 @@ package filter
 
 import (
 	"context"
+	"errors"
 
 	"github.com/go-logr/logr"
 	"github.com/golang/mock/gomock"
 	hubv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api-hub/v1beta1"
 	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
 	mockClient "github.com/kubernetes-sigs/kernel-module-management/internal/client"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	appsv1 "k8s.io/api/apps/v1"
@@ -56,6 +58,40 @@ var _ = Describe("HasLabel", func() {
 	)
 })
 
+var _ = DescribeTable("LabelNotEqual",
+	func(labels map[string]string, expected bool) {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Labels: labels},
+		}
+
+		Expect(
+			LabelNotEqual("test-label", "companion").Create(event.CreateEvent{Object: pod}),
+		).To(
+			Equal(expected),
+		)
+	},
+	Entry("label not set", nil, true),
+	Entry("label set to another value", map[string]string{"test-label": "module-loader"}, true),
+	Entry("label set to the value", map[string]string{"test-label": "companion"}, false),
+)
+
+var _ = DescribeTable("HasAnnotation",
+	func(annotations map[string]string, expected bool) {
+		ds := &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Annotations: annotations},
+		}
+
+		Expect(
+			HasAnnotation("test-annotation").Create(event.CreateEvent{Object: ds}),
+		).To(
+			Equal(expected),
+		)
+	},
+	Entry("annotation not set", nil, false),
+	Entry("annotation set to empty value", map[string]string{"test-annotation": ""}, false),
+	Entry("annotation set to a concrete value", map[string]string{"test-annotation": "some-module"}, true),
+)
+
 var _ = Describe("skipDeletions", func() {
 	It("should return false for delete events", func() {
 		Expect(
@@ -67,11 +103,17 @@ var _ = Describe("skipDeletions", func() {
 })
 
 var _ = Describe("ModuleReconcilerNodePredicate", func() {
-	const kernelLabel = "kernel-label"
+	const (
+		kernelLabel      = "kernel-label"
+		extraKernelLabel = "extra-kernel-label"
+	)
+
 	var p predicate.Predicate
 
 	BeforeEach(func() {
-		p = New(nil, logr.Discard()).ModuleReconcilerNodePredicate(kernelLabel)
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = mockClient.NewMockClient(ctrl)
+		p = New(clnt, logr.Discard()).ModuleReconcilerNodePredicate(kernelLabel, extraKernelLabel)
 	})
 
 	It("should return true for creations", func() {
@@ -127,6 +169,139 @@ var _ = Describe("ModuleReconcilerNodePredicate", func() {
 		)
 	})
 
+	It("should return false for status-only updates", func() {
+		oldNode := v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{kernelLabel: "1.2.3"},
+			},
+		}
+
+		newNode := *oldNode.DeepCopy()
+		newNode.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}
+
+		Expect(
+			p.Update(event.UpdateEvent{ObjectOld: &oldNode, ObjectNew: &newNode}),
+		).To(
+			BeFalse(),
+		)
+	})
+
+	It("should return true if a NoSchedule taint was added", func() {
+		oldNode := v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{kernelLabel: "1.2.3"},
+			},
+		}
+
+		newNode := *oldNode.DeepCopy()
+		newNode.Spec.Taints = []v1.Taint{{Key: "some-taint", Effect: v1.TaintEffectNoSchedule}}
+
+		Expect(
+			p.Update(event.UpdateEvent{ObjectOld: &oldNode, ObjectNew: &newNode}),
+		).To(
+			BeTrue(),
+		)
+	})
+
+	It("should return false if a NoExecute taint was added", func() {
+		oldNode := v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{kernelLabel: "1.2.3"},
+			},
+		}
+
+		newNode := *oldNode.DeepCopy()
+		newNode.Spec.Taints = []v1.Taint{{Key: "some-taint", Effect: v1.TaintEffectNoExecute}}
+
+		Expect(
+			p.Update(event.UpdateEvent{ObjectOld: &oldNode, ObjectNew: &newNode}),
+		).To(
+			BeFalse(),
+		)
+	})
+
+	DescribeTable("label updates",
+		func(changedLabel string, expected bool) {
+			oldNode := v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{kernelLabel: "1.2.3"},
+				},
+			}
+
+			newNode := *oldNode.DeepCopy()
+			newNode.Labels[changedLabel] = "some-value"
+
+			clnt.EXPECT().List(context.Background(), &kmmv1beta1.ModuleList{}).DoAndReturn(
+				func(_ interface{}, list *kmmv1beta1.ModuleList, _ ...interface{}) error {
+					list.Items = []kmmv1beta1.Module{
+						{
+							Spec: kmmv1beta1.ModuleSpec{
+								ModuleLoader: kmmv1beta1.ModuleLoaderSpec{
+									Container: kmmv1beta1.ModuleLoaderContainerSpec{
+										KernelMappings: []kmmv1beta1.KernelMapping{
+											{Regexp: "^.+$"},
+											{
+												Regexp:       "^.+$",
+												NodeSelector: map[string]string{"mapping-label": "some-value"},
+											},
+										},
+									},
+								},
+								Selector: map[string]string{"selector-label": "some-value"},
+							},
+						},
+					}
+					return nil
+				},
+			)
+
+			Expect(
+				p.Update(event.UpdateEvent{ObjectOld: &oldNode, ObjectNew: &newNode}),
+			).To(
+				Equal(expected),
+			)
+		},
+		Entry("label used in a Module selector", "selector-label", true),
+		Entry("label used in a kernel mapping node selector", "mapping-label", true),
+		Entry("label not used in any Module selector", "other-label", false),
+	)
+
+	It("should return true if an extra kernel label changed", func() {
+		oldNode := v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{extraKernelLabel: "1.2"},
+			},
+		}
+
+		newNode := *oldNode.DeepCopy()
+		newNode.Labels[extraKernelLabel] = "1.3"
+
+		Expect(
+			p.Update(event.UpdateEvent{ObjectOld: &oldNode, ObjectNew: &newNode}),
+		).To(
+			BeTrue(),
+		)
+	})
+
+	It("should return true if the Modules cannot be listed", func() {
+		oldNode := v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{kernelLabel: "1.2.3"},
+			},
+		}
+
+		newNode := *oldNode.DeepCopy()
+		newNode.Labels["some-label"] = "some-value"
+
+		clnt.EXPECT().List(context.Background(), &kmmv1beta1.ModuleList{}).Return(errors.New("some error"))
+
+		Expect(
+			p.Update(event.UpdateEvent{ObjectOld: &oldNode, ObjectNew: &newNode}),
+		).To(
+			BeTrue(),
+		)
+	})
+
 	It("should return false for deletions", func() {
 		ev := event.DeleteEvent{
 			Object: &v1.Node{
@@ -263,6 +438,123 @@ var _ = Describe("NodeUpdateKernelChangedPredicate", func() {
 	)
 })
 
+var _ = Describe("FindModulesForSecret", func() {
+	const namespace = "namespace"
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = mockClient.NewMockClient(ctrl)
+	})
+
+	It("should return the Modules that reference the Secret", func() {
+		secretRef := &v1.LocalObjectReference{Name: "secret"}
+
+		mods := []kmmv1beta1.Module{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "pull-secret", Namespace: namespace},
+				Spec:       kmmv1beta1.ModuleSpec{ImageRepoSecret: secretRef},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "mapping-sign", Namespace: namespace},
+				Spec: kmmv1beta1.ModuleSpec{
+					ModuleLoader: kmmv1beta1.ModuleLoaderSpec{
+						Container: kmmv1beta1.ModuleLoaderContainerSpec{
+							KernelMappings: []kmmv1beta1.KernelMapping{
+								{Sign: &kmmv1beta1.Sign{CertSecret: secretRef}},
+							},
+						},
+					},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "build-secret", Namespace: namespace},
+				Spec: kmmv1beta1.ModuleSpec{
+					ModuleLoader: kmmv1beta1.ModuleLoaderSpec{
+						Container: kmmv1beta1.ModuleLoaderContainerSpec{
+							Build: &kmmv1beta1.Build{Secrets: []v1.LocalObjectReference{*secretRef}},
+						},
+					},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "other-secret", Namespace: namespace},
+				Spec: kmmv1beta1.ModuleSpec{
+					ImageRepoSecret: &v1.LocalObjectReference{Name: "other-secret"},
+				},
+			},
+		}
+
+		clnt.EXPECT().List(context.Background(), gomock.Any(), client.InNamespace(namespace)).DoAndReturn(
+			func(_ interface{}, list *kmmv1beta1.ModuleList, _ ...interface{}) error {
+				list.Items = mods
+				return nil
+			},
+		)
+
+		secret := &metav1.PartialObjectMetadata{
+			ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace},
+		}
+
+		Expect(
+			New(clnt, logr.Discard()).FindModulesForSecret(secret),
+		).To(
+			Equal([]reconcile.Request{
+				{NamespacedName: types.NamespacedName{Name: "pull-secret", Namespace: namespace}},
+				{NamespacedName: types.NamespacedName{Name: "mapping-sign", Namespace: namespace}},
+				{NamespacedName: types.NamespacedName{Name: "build-secret", Namespace: namespace}},
+			}),
+		)
+	})
+})
+
+var _ = Describe("FindModulesForConfigMap", func() {
+	const namespace = "namespace"
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = mockClient.NewMockClient(ctrl)
+	})
+
+	It("should return the Modules that use the ConfigMap as their Dockerfile", func() {
+		cmRef := &v1.LocalObjectReference{Name: "dockerfile"}
+
+		mods := []kmmv1beta1.Module{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "module-build", Namespace: namespace},
+				Spec: kmmv1beta1.ModuleSpec{
+					ModuleLoader: kmmv1beta1.ModuleLoaderSpec{
+						Container: kmmv1beta1.ModuleLoaderContainerSpec{
+							Build: &kmmv1beta1.Build{DockerfileConfigMap: cmRef},
+						},
+					},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "no-build", Namespace: namespace},
+			},
+		}
+
+		clnt.EXPECT().List(context.Background(), gomock.Any(), client.InNamespace(namespace)).DoAndReturn(
+			func(_ interface{}, list *kmmv1beta1.ModuleList, _ ...interface{}) error {
+				list.Items = mods
+				return nil
+			},
+		)
+
+		cm := &metav1.PartialObjectMetadata{
+			ObjectMeta: metav1.ObjectMeta{Name: "dockerfile", Namespace: namespace},
+		}
+
+		Expect(
+			New(clnt, logr.Discard()).FindModulesForConfigMap(cm),
+		).To(
+			Equal([]reconcile.Request{
+				{NamespacedName: types.NamespacedName{Name: "module-build", Namespace: namespace}},
+			}),
+		)
+	})
+})
+
 var _ = Describe("FindModulesForNode", func() {
 	BeforeEach(func() {
 		ctrl = gomock.NewController(GinkgoT())
@@ -502,6 +794,83 @@ var _ = Describe("PodReadinessChangedPredicate", func() {
 	)
 })
 
+var _ = Describe("ModuleLoaderRestartedPredicate", func() {
+	p := ModuleLoaderRestartedPredicate()
+
+	restartedPod := func(restarts int32) *v1.Pod {
+		return &v1.Pod{
+			Status: v1.PodStatus{
+				ContainerStatuses: []v1.ContainerStatus{
+					{Name: "module-loader", RestartCount: restarts},
+				},
+			},
+		}
+	}
+
+	DescribeTable(
+		"should return the expected value",
+		func(e event.UpdateEvent, expected bool) {
+			Expect(p.Update(e)).To(Equal(expected))
+		},
+		Entry("objects are not Pods", event.UpdateEvent{ObjectOld: &v1.Node{}, ObjectNew: &v1.Node{}}, false),
+		Entry("same restart count", event.UpdateEvent{ObjectOld: restartedPod(1), ObjectNew: restartedPod(1)}, false),
+		Entry("restarted", event.UpdateEvent{ObjectOld: restartedPod(1), ObjectNew: restartedPod(2)}, true),
+	)
+})
+
+var _ = Describe("ModuleLoadRejectedPredicate", func() {
+	p := ModuleLoadRejectedPredicate()
+
+	rejectedPod := func(message string) *v1.Pod {
+		return &v1.Pod{
+			Status: v1.PodStatus{
+				ContainerStatuses: []v1.ContainerStatus{
+					{
+						Name: "module-loader",
+						LastTerminationState: v1.ContainerState{
+							Terminated: &v1.ContainerStateTerminated{Message: message},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	It("should ignore creations and deletions", func() {
+		pod := rejectedPod("Key was rejected by service")
+
+		Expect(p.Create(event.CreateEvent{Object: pod})).To(BeFalse())
+		Expect(p.Delete(event.DeleteEvent{Object: pod})).To(BeFalse())
+	})
+
+	DescribeTable(
+		"should return the expected value",
+		func(e event.UpdateEvent, expected bool) {
+			Expect(p.Update(e)).To(Equal(expected))
+		},
+		Entry("objects are not pods", event.UpdateEvent{ObjectOld: &v1.Node{}, ObjectNew: &v1.Node{}}, false),
+		Entry("no rejection", event.UpdateEvent{ObjectOld: &v1.Pod{}, ObjectNew: &v1.Pod{}}, false),
+		Entry(
+			"new rejection",
+			event.UpdateEvent{ObjectOld: &v1.Pod{}, ObjectNew: rejectedPod("Key was rejected by service")},
+			true,
+		),
+		Entry(
+			"same rejection",
+			event.UpdateEvent{
+				ObjectOld: rejectedPod("Key was rejected by service"),
+				ObjectNew: rejectedPod("Key was rejected by service"),
+			},
+			false,
+		),
+		Entry(
+			"other failure",
+			event.UpdateEvent{ObjectOld: &v1.Pod{}, ObjectNew: rejectedPod("Module not found")},
+			false,
+		),
+	)
+})
+
 var _ = Describe("FindPreflightsForModule", func() {
 
 	BeforeEach(func() {
@@ -545,3 +914,57 @@ var _ = Describe("FindPreflightsForModule", func() {
 	})
 
 })
+
+var _ = Describe("EnqueueAllNodes", func() {
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = mockClient.NewMockClient(ctrl)
+	})
+
+	It("should return a request for each node", func() {
+		clnt.EXPECT().List(context.Background(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, list *v1.NodeList, _ ...interface{}) error {
+				list.Items = []v1.Node{
+					{ObjectMeta: metav1.ObjectMeta{Name: "node1"}},
+					{ObjectMeta: metav1.ObjectMeta{Name: "node2"}},
+				}
+				return nil
+			},
+		)
+
+		res := New(clnt, logr.Discard()).EnqueueAllNodes(&kmmv1beta1.Module{})
+		Expect(res).To(Equal([]reconcile.Request{
+			{NamespacedName: types.NamespacedName{Name: "node1"}},
+			{NamespacedName: types.NamespacedName{Name: "node2"}},
+		}))
+	})
+})
+
+var _ = Describe("ModuleReadinessGroupPredicate", func() {
+	p := ModuleReadinessGroupPredicate()
+
+	modWithGroup := func(group string, generation int64) *kmmv1beta1.Module {
+		return &kmmv1beta1.Module{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:     map[string]string{constants.ReadinessGroupLabel: group},
+				Generation: generation,
+			},
+		}
+	}
+
+	It("should only return true for the creation of Modules in a group", func() {
+		Expect(p.Create(event.CreateEvent{Object: modWithGroup("kubevirt", 1)})).To(BeTrue())
+		Expect(p.Create(event.CreateEvent{Object: &kmmv1beta1.Module{}})).To(BeFalse())
+	})
+
+	DescribeTable("should return the expected value on updates",
+		func(oldMod, newMod *kmmv1beta1.Module, expected bool) {
+			Expect(p.Update(event.UpdateEvent{ObjectOld: oldMod, ObjectNew: newMod})).To(Equal(expected))
+		},
+		Entry("no group", &kmmv1beta1.Module{}, &kmmv1beta1.Module{}, false),
+		Entry("status update", modWithGroup("kubevirt", 1), modWithGroup("kubevirt", 1), false),
+		Entry("spec update", modWithGroup("kubevirt", 1), modWithGroup("kubevirt", 2), true),
+		Entry("group added", &kmmv1beta1.Module{}, modWithGroup("kubevirt", 0), true),
+		Entry("group removed", modWithGroup("kubevirt", 0), &kmmv1beta1.Module{}, true),
+	)
+})
@@ -0,0 +1,57 @@
+package filter
+
+import (
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// EnqueueRequestsFromMapFuncAfter works like handler.EnqueueRequestsFromMapFunc, but adds the requests to the queue
+// after delay.
+// The workqueue only keeps one instance of a request waiting to be added, so all the events received during delay that
+// map to the same request result in a single reconciliation. This coalesces the bursts of events sent when many
+// objects change at once, such as all nodes running the same kernel.
+func EnqueueRequestsFromMapFuncAfter(fn handler.MapFunc, delay time.Duration) handler.EventHandler {
+	return &delayedEnqueueRequestsFromMapFunc{
+		toRequests: fn,
+		delay:      delay,
+	}
+}
+
+type delayedEnqueueRequestsFromMapFunc struct {
+	toRequests handler.MapFunc
+	delay      time.Duration
+}
+
+func (e *delayedEnqueueRequestsFromMapFunc) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	e.mapAndEnqueue(q, evt.Object)
+}
+
+func (e *delayedEnqueueRequestsFromMapFunc) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	e.mapAndEnqueue(q, evt.ObjectOld, evt.ObjectNew)
+}
+
+func (e *delayedEnqueueRequestsFromMapFunc) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	e.mapAndEnqueue(q, evt.Object)
+}
+
+func (e *delayedEnqueueRequestsFromMapFunc) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	e.mapAndEnqueue(q, evt.Object)
+}
+
+func (e *delayedEnqueueRequestsFromMapFunc) mapAndEnqueue(q workqueue.RateLimitingInterface, objects ...client.Object) {
+	reqs := make(map[reconcile.Request]struct{})
+
+	for _, o := range objects {
+		for _, req := range e.toRequests(o) {
+			if _, ok := reqs[req]; !ok {
+				q.AddAfter(req, e.delay)
+				reqs[req] = struct{}{}
+			}
+		}
+	}
+}
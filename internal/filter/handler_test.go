@@ -0,0 +1,57 @@
+package filter
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("EnqueueRequestsFromMapFuncAfter", func() {
+	var q workqueue.RateLimitingInterface
+
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "name", Namespace: "namespace"}}
+
+	mapFunc := func(_ client.Object) []reconcile.Request {
+		return []reconcile.Request{req}
+	}
+
+	BeforeEach(func() {
+		q = workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	})
+
+	AfterEach(func() {
+		q.ShutDown()
+	})
+
+	It("should enqueue a single request for many events received during the delay", func() {
+		h := EnqueueRequestsFromMapFuncAfter(mapFunc, 100*time.Millisecond)
+
+		for i := 0; i < 10; i++ {
+			node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+			h.Update(event.UpdateEvent{ObjectOld: node, ObjectNew: node}, q)
+		}
+
+		Expect(q.Len()).To(Equal(0))
+		Eventually(q.Len).Should(Equal(1))
+		Consistently(q.Len, 200*time.Millisecond).Should(Equal(1))
+
+		item, _ := q.Get()
+		Expect(item).To(Equal(req))
+	})
+
+	It("should enqueue the request immediately when there is no delay", func() {
+		h := EnqueueRequestsFromMapFuncAfter(mapFunc, 0)
+
+		h.Create(event.CreateEvent{Object: &v1.Node{}}, q)
+
+		Expect(q.Len()).To(Equal(1))
+	})
+})
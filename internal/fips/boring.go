@@ -0,0 +1,15 @@
+//go:build goexperiment.boringcrypto
+
+package fips
+
+import (
+	"crypto/boring"
+
+	// restrict TLS to FIPS-approved settings
+	_ "crypto/tls/fipsonly"
+)
+
+// Enabled returns true if the binary was built with, and uses the FIPS 140-2 validated BoringCrypto module.
+func Enabled() bool {
+	return boring.Enabled()
+}
@@ -0,0 +1,64 @@
+package fips
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+const minRSAKeySize = 2048
+
+// The curves approved by FIPS 186-4 and supported by the kernel.
+var approvedCurves = map[elliptic.Curve]bool{
+	elliptic.P256(): true,
+	elliptic.P384(): true,
+	elliptic.P521(): true,
+}
+
+var approvedSignatureAlgorithms = map[x509.SignatureAlgorithm]bool{
+	x509.SHA256WithRSA:    true,
+	x509.SHA384WithRSA:    true,
+	x509.SHA512WithRSA:    true,
+	x509.SHA256WithRSAPSS: true,
+	x509.SHA384WithRSAPSS: true,
+	x509.SHA512WithRSAPSS: true,
+	x509.ECDSAWithSHA256:  true,
+	x509.ECDSAWithSHA384:  true,
+	x509.ECDSAWithSHA512:  true,
+}
+
+// CheckSigningCertificate returns an error if the kernel module signing certificate cert, in DER or PEM format, does
+// not use FIPS-approved algorithms.
+func CheckSigningCertificate(cert []byte) error {
+	if block, _ := pem.Decode(cert); block != nil {
+		cert = block.Bytes
+	}
+
+	c, err := x509.ParseCertificate(cert)
+	if err != nil {
+		return fmt.Errorf("could not parse the certificate: %v", err)
+	}
+
+	if !approvedSignatureAlgorithms[c.SignatureAlgorithm] {
+		return fmt.Errorf("the certificate is signed with %v, which is not FIPS-approved", c.SignatureAlgorithm)
+	}
+
+	switch pub := c.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if size := pub.N.BitLen(); size < minRSAKeySize {
+			return fmt.Errorf("%d-bit RSA keys are not FIPS-approved; at least %d bits are required", size, minRSAKeySize)
+		}
+	case *ecdsa.PublicKey:
+		if !approvedCurves[pub.Curve] {
+			return fmt.Errorf("curve %s is not FIPS-approved", pub.Curve.Params().Name)
+		}
+	default:
+		return errors.New("only RSA and ECDSA keys are FIPS-approved")
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package fips
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func makeCertificate(key crypto.Signer, sigAlg x509.SignatureAlgorithm) []byte {
+	template := x509.Certificate{
+		SerialNumber:       big.NewInt(1),
+		Subject:            pkix.Name{CommonName: "kmm-signing-key"},
+		NotBefore:          time.Now(),
+		NotAfter:           time.Now().Add(time.Hour),
+		SignatureAlgorithm: sigAlg,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, key.Public(), key)
+	Expect(err).NotTo(HaveOccurred())
+
+	return der
+}
+
+var _ = Describe("CheckSigningCertificate", func() {
+	var (
+		rsaKey   *rsa.PrivateKey
+		ecdsaKey *ecdsa.PrivateKey
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+
+		ecdsaKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should accept an RSA certificate in DER format", func() {
+		Expect(
+			CheckSigningCertificate(makeCertificate(rsaKey, x509.SHA256WithRSA)),
+		).To(
+			Succeed(),
+		)
+	})
+
+	It("should accept an ECDSA certificate in PEM format", func() {
+		der := makeCertificate(ecdsaKey, x509.ECDSAWithSHA384)
+
+		Expect(
+			CheckSigningCertificate(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		).To(
+			Succeed(),
+		)
+	})
+
+	It("should reject invalid certificates", func() {
+		Expect(CheckSigningCertificate([]byte("some public key"))).NotTo(Succeed())
+	})
+
+	It("should reject SHA-1 signatures", func() {
+		Expect(
+			CheckSigningCertificate(makeCertificate(rsaKey, x509.SHA1WithRSA)),
+		).To(
+			MatchError(ContainSubstring("SHA1-RSA")),
+		)
+	})
+
+	It("should reject small RSA keys", func() {
+		smallKey, err := rsa.GenerateKey(rand.Reader, 1024)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(
+			CheckSigningCertificate(makeCertificate(smallKey, x509.SHA256WithRSA)),
+		).To(
+			MatchError(ContainSubstring("1024-bit")),
+		)
+	})
+
+	It("should reject unapproved curves", func() {
+		key, err := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(
+			CheckSigningCertificate(makeCertificate(key, x509.ECDSAWithSHA256)),
+		).To(
+			MatchError(ContainSubstring("P-224")),
+		)
+	})
+
+	It("should reject Ed25519 keys", func() {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(
+			CheckSigningCertificate(makeCertificate(key, x509.PureEd25519)),
+		).To(
+			HaveOccurred(),
+		)
+	})
+})
@@ -0,0 +1,8 @@
+//go:build !goexperiment.boringcrypto
+
+package fips
+
+// Enabled returns true if the binary was built with, and uses the FIPS 140-2 validated BoringCrypto module.
+func Enabled() bool {
+	return false
+}
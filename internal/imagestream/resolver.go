@@ -0,0 +1,87 @@
+package imagestream
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ReferencePrefix is the prefix of the container images that reference an OpenShift ImageStreamTag in the namespace
+// of the Module, e.g. imagestreamtag:my-kmod:5.14.0-70.13.1.el9_0.x86_64.
+const ReferencePrefix = "imagestreamtag:"
+
+const referencePolicyLocal = "Local"
+
+var (
+	imageStreamGVK    = schema.GroupVersionKind{Group: "image.openshift.io", Version: "v1", Kind: "ImageStream"}
+	imageStreamTagGVK = schema.GroupVersionKind{Group: "image.openshift.io", Version: "v1", Kind: "ImageStreamTag"}
+)
+
+//+kubebuilder:rbac:groups=image.openshift.io,resources=imagestreams;imagestreamtags,verbs=get
+
+//go:generate mockgen -source=resolver.go -package=imagestream -destination=mock_resolver.go
+
+type Resolver interface {
+	Resolve(ctx context.Context, image, namespace string) (string, error)
+}
+
+type resolver struct {
+	client client.Client
+}
+
+func NewResolver(client client.Client) Resolver {
+	return &resolver{client: client}
+}
+
+// Resolve returns image if it does not start with ReferencePrefix, and otherwise the pull spec by digest of the image
+// that the referenced ImageStreamTag in namespace points to.
+// If the tag's reference policy is Local, like for pull-through tags, the pull spec is in the integrated registry.
+func (r *resolver) Resolve(ctx context.Context, image, namespace string) (string, error) {
+	name := strings.TrimPrefix(image, ReferencePrefix)
+	if name == image {
+		return image, nil
+	}
+
+	ist := unstructured.Unstructured{}
+	ist.SetGroupVersionKind(imageStreamTagGVK)
+
+	if err := r.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &ist); err != nil {
+		return "", fmt.Errorf("could not get ImageStreamTag %s/%s: %v", namespace, name, err)
+	}
+
+	digest, _, _ := unstructured.NestedString(ist.Object, "image", "metadata", "name")
+	if digest == "" {
+		return "", fmt.Errorf("ImageStreamTag %s/%s does not point to an image yet", namespace, name)
+	}
+
+	policy, _, _ := unstructured.NestedString(ist.Object, "tag", "referencePolicy", "type")
+	if policy != referencePolicyLocal {
+		ref, _, _ := unstructured.NestedString(ist.Object, "image", "dockerImageReference")
+		if ref == "" {
+			return "", fmt.Errorf("ImageStreamTag %s/%s has no image reference", namespace, name)
+		}
+
+		return ref, nil
+	}
+
+	isName, _, _ := strings.Cut(name, ":")
+
+	is := unstructured.Unstructured{}
+	is.SetGroupVersionKind(imageStreamGVK)
+
+	if err := r.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: isName}, &is); err != nil {
+		return "", fmt.Errorf("could not get ImageStream %s/%s: %v", namespace, isName, err)
+	}
+
+	repo, _, _ := unstructured.NestedString(is.Object, "status", "dockerImageRepository")
+	if repo == "" {
+		return "", fmt.Errorf("ImageStream %s/%s is not exposed by the integrated registry", namespace, isName)
+	}
+
+	return repo + "@" + digest, nil
+}
@@ -0,0 +1,92 @@
+package imagestream
+
+import (
+	"context"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mock_client "github.com/kubernetes-sigs/kernel-module-management/internal/client"
+)
+
+var _ = Describe("Resolve", func() {
+	const (
+		namespace = "kmm-ns"
+		digest    = "sha256:0123456789abcdef"
+	)
+
+	var (
+		clnt *mock_client.MockClient
+		r    Resolver
+	)
+
+	BeforeEach(func() {
+		ctrl := gomock.NewController(GinkgoT())
+		clnt = mock_client.NewMockClient(ctrl)
+		r = NewResolver(clnt)
+	})
+
+	ctx := context.Background()
+	istKey := types.NamespacedName{Namespace: namespace, Name: "kmod:5.14"}
+
+	expectGetTag := func(policy string) *gomock.Call {
+		return clnt.
+			EXPECT().
+			Get(ctx, istKey, gomock.AssignableToTypeOf(&unstructured.Unstructured{})).
+			Do(func(_ context.Context, _ types.NamespacedName, ist *unstructured.Unstructured, _ ...client.GetOption) {
+				ist.Object["image"] = map[string]interface{}{
+					"metadata":             map[string]interface{}{"name": digest},
+					"dockerImageReference": "quay.io/vendor/kmod@" + digest,
+				}
+				ist.Object["tag"] = map[string]interface{}{
+					"referencePolicy": map[string]interface{}{"type": policy},
+				}
+			})
+	}
+
+	It("should return images that do not reference an ImageStreamTag", func() {
+		Expect(r.Resolve(ctx, "quay.io/vendor/kmod:5.14", namespace)).To(Equal("quay.io/vendor/kmod:5.14"))
+	})
+
+	It("should return the source reference of the image", func() {
+		expectGetTag("Source")
+
+		Expect(r.Resolve(ctx, "imagestreamtag:kmod:5.14", namespace)).To(Equal("quay.io/vendor/kmod@" + digest))
+	})
+
+	It("should return the reference in the integrated registry for Local tags", func() {
+		gomock.InOrder(
+			expectGetTag("Local"),
+			clnt.
+				EXPECT().
+				Get(ctx, types.NamespacedName{Namespace: namespace, Name: "kmod"}, gomock.AssignableToTypeOf(&unstructured.Unstructured{})).
+				Do(func(_ context.Context, _ types.NamespacedName, is *unstructured.Unstructured, _ ...client.GetOption) {
+					is.Object["status"] = map[string]interface{}{
+						"dockerImageRepository": "image-registry.openshift-image-registry.svc:5000/kmm-ns/kmod",
+					}
+				}),
+		)
+
+		Expect(
+			r.Resolve(ctx, "imagestreamtag:kmod:5.14", namespace),
+		).To(
+			Equal("image-registry.openshift-image-registry.svc:5000/kmm-ns/kmod@" + digest),
+		)
+	})
+
+	It("should return an error if the ImageStreamTag does not exist", func() {
+		clnt.
+			EXPECT().
+			Get(ctx, istKey, gomock.Any()).
+			Return(k8serrors.NewNotFound(schema.GroupResource{}, istKey.Name))
+
+		_, err := r.Resolve(ctx, "imagestreamtag:kmod:5.14", namespace)
+		Expect(err).To(HaveOccurred())
+	})
+})
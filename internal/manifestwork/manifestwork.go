@@ -20,6 +20,25 @@ import (
 	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
 )
 
+// Names of the status feedback values that the work agent of managed clusters reports for their Module.
+const (
+	feedbackPhase                     = "phase"
+	feedbackReadyKernels              = "readyKernels"
+	feedbackTotalKernels              = "totalKernels"
+	feedbackModuleLoaderAvailable     = "moduleLoaderAvailable"
+	feedbackModuleLoaderDesired       = "moduleLoaderDesired"
+	feedbackModuleLoaderMatchingNodes = "moduleLoaderNodesMatchingSelector"
+)
+
+var moduleStatusFeedbackPaths = []workv1.JsonPath{
+	{Name: feedbackPhase, Path: ".status.phase"},
+	{Name: feedbackReadyKernels, Path: ".status.readyKernels"},
+	{Name: feedbackTotalKernels, Path: ".status.totalKernels"},
+	{Name: feedbackModuleLoaderAvailable, Path: ".status.moduleLoader.availableNumber"},
+	{Name: feedbackModuleLoaderDesired, Path: ".status.moduleLoader.desiredNumber"},
+	{Name: feedbackModuleLoaderMatchingNodes, Path: ".status.moduleLoader.nodesMatchingSelectorNumber"},
+}
+
 //go:generate mockgen -source=manifestwork.go -package=manifestwork -destination=mock_manifestwork.go
 
 type ManifestWorkCreator interface {
@@ -107,11 +126,71 @@ func (mwg *manifestWorkGenerator) SetManifestWorkAsDesired(ctx context.Context,
 		Workload: workv1.ManifestsTemplate{
 			Manifests: []workv1.Manifest{manifest},
 		},
+		// Have the work agent report the status of the Module, so that it can be aggregated on the hub.
+		ManifestConfigs: []workv1.ManifestConfigOption{
+			{
+				ResourceIdentifier: workv1.ResourceIdentifier{
+					Group:     kmmv1beta1.GroupVersion.Group,
+					Resource:  "modules",
+					Name:      mod.Name,
+					Namespace: mod.Namespace,
+				},
+				FeedbackRules: []workv1.FeedbackRule{
+					{Type: workv1.JSONPathsType, JsonPaths: moduleStatusFeedbackPaths},
+				},
+			},
+		},
 	}
 
 	return controllerutil.SetControllerReference(&mcm, mw, mwg.scheme)
 }
 
+// ClusterModuleStatus returns the status of the Module deployed by mw, as reported by the work agent of its managed
+// cluster.
+// It returns false if the work agent has not reported it yet.
+func ClusterModuleStatus(mw *workv1.ManifestWork) (hubv1beta1.ClusterModuleStatus, bool) {
+	kind := reflect.TypeOf(kmmv1beta1.Module{}).Name()
+
+	for _, m := range mw.Status.ResourceStatus.Manifests {
+		if m.ResourceMeta.Kind != kind || len(m.StatusFeedbacks.Values) == 0 {
+			continue
+		}
+
+		cms := hubv1beta1.ClusterModuleStatus{ClusterName: mw.Namespace}
+
+		for _, v := range m.StatusFeedbacks.Values {
+			switch v.Name {
+			case feedbackPhase:
+				if v.Value.String != nil {
+					cms.Phase = kmmv1beta1.KernelPhase(*v.Value.String)
+				}
+			case feedbackReadyKernels:
+				cms.ReadyKernels = feedbackInt32(v.Value)
+			case feedbackTotalKernels:
+				cms.TotalKernels = feedbackInt32(v.Value)
+			case feedbackModuleLoaderAvailable:
+				cms.ModuleLoader.AvailableNumber = feedbackInt32(v.Value)
+			case feedbackModuleLoaderDesired:
+				cms.ModuleLoader.DesiredNumber = feedbackInt32(v.Value)
+			case feedbackModuleLoaderMatchingNodes:
+				cms.ModuleLoader.NodesMatchingSelectorNumber = feedbackInt32(v.Value)
+			}
+		}
+
+		return cms, true
+	}
+
+	return hubv1beta1.ClusterModuleStatus{}, false
+}
+
+func feedbackInt32(v workv1.FieldValue) int32 {
+	if v.Integer == nil {
+		return 0
+	}
+
+	return int32(*v.Integer)
+}
+
 func (mwg *manifestWorkGenerator) getOwnedManifestWorks(ctx context.Context, mcm hubv1beta1.ManagedClusterModule) (*workv1.ManifestWorkList, error) {
 	manifestWorkList := &workv1.ManifestWorkList{}
 
@@ -158,4 +158,87 @@ var _ = Describe("SetManifestWorkAsDesired", func() {
 		Expect(mw.Spec.Workload.Manifests).To(HaveLen(1))
 		Expect((mw.Spec.Workload.Manifests[0].RawExtension.Object).(*kmmv1beta1.Module).Spec).To(Equal(mcm.Spec.ModuleSpec))
 	})
+
+	It("should request the status of the Module from the work agent", func() {
+		mcm := hubv1beta1.ManagedClusterModule{
+			ObjectMeta: metav1.ObjectMeta{Name: "some-module"},
+			Spec:       hubv1beta1.ManagedClusterModuleSpec{SpokeNamespace: "kmm"},
+		}
+
+		mw := &workv1.ManifestWork{}
+
+		err := mwc.SetManifestWorkAsDesired(context.Background(), mw, mcm)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mw.Spec.ManifestConfigs).To(HaveLen(1))
+		Expect(mw.Spec.ManifestConfigs[0].ResourceIdentifier).To(Equal(workv1.ResourceIdentifier{
+			Group:     "kmm.sigs.x-k8s.io",
+			Resource:  "modules",
+			Name:      "some-module",
+			Namespace: "kmm",
+		}))
+		Expect(mw.Spec.ManifestConfigs[0].FeedbackRules).To(Equal([]workv1.FeedbackRule{
+			{Type: workv1.JSONPathsType, JsonPaths: moduleStatusFeedbackPaths},
+		}))
+	})
+})
+
+var _ = Describe("ClusterModuleStatus", func() {
+	It("should return false if the work agent has not reported the status yet", func() {
+		mw := workv1.ManifestWork{
+			Status: workv1.ManifestWorkStatus{
+				ResourceStatus: workv1.ManifestResourceStatus{
+					Manifests: []workv1.ManifestCondition{
+						{ResourceMeta: workv1.ManifestResourceMeta{Kind: "Module"}},
+					},
+				},
+			},
+		}
+
+		_, ok := ClusterModuleStatus(&mw)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should return the status reported by the work agent", func() {
+		phase := "Ready"
+		integer := func(i int64) workv1.FieldValue {
+			return workv1.FieldValue{Type: workv1.Integer, Integer: &i}
+		}
+
+		mw := workv1.ManifestWork{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "cluster-1"},
+			Status: workv1.ManifestWorkStatus{
+				ResourceStatus: workv1.ManifestResourceStatus{
+					Manifests: []workv1.ManifestCondition{
+						{
+							ResourceMeta: workv1.ManifestResourceMeta{Kind: "Module"},
+							StatusFeedbacks: workv1.StatusFeedbackResult{
+								Values: []workv1.FeedbackValue{
+									{Name: "phase", Value: workv1.FieldValue{Type: workv1.String, String: &phase}},
+									{Name: "readyKernels", Value: integer(2)},
+									{Name: "totalKernels", Value: integer(2)},
+									{Name: "moduleLoaderAvailable", Value: integer(3)},
+									{Name: "moduleLoaderDesired", Value: integer(3)},
+									{Name: "moduleLoaderNodesMatchingSelector", Value: integer(4)},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		cms, ok := ClusterModuleStatus(&mw)
+		Expect(ok).To(BeTrue())
+		Expect(cms).To(Equal(hubv1beta1.ClusterModuleStatus{
+			ClusterName: "cluster-1",
+			ModuleLoader: kmmv1beta1.DaemonSetStatus{
+				NodesMatchingSelectorNumber: 4,
+				DesiredNumber:               3,
+				AvailableNumber:             3,
+			},
+			Phase:        kmmv1beta1.KernelPhaseReady,
+			ReadyKernels: 2,
+			TotalKernels: 2,
+		}))
+	})
 })
@@ -9,10 +9,17 @@ import (
 const (
 	existingKMMOModulesQuery = "kmmo_module_total"
 	completedKMMOStageQuery  = "kmmo_completed_stage"
+	dryRunOperationsQuery    = "kmmo_dry_run_operations_total"
+	prunedImagesQuery        = "kmmo_pruned_images_total"
 	BuildStage               = "build"
 	SignStage                = "sign"
 	ModuleLoaderStage        = "module-loader"
 	DevicePluginStage        = "device-plugin"
+
+	// Results of image pruning.
+	PruneDeleted = "deleted"
+	PruneDryRun  = "dry-run"
+	PruneFailed  = "failed"
 )
 
 //go:generate mockgen -source=metrics.go -package=metrics -destination=mock_metrics_api.go
@@ -22,11 +29,15 @@ type Metrics interface {
 	Register()
 	SetExistingKMMOModules(value int)
 	SetCompletedStage(kmmoName, kmmoNamespace, kernelVersion, stage string, completed bool)
+	AddDryRunOperation(kind, verb string)
+	AddPrunedImage(kmmoName, kmmoNamespace, result string)
 }
 
 type metrics struct {
 	kmmoResourcesNum   prometheus.Gauge
 	kmmoCompletedStage *prometheus.GaugeVec
+	dryRunOperations   *prometheus.CounterVec
+	prunedImages       *prometheus.CounterVec
 }
 
 func New() Metrics {
@@ -45,9 +56,27 @@ func New() Metrics {
 		[]string{"kmmo", "namespace", "kernel", "stage"},
 	)
 
+	dryRunOperations := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: dryRunOperationsQuery,
+			Help: "For a given kind and verb (create, update, patch, delete), the number of operations that the operator would have run if it were not in dry-run mode.",
+		},
+		[]string{"kind", "verb"},
+	)
+
+	prunedImages := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: prunedImagesQuery,
+			Help: "For a given kmmo, namespace and result (deleted, dry-run, failed), the number of images built or signed by KMM that the image retention policy pruned from their registry.",
+		},
+		[]string{"kmmo", "namespace", "result"},
+	)
+
 	return &metrics{
 		kmmoResourcesNum:   kmmoResourcesNum,
 		kmmoCompletedStage: completedStages,
+		dryRunOperations:   dryRunOperations,
+		prunedImages:       prunedImages,
 	}
 }
 
@@ -55,6 +84,8 @@ func (m *metrics) Register() {
 	runtimemetrics.Registry.MustRegister(
 		m.kmmoResourcesNum,
 		m.kmmoCompletedStage,
+		m.dryRunOperations,
+		m.prunedImages,
 	)
 }
 
@@ -69,3 +100,11 @@ func (m *metrics) SetCompletedStage(kmmoName, kmmoNamespace, kernelVersion, stag
 	}
 	m.kmmoCompletedStage.WithLabelValues(kmmoName, kmmoNamespace, kernelVersion, stage).Set(value)
 }
+
+func (m *metrics) AddDryRunOperation(kind, verb string) {
+	m.dryRunOperations.WithLabelValues(kind, verb).Inc()
+}
+
+func (m *metrics) AddPrunedImage(kmmoName, kmmoNamespace, result string) {
+	m.prunedImages.WithLabelValues(kmmoName, kmmoNamespace, result).Inc()
+}
@@ -33,6 +33,30 @@ func (m *MockMetrics) EXPECT() *MockMetricsMockRecorder {
 	return m.recorder
 }
 
+// AddDryRunOperation mocks base method.
+func (m *MockMetrics) AddDryRunOperation(kind, verb string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddDryRunOperation", kind, verb)
+}
+
+// AddDryRunOperation indicates an expected call of AddDryRunOperation.
+func (mr *MockMetricsMockRecorder) AddDryRunOperation(kind, verb interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddDryRunOperation", reflect.TypeOf((*MockMetrics)(nil).AddDryRunOperation), kind, verb)
+}
+
+// AddPrunedImage mocks base method.
+func (m *MockMetrics) AddPrunedImage(kmmoName, kmmoNamespace, result string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddPrunedImage", kmmoName, kmmoNamespace, result)
+}
+
+// AddPrunedImage indicates an expected call of AddPrunedImage.
+func (mr *MockMetricsMockRecorder) AddPrunedImage(kmmoName, kmmoNamespace, result interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPrunedImage", reflect.TypeOf((*MockMetrics)(nil).AddPrunedImage), kmmoName, kmmoNamespace, result)
+}
+
 // Register mocks base method.
 func (m *MockMetrics) Register() {
 	m.ctrl.T.Helper()
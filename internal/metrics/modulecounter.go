@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ModuleCounter is a manager.Runnable that periodically sets the number of existing Modules in the metrics.
+// Counting Modules requires listing all of them; doing it periodically rather than on each Module reconciliation
+// keeps that list out of the reconciliation path.
+type ModuleCounter struct {
+	reader     client.Reader
+	metricsAPI Metrics
+	period     time.Duration
+	logger     logr.Logger
+}
+
+func NewModuleCounter(reader client.Reader, metricsAPI Metrics, period time.Duration, logger logr.Logger) *ModuleCounter {
+	return &ModuleCounter{
+		reader:     reader,
+		metricsAPI: metricsAPI,
+		period:     period,
+		logger:     logger,
+	}
+}
+
+// Start counts the Modules every period until ctx is done.
+func (mc *ModuleCounter) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, mc.countModules, mc.period)
+	return nil
+}
+
+func (mc *ModuleCounter) countModules(ctx context.Context) {
+	mods := kmmv1beta1.ModuleList{}
+
+	if err := mc.reader.List(ctx, &mods); err != nil {
+		mc.logger.Error(err, "failed to list Modules for metrics")
+		return
+	}
+
+	mc.metricsAPI.SetExistingKMMOModules(len(mods.Items))
+}
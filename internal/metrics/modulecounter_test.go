@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/client"
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("ModuleCounter_countModules", func() {
+	var (
+		ctrl        *gomock.Controller
+		clnt        *client.MockClient
+		mockMetrics *MockMetrics
+		mc          *ModuleCounter
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+		mockMetrics = NewMockMetrics(ctrl)
+		mc = NewModuleCounter(clnt, mockMetrics, 0, logr.Discard())
+	})
+
+	ctx := context.Background()
+
+	It("should set the number of existing Modules", func() {
+		gomock.InOrder(
+			clnt.EXPECT().List(ctx, &kmmv1beta1.ModuleList{}).DoAndReturn(
+				func(_ interface{}, list *kmmv1beta1.ModuleList, _ ...interface{}) error {
+					list.Items = []kmmv1beta1.Module{{}, {}}
+					return nil
+				},
+			),
+			mockMetrics.EXPECT().SetExistingKMMOModules(2),
+		)
+
+		mc.countModules(ctx)
+	})
+
+	It("should not set the metric if the Modules could not be listed", func() {
+		clnt.EXPECT().List(ctx, &kmmv1beta1.ModuleList{}).Return(errors.New("some error"))
+
+		mc.countModules(ctx)
+	})
+})
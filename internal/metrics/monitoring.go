@@ -0,0 +1,155 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// MonitoringObjectsName is the name of the metrics Service and of the ServiceMonitor created by the operator.
+	MonitoringObjectsName = "kmm-operator-metrics"
+
+	// metricsPortName and metricsPort are those of the authenticating proxy in front of the metrics server.
+	metricsPortName = "https"
+	metricsPort     = 8443
+
+	// serviceNameLabel is set on the Service for the ServiceMonitor to select it, and not another Service exposing
+	// the same pods.
+	serviceNameLabel = "app.kubernetes.io/name"
+
+	serviceAccountTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+var serviceMonitorGVK = schema.GroupVersionKind{
+	Group:   "monitoring.coreos.com",
+	Version: "v1",
+	Kind:    "ServiceMonitor",
+}
+
+//+kubebuilder:rbac:groups="",resources=services,verbs=create;get;patch
+//+kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=create;get;patch
+
+// MonitoringCreator is a manager.Runnable that creates the Service exposing the metrics of the operator and, if the
+// Prometheus Operator is installed, a ServiceMonitor scraping it.
+// Both are created from the same settings as the metrics endpoint, so that they cannot drift from it.
+type MonitoringCreator struct {
+	client    client.Client
+	scheme    *runtime.Scheme
+	namespace string
+	podLabels map[string]string
+	logger    logr.Logger
+}
+
+// NewMonitoringCreator returns a MonitoringCreator for the operator pods running in namespace with podLabels.
+func NewMonitoringCreator(
+	client client.Client,
+	scheme *runtime.Scheme,
+	namespace string,
+	podLabels map[string]string,
+	logger logr.Logger,
+) *MonitoringCreator {
+	return &MonitoringCreator{
+		client:    client,
+		scheme:    scheme,
+		namespace: namespace,
+		podLabels: podLabels,
+		logger:    logger,
+	}
+}
+
+// Start creates or updates the Service and the ServiceMonitor once.
+func (mc *MonitoringCreator) Start(ctx context.Context) error {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      MonitoringObjectsName,
+			Namespace: mc.namespace,
+		},
+	}
+
+	opRes, err := controllerutil.CreateOrPatch(ctx, mc.client, svc, func() error {
+		mc.setServiceSpec(svc)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("could not create/patch the metrics Service: %v", err)
+	}
+	mc.logger.Info("Created the metrics Service", "name", svc.Name, "result", opRes)
+
+	sm := &unstructured.Unstructured{}
+	sm.SetGroupVersionKind(serviceMonitorGVK)
+	sm.SetName(MonitoringObjectsName)
+	sm.SetNamespace(mc.namespace)
+
+	opRes, err = controllerutil.CreateOrPatch(ctx, mc.client, sm, func() error {
+		if err := mc.setServiceMonitorSpec(sm); err != nil {
+			return err
+		}
+
+		// Deleting the Service also deletes the ServiceMonitor scraping it.
+		return controllerutil.SetControllerReference(svc, sm, mc.scheme)
+	})
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			mc.logger.Info("The ServiceMonitor API is not available; not creating a ServiceMonitor")
+			return nil
+		}
+
+		return fmt.Errorf("could not create/patch the ServiceMonitor: %v", err)
+	}
+	mc.logger.Info("Created the ServiceMonitor", "name", sm.GetName(), "result", opRes)
+
+	return nil
+}
+
+func (mc *MonitoringCreator) setServiceSpec(svc *v1.Service) {
+	svc.SetLabels(map[string]string{serviceNameLabel: MonitoringObjectsName})
+
+	svc.Spec.Selector = mc.podLabels
+	svc.Spec.Ports = []v1.ServicePort{
+		{
+			Name:       metricsPortName,
+			Port:       metricsPort,
+			Protocol:   v1.ProtocolTCP,
+			TargetPort: intstr.FromString(metricsPortName),
+		},
+	}
+}
+
+func (mc *MonitoringCreator) setServiceMonitorSpec(sm *unstructured.Unstructured) error {
+	sm.SetLabels(map[string]string{serviceNameLabel: MonitoringObjectsName})
+
+	endpoint := map[string]interface{}{
+		"path":            "/metrics",
+		"port":            metricsPortName,
+		"scheme":          "https",
+		"bearerTokenFile": serviceAccountTokenFile,
+		"tlsConfig": map[string]interface{}{
+			// The authenticating proxy serves a self-signed certificate.
+			"insecureSkipVerify": true,
+			"serverName":         fmt.Sprintf("%s.%s.svc", MonitoringObjectsName, mc.namespace),
+		},
+	}
+
+	selector := map[string]interface{}{serviceNameLabel: MonitoringObjectsName}
+
+	if err := unstructured.SetNestedSlice(sm.Object, []interface{}{endpoint}, "spec", "endpoints"); err != nil {
+		return fmt.Errorf("could not set the endpoints: %v", err)
+	}
+
+	if err := unstructured.SetNestedMap(sm.Object, selector, "spec", "selector", "matchLabels"); err != nil {
+		return fmt.Errorf("could not set the selector: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/kubernetes-sigs/kernel-module-management/internal/client"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/test"
+)
+
+var _ = Describe("MonitoringCreator_Start", func() {
+	const namespace = "kmm-operator-system"
+
+	var (
+		ctrl *gomock.Controller
+		clnt *client.MockClient
+		mc   *MonitoringCreator
+	)
+
+	ctx := context.Background()
+	podLabels := map[string]string{"control-plane": "controller-manager"}
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+
+		scheme, err := test.TestScheme()
+		Expect(err).NotTo(HaveOccurred())
+
+		mc = NewMonitoringCreator(clnt, scheme, namespace, podLabels, logr.Discard())
+	})
+
+	notFound := apierrors.NewNotFound(schema.GroupResource{}, MonitoringObjectsName)
+
+	It("should create the Service and the ServiceMonitor", func() {
+		gomock.InOrder(
+			clnt.EXPECT().Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(&v1.Service{})).Return(notFound),
+			clnt.EXPECT().Create(ctx, gomock.Any()).Do(func(_ context.Context, svc *v1.Service, _ ...interface{}) {
+				Expect(svc.Namespace).To(Equal(namespace))
+				Expect(svc.Spec.Selector).To(Equal(podLabels))
+				Expect(svc.Spec.Ports).To(HaveLen(1))
+				Expect(svc.Spec.Ports[0].Port).To(BeEquivalentTo(8443))
+				Expect(svc.Spec.Ports[0].TargetPort).To(Equal(intstr.FromString("https")))
+			}),
+			clnt.EXPECT().Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(&unstructured.Unstructured{})).Return(notFound),
+			clnt.EXPECT().Create(ctx, gomock.Any()).Do(func(_ context.Context, sm *unstructured.Unstructured, _ ...interface{}) {
+				Expect(sm.GetKind()).To(Equal("ServiceMonitor"))
+				Expect(sm.GetOwnerReferences()).To(HaveLen(1))
+				Expect(sm.GetOwnerReferences()[0].Kind).To(Equal("Service"))
+
+				selector, _, err := unstructured.NestedStringMap(sm.Object, "spec", "selector", "matchLabels")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(selector).To(Equal(map[string]string{"app.kubernetes.io/name": MonitoringObjectsName}))
+
+				serverName, _, err := unstructured.NestedString(
+					sm.Object["spec"].(map[string]interface{})["endpoints"].([]interface{})[0].(map[string]interface{}),
+					"tlsConfig",
+					"serverName",
+				)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(serverName).To(Equal(MonitoringObjectsName + ".kmm-operator-system.svc"))
+			}),
+		)
+
+		Expect(mc.Start(ctx)).To(Succeed())
+	})
+
+	It("should only create the Service if the ServiceMonitor API is not available", func() {
+		gomock.InOrder(
+			clnt.EXPECT().Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(&v1.Service{})).Return(notFound),
+			clnt.EXPECT().Create(ctx, gomock.AssignableToTypeOf(&v1.Service{})),
+			clnt.EXPECT().
+				Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(&unstructured.Unstructured{})).
+				Return(&meta.NoKindMatchError{GroupKind: serviceMonitorGVK.GroupKind()}),
+		)
+
+		Expect(mc.Start(ctx)).To(Succeed())
+	})
+
+	It("should return an error if the Service could not be created", func() {
+		gomock.InOrder(
+			clnt.EXPECT().Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(&v1.Service{})).Return(notFound),
+			clnt.EXPECT().Create(ctx, gomock.Any()).Return(errors.New("some error")),
+		)
+
+		Expect(mc.Start(ctx)).To(HaveOccurred())
+	})
+})
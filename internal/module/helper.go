@@ -5,7 +5,11 @@ import (
 	"fmt"
 	"strings"
 
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
@@ -47,6 +51,56 @@ func ShouldBeSigned(modSpec kmmv1beta1.ModuleSpec, km kmmv1beta1.KernelMapping)
 	return modSpec.ModuleLoader.Container.Sign != nil || km.Sign != nil
 }
 
+// KernelArchitecture returns the architecture reported by the kubelet of the nodes matching selector that run
+// kernelVersion, or an empty string if there are no such nodes or if they do not all share the same architecture.
+func KernelArchitecture(ctx context.Context, clnt client.Client, selector map[string]string, kernelVersion string) (string, error) {
+	nodes := v1.NodeList{}
+
+	if err := clnt.List(ctx, &nodes, client.MatchingLabels(selector)); err != nil {
+		return "", fmt.Errorf("could not list nodes: %v", err)
+	}
+
+	arch := ""
+
+	for _, n := range nodes.Items {
+		if strings.TrimSuffix(n.Status.NodeInfo.KernelVersion, "+") != kernelVersion {
+			continue
+		}
+
+		if arch != "" && n.Status.NodeInfo.Architecture != arch {
+			return "", nil
+		}
+
+		arch = n.Status.NodeInfo.Architecture
+	}
+
+	return arch, nil
+}
+
+// JobNodeSelector returns the node selector of the build and sign Jobs of a Module for targetKernel: the Module's
+// selector, restricted to the architecture of the nodes running targetKernel if it is known so that the Job runs on a
+// node that can execute the builder and the resulting images.
+func JobNodeSelector(ctx context.Context, clnt client.Client, selector map[string]string, targetKernel string) (map[string]string, error) {
+	arch, err := KernelArchitecture(ctx, clnt, selector, targetKernel)
+	if err != nil {
+		return nil, fmt.Errorf("could not determine the architecture of kernel %s: %v", targetKernel, err)
+	}
+
+	if arch == "" {
+		return selector, nil
+	}
+
+	ns := make(map[string]string, len(selector)+1)
+
+	for k, v := range selector {
+		ns[k] = v
+	}
+
+	ns[v1.LabelArchStable] = arch
+
+	return ns, nil
+}
+
 func ImageExists(
 	ctx context.Context,
 	client client.Client,
@@ -62,6 +116,8 @@ func ImageExists(
 			Name:      modSpec.ImageRepoSecret.Name,
 			Namespace: namespace,
 		})
+	} else if modSpec.WorkloadIdentity != nil {
+		registryAuthGetter = auth.NewModuleWorkloadIdentityAuthGetter(namespace)
 	}
 
 	tlsOptions := TLSOptions(modSpec, km)
@@ -72,3 +128,36 @@ func ImageExists(
 
 	return exists, nil
 }
+
+// NodeImages returns the module-loader and device-plugin images of the Modules of mods that run on node.
+// Modules whose kernel variables could not be substituted in their kernel mapping are skipped; the returned error
+// aggregates the reasons, and the images of the other Modules are still returned.
+func NodeImages(kernelAPI KernelMapper, node *v1.Node, mods []kmmv1beta1.Module) (sets.String, error) {
+	images := sets.NewString()
+	errs := make([]error, 0)
+
+	for _, mod := range mods {
+		if mod.DeletionTimestamp != nil || !labels.SelectorFromSet(mod.Spec.Selector).Matches(labels.Set(node.Labels)) {
+			continue
+		}
+
+		m, err := kernelAPI.FindMappingForNode(mod.Spec.ModuleLoader.Container.KernelMappings, node)
+		if err != nil {
+			continue
+		}
+
+		m, err = kernelAPI.PrepareKernelMapping(m, kernelAPI.GetNodeOSConfig(node))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("module %s/%s: %v", mod.Namespace, mod.Name, err))
+			continue
+		}
+
+		images.Insert(m.ContainerImage)
+
+		if dp := mod.Spec.DevicePlugin; dp != nil {
+			images.Insert(dp.Container.Image)
+		}
+	}
+
+	return images, utilerrors.NewAggregate(errs)
+}
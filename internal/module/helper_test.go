@@ -9,12 +9,112 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	v1 "k8s.io/api/core/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/client"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/registry"
 )
 
+var _ = Describe("KernelArchitecture", func() {
+	const kernelVersion = "5.19.0-1022-aws"
+
+	var (
+		ctrl *gomock.Controller
+		clnt *client.MockClient
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+	})
+
+	node := func(kernelVersion, arch string) v1.Node {
+		return v1.Node{
+			Status: v1.NodeStatus{
+				NodeInfo: v1.NodeSystemInfo{KernelVersion: kernelVersion, Architecture: arch},
+			},
+		}
+	}
+
+	listNodes := func(nodes ...v1.Node) {
+		clnt.
+			EXPECT().
+			List(context.Background(), &v1.NodeList{}, ctrlclient.MatchingLabels{"zone": "z1"}).
+			DoAndReturn(func(_ interface{}, nl *v1.NodeList, _ ...ctrlclient.ListOption) error {
+				nl.Items = nodes
+				return nil
+			})
+	}
+
+	It("should return the architecture of the nodes running the kernel", func() {
+		listNodes(node(kernelVersion+"+", "arm64"), node("6.1.0", "amd64"))
+
+		arch, err := KernelArchitecture(context.Background(), clnt, map[string]string{"zone": "z1"}, kernelVersion)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(arch).To(Equal("arm64"))
+	})
+
+	It("should return an empty string if the nodes running the kernel have different architectures", func() {
+		listNodes(node(kernelVersion, "arm64"), node(kernelVersion, "amd64"))
+
+		arch, err := KernelArchitecture(context.Background(), clnt, map[string]string{"zone": "z1"}, kernelVersion)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(arch).To(BeEmpty())
+	})
+
+	It("should return an error if the nodes cannot be listed", func() {
+		clnt.EXPECT().List(context.Background(), &v1.NodeList{}, gomock.Any()).Return(errors.New("some error"))
+
+		_, err := KernelArchitecture(context.Background(), clnt, map[string]string{"zone": "z1"}, kernelVersion)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("JobNodeSelector", func() {
+	const kernelVersion = "4.18.0-372.el8.ppc64le"
+
+	var (
+		ctrl *gomock.Controller
+		clnt *client.MockClient
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+	})
+
+	selector := map[string]string{"zone": "z1"}
+
+	It("should return the selector if the architecture is unknown", func() {
+		clnt.EXPECT().List(context.Background(), &v1.NodeList{}, gomock.Any())
+
+		Expect(JobNodeSelector(context.Background(), clnt, selector, kernelVersion)).To(Equal(selector))
+	})
+
+	It("should add the architecture without modifying the selector", func() {
+		clnt.
+			EXPECT().
+			List(context.Background(), &v1.NodeList{}, gomock.Any()).
+			DoAndReturn(func(_ interface{}, nl *v1.NodeList, _ ...ctrlclient.ListOption) error {
+				nl.Items = []v1.Node{
+					{
+						Status: v1.NodeStatus{
+							NodeInfo: v1.NodeSystemInfo{KernelVersion: kernelVersion, Architecture: "ppc64le"},
+						},
+					},
+				}
+				return nil
+			})
+
+		Expect(JobNodeSelector(context.Background(), clnt, selector, kernelVersion)).To(Equal(map[string]string{
+			"zone":             "z1",
+			v1.LabelArchStable: "ppc64le",
+		}))
+		Expect(selector).To(HaveLen(1))
+	})
+})
+
 var _ = Describe("TLSOptions", func() {
 	It("should return the KernelMapping's TLSOptions if it's defined", func() {
 		mod := kmmv1beta1.Module{
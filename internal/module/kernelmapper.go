@@ -10,6 +10,7 @@ import (
 	"github.com/a8m/envsubst/parse"
 	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 const (
@@ -24,39 +25,107 @@ type NodeOSConfig struct {
 	KernelVersionMajor string `subst:"KERNEL_X"`
 	KernelVersionMinor string `subst:"KERNEL_Y"`
 	KernelVersionPatch string `subst:"KERNEL_Z"`
+	KernelPageSize     string `subst:"KERNEL_PAGE_SIZE"`
+}
+
+// pageSizeSuffixRegexp matches the suffixes that distributions append to the version of the kernels that do not use
+// 4k pages, e.g. 5.14.0-284.11.1.el9_2.aarch64+64k on RHEL or 6.8.0-1008-nvidia-64k on Ubuntu.
+var pageSizeSuffixRegexp = regexp.MustCompile(`[+-](16k|64k)$`)
+
+// KernelPageSize returns the page size of kernelVersion, inferred from its suffix.
+func KernelPageSize(kernelVersion string) kmmv1beta1.KernelPageSize {
+	m := pageSizeSuffixRegexp.FindStringSubmatch(strings.TrimSuffix(kernelVersion, "+"))
+	if m == nil {
+		return kmmv1beta1.KernelPageSize4k
+	}
+
+	return kmmv1beta1.KernelPageSize(m[1])
 }
 
 //go:generate mockgen -source=kernelmapper.go -package=module -destination=mock_kernelmapper.go
 
 type KernelMapper interface {
 	FindMappingForKernel(mappings []kmmv1beta1.KernelMapping, kernelVersion string) (*kmmv1beta1.KernelMapping, error)
+	FindMappingForNode(mappings []kmmv1beta1.KernelMapping, node *v1.Node) (*kmmv1beta1.KernelMapping, error)
 	GetNodeOSConfig(node *v1.Node) *NodeOSConfig
 	GetNodeOSConfigFromKernelVersion(kernelVersion string) *NodeOSConfig
 	PrepareKernelMapping(mapping *kmmv1beta1.KernelMapping, osConfig *NodeOSConfig) (*kmmv1beta1.KernelMapping, error)
 }
 
-type kernelMapper struct{}
+type kernelMapper struct {
+	rules []KernelNormalizationRule
+}
 
 func NewKernelMapper() KernelMapper {
 	return &kernelMapper{}
 }
 
+// NewNormalizingKernelMapper returns a KernelMapper that also matches mappings against the kernel versions normalized
+// by the first of rules that applies to them.
+func NewNormalizingKernelMapper(rules []KernelNormalizationRule) KernelMapper {
+	return &kernelMapper{rules: rules}
+}
+
 // FindMappingForKernel tries to match kernelVersion against mappings. It returns the first mapping that has a Literal
 // field equal to kernelVersion or a Regexp field that matches kernelVersion.
+// The NodeSelector of mappings is ignored, as there is no node to match it against.
 func (k *kernelMapper) FindMappingForKernel(mappings []kmmv1beta1.KernelMapping, kernelVersion string) (*kmmv1beta1.KernelMapping, error) {
+	return findMapping(mappings, k.kernelVersions(kernelVersion), nil)
+}
+
+// FindMappingForNode returns the first mapping that matches the kernel of node, like FindMappingForKernel, and whose
+// NodeSelector matches the labels of node.
+func (k *kernelMapper) FindMappingForNode(mappings []kmmv1beta1.KernelMapping, node *v1.Node) (*kmmv1beta1.KernelMapping, error) {
+	kernelVersion := strings.TrimSuffix(node.Status.NodeInfo.KernelVersion, "+")
+
+	// A node without labels must not skip the node selectors.
+	nodeLabels := labels.Set(node.Labels)
+	if nodeLabels == nil {
+		nodeLabels = labels.Set{}
+	}
+
+	return findMapping(mappings, k.kernelVersions(kernelVersion), nodeLabels)
+}
+
+// kernelVersions returns kernelVersion followed, if a normalization rule applies to it, by its normalized version.
+func (k *kernelMapper) kernelVersions(kernelVersion string) []string {
+	for i := range k.rules {
+		if normalized, ok := k.rules[i].normalize(kernelVersion); ok && normalized != kernelVersion {
+			return []string{kernelVersion, normalized}
+		}
+	}
+
+	return []string{kernelVersion}
+}
+
+// findMapping returns the first mapping that matches one of kernelVersions and, if it sets one, the page size of the
+// first one and, if nodeLabels is not nil, whose NodeSelector is a subset of nodeLabels.
+func findMapping(mappings []kmmv1beta1.KernelMapping, kernelVersions []string, nodeLabels labels.Set) (*kmmv1beta1.KernelMapping, error) {
+	pageSize := KernelPageSize(kernelVersions[0])
+
 	for _, m := range mappings {
-		if m.Literal != "" && m.Literal == kernelVersion {
-			return &m, nil
+		if nodeLabels != nil && !labels.SelectorFromSet(m.NodeSelector).Matches(nodeLabels) {
+			continue
 		}
 
-		if m.Regexp == "" {
+		if m.PageSize != "" && m.PageSize != pageSize {
 			continue
 		}
 
-		if matches, err := regexp.MatchString(m.Regexp, kernelVersion); err != nil {
-			return nil, fmt.Errorf("could not match regexp %q against kernel %q: %v", m.Regexp, kernelVersion, err)
-		} else if matches {
-			return &m, nil
+		for _, kernelVersion := range kernelVersions {
+			if m.Literal != "" && m.Literal == kernelVersion {
+				return &m, nil
+			}
+
+			if m.Regexp == "" {
+				continue
+			}
+
+			if matches, err := regexp.MatchString(m.Regexp, kernelVersion); err != nil {
+				return nil, fmt.Errorf("could not match regexp %q against kernel %q: %v", m.Regexp, kernelVersion, err)
+			} else if matches {
+				return &m, nil
+			}
 		}
 	}
 
@@ -77,6 +146,7 @@ func (k *kernelMapper) GetNodeOSConfigFromKernelVersion(kernelVersion string) *N
 	osConfig.KernelVersionMajor = osConfigFieldsList[kernelVersionMajorIdx]
 	osConfig.KernelVersionMinor = osConfigFieldsList[kernelVersionMinorIdx]
 	osConfig.KernelVersionPatch = osConfigFieldsList[kernelVersionPatchIdx]
+	osConfig.KernelPageSize = string(KernelPageSize(kernelVersion))
 
 	return &osConfig
 }
@@ -5,6 +5,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 var _ = Describe("FindMappingForKernel", func() {
@@ -64,6 +65,145 @@ var _ = Describe("FindMappingForKernel", func() {
 	})
 })
 
+var _ = Describe("FindMappingForKernel_normalization", func() {
+	var km KernelMapper
+
+	BeforeEach(func() {
+		rules, err := ParseKernelNormalizationRules([]byte(`
+- name: aks
+  regexp: '^(\d+\.\d+\.\d+)-\d+-azure$'
+  replacement: '${1}-aks'
+`))
+		Expect(err).NotTo(HaveOccurred())
+
+		km = NewNormalizingKernelMapper(rules)
+	})
+
+	It("should match mappings against the normalized kernel version", func() {
+		mapping := kmmv1beta1.KernelMapping{ContainerImage: "image-aks", Regexp: `^5\.15\.\d+-aks$`}
+
+		m, err := km.FindMappingForKernel([]kmmv1beta1.KernelMapping{mapping}, "5.15.0-1057-azure")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m).To(Equal(&mapping))
+	})
+
+	It("should still match mappings against the original kernel version", func() {
+		mapping := kmmv1beta1.KernelMapping{ContainerImage: "image", Literal: "5.15.0-1057-azure"}
+
+		m, err := km.FindMappingForKernel([]kmmv1beta1.KernelMapping{mapping}, "5.15.0-1057-azure")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m).To(Equal(&mapping))
+	})
+
+	It("should not normalize kernels that no rule matches", func() {
+		mapping := kmmv1beta1.KernelMapping{ContainerImage: "image-aks", Regexp: `-aks$`}
+
+		_, err := km.FindMappingForKernel([]kmmv1beta1.KernelMapping{mapping}, "5.15.0-1049-gke")
+		Expect(err).To(MatchError("no suitable mapping found"))
+	})
+})
+
+var _ = Describe("FindMappingForKernel_pageSize", func() {
+	km := NewKernelMapper()
+
+	mappings := []kmmv1beta1.KernelMapping{
+		{
+			ContainerImage: "image-4k",
+			Regexp:         `^.+\.aarch64`,
+			PageSize:       kmmv1beta1.KernelPageSize4k,
+		},
+		{
+			ContainerImage: "image-64k",
+			Regexp:         `^.+\.aarch64`,
+			PageSize:       kmmv1beta1.KernelPageSize64k,
+		},
+	}
+
+	It("should select the mapping of a 4k kernel", func() {
+		m, err := km.FindMappingForKernel(mappings, "5.14.0-284.11.1.el9_2.aarch64")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m.ContainerImage).To(Equal("image-4k"))
+	})
+
+	It("should select the mapping of a 64k kernel", func() {
+		m, err := km.FindMappingForKernel(mappings, "5.14.0-284.11.1.el9_2.aarch64+64k")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m.ContainerImage).To(Equal("image-64k"))
+	})
+
+	It("should not select a mapping of another page size", func() {
+		_, err := km.FindMappingForKernel(mappings[:1], "5.14.0-284.11.1.el9_2.aarch64+64k")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should select a mapping without a page size for kernels of all page sizes", func() {
+		mapping := kmmv1beta1.KernelMapping{ContainerImage: "image", Regexp: `^.+\.aarch64`}
+
+		for _, kernelVersion := range []string{"5.14.0-284.11.1.el9_2.aarch64", "5.14.0-284.11.1.el9_2.aarch64+64k"} {
+			m, err := km.FindMappingForKernel([]kmmv1beta1.KernelMapping{mapping}, kernelVersion)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m.ContainerImage).To(Equal("image"))
+		}
+	})
+})
+
+var _ = DescribeTable("KernelPageSize",
+	func(kernelVersion string, expected kmmv1beta1.KernelPageSize) {
+		Expect(KernelPageSize(kernelVersion)).To(Equal(expected))
+	},
+	Entry("no suffix", "5.14.0-284.11.1.el9_2.aarch64", kmmv1beta1.KernelPageSize4k),
+	Entry("RHEL 64k", "5.14.0-284.11.1.el9_2.aarch64+64k", kmmv1beta1.KernelPageSize64k),
+	Entry("Ubuntu 64k", "6.8.0-1008-nvidia-64k", kmmv1beta1.KernelPageSize64k),
+	Entry("16k", "6.5.0-asahi-16k", kmmv1beta1.KernelPageSize16k),
+	Entry("trailing +", "5.14.0-284.11.1.el9_2.aarch64+64k+", kmmv1beta1.KernelPageSize64k),
+)
+
+var _ = Describe("FindMappingForNode", func() {
+	km := NewKernelMapper()
+
+	mappings := []kmmv1beta1.KernelMapping{
+		{
+			ContainerImage: "nvidia-image",
+			Regexp:         `^1\..*$`,
+			NodeSelector:   map[string]string{"feature.node.kubernetes.io/pci-10de.present": "true"},
+		},
+		{
+			ContainerImage: "default-image",
+			Regexp:         `^1\..*$`,
+		},
+	}
+
+	node := func(kernelVersion string, labels map[string]string) *v1.Node {
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Labels: labels},
+			Status:     v1.NodeStatus{NodeInfo: v1.NodeSystemInfo{KernelVersion: kernelVersion}},
+		}
+	}
+
+	It("should return the first mapping whose node selector matches the labels of the node", func() {
+		m, err := km.FindMappingForNode(
+			mappings,
+			node("1.2.3+", map[string]string{"feature.node.kubernetes.io/pci-10de.present": "true"}),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m.ContainerImage).To(Equal("nvidia-image"))
+	})
+
+	It("should skip the mappings whose node selector does not match the labels of the node", func() {
+		m, err := km.FindMappingForNode(mappings, node("1.2.3", nil))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m.ContainerImage).To(Equal("default-image"))
+	})
+
+	It("should return an error if no mapping matches the kernel of the node", func() {
+		_, err := km.FindMappingForNode(
+			mappings,
+			node("2.0.0", map[string]string{"feature.node.kubernetes.io/pci-10de.present": "true"}),
+		)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
 var _ = Describe("PrepareKernelMapping", func() {
 	km := NewKernelMapper()
 	osConfig := NodeOSConfig{
@@ -141,6 +281,7 @@ var _ = Describe("GetNodeOSConfig", func() {
 			KernelVersionMajor: "4",
 			KernelVersionMinor: "18",
 			KernelVersionPatch: "0",
+			KernelPageSize:     "4k",
 		}
 
 		res := km.GetNodeOSConfig(&node)
@@ -160,6 +301,7 @@ var _ = Describe("GetNodeOSConfigFromKernelVersion", func() {
 			KernelVersionMajor: "4",
 			KernelVersionMinor: "18",
 			KernelVersionPatch: "0",
+			KernelPageSize:     "4k",
 		}
 
 		res := km.GetNodeOSConfigFromKernelVersion(kernelVersion)
@@ -50,6 +50,21 @@ func (mr *MockKernelMapperMockRecorder) FindMappingForKernel(mappings, kernelVer
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindMappingForKernel", reflect.TypeOf((*MockKernelMapper)(nil).FindMappingForKernel), mappings, kernelVersion)
 }
 
+// FindMappingForNode mocks base method.
+func (m *MockKernelMapper) FindMappingForNode(mappings []v1beta1.KernelMapping, node *v1.Node) (*v1beta1.KernelMapping, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindMappingForNode", mappings, node)
+	ret0, _ := ret[0].(*v1beta1.KernelMapping)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindMappingForNode indicates an expected call of FindMappingForNode.
+func (mr *MockKernelMapperMockRecorder) FindMappingForNode(mappings, node interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindMappingForNode", reflect.TypeOf((*MockKernelMapper)(nil).FindMappingForNode), mappings, node)
+}
+
 // GetNodeOSConfig mocks base method.
 func (m *MockKernelMapper) GetNodeOSConfig(node *v1.Node) *NodeOSConfig {
 	m.ctrl.T.Helper()
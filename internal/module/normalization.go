@@ -0,0 +1,68 @@
+package module
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"sigs.k8s.io/yaml"
+)
+
+// KernelNormalizationRule rewrites the kernel versions that its regular expression matches, typically the versions of
+// managed cloud kernels, into a normalized version that kernel mappings can match in addition to the original one.
+type KernelNormalizationRule struct {
+	// Name identifies the rule in errors.
+	Name string `json:"name"`
+	// Regexp is the regular expression that kernel versions must match for the rule to apply.
+	Regexp string `json:"regexp"`
+	// Replacement is the normalized version, in which $1 or ${name} are replaced with the submatches of Regexp.
+	Replacement string `json:"replacement"`
+
+	re *regexp.Regexp
+}
+
+// normalize returns the normalized kernelVersion and true if the rule applies to it.
+func (r *KernelNormalizationRule) normalize(kernelVersion string) (string, bool) {
+	m := r.re.FindStringSubmatchIndex(kernelVersion)
+	if m == nil {
+		return "", false
+	}
+
+	return string(r.re.ExpandString(nil, r.Replacement, kernelVersion, m)), true
+}
+
+// ParseKernelNormalizationRules parses the YAML or JSON list of rules in b and compiles their regular expressions.
+func ParseKernelNormalizationRules(b []byte) ([]KernelNormalizationRule, error) {
+	rules := make([]KernelNormalizationRule, 0)
+
+	if err := yaml.UnmarshalStrict(b, &rules); err != nil {
+		return nil, fmt.Errorf("could not unmarshal the rules: %v", err)
+	}
+
+	for i := range rules {
+		r := &rules[i]
+
+		if r.Regexp == "" || r.Replacement == "" {
+			return nil, fmt.Errorf("rule %d (%q): regexp and replacement cannot be empty", i, r.Name)
+		}
+
+		re, err := regexp.Compile(r.Regexp)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d (%q): invalid regexp: %v", i, r.Name, err)
+		}
+
+		r.re = re
+	}
+
+	return rules, nil
+}
+
+// LoadKernelNormalizationRules reads the rules in the file at path; see ParseKernelNormalizationRules.
+func LoadKernelNormalizationRules(path string) ([]KernelNormalizationRule, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %v", path, err)
+	}
+
+	return ParseKernelNormalizationRules(b)
+}
@@ -0,0 +1,58 @@
+package module
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseKernelNormalizationRules", func() {
+	It("should parse and compile the rules", func() {
+		rules, err := ParseKernelNormalizationRules([]byte(`
+- name: eks
+  regexp: '^(\d+\.\d+\.\d+)-[\d.]+\.amzn2\.x86_64$'
+  replacement: '${1}-eks'
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rules).To(HaveLen(1))
+
+		normalized, ok := rules[0].normalize("5.10.210-201.852.amzn2.x86_64")
+		Expect(ok).To(BeTrue())
+		Expect(normalized).To(Equal("5.10.210-eks"))
+
+		_, ok = rules[0].normalize("5.15.0-1057-azure")
+		Expect(ok).To(BeFalse())
+	})
+
+	DescribeTable("should return an error for invalid rules",
+		func(data string) {
+			_, err := ParseKernelNormalizationRules([]byte(data))
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("not a list", "name: eks"),
+		Entry("unknown field", "- name: eks\n  regex: '^.+$'\n  replacement: eks"),
+		Entry("empty replacement", "- name: eks\n  regexp: '^.+$'"),
+		Entry("invalid regexp", "- name: eks\n  regexp: 'invalid)'\n  replacement: eks"),
+	)
+})
+
+var _ = Describe("LoadKernelNormalizationRules", func() {
+	It("should read the rules from a file", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "rules.yaml")
+		Expect(
+			os.WriteFile(path, []byte("- name: aks\n  regexp: '^(\\d+\\.\\d+\\.\\d+)-\\d+-azure$'\n  replacement: '${1}-aks'\n"), 0600),
+		).To(Succeed())
+
+		rules, err := LoadKernelNormalizationRules(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rules).To(HaveLen(1))
+		Expect(rules[0].Name).To(Equal("aks"))
+	})
+
+	It("should return an error if the file does not exist", func() {
+		_, err := LoadKernelNormalizationRules("/non/existent")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,161 @@
+package networkpolicy
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/utils"
+)
+
+// dnsPort is always allowed, so that build and sign pods can resolve the registry's hostname.
+const dnsPort = 53
+
+//go:generate mockgen -source=networkpolicy.go -package=networkpolicy -destination=mock_networkpolicy.go
+
+type NetworkPolicyCreator interface {
+	CreateBuildSignNetworkPolicy(ctx context.Context, mod kmmv1beta1.Module) error
+	DeleteBuildSignNetworkPolicy(ctx context.Context, mod kmmv1beta1.Module) error
+}
+
+type networkPolicyCreator struct {
+	client      client.Client
+	scheme      *runtime.Scheme
+	egressPorts []int32
+}
+
+// NewCreator returns a NetworkPolicyCreator that only allows egress traffic from build and sign pods to DNS and to
+// egressPorts over TCP, typically those of image registries and proxies.
+func NewCreator(client client.Client, scheme *runtime.Scheme, egressPorts []int32) NetworkPolicyCreator {
+	return &networkPolicyCreator{
+		client:      client,
+		scheme:      scheme,
+		egressPorts: egressPorts,
+	}
+}
+
+func (nc *networkPolicyCreator) CreateBuildSignNetworkPolicy(ctx context.Context, mod kmmv1beta1.Module) error {
+	logger := log.FromContext(ctx)
+
+	np := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GenerateBuildSignNetworkPolicyName(mod),
+			Namespace: mod.Namespace,
+		},
+	}
+
+	opRes, err := controllerutil.CreateOrPatch(ctx, nc.client, np, func() error {
+		np.Spec = nc.buildSignNetworkPolicySpec(mod)
+
+		return controllerutil.SetControllerReference(&mod, np, nc.scheme)
+	})
+	if err != nil {
+		return fmt.Errorf("could not create/patch NetworkPolicy: %w", err)
+	}
+	logger.Info("Created build and sign NetworkPolicy", "name", np.Name, "result", opRes)
+
+	return nil
+}
+
+// DeleteBuildSignNetworkPolicy deletes the build and sign NetworkPolicy of mod if it exists, typically once mod does
+// not build nor sign anymore.
+func (nc *networkPolicyCreator) DeleteBuildSignNetworkPolicy(ctx context.Context, mod kmmv1beta1.Module) error {
+	logger := log.FromContext(ctx)
+
+	np := networkingv1.NetworkPolicy{}
+	nsn := types.NamespacedName{Name: GenerateBuildSignNetworkPolicyName(mod), Namespace: mod.Namespace}
+
+	if err := nc.client.Get(ctx, nsn, &np); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+
+		return fmt.Errorf("could not get NetworkPolicy %s: %v", nsn, err)
+	}
+
+	if err := nc.client.Delete(ctx, &np); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("could not delete NetworkPolicy %s: %v", nsn, err)
+	}
+	logger.Info("Deleted build and sign NetworkPolicy", "name", np.Name)
+
+	return nil
+}
+
+func (nc *networkPolicyCreator) buildSignNetworkPolicySpec(mod kmmv1beta1.Module) networkingv1.NetworkPolicySpec {
+	tcp := v1.ProtocolTCP
+	udp := v1.ProtocolUDP
+
+	dns := intstr.FromInt(dnsPort)
+
+	ports := []networkingv1.NetworkPolicyPort{
+		{Protocol: &udp, Port: &dns},
+		{Protocol: &tcp, Port: &dns},
+	}
+
+	for _, p := range nc.egressPorts {
+		port := intstr.FromInt(int(p))
+		ports = append(ports, networkingv1.NetworkPolicyPort{Protocol: &tcp, Port: &port})
+	}
+
+	return networkingv1.NetworkPolicySpec{
+		PodSelector: metav1.LabelSelector{
+			MatchLabels: map[string]string{constants.ModuleNameLabel: mod.Name},
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{
+					Key:      constants.JobType,
+					Operator: metav1.LabelSelectorOpIn,
+					Values:   []string{utils.JobTypeBuild, utils.JobTypeSign},
+				},
+			},
+		},
+		// No ingress rule: build and sign pods do not accept any incoming connection.
+		Egress: []networkingv1.NetworkPolicyEgressRule{
+			{Ports: ports},
+		},
+		PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+	}
+}
+
+func GenerateBuildSignNetworkPolicyName(mod kmmv1beta1.Module) string {
+	return mod.Name + "-build-sign"
+}
+
+// ParsePorts parses a comma-separated list of TCP ports.
+func ParsePorts(s string) ([]int32, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	elems := strings.Split(s, ",")
+
+	ports := make([]int32, 0, len(elems))
+
+	for _, e := range elems {
+		p, err := strconv.ParseInt(strings.TrimSpace(e), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%q: invalid port: %v", e, err)
+		}
+
+		if p < 1 || p > 65535 {
+			return nil, fmt.Errorf("%d: port out of range", p)
+		}
+
+		ports = append(ports, int32(p))
+	}
+
+	return ports, nil
+}
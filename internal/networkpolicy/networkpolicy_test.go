@@ -0,0 +1,230 @@
+package networkpolicy
+
+import (
+	"context"
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/client"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+)
+
+var _ = Describe("CreateBuildSignNetworkPolicy", func() {
+	const (
+		moduleName = "test-module"
+		namespace  = "namespace"
+	)
+
+	var (
+		ctrl *gomock.Controller
+		clnt *client.MockClient
+		nc   NetworkPolicyCreator
+
+		mod kmmv1beta1.Module
+
+		requestedNetworkPolicy *networkingv1.NetworkPolicy
+		expectedNetworkPolicy  *networkingv1.NetworkPolicy
+	)
+
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+		nc = NewCreator(clnt, scheme, []int32{443})
+
+		mod = kmmv1beta1.Module{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: kmmv1beta1.GroupVersion.String(),
+				Kind:       "Module",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      moduleName,
+				Namespace: namespace,
+			},
+		}
+
+		requestedNetworkPolicy = &networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      moduleName + "-build-sign",
+				Namespace: namespace,
+			},
+		}
+
+		tcp := v1.ProtocolTCP
+		udp := v1.ProtocolUDP
+		dns := intstr.FromInt(53)
+		https := intstr.FromInt(443)
+
+		expectedNetworkPolicy = &networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      moduleName + "-build-sign",
+				Namespace: namespace,
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion:         mod.APIVersion,
+						BlockOwnerDeletion: pointer.Bool(true),
+						Controller:         pointer.Bool(true),
+						Kind:               mod.Kind,
+						Name:               moduleName,
+						UID:                mod.UID,
+					},
+				},
+			},
+			Spec: networkingv1.NetworkPolicySpec{
+				PodSelector: metav1.LabelSelector{
+					MatchLabels: map[string]string{constants.ModuleNameLabel: moduleName},
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{
+							Key:      constants.JobType,
+							Operator: metav1.LabelSelectorOpIn,
+							Values:   []string{"build", "sign"},
+						},
+					},
+				},
+				Egress: []networkingv1.NetworkPolicyEgressRule{
+					{
+						Ports: []networkingv1.NetworkPolicyPort{
+							{Protocol: &udp, Port: &dns},
+							{Protocol: &tcp, Port: &dns},
+							{Protocol: &tcp, Port: &https},
+						},
+					},
+				},
+				PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+			},
+		}
+	})
+
+	It("should create the NetworkPolicy", func() {
+		gomock.InOrder(
+			clnt.EXPECT().Get(ctx, gomock.Any(), requestedNetworkPolicy).Return(apierrors.NewNotFound(schema.GroupResource{}, "whatever")),
+			clnt.EXPECT().Create(ctx, expectedNetworkPolicy).Return(nil),
+		)
+
+		err := nc.CreateBuildSignNetworkPolicy(ctx, mod)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should return an error when the NetworkPolicy fetch fails", func() {
+		clnt.EXPECT().Get(ctx, gomock.Any(), requestedNetworkPolicy).Return(errors.New("some-error"))
+
+		err := nc.CreateBuildSignNetworkPolicy(ctx, mod)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return an error when the NetworkPolicy creation fails", func() {
+		gomock.InOrder(
+			clnt.EXPECT().Get(ctx, gomock.Any(), requestedNetworkPolicy).Return(apierrors.NewNotFound(schema.GroupResource{}, "whatever")),
+			clnt.EXPECT().Create(ctx, expectedNetworkPolicy).Return(errors.New("some-error")),
+		)
+
+		err := nc.CreateBuildSignNetworkPolicy(ctx, mod)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("DeleteBuildSignNetworkPolicy", func() {
+	var (
+		ctrl *gomock.Controller
+		clnt *client.MockClient
+		nc   NetworkPolicyCreator
+	)
+
+	ctx := context.Background()
+
+	mod := kmmv1beta1.Module{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-module",
+			Namespace: "namespace",
+		},
+	}
+
+	nsn := types.NamespacedName{Name: "test-module-build-sign", Namespace: "namespace"}
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+		nc = NewCreator(clnt, scheme, nil)
+	})
+
+	It("should do nothing if the NetworkPolicy does not exist", func() {
+		clnt.EXPECT().Get(ctx, nsn, &networkingv1.NetworkPolicy{}).Return(apierrors.NewNotFound(schema.GroupResource{}, "whatever"))
+
+		Expect(
+			nc.DeleteBuildSignNetworkPolicy(ctx, mod),
+		).NotTo(
+			HaveOccurred(),
+		)
+	})
+
+	It("should delete the NetworkPolicy", func() {
+		gomock.InOrder(
+			clnt.EXPECT().Get(ctx, nsn, &networkingv1.NetworkPolicy{}).DoAndReturn(
+				func(_ interface{}, _ interface{}, np *networkingv1.NetworkPolicy, _ ...ctrlclient.GetOption) error {
+					np.Name = nsn.Name
+					np.Namespace = nsn.Namespace
+					return nil
+				},
+			),
+			clnt.EXPECT().Delete(ctx, &networkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: nsn.Name, Namespace: nsn.Namespace},
+			}),
+		)
+
+		Expect(
+			nc.DeleteBuildSignNetworkPolicy(ctx, mod),
+		).NotTo(
+			HaveOccurred(),
+		)
+	})
+
+	It("should return an error if the NetworkPolicy cannot be deleted", func() {
+		gomock.InOrder(
+			clnt.EXPECT().Get(ctx, nsn, &networkingv1.NetworkPolicy{}),
+			clnt.EXPECT().Delete(ctx, gomock.Any()).Return(errors.New("some error")),
+		)
+
+		Expect(
+			nc.DeleteBuildSignNetworkPolicy(ctx, mod),
+		).To(
+			HaveOccurred(),
+		)
+	})
+})
+
+var _ = Describe("ParsePorts", func() {
+	DescribeTable("should parse valid lists",
+		func(s string, expected []int32) {
+			Expect(ParsePorts(s)).To(Equal(expected))
+		},
+		Entry("empty", "", nil),
+		Entry("single port", "443", []int32{443}),
+		Entry("multiple ports", "80, 443,5000", []int32{80, 443, 5000}),
+	)
+
+	DescribeTable("should reject invalid lists",
+		func(s string) {
+			_, err := ParsePorts(s)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("not a number", "https"),
+		Entry("zero", "0"),
+		Entry("too large", "65536"),
+		Entry("trailing comma", "443,"),
+	)
+})
@@ -0,0 +1,141 @@
+package nodepool
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+)
+
+// poolLabels are the node labels naming the node pool of a node, by order of precedence.
+var poolLabels = []string{
+	"karpenter.sh/nodepool",
+	"karpenter.sh/provisioner-name",
+	"eks.amazonaws.com/nodegroup",
+	"cloud.google.com/gke-nodepool",
+	"kubernetes.azure.com/agentpool",
+}
+
+//+kubebuilder:rbac:groups="core",resources=configmaps,verbs=create;get;patch
+
+// PoolName returns the name of the node pool of node, or an empty string if node does not belong to a known pool.
+func PoolName(node *v1.Node) string {
+	for _, l := range poolLabels {
+		if name := node.Labels[l]; name != "" {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// ImagePublisher is a manager.Runnable that periodically publishes the module-loader and device-plugin images run on
+// the nodes of each node pool in a ConfigMap, with one key per pool listing one image per line.
+// Node bootstrap tooling can pre-pull them on new nodes of a pool, which then load their modules sooner.
+type ImagePublisher struct {
+	client    client.Client
+	kernelAPI module.KernelMapper
+	name      types.NamespacedName
+	period    time.Duration
+	logger    logr.Logger
+}
+
+func NewImagePublisher(
+	client client.Client,
+	kernelAPI module.KernelMapper,
+	name types.NamespacedName,
+	period time.Duration,
+	logger logr.Logger,
+) *ImagePublisher {
+	return &ImagePublisher{
+		client:    client,
+		kernelAPI: kernelAPI,
+		name:      name,
+		period:    period,
+		logger:    logger,
+	}
+}
+
+// Start publishes the images every period until ctx is done.
+func (ip *ImagePublisher) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, ip.publish, ip.period)
+	return nil
+}
+
+func (ip *ImagePublisher) publish(ctx context.Context) {
+	images, err := ip.poolImages(ctx)
+	if err != nil {
+		ip.logger.Error(err, "failed to compute the images of node pools")
+		return
+	}
+
+	data := make(map[string]string, len(images))
+
+	for pool, poolImages := range images {
+		data[pool] = ""
+
+		for _, img := range poolImages.List() {
+			data[pool] += img + "\n"
+		}
+	}
+
+	cm := &v1.ConfigMap{}
+	cm.Name = ip.name.Name
+	cm.Namespace = ip.name.Namespace
+
+	opRes, err := controllerutil.CreateOrPatch(ctx, ip.client, cm, func() error {
+		cm.Data = data
+		return nil
+	})
+	if err != nil {
+		ip.logger.Error(err, "failed to publish the images of node pools", "configmap", ip.name)
+		return
+	}
+
+	ip.logger.V(1).Info("Published the images of node pools", "configmap", ip.name, "result", opRes)
+}
+
+// poolImages returns, for each node pool, the images of the Modules running on its nodes.
+func (ip *ImagePublisher) poolImages(ctx context.Context) (map[string]sets.String, error) {
+	nodes := v1.NodeList{}
+
+	if err := ip.client.List(ctx, &nodes); err != nil {
+		return nil, fmt.Errorf("could not list nodes: %v", err)
+	}
+
+	mods := kmmv1beta1.ModuleList{}
+
+	if err := ip.client.List(ctx, &mods); err != nil {
+		return nil, fmt.Errorf("could not list Modules: %v", err)
+	}
+
+	images := make(map[string]sets.String)
+
+	for i := 0; i < len(nodes.Items); i++ {
+		node := &nodes.Items[i]
+
+		pool := PoolName(node)
+		if pool == "" {
+			continue
+		}
+
+		nodeImages, err := module.NodeImages(ip.kernelAPI, node, mods.Items)
+		if err != nil {
+			ip.logger.Info("Could not substitute the kernel variables", "node", node.Name, "error", err)
+		}
+
+		images[pool] = images[pool].Union(nodeImages)
+	}
+
+	return images, nil
+}
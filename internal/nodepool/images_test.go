@@ -0,0 +1,125 @@
+package nodepool
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/client"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+)
+
+var _ = Describe("PoolName", func() {
+	DescribeTable("should return the name of the node pool",
+		func(nodeLabels map[string]string, expected string) {
+			node := v1.Node{
+				ObjectMeta: metav1.ObjectMeta{Labels: nodeLabels},
+			}
+
+			Expect(PoolName(&node)).To(Equal(expected))
+		},
+		Entry("Karpenter NodePool", map[string]string{"karpenter.sh/nodepool": "gpu"}, "gpu"),
+		Entry("Karpenter Provisioner", map[string]string{"karpenter.sh/provisioner-name": "default"}, "default"),
+		Entry("GKE node pool", map[string]string{"cloud.google.com/gke-nodepool": "pool-1"}, "pool-1"),
+		Entry(
+			"Karpenter NodePool on EKS",
+			map[string]string{"karpenter.sh/nodepool": "gpu", "eks.amazonaws.com/nodegroup": "system"},
+			"gpu",
+		),
+		Entry("no node pool", map[string]string{"kubernetes.io/os": "linux"}, ""),
+	)
+})
+
+var _ = Describe("ImagePublisher_publish", func() {
+	var (
+		ctrl *gomock.Controller
+		clnt *client.MockClient
+		ip   *ImagePublisher
+	)
+
+	ctx := context.Background()
+	nn := types.NamespacedName{Namespace: "kmm-operator-system", Name: "kmm-node-pool-images"}
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+		ip = NewImagePublisher(clnt, module.NewKernelMapper(), nn, 0, logr.Discard())
+	})
+
+	node := func(name, pool, kernelVersion string) v1.Node {
+		n := v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"gpu": "true"}},
+			Status:     v1.NodeStatus{NodeInfo: v1.NodeSystemInfo{KernelVersion: kernelVersion}},
+		}
+
+		if pool != "" {
+			n.Labels["karpenter.sh/nodepool"] = pool
+		}
+
+		return n
+	}
+
+	mod := kmmv1beta1.Module{
+		ObjectMeta: metav1.ObjectMeta{Name: "gpu-kmod", Namespace: "default"},
+		Spec: kmmv1beta1.ModuleSpec{
+			DevicePlugin: &kmmv1beta1.DevicePluginSpec{
+				Container: kmmv1beta1.DevicePluginContainerSpec{Image: "example.com/gpu-device-plugin:v1"},
+			},
+			ModuleLoader: kmmv1beta1.ModuleLoaderSpec{
+				Container: kmmv1beta1.ModuleLoaderContainerSpec{
+					KernelMappings: []kmmv1beta1.KernelMapping{
+						{Regexp: "^.+$", ContainerImage: "example.com/gpu-kmod:${KERNEL_FULL_VERSION}"},
+					},
+				},
+			},
+			Selector: map[string]string{"gpu": "true"},
+		},
+	}
+
+	It("should publish the images of each node pool", func() {
+		gomock.InOrder(
+			clnt.EXPECT().List(ctx, gomock.AssignableToTypeOf(&v1.NodeList{})).DoAndReturn(
+				func(_ interface{}, list *v1.NodeList, _ ...interface{}) error {
+					list.Items = []v1.Node{
+						node("gpu-1", "gpu", "5.14.0-1"),
+						node("gpu-2", "gpu", "5.14.0-2"),
+						node("static-1", "", "5.14.0-1"),
+					}
+					return nil
+				},
+			),
+			clnt.EXPECT().List(ctx, gomock.AssignableToTypeOf(&kmmv1beta1.ModuleList{})).DoAndReturn(
+				func(_ interface{}, list *kmmv1beta1.ModuleList, _ ...interface{}) error {
+					list.Items = []kmmv1beta1.Module{mod}
+					return nil
+				},
+			),
+			clnt.EXPECT().
+				Get(ctx, nn, gomock.AssignableToTypeOf(&v1.ConfigMap{})).
+				Return(apierrors.NewNotFound(schema.GroupResource{}, nn.Name)),
+			clnt.EXPECT().Create(ctx, gomock.Any()).Do(func(_ context.Context, cm *v1.ConfigMap, _ ...interface{}) {
+				Expect(cm.Data).To(Equal(map[string]string{
+					"gpu": "example.com/gpu-device-plugin:v1\nexample.com/gpu-kmod:5.14.0-1\nexample.com/gpu-kmod:5.14.0-2\n",
+				}))
+			}),
+		)
+
+		ip.publish(ctx)
+	})
+
+	It("should not publish anything if the nodes could not be listed", func() {
+		clnt.EXPECT().List(ctx, gomock.AssignableToTypeOf(&v1.NodeList{})).Return(errors.New("some error"))
+
+		ip.publish(ctx)
+	})
+})
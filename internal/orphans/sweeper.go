@@ -0,0 +1,139 @@
+package orphans
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+)
+
+//+kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=delete;list;patch
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=delete;list;patch
+//+kubebuilder:rbac:groups=kmm.sigs.x-k8s.io,resources=modules,verbs=get
+
+// Sweeper is a manager.Runnable that periodically looks, in all namespaces, for the DaemonSets and Jobs created by
+// KMM that have no controller anymore, typically because backup and restore tooling stripped their owner references.
+// Those whose Module still exists are adopted by it again, so that they are updated and garbage-collected with it;
+// the others are deleted, so that no privileged DaemonSet outlives its Module.
+// Objects that still have a controller are left to the Kubernetes garbage collector.
+type Sweeper struct {
+	client client.Client
+	scheme *runtime.Scheme
+	period time.Duration
+	logger logr.Logger
+}
+
+func NewSweeper(client client.Client, scheme *runtime.Scheme, period time.Duration, logger logr.Logger) *Sweeper {
+	return &Sweeper{
+		client: client,
+		scheme: scheme,
+		period: period,
+		logger: logger,
+	}
+}
+
+// Start sweeps orphans every period until ctx is done.
+func (s *Sweeper) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, s.sweep, s.period)
+	return nil
+}
+
+func (s *Sweeper) sweep(ctx context.Context) {
+	objs, err := s.kmmObjects(ctx)
+	if err != nil {
+		s.logger.Error(err, "failed to list the objects created by KMM")
+		return
+	}
+
+	for _, obj := range objs {
+		if obj.GetDeletionTimestamp() != nil || metav1.GetControllerOf(obj) != nil {
+			continue
+		}
+
+		logger := s.logger.WithValues(
+			"kind", fmt.Sprintf("%T", obj),
+			"name", obj.GetNamespace()+"/"+obj.GetName(),
+			"module", obj.GetLabels()[constants.ModuleNameLabel],
+		)
+
+		if err = s.handleOrphan(ctx, logger, obj); err != nil {
+			logger.Error(err, "failed to handle the orphaned object")
+		}
+	}
+}
+
+// kmmObjects returns the module-loader and device-plugin DaemonSets, and the build and sign Jobs, of all namespaces.
+func (s *Sweeper) kmmObjects(ctx context.Context) ([]client.Object, error) {
+	objs := make([]client.Object, 0)
+
+	dsList := appsv1.DaemonSetList{}
+
+	if err := s.client.List(ctx, &dsList, client.HasLabels{constants.ModuleNameLabel, constants.DaemonSetRole}); err != nil {
+		return nil, fmt.Errorf("could not list DaemonSets: %v", err)
+	}
+
+	for i := 0; i < len(dsList.Items); i++ {
+		objs = append(objs, &dsList.Items[i])
+	}
+
+	jobList := batchv1.JobList{}
+
+	if err := s.client.List(ctx, &jobList, client.HasLabels{constants.ModuleNameLabel, constants.JobType}); err != nil {
+		return nil, fmt.Errorf("could not list Jobs: %v", err)
+	}
+
+	for i := 0; i < len(jobList.Items); i++ {
+		objs = append(objs, &jobList.Items[i])
+	}
+
+	return objs, nil
+}
+
+// handleOrphan makes the Module named in the labels of obj its controller, or deletes obj if that Module does not
+// exist or is being deleted.
+func (s *Sweeper) handleOrphan(ctx context.Context, logger logr.Logger, obj client.Object) error {
+	mod := kmmv1beta1.Module{}
+	nsn := types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetLabels()[constants.ModuleNameLabel]}
+
+	err := s.client.Get(ctx, nsn, &mod)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("could not get Module %s: %v", nsn, err)
+	}
+
+	if err != nil || mod.DeletionTimestamp != nil {
+		logger.Info("Deleting the orphaned object, as its Module does not exist anymore")
+
+		if err = s.client.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("could not delete the object: %v", err)
+		}
+
+		return nil
+	}
+
+	logger.Info("Adopting the orphaned object")
+
+	patchFrom := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+
+	if err = controllerutil.SetControllerReference(&mod, obj, s.scheme); err != nil {
+		return fmt.Errorf("could not set the owner reference: %v", err)
+	}
+
+	if err = s.client.Patch(ctx, obj, patchFrom); err != nil {
+		return fmt.Errorf("could not patch the object: %v", err)
+	}
+
+	return nil
+}
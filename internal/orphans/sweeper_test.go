@@ -0,0 +1,109 @@
+package orphans
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/client"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+)
+
+var _ = Describe("Sweeper_sweep", func() {
+	const (
+		moduleName = "kmod"
+		namespace  = "default"
+	)
+
+	var (
+		clnt *client.MockClient
+		s    *Sweeper
+	)
+
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		clnt = client.NewMockClient(gomock.NewController(GinkgoT()))
+		s = NewSweeper(clnt, scheme, 0, logr.Discard())
+	})
+
+	daemonSet := func(owners ...metav1.OwnerReference) appsv1.DaemonSet {
+		return appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "kmod-module-loader",
+				Namespace:       namespace,
+				Labels:          map[string]string{constants.ModuleNameLabel: moduleName, constants.DaemonSetRole: "module-loader"},
+				OwnerReferences: owners,
+			},
+		}
+	}
+
+	expectList := func(dss ...appsv1.DaemonSet) {
+		gomock.InOrder(
+			clnt.EXPECT().List(ctx, gomock.AssignableToTypeOf(&appsv1.DaemonSetList{}), gomock.Any()).DoAndReturn(
+				func(_ interface{}, list *appsv1.DaemonSetList, _ ...interface{}) error {
+					list.Items = dss
+					return nil
+				},
+			),
+			clnt.EXPECT().List(ctx, gomock.AssignableToTypeOf(&batchv1.JobList{}), gomock.Any()),
+		)
+	}
+
+	It("should leave the objects that have a controller alone", func() {
+		expectList(daemonSet(metav1.OwnerReference{Kind: "Module", Name: moduleName, Controller: pointer.Bool(true)}))
+
+		s.sweep(ctx)
+	})
+
+	It("should delete the orphans whose Module does not exist", func() {
+		expectList(daemonSet())
+
+		gomock.InOrder(
+			clnt.
+				EXPECT().
+				Get(ctx, types.NamespacedName{Namespace: namespace, Name: moduleName}, gomock.AssignableToTypeOf(&kmmv1beta1.Module{})).
+				Return(apierrors.NewNotFound(schema.GroupResource{}, moduleName)),
+			clnt.EXPECT().Delete(ctx, gomock.AssignableToTypeOf(&appsv1.DaemonSet{}), gomock.Any()),
+		)
+
+		s.sweep(ctx)
+	})
+
+	It("should make the Module the controller of its orphans", func() {
+		expectList(daemonSet())
+
+		gomock.InOrder(
+			clnt.
+				EXPECT().
+				Get(ctx, types.NamespacedName{Namespace: namespace, Name: moduleName}, gomock.AssignableToTypeOf(&kmmv1beta1.Module{})).
+				Do(func(_ context.Context, _ types.NamespacedName, mod *kmmv1beta1.Module, _ ...ctrlclient.GetOption) {
+					mod.Name = moduleName
+					mod.Namespace = namespace
+					mod.UID = "some-uid"
+				}),
+			clnt.EXPECT().Patch(ctx, gomock.Any(), gomock.Any()).Do(
+				func(_ context.Context, ds *appsv1.DaemonSet, _ ctrlclient.Patch, _ ...ctrlclient.PatchOption) {
+					owner := metav1.GetControllerOf(ds)
+					Expect(owner).NotTo(BeNil())
+					Expect(owner.Kind).To(Equal("Module"))
+					Expect(owner.UID).To(BeEquivalentTo("some-uid"))
+				},
+			),
+		)
+
+		s.sweep(ctx)
+	})
+})
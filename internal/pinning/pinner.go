@@ -0,0 +1,494 @@
+package pinning
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/go-logr/logr"
+	"github.com/google/go-containerregistry/pkg/name"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/utils"
+)
+
+const (
+	// ObjectsName is the name of the ConfigMap holding the CRI-O configuration file and of the DaemonSet installing
+	// it on nodes.
+	ObjectsName = "kmm-pinned-images"
+
+	configFileName = "99-kmm-pinned-images.conf"
+
+	configVolumeName = "pinned-images"
+	configMountPath  = "/pinned-images"
+
+	hostEtcVolumeName = "host-etc"
+	hostEtcMountPath  = "/host/etc"
+
+	// installPeriodSeconds is how often the installer compares the configuration file of the node with that of the
+	// ConfigMap and with the images pinned by the other CRI-O configuration files.
+	installPeriodSeconds = 60
+
+	// uninstalledFile is created by the uninstaller once it removed the configuration file of the node.
+	uninstalledFile = "/tmp/uninstalled"
+)
+
+// scriptHeader defines the variables and functions of the installer and uninstaller scripts.
+// reload only signals the CRI-O service of the host, started by its init: processes named crio in containers are left
+// alone.
+var scriptHeader = fmt.Sprintf(`set -u
+src=%[1]s/%[3]s
+conf=%[2]s/crio/crio.conf
+dropins=%[2]s/crio/crio.conf.d
+dst=$dropins/%[3]s
+# Written out of the drop-in directory, so that CRI-O never reads a partial file.
+tmp=%[2]s/crio/.%[3]s.tmp
+reload() {
+  for p in /proc/[0-9]*; do
+    [ "$(cat "$p/comm" 2>/dev/null)" = crio ] || continue
+    read -r _ _ _ ppid _ < "$p/stat" && [ "$ppid" = 1 ] && kill -HUP "${p#/proc/}"
+  done
+}
+idle() {
+  while true; do sleep 3600 & wait $!; done
+}
+trap 'exit 0' TERM
+`, configMountPath, hostEtcMountPath, configFileName)
+
+// installScript copies the configuration file of the ConfigMap to the CRI-O drop-in directory, and reloads CRI-O when
+// the file changed.
+// The file is owned by KMM and never merged with other files: as its pinned_images would replace, or be replaced by
+// those of the other CRI-O configuration files, it is not installed on the nodes where another file sets them.
+// Nodes on which CRI-O is not installed are left untouched.
+var installScript = scriptHeader + fmt.Sprintf(`others() {
+  grep -ls '^[[:space:]]*pinned_images[[:space:]]*=' "$conf" "$dropins"/* | grep -vxF "$dst"
+}
+if [ ! -d "$dropins" ]; then
+  echo "CRI-O is not installed on this node: images are not pinned"
+  idle
+fi
+last=
+while true; do
+  pinners=$(others)
+  if [ -n "$pinners" ]; then
+    if [ -f "$dst" ]; then
+      rm -f "$dst" && reload
+    fi
+    if [ "$pinners" != "$last" ]; then
+      echo "Images are pinned by" $pinners "on this node: not pinning the module images"
+    fi
+  elif [ -f "$src" ] && [ "$(cat "$src")" != "$(cat "$dst" 2>/dev/null)" ]; then
+    cp "$src" "$tmp" && mv -f "$tmp" "$dst" && reload
+  fi
+  last=$pinners
+  sleep %[1]d & wait $!
+done
+`, installPeriodSeconds)
+
+// uninstallScript removes the configuration file from the CRI-O drop-in directory and reloads CRI-O.
+var uninstallScript = scriptHeader + fmt.Sprintf(`if [ -f "$dst" ]; then
+  rm -f "$dst" && reload
+fi
+touch %s
+idle
+`, uninstalledFile)
+
+//+kubebuilder:rbac:groups="core",resources=configmaps,verbs=create;delete;get;patch
+//+kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=create;delete;get;patch
+
+// ImagePinner is a manager.Runnable that pins the module-loader and device-plugin images in CRI-O on all nodes, so
+// that the kubelet's image garbage collection never removes them and modules can be loaded again after a reboot even
+// if the registry is not reachable.
+// It periodically writes the images run on nodes to a CRI-O configuration file in a ConfigMap, which a DaemonSet
+// copies to the CRI-O drop-in directory of each node.
+type ImagePinner struct {
+	client         client.Client
+	kernelAPI      module.KernelMapper
+	namespace      string
+	installerImage string
+	period         time.Duration
+	logger         logr.Logger
+}
+
+// NewImagePinner returns an ImagePinner creating its ConfigMap and DaemonSet in namespace.
+// installerImage is run by the DaemonSet, as root in the PID namespace of the host; it must be referenced by digest and
+// provide sh and grep.
+func NewImagePinner(
+	client client.Client,
+	kernelAPI module.KernelMapper,
+	namespace string,
+	installerImage string,
+	period time.Duration,
+	logger logr.Logger,
+) *ImagePinner {
+	return &ImagePinner{
+		client:         client,
+		kernelAPI:      kernelAPI,
+		namespace:      namespace,
+		installerImage: installerImage,
+		period:         period,
+		logger:         logger,
+	}
+}
+
+// Start creates the installer DaemonSet, and then publishes the images every period until ctx is done.
+func (ip *ImagePinner) Start(ctx context.Context) error {
+	if err := ip.createInstaller(ctx); err != nil {
+		return err
+	}
+
+	wait.UntilWithContext(ctx, ip.publish, ip.period)
+	return nil
+}
+
+func (ip *ImagePinner) createInstaller(ctx context.Context) error {
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ObjectsName,
+			Namespace: ip.namespace,
+		},
+	}
+
+	opRes, err := controllerutil.CreateOrPatch(ctx, ip.client, ds, func() error {
+		setInstallerSpec(ds, ip.installerImage, installScript)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("could not create/patch the pinned images installer DaemonSet: %v", err)
+	}
+	ip.logger.Info("Created the pinned images installer DaemonSet", "name", ds.Name, "result", opRes)
+
+	return nil
+}
+
+func setInstallerSpec(ds *appsv1.DaemonSet, image, script string) {
+	labels := map[string]string{"app.kubernetes.io/name": ObjectsName}
+
+	ds.SetLabels(labels)
+
+	directory := v1.HostPathDirectory
+
+	ds.Spec = appsv1.DaemonSetSpec{
+		Selector: &metav1.LabelSelector{MatchLabels: labels},
+		Template: v1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: labels},
+			Spec: v1.PodSpec{
+				AutomountServiceAccountToken: pointer.Bool(false),
+				Containers: []v1.Container{
+					{
+						Name:    "installer",
+						Image:   image,
+						Command: []string{"/bin/sh", "-c", script},
+						// Only the uninstaller creates the file.
+						ReadinessProbe: &v1.Probe{
+							ProbeHandler: v1.ProbeHandler{
+								Exec: &v1.ExecAction{Command: readinessCommand(script)},
+							},
+						},
+						// root owns the drop-in directory and runs CRI-O, so no capability is needed to write the file
+						// and signal CRI-O; SELinux denies both to the default container type.
+						SecurityContext: &v1.SecurityContext{
+							AllowPrivilegeEscalation: pointer.Bool(false),
+							Capabilities:             &v1.Capabilities{Drop: []v1.Capability{"ALL"}},
+							RunAsUser:                pointer.Int64(0),
+							SELinuxOptions:           &v1.SELinuxOptions{Type: "spc_t"},
+						},
+						VolumeMounts: []v1.VolumeMount{
+							{
+								Name:      configVolumeName,
+								MountPath: configMountPath,
+								ReadOnly:  true,
+							},
+							{
+								Name:      hostEtcVolumeName,
+								MountPath: hostEtcMountPath,
+							},
+						},
+					},
+				},
+				// CRI-O is found through /proc.
+				HostPID:           true,
+				NodeSelector:      map[string]string{"kubernetes.io/os": "linux"},
+				PriorityClassName: "system-node-critical",
+				// Cordoned nodes and control plane nodes may run modules.
+				Tolerations: []v1.Toleration{{Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule}},
+				Volumes: []v1.Volume{
+					{
+						Name: configVolumeName,
+						VolumeSource: v1.VolumeSource{
+							ConfigMap: &v1.ConfigMapVolumeSource{
+								LocalObjectReference: v1.LocalObjectReference{Name: ObjectsName},
+								// The DaemonSet is created before the first publication.
+								Optional: pointer.Bool(true),
+							},
+						},
+					},
+					{
+						// The whole /etc is mounted, so that the drop-in directory is not created on nodes without CRI-O.
+						Name: hostEtcVolumeName,
+						VolumeSource: v1.VolumeSource{
+							HostPath: &v1.HostPathVolumeSource{Path: "/etc", Type: &directory},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	utils.SetDaemonSetDefaults(&ds.Spec)
+}
+
+// readinessCommand returns the command of the readiness probe of the DaemonSet running script.
+// The installer is always ready, while the uninstaller is only ready once it removed the configuration file.
+func readinessCommand(script string) []string {
+	if script == uninstallScript {
+		return []string{"test", "-f", uninstalledFile}
+	}
+
+	return []string{"true"}
+}
+
+func (ip *ImagePinner) publish(ctx context.Context) {
+	images, err := ip.nodeImages(ctx)
+	if err != nil {
+		ip.logger.Error(err, "failed to compute the images to pin")
+		return
+	}
+
+	config, err := crioConfig(ip.validImages(images.List()))
+	if err != nil {
+		ip.logger.Error(err, "failed to generate the CRI-O configuration file")
+		return
+	}
+
+	cm := &v1.ConfigMap{}
+	cm.Name = ObjectsName
+	cm.Namespace = ip.namespace
+
+	opRes, err := controllerutil.CreateOrPatch(ctx, ip.client, cm, func() error {
+		cm.Data = map[string]string{configFileName: config}
+		return nil
+	})
+	if err != nil {
+		ip.logger.Error(err, "failed to publish the images to pin")
+		return
+	}
+
+	ip.logger.V(1).Info("Published the images to pin", "count", images.Len(), "result", opRes)
+}
+
+// nodeImages returns the images of the Modules running on all nodes.
+func (ip *ImagePinner) nodeImages(ctx context.Context) (sets.String, error) {
+	nodes := v1.NodeList{}
+
+	if err := ip.client.List(ctx, &nodes); err != nil {
+		return nil, fmt.Errorf("could not list nodes: %v", err)
+	}
+
+	mods := kmmv1beta1.ModuleList{}
+
+	if err := ip.client.List(ctx, &mods); err != nil {
+		return nil, fmt.Errorf("could not list Modules: %v", err)
+	}
+
+	images := sets.NewString()
+
+	for i := 0; i < len(nodes.Items); i++ {
+		node := &nodes.Items[i]
+
+		nodeImages, err := module.NodeImages(ip.kernelAPI, node, mods.Items)
+		if err != nil {
+			ip.logger.Info("Could not substitute the kernel variables", "node", node.Name, "error", err)
+		}
+
+		images = images.Union(nodeImages)
+	}
+
+	return images, nil
+}
+
+// validImages returns the images that are valid references.
+// The others could not be pulled anyway, and would be dropped by the installer.
+func (ip *ImagePinner) validImages(images []string) []string {
+	valid := make([]string, 0, len(images))
+
+	for _, img := range images {
+		if _, err := name.ParseReference(img); err != nil {
+			ip.logger.Info("Not pinning an invalid image", "image", img, "error", err)
+			continue
+		}
+
+		valid = append(valid, img)
+	}
+
+	return valid
+}
+
+type crioImageConfig struct {
+	PinnedImages []string `toml:"pinned_images"`
+}
+
+type crioRuntimeConfig struct {
+	Image crioImageConfig `toml:"image"`
+}
+
+type crioConfigFile struct {
+	Crio crioRuntimeConfig `toml:"crio"`
+}
+
+// crioConfig returns a CRI-O configuration file pinning images.
+func crioConfig(images []string) (string, error) {
+	sb := strings.Builder{}
+
+	sb.WriteString("# Generated by the Kernel Module Management operator.\n")
+
+	enc := toml.NewEncoder(&sb)
+	enc.Indent = ""
+
+	cfg := crioConfigFile{
+		Crio: crioRuntimeConfig{
+			Image: crioImageConfig{PinnedImages: images},
+		},
+	}
+
+	if err := enc.Encode(cfg); err != nil {
+		return "", fmt.Errorf("could not encode the CRI-O configuration: %v", err)
+	}
+
+	return sb.String(), nil
+}
+
+// Uninstaller is a manager.Runnable that removes the CRI-O configuration file installed by ImagePinner from all nodes,
+// and then deletes the ConfigMap and the DaemonSet of ImagePinner.
+// It is run when images are not pinned, so that the pins are lifted once the operator stops pinning images; it only
+// deploys a DaemonSet if the ConfigMap of ImagePinner exists, as the file is never installed otherwise.
+type Uninstaller struct {
+	client    client.Client
+	namespace string
+	period    time.Duration
+	logger    logr.Logger
+}
+
+// NewUninstaller returns an Uninstaller checking every period whether the configuration file was removed from all
+// nodes.
+func NewUninstaller(client client.Client, namespace string, period time.Duration, logger logr.Logger) *Uninstaller {
+	return &Uninstaller{
+		client:    client,
+		namespace: namespace,
+		period:    period,
+		logger:    logger,
+	}
+}
+
+// Start uninstalls the configuration file from all nodes, if ImagePinner ever installed it.
+func (u *Uninstaller) Start(ctx context.Context) error {
+	return wait.PollImmediateUntilWithContext(ctx, u.period, func(ctx context.Context) (bool, error) {
+		done, err := u.uninstall(ctx)
+		if err != nil {
+			u.logger.Error(err, "failed to uninstall the pinned images")
+		}
+
+		return done, nil
+	})
+}
+
+func (u *Uninstaller) uninstall(ctx context.Context) (bool, error) {
+	nn := types.NamespacedName{Namespace: u.namespace, Name: ObjectsName}
+
+	if err := u.client.Get(ctx, nn, &v1.ConfigMap{}); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return false, fmt.Errorf("could not get the pinned images ConfigMap: %v", err)
+		}
+
+		// The installer, if any, never had a file to install.
+		ds := &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: ObjectsName, Namespace: u.namespace},
+		}
+
+		if err = u.client.Delete(ctx, ds); client.IgnoreNotFound(err) != nil {
+			return false, fmt.Errorf("could not delete the pinned images installer DaemonSet: %v", err)
+		}
+
+		return true, nil
+	}
+
+	ds := &appsv1.DaemonSet{}
+
+	if err := u.client.Get(ctx, nn, ds); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return false, fmt.Errorf("could not get the pinned images installer DaemonSet: %v", err)
+		}
+
+		return true, u.deleteConfigMap(ctx)
+	}
+
+	if len(ds.Spec.Template.Spec.Containers) == 0 {
+		return false, fmt.Errorf("DaemonSet %s has no container", ObjectsName)
+	}
+
+	if c := ds.Spec.Template.Spec.Containers[0]; len(c.Command) == 0 || c.Command[len(c.Command)-1] != uninstallScript {
+		// The image that already runs on nodes is reused, as no installer image may be configured anymore.
+		image := c.Image
+
+		if _, err := controllerutil.CreateOrPatch(ctx, u.client, ds, func() error {
+			setInstallerSpec(ds, image, uninstallScript)
+			return nil
+		}); err != nil {
+			return false, fmt.Errorf("could not patch the pinned images installer DaemonSet: %v", err)
+		}
+
+		u.logger.Info("Uninstalling the pinned images from all nodes")
+
+		return false, nil
+	}
+
+	if !uninstalled(ds) {
+		u.logger.V(1).Info(
+			"Waiting for the pinned images to be uninstalled",
+			"ready", ds.Status.NumberReady,
+			"desired", ds.Status.DesiredNumberScheduled,
+		)
+
+		return false, nil
+	}
+
+	if err := u.client.Delete(ctx, ds); client.IgnoreNotFound(err) != nil {
+		return false, fmt.Errorf("could not delete the pinned images installer DaemonSet: %v", err)
+	}
+
+	u.logger.Info("Uninstalled the pinned images from all nodes")
+
+	return true, u.deleteConfigMap(ctx)
+}
+
+func (u *Uninstaller) deleteConfigMap(ctx context.Context) error {
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ObjectsName, Namespace: u.namespace},
+	}
+
+	if err := u.client.Delete(ctx, cm); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("could not delete the pinned images ConfigMap: %v", err)
+	}
+
+	return nil
+}
+
+// uninstalled returns true if the uninstaller runs and is ready on all nodes.
+func uninstalled(ds *appsv1.DaemonSet) bool {
+	return ds.Status.ObservedGeneration >= ds.Generation &&
+		ds.Status.UpdatedNumberScheduled == ds.Status.DesiredNumberScheduled &&
+		ds.Status.NumberReady == ds.Status.DesiredNumberScheduled
+}
@@ -0,0 +1,324 @@
+package pinning
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/client"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+)
+
+const (
+	namespace      = "kmm-operator-system"
+	installerImage = "example.com/installer@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+)
+
+var _ = Describe("ImagePinner_createInstaller", func() {
+	var (
+		ctrl *gomock.Controller
+		clnt *client.MockClient
+		ip   *ImagePinner
+	)
+
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+		ip = NewImagePinner(clnt, module.NewKernelMapper(), namespace, installerImage, 0, logr.Discard())
+	})
+
+	It("should create the DaemonSet", func() {
+		gomock.InOrder(
+			clnt.
+				EXPECT().
+				Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(&appsv1.DaemonSet{})).
+				Return(apierrors.NewNotFound(schema.GroupResource{}, ObjectsName)),
+			clnt.EXPECT().Create(ctx, gomock.Any()).Do(func(_ context.Context, ds *appsv1.DaemonSet, _ ...interface{}) {
+				Expect(ds.Namespace).To(Equal(namespace))
+				Expect(ds.Spec.Template.Spec.Containers[0].Image).To(Equal(installerImage))
+				Expect(ds.Spec.Template.Spec.Containers[0].Command).To(Equal([]string{"/bin/sh", "-c", installScript}))
+				Expect(ds.Spec.Template.Spec.Containers[0].SecurityContext.Privileged).To(BeNil())
+				Expect(ds.Spec.Template.Spec.Containers[0].SecurityContext.Capabilities.Drop).To(ConsistOf(v1.Capability("ALL")))
+				Expect(ds.Spec.Template.Spec.HostPID).To(BeTrue())
+				Expect(ds.Spec.Template.Spec.Tolerations).To(Equal([]v1.Toleration{{Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule}}))
+				Expect(*ds.Spec.Template.Spec.AutomountServiceAccountToken).To(BeFalse())
+				Expect(ds.Spec.Template.Spec.Volumes[0].ConfigMap.Name).To(Equal(ObjectsName))
+				Expect(ds.Spec.Template.Spec.Volumes[1].HostPath.Path).To(Equal("/etc"))
+			}),
+		)
+
+		Expect(ip.createInstaller(ctx)).To(Succeed())
+	})
+
+	It("should return an error if the DaemonSet could not be created", func() {
+		gomock.InOrder(
+			clnt.
+				EXPECT().
+				Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(&appsv1.DaemonSet{})).
+				Return(apierrors.NewNotFound(schema.GroupResource{}, ObjectsName)),
+			clnt.EXPECT().Create(ctx, gomock.Any()).Return(errors.New("some error")),
+		)
+
+		Expect(ip.createInstaller(ctx)).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("ImagePinner_publish", func() {
+	var (
+		ctrl *gomock.Controller
+		clnt *client.MockClient
+		ip   *ImagePinner
+	)
+
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+		ip = NewImagePinner(clnt, module.NewKernelMapper(), namespace, installerImage, 0, logr.Discard())
+	})
+
+	node := func(name, kernelVersion string) v1.Node {
+		return v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"gpu": "true"}},
+			Status:     v1.NodeStatus{NodeInfo: v1.NodeSystemInfo{KernelVersion: kernelVersion}},
+		}
+	}
+
+	mod := kmmv1beta1.Module{
+		ObjectMeta: metav1.ObjectMeta{Name: "gpu-kmod", Namespace: "default"},
+		Spec: kmmv1beta1.ModuleSpec{
+			ModuleLoader: kmmv1beta1.ModuleLoaderSpec{
+				Container: kmmv1beta1.ModuleLoaderContainerSpec{
+					KernelMappings: []kmmv1beta1.KernelMapping{
+						{Regexp: "^.+$", ContainerImage: "example.com/gpu-kmod:${KERNEL_FULL_VERSION}"},
+					},
+				},
+			},
+			Selector: map[string]string{"gpu": "true"},
+		},
+	}
+
+	It("should publish the images of all nodes", func() {
+		gomock.InOrder(
+			clnt.EXPECT().List(ctx, gomock.AssignableToTypeOf(&v1.NodeList{})).DoAndReturn(
+				func(_ interface{}, list *v1.NodeList, _ ...interface{}) error {
+					list.Items = []v1.Node{node("node-1", "5.14.0-1"), node("node-2", "5.14.0-2")}
+					return nil
+				},
+			),
+			clnt.EXPECT().List(ctx, gomock.AssignableToTypeOf(&kmmv1beta1.ModuleList{})).DoAndReturn(
+				func(_ interface{}, list *kmmv1beta1.ModuleList, _ ...interface{}) error {
+					list.Items = []kmmv1beta1.Module{mod}
+					return nil
+				},
+			),
+			clnt.
+				EXPECT().
+				Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(&v1.ConfigMap{})).
+				Return(apierrors.NewNotFound(schema.GroupResource{}, ObjectsName)),
+			clnt.EXPECT().Create(ctx, gomock.Any()).Do(func(_ context.Context, cm *v1.ConfigMap, _ ...interface{}) {
+				Expect(cm.Data).To(HaveKeyWithValue(
+					"99-kmm-pinned-images.conf",
+					`# Generated by the Kernel Module Management operator.
+[crio]
+[crio.image]
+pinned_images = ["example.com/gpu-kmod:5.14.0-1", "example.com/gpu-kmod:5.14.0-2"]
+`,
+				))
+			}),
+		)
+
+		ip.publish(ctx)
+	})
+
+	It("should not publish invalid images", func() {
+		invalidMod := mod
+		invalidMod.Spec.ModuleLoader.Container.KernelMappings = []kmmv1beta1.KernelMapping{
+			{Regexp: "^.+$", ContainerImage: `example.com/gpu-kmod:${KERNEL_FULL_VERSION}"]`},
+		}
+
+		gomock.InOrder(
+			clnt.EXPECT().List(ctx, gomock.AssignableToTypeOf(&v1.NodeList{})).DoAndReturn(
+				func(_ interface{}, list *v1.NodeList, _ ...interface{}) error {
+					list.Items = []v1.Node{node("node-1", "5.14.0-1")}
+					return nil
+				},
+			),
+			clnt.EXPECT().List(ctx, gomock.AssignableToTypeOf(&kmmv1beta1.ModuleList{})).DoAndReturn(
+				func(_ interface{}, list *kmmv1beta1.ModuleList, _ ...interface{}) error {
+					list.Items = []kmmv1beta1.Module{mod, invalidMod}
+					return nil
+				},
+			),
+			clnt.
+				EXPECT().
+				Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(&v1.ConfigMap{})).
+				Return(apierrors.NewNotFound(schema.GroupResource{}, ObjectsName)),
+			clnt.EXPECT().Create(ctx, gomock.Any()).Do(func(_ context.Context, cm *v1.ConfigMap, _ ...interface{}) {
+				Expect(cm.Data).To(HaveKeyWithValue(
+					"99-kmm-pinned-images.conf",
+					`# Generated by the Kernel Module Management operator.
+[crio]
+[crio.image]
+pinned_images = ["example.com/gpu-kmod:5.14.0-1"]
+`,
+				))
+			}),
+		)
+
+		ip.publish(ctx)
+	})
+
+	It("should not publish anything if the Modules could not be listed", func() {
+		gomock.InOrder(
+			clnt.EXPECT().List(ctx, gomock.AssignableToTypeOf(&v1.NodeList{})),
+			clnt.EXPECT().List(ctx, gomock.AssignableToTypeOf(&kmmv1beta1.ModuleList{})).Return(errors.New("some error")),
+		)
+
+		ip.publish(ctx)
+	})
+})
+
+var _ = Describe("Uninstaller_uninstall", func() {
+	var (
+		ctrl *gomock.Controller
+		clnt *client.MockClient
+		u    *Uninstaller
+	)
+
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+		u = NewUninstaller(clnt, namespace, 0, logr.Discard())
+	})
+
+	installer := func(script string) *appsv1.DaemonSet {
+		ds := appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: ObjectsName, Namespace: namespace},
+		}
+
+		setInstallerSpec(&ds, installerImage, script)
+
+		return &ds
+	}
+
+	It("should only delete the DaemonSet if there is no ConfigMap", func() {
+		gomock.InOrder(
+			clnt.
+				EXPECT().
+				Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(&v1.ConfigMap{})).
+				Return(apierrors.NewNotFound(schema.GroupResource{}, ObjectsName)),
+			clnt.
+				EXPECT().
+				Delete(ctx, gomock.AssignableToTypeOf(&appsv1.DaemonSet{})).
+				Return(apierrors.NewNotFound(schema.GroupResource{}, ObjectsName)),
+		)
+
+		Expect(u.uninstall(ctx)).To(BeTrue())
+	})
+
+	It("should only delete the ConfigMap if there is no DaemonSet", func() {
+		gomock.InOrder(
+			clnt.
+				EXPECT().
+				Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(&v1.ConfigMap{})),
+			clnt.
+				EXPECT().
+				Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(&appsv1.DaemonSet{})).
+				Return(apierrors.NewNotFound(schema.GroupResource{}, ObjectsName)),
+			clnt.
+				EXPECT().
+				Delete(ctx, gomock.AssignableToTypeOf(&v1.ConfigMap{})).
+				Return(apierrors.NewNotFound(schema.GroupResource{}, ObjectsName)),
+		)
+
+		Expect(u.uninstall(ctx)).To(BeTrue())
+	})
+
+	It("should run the uninstaller with the image of the installer", func() {
+		gomock.InOrder(
+			clnt.
+				EXPECT().
+				Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(&v1.ConfigMap{})),
+			clnt.
+				EXPECT().
+				Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(&appsv1.DaemonSet{})).
+				DoAndReturn(func(_ interface{}, _ interface{}, ds *appsv1.DaemonSet, _ ...interface{}) error {
+					installer(installScript).DeepCopyInto(ds)
+					return nil
+				}),
+			clnt.
+				EXPECT().
+				Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(&appsv1.DaemonSet{})).
+				DoAndReturn(func(_ interface{}, _ interface{}, ds *appsv1.DaemonSet, _ ...interface{}) error {
+					installer(installScript).DeepCopyInto(ds)
+					return nil
+				}),
+			clnt.EXPECT().Patch(ctx, gomock.Any(), gomock.Any()).Do(func(_ context.Context, ds *appsv1.DaemonSet, _ ctrlclient.Patch, _ ...interface{}) {
+				Expect(ds.Spec.Template.Spec.Containers[0].Image).To(Equal(installerImage))
+				Expect(ds.Spec.Template.Spec.Containers[0].Command).To(Equal([]string{"/bin/sh", "-c", uninstallScript}))
+				Expect(ds.Spec.Template.Spec.Containers[0].ReadinessProbe.Exec.Command).To(Equal([]string{"test", "-f", uninstalledFile}))
+			}),
+		)
+
+		Expect(u.uninstall(ctx)).To(BeFalse())
+	})
+
+	It("should wait for the uninstaller to be ready on all nodes", func() {
+		ds := installer(uninstallScript)
+		ds.Status = appsv1.DaemonSetStatus{DesiredNumberScheduled: 2, UpdatedNumberScheduled: 2, NumberReady: 1}
+
+		gomock.InOrder(
+			clnt.
+				EXPECT().
+				Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(&v1.ConfigMap{})),
+			clnt.
+				EXPECT().
+				Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(&appsv1.DaemonSet{})).
+				DoAndReturn(func(_ interface{}, _ interface{}, obj *appsv1.DaemonSet, _ ...interface{}) error {
+					ds.DeepCopyInto(obj)
+					return nil
+				}),
+		)
+
+		Expect(u.uninstall(ctx)).To(BeFalse())
+	})
+
+	It("should delete the DaemonSet and the ConfigMap once the uninstaller is ready on all nodes", func() {
+		ds := installer(uninstallScript)
+		ds.Status = appsv1.DaemonSetStatus{DesiredNumberScheduled: 2, UpdatedNumberScheduled: 2, NumberReady: 2}
+
+		gomock.InOrder(
+			clnt.
+				EXPECT().
+				Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(&v1.ConfigMap{})),
+			clnt.
+				EXPECT().
+				Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(&appsv1.DaemonSet{})).
+				DoAndReturn(func(_ interface{}, _ interface{}, obj *appsv1.DaemonSet, _ ...interface{}) error {
+					ds.DeepCopyInto(obj)
+					return nil
+				}),
+			clnt.EXPECT().Delete(ctx, gomock.AssignableToTypeOf(&appsv1.DaemonSet{})),
+			clnt.EXPECT().Delete(ctx, gomock.AssignableToTypeOf(&v1.ConfigMap{})),
+		)
+
+		Expect(u.uninstall(ctx)).To(BeTrue())
+	})
+})
@@ -0,0 +1,161 @@
+package podsecurity
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+)
+
+const (
+	// EnforceLabel is the namespace label that sets the Pod Security Admission level enforced in the namespace.
+	EnforceLabel = "pod-security.kubernetes.io/enforce"
+
+	// LevelPrivileged is the only Pod Security Admission level that allows module-loader and device-plugin pods.
+	LevelPrivileged = "privileged"
+
+	// PreviousEnforceAnnotation holds the value of EnforceLabel before the operator set it, so that it can be restored
+	// once the namespace has no Module anymore.
+	// An empty value means that the label was not set.
+	PreviousEnforceAnnotation = "kmm.node.kubernetes.io/previous-pod-security-enforce"
+
+	// nonRootUID is the user that containers of restricted pods run as, unless their image sets a non-root user.
+	nonRootUID = 65534
+)
+
+// RestrictedPodSecurityContext returns a PodSecurityContext that satisfies the "restricted" Pod Security Standard.
+func RestrictedPodSecurityContext() *v1.PodSecurityContext {
+	return &v1.PodSecurityContext{
+		RunAsNonRoot:   pointer.Bool(true),
+		RunAsUser:      pointer.Int64(nonRootUID),
+		SeccompProfile: &v1.SeccompProfile{Type: v1.SeccompProfileTypeRuntimeDefault},
+	}
+}
+
+// RestrictedSecurityContext returns a container SecurityContext that satisfies the "restricted" Pod Security Standard.
+func RestrictedSecurityContext() *v1.SecurityContext {
+	return &v1.SecurityContext{
+		AllowPrivilegeEscalation: pointer.Bool(false),
+		Capabilities: &v1.Capabilities{
+			Drop: []v1.Capability{"ALL"},
+		},
+	}
+}
+
+//go:generate mockgen -source=podsecurity.go -package=podsecurity -destination=mock_podsecurity.go
+
+type NamespaceLabeler interface {
+	RestorePrevious(ctx context.Context, namespace string) error
+	SetPrivileged(ctx context.Context, namespace string) error
+}
+
+type namespaceLabeler struct {
+	client     client.Client
+	namespaces sets.String
+}
+
+// NewNamespaceLabeler returns a NamespaceLabeler that only labels the namespaces listed by the cluster administrator.
+func NewNamespaceLabeler(client client.Client, namespaces ...string) NamespaceLabeler {
+	return &namespaceLabeler{
+		client:     client,
+		namespaces: sets.NewString(namespaces...),
+	}
+}
+
+// SetPrivileged labels namespace so that the "privileged" Pod Security Admission level is enforced there.
+// This is the exception that module-loader and device-plugin pods require.
+// Namespaces that the cluster administrator did not allow are left untouched.
+// The previous level is recorded on the namespace and restored by RestorePrevious.
+func (nl *namespaceLabeler) SetPrivileged(ctx context.Context, namespace string) error {
+	logger := log.FromContext(ctx)
+
+	if !nl.namespaces.Has(namespace) {
+		logger.V(1).Info("Not labeling a namespace that is not allowed", "namespace", namespace)
+		return nil
+	}
+
+	ns := v1.Namespace{}
+
+	if err := nl.client.Get(ctx, client.ObjectKey{Name: namespace}, &ns); err != nil {
+		return fmt.Errorf("could not get namespace %s: %v", namespace, err)
+	}
+
+	if ns.Labels[EnforceLabel] == LevelPrivileged {
+		return nil
+	}
+
+	patchFrom := client.MergeFrom(ns.DeepCopy())
+
+	if ns.Labels == nil {
+		ns.Labels = make(map[string]string)
+	}
+
+	if ns.Annotations == nil {
+		ns.Annotations = make(map[string]string)
+	}
+
+	ns.Annotations[PreviousEnforceAnnotation] = ns.Labels[EnforceLabel]
+	ns.Labels[EnforceLabel] = LevelPrivileged
+
+	if err := nl.client.Patch(ctx, &ns, patchFrom); err != nil {
+		return fmt.Errorf("could not patch namespace %s: %v", namespace, err)
+	}
+
+	logger.Info("Labeled namespace for privileged pods", "namespace", namespace, "label", EnforceLabel)
+
+	return nil
+}
+
+// RestorePrevious restores the Pod Security Admission level that namespace enforced before SetPrivileged labeled it,
+// once namespace has no Module anymore.
+func (nl *namespaceLabeler) RestorePrevious(ctx context.Context, namespace string) error {
+	logger := log.FromContext(ctx)
+
+	ns := v1.Namespace{}
+
+	if err := nl.client.Get(ctx, client.ObjectKey{Name: namespace}, &ns); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	previous, ok := ns.Annotations[PreviousEnforceAnnotation]
+	if !ok {
+		return nil
+	}
+
+	mods := kmmv1beta1.ModuleList{}
+
+	if err := nl.client.List(ctx, &mods, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("could not list the Modules in namespace %s: %v", namespace, err)
+	}
+
+	if len(mods.Items) > 0 {
+		return nil
+	}
+
+	patchFrom := client.MergeFrom(ns.DeepCopy())
+
+	delete(ns.Annotations, PreviousEnforceAnnotation)
+
+	// The level was changed by someone else after SetPrivileged; theirs is kept.
+	if ns.Labels[EnforceLabel] == LevelPrivileged {
+		if previous == "" {
+			delete(ns.Labels, EnforceLabel)
+		} else {
+			ns.Labels[EnforceLabel] = previous
+		}
+	}
+
+	if err := nl.client.Patch(ctx, &ns, patchFrom); err != nil {
+		return fmt.Errorf("could not patch namespace %s: %v", namespace, err)
+	}
+
+	logger.Info("Restored the Pod Security Admission level of namespace", "namespace", namespace, "level", previous)
+
+	return nil
+}
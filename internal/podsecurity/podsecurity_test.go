@@ -0,0 +1,236 @@
+package podsecurity
+
+import (
+	"context"
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/client"
+)
+
+var _ = Describe("SetPrivileged", func() {
+	const namespace = "namespace"
+
+	var (
+		ctrl *gomock.Controller
+		clnt *client.MockClient
+		nl   NamespaceLabeler
+	)
+
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+		nl = NewNamespaceLabeler(clnt, namespace)
+	})
+
+	It("should do nothing if the namespace is not allowed", func() {
+		Expect(
+			nl.SetPrivileged(ctx, "other-namespace"),
+		).NotTo(
+			HaveOccurred(),
+		)
+	})
+
+	It("should return an error if the namespace cannot be fetched", func() {
+		clnt.EXPECT().Get(ctx, ctrlclient.ObjectKey{Name: namespace}, gomock.Any()).Return(errors.New("some error"))
+
+		Expect(
+			nl.SetPrivileged(ctx, namespace),
+		).To(
+			HaveOccurred(),
+		)
+	})
+
+	It("should do nothing if the namespace is already privileged", func() {
+		clnt.EXPECT().Get(ctx, ctrlclient.ObjectKey{Name: namespace}, gomock.Any()).DoAndReturn(
+			func(_ interface{}, _ interface{}, ns *v1.Namespace, _ ...ctrlclient.GetOption) error {
+				ns.Labels = map[string]string{EnforceLabel: LevelPrivileged}
+				return nil
+			},
+		)
+
+		Expect(
+			nl.SetPrivileged(ctx, namespace),
+		).NotTo(
+			HaveOccurred(),
+		)
+	})
+
+	It("should label the namespace", func() {
+		gomock.InOrder(
+			clnt.EXPECT().Get(ctx, ctrlclient.ObjectKey{Name: namespace}, gomock.Any()).DoAndReturn(
+				func(_ interface{}, _ interface{}, ns *v1.Namespace, _ ...ctrlclient.GetOption) error {
+					ns.ObjectMeta = metav1.ObjectMeta{
+						Name:   namespace,
+						Labels: map[string]string{EnforceLabel: "restricted"},
+					}
+					return nil
+				},
+			),
+			clnt.EXPECT().Patch(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ interface{}, ns *v1.Namespace, _ ctrlclient.Patch, _ ...ctrlclient.PatchOption) error {
+					Expect(ns.Labels).To(HaveKeyWithValue(EnforceLabel, LevelPrivileged))
+					Expect(ns.Annotations).To(HaveKeyWithValue(PreviousEnforceAnnotation, "restricted"))
+					return nil
+				},
+			),
+		)
+
+		Expect(
+			nl.SetPrivileged(ctx, namespace),
+		).NotTo(
+			HaveOccurred(),
+		)
+	})
+
+	It("should return an error if the namespace cannot be patched", func() {
+		gomock.InOrder(
+			clnt.EXPECT().Get(ctx, ctrlclient.ObjectKey{Name: namespace}, gomock.Any()),
+			clnt.EXPECT().Patch(ctx, gomock.Any(), gomock.Any()).Return(errors.New("some error")),
+		)
+
+		Expect(
+			nl.SetPrivileged(ctx, namespace),
+		).To(
+			HaveOccurred(),
+		)
+	})
+})
+
+var _ = Describe("RestorePrevious", func() {
+	const namespace = "namespace"
+
+	var (
+		ctrl *gomock.Controller
+		clnt *client.MockClient
+		nl   NamespaceLabeler
+	)
+
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+		nl = NewNamespaceLabeler(clnt, namespace)
+	})
+
+	getNamespace := func(labels, annotations map[string]string) *gomock.Call {
+		return clnt.EXPECT().Get(ctx, ctrlclient.ObjectKey{Name: namespace}, gomock.Any()).DoAndReturn(
+			func(_ interface{}, _ interface{}, ns *v1.Namespace, _ ...ctrlclient.GetOption) error {
+				ns.ObjectMeta = metav1.ObjectMeta{
+					Name:        namespace,
+					Labels:      labels,
+					Annotations: annotations,
+				}
+				return nil
+			},
+		)
+	}
+
+	It("should do nothing if the namespace was not labeled by the operator", func() {
+		getNamespace(map[string]string{EnforceLabel: LevelPrivileged}, nil)
+
+		Expect(
+			nl.RestorePrevious(ctx, namespace),
+		).NotTo(
+			HaveOccurred(),
+		)
+	})
+
+	It("should do nothing if the namespace still has Modules", func() {
+		gomock.InOrder(
+			getNamespace(
+				map[string]string{EnforceLabel: LevelPrivileged},
+				map[string]string{PreviousEnforceAnnotation: "restricted"},
+			),
+			clnt.EXPECT().List(ctx, gomock.AssignableToTypeOf(&kmmv1beta1.ModuleList{}), ctrlclient.InNamespace(namespace)).DoAndReturn(
+				func(_ interface{}, list *kmmv1beta1.ModuleList, _ ...ctrlclient.ListOption) error {
+					list.Items = []kmmv1beta1.Module{{}}
+					return nil
+				},
+			),
+		)
+
+		Expect(
+			nl.RestorePrevious(ctx, namespace),
+		).NotTo(
+			HaveOccurred(),
+		)
+	})
+
+	It("should restore the previous level", func() {
+		gomock.InOrder(
+			getNamespace(
+				map[string]string{EnforceLabel: LevelPrivileged},
+				map[string]string{PreviousEnforceAnnotation: "restricted"},
+			),
+			clnt.EXPECT().List(ctx, gomock.AssignableToTypeOf(&kmmv1beta1.ModuleList{}), ctrlclient.InNamespace(namespace)),
+			clnt.EXPECT().Patch(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ interface{}, ns *v1.Namespace, _ ctrlclient.Patch, _ ...ctrlclient.PatchOption) error {
+					Expect(ns.Labels).To(HaveKeyWithValue(EnforceLabel, "restricted"))
+					Expect(ns.Annotations).NotTo(HaveKey(PreviousEnforceAnnotation))
+					return nil
+				},
+			),
+		)
+
+		Expect(
+			nl.RestorePrevious(ctx, namespace),
+		).NotTo(
+			HaveOccurred(),
+		)
+	})
+
+	It("should remove the label if it was not set", func() {
+		gomock.InOrder(
+			getNamespace(
+				map[string]string{EnforceLabel: LevelPrivileged},
+				map[string]string{PreviousEnforceAnnotation: ""},
+			),
+			clnt.EXPECT().List(ctx, gomock.AssignableToTypeOf(&kmmv1beta1.ModuleList{}), ctrlclient.InNamespace(namespace)),
+			clnt.EXPECT().Patch(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ interface{}, ns *v1.Namespace, _ ctrlclient.Patch, _ ...ctrlclient.PatchOption) error {
+					Expect(ns.Labels).NotTo(HaveKey(EnforceLabel))
+					return nil
+				},
+			),
+		)
+
+		Expect(
+			nl.RestorePrevious(ctx, namespace),
+		).NotTo(
+			HaveOccurred(),
+		)
+	})
+
+	It("should keep a level changed by someone else", func() {
+		gomock.InOrder(
+			getNamespace(
+				map[string]string{EnforceLabel: "baseline"},
+				map[string]string{PreviousEnforceAnnotation: "restricted"},
+			),
+			clnt.EXPECT().List(ctx, gomock.AssignableToTypeOf(&kmmv1beta1.ModuleList{}), ctrlclient.InNamespace(namespace)),
+			clnt.EXPECT().Patch(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ interface{}, ns *v1.Namespace, _ ctrlclient.Patch, _ ...ctrlclient.PatchOption) error {
+					Expect(ns.Labels).To(HaveKeyWithValue(EnforceLabel, "baseline"))
+					return nil
+				},
+			),
+		)
+
+		Expect(
+			nl.RestorePrevious(ctx, namespace),
+		).NotTo(
+			HaveOccurred(),
+		)
+	})
+})
@@ -0,0 +1,331 @@
+package provenance
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/auth"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/registry"
+)
+
+const (
+	// PublicKeyDataKey is the entry of the policy's Secret that holds the public key.
+	PublicKeyDataKey = "key"
+
+	inTotoPayloadType = "application/vnd.in-toto+json"
+
+	slsaProvenanceV02 = "https://slsa.dev/provenance/v0.2"
+	slsaProvenanceV1  = "https://slsa.dev/provenance/v1"
+)
+
+// ErrVerificationFailed is returned when an image does not carry an attestation that satisfies the policy.
+var ErrVerificationFailed = errors.New("provenance verification failed")
+
+//go:generate mockgen -source=provenance.go -package=provenance -destination=mock_provenance.go
+
+type Verifier interface {
+	Verify(ctx context.Context, mod *kmmv1beta1.Module, km *kmmv1beta1.KernelMapping) (string, error)
+}
+
+type verifier struct {
+	client   client.Client
+	registry registry.Registry
+}
+
+func NewVerifier(client client.Client, registry registry.Registry) Verifier {
+	return &verifier{
+		client:   client,
+		registry: registry,
+	}
+}
+
+// Verify looks for an in-toto attestation of km's container image that is signed with the policy's public key and
+// whose SLSA provenance matches the policy.
+// It returns the image referenced by the digest that was verified, so that the verified manifest is deployed even if
+// the tag is moved afterwards, or km's image unchanged if the Module has no policy.
+// It returns an error wrapping ErrVerificationFailed if there is no such attestation.
+func (v *verifier) Verify(ctx context.Context, mod *kmmv1beta1.Module, km *kmmv1beta1.KernelMapping) (string, error) {
+	policy := mod.Spec.ModuleLoader.Container.Provenance
+	if policy == nil {
+		return km.ContainerImage, nil
+	}
+
+	key, err := v.getPublicKey(ctx, policy.PublicKeySecret.Name, mod.Namespace)
+	if err != nil {
+		return "", fmt.Errorf("could not get the public key: %v", err)
+	}
+
+	digest, envelopes, err := v.registry.GetAttestations(
+		ctx,
+		km.ContainerImage,
+		module.TLSOptions(mod.Spec, *km),
+		auth.NewRegistryAuthGetterFrom(v.client, mod),
+	)
+	if err != nil {
+		return "", fmt.Errorf("could not get the attestations of image %s: %v", km.ContainerImage, err)
+	}
+
+	if len(envelopes) == 0 {
+		return "", fmt.Errorf("%w: image %s has no attestation", ErrVerificationFailed, km.ContainerImage)
+	}
+
+	reasons := make([]string, 0, len(envelopes))
+
+	for _, e := range envelopes {
+		if err = verifyEnvelope(e, key, digest, policy); err == nil {
+			return pinnedImage(km.ContainerImage, digest)
+		}
+
+		reasons = append(reasons, err.Error())
+	}
+
+	return "", fmt.Errorf(
+		"%w: no attestation of image %s satisfies the policy: %s",
+		ErrVerificationFailed,
+		km.ContainerImage,
+		strings.Join(reasons, "; "),
+	)
+}
+
+// pinnedImage returns image referenced by digest instead of its tag.
+func pinnedImage(image, digest string) (string, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return "", fmt.Errorf("could not parse image %s: %v", image, err)
+	}
+
+	return ref.Context().Digest(digest).String(), nil
+}
+
+func (v *verifier) getPublicKey(ctx context.Context, secretName, namespace string) (crypto.PublicKey, error) {
+	secret := v1.Secret{}
+
+	if err := v.client.Get(ctx, types.NamespacedName{Name: secretName, Namespace: namespace}, &secret); err != nil {
+		return nil, fmt.Errorf("could not get Secret %s: %v", secretName, err)
+	}
+
+	block, _ := pem.Decode(secret.Data[PublicKeyDataKey])
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data in the %q entry of Secret %s", PublicKeyDataKey, secretName)
+	}
+
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+type envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"`
+	Signatures  []signature `json:"signatures"`
+}
+
+type signature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+type statement struct {
+	Type          string          `json:"_type"`
+	PredicateType string          `json:"predicateType"`
+	Subject       []subject       `json:"subject"`
+	Predicate     json.RawMessage `json:"predicate"`
+}
+
+type subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+type resourceDescriptor struct {
+	URI string `json:"uri"`
+}
+
+type builder struct {
+	ID string `json:"id"`
+}
+
+// predicateV02 holds the fields of a SLSA v0.2 provenance that the policy applies to.
+type predicateV02 struct {
+	Builder    builder `json:"builder"`
+	Invocation struct {
+		ConfigSource resourceDescriptor `json:"configSource"`
+	} `json:"invocation"`
+	Materials []resourceDescriptor `json:"materials"`
+}
+
+// predicateV1 holds the fields of a SLSA v1 provenance that the policy applies to.
+type predicateV1 struct {
+	BuildDefinition struct {
+		ResolvedDependencies []resourceDescriptor `json:"resolvedDependencies"`
+	} `json:"buildDefinition"`
+	RunDetails struct {
+		Builder builder `json:"builder"`
+	} `json:"runDetails"`
+}
+
+func verifyEnvelope(b []byte, key crypto.PublicKey, digest string, policy *kmmv1beta1.ProvenancePolicy) error {
+	env := envelope{}
+
+	if err := json.Unmarshal(b, &env); err != nil {
+		return fmt.Errorf("invalid envelope: %v", err)
+	}
+
+	if env.PayloadType != inTotoPayloadType {
+		return fmt.Errorf("unexpected payload type %q", env.PayloadType)
+	}
+
+	payload, err := base64.StdEncoding.DecodeString(env.Payload)
+	if err != nil {
+		return fmt.Errorf("invalid payload: %v", err)
+	}
+
+	if err = verifySignatures(env, payload, key); err != nil {
+		return err
+	}
+
+	st := statement{}
+
+	if err = json.Unmarshal(payload, &st); err != nil {
+		return fmt.Errorf("invalid statement: %v", err)
+	}
+
+	if !hasSubject(st, digest) {
+		return fmt.Errorf("the statement's subject is not %s", digest)
+	}
+
+	builderID, sources, err := parsePredicate(st)
+	if err != nil {
+		return err
+	}
+
+	if policy.BuilderID != "" && builderID != policy.BuilderID {
+		return fmt.Errorf("unexpected builder %q", builderID)
+	}
+
+	if policy.SourceRepository != "" && !hasSource(sources, policy.SourceRepository) {
+		return fmt.Errorf("no material from %s", policy.SourceRepository)
+	}
+
+	return nil
+}
+
+// verifySignatures returns nil if at least one of env's signatures is a valid signature of payload by key.
+func verifySignatures(env envelope, payload []byte, key crypto.PublicKey) error {
+	// The signatures apply to the pre-authentication encoding of the payload.
+	pae := []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(env.PayloadType), env.PayloadType, len(payload), payload))
+
+	for _, s := range env.Signatures {
+		sig, err := base64.StdEncoding.DecodeString(s.Sig)
+		if err != nil {
+			continue
+		}
+
+		if verifySignature(key, pae, sig) == nil {
+			return nil
+		}
+	}
+
+	return errors.New("no valid signature")
+}
+
+func verifySignature(key crypto.PublicKey, message, sig []byte) error {
+	h := sha256.Sum256(message)
+
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(k, h[:], sig) {
+			return errors.New("invalid ECDSA signature")
+		}
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, h[:], sig)
+	case ed25519.PublicKey:
+		if !ed25519.Verify(k, message, sig) {
+			return errors.New("invalid Ed25519 signature")
+		}
+	default:
+		return fmt.Errorf("unsupported public key type %T", key)
+	}
+
+	return nil
+}
+
+func hasSubject(st statement, digest string) bool {
+	algo, hex, ok := strings.Cut(digest, ":")
+	if !ok {
+		return false
+	}
+
+	for _, s := range st.Subject {
+		if s.Digest[algo] == hex {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parsePredicate returns the builder ID and the source URIs of st's SLSA provenance.
+func parsePredicate(st statement) (string, []string, error) {
+	switch st.PredicateType {
+	case slsaProvenanceV02:
+		p := predicateV02{}
+
+		if err := json.Unmarshal(st.Predicate, &p); err != nil {
+			return "", nil, fmt.Errorf("invalid SLSA v0.2 provenance: %v", err)
+		}
+
+		sources := []string{p.Invocation.ConfigSource.URI}
+		for _, m := range p.Materials {
+			sources = append(sources, m.URI)
+		}
+
+		return p.Builder.ID, sources, nil
+	case slsaProvenanceV1:
+		p := predicateV1{}
+
+		if err := json.Unmarshal(st.Predicate, &p); err != nil {
+			return "", nil, fmt.Errorf("invalid SLSA v1 provenance: %v", err)
+		}
+
+		sources := make([]string, 0, len(p.BuildDefinition.ResolvedDependencies))
+		for _, d := range p.BuildDefinition.ResolvedDependencies {
+			sources = append(sources, d.URI)
+		}
+
+		return p.RunDetails.Builder.ID, sources, nil
+	default:
+		return "", nil, fmt.Errorf("unsupported predicate type %q", st.PredicateType)
+	}
+}
+
+// hasSource returns true if one of sources is repo, optionally followed by a ref, a path, a query or a fragment.
+func hasSource(sources []string, repo string) bool {
+	for _, s := range sources {
+		if !strings.HasPrefix(s, repo) {
+			continue
+		}
+
+		if rest := s[len(repo):]; rest == "" || strings.ContainsAny(rest[:1], "@/?#") {
+			return true
+		}
+	}
+
+	return false
+}
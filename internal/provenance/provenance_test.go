@@ -0,0 +1,263 @@
+package provenance
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/client"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/registry"
+)
+
+const (
+	builderID  = "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/builder_container-based_slsa3.yml@refs/tags/v1.5.0"
+	digest     = "sha256:0123456789abcdef"
+	image      = "registry.example.com/org/kmod:v1"
+	namespace  = "namespace"
+	secretName = "provenance-key"
+	sourceRepo = "git+https://github.com/org/kmod"
+)
+
+func makeEnvelope(key *ecdsa.PrivateKey, predicateType string, predicate interface{}, subjectDigest string) []byte {
+	pred, err := json.Marshal(predicate)
+	Expect(err).NotTo(HaveOccurred())
+
+	payload, err := json.Marshal(statement{
+		Type:          "https://in-toto.io/Statement/v0.1",
+		PredicateType: predicateType,
+		Subject:       []subject{{Name: image, Digest: map[string]string{"sha256": subjectDigest}}},
+		Predicate:     pred,
+	})
+	Expect(err).NotTo(HaveOccurred())
+
+	h := sha256.Sum256([]byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(inTotoPayloadType), inTotoPayloadType, len(payload), payload)))
+
+	sig, err := ecdsa.SignASN1(rand.Reader, key, h[:])
+	Expect(err).NotTo(HaveOccurred())
+
+	b, err := json.Marshal(envelope{
+		PayloadType: inTotoPayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures:  []signature{{Sig: base64.StdEncoding.EncodeToString(sig)}},
+	})
+	Expect(err).NotTo(HaveOccurred())
+
+	return b
+}
+
+func v02Predicate(builder, source string) map[string]interface{} {
+	return map[string]interface{}{
+		"builder":   map[string]string{"id": builder},
+		"materials": []map[string]string{{"uri": source + "@refs/heads/main"}},
+	}
+}
+
+var _ = Describe("Verify", func() {
+	var (
+		ctrl    *gomock.Controller
+		clnt    *client.MockClient
+		mockReg *registry.MockRegistry
+		v       Verifier
+
+		key *ecdsa.PrivateKey
+		mod *kmmv1beta1.Module
+		km  *kmmv1beta1.KernelMapping
+	)
+
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+		mockReg = registry.NewMockRegistry(ctrl)
+		v = NewVerifier(clnt, mockReg)
+
+		var err error
+
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+
+		mod = &kmmv1beta1.Module{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace},
+			Spec: kmmv1beta1.ModuleSpec{
+				ModuleLoader: kmmv1beta1.ModuleLoaderSpec{
+					Container: kmmv1beta1.ModuleLoaderContainerSpec{
+						Provenance: &kmmv1beta1.ProvenancePolicy{
+							PublicKeySecret:  v1.LocalObjectReference{Name: secretName},
+							BuilderID:        builderID,
+							SourceRepository: sourceRepo,
+						},
+					},
+				},
+			},
+		}
+
+		km = &kmmv1beta1.KernelMapping{ContainerImage: image}
+	})
+
+	expectPublicKey := func(pub interface{}) *gomock.Call {
+		der, err := x509.MarshalPKIXPublicKey(pub)
+		Expect(err).NotTo(HaveOccurred())
+
+		return clnt.EXPECT().Get(ctx, types.NamespacedName{Name: secretName, Namespace: namespace}, gomock.Any()).DoAndReturn(
+			func(_ interface{}, _ interface{}, s *v1.Secret, _ ...ctrlclient.GetOption) error {
+				s.Data = map[string][]byte{
+					PublicKeyDataKey: pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}),
+				}
+				return nil
+			},
+		)
+	}
+
+	It("should do nothing if the Module has no policy", func() {
+		mod.Spec.ModuleLoader.Container.Provenance = nil
+
+		img, err := v.Verify(ctx, mod, km)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(img).To(Equal(image))
+	})
+
+	It("should return an error if the public key cannot be fetched", func() {
+		clnt.EXPECT().Get(ctx, gomock.Any(), gomock.Any()).Return(errors.New("some error"))
+
+		_, err := v.Verify(ctx, mod, km)
+		Expect(err).To(HaveOccurred())
+		Expect(errors.Is(err, ErrVerificationFailed)).To(BeFalse())
+	})
+
+	It("should return an error if the attestations cannot be fetched", func() {
+		gomock.InOrder(
+			expectPublicKey(&key.PublicKey),
+			mockReg.EXPECT().GetAttestations(ctx, image, gomock.Any(), gomock.Any()).Return("", nil, errors.New("some error")),
+		)
+
+		_, err := v.Verify(ctx, mod, km)
+		Expect(err).To(HaveOccurred())
+		Expect(errors.Is(err, ErrVerificationFailed)).To(BeFalse())
+	})
+
+	DescribeTable("should check the attestations against the policy",
+		func(makeEnvelopes func() [][]byte, useOtherKey bool, expectedSuccess bool) {
+			pub := &key.PublicKey
+
+			if useOtherKey {
+				otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+				Expect(err).NotTo(HaveOccurred())
+
+				pub = &otherKey.PublicKey
+			}
+
+			expectPublicKey(pub)
+			mockReg.EXPECT().GetAttestations(ctx, image, gomock.Any(), gomock.Any()).Return(digest, makeEnvelopes(), nil)
+
+			img, err := v.Verify(ctx, mod, km)
+
+			if expectedSuccess {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(img).To(Equal("registry.example.com/org/kmod@" + digest))
+			} else {
+				Expect(errors.Is(err, ErrVerificationFailed)).To(BeTrue())
+			}
+		},
+		Entry(
+			"valid SLSA v0.2 provenance",
+			func() [][]byte {
+				return [][]byte{makeEnvelope(key, slsaProvenanceV02, v02Predicate(builderID, sourceRepo), "0123456789abcdef")}
+			},
+			false,
+			true,
+		),
+		Entry(
+			"valid SLSA v1 provenance",
+			func() [][]byte {
+				predicate := map[string]interface{}{
+					"buildDefinition": map[string]interface{}{
+						"resolvedDependencies": []map[string]string{{"uri": sourceRepo + "@refs/heads/main"}},
+					},
+					"runDetails": map[string]interface{}{
+						"builder": map[string]string{"id": builderID},
+					},
+				}
+
+				return [][]byte{makeEnvelope(key, slsaProvenanceV1, predicate, "0123456789abcdef")}
+			},
+			false,
+			true,
+		),
+		Entry(
+			"one valid attestation among invalid ones",
+			func() [][]byte {
+				return [][]byte{
+					[]byte("not JSON"),
+					makeEnvelope(key, slsaProvenanceV02, v02Predicate("other-builder", sourceRepo), "0123456789abcdef"),
+					makeEnvelope(key, slsaProvenanceV02, v02Predicate(builderID, sourceRepo), "0123456789abcdef"),
+				}
+			},
+			false,
+			true,
+		),
+		Entry(
+			"no attestation",
+			func() [][]byte { return nil },
+			false,
+			false,
+		),
+		Entry(
+			"signed with another key",
+			func() [][]byte {
+				return [][]byte{makeEnvelope(key, slsaProvenanceV02, v02Predicate(builderID, sourceRepo), "0123456789abcdef")}
+			},
+			true,
+			false,
+		),
+		Entry(
+			"unexpected builder",
+			func() [][]byte {
+				return [][]byte{makeEnvelope(key, slsaProvenanceV02, v02Predicate("other-builder", sourceRepo), "0123456789abcdef")}
+			},
+			false,
+			false,
+		),
+		Entry(
+			"unexpected source repository",
+			func() [][]byte {
+				return [][]byte{makeEnvelope(key, slsaProvenanceV02, v02Predicate(builderID, sourceRepo+"-fork"), "0123456789abcdef")}
+			},
+			false,
+			false,
+		),
+		Entry(
+			"attestation of another image",
+			func() [][]byte {
+				return [][]byte{makeEnvelope(key, slsaProvenanceV02, v02Predicate(builderID, sourceRepo), "fedcba9876543210")}
+			},
+			false,
+			false,
+		),
+		Entry(
+			"unsupported predicate type",
+			func() [][]byte {
+				return [][]byte{makeEnvelope(key, "https://example.com/predicate", v02Predicate(builderID, sourceRepo), "0123456789abcdef")}
+			},
+			false,
+			false,
+		),
+	)
+})
@@ -10,6 +10,7 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	v1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // MockRBACCreator is a mock of RBACCreator interface.
@@ -35,6 +36,20 @@ func (m *MockRBACCreator) EXPECT() *MockRBACCreatorMockRecorder {
 	return m.recorder
 }
 
+// CreateBuildServiceAccount mocks base method.
+func (m *MockRBACCreator) CreateBuildServiceAccount(ctx context.Context, mod v1beta1.Module, owner v1.Object) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBuildServiceAccount", ctx, mod, owner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateBuildServiceAccount indicates an expected call of CreateBuildServiceAccount.
+func (mr *MockRBACCreatorMockRecorder) CreateBuildServiceAccount(ctx, mod, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBuildServiceAccount", reflect.TypeOf((*MockRBACCreator)(nil).CreateBuildServiceAccount), ctx, mod, owner)
+}
+
 // CreateDevicePluginServiceAccount mocks base method.
 func (m *MockRBACCreator) CreateDevicePluginServiceAccount(ctx context.Context, mod v1beta1.Module) error {
 	m.ctrl.T.Helper()
@@ -7,18 +7,23 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/auth"
 )
 
 //go:generate mockgen -source=rbac.go -package=rbac -destination=mock_rbac.go
 
+// RBACCreator creates the ServiceAccounts used by the workloads of a Module.
+// None of those workloads needs to access the Kubernetes API, so the ServiceAccounts are not bound to any Role.
 type RBACCreator interface {
 	CreateModuleLoaderServiceAccount(ctx context.Context, mod kmmv1beta1.Module) error
 	CreateDevicePluginServiceAccount(ctx context.Context, mod kmmv1beta1.Module) error
+	CreateBuildServiceAccount(ctx context.Context, mod kmmv1beta1.Module, owner metav1.Object) error
 }
 
 type rbacCreator struct {
@@ -34,8 +39,6 @@ func NewCreator(client client.Client, scheme *runtime.Scheme) RBACCreator {
 }
 
 func (rc *rbacCreator) CreateModuleLoaderServiceAccount(ctx context.Context, mod kmmv1beta1.Module) error {
-	logger := log.FromContext(ctx)
-
 	sa := &corev1.ServiceAccount{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      GenerateModuleLoaderServiceAccountName(mod),
@@ -43,20 +46,10 @@ func (rc *rbacCreator) CreateModuleLoaderServiceAccount(ctx context.Context, mod
 		},
 	}
 
-	opRes, err := controllerutil.CreateOrPatch(ctx, rc.client, sa, func() error {
-		return controllerutil.SetControllerReference(&mod, sa, rc.scheme)
-	})
-	if err != nil {
-		return fmt.Errorf("cound not create/patch ServiceAccount: %w", err)
-	}
-	logger.Info("Created module-loader's ServiceAccount", "name", sa.Name, "result", opRes)
-
-	return nil
+	return rc.createServiceAccount(ctx, "module-loader", sa, &mod)
 }
 
 func (rc *rbacCreator) CreateDevicePluginServiceAccount(ctx context.Context, mod kmmv1beta1.Module) error {
-	logger := log.FromContext(ctx)
-
 	sa := &corev1.ServiceAccount{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      GenerateDevicePluginServiceAccountName(mod),
@@ -64,13 +57,41 @@ func (rc *rbacCreator) CreateDevicePluginServiceAccount(ctx context.Context, mod
 		},
 	}
 
+	return rc.createServiceAccount(ctx, "device-plugin", sa, &mod)
+}
+
+// CreateBuildServiceAccount creates the ServiceAccount used by the build and sign pods of mod.
+// As those pods never talk to the Kubernetes API, no token is mounted into them.
+// The ServiceAccount is bound to the workload identity of mod, if any.
+func (rc *rbacCreator) CreateBuildServiceAccount(ctx context.Context, mod kmmv1beta1.Module, owner metav1.Object) error {
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        GenerateBuildServiceAccountName(mod),
+			Namespace:   mod.Namespace,
+			Annotations: auth.WorkloadIdentityServiceAccountAnnotations(mod.Spec.WorkloadIdentity),
+		},
+		AutomountServiceAccountToken: pointer.Bool(false),
+	}
+
+	return rc.createServiceAccount(ctx, "build", sa, owner)
+}
+
+func (rc *rbacCreator) createServiceAccount(ctx context.Context, workload string, sa *corev1.ServiceAccount, owner metav1.Object) error {
+	logger := log.FromContext(ctx)
+
+	desired := sa.DeepCopy()
+
 	opRes, err := controllerutil.CreateOrPatch(ctx, rc.client, sa, func() error {
-		return controllerutil.SetControllerReference(&mod, sa, rc.scheme)
+		sa.AutomountServiceAccountToken = desired.AutomountServiceAccountToken
+		for k, v := range desired.Annotations {
+			metav1.SetMetaDataAnnotation(&sa.ObjectMeta, k, v)
+		}
+		return controllerutil.SetControllerReference(owner, sa, rc.scheme)
 	})
 	if err != nil {
 		return fmt.Errorf("cound not create/patch ServiceAccount: %w", err)
 	}
-	logger.Info("Created device-plugin's ServiceAccount", "name", sa.Name, "result", opRes)
+	logger.Info("Created "+workload+"'s ServiceAccount", "name", sa.Name, "result", opRes)
 
 	return nil
 }
@@ -82,3 +103,7 @@ func GenerateModuleLoaderServiceAccountName(mod kmmv1beta1.Module) string {
 func GenerateDevicePluginServiceAccountName(mod kmmv1beta1.Module) string {
 	return mod.Name + "-device-plugin"
 }
+
+func GenerateBuildServiceAccountName(mod kmmv1beta1.Module) string {
+	return mod.Name + "-build"
+}
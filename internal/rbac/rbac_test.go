@@ -197,6 +197,93 @@ var _ = Describe("CreateDevicePluginServiceAccount", func() {
 	})
 })
 
+var _ = Describe("CreateBuildServiceAccount", func() {
+	const (
+		moduleName = "test-module"
+		namespace  = "namespace"
+	)
+
+	var (
+		rc RBACCreator
+
+		mod kmmv1beta1.Module
+	)
+
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+		rc = NewCreator(clnt, scheme)
+
+		mod = kmmv1beta1.Module{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: kmmv1beta1.GroupVersion.String(),
+				Kind:       "Module",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      moduleName,
+				Namespace: namespace,
+			},
+		}
+	})
+
+	It("should add a build ServiceAccount without token", func() {
+		objectMeta := metav1.ObjectMeta{
+			Name:      moduleName + "-build",
+			Namespace: namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         mod.APIVersion,
+					BlockOwnerDeletion: pointer.Bool(true),
+					Controller:         pointer.Bool(true),
+					Kind:               mod.Kind,
+					Name:               moduleName,
+					UID:                mod.UID,
+				},
+			},
+		}
+		requestedObjectMeta := metav1.ObjectMeta{
+			Name:      moduleName + "-build",
+			Namespace: namespace,
+		}
+
+		gomock.InOrder(
+			clnt.EXPECT().Get(ctx, gomock.Any(), &corev1.ServiceAccount{ObjectMeta: requestedObjectMeta, AutomountServiceAccountToken: pointer.Bool(false)}).Return(apierrors.NewNotFound(schema.GroupResource{}, "whatever")),
+			clnt.EXPECT().Create(ctx, &corev1.ServiceAccount{ObjectMeta: objectMeta, AutomountServiceAccountToken: pointer.Bool(false)}).Return(nil),
+		)
+
+		err := rc.CreateBuildServiceAccount(ctx, mod, &mod)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should bind the build ServiceAccount to the Module's workload identity", func() {
+		mod.Spec.WorkloadIdentity = &kmmv1beta1.WorkloadIdentity{
+			Provider: kmmv1beta1.WorkloadIdentityProviderAWS,
+			Identity: "arn:aws:iam::123456789012:role/kmm-build",
+		}
+
+		gomock.InOrder(
+			clnt.EXPECT().Get(ctx, gomock.Any(), gomock.Any()).Return(apierrors.NewNotFound(schema.GroupResource{}, "whatever")),
+			clnt.EXPECT().Create(ctx, gomock.Any()).Do(func(_ interface{}, sa *corev1.ServiceAccount, _ ...interface{}) {
+				Expect(sa.Annotations).To(
+					HaveKeyWithValue("eks.amazonaws.com/role-arn", "arn:aws:iam::123456789012:role/kmm-build"),
+				)
+			}),
+		)
+
+		err := rc.CreateBuildServiceAccount(ctx, mod, &mod)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should return an error when the ServiceAccount fetch fails", func() {
+		clnt.EXPECT().Get(ctx, gomock.Any(), gomock.Any()).Return(errors.New("some-error"))
+
+		err := rc.CreateBuildServiceAccount(ctx, mod, &mod)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
 var _ = Describe("GenerateModuleLoaderServiceAccountName", func() {
 	mod := kmmv1beta1.Module{
 		ObjectMeta: metav1.ObjectMeta{Name: "test-module", Namespace: "namespace"},
@@ -216,3 +303,13 @@ var _ = Describe("GenerateDevicePluginServiceAccountName", func() {
 		Expect(GenerateDevicePluginServiceAccountName(mod)).To(Equal("test-module-device-plugin"))
 	})
 })
+
+var _ = Describe("GenerateBuildServiceAccountName", func() {
+	mod := kmmv1beta1.Module{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-module", Namespace: "namespace"},
+	}
+
+	It("should return the build ServiceAccount name", func() {
+		Expect(GenerateBuildServiceAccountName(mod)).To(Equal("test-module-build"))
+	})
+})
@@ -0,0 +1,77 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/auth"
+	"k8s.io/apimachinery/pkg/util/cache"
+)
+
+type cachingRegistry struct {
+	Registry
+
+	existingImages *cache.Expiring
+	ttl            time.Duration
+}
+
+// NewCachingRegistry returns a Registry that delegates to reg, but remembers for ttl the images that ImageExists found.
+// Only positive results are cached: an image that is missing, for example because it is still being built, is looked
+// up in the registry again on the next call.
+// Results are only shared between calls made with the same credentials, so that a Module cannot learn that an image
+// exists through the credentials of another Module.
+func NewCachingRegistry(reg Registry, ttl time.Duration) Registry {
+	return &cachingRegistry{
+		Registry:       reg,
+		existingImages: cache.NewExpiring(),
+		ttl:            ttl,
+	}
+}
+
+func (cr *cachingRegistry) ImageExists(ctx context.Context, image string, tlsOptions *kmmv1beta1.TLSOptions, registryAuthGetter auth.RegistryAuthGetter) (bool, error) {
+	key := imageCacheKey(image, tlsOptions, registryAuthGetter)
+
+	if _, ok := cr.existingImages.Get(key); ok {
+		return true, nil
+	}
+
+	exists, err := cr.Registry.ImageExists(ctx, image, tlsOptions, registryAuthGetter)
+	if err == nil && exists {
+		cr.existingImages.Set(key, struct{}{}, cr.ttl)
+	}
+
+	return exists, err
+}
+
+// DeleteImage deletes image and forgets that it exists.
+func (cr *cachingRegistry) DeleteImage(ctx context.Context, image string, tlsOptions *kmmv1beta1.TLSOptions, registryAuthGetter auth.RegistryAuthGetter) error {
+	if err := cr.Registry.DeleteImage(ctx, image, tlsOptions, registryAuthGetter); err != nil {
+		return err
+	}
+
+	cr.existingImages.Delete(imageCacheKey(image, tlsOptions, registryAuthGetter))
+
+	return nil
+}
+
+func imageCacheKey(image string, tlsOptions *kmmv1beta1.TLSOptions, registryAuthGetter auth.RegistryAuthGetter) string {
+	if tlsOptions == nil {
+		tlsOptions = &kmmv1beta1.TLSOptions{}
+	}
+
+	identity := "anonymous"
+
+	if registryAuthGetter != nil {
+		identity = registryAuthGetter.Identity()
+	}
+
+	return fmt.Sprintf(
+		"%s|insecure=%t|insecureSkipTLSVerify=%t|auth=%s",
+		image,
+		tlsOptions.Insecure,
+		tlsOptions.InsecureSkipTLSVerify,
+		identity,
+	)
+}
@@ -0,0 +1,154 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/auth"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("cachingRegistry_ImageExists", func() {
+	const image = "example.com/org/image:tag"
+
+	var (
+		ctrl    *gomock.Controller
+		mockReg *MockRegistry
+		reg     Registry
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockReg = NewMockRegistry(ctrl)
+		reg = NewCachingRegistry(mockReg, time.Hour)
+	})
+
+	ctx := context.Background()
+
+	It("should only query the registry once for an existing image", func() {
+		mockReg.EXPECT().ImageExists(ctx, image, nil, nil).Return(true, nil)
+
+		for i := 0; i < 2; i++ {
+			exists, err := reg.ImageExists(ctx, image, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeTrue())
+		}
+	})
+
+	It("should query the registry each time for a missing image", func() {
+		mockReg.EXPECT().ImageExists(ctx, image, nil, nil).Return(false, nil).Times(2)
+
+		for i := 0; i < 2; i++ {
+			exists, err := reg.ImageExists(ctx, image, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeFalse())
+		}
+	})
+
+	It("should not cache errors", func() {
+		gomock.InOrder(
+			mockReg.EXPECT().ImageExists(ctx, image, nil, nil).Return(false, errors.New("some error")),
+			mockReg.EXPECT().ImageExists(ctx, image, nil, nil).Return(true, nil),
+		)
+
+		_, err := reg.ImageExists(ctx, image, nil, nil)
+		Expect(err).To(HaveOccurred())
+
+		exists, err := reg.ImageExists(ctx, image, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exists).To(BeTrue())
+	})
+
+	It("should not share cached results between different TLS options", func() {
+		tlsOptions := &kmmv1beta1.TLSOptions{Insecure: true}
+
+		gomock.InOrder(
+			mockReg.EXPECT().ImageExists(ctx, image, nil, nil).Return(true, nil),
+			mockReg.EXPECT().ImageExists(ctx, image, tlsOptions, nil).Return(true, nil),
+		)
+
+		for _, o := range []*kmmv1beta1.TLSOptions{nil, tlsOptions} {
+			exists, err := reg.ImageExists(ctx, image, o, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeTrue())
+		}
+	})
+
+	It("should not share cached results between different credentials", func() {
+		authGetter1 := auth.NewMockRegistryAuthGetter(ctrl)
+		authGetter2 := auth.NewMockRegistryAuthGetter(ctrl)
+
+		authGetter1.EXPECT().Identity().Return("secret:ns1/pull-secret").AnyTimes()
+		authGetter2.EXPECT().Identity().Return("secret:ns2/pull-secret").AnyTimes()
+
+		gomock.InOrder(
+			mockReg.EXPECT().ImageExists(ctx, image, nil, authGetter1).Return(true, nil),
+			mockReg.EXPECT().ImageExists(ctx, image, nil, authGetter2).Return(false, nil),
+			mockReg.EXPECT().ImageExists(ctx, image, nil, nil).Return(false, nil),
+		)
+
+		exists, err := reg.ImageExists(ctx, image, nil, authGetter1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exists).To(BeTrue())
+
+		exists, err = reg.ImageExists(ctx, image, nil, authGetter2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exists).To(BeFalse())
+
+		exists, err = reg.ImageExists(ctx, image, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exists).To(BeFalse())
+	})
+
+	It("should query the registry again once the entry expired", func() {
+		reg = NewCachingRegistry(mockReg, time.Nanosecond)
+
+		mockReg.EXPECT().ImageExists(ctx, image, nil, nil).Return(true, nil).Times(2)
+
+		for i := 0; i < 2; i++ {
+			exists, err := reg.ImageExists(ctx, image, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeTrue())
+
+			time.Sleep(time.Millisecond)
+		}
+	})
+})
+
+var _ = Describe("cachingRegistry_DeleteImage", func() {
+	const image = "example.com/org/image:tag"
+
+	var (
+		mockReg *MockRegistry
+		reg     Registry
+	)
+
+	BeforeEach(func() {
+		mockReg = NewMockRegistry(gomock.NewController(GinkgoT()))
+		reg = NewCachingRegistry(mockReg, time.Hour)
+	})
+
+	ctx := context.Background()
+
+	It("should forget that a deleted image exists", func() {
+		gomock.InOrder(
+			mockReg.EXPECT().ImageExists(ctx, image, nil, nil).Return(true, nil),
+			mockReg.EXPECT().DeleteImage(ctx, image, nil, nil),
+			mockReg.EXPECT().ImageExists(ctx, image, nil, nil).Return(false, nil),
+		)
+
+		exists, err := reg.ImageExists(ctx, image, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exists).To(BeTrue())
+
+		Expect(reg.DeleteImage(ctx, image, nil, nil)).To(Succeed())
+
+		exists, err = reg.ImageExists(ctx, image, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exists).To(BeFalse())
+	})
+})
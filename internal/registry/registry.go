@@ -50,7 +50,10 @@ type RepoPullConfig struct {
 
 type Registry interface {
 	ImageExists(ctx context.Context, image string, tlsOptions *kmmv1beta1.TLSOptions, registryAuthGetter auth.RegistryAuthGetter) (bool, error)
+	DeleteImage(ctx context.Context, image string, tlsOptions *kmmv1beta1.TLSOptions, registryAuthGetter auth.RegistryAuthGetter) error
+	GetDigest(ctx context.Context, image string, tlsOptions *kmmv1beta1.TLSOptions, registryAuthGetter auth.RegistryAuthGetter) (string, error)
 	VerifyModuleExists(layer v1.Layer, pathPrefix, kernelVersion, moduleFileName string) bool
+	GetAttestations(ctx context.Context, image string, tlsOptions *kmmv1beta1.TLSOptions, registryAuthGetter auth.RegistryAuthGetter) (string, [][]byte, error)
 	GetLayersDigests(ctx context.Context, image string, tlsOptions *kmmv1beta1.TLSOptions, registryAuthGetter auth.RegistryAuthGetter) ([]string, *RepoPullConfig, error)
 	GetLayerByDigest(digest string, pullConfig *RepoPullConfig) (v1.Layer, error)
 	WriteImageByName(imageName string, image v1.Image, auth authn.Authenticator) error
@@ -82,6 +85,37 @@ func (r *registry) ImageExists(ctx context.Context, image string, tlsOptions *km
 	return true, nil
 }
 
+// DeleteImage deletes the manifest that image points to from its registry, along with all the tags referencing it.
+// Registries only delete manifests by digest, so a tag is first resolved to its digest.
+// An image that does not exist is not an error.
+func (r *registry) DeleteImage(ctx context.Context, image string, tlsOptions *kmmv1beta1.TLSOptions, registryAuthGetter auth.RegistryAuthGetter) error {
+	pullConfig, err := r.getPullOptions(ctx, image, tlsOptions, registryAuthGetter)
+	if err != nil {
+		return fmt.Errorf("failed to get pull options for image %s: %w", image, err)
+	}
+
+	digest, err := r.getDigest(image, pullConfig)
+	if err != nil || digest == "" {
+		return err
+	}
+
+	if err = crane.Delete(pullConfig.repo+"@"+digest, pullConfig.authOptions...); err != nil {
+		return fmt.Errorf("could not delete image %s: %w", image, err)
+	}
+
+	return nil
+}
+
+// GetDigest returns the digest of the manifest that image points to, or an empty string if image does not exist.
+func (r *registry) GetDigest(ctx context.Context, image string, tlsOptions *kmmv1beta1.TLSOptions, registryAuthGetter auth.RegistryAuthGetter) (string, error) {
+	pullConfig, err := r.getPullOptions(ctx, image, tlsOptions, registryAuthGetter)
+	if err != nil {
+		return "", fmt.Errorf("failed to get pull options for image %s: %w", image, err)
+	}
+
+	return r.getDigest(image, pullConfig)
+}
+
 func (r *registry) GetLayersDigests(ctx context.Context, image string, tlsOptions *kmmv1beta1.TLSOptions, registryAuthGetter auth.RegistryAuthGetter) ([]string, *RepoPullConfig, error) {
 	manifest, pullConfig, err := r.getImageManifest(ctx, image, tlsOptions, registryAuthGetter)
 	if err != nil {
@@ -96,6 +130,61 @@ func (r *registry) GetLayersDigests(ctx context.Context, image string, tlsOption
 	return digests, pullConfig, nil
 }
 
+// GetAttestations returns the digest of image and the DSSE envelopes attached to it under the tag that cosign uses
+// for attestations (sha256-<digest>.att). No envelope is returned if that tag does not exist.
+func (r *registry) GetAttestations(ctx context.Context, image string, tlsOptions *kmmv1beta1.TLSOptions, registryAuthGetter auth.RegistryAuthGetter) (string, [][]byte, error) {
+	pullConfig, err := r.getPullOptions(ctx, image, tlsOptions, registryAuthGetter)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get pull options for image %s: %w", image, err)
+	}
+
+	ref, err := r.ParseReference(image)
+	if err != nil {
+		return "", nil, err
+	}
+
+	digest, err := crane.Digest(image, pullConfig.authOptions...)
+	if err != nil {
+		return "", nil, fmt.Errorf("could not get the digest of image %s: %w", image, err)
+	}
+
+	attTag := ref.Context().Tag(strings.Replace(digest, ":", "-", 1) + ".att")
+
+	att, err := crane.Pull(attTag.String(), pullConfig.authOptions...)
+	if err != nil {
+		te := &transport.Error{}
+		if errors.As(err, &te) && te.StatusCode == http.StatusNotFound {
+			return digest, nil, nil
+		}
+		return "", nil, fmt.Errorf("could not get attestations %s: %w", attTag, err)
+	}
+
+	layers, err := att.Layers()
+	if err != nil {
+		return "", nil, fmt.Errorf("could not get the layers of attestations %s: %w", attTag, err)
+	}
+
+	envelopes := make([][]byte, 0, len(layers))
+
+	for _, l := range layers {
+		// Attestation layers are not compressed: the blob is the envelope itself.
+		rc, err := l.Compressed()
+		if err != nil {
+			return "", nil, fmt.Errorf("could not read a layer of attestations %s: %w", attTag, err)
+		}
+
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return "", nil, fmt.Errorf("could not read a layer of attestations %s: %w", attTag, err)
+		}
+
+		envelopes = append(envelopes, b)
+	}
+
+	return digest, envelopes, nil
+}
+
 func (r *registry) GetLayerByDigest(digest string, pullConfig *RepoPullConfig) (v1.Layer, error) {
 	return crane.PullLayer(pullConfig.repo+"@"+digest, pullConfig.authOptions...)
 }
@@ -153,6 +242,19 @@ func (r *registry) getPullOptions(ctx context.Context, image string, tlsOptions
 	return &RepoPullConfig{repo: repo, authOptions: options}, nil
 }
 
+func (r *registry) getDigest(image string, pullConfig *RepoPullConfig) (string, error) {
+	digest, err := crane.Digest(image, pullConfig.authOptions...)
+	if err != nil {
+		te := &transport.Error{}
+		if errors.As(err, &te) && te.StatusCode == http.StatusNotFound {
+			return "", nil
+		}
+		return "", fmt.Errorf("could not get the digest of image %s: %w", image, err)
+	}
+
+	return digest, nil
+}
+
 func (r *registry) getImageManifest(ctx context.Context, image string, tlsOptions *kmmv1beta1.TLSOptions, registryAuthGetter auth.RegistryAuthGetter) ([]byte, *RepoPullConfig, error) {
 	pullConfig, err := r.getPullOptions(ctx, image, tlsOptions, registryAuthGetter)
 	if err != nil {
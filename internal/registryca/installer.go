@@ -0,0 +1,305 @@
+package registryca
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/kubernetes-sigs/kernel-module-management/internal/utils"
+)
+
+const (
+	// InstallerName is the name of the DaemonSet installing the registry CA bundles on nodes.
+	InstallerName = "kmm-registry-ca-installer"
+
+	caVolumeName = "registry-ca"
+	caMountPath  = "/registry-ca"
+
+	// installPeriodSeconds is how often the bundles are copied again, so that changes to the ConfigMap, which the
+	// kubelet propagates to the mounted volume, reach the nodes.
+	installPeriodSeconds = 60
+
+	// markerFile is created next to each bundle installed by the DaemonSet, so that only those bundles are removed
+	// once their registry is removed from the ConfigMap.
+	markerFile = ".kmm-registry-ca"
+
+	// uninstalledFile is created by the uninstaller once it removed the bundles of the node.
+	uninstalledFile = "/tmp/uninstalled"
+)
+
+// certsDirs are the directories in which CRI-O and containerd look for the CA bundle of a registry, in a subdirectory
+// named after the host and port of the registry.
+var certsDirs = []struct {
+	volumeName string
+	path       string
+}{
+	{volumeName: "crio-certs", path: "/etc/containers/certs.d"},
+	{volumeName: "containerd-certs", path: "/etc/containerd/certs.d"},
+}
+
+// removeScript returns the shell commands removing from dir the bundles that the DaemonSet installed, except those
+// for which the keep condition succeeds, if any.
+func removeScript(dir, keep string) string {
+	if keep != "" {
+		keep += " && continue; "
+	}
+
+	return fmt.Sprintf(
+		"  for m in %s/*/%s; do [ -f \"$m\" ] || continue; d=$(dirname \"$m\"); %srm -f \"$d/ca.crt\" \"$m\"; rmdir \"$d\" 2>/dev/null; done\n",
+		dir,
+		markerFile,
+		keep,
+	)
+}
+
+// installScript copies the bundles of the ConfigMap to the certificate directories, and removes those of the
+// registries that are not in the ConfigMap anymore.
+func installScript() string {
+	script := "while true; do\n"
+
+	for _, cd := range certsDirs {
+		script += fmt.Sprintf(
+			"  for f in %s/*; do [ -f \"$f\" ] || continue; d=%s/$(basename \"$f\" | tr _ :); mkdir -p \"$d\" && cp -f \"$f\" \"$d/ca.crt\" && touch \"$d/%s\"; done\n",
+			caMountPath,
+			cd.path,
+			markerFile,
+		)
+
+		script += removeScript(cd.path, fmt.Sprintf(`[ -f "%s/$(basename "$d" | tr : _)" ]`, caMountPath))
+	}
+
+	return script + fmt.Sprintf("  sleep %d\ndone\n", installPeriodSeconds)
+}
+
+// uninstallScript removes all the bundles installed by the DaemonSet, and then waits to be deleted.
+func uninstallScript() string {
+	script := ""
+
+	for _, cd := range certsDirs {
+		script += removeScript(cd.path, "")
+	}
+
+	return script + fmt.Sprintf("touch %s\ntrap 'exit 0' TERM\nwhile true; do sleep 3600 & wait $!; done\n", uninstalledFile)
+}
+
+//+kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=create;delete;get;patch
+
+// InstallerCreator is a manager.Runnable that creates a DaemonSet installing the CA bundles of a ConfigMap into the
+// registry certificate directories of the container runtime of each node, so that images can be pulled from registries
+// using private CAs without disabling TLS verification.
+// Each key of the ConfigMap is the host of a registry, with an underscore instead of the colon before its port, as
+// ConfigMap keys cannot contain colons; its value is the PEM-encoded CA bundle of the registry.
+type InstallerCreator struct {
+	client         client.Client
+	namespace      string
+	configMapName  string
+	installerImage string
+	logger         logr.Logger
+}
+
+// NewInstallerCreator returns an InstallerCreator for the configMapName ConfigMap in namespace, in which the DaemonSet
+// is also created.
+// installerImage is run by the DaemonSet, privileged; it must be referenced by digest.
+func NewInstallerCreator(client client.Client, namespace, configMapName, installerImage string, logger logr.Logger) *InstallerCreator {
+	return &InstallerCreator{
+		client:         client,
+		namespace:      namespace,
+		configMapName:  configMapName,
+		installerImage: installerImage,
+		logger:         logger,
+	}
+}
+
+// Start creates or updates the DaemonSet once.
+func (ic *InstallerCreator) Start(ctx context.Context) error {
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      InstallerName,
+			Namespace: ic.namespace,
+		},
+	}
+
+	opRes, err := controllerutil.CreateOrPatch(ctx, ic.client, ds, func() error {
+		setDaemonSetSpec(ds, ic.installerImage, ic.configMapName)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("could not create/patch the registry CA installer DaemonSet: %v", err)
+	}
+	ic.logger.Info("Created the registry CA installer DaemonSet", "name", ds.Name, "result", opRes)
+
+	return nil
+}
+
+// setDaemonSetSpec sets the spec of ds so that it installs the bundles of the configMapName ConfigMap, or uninstalls
+// all bundles if configMapName is empty.
+func setDaemonSetSpec(ds *appsv1.DaemonSet, image, configMapName string) {
+	labels := map[string]string{"app.kubernetes.io/name": InstallerName}
+
+	ds.SetLabels(labels)
+
+	var (
+		volumes      []v1.Volume
+		volumeMounts []v1.VolumeMount
+		script       = uninstallScript()
+		// The uninstaller is only ready once it removed the bundles.
+		readinessCommand = []string{"test", "-f", uninstalledFile}
+	)
+
+	if configMapName != "" {
+		volumes = append(volumes, v1.Volume{
+			Name: caVolumeName,
+			VolumeSource: v1.VolumeSource{
+				ConfigMap: &v1.ConfigMapVolumeSource{
+					LocalObjectReference: v1.LocalObjectReference{Name: configMapName},
+				},
+			},
+		})
+
+		volumeMounts = append(volumeMounts, v1.VolumeMount{
+			Name:      caVolumeName,
+			MountPath: caMountPath,
+			ReadOnly:  true,
+		})
+
+		script = installScript()
+		readinessCommand = []string{"true"}
+	}
+
+	directoryOrCreate := v1.HostPathDirectoryOrCreate
+
+	for _, cd := range certsDirs {
+		volumes = append(volumes, v1.Volume{
+			Name: cd.volumeName,
+			VolumeSource: v1.VolumeSource{
+				HostPath: &v1.HostPathVolumeSource{Path: cd.path, Type: &directoryOrCreate},
+			},
+		})
+
+		volumeMounts = append(volumeMounts, v1.VolumeMount{Name: cd.volumeName, MountPath: cd.path})
+	}
+
+	ds.Spec = appsv1.DaemonSetSpec{
+		Selector: &metav1.LabelSelector{MatchLabels: labels},
+		Template: v1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: labels},
+			Spec: v1.PodSpec{
+				AutomountServiceAccountToken: pointer.Bool(false),
+				Containers: []v1.Container{
+					{
+						Name:    "installer",
+						Image:   image,
+						Command: []string{"/bin/sh", "-c", script},
+						ReadinessProbe: &v1.Probe{
+							ProbeHandler: v1.ProbeHandler{
+								Exec: &v1.ExecAction{Command: readinessCommand},
+							},
+						},
+						// Writing to the host's /etc requires bypassing SELinux.
+						SecurityContext: &v1.SecurityContext{Privileged: pointer.Bool(true)},
+						VolumeMounts:    volumeMounts,
+					},
+				},
+				NodeSelector:      map[string]string{"kubernetes.io/os": "linux"},
+				PriorityClassName: "system-node-critical",
+				// Images may be pulled on any node.
+				Tolerations: []v1.Toleration{{Operator: v1.TolerationOpExists}},
+				Volumes:     volumes,
+			},
+		},
+	}
+
+	utils.SetDaemonSetDefaults(&ds.Spec)
+}
+
+// Uninstaller is a manager.Runnable that removes the bundles installed by the InstallerCreator's DaemonSet from all
+// nodes, and then deletes the DaemonSet.
+// It is run when no registry CA ConfigMap is configured, so that the bundles are removed once the operator stops
+// installing them.
+type Uninstaller struct {
+	client    client.Client
+	namespace string
+	period    time.Duration
+	logger    logr.Logger
+}
+
+// NewUninstaller returns an Uninstaller checking every period whether the bundles were removed from all nodes.
+func NewUninstaller(client client.Client, namespace string, period time.Duration, logger logr.Logger) *Uninstaller {
+	return &Uninstaller{
+		client:    client,
+		namespace: namespace,
+		period:    period,
+		logger:    logger,
+	}
+}
+
+// Start uninstalls the bundles from all nodes, if the InstallerCreator ever installed them.
+func (u *Uninstaller) Start(ctx context.Context) error {
+	return wait.PollImmediateUntilWithContext(ctx, u.period, func(ctx context.Context) (bool, error) {
+		done, err := u.uninstall(ctx)
+		if err != nil {
+			u.logger.Error(err, "failed to uninstall the registry CA bundles")
+		}
+
+		return done, nil
+	})
+}
+
+func (u *Uninstaller) uninstall(ctx context.Context) (bool, error) {
+	ds := &appsv1.DaemonSet{}
+
+	if err := u.client.Get(ctx, types.NamespacedName{Namespace: u.namespace, Name: InstallerName}, ds); err != nil {
+		return apierrors.IsNotFound(err), client.IgnoreNotFound(err)
+	}
+
+	if len(ds.Spec.Template.Spec.Containers) == 0 {
+		return false, fmt.Errorf("DaemonSet %s has no container", InstallerName)
+	}
+
+	if c := ds.Spec.Template.Spec.Containers[0]; len(c.Command) == 0 || c.Command[len(c.Command)-1] != uninstallScript() {
+		// The image that already runs on nodes is reused, as no installer image may be configured anymore.
+		image := c.Image
+
+		if _, err := controllerutil.CreateOrPatch(ctx, u.client, ds, func() error {
+			setDaemonSetSpec(ds, image, "")
+			return nil
+		}); err != nil {
+			return false, fmt.Errorf("could not patch the registry CA installer DaemonSet: %v", err)
+		}
+
+		u.logger.Info("Uninstalling the registry CA bundles from all nodes")
+
+		return false, nil
+	}
+
+	if ds.Status.ObservedGeneration < ds.Generation ||
+		ds.Status.UpdatedNumberScheduled != ds.Status.DesiredNumberScheduled ||
+		ds.Status.NumberReady != ds.Status.DesiredNumberScheduled {
+		u.logger.V(1).Info(
+			"Waiting for the registry CA bundles to be uninstalled",
+			"ready", ds.Status.NumberReady,
+			"desired", ds.Status.DesiredNumberScheduled,
+		)
+
+		return false, nil
+	}
+
+	if err := u.client.Delete(ctx, ds); client.IgnoreNotFound(err) != nil {
+		return false, fmt.Errorf("could not delete the registry CA installer DaemonSet: %v", err)
+	}
+
+	u.logger.Info("Uninstalled the registry CA bundles from all nodes")
+
+	return true, nil
+}
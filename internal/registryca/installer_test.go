@@ -0,0 +1,170 @@
+package registryca
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubernetes-sigs/kernel-module-management/internal/client"
+)
+
+const (
+	namespace      = "kmm-operator-system"
+	installerImage = "example.com/installer@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+)
+
+var _ = Describe("InstallerCreator_Start", func() {
+	var (
+		ctrl *gomock.Controller
+		clnt *client.MockClient
+		ic   *InstallerCreator
+	)
+
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+		ic = NewInstallerCreator(clnt, namespace, "registry-cas", installerImage, logr.Discard())
+	})
+
+	It("should create the DaemonSet", func() {
+		gomock.InOrder(
+			clnt.
+				EXPECT().
+				Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(&appsv1.DaemonSet{})).
+				Return(apierrors.NewNotFound(schema.GroupResource{}, InstallerName)),
+			clnt.EXPECT().Create(ctx, gomock.Any()).Do(func(_ context.Context, ds *appsv1.DaemonSet, _ ...interface{}) {
+				Expect(ds.Namespace).To(Equal(namespace))
+
+				podSpec := ds.Spec.Template.Spec
+
+				Expect(podSpec.Containers[0].Image).To(Equal(installerImage))
+				Expect(*podSpec.AutomountServiceAccountToken).To(BeFalse())
+
+				Expect(podSpec.Volumes).To(HaveLen(3))
+				Expect(podSpec.Volumes[0].ConfigMap.Name).To(Equal("registry-cas"))
+				Expect(podSpec.Volumes[1].HostPath.Path).To(Equal("/etc/containers/certs.d"))
+				Expect(podSpec.Volumes[2].HostPath.Path).To(Equal("/etc/containerd/certs.d"))
+
+				script := podSpec.Containers[0].Command[2]
+
+				Expect(script).To(ContainSubstring(`d=/etc/containers/certs.d/$(basename "$f" | tr _ :)`))
+				Expect(script).To(ContainSubstring(`d=/etc/containerd/certs.d/$(basename "$f" | tr _ :)`))
+				Expect(script).To(ContainSubstring(`for m in /etc/containers/certs.d/*/.kmm-registry-ca`))
+			}),
+		)
+
+		Expect(ic.Start(ctx)).To(Succeed())
+	})
+
+	It("should return an error if the DaemonSet could not be created", func() {
+		gomock.InOrder(
+			clnt.
+				EXPECT().
+				Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(&appsv1.DaemonSet{})).
+				Return(apierrors.NewNotFound(schema.GroupResource{}, InstallerName)),
+			clnt.EXPECT().Create(ctx, gomock.Any()).Return(errors.New("some error")),
+		)
+
+		Expect(ic.Start(ctx)).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Uninstaller_uninstall", func() {
+	var (
+		ctrl *gomock.Controller
+		clnt *client.MockClient
+		u    *Uninstaller
+	)
+
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+		u = NewUninstaller(clnt, namespace, 0, logr.Discard())
+	})
+
+	installer := func(configMapName string) *appsv1.DaemonSet {
+		ds := appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: InstallerName, Namespace: namespace},
+		}
+
+		setDaemonSetSpec(&ds, installerImage, configMapName)
+
+		return &ds
+	}
+
+	It("should do nothing if there is no DaemonSet", func() {
+		clnt.
+			EXPECT().
+			Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(&appsv1.DaemonSet{})).
+			Return(apierrors.NewNotFound(schema.GroupResource{}, InstallerName))
+
+		Expect(u.uninstall(ctx)).To(BeTrue())
+	})
+
+	It("should run the uninstaller with the image of the installer", func() {
+		getInstaller := func(_ interface{}, _ interface{}, ds *appsv1.DaemonSet, _ ...interface{}) error {
+			installer("registry-cas").DeepCopyInto(ds)
+			return nil
+		}
+
+		gomock.InOrder(
+			clnt.EXPECT().Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(&appsv1.DaemonSet{})).DoAndReturn(getInstaller),
+			clnt.EXPECT().Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(&appsv1.DaemonSet{})).DoAndReturn(getInstaller),
+			clnt.EXPECT().Patch(ctx, gomock.Any(), gomock.Any()).Do(func(_ context.Context, ds *appsv1.DaemonSet, _ ctrlclient.Patch, _ ...interface{}) {
+				podSpec := ds.Spec.Template.Spec
+
+				Expect(podSpec.Containers[0].Image).To(Equal(installerImage))
+				Expect(podSpec.Containers[0].Command[2]).To(Equal(uninstallScript()))
+				Expect(podSpec.Volumes).To(HaveLen(2))
+			}),
+		)
+
+		Expect(u.uninstall(ctx)).To(BeFalse())
+	})
+
+	It("should wait for the uninstaller to be ready on all nodes", func() {
+		ds := installer("")
+		ds.Status = appsv1.DaemonSetStatus{DesiredNumberScheduled: 2, UpdatedNumberScheduled: 2, NumberReady: 1}
+
+		clnt.
+			EXPECT().
+			Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(&appsv1.DaemonSet{})).
+			DoAndReturn(func(_ interface{}, _ interface{}, obj *appsv1.DaemonSet, _ ...interface{}) error {
+				ds.DeepCopyInto(obj)
+				return nil
+			})
+
+		Expect(u.uninstall(ctx)).To(BeFalse())
+	})
+
+	It("should delete the DaemonSet once the uninstaller is ready on all nodes", func() {
+		ds := installer("")
+		ds.Status = appsv1.DaemonSetStatus{DesiredNumberScheduled: 2, UpdatedNumberScheduled: 2, NumberReady: 2}
+
+		gomock.InOrder(
+			clnt.
+				EXPECT().
+				Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(&appsv1.DaemonSet{})).
+				DoAndReturn(func(_ interface{}, _ interface{}, obj *appsv1.DaemonSet, _ ...interface{}) error {
+					ds.DeepCopyInto(obj)
+					return nil
+				}),
+			clnt.EXPECT().Delete(ctx, gomock.AssignableToTypeOf(&appsv1.DaemonSet{})),
+		)
+
+		Expect(u.uninstall(ctx)).To(BeTrue())
+	})
+})
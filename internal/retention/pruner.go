@@ -0,0 +1,450 @@
+package retention
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/auth"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/metrics"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/registry"
+)
+
+// ConfigMapName is the name of the ConfigMap in which the ImagePruner records, for each Module, since when its
+// kernels are absent from the cluster.
+const ConfigMapName = "kmm-image-retention"
+
+//+kubebuilder:rbac:groups="core",resources=configmaps,verbs=create;get;patch
+//+kubebuilder:rbac:groups="core",resources=nodes,verbs=list;watch
+//+kubebuilder:rbac:groups=kmm.sigs.x-k8s.io,resources=modules,verbs=list;watch
+
+// Policy defines which images built or signed by KMM are pruned.
+type Policy struct {
+	// MaxAbsence is how long no node targeted by a Module must have run a kernel for its images to be pruned.
+	MaxAbsence time.Duration
+	// Keep is the number of most recently absent kernels of each Module whose images are never pruned.
+	Keep int
+	// DryRun only logs and counts the images that would be pruned.
+	DryRun bool
+}
+
+// kernelRecord is what the ImagePruner remembers about a kernel of a Module.
+type kernelRecord struct {
+	// AbsentSince is when the last node targeted by the Module stopped running the kernel; nil while nodes run it.
+	AbsentSince *metav1.Time `json:"absentSince,omitempty"`
+	// DryRunReported is true once the images of the kernel were reported in dry-run mode, so that they are only
+	// reported once.
+	DryRunReported bool `json:"dryRunReported,omitempty"`
+	// Images are the images that KMM built or signed for the kernel while nodes ran it; only those are pruned.
+	Images []string `json:"images,omitempty"`
+}
+
+// inUseImages are the images that the nodes run or may run, with the Module and TLS options to resolve them with.
+type inUseImages struct {
+	sources map[string]imageSource
+	// digests are the digests of the images, resolved when the first image is about to be pruned.
+	digests sets.String
+	// err is why the digests could not be resolved.
+	err error
+}
+
+type imageSource struct {
+	mod        *kmmv1beta1.Module
+	tlsOptions *kmmv1beta1.TLSOptions
+}
+
+// ImagePruner is a manager.Runnable that deletes from their registry the images that KMM built or signed for kernels
+// that the nodes targeted by their Module no longer run, according to a Policy.
+// Only the images recorded while nodes ran a kernel with a build or sign section are deleted, and never while another
+// image in use points to the same manifest.
+type ImagePruner struct {
+	client      client.Client
+	kernelAPI   module.KernelMapper
+	registryAPI registry.Registry
+	metricsAPI  metrics.Metrics
+	namespace   string
+	policy      Policy
+	period      time.Duration
+	logger      logr.Logger
+
+	now func() time.Time
+}
+
+// NewImagePruner returns an ImagePruner keeping its ConfigMap in namespace and applying policy every period.
+func NewImagePruner(
+	client client.Client,
+	kernelAPI module.KernelMapper,
+	registryAPI registry.Registry,
+	metricsAPI metrics.Metrics,
+	namespace string,
+	policy Policy,
+	period time.Duration,
+	logger logr.Logger,
+) *ImagePruner {
+	return &ImagePruner{
+		client:      client,
+		kernelAPI:   kernelAPI,
+		registryAPI: registryAPI,
+		metricsAPI:  metricsAPI,
+		namespace:   namespace,
+		policy:      policy,
+		period:      period,
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+// Start prunes images every period until ctx is done.
+func (ip *ImagePruner) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, ip.prune, ip.period)
+	return nil
+}
+
+func (ip *ImagePruner) prune(ctx context.Context) {
+	nodes := v1.NodeList{}
+
+	if err := ip.client.List(ctx, &nodes); err != nil {
+		ip.logger.Error(err, "failed to list nodes")
+		return
+	}
+
+	mods := kmmv1beta1.ModuleList{}
+
+	if err := ip.client.List(ctx, &mods); err != nil {
+		ip.logger.Error(err, "failed to list Modules")
+		return
+	}
+
+	cm := v1.ConfigMap{}
+
+	err := ip.client.Get(ctx, types.NamespacedName{Namespace: ip.namespace, Name: ConfigMapName}, &cm)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		ip.logger.Error(err, "failed to get the image retention ConfigMap")
+		return
+	}
+
+	presentKernels := make([]sets.String, len(mods.Items))
+	inUse := &inUseImages{sources: make(map[string]imageSource)}
+
+	for i := 0; i < len(mods.Items); i++ {
+		presentKernels[i] = moduleKernels(&mods.Items[i], nodes.Items)
+
+		for _, k := range presentKernels[i].UnsortedList() {
+			images, tlsOptions, _ := ip.moduleImages(&mods.Items[i], k, false)
+
+			for _, img := range images {
+				inUse.sources[img] = imageSource{mod: &mods.Items[i], tlsOptions: tlsOptions}
+			}
+		}
+	}
+
+	data := make(map[string]string, len(mods.Items))
+
+	for i := 0; i < len(mods.Items); i++ {
+		mod := &mods.Items[i]
+		key := mod.Namespace + "." + mod.Name
+		logger := ip.logger.WithValues("module", mod.Namespace+"/"+mod.Name)
+
+		if mod.DeletionTimestamp != nil {
+			continue
+		}
+
+		records := make(map[string]*kernelRecord)
+
+		if s := cm.Data[key]; s != "" {
+			if err = json.Unmarshal([]byte(s), &records); err != nil {
+				logger.Error(err, "ignoring the invalid retention records of the Module")
+				records = make(map[string]*kernelRecord)
+			}
+		}
+
+		ip.updateRecords(mod, records, presentKernels[i])
+
+		for _, kernel := range ip.expiredKernels(records) {
+			if ip.pruneKernel(ctx, logger, mod, kernel, records[kernel], inUse) {
+				delete(records, kernel)
+			}
+		}
+
+		if len(records) == 0 {
+			continue
+		}
+
+		b, err := json.Marshal(records)
+		if err != nil {
+			logger.Error(err, "failed to marshal the retention records of the Module")
+			continue
+		}
+
+		data[key] = string(b)
+	}
+
+	cm = v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ip.namespace, Name: ConfigMapName},
+	}
+
+	opRes, err := controllerutil.CreateOrPatch(ctx, ip.client, &cm, func() error {
+		cm.Data = data
+		return nil
+	})
+	if err != nil {
+		ip.logger.Error(err, "failed to update the image retention ConfigMap")
+		return
+	}
+
+	ip.logger.V(1).Info("Updated the image retention ConfigMap", "result", opRes)
+}
+
+// moduleKernels returns the kernels run by the nodes that mod targets.
+func moduleKernels(mod *kmmv1beta1.Module, nodes []v1.Node) sets.String {
+	kernels := sets.NewString()
+	selector := labels.SelectorFromSet(mod.Spec.Selector)
+
+	for _, n := range nodes {
+		// Nodes that just joined the cluster may not have reported their kernel yet.
+		if kv := n.Status.NodeInfo.KernelVersion; kv != "" && selector.Matches(labels.Set(n.Labels)) {
+			kernels.Insert(strings.TrimSuffix(kv, "+"))
+		}
+	}
+
+	return kernels
+}
+
+// moduleImages returns the images of mod for kernel, along with their TLS options.
+// If pushedOnly is true, only the images that KMM built or signed are returned: the target image, and the
+// intermediate image when the target image is both built and signed.
+// Images referenced by digest are never returned, as KMM only pushes tags.
+func (ip *ImagePruner) moduleImages(mod *kmmv1beta1.Module, kernel string, pushedOnly bool) ([]string, *kmmv1beta1.TLSOptions, error) {
+	m, err := ip.kernelAPI.FindMappingForKernel(mod.Spec.ModuleLoader.Container.KernelMappings, kernel)
+	if err != nil {
+		return nil, nil, nil
+	}
+
+	m, err = ip.kernelAPI.PrepareKernelMapping(m, ip.kernelAPI.GetNodeOSConfigFromKernelVersion(kernel))
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not substitute the kernel variables: %v", err)
+	}
+
+	build := module.ShouldBeBuilt(mod.Spec, *m)
+	sign := module.ShouldBeSigned(mod.Spec, *m)
+
+	if (pushedOnly && !build && !sign) || strings.Contains(m.ContainerImage, "@") {
+		return nil, nil, nil
+	}
+
+	images := []string{m.ContainerImage}
+
+	if build && sign {
+		images = append(images, module.IntermediateImageName(mod.Name, mod.Namespace, m.ContainerImage))
+	}
+
+	return images, module.TLSOptions(mod.Spec, *m), nil
+}
+
+// updateRecords records the kernels of mod that nodes run now with the images that KMM pushes for them, and since when
+// the others are absent.
+// Only the kernels for which KMM pushes images are recorded; images recorded earlier for a kernel are kept, so that
+// those pushed before the Module changed are pruned too.
+func (ip *ImagePruner) updateRecords(mod *kmmv1beta1.Module, records map[string]*kernelRecord, present sets.String) {
+	for _, kernel := range present.UnsortedList() {
+		images := sets.NewString()
+
+		if r := records[kernel]; r != nil {
+			images.Insert(r.Images...)
+		}
+
+		pushed, _, _ := ip.moduleImages(mod, kernel, true)
+		images.Insert(pushed...)
+
+		if images.Len() == 0 {
+			continue
+		}
+
+		records[kernel] = &kernelRecord{Images: images.List()}
+	}
+
+	now := metav1.NewTime(ip.now())
+
+	for kernel, r := range records {
+		if !present.Has(kernel) && r.AbsentSince == nil {
+			r.AbsentSince = &now
+		}
+	}
+}
+
+// expiredKernels returns the kernels of records that have been absent for longer than the Policy allows, excluding the
+// Keep most recently absent ones.
+func (ip *ImagePruner) expiredKernels(records map[string]*kernelRecord) []string {
+	absent := make([]string, 0, len(records))
+
+	for kernel, r := range records {
+		if r.AbsentSince != nil {
+			absent = append(absent, kernel)
+		}
+	}
+
+	sort.Slice(absent, func(i, j int) bool {
+		ti, tj := records[absent[i]].AbsentSince, records[absent[j]].AbsentSince
+
+		if ti.Equal(tj) {
+			return absent[i] < absent[j]
+		}
+
+		return tj.Before(ti)
+	})
+
+	expired := make([]string, 0)
+
+	for i, kernel := range absent {
+		if i >= ip.policy.Keep && ip.now().Sub(records[kernel].AbsentSince.Time) >= ip.policy.MaxAbsence {
+			expired = append(expired, kernel)
+		}
+	}
+
+	return expired
+}
+
+// pruneKernel deletes the recorded images of mod for kernel that no node uses, and returns true if kernel can be
+// forgotten.
+// Images are compared by digest, as deleting an image removes all the tags of its manifest.
+// Failed deletions are retried the next time images are pruned.
+func (ip *ImagePruner) pruneKernel(
+	ctx context.Context,
+	logger logr.Logger,
+	mod *kmmv1beta1.Module,
+	kernel string,
+	record *kernelRecord,
+	inUse *inUseImages,
+) bool {
+	logger = logger.WithValues("kernel", kernel, "absent since", record.AbsentSince)
+
+	if len(record.Images) == 0 {
+		// Kernels recorded by earlier versions of the operator have no images: what KMM pushed for them is unknown.
+		return true
+	}
+
+	if ip.policy.DryRun && record.DryRunReported {
+		return false
+	}
+
+	// The TLS options of the kernel mapping are still needed to reach the registry.
+	_, tlsOptions, err := ip.moduleImages(mod, kernel, false)
+	if err != nil {
+		logger.Error(err, "failed to determine the TLS options of the kernel")
+		return false
+	}
+
+	inUseDigests, err := ip.inUseDigests(ctx, inUse)
+	if err != nil {
+		logger.Error(err, "failed to resolve the digests of the images in use")
+		return false
+	}
+
+	registryAuthGetter := auth.NewRegistryAuthGetterFrom(ip.client, mod)
+	remaining := make([]string, 0)
+
+	for _, img := range record.Images {
+		digest, err := ip.registryAPI.GetDigest(ctx, img, tlsOptions, registryAuthGetter)
+		if err != nil {
+			logger.Error(err, "failed to resolve the image", "image", img)
+			ip.metricsAPI.AddPrunedImage(mod.Name, mod.Namespace, metrics.PruneFailed)
+			remaining = append(remaining, img)
+			continue
+		}
+
+		if digest == "" {
+			logger.V(1).Info("The image no longer exists", "image", img)
+			continue
+		}
+
+		if inUseDigests.Has(digest) {
+			logger.Info("Not pruning an image that is still in use", "image", img, "digest", digest)
+			continue
+		}
+
+		if ip.policy.DryRun {
+			logger.Info("Would prune the image (dry-run)", "image", img, "digest", digest)
+			ip.metricsAPI.AddPrunedImage(mod.Name, mod.Namespace, metrics.PruneDryRun)
+			remaining = append(remaining, img)
+			continue
+		}
+
+		if err = ip.registryAPI.DeleteImage(ctx, digestReference(img, digest), tlsOptions, registryAuthGetter); err != nil {
+			logger.Error(err, "failed to prune the image", "image", img)
+			ip.metricsAPI.AddPrunedImage(mod.Name, mod.Namespace, metrics.PruneFailed)
+			remaining = append(remaining, img)
+			continue
+		}
+
+		logger.Info("Pruned the image", "image", img, "digest", digest)
+		ip.metricsAPI.AddPrunedImage(mod.Name, mod.Namespace, metrics.PruneDeleted)
+	}
+
+	record.Images = remaining
+
+	if ip.policy.DryRun {
+		// The kernel is kept, so that its images are pruned if dry-run mode is disabled.
+		record.DryRunReported = true
+		return false
+	}
+
+	return len(remaining) == 0
+}
+
+// inUseDigests returns the digests of the images of inUse, resolving them the first time it is called.
+// Images that do not exist are ignored.
+func (ip *ImagePruner) inUseDigests(ctx context.Context, inUse *inUseImages) (sets.String, error) {
+	if inUse.digests != nil || inUse.err != nil {
+		return inUse.digests, inUse.err
+	}
+
+	digests := sets.NewString()
+
+	for img, src := range inUse.sources {
+		if _, digest, ok := strings.Cut(img, "@"); ok {
+			digests.Insert(digest)
+			continue
+		}
+
+		digest, err := ip.registryAPI.GetDigest(ctx, img, src.tlsOptions, auth.NewRegistryAuthGetterFrom(ip.client, src.mod))
+		if err != nil {
+			inUse.err = fmt.Errorf("could not resolve image %s: %v", img, err)
+			return nil, inUse.err
+		}
+
+		if digest != "" {
+			digests.Insert(digest)
+		}
+	}
+
+	inUse.digests = digests
+
+	return digests, nil
+}
+
+// digestReference returns the reference to digest in the repository of image.
+func digestReference(image, digest string) string {
+	repo := image
+
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		repo = image[:i]
+	}
+
+	return repo + "@" + digest
+}
@@ -0,0 +1,241 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/client"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/metrics"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/registry"
+)
+
+const namespace = "kmm-operator-system"
+
+var (
+	now = time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	builtModule = kmmv1beta1.Module{
+		ObjectMeta: metav1.ObjectMeta{Name: "gpu-kmod", Namespace: "default"},
+		Spec: kmmv1beta1.ModuleSpec{
+			ModuleLoader: kmmv1beta1.ModuleLoaderSpec{
+				Container: kmmv1beta1.ModuleLoaderContainerSpec{
+					Build: &kmmv1beta1.Build{},
+					KernelMappings: []kmmv1beta1.KernelMapping{
+						{Regexp: "^.+$", ContainerImage: "example.com/gpu-kmod:${KERNEL_FULL_VERSION}"},
+					},
+				},
+			},
+			Selector: map[string]string{"gpu": "true"},
+		},
+	}
+)
+
+func absentSince(d time.Duration) *kernelRecord {
+	t := metav1.NewTime(now.Add(-d))
+	return &kernelRecord{AbsentSince: &t}
+}
+
+func newPruner(c *client.MockClient, reg *registry.MockRegistry, m *metrics.MockMetrics, policy Policy) *ImagePruner {
+	ip := NewImagePruner(c, module.NewKernelMapper(), reg, m, namespace, policy, 0, logr.Discard())
+	ip.now = func() time.Time { return now }
+
+	return ip
+}
+
+var _ = Describe("ImagePruner_updateRecords", func() {
+	It("should record the absence of kernels that nodes no longer run", func() {
+		ip := newPruner(nil, nil, nil, Policy{})
+
+		records := map[string]*kernelRecord{
+			"5.14.0-1": {},
+			"5.14.0-2": absentSince(time.Hour),
+			"5.14.0-3": absentSince(time.Hour),
+		}
+
+		ip.updateRecords(&builtModule, records, sets.NewString("5.14.0-3", "5.14.0-4"))
+
+		Expect(records).To(HaveLen(4))
+		Expect(records["5.14.0-1"].AbsentSince.Time).To(Equal(now))
+		Expect(records["5.14.0-2"]).To(Equal(absentSince(time.Hour)))
+		Expect(records["5.14.0-3"]).To(Equal(&kernelRecord{Images: []string{"example.com/gpu-kmod:5.14.0-3"}}))
+		Expect(records["5.14.0-4"]).To(Equal(&kernelRecord{Images: []string{"example.com/gpu-kmod:5.14.0-4"}}))
+	})
+
+	It("should keep the images recorded before the Module changed", func() {
+		ip := newPruner(nil, nil, nil, Policy{})
+
+		records := map[string]*kernelRecord{
+			"5.14.0-1": {Images: []string{"example.com/old-kmod:5.14.0-1"}},
+		}
+
+		ip.updateRecords(&builtModule, records, sets.NewString("5.14.0-1"))
+
+		Expect(records["5.14.0-1"].Images).To(Equal([]string{"example.com/gpu-kmod:5.14.0-1", "example.com/old-kmod:5.14.0-1"}))
+	})
+
+	It("should not record the kernels of images that KMM does not push", func() {
+		ip := newPruner(nil, nil, nil, Policy{})
+
+		mod := builtModule.DeepCopy()
+		mod.Spec.ModuleLoader.Container.Build = nil
+
+		records := make(map[string]*kernelRecord)
+
+		ip.updateRecords(mod, records, sets.NewString("5.14.0-1"))
+
+		Expect(records).To(BeEmpty())
+	})
+})
+
+var _ = Describe("ImagePruner_expiredKernels", func() {
+	records := map[string]*kernelRecord{
+		"5.14.0-1": {},
+		"5.14.0-2": absentSince(48 * time.Hour),
+		"5.14.0-3": absentSince(72 * time.Hour),
+		"5.14.0-4": absentSince(96 * time.Hour),
+		"5.14.0-5": absentSince(time.Hour),
+	}
+
+	DescribeTable("should return the kernels absent for too long, except the most recent ones",
+		func(policy Policy, expected []string) {
+			Expect(newPruner(nil, nil, nil, policy).expiredKernels(records)).To(Equal(expected))
+		},
+		Entry("no kernel kept", Policy{MaxAbsence: 24 * time.Hour}, []string{"5.14.0-2", "5.14.0-3", "5.14.0-4"}),
+		Entry("two kernels kept", Policy{MaxAbsence: 24 * time.Hour, Keep: 2}, []string{"5.14.0-3", "5.14.0-4"}),
+		Entry("all kernels kept", Policy{MaxAbsence: 24 * time.Hour, Keep: 10}, []string{}),
+	)
+})
+
+var _ = Describe("ImagePruner_prune", func() {
+	var (
+		clnt    *client.MockClient
+		mockReg *registry.MockRegistry
+		mockMet *metrics.MockMetrics
+	)
+
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		ctrl := gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+		mockReg = registry.NewMockRegistry(ctrl)
+		mockMet = metrics.NewMockMetrics(ctrl)
+	})
+
+	node := v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{"gpu": "true"}},
+		Status:     v1.NodeStatus{NodeInfo: v1.NodeSystemInfo{KernelVersion: "5.14.0-2"}},
+	}
+
+	expectLists := func(records string) {
+		clnt.EXPECT().List(ctx, gomock.AssignableToTypeOf(&v1.NodeList{})).DoAndReturn(
+			func(_ interface{}, list *v1.NodeList, _ ...interface{}) error {
+				list.Items = []v1.Node{node}
+				return nil
+			},
+		)
+		clnt.EXPECT().List(ctx, gomock.AssignableToTypeOf(&kmmv1beta1.ModuleList{})).DoAndReturn(
+			func(_ interface{}, list *kmmv1beta1.ModuleList, _ ...interface{}) error {
+				list.Items = []kmmv1beta1.Module{builtModule}
+				return nil
+			},
+		)
+		clnt.EXPECT().Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(&v1.ConfigMap{})).DoAndReturn(
+			func(_ interface{}, _ interface{}, cm *v1.ConfigMap, _ ...interface{}) error {
+				cm.Data = map[string]string{"default.gpu-kmod": records}
+				return nil
+			},
+		)
+	}
+
+	expectConfigMap := func(data map[string]string) {
+		clnt.
+			EXPECT().
+			Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(&v1.ConfigMap{})).
+			Return(apierrors.NewNotFound(schema.GroupResource{}, ConfigMapName))
+		clnt.EXPECT().Create(ctx, gomock.Any()).Do(func(_ context.Context, cm *v1.ConfigMap, _ ...interface{}) {
+			Expect(cm.Data).To(Equal(data))
+		})
+	}
+
+	const (
+		expiredRecord = `{"5.14.0-1":{"absentSince":"2023-05-01T00:00:00Z","images":["example.com/gpu-kmod:5.14.0-1"]}}`
+		presentRecord = `"5.14.0-2":{"images":["example.com/gpu-kmod:5.14.0-2"]}`
+	)
+
+	expectDigests := func(expired, inUse string) {
+		mockReg.EXPECT().GetDigest(ctx, "example.com/gpu-kmod:5.14.0-2", gomock.Any(), gomock.Any()).Return(inUse, nil)
+		mockReg.EXPECT().GetDigest(ctx, "example.com/gpu-kmod:5.14.0-1", gomock.Any(), gomock.Any()).Return(expired, nil)
+	}
+
+	It("should delete the recorded images of expired kernels by digest and forget them", func() {
+		ip := newPruner(clnt, mockReg, mockMet, Policy{MaxAbsence: 24 * time.Hour})
+
+		expectLists(expiredRecord)
+		expectDigests("sha256:expired", "sha256:in-use")
+		mockReg.EXPECT().DeleteImage(ctx, "example.com/gpu-kmod@sha256:expired", gomock.Any(), gomock.Any())
+		mockMet.EXPECT().AddPrunedImage("gpu-kmod", "default", metrics.PruneDeleted)
+		expectConfigMap(map[string]string{"default.gpu-kmod": "{" + presentRecord + "}"})
+
+		ip.prune(ctx)
+	})
+
+	It("should not delete an image whose manifest is still in use under another tag", func() {
+		ip := newPruner(clnt, mockReg, mockMet, Policy{MaxAbsence: 24 * time.Hour})
+
+		expectLists(expiredRecord)
+		expectDigests("sha256:in-use", "sha256:in-use")
+		expectConfigMap(map[string]string{"default.gpu-kmod": "{" + presentRecord + "}"})
+
+		ip.prune(ctx)
+	})
+
+	It("should only prune the recorded images", func() {
+		ip := newPruner(clnt, mockReg, mockMet, Policy{MaxAbsence: 24 * time.Hour})
+
+		expectLists(`{"5.14.0-1":{"absentSince":"2023-05-01T00:00:00Z"}}`)
+		expectConfigMap(map[string]string{"default.gpu-kmod": "{" + presentRecord + "}"})
+
+		ip.prune(ctx)
+	})
+
+	It("should keep the kernels whose images could not be deleted", func() {
+		ip := newPruner(clnt, mockReg, mockMet, Policy{MaxAbsence: 24 * time.Hour})
+
+		expectLists(expiredRecord)
+		expectDigests("sha256:expired", "sha256:in-use")
+		mockReg.EXPECT().DeleteImage(ctx, "example.com/gpu-kmod@sha256:expired", gomock.Any(), gomock.Any()).Return(errors.New("some error"))
+		mockMet.EXPECT().AddPrunedImage("gpu-kmod", "default", metrics.PruneFailed)
+		expectConfigMap(map[string]string{
+			"default.gpu-kmod": `{"5.14.0-1":{"absentSince":"2023-05-01T00:00:00Z","images":["example.com/gpu-kmod:5.14.0-1"]},` + presentRecord + "}",
+		})
+
+		ip.prune(ctx)
+	})
+
+	It("should only report the images once in dry-run mode", func() {
+		ip := newPruner(clnt, mockReg, mockMet, Policy{MaxAbsence: 24 * time.Hour, DryRun: true})
+
+		expectLists(expiredRecord)
+		expectDigests("sha256:expired", "sha256:in-use")
+		mockMet.EXPECT().AddPrunedImage("gpu-kmod", "default", metrics.PruneDryRun)
+		expectConfigMap(map[string]string{
+			"default.gpu-kmod": `{"5.14.0-1":{"absentSince":"2023-05-01T00:00:00Z","dryRunReported":true,"images":["example.com/gpu-kmod:5.14.0-1"]},` + presentRecord + "}",
+		})
+
+		ip.prune(ctx)
+	})
+})
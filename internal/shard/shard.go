@@ -0,0 +1,85 @@
+package shard
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Shard selects the namespaces handled by one of several operator replicas.
+// Namespaces are assigned to shards based on a hash of their name, so that each namespace is handled by exactly one
+// replica when all replicas are started with the same count and distinct indexes.
+type Shard struct {
+	index int
+	count int
+}
+
+// New returns the shard with the given index among count shards.
+func New(index, count int) (*Shard, error) {
+	if count < 1 {
+		return nil, fmt.Errorf("invalid shard count %d: must be at least 1", count)
+	}
+
+	if index < 0 || index >= count {
+		return nil, errors.New("the shard index must be between 0 and the shard count (excluded)")
+	}
+
+	return &Shard{index: index, count: count}, nil
+}
+
+// Contains returns true if namespace belongs to this shard.
+func (s *Shard) Contains(namespace string) bool {
+	if s.count == 1 {
+		return true
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(namespace))
+
+	return int(h.Sum32()%uint32(s.count)) == s.index
+}
+
+// IsFirst returns true if s is the first shard.
+// Controllers for cluster-scoped resources should only run in the first shard.
+func (s *Shard) IsFirst() bool {
+	return s.index == 0
+}
+
+// IsSharded returns true if there are several shards.
+func (s *Shard) IsSharded() bool {
+	return s.count > 1
+}
+
+// Index returns the index of this shard.
+func (s *Shard) Index() int {
+	return s.index
+}
+
+// Predicate returns a predicate that only lets through events for objects in this shard's namespaces.
+func (s *Shard) Predicate() predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(o client.Object) bool {
+		return s.Contains(o.GetNamespace())
+	})
+}
+
+// MapFunc wraps fn so that it only returns the requests for objects in this shard's namespaces.
+func (s *Shard) MapFunc(fn handler.MapFunc) handler.MapFunc {
+	return func(o client.Object) []reconcile.Request {
+		reqs := fn(o)
+
+		filtered := make([]reconcile.Request, 0, len(reqs))
+
+		for _, req := range reqs {
+			if s.Contains(req.Namespace) {
+				filtered = append(filtered, req)
+			}
+		}
+
+		return filtered
+	}
+}
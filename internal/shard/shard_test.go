@@ -0,0 +1,114 @@
+package shard
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("New", func() {
+	DescribeTable("should validate the index and count",
+		func(index, count int, expectError bool) {
+			_, err := New(index, count)
+
+			if expectError {
+				Expect(err).To(HaveOccurred())
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+			}
+		},
+		Entry("single shard", 0, 1, false),
+		Entry("last of several shards", 2, 3, false),
+		Entry("count of 0", 0, 0, true),
+		Entry("negative index", -1, 3, true),
+		Entry("index equal to the count", 3, 3, true),
+	)
+})
+
+var _ = Describe("Contains", func() {
+	It("should contain all namespaces if there is only one shard", func() {
+		s, err := New(0, 1)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(s.Contains("some-namespace")).To(BeTrue())
+		Expect(s.Contains("")).To(BeTrue())
+	})
+
+	It("should assign each namespace to exactly one shard", func() {
+		const count = 3
+
+		shards := make([]*Shard, 0, count)
+
+		for i := 0; i < count; i++ {
+			s, err := New(i, count)
+			Expect(err).NotTo(HaveOccurred())
+
+			shards = append(shards, s)
+		}
+
+		for i := 0; i < 100; i++ {
+			ns := fmt.Sprintf("namespace-%d", i)
+
+			owners := 0
+
+			for _, s := range shards {
+				if s.Contains(ns) {
+					owners++
+				}
+			}
+
+			Expect(owners).To(Equal(1), ns)
+		}
+	})
+})
+
+var _ = Describe("Predicate", func() {
+	It("should only let through objects in the shard's namespaces", func() {
+		s, err := New(0, 2)
+		Expect(err).NotTo(HaveOccurred())
+
+		p := s.Predicate()
+
+		for i := 0; i < 10; i++ {
+			ns := fmt.Sprintf("namespace-%d", i)
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: ns}}
+
+			Expect(p.Create(event.CreateEvent{Object: pod})).To(Equal(s.Contains(ns)))
+		}
+	})
+})
+
+var _ = Describe("MapFunc", func() {
+	It("should drop requests for namespaces outside of the shard", func() {
+		s, err := New(1, 2)
+		Expect(err).NotTo(HaveOccurred())
+
+		reqs := make([]reconcile.Request, 0)
+		expected := make([]reconcile.Request, 0)
+
+		for i := 0; i < 10; i++ {
+			req := reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "name", Namespace: fmt.Sprintf("namespace-%d", i)},
+			}
+
+			reqs = append(reqs, req)
+
+			if s.Contains(req.Namespace) {
+				expected = append(expected, req)
+			}
+		}
+
+		fn := s.MapFunc(func(_ client.Object) []reconcile.Request {
+			return reqs
+		})
+
+		Expect(fn(&v1.Node{})).To(Equal(expected))
+	})
+})
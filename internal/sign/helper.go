@@ -2,6 +2,7 @@ package sign
 
 import (
 	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
 )
 
 //go:generate mockgen -source=helper.go -package=sign -destination=mock_helper.go
@@ -31,14 +32,35 @@ func (m *helper) GetRelevantSign(modSpec kmmv1beta1.ModuleSpec, km kmmv1beta1.Ke
 	if km.Sign.UnsignedImage != "" {
 		signConfig.UnsignedImage = km.Sign.UnsignedImage
 	}
+	// The keys are properties of the Secrets; they are overridden along with them.
 	if km.Sign.KeySecret != nil {
 		signConfig.KeySecret = km.Sign.KeySecret
+		signConfig.KeySecretKey = km.Sign.KeySecretKey
 	}
 	if km.Sign.CertSecret != nil {
 		signConfig.CertSecret = km.Sign.CertSecret
+		signConfig.CertSecretKey = km.Sign.CertSecretKey
 	}
 	//append (not overwrite) any files in the km to the defaults
 	signConfig.FilesToSign = append(signConfig.FilesToSign, km.Sign.FilesToSign...)
 
 	return signConfig
 }
+
+// KeySecretKey returns the key of the private key in the KeySecret of s.
+func KeySecretKey(s *kmmv1beta1.Sign) string {
+	if s.KeySecretKey != "" {
+		return s.KeySecretKey
+	}
+
+	return constants.PrivateSignDataKey
+}
+
+// CertSecretKey returns the key of the public key in the CertSecret of s.
+func CertSecretKey(s *kmmv1beta1.Sign) string {
+	if s.CertSecretKey != "" {
+		return s.CertSecretKey
+	}
+
+	return constants.PublicSignDataKey
+}
@@ -166,3 +166,19 @@ var _ = Describe("GetRelevantSign", func() {
 	)
 
 })
+
+var _ = Describe("KeySecretKey and CertSecretKey", func() {
+	It("should return the default keys", func() {
+		s := &kmmv1beta1.Sign{}
+
+		Expect(KeySecretKey(s)).To(Equal("key"))
+		Expect(CertSecretKey(s)).To(Equal("cert"))
+	})
+
+	It("should return the keys set in the spec", func() {
+		s := &kmmv1beta1.Sign{KeySecretKey: "tls.key", CertSecretKey: "tls.crt"}
+
+		Expect(KeySecretKey(s)).To(Equal("tls.key"))
+		Expect(CertSecretKey(s)).To(Equal("tls.crt"))
+	})
+})
@@ -15,8 +15,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/auth"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/fips"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/module"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/podsecurity"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/rbac"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/sign"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/utils"
 	"github.com/mitchellh/hashstructure"
@@ -37,6 +41,20 @@ type Signer interface {
 	) (*batchv1.Job, error)
 }
 
+const (
+	signerImage = "quay.io/chrisp262/kmod-signer:latest"
+
+	// The signer container shares an in-memory emptyDir with the signimage container, which holds its socket and the
+	// kernel modules to sign.
+	signerVolumeName = "signer"
+	signerDir        = "/run/kmm-signer"
+	signerSocketPath = signerDir + "/signer.sock"
+
+	// The private key is only exposed to the signer container, through an environment variable, so that it never
+	// lands on the filesystem of the pod.
+	signingKeyEnvVar = "SIGNING_KEY"
+)
+
 type hashData struct {
 	PrivateKeyData []byte
 	PublicKeyData  []byte
@@ -44,22 +62,31 @@ type hashData struct {
 }
 
 type signer struct {
-	client    client.Client
-	scheme    *runtime.Scheme
-	helper    sign.Helper
-	jobHelper utils.JobHelper
+	client     client.Client
+	scheme     *runtime.Scheme
+	helper     sign.Helper
+	jobHelper  utils.JobHelper
+	restricted bool
+	fipsMode   bool
 }
 
+// NewSigner returns a Signer.
+// If restricted is true, sign pods satisfy the "restricted" Pod Security Standard.
+// If fipsMode is true, signing certificates that do not use FIPS-approved algorithms are refused.
 func NewSigner(
 	client client.Client,
 	scheme *runtime.Scheme,
 	helper sign.Helper,
-	jobHelper utils.JobHelper) Signer {
+	jobHelper utils.JobHelper,
+	restricted bool,
+	fipsMode bool) Signer {
 	return &signer{
-		client:    client,
-		scheme:    scheme,
-		helper:    helper,
-		jobHelper: jobHelper,
+		client:     client,
+		scheme:     scheme,
+		helper:     helper,
+		jobHelper:  jobHelper,
+		restricted: restricted,
+		fipsMode:   fipsMode,
 	}
 }
 
@@ -98,8 +125,7 @@ func (m *signer) MakeJobTemplate(
 	} else {
 		return nil, fmt.Errorf("no image to sign given")
 	}
-	args = append(args, "-key", "/signingkey/key.priv")
-	args = append(args, "-cert", "/signingcert/public.der")
+	args = append(args, "-signer-socket", signerSocketPath, "-workdir", signerDir)
 
 	if len(signConfig.FilesToSign) > 0 {
 		args = append(args, "-filestosign", strings.Join(signConfig.FilesToSign, ":"))
@@ -113,38 +139,84 @@ func (m *signer) MakeJobTemplate(
 		args = append(args, "--skip-tls-verify-pull")
 	}
 
-	volumes := []v1.Volume{
-		utils.MakeSecretVolume(signConfig.KeySecret, "key", "key.priv"),
-		utils.MakeSecretVolume(signConfig.CertSecret, "cert", "public.der"),
+	signerVolume := v1.Volume{
+		Name:         signerVolumeName,
+		VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{Medium: v1.StorageMediumMemory}},
 	}
-	volumeMounts := []v1.VolumeMount{
-		utils.MakeSecretVolumeMount(signConfig.CertSecret, "/signingcert"),
-		utils.MakeSecretVolumeMount(signConfig.KeySecret, "/signingkey"),
+	signerVolumeMount := v1.VolumeMount{Name: signerVolumeName, MountPath: signerDir}
+
+	volumes := []v1.Volume{
+		signerVolume,
+		utils.MakeSecretVolume(signConfig.CertSecret, sign.CertSecretKey(signConfig), "public.der"),
 	}
+	volumeMounts := []v1.VolumeMount{signerVolumeMount}
 
 	if mod.Spec.ImageRepoSecret != nil {
 		args = append(args, "-pullsecret", "/docker_config/config.json")
 		volumes = append(volumes, utils.MakeSecretVolume(mod.Spec.ImageRepoSecret, v1.DockerConfigJsonKey, "config.json"))
 		volumeMounts = append(volumeMounts, utils.MakeSecretVolumeMount(mod.Spec.ImageRepoSecret, "/docker_config"))
+	} else if mod.Spec.WorkloadIdentity != nil {
+		args = append(args, "-workload-identity")
+	}
+
+	nodeSelector, err := module.JobNodeSelector(ctx, m.client, mod.Spec.Selector, targetKernel)
+	if err != nil {
+		return nil, err
 	}
 
 	specTemplate := v1.PodTemplateSpec{
+		// The Pod is labeled like the Job, so that it is selected by the build and sign NetworkPolicy.
+		ObjectMeta: metav1.ObjectMeta{Labels: auth.WorkloadIdentityPodLabels(mod.Spec.WorkloadIdentity, labels)},
 		Spec: v1.PodSpec{
 			Containers: []v1.Container{
 				{
 					Name:         "signimage",
-					Image:        "quay.io/chrisp262/kmod-signer:latest",
+					Image:        signerImage,
 					Args:         args,
 					VolumeMounts: volumeMounts,
 				},
+				{
+					Name:  "signer",
+					Image: signerImage,
+					Args: []string{
+						"-serve",
+						"-signer-socket", signerSocketPath,
+						"-workdir", signerDir,
+						"-cert", "/signingcert/public.der",
+					},
+					Env: []v1.EnvVar{
+						{
+							Name: signingKeyEnvVar,
+							ValueFrom: &v1.EnvVarSource{
+								SecretKeyRef: &v1.SecretKeySelector{
+									LocalObjectReference: *signConfig.KeySecret,
+									Key:                  sign.KeySecretKey(signConfig),
+								},
+							},
+						},
+					},
+					VolumeMounts: []v1.VolumeMount{
+						signerVolumeMount,
+						utils.MakeSecretVolumeMount(signConfig.CertSecret, "/signingcert"),
+					},
+				},
 			},
-			RestartPolicy: v1.RestartPolicyOnFailure,
-			Volumes:       volumes,
-			NodeSelector:  mod.Spec.Selector,
+			RestartPolicy:      v1.RestartPolicyOnFailure,
+			ServiceAccountName: rbac.GenerateBuildServiceAccountName(mod),
+			Volumes:            volumes,
+			NodeSelector:       nodeSelector,
 		},
 	}
 
-	specTemplateHash, err := m.getHashAnnotationValue(ctx, signConfig.KeySecret.Name, signConfig.CertSecret.Name, mod.Namespace, &specTemplate)
+	if m.restricted {
+		specTemplate.Spec.SecurityContext = podsecurity.RestrictedPodSecurityContext()
+
+		for i := range specTemplate.Spec.Containers {
+			specTemplate.Spec.Containers[i].SecurityContext = podsecurity.RestrictedSecurityContext()
+		}
+	}
+
+	specTemplateHash, err := m.getHashAnnotationValue(ctx, signConfig, mod.Namespace, &specTemplate)
 	if err != nil {
 		return nil, fmt.Errorf("could not hash job's definitions: %v", err)
 	}
@@ -169,16 +241,25 @@ func (m *signer) MakeJobTemplate(
 	return job, nil
 }
 
-func (s *signer) getHashAnnotationValue(ctx context.Context, privateSecret, publicSecret, namespace string, podTemplate *v1.PodTemplateSpec) (uint64, error) {
-	privateKeyData, err := s.getSecretData(ctx, privateSecret, constants.PrivateSignDataKey, namespace)
+func (s *signer) getHashAnnotationValue(ctx context.Context, signConfig *kmmv1beta1.Sign, namespace string, podTemplate *v1.PodTemplateSpec) (uint64, error) {
+	privateSecret := signConfig.KeySecret.Name
+	publicSecret := signConfig.CertSecret.Name
+
+	privateKeyData, err := s.getSecretData(ctx, privateSecret, sign.KeySecretKey(signConfig), namespace)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get private secret %s for signing: %v", privateSecret, err)
 	}
-	publicKeyData, err := s.getSecretData(ctx, publicSecret, constants.PublicSignDataKey, namespace)
+	publicKeyData, err := s.getSecretData(ctx, publicSecret, sign.CertSecretKey(signConfig), namespace)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get public secret %s for signing: %v", publicSecret, err)
 	}
 
+	if s.fipsMode {
+		if err = fips.CheckSigningCertificate(publicKeyData); err != nil {
+			return 0, fmt.Errorf("certificate %s cannot be used in FIPS mode: %v", publicSecret, err)
+		}
+	}
+
 	return getHashValue(podTemplate, publicKeyData, privateKeyData)
 }
 
@@ -20,6 +20,7 @@ import (
 	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/client"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
+	"github.com/kubernetes-sigs/kernel-module-management/internal/podsecurity"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/sign"
 	"github.com/kubernetes-sigs/kernel-module-management/internal/utils"
 )
@@ -51,7 +52,7 @@ var _ = Describe("MakeJobTemplate", func() {
 		clnt = client.NewMockClient(ctrl)
 		helper = sign.NewMockHelper(ctrl)
 		jobhelper = utils.NewMockJobHelper(ctrl)
-		m = NewSigner(clnt, scheme, helper, jobhelper)
+		m = NewSigner(clnt, scheme, helper, jobhelper, false, false)
 		mod = kmmv1beta1.Module{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      moduleName,
@@ -86,28 +87,19 @@ var _ = Describe("MakeJobTemplate", func() {
 			ContainerImage: signedImage,
 		}
 
-		secretMount := v1.VolumeMount{
-			Name:      "secret-securebootcert",
-			ReadOnly:  true,
-			MountPath: "/signingcert",
+		signerMount := v1.VolumeMount{
+			Name:      "signer",
+			MountPath: "/run/kmm-signer",
 		}
 		certMount := v1.VolumeMount{
-			Name:      "secret-securebootkey",
+			Name:      "secret-securebootcert",
 			ReadOnly:  true,
-			MountPath: "/signingkey",
+			MountPath: "/signingcert",
 		}
-		keysecret := v1.Volume{
-			Name: "secret-securebootkey",
+		signerVolume := v1.Volume{
+			Name: "signer",
 			VolumeSource: v1.VolumeSource{
-				Secret: &v1.SecretVolumeSource{
-					SecretName: "securebootkey",
-					Items: []v1.KeyToPath{
-						{
-							Key:  "key",
-							Path: "key.priv",
-						},
-					},
-				},
+				EmptyDir: &v1.EmptyDirVolumeSource{Medium: v1.StorageMediumMemory},
 			},
 		}
 		certsecret := v1.Volume{
@@ -148,6 +140,7 @@ var _ = Describe("MakeJobTemplate", func() {
 			Spec: batchv1.JobSpec{
 				Completions: pointer.Int32(1),
 				Template: v1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
 					Spec: v1.PodSpec{
 						Containers: []v1.Container{
 							{
@@ -156,17 +149,40 @@ var _ = Describe("MakeJobTemplate", func() {
 								Args: []string{
 									"-signedimage", signedImage,
 									"-unsignedimage", unsignedImage,
-									"-key", "/signingkey/key.priv",
-									"-cert", "/signingcert/public.der",
+									"-signer-socket", "/run/kmm-signer/signer.sock",
+									"-workdir", "/run/kmm-signer",
 									"-filestosign", filesToSign,
 								},
-								VolumeMounts: []v1.VolumeMount{secretMount, certMount},
+								VolumeMounts: []v1.VolumeMount{signerMount},
+							},
+							{
+								Name:  "signer",
+								Image: "quay.io/chrisp262/kmod-signer:latest",
+								Args: []string{
+									"-serve",
+									"-signer-socket", "/run/kmm-signer/signer.sock",
+									"-workdir", "/run/kmm-signer",
+									"-cert", "/signingcert/public.der",
+								},
+								Env: []v1.EnvVar{
+									{
+										Name: "SIGNING_KEY",
+										ValueFrom: &v1.EnvVarSource{
+											SecretKeyRef: &v1.SecretKeySelector{
+												LocalObjectReference: v1.LocalObjectReference{Name: "securebootkey"},
+												Key:                  "key",
+											},
+										},
+									},
+								},
+								VolumeMounts: []v1.VolumeMount{signerMount, certMount},
 							},
 						},
-						NodeSelector:  nodeSelector,
-						RestartPolicy: v1.RestartPolicyOnFailure,
+						NodeSelector:       nodeSelector,
+						RestartPolicy:      v1.RestartPolicyOnFailure,
+						ServiceAccountName: moduleName + "-build",
 
-						Volumes: []v1.Volume{keysecret, certsecret},
+						Volumes: []v1.Volume{signerVolume, certsecret},
 					},
 				},
 			},
@@ -211,6 +227,7 @@ var _ = Describe("MakeJobTemplate", func() {
 		mod := mod.DeepCopy()
 		mod.Spec.Selector = nodeSelector
 
+		clnt.EXPECT().List(ctx, &v1.NodeList{}, gomock.Any())
 		gomock.InOrder(
 			helper.EXPECT().GetRelevantSign(mod.Spec, km).Return(km.Sign),
 			clnt.EXPECT().Get(ctx, types.NamespacedName{Name: km.Sign.KeySecret.Name, Namespace: mod.Namespace}, gomock.Any()).DoAndReturn(
@@ -258,6 +275,7 @@ var _ = Describe("MakeJobTemplate", func() {
 			ContainerImage: unsignedImage,
 		}
 
+		clnt.EXPECT().List(ctx, &v1.NodeList{}, gomock.Any())
 		gomock.InOrder(
 			helper.EXPECT().GetRelevantSign(mod.Spec, km).Return(km.Sign),
 			clnt.EXPECT().Get(ctx, types.NamespacedName{Name: km.Sign.KeySecret.Name, Namespace: mod.Namespace}, gomock.Any()).DoAndReturn(
@@ -278,8 +296,8 @@ var _ = Describe("MakeJobTemplate", func() {
 
 		Expect(err).NotTo(HaveOccurred())
 		Expect(actual.Spec.Template.Spec.Containers[0].Args).To(ContainElement("-unsignedimage"))
-		Expect(actual.Spec.Template.Spec.Containers[0].Args).To(ContainElement("-key"))
-		Expect(actual.Spec.Template.Spec.Containers[0].Args).To(ContainElement("-cert"))
+		Expect(actual.Spec.Template.Spec.Containers[0].Args).To(ContainElement("-signer-socket"))
+		Expect(actual.Spec.Template.Spec.Containers[0].Args).NotTo(ContainElement("-key"))
 
 		if pushImage {
 			Expect(actual.Spec.Template.Spec.Containers[0].Args).To(ContainElement("-signedimage"))
@@ -310,6 +328,96 @@ var _ = Describe("MakeJobTemplate", func() {
 		),
 	)
 
+	It("should only expose the private key to the signer container", func() {
+		ctx := context.Background()
+		km := kmmv1beta1.KernelMapping{
+			Sign: &kmmv1beta1.Sign{
+				UnsignedImage: signedImage,
+				KeySecret:     &v1.LocalObjectReference{Name: "securebootkey"},
+				CertSecret:    &v1.LocalObjectReference{Name: "securebootcert"},
+			},
+			ContainerImage: unsignedImage,
+		}
+
+		mod.Spec.ImageRepoSecret = &v1.LocalObjectReference{Name: "pull-push-secret"}
+
+		clnt.EXPECT().List(ctx, &v1.NodeList{}, gomock.Any())
+		gomock.InOrder(
+			helper.EXPECT().GetRelevantSign(mod.Spec, km).Return(km.Sign),
+			clnt.EXPECT().Get(ctx, types.NamespacedName{Name: km.Sign.KeySecret.Name, Namespace: mod.Namespace}, gomock.Any()).DoAndReturn(
+				func(_ interface{}, _ interface{}, secret *v1.Secret, _ ...ctrlclient.GetOption) error {
+					secret.Data = privateSignData
+					return nil
+				},
+			),
+			clnt.EXPECT().Get(ctx, types.NamespacedName{Name: km.Sign.CertSecret.Name, Namespace: mod.Namespace}, gomock.Any()).DoAndReturn(
+				func(_ interface{}, _ interface{}, secret *v1.Secret, _ ...ctrlclient.GetOption) error {
+					secret.Data = publicSignData
+					return nil
+				},
+			),
+		)
+
+		actual, err := m.MakeJobTemplate(ctx, mod, km, kernelVersion, labels, "", true, &mod)
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, vol := range actual.Spec.Template.Spec.Volumes {
+			if vol.Secret != nil {
+				Expect(vol.Secret.SecretName).NotTo(Equal("securebootkey"))
+			}
+		}
+
+		signimage := actual.Spec.Template.Spec.Containers[0]
+		Expect(signimage.Env).To(BeEmpty())
+
+		signer := actual.Spec.Template.Spec.Containers[1]
+		Expect(signer.Name).To(Equal("signer"))
+		Expect(signer.Env).To(HaveLen(1))
+		Expect(signer.Env[0].ValueFrom.SecretKeyRef.Name).To(Equal("securebootkey"))
+		Expect(signer.VolumeMounts).NotTo(ContainElement(HaveField("MountPath", "/docker_config")))
+	})
+
+	It("should use the workload identity of the Module", func() {
+		ctx := context.Background()
+		km := kmmv1beta1.KernelMapping{
+			Sign: &kmmv1beta1.Sign{
+				UnsignedImage: signedImage,
+				KeySecret:     &v1.LocalObjectReference{Name: "securebootkey"},
+				CertSecret:    &v1.LocalObjectReference{Name: "securebootcert"},
+			},
+			ContainerImage: unsignedImage,
+		}
+
+		mod.Spec.WorkloadIdentity = &kmmv1beta1.WorkloadIdentity{
+			Provider: kmmv1beta1.WorkloadIdentityProviderAzure,
+			Identity: "some-client-id",
+		}
+
+		clnt.EXPECT().List(ctx, &v1.NodeList{}, gomock.Any())
+		gomock.InOrder(
+			helper.EXPECT().GetRelevantSign(mod.Spec, km).Return(km.Sign),
+			clnt.EXPECT().Get(ctx, types.NamespacedName{Name: km.Sign.KeySecret.Name, Namespace: mod.Namespace}, gomock.Any()).DoAndReturn(
+				func(_ interface{}, _ interface{}, secret *v1.Secret, _ ...ctrlclient.GetOption) error {
+					secret.Data = privateSignData
+					return nil
+				},
+			),
+			clnt.EXPECT().Get(ctx, types.NamespacedName{Name: km.Sign.CertSecret.Name, Namespace: mod.Namespace}, gomock.Any()).DoAndReturn(
+				func(_ interface{}, _ interface{}, secret *v1.Secret, _ ...ctrlclient.GetOption) error {
+					secret.Data = publicSignData
+					return nil
+				},
+			),
+		)
+
+		actual, err := m.MakeJobTemplate(ctx, mod, km, kernelVersion, labels, "", true, &mod)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actual.Spec.Template.Spec.Containers[0].Args).To(ContainElement("-workload-identity"))
+		Expect(actual.Spec.Template.Labels).To(HaveKeyWithValue("azure.workload.identity/use", "true"))
+		Expect(actual.Labels).NotTo(HaveKey("azure.workload.identity/use"))
+	})
+
 	DescribeTable("should set correct kmod-signer TLS flags", func(kmRegistryTLS,
 		unsignedImageRegistryTLS kmmv1beta1.TLSOptions, expectedFlag string) {
 		ctx := context.Background()
@@ -323,6 +431,7 @@ var _ = Describe("MakeJobTemplate", func() {
 			},
 		}
 
+		clnt.EXPECT().List(ctx, &v1.NodeList{}, gomock.Any())
 		gomock.InOrder(
 			helper.EXPECT().GetRelevantSign(mod.Spec, km).Return(km.Sign),
 			clnt.EXPECT().Get(ctx, types.NamespacedName{Name: km.Sign.KeySecret.Name, Namespace: mod.Namespace}, gomock.Any()).DoAndReturn(
@@ -377,4 +486,78 @@ var _ = Describe("MakeJobTemplate", func() {
 			"--skip-tls-verify-pull",
 		),
 	)
+
+	It("should refuse certificates that are not FIPS-compliant in FIPS mode", func() {
+		ctx := context.Background()
+		km := kmmv1beta1.KernelMapping{
+			Sign: &kmmv1beta1.Sign{
+				UnsignedImage: signedImage,
+				KeySecret:     &v1.LocalObjectReference{Name: "securebootkey"},
+				CertSecret:    &v1.LocalObjectReference{Name: "securebootcert"},
+			},
+			ContainerImage: unsignedImage,
+		}
+
+		clnt.EXPECT().List(ctx, &v1.NodeList{}, gomock.Any())
+		gomock.InOrder(
+			helper.EXPECT().GetRelevantSign(mod.Spec, km).Return(km.Sign),
+			clnt.EXPECT().Get(ctx, types.NamespacedName{Name: km.Sign.KeySecret.Name, Namespace: mod.Namespace}, gomock.Any()).DoAndReturn(
+				func(_ interface{}, _ interface{}, secret *v1.Secret, _ ...ctrlclient.GetOption) error {
+					secret.Data = privateSignData
+					return nil
+				},
+			),
+			clnt.EXPECT().Get(ctx, types.NamespacedName{Name: km.Sign.CertSecret.Name, Namespace: mod.Namespace}, gomock.Any()).DoAndReturn(
+				func(_ interface{}, _ interface{}, secret *v1.Secret, _ ...ctrlclient.GetOption) error {
+					secret.Data = publicSignData
+					return nil
+				},
+			),
+		)
+
+		_, err := NewSigner(clnt, scheme, helper, jobhelper, false, true).
+			MakeJobTemplate(ctx, mod, km, kernelVersion, labels, "", true, &mod)
+
+		Expect(err).To(MatchError(ContainSubstring("FIPS mode")))
+	})
+
+	It("should make sign pods satisfy the restricted Pod Security Standard in restricted mode", func() {
+		ctx := context.Background()
+		km := kmmv1beta1.KernelMapping{
+			Sign: &kmmv1beta1.Sign{
+				UnsignedImage: signedImage,
+				KeySecret:     &v1.LocalObjectReference{Name: "securebootkey"},
+				CertSecret:    &v1.LocalObjectReference{Name: "securebootcert"},
+			},
+			ContainerImage: unsignedImage,
+		}
+
+		clnt.EXPECT().List(ctx, &v1.NodeList{}, gomock.Any())
+		gomock.InOrder(
+			helper.EXPECT().GetRelevantSign(mod.Spec, km).Return(km.Sign),
+			clnt.EXPECT().Get(ctx, types.NamespacedName{Name: km.Sign.KeySecret.Name, Namespace: mod.Namespace}, gomock.Any()).DoAndReturn(
+				func(_ interface{}, _ interface{}, secret *v1.Secret, _ ...ctrlclient.GetOption) error {
+					secret.Data = privateSignData
+					return nil
+				},
+			),
+			clnt.EXPECT().Get(ctx, types.NamespacedName{Name: km.Sign.CertSecret.Name, Namespace: mod.Namespace}, gomock.Any()).DoAndReturn(
+				func(_ interface{}, _ interface{}, secret *v1.Secret, _ ...ctrlclient.GetOption) error {
+					secret.Data = publicSignData
+					return nil
+				},
+			),
+		)
+
+		actual, err := NewSigner(clnt, scheme, helper, jobhelper, true, false).
+			MakeJobTemplate(ctx, mod, km, kernelVersion, labels, "", true, &mod)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actual.Spec.Template.Spec.SecurityContext).To(Equal(podsecurity.RestrictedPodSecurityContext()))
+		Expect(actual.Spec.Template.Spec.Containers).To(HaveLen(2))
+
+		for _, c := range actual.Spec.Template.Spec.Containers {
+			Expect(c.SecurityContext).To(Equal(podsecurity.RestrictedSecurityContext()))
+		}
+	})
 })
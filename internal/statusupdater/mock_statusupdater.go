@@ -12,6 +12,7 @@ import (
 	v1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
 	v1 "k8s.io/api/apps/v1"
 	v10 "k8s.io/api/core/v1"
+	v11 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	sets "k8s.io/apimachinery/pkg/util/sets"
 )
 
@@ -38,6 +39,48 @@ func (m *MockModuleStatusUpdater) EXPECT() *MockModuleStatusUpdaterMockRecorder
 	return m.recorder
 }
 
+// ModuleRecordJobFailures mocks base method.
+func (m *MockModuleStatusUpdater) ModuleRecordJobFailures(ctx context.Context, mod *v1beta1.Module, failures []v1beta1.JobFailure) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ModuleRecordJobFailures", ctx, mod, failures)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ModuleRecordJobFailures indicates an expected call of ModuleRecordJobFailures.
+func (mr *MockModuleStatusUpdaterMockRecorder) ModuleRecordJobFailures(ctx, mod, failures interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ModuleRecordJobFailures", reflect.TypeOf((*MockModuleStatusUpdater)(nil).ModuleRecordJobFailures), ctx, mod, failures)
+}
+
+// ModuleSetCondition mocks base method.
+func (m *MockModuleStatusUpdater) ModuleSetCondition(ctx context.Context, mod *v1beta1.Module, condition v11.Condition) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ModuleSetCondition", ctx, mod, condition)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ModuleSetCondition indicates an expected call of ModuleSetCondition.
+func (mr *MockModuleStatusUpdaterMockRecorder) ModuleSetCondition(ctx, mod, condition interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ModuleSetCondition", reflect.TypeOf((*MockModuleStatusUpdater)(nil).ModuleSetCondition), ctx, mod, condition)
+}
+
+// ModuleSetKernelStatuses mocks base method.
+func (m *MockModuleStatusUpdater) ModuleSetKernelStatuses(ctx context.Context, mod *v1beta1.Module, kernels []v1beta1.KernelStatus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ModuleSetKernelStatuses", ctx, mod, kernels)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ModuleSetKernelStatuses indicates an expected call of ModuleSetKernelStatuses.
+func (mr *MockModuleStatusUpdaterMockRecorder) ModuleSetKernelStatuses(ctx, mod, kernels interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ModuleSetKernelStatuses", reflect.TypeOf((*MockModuleStatusUpdater)(nil).ModuleSetKernelStatuses), ctx, mod, kernels)
+}
+
 // ModuleUpdateStatus mocks base method.
 func (m *MockModuleStatusUpdater) ModuleUpdateStatus(ctx context.Context, mod *v1beta1.Module, kernelMappingNodes, targetedNodes []v10.Node, dsByKernelVersion map[string]*v1.DaemonSet) error {
 	m.ctrl.T.Helper()
@@ -89,6 +132,20 @@ func (mr *MockPreflightStatusUpdaterMockRecorder) PreflightPresetStatuses(ctx, p
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreflightPresetStatuses", reflect.TypeOf((*MockPreflightStatusUpdater)(nil).PreflightPresetStatuses), ctx, pv, existingModules, newModules)
 }
 
+// PreflightSetCondition mocks base method.
+func (m *MockPreflightStatusUpdater) PreflightSetCondition(ctx context.Context, preflight *v1beta1.PreflightValidation, condition v11.Condition) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreflightSetCondition", ctx, preflight, condition)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PreflightSetCondition indicates an expected call of PreflightSetCondition.
+func (mr *MockPreflightStatusUpdaterMockRecorder) PreflightSetCondition(ctx, preflight, condition interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreflightSetCondition", reflect.TypeOf((*MockPreflightStatusUpdater)(nil).PreflightSetCondition), ctx, preflight, condition)
+}
+
 // PreflightSetVerificationStage mocks base method.
 func (m *MockPreflightStatusUpdater) PreflightSetVerificationStage(ctx context.Context, preflight *v1beta1.PreflightValidation, moduleName, stage string) error {
 	m.ctrl.T.Helper()
@@ -3,6 +3,8 @@ package statusupdater
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
@@ -10,8 +12,12 @@ import (
 	"github.com/kubernetes-sigs/kernel-module-management/internal/metrics"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -20,8 +26,14 @@ import (
 type ModuleStatusUpdater interface {
 	ModuleUpdateStatus(ctx context.Context, mod *kmmv1beta1.Module, kernelMappingNodes []v1.Node,
 		targetedNodes []v1.Node, dsByKernelVersion map[string]*appsv1.DaemonSet) error
+	ModuleSetCondition(ctx context.Context, mod *kmmv1beta1.Module, condition metav1.Condition) error
+	ModuleSetKernelStatuses(ctx context.Context, mod *kmmv1beta1.Module, kernels []kmmv1beta1.KernelStatus) error
+	ModuleRecordJobFailures(ctx context.Context, mod *kmmv1beta1.Module, failures []kmmv1beta1.JobFailure) error
 }
 
+// maxJobFailuresPerKernel is the number of Job failures kept in the status of a Module for each kernel version.
+const maxJobFailuresPerKernel = 3
+
 //go:generate mockgen -source=statusupdater.go -package=statusupdater -destination=mock_statusupdater.go
 
 type PreflightStatusUpdater interface {
@@ -31,6 +43,7 @@ type PreflightStatusUpdater interface {
 		verificationStatus string, message string) error
 	PreflightSetVerificationStage(ctx context.Context, preflight *kmmv1beta1.PreflightValidation,
 		moduleName string, stage string) error
+	PreflightSetCondition(ctx context.Context, preflight *kmmv1beta1.PreflightValidation, condition metav1.Condition) error
 }
 
 type moduleStatusUpdater struct {
@@ -72,16 +85,234 @@ func (m *moduleStatusUpdater) ModuleUpdateStatus(ctx context.Context,
 			numAvailableKernelModule += ds.Status.NumberAvailable
 		}
 	}
-	mod.Status.ModuleLoader.NodesMatchingSelectorNumber = nodesMatchingSelectorNumber
-	mod.Status.ModuleLoader.DesiredNumber = numDesired
-	mod.Status.ModuleLoader.AvailableNumber = numAvailableKernelModule
-	if mod.Spec.DevicePlugin != nil {
-		mod.Status.DevicePlugin.NodesMatchingSelectorNumber = nodesMatchingSelectorNumber
-		mod.Status.DevicePlugin.DesiredNumber = numDesired
-		mod.Status.DevicePlugin.AvailableNumber = numAvailableDevicePlugin
-	}
 	m.updateMetrics(ctx, mod, dsByKernelVersion)
-	return m.client.Status().Update(ctx, mod)
+	return m.patchModuleStatus(ctx, mod, func(mod *kmmv1beta1.Module) {
+		mod.Status.ModuleLoader.NodesMatchingSelectorNumber = nodesMatchingSelectorNumber
+		mod.Status.ModuleLoader.DesiredNumber = numDesired
+		mod.Status.ModuleLoader.AvailableNumber = numAvailableKernelModule
+		if mod.Spec.DevicePlugin != nil {
+			mod.Status.DevicePlugin.NodesMatchingSelectorNumber = nodesMatchingSelectorNumber
+			mod.Status.DevicePlugin.DesiredNumber = numDesired
+			mod.Status.DevicePlugin.AvailableNumber = numAvailableDevicePlugin
+		}
+	})
+}
+
+// ModuleSetCondition adds condition to mod's status, or updates the existing condition of the same type.
+func (m *moduleStatusUpdater) ModuleSetCondition(ctx context.Context, mod *kmmv1beta1.Module, condition metav1.Condition) error {
+	return m.patchModuleStatus(ctx, mod, func(mod *kmmv1beta1.Module) {
+		meta.SetStatusCondition(&mod.Status.Conditions, condition)
+	})
+}
+
+// ModuleSetKernelStatuses replaces the kernel statuses of mod with kernels, sorted by kernel version.
+// The last transition time of a kernel is only updated when its phase changes, and that of its conditions when their
+// status changes. The previous conditions of a kernel are kept if kernels has none for it.
+func (m *moduleStatusUpdater) ModuleSetKernelStatuses(ctx context.Context, mod *kmmv1beta1.Module, kernels []kmmv1beta1.KernelStatus) error {
+	return m.patchModuleStatus(ctx, mod, func(mod *kmmv1beta1.Module) {
+		setKernelStatuses(mod, kernels)
+	})
+}
+
+// setKernelStatuses implements ModuleSetKernelStatuses on mod without writing it.
+func setKernelStatuses(mod *kmmv1beta1.Module, kernels []kmmv1beta1.KernelStatus) {
+	previous := make(map[string]kmmv1beta1.KernelStatus, len(mod.Status.Kernels))
+
+	for _, ks := range mod.Status.Kernels {
+		previous[ks.KernelVersion] = ks
+	}
+
+	now := metav1.Now()
+
+	statuses := make([]kmmv1beta1.KernelStatus, 0, len(kernels))
+
+	for _, ks := range kernels {
+		ks.LastTransitionTime = now
+
+		if p, ok := previous[ks.KernelVersion]; ok && p.Phase == ks.Phase {
+			ks.LastTransitionTime = p.LastTransitionTime
+		}
+
+		conditions := make([]metav1.Condition, 0, len(previous[ks.KernelVersion].Conditions))
+
+		for _, c := range previous[ks.KernelVersion].Conditions {
+			conditions = append(conditions, *c.DeepCopy())
+		}
+
+		for _, c := range ks.Conditions {
+			meta.SetStatusCondition(&conditions, c)
+		}
+
+		ks.Conditions = conditions
+
+		if len(conditions) == 0 {
+			ks.Conditions = nil
+		}
+
+		statuses = append(statuses, ks)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].KernelVersion < statuses[j].KernelVersion
+	})
+
+	mod.Status.Kernels = statuses
+
+	if len(statuses) == 0 {
+		mod.Status.Kernels = nil
+	}
+
+	setKernelSummary(&mod.Status)
+	meta.SetStatusCondition(&mod.Status.Conditions, progressingCondition(mod))
+}
+
+// ModuleRecordJobFailures adds failures to the Job failures of mod's status, most recent first.
+// Failures of Jobs that are already recorded are ignored, and only the maxJobFailuresPerKernel most recent failures of
+// each kernel version are kept.
+func (m *moduleStatusUpdater) ModuleRecordJobFailures(ctx context.Context, mod *kmmv1beta1.Module, failures []kmmv1beta1.JobFailure) error {
+	return m.patchModuleStatus(ctx, mod, func(mod *kmmv1beta1.Module) {
+		recordJobFailures(mod, failures)
+	})
+}
+
+// recordJobFailures implements ModuleRecordJobFailures on mod without writing it.
+func recordJobFailures(mod *kmmv1beta1.Module, failures []kmmv1beta1.JobFailure) {
+	all := make([]kmmv1beta1.JobFailure, 0, len(mod.Status.JobFailures)+len(failures))
+	recorded := sets.NewString()
+
+	for _, jf := range append(append([]kmmv1beta1.JobFailure{}, mod.Status.JobFailures...), failures...) {
+		if recorded.Has(jf.JobName) {
+			continue
+		}
+
+		recorded.Insert(jf.JobName)
+		all = append(all, jf)
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[j].FailureTime.Before(&all[i].FailureTime)
+	})
+
+	perKernel := make(map[string]int)
+	kept := make([]kmmv1beta1.JobFailure, 0, len(all))
+
+	for _, jf := range all {
+		if perKernel[jf.KernelVersion] >= maxJobFailuresPerKernel {
+			continue
+		}
+
+		perKernel[jf.KernelVersion]++
+		kept = append(kept, jf)
+	}
+
+	mod.Status.JobFailures = kept
+}
+
+// progressingCondition returns the Progressing condition of mod given the phases of its kernels.
+func progressingCondition(mod *kmmv1beta1.Module) metav1.Condition {
+	building := make([]string, 0)
+	signing := make([]string, 0)
+
+	for _, ks := range mod.Status.Kernels {
+		switch ks.Phase {
+		case kmmv1beta1.KernelPhaseBuilding:
+			building = append(building, ks.KernelVersion)
+		case kmmv1beta1.KernelPhaseSigning:
+			signing = append(signing, ks.KernelVersion)
+		}
+	}
+
+	if len(building) == 0 && len(signing) == 0 {
+		return metav1.Condition{
+			Type:               kmmv1beta1.ModuleConditionProgressing,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: mod.Generation,
+			Reason:             "NoJobInProgress",
+			Message:            "No build or sign Job is in progress",
+		}
+	}
+
+	waits := make([]string, 0, 2)
+
+	if len(building) > 0 {
+		waits = append(waits, "the build of kernels "+strings.Join(building, ", "))
+	}
+
+	if len(signing) > 0 {
+		waits = append(waits, "the signing of kernels "+strings.Join(signing, ", "))
+	}
+
+	return metav1.Condition{
+		Type:               kmmv1beta1.ModuleConditionProgressing,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: mod.Generation,
+		Reason:             "WaitingForJobs",
+		Message:            "Waiting for " + strings.Join(waits, " and "),
+	}
+}
+
+// phaseProgress orders phases from the least to the most advanced one.
+var phaseProgress = map[kmmv1beta1.KernelPhase]int{
+	kmmv1beta1.KernelPhaseFailed:    0,
+	kmmv1beta1.KernelPhasePending:   1,
+	kmmv1beta1.KernelPhaseBuilding:  2,
+	kmmv1beta1.KernelPhaseSigning:   3,
+	kmmv1beta1.KernelPhaseDeploying: 4,
+	kmmv1beta1.KernelPhaseReady:     5,
+}
+
+// setKernelSummary sets the fields of status that summarize its kernels, for kubectl get to print.
+func setKernelSummary(status *kmmv1beta1.ModuleStatus) {
+	status.Phase = ""
+	status.ReadyKernels = 0
+	status.TotalKernels = int32(len(status.Kernels))
+	status.LastTransitionTime = nil
+
+	for i := 0; i < len(status.Kernels); i++ {
+		ks := &status.Kernels[i]
+
+		if ks.Phase == kmmv1beta1.KernelPhaseReady {
+			status.ReadyKernels++
+		}
+
+		if status.Phase == "" || phaseProgress[ks.Phase] < phaseProgress[status.Phase] {
+			status.Phase = ks.Phase
+		}
+
+		if status.LastTransitionTime == nil || status.LastTransitionTime.Before(&ks.LastTransitionTime) {
+			status.LastTransitionTime = ks.LastTransitionTime.DeepCopy()
+		}
+	}
+}
+
+// patchModuleStatus applies mutate to mod's status and writes it to the API server using a merge patch, which only
+// holds the fields that mutate changed, with the resource version of mod as an optimistic lock.
+// Nothing is sent if mutate changed nothing. On conflict, mod is refreshed from the API server and mutate is applied
+// again, so that the status fields written in the meantime by other controllers, or other parts of the status, are
+// kept.
+func (m *moduleStatusUpdater) patchModuleStatus(ctx context.Context, mod *kmmv1beta1.Module, mutate func(*kmmv1beta1.Module)) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		base := mod.DeepCopy()
+
+		mutate(mod)
+
+		if equality.Semantic.DeepEqual(base.Status, mod.Status) {
+			return nil
+		}
+
+		patch := client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{})
+
+		err := m.client.Status().Patch(ctx, mod, patch)
+		if !k8serrors.IsConflict(err) {
+			return err
+		}
+
+		if getErr := m.client.Get(ctx, client.ObjectKeyFromObject(mod), mod); getErr != nil {
+			return fmt.Errorf("could not get the latest version of Module %s/%s: %w", mod.Namespace, mod.Name, getErr)
+		}
+
+		return err
+	})
 }
 
 func (p *preflightStatusUpdater) PreflightPresetStatuses(ctx context.Context,
@@ -124,6 +355,23 @@ func (p *preflightStatusUpdater) PreflightSetVerificationStage(ctx context.Conte
 	return p.client.Status().Update(ctx, pv)
 }
 
+// PreflightSetCondition adds condition to the status of pv, or updates the existing condition of the same type.
+// The status is not written if the condition did not change.
+func (p *preflightStatusUpdater) PreflightSetCondition(ctx context.Context, pv *kmmv1beta1.PreflightValidation,
+	condition metav1.Condition) error {
+	existing := meta.FindStatusCondition(pv.Status.Conditions, condition.Type)
+	if existing != nil &&
+		existing.Status == condition.Status &&
+		existing.Reason == condition.Reason &&
+		existing.Message == condition.Message {
+		return nil
+	}
+
+	meta.SetStatusCondition(&pv.Status.Conditions, condition)
+
+	return p.client.Status().Update(ctx, pv)
+}
+
 func (m *moduleStatusUpdater) updateMetrics(ctx context.Context, mod *kmmv1beta1.Module, dsByKernelVersion map[string]*appsv1.DaemonSet) {
 	for kernelVersion, ds := range dsByKernelVersion {
 		stage := metrics.ModuleLoaderStage
@@ -2,6 +2,7 @@ package statusupdater
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/golang/mock/gomock"
@@ -13,8 +14,13 @@ import (
 	. "github.com/onsi/gomega"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type daemonSetConfig struct {
@@ -70,9 +76,11 @@ var _ = Describe("module status update", func() {
 						ds.Status.NumberAvailable == ds.Status.DesiredNumberScheduled)
 				}
 			}
-			statusWrite := client.NewMockStatusWriter(ctrl)
-			clnt.EXPECT().Status().Return(statusWrite)
-			statusWrite.EXPECT().Update(context.Background(), mod).Return(nil)
+			if len(targetedNodes) > 0 {
+				statusWrite := client.NewMockStatusWriter(ctrl)
+				clnt.EXPECT().Status().Return(statusWrite)
+				statusWrite.EXPECT().Patch(context.Background(), mod, gomock.Any()).Return(nil)
+			}
 
 			res := su.ModuleUpdateStatus(context.Background(), mod, mappingsNodes, targetedNodes, dsMap)
 
@@ -115,6 +123,412 @@ var _ = Describe("module status update", func() {
 			true,
 		),
 	)
+
+	It("should not write the status if it did not change", func() {
+		mod.Status.ModuleLoader = kmmv1beta1.DaemonSetStatus{
+			NodesMatchingSelectorNumber: 1,
+			DesiredNumber:               1,
+		}
+
+		res := su.ModuleUpdateStatus(context.Background(), mod, []v1.Node{{}}, []v1.Node{{}}, nil)
+		Expect(res).To(BeNil())
+	})
+
+	It("should refresh the Module and retry on conflict", func() {
+		ctx := context.Background()
+		statusWrite := client.NewMockStatusWriter(ctrl)
+
+		gomock.InOrder(
+			clnt.EXPECT().Status().Return(statusWrite),
+			statusWrite.EXPECT().Patch(ctx, mod, gomock.Any()).Return(
+				apierrors.NewConflict(schema.GroupResource{}, name, errors.New("some-error")),
+			),
+			clnt.EXPECT().Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, gomock.Any()).DoAndReturn(
+				func(_ interface{}, _ interface{}, m *kmmv1beta1.Module, _ ...ctrlclient.GetOption) error {
+					*m = kmmv1beta1.Module{
+						ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, ResourceVersion: "2"},
+					}
+					return nil
+				},
+			),
+			clnt.EXPECT().Status().Return(statusWrite),
+			statusWrite.EXPECT().Patch(ctx, mod, gomock.Any()).Return(nil),
+		)
+
+		res := su.ModuleUpdateStatus(ctx, mod, []v1.Node{{}}, []v1.Node{{}, {}}, nil)
+		Expect(res).To(BeNil())
+		Expect(mod.ResourceVersion).To(Equal("2"))
+		Expect(mod.Status.ModuleLoader.NodesMatchingSelectorNumber).To(Equal(int32(2)))
+		Expect(mod.Status.ModuleLoader.DesiredNumber).To(Equal(int32(1)))
+	})
+
+	It("should keep the status fields written concurrently by other controllers on conflict", func() {
+		ctx := context.Background()
+		statusWrite := client.NewMockStatusWriter(ctrl)
+
+		gomock.InOrder(
+			clnt.EXPECT().Status().Return(statusWrite),
+			statusWrite.EXPECT().Patch(ctx, mod, gomock.Any()).Return(
+				apierrors.NewConflict(schema.GroupResource{}, name, errors.New("some-error")),
+			),
+			clnt.EXPECT().Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, gomock.Any()).DoAndReturn(
+				func(_ interface{}, _ interface{}, m *kmmv1beta1.Module, _ ...ctrlclient.GetOption) error {
+					m.ObjectMeta = metav1.ObjectMeta{Name: name, Namespace: namespace, ResourceVersion: "2"}
+					m.Status = kmmv1beta1.ModuleStatus{NodesPendingReboot: []string{"node-1"}}
+					return nil
+				},
+			),
+			clnt.EXPECT().Status().Return(statusWrite),
+			statusWrite.EXPECT().Patch(ctx, mod, gomock.Any()).Do(
+				func(_ context.Context, m *kmmv1beta1.Module, p ctrlclient.Patch, _ ...ctrlclient.PatchOption) {
+					data, err := p.Data(m)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(data)).NotTo(ContainSubstring("nodesPendingReboot"))
+				},
+			),
+		)
+
+		res := su.ModuleUpdateStatus(ctx, mod, []v1.Node{{}}, []v1.Node{{}, {}}, nil)
+		Expect(res).To(BeNil())
+		Expect(mod.Status.NodesPendingReboot).To(Equal([]string{"node-1"}))
+		Expect(mod.Status.ModuleLoader.NodesMatchingSelectorNumber).To(Equal(int32(2)))
+	})
+})
+
+var _ = Describe("ModuleSetCondition", func() {
+	const (
+		name      = "sr-name"
+		namespace = "sr-namespace"
+	)
+
+	var (
+		ctrl *gomock.Controller
+		clnt *client.MockClient
+		mod  *kmmv1beta1.Module
+		su   ModuleStatusUpdater
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+		mod = &kmmv1beta1.Module{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+		su = NewModuleStatusUpdater(clnt, nil)
+	})
+
+	It("should add the condition", func() {
+		ctx := context.Background()
+		statusWrite := client.NewMockStatusWriter(ctrl)
+
+		gomock.InOrder(
+			clnt.EXPECT().Status().Return(statusWrite),
+			statusWrite.EXPECT().Patch(ctx, mod, gomock.Any()).Return(nil),
+		)
+
+		cond := metav1.Condition{
+			Type:    kmmv1beta1.ModuleConditionDegraded,
+			Status:  metav1.ConditionTrue,
+			Reason:  "SomeReason",
+			Message: "some message",
+		}
+
+		Expect(
+			su.ModuleSetCondition(ctx, mod, cond),
+		).NotTo(
+			HaveOccurred(),
+		)
+		Expect(mod.Status.Conditions).To(HaveLen(1))
+		Expect(mod.Status.Conditions[0].Status).To(Equal(metav1.ConditionTrue))
+		Expect(mod.Status.Conditions[0].Message).To(Equal("some message"))
+	})
+
+	It("should not write the status if the condition did not change", func() {
+		cond := metav1.Condition{
+			Type:   kmmv1beta1.ModuleConditionDegraded,
+			Status: metav1.ConditionFalse,
+			Reason: "SomeReason",
+		}
+
+		mod.Status.Conditions = []metav1.Condition{cond}
+
+		Expect(
+			su.ModuleSetCondition(context.Background(), mod, cond),
+		).NotTo(
+			HaveOccurred(),
+		)
+	})
+})
+
+var _ = Describe("ModuleSetKernelStatuses", func() {
+	const (
+		name      = "sr-name"
+		namespace = "sr-namespace"
+	)
+
+	var (
+		ctrl *gomock.Controller
+		clnt *client.MockClient
+		mod  *kmmv1beta1.Module
+		su   ModuleStatusUpdater
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+		mod = &kmmv1beta1.Module{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+		su = NewModuleStatusUpdater(clnt, nil)
+	})
+
+	It("should only update the transition time of the kernels whose phase changed", func() {
+		ctx := context.Background()
+		statusWrite := client.NewMockStatusWriter(ctrl)
+
+		past := metav1.Unix(1000, 0)
+
+		mod.Status.Kernels = []kmmv1beta1.KernelStatus{
+			{KernelVersion: "1.2.3", Phase: kmmv1beta1.KernelPhaseBuilding, LastTransitionTime: past},
+			{KernelVersion: "4.5.6", Phase: kmmv1beta1.KernelPhaseReady, LastTransitionTime: past},
+			{KernelVersion: "7.8.9", Phase: kmmv1beta1.KernelPhaseReady, LastTransitionTime: past},
+		}
+
+		gomock.InOrder(
+			clnt.EXPECT().Status().Return(statusWrite),
+			statusWrite.EXPECT().Patch(ctx, mod, gomock.Any()).Return(nil),
+		)
+
+		kernels := []kmmv1beta1.KernelStatus{
+			{KernelVersion: "4.5.6", Phase: kmmv1beta1.KernelPhaseReady},
+			{KernelVersion: "1.2.3", Phase: kmmv1beta1.KernelPhaseFailed, Message: "some error"},
+		}
+
+		Expect(
+			su.ModuleSetKernelStatuses(ctx, mod, kernels),
+		).NotTo(
+			HaveOccurred(),
+		)
+
+		Expect(mod.Status.Kernels).To(HaveLen(2))
+		Expect(mod.Status.Kernels[0].KernelVersion).To(Equal("1.2.3"))
+		Expect(mod.Status.Kernels[0].Phase).To(Equal(kmmv1beta1.KernelPhaseFailed))
+		Expect(mod.Status.Kernels[0].Message).To(Equal("some error"))
+		Expect(mod.Status.Kernels[0].LastTransitionTime.After(past.Time)).To(BeTrue())
+		Expect(mod.Status.Kernels[1].KernelVersion).To(Equal("4.5.6"))
+		Expect(mod.Status.Kernels[1].LastTransitionTime).To(Equal(past))
+	})
+
+	It("should summarize the kernels", func() {
+		ctx := context.Background()
+		statusWrite := client.NewMockStatusWriter(ctrl)
+
+		gomock.InOrder(
+			clnt.EXPECT().Status().Return(statusWrite),
+			statusWrite.EXPECT().Patch(ctx, mod, gomock.Any()).Return(nil),
+		)
+
+		kernels := []kmmv1beta1.KernelStatus{
+			{KernelVersion: "1.2.3", Phase: kmmv1beta1.KernelPhaseReady},
+			{KernelVersion: "4.5.6", Phase: kmmv1beta1.KernelPhaseSigning},
+			{KernelVersion: "7.8.9", Phase: kmmv1beta1.KernelPhaseDeploying},
+		}
+
+		Expect(
+			su.ModuleSetKernelStatuses(ctx, mod, kernels),
+		).NotTo(
+			HaveOccurred(),
+		)
+
+		Expect(mod.Status.Phase).To(Equal(kmmv1beta1.KernelPhaseSigning))
+		Expect(mod.Status.ReadyKernels).To(Equal(int32(1)))
+		Expect(mod.Status.TotalKernels).To(Equal(int32(3)))
+		Expect(mod.Status.LastTransitionTime).NotTo(BeNil())
+		Expect(*mod.Status.LastTransitionTime).To(Equal(mod.Status.Kernels[0].LastTransitionTime))
+	})
+
+	It("should explain which Jobs the Module is waiting for", func() {
+		ctx := context.Background()
+		statusWrite := client.NewMockStatusWriter(ctrl)
+
+		gomock.InOrder(
+			clnt.EXPECT().Status().Return(statusWrite),
+			statusWrite.EXPECT().Patch(ctx, mod, gomock.Any()).Return(nil),
+		)
+
+		kernels := []kmmv1beta1.KernelStatus{
+			{KernelVersion: "7.8.9", Phase: kmmv1beta1.KernelPhaseSigning},
+			{KernelVersion: "4.5.6", Phase: kmmv1beta1.KernelPhaseBuilding},
+			{KernelVersion: "1.2.3", Phase: kmmv1beta1.KernelPhaseBuilding},
+			{KernelVersion: "0.1.2", Phase: kmmv1beta1.KernelPhaseReady},
+		}
+
+		Expect(
+			su.ModuleSetKernelStatuses(ctx, mod, kernels),
+		).NotTo(
+			HaveOccurred(),
+		)
+
+		cond := meta.FindStatusCondition(mod.Status.Conditions, kmmv1beta1.ModuleConditionProgressing)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal("WaitingForJobs"))
+		Expect(cond.Message).To(Equal("Waiting for the build of kernels 1.2.3, 4.5.6 and the signing of kernels 7.8.9"))
+	})
+
+	It("should only update the transition time of the kernel conditions whose status changed", func() {
+		ctx := context.Background()
+		statusWrite := client.NewMockStatusWriter(ctrl)
+
+		past := metav1.Unix(1000, 0)
+
+		mod.Status.Kernels = []kmmv1beta1.KernelStatus{
+			{
+				KernelVersion:      "1.2.3",
+				Phase:              kmmv1beta1.KernelPhaseSigning,
+				LastTransitionTime: past,
+				Conditions: []metav1.Condition{
+					{Type: kmmv1beta1.KernelConditionBuilt, Status: metav1.ConditionTrue, Reason: "Succeeded", LastTransitionTime: past},
+					{Type: kmmv1beta1.KernelConditionSigned, Status: metav1.ConditionFalse, Reason: "Signing", LastTransitionTime: past},
+				},
+			},
+			{
+				KernelVersion:      "4.5.6",
+				Phase:              kmmv1beta1.KernelPhaseDeploying,
+				LastTransitionTime: past,
+				Conditions: []metav1.Condition{
+					{Type: kmmv1beta1.KernelConditionRolledOut, Status: metav1.ConditionFalse, Reason: "Deploying", LastTransitionTime: past},
+				},
+			},
+		}
+
+		gomock.InOrder(
+			clnt.EXPECT().Status().Return(statusWrite),
+			statusWrite.EXPECT().Patch(ctx, mod, gomock.Any()).Return(nil),
+		)
+
+		kernels := []kmmv1beta1.KernelStatus{
+			{
+				KernelVersion: "1.2.3",
+				Phase:         kmmv1beta1.KernelPhaseFailed,
+				Conditions: []metav1.Condition{
+					{Type: kmmv1beta1.KernelConditionBuilt, Status: metav1.ConditionTrue, Reason: "Succeeded"},
+					{Type: kmmv1beta1.KernelConditionSigned, Status: metav1.ConditionFalse, Reason: "JobFailed"},
+				},
+			},
+			{KernelVersion: "4.5.6", Phase: kmmv1beta1.KernelPhasePending},
+		}
+
+		Expect(
+			su.ModuleSetKernelStatuses(ctx, mod, kernels),
+		).NotTo(
+			HaveOccurred(),
+		)
+
+		signed := meta.FindStatusCondition(mod.Status.Kernels[0].Conditions, kmmv1beta1.KernelConditionSigned)
+		Expect(signed).NotTo(BeNil())
+		Expect(signed.Reason).To(Equal("JobFailed"))
+		Expect(signed.LastTransitionTime).To(Equal(past))
+
+		// Pending kernels keep their previous conditions.
+		Expect(mod.Status.Kernels[1].Conditions).To(Equal([]metav1.Condition{
+			{Type: kmmv1beta1.KernelConditionRolledOut, Status: metav1.ConditionFalse, Reason: "Deploying", LastTransitionTime: past},
+		}))
+	})
+
+	It("should not write the status if no phase changed", func() {
+		past := metav1.Unix(1000, 0)
+
+		mod.Status = kmmv1beta1.ModuleStatus{
+			Kernels: []kmmv1beta1.KernelStatus{
+				{KernelVersion: "1.2.3", Phase: kmmv1beta1.KernelPhaseReady, LastTransitionTime: past},
+			},
+			Phase:              kmmv1beta1.KernelPhaseReady,
+			ReadyKernels:       1,
+			TotalKernels:       1,
+			LastTransitionTime: &past,
+			Conditions: []metav1.Condition{
+				{
+					Type:               kmmv1beta1.ModuleConditionProgressing,
+					Status:             metav1.ConditionFalse,
+					LastTransitionTime: past,
+					Reason:             "NoJobInProgress",
+					Message:            "No build or sign Job is in progress",
+				},
+			},
+		}
+
+		Expect(
+			su.ModuleSetKernelStatuses(
+				context.Background(),
+				mod,
+				[]kmmv1beta1.KernelStatus{{KernelVersion: "1.2.3", Phase: kmmv1beta1.KernelPhaseReady}},
+			),
+		).NotTo(
+			HaveOccurred(),
+		)
+	})
+})
+
+var _ = Describe("ModuleRecordJobFailures", func() {
+	var (
+		ctrl *gomock.Controller
+		clnt *client.MockClient
+		mod  *kmmv1beta1.Module
+		su   ModuleStatusUpdater
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+		mod = &kmmv1beta1.Module{ObjectMeta: metav1.ObjectMeta{Name: "name", Namespace: "namespace"}}
+		su = NewModuleStatusUpdater(clnt, nil)
+	})
+
+	failure := func(kernelVersion, jobName string, sec int64) kmmv1beta1.JobFailure {
+		return kmmv1beta1.JobFailure{
+			KernelVersion: kernelVersion,
+			JobType:       "build",
+			JobName:       jobName,
+			FailureTime:   metav1.Unix(sec, 0),
+		}
+	}
+
+	It("should keep the most recent failures of each kernel, most recent first", func() {
+		ctx := context.Background()
+		statusWrite := client.NewMockStatusWriter(ctrl)
+
+		mod.Status.JobFailures = []kmmv1beta1.JobFailure{
+			failure("1.2.3", "job-3", 3000),
+			failure("1.2.3", "job-2", 2000),
+			failure("1.2.3", "job-1", 1000),
+			failure("4.5.6", "job-a", 1500),
+		}
+
+		gomock.InOrder(
+			clnt.EXPECT().Status().Return(statusWrite),
+			statusWrite.EXPECT().Patch(ctx, mod, gomock.Any()).Return(nil),
+		)
+
+		Expect(
+			su.ModuleRecordJobFailures(ctx, mod, []kmmv1beta1.JobFailure{failure("1.2.3", "job-4", 4000)}),
+		).NotTo(
+			HaveOccurred(),
+		)
+
+		Expect(mod.Status.JobFailures).To(Equal([]kmmv1beta1.JobFailure{
+			failure("1.2.3", "job-4", 4000),
+			failure("1.2.3", "job-3", 3000),
+			failure("1.2.3", "job-2", 2000),
+			failure("4.5.6", "job-a", 1500),
+		}))
+	})
+
+	It("should not write the status if the failures are already recorded", func() {
+		mod.Status.JobFailures = []kmmv1beta1.JobFailure{failure("1.2.3", "job-1", 1000)}
+
+		Expect(
+			su.ModuleRecordJobFailures(context.Background(), mod, []kmmv1beta1.JobFailure{failure("1.2.3", "job-1", 1000)}),
+		).NotTo(
+			HaveOccurred(),
+		)
+	})
 })
 
 var _ = Describe("preflight status updates", func() {
@@ -182,6 +596,38 @@ var _ = Describe("preflight status updates", func() {
 		Expect(res).To(BeNil())
 		Expect(pv.Status.CRStatuses[moduleName].VerificationStage).To(Equal("verificationStage"))
 	})
+
+	It("set preflight condition", func() {
+		cond := metav1.Condition{
+			Type:    kmmv1beta1.PreflightValidationConditionReady,
+			Status:  metav1.ConditionTrue,
+			Reason:  "Verified",
+			Message: "some message",
+		}
+
+		statusWrite := client.NewMockStatusWriter(ctrl)
+		clnt.EXPECT().Status().Return(statusWrite)
+		statusWrite.EXPECT().Update(context.Background(), pv).Return(nil)
+
+		res := su.PreflightSetCondition(context.Background(), pv, cond)
+		Expect(res).To(BeNil())
+		Expect(pv.Status.Conditions).To(HaveLen(1))
+		Expect(pv.Status.Conditions[0].Status).To(Equal(metav1.ConditionTrue))
+	})
+
+	It("should not write the preflight status if the condition did not change", func() {
+		cond := metav1.Condition{
+			Type:    kmmv1beta1.PreflightValidationConditionReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  "NotVerified",
+			Message: "some message",
+		}
+
+		pv.Status.Conditions = []metav1.Condition{cond}
+
+		res := su.PreflightSetCondition(context.Background(), pv, cond)
+		Expect(res).To(BeNil())
+	})
 })
 
 func getDaemonSet(kernelNumber int, dsConfig daemonSetConfig) (string, *appsv1.DaemonSet) {
@@ -0,0 +1,226 @@
+package utils
+
+import (
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+)
+
+// Values set by the API server on the fields left empty; see k8s.io/kubernetes/pkg/apis/{apps,core}/v1/defaults.go.
+const (
+	defaultProgressDeadlineSeconds       = 600
+	defaultRevisionHistoryLimit          = 10
+	defaultTerminationGracePeriodSeconds = 30
+	defaultVolumeMode                    = 0644
+)
+
+// SetDaemonSetDefaults sets the fields of spec that the API server would default, to the values it would set.
+// Objects generated with all their defaults are equal to those stored by the API server, so that updating them with
+// the same spec is a no-op and tools comparing them to their live version, such as GitOps agents, see no drift.
+func SetDaemonSetDefaults(spec *appsv1.DaemonSetSpec) {
+	if spec.RevisionHistoryLimit == nil {
+		spec.RevisionHistoryLimit = pointer.Int32(defaultRevisionHistoryLimit)
+	}
+
+	if spec.UpdateStrategy.Type == "" {
+		spec.UpdateStrategy.Type = appsv1.RollingUpdateDaemonSetStrategyType
+	}
+
+	if spec.UpdateStrategy.Type == appsv1.RollingUpdateDaemonSetStrategyType {
+		if spec.UpdateStrategy.RollingUpdate == nil {
+			spec.UpdateStrategy.RollingUpdate = &appsv1.RollingUpdateDaemonSet{}
+		}
+
+		ru := spec.UpdateStrategy.RollingUpdate
+
+		if ru.MaxUnavailable == nil {
+			maxUnavailable := intstr.FromInt(1)
+			ru.MaxUnavailable = &maxUnavailable
+		}
+
+		if ru.MaxSurge == nil {
+			maxSurge := intstr.FromInt(0)
+			ru.MaxSurge = &maxSurge
+		}
+	}
+
+	SetPodSpecDefaults(&spec.Template.Spec)
+}
+
+// SetDeploymentDefaults sets the fields of spec that the API server would default, to the values it would set; see
+// SetDaemonSetDefaults.
+func SetDeploymentDefaults(spec *appsv1.DeploymentSpec) {
+	if spec.Replicas == nil {
+		spec.Replicas = pointer.Int32(1)
+	}
+
+	if spec.RevisionHistoryLimit == nil {
+		spec.RevisionHistoryLimit = pointer.Int32(defaultRevisionHistoryLimit)
+	}
+
+	if spec.ProgressDeadlineSeconds == nil {
+		spec.ProgressDeadlineSeconds = pointer.Int32(defaultProgressDeadlineSeconds)
+	}
+
+	if spec.Strategy.Type == "" {
+		spec.Strategy.Type = appsv1.RollingUpdateDeploymentStrategyType
+	}
+
+	if spec.Strategy.Type == appsv1.RollingUpdateDeploymentStrategyType {
+		if spec.Strategy.RollingUpdate == nil {
+			spec.Strategy.RollingUpdate = &appsv1.RollingUpdateDeployment{}
+		}
+
+		ru := spec.Strategy.RollingUpdate
+
+		if ru.MaxUnavailable == nil {
+			maxUnavailable := intstr.FromString("25%")
+			ru.MaxUnavailable = &maxUnavailable
+		}
+
+		if ru.MaxSurge == nil {
+			maxSurge := intstr.FromString("25%")
+			ru.MaxSurge = &maxSurge
+		}
+	}
+
+	SetPodSpecDefaults(&spec.Template.Spec)
+}
+
+// SetPodSpecDefaults sets the fields of spec that the API server would default, to the values it would set.
+// Containers and volumes are copied first, as they often share pointers with the object spec was generated from.
+func SetPodSpecDefaults(spec *v1.PodSpec) {
+	spec.InitContainers = copyContainers(spec.InitContainers)
+	spec.Containers = copyContainers(spec.Containers)
+
+	if spec.Volumes != nil {
+		volumes := make([]v1.Volume, 0, len(spec.Volumes))
+
+		for _, vol := range spec.Volumes {
+			volumes = append(volumes, *vol.DeepCopy())
+		}
+
+		spec.Volumes = volumes
+	}
+
+	if spec.RestartPolicy == "" {
+		spec.RestartPolicy = v1.RestartPolicyAlways
+	}
+
+	if spec.DNSPolicy == "" {
+		spec.DNSPolicy = v1.DNSClusterFirst
+	}
+
+	if spec.SchedulerName == "" {
+		spec.SchedulerName = v1.DefaultSchedulerName
+	}
+
+	if spec.SecurityContext == nil {
+		spec.SecurityContext = &v1.PodSecurityContext{}
+	}
+
+	if spec.TerminationGracePeriodSeconds == nil {
+		spec.TerminationGracePeriodSeconds = pointer.Int64(defaultTerminationGracePeriodSeconds)
+	}
+
+	for i := range spec.InitContainers {
+		setContainerDefaults(&spec.InitContainers[i])
+	}
+
+	for i := range spec.Containers {
+		setContainerDefaults(&spec.Containers[i])
+	}
+
+	for i := range spec.Volumes {
+		setVolumeDefaults(&spec.Volumes[i])
+	}
+}
+
+func copyContainers(containers []v1.Container) []v1.Container {
+	if containers == nil {
+		return nil
+	}
+
+	res := make([]v1.Container, 0, len(containers))
+
+	for _, c := range containers {
+		res = append(res, *c.DeepCopy())
+	}
+
+	return res
+}
+
+func setContainerDefaults(c *v1.Container) {
+	if c.ImagePullPolicy == "" {
+		c.ImagePullPolicy = defaultPullPolicy(c.Image)
+	}
+
+	if c.TerminationMessagePath == "" {
+		c.TerminationMessagePath = v1.TerminationMessagePathDefault
+	}
+
+	if c.TerminationMessagePolicy == "" {
+		c.TerminationMessagePolicy = v1.TerminationMessageReadFile
+	}
+
+	for i := range c.Env {
+		if fr := c.Env[i].ValueFrom; fr != nil && fr.FieldRef != nil && fr.FieldRef.APIVersion == "" {
+			fr.FieldRef.APIVersion = "v1"
+		}
+	}
+
+	for i := range c.Ports {
+		if c.Ports[i].Protocol == "" {
+			c.Ports[i].Protocol = v1.ProtocolTCP
+		}
+	}
+
+	// Resources that have a limit but no request are requested up to their limit.
+	for name, limit := range c.Resources.Limits {
+		if _, ok := c.Resources.Requests[name]; ok {
+			continue
+		}
+
+		if c.Resources.Requests == nil {
+			c.Resources.Requests = make(v1.ResourceList, len(c.Resources.Limits))
+		}
+
+		c.Resources.Requests[name] = limit.DeepCopy()
+	}
+}
+
+// defaultPullPolicy returns Always for images that are untagged or tagged latest, and IfNotPresent otherwise.
+func defaultPullPolicy(image string) v1.PullPolicy {
+	if strings.Contains(image, "@") {
+		return v1.PullIfNotPresent
+	}
+
+	name := image[strings.LastIndex(image, "/")+1:]
+
+	if i := strings.LastIndex(name, ":"); i == -1 || name[i+1:] == "latest" {
+		return v1.PullAlways
+	}
+
+	return v1.PullIfNotPresent
+}
+
+func setVolumeDefaults(vol *v1.Volume) {
+	switch {
+	case vol.HostPath != nil:
+		if vol.HostPath.Type == nil {
+			unset := v1.HostPathUnset
+			vol.HostPath.Type = &unset
+		}
+	case vol.Secret != nil:
+		if vol.Secret.DefaultMode == nil {
+			vol.Secret.DefaultMode = pointer.Int32(defaultVolumeMode)
+		}
+	case vol.ConfigMap != nil:
+		if vol.ConfigMap.DefaultMode == nil {
+			vol.ConfigMap.DefaultMode = pointer.Int32(defaultVolumeMode)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+)
+
+var _ = Describe("SetDaemonSetDefaults", func() {
+	It("should set the defaults of the API server", func() {
+		spec := appsv1.DaemonSetSpec{}
+
+		SetDaemonSetDefaults(&spec)
+
+		maxUnavailable := intstr.FromInt(1)
+		maxSurge := intstr.FromInt(0)
+
+		Expect(spec.RevisionHistoryLimit).To(Equal(pointer.Int32(10)))
+		Expect(spec.UpdateStrategy).To(Equal(appsv1.DaemonSetUpdateStrategy{
+			Type: appsv1.RollingUpdateDaemonSetStrategyType,
+			RollingUpdate: &appsv1.RollingUpdateDaemonSet{
+				MaxUnavailable: &maxUnavailable,
+				MaxSurge:       &maxSurge,
+			},
+		}))
+		Expect(spec.Template.Spec.RestartPolicy).To(Equal(v1.RestartPolicyAlways))
+	})
+
+	It("should not override the values already set", func() {
+		spec := appsv1.DaemonSetSpec{
+			RevisionHistoryLimit: pointer.Int32(3),
+			UpdateStrategy:       appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType},
+		}
+
+		SetDaemonSetDefaults(&spec)
+
+		Expect(spec.RevisionHistoryLimit).To(Equal(pointer.Int32(3)))
+		Expect(spec.UpdateStrategy).To(Equal(appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType}))
+	})
+})
+
+var _ = Describe("SetDeploymentDefaults", func() {
+	It("should set the defaults of the API server", func() {
+		spec := appsv1.DeploymentSpec{}
+
+		SetDeploymentDefaults(&spec)
+
+		maxUnavailable := intstr.FromString("25%")
+		maxSurge := intstr.FromString("25%")
+
+		Expect(spec.Replicas).To(Equal(pointer.Int32(1)))
+		Expect(spec.RevisionHistoryLimit).To(Equal(pointer.Int32(10)))
+		Expect(spec.ProgressDeadlineSeconds).To(Equal(pointer.Int32(600)))
+		Expect(spec.Strategy).To(Equal(appsv1.DeploymentStrategy{
+			Type: appsv1.RollingUpdateDeploymentStrategyType,
+			RollingUpdate: &appsv1.RollingUpdateDeployment{
+				MaxUnavailable: &maxUnavailable,
+				MaxSurge:       &maxSurge,
+			},
+		}))
+		Expect(spec.Template.Spec.RestartPolicy).To(Equal(v1.RestartPolicyAlways))
+	})
+
+	It("should not override the values already set", func() {
+		spec := appsv1.DeploymentSpec{
+			Replicas: pointer.Int32(0),
+			Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
+		}
+
+		SetDeploymentDefaults(&spec)
+
+		Expect(spec.Replicas).To(Equal(pointer.Int32(0)))
+		Expect(spec.Strategy).To(Equal(appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}))
+	})
+})
+
+var _ = Describe("SetPodSpecDefaults", func() {
+	It("should set the defaults of the API server without modifying the source of the spec", func() {
+		secretVolumeSource := v1.SecretVolumeSource{SecretName: "some-secret"}
+		env := []v1.EnvVar{
+			{
+				Name:      "NODE_NAME",
+				ValueFrom: &v1.EnvVarSource{FieldRef: &v1.ObjectFieldSelector{FieldPath: "spec.nodeName"}},
+			},
+		}
+
+		spec := v1.PodSpec{
+			Containers: []v1.Container{
+				{Name: "c0", Image: "example.com/image", Env: env},
+				{Name: "c1", Image: "example.com:5000/image:v1", ImagePullPolicy: v1.PullNever},
+			},
+			Volumes: []v1.Volume{
+				{
+					Name:         "secret",
+					VolumeSource: v1.VolumeSource{Secret: &secretVolumeSource},
+				},
+			},
+		}
+
+		SetPodSpecDefaults(&spec)
+
+		Expect(spec.RestartPolicy).To(Equal(v1.RestartPolicyAlways))
+		Expect(spec.DNSPolicy).To(Equal(v1.DNSClusterFirst))
+		Expect(spec.SchedulerName).To(Equal(v1.DefaultSchedulerName))
+		Expect(spec.SecurityContext).To(Equal(&v1.PodSecurityContext{}))
+		Expect(spec.TerminationGracePeriodSeconds).To(Equal(pointer.Int64(30)))
+
+		Expect(spec.Containers[0].ImagePullPolicy).To(Equal(v1.PullAlways))
+		Expect(spec.Containers[0].TerminationMessagePath).To(Equal(v1.TerminationMessagePathDefault))
+		Expect(spec.Containers[0].TerminationMessagePolicy).To(Equal(v1.TerminationMessageReadFile))
+		Expect(spec.Containers[0].Env[0].ValueFrom.FieldRef.APIVersion).To(Equal("v1"))
+		Expect(spec.Containers[1].ImagePullPolicy).To(Equal(v1.PullNever))
+		Expect(spec.Volumes[0].Secret.DefaultMode).To(Equal(pointer.Int32(0644)))
+
+		Expect(env[0].ValueFrom.FieldRef.APIVersion).To(BeEmpty())
+		Expect(secretVolumeSource.DefaultMode).To(BeNil())
+	})
+
+	It("should request the resources that only have a limit", func() {
+		limits := v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("500m"),
+			v1.ResourceMemory: resource.MustParse("256Mi"),
+		}
+
+		spec := v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name:  "c0",
+					Image: "example.com/image:v1",
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("10m")},
+						Limits:   limits,
+					},
+				},
+				{
+					Name:      "c1",
+					Image:     "example.com/image:v1",
+					Resources: v1.ResourceRequirements{Limits: limits},
+				},
+			},
+		}
+
+		SetPodSpecDefaults(&spec)
+
+		Expect(spec.Containers[0].Resources.Requests).To(Equal(v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("10m"),
+			v1.ResourceMemory: resource.MustParse("256Mi"),
+		}))
+		Expect(spec.Containers[1].Resources.Requests).To(Equal(limits))
+	})
+
+	DescribeTable("should default the image pull policy like the API server",
+		func(image string, expected v1.PullPolicy) {
+			Expect(defaultPullPolicy(image)).To(Equal(expected))
+		},
+		Entry("no tag", "example.com/image", v1.PullAlways),
+		Entry("registry port and no tag", "example.com:5000/image", v1.PullAlways),
+		Entry("latest tag", "example.com/image:latest", v1.PullAlways),
+		Entry("other tag", "example.com/image:v1", v1.PullIfNotPresent),
+		Entry("digest", "example.com/image@sha256:0123", v1.PullIfNotPresent),
+	)
+})
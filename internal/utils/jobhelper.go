@@ -6,7 +6,9 @@ import (
 	"fmt"
 
 	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/kubernetes-sigs/kernel-module-management/internal/constants"
@@ -24,7 +26,43 @@ const (
 	StatusFailed     = "failed"
 )
 
-var ErrNoMatchingJob = errors.New("no matching job")
+var (
+	ErrNoMatchingJob = errors.New("no matching job")
+	ErrJobFailed     = errors.New("job failed")
+)
+
+// JobFailedError is returned when a build or sign Job failed.
+// It matches ErrJobFailed with errors.Is.
+type JobFailedError struct {
+	JobName string
+	// Reason is the reason of the Failed condition of the Job, if it has one.
+	Reason string
+	// FailureTime is when the Job failed, or when the failure was noticed if the Job has no Failed condition.
+	FailureTime metav1.Time
+}
+
+// newJobFailedError returns a JobFailedError describing job.
+func newJobFailedError(job *batchv1.Job) *JobFailedError {
+	jfe := &JobFailedError{JobName: job.Name, FailureTime: metav1.Now()}
+
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == v1.ConditionTrue {
+			jfe.Reason = c.Reason
+			jfe.FailureTime = c.LastTransitionTime
+			break
+		}
+	}
+
+	return jfe
+}
+
+func (e *JobFailedError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrJobFailed, e.JobName)
+}
+
+func (e *JobFailedError) Is(target error) bool {
+	return target == ErrJobFailed
+}
 
 type Result struct {
 	Requeue bool
@@ -44,12 +82,17 @@ type JobHelper interface {
 }
 
 type jobHelper struct {
-	client client.Client
+	client    client.Client
+	queueName string
 }
 
-func NewJobHelper(client client.Client) JobHelper {
+// NewJobHelper returns a JobHelper.
+// If queueName is not empty, Jobs are created suspended with the constants.JobQueueNameLabel label, so that they only
+// run once admitted by Kueue through the LocalQueue of that name in their namespace.
+func NewJobHelper(client client.Client, queueName string) JobHelper {
 	return &jobHelper{
-		client: client,
+		client:    client,
+		queueName: queueName,
 	}
 }
 
@@ -109,6 +152,15 @@ func (jh *jobHelper) DeleteJob(ctx context.Context, job *batchv1.Job) error {
 }
 
 func (jh *jobHelper) CreateJob(ctx context.Context, jobTemplate *batchv1.Job) error {
+	if jh.queueName != "" {
+		if jobTemplate.Labels == nil {
+			jobTemplate.Labels = make(map[string]string)
+		}
+
+		jobTemplate.Labels[constants.JobQueueNameLabel] = jh.queueName
+		jobTemplate.Spec.Suspend = pointer.Bool(true)
+	}
+
 	err := jh.client.Create(ctx, jobTemplate)
 	if err != nil {
 		return err
@@ -125,7 +177,10 @@ func (jh *jobHelper) GetJobStatus(job *batchv1.Job) (Status, bool, error) {
 	case job.Status.Active == 1:
 		return StatusInProgress, true, nil
 	case job.Status.Failed == 1:
-		return StatusFailed, false, fmt.Errorf("job failed")
+		return StatusFailed, false, newJobFailedError(job)
+	case job.Spec.Suspend != nil && *job.Spec.Suspend:
+		// The Job is waiting to be admitted by a queueing system.
+		return StatusInProgress, true, nil
 	default:
 		return StatusFailed, false, fmt.Errorf("unknown status: %v", job.Status)
 	}
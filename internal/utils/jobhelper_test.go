@@ -4,12 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	"github.com/golang/mock/gomock"
@@ -33,7 +36,7 @@ var _ = Describe("JobLabels", func() {
 		mod := kmmv1beta1.Module{
 			ObjectMeta: metav1.ObjectMeta{Name: "moduleName"},
 		}
-		mgr := NewJobHelper(clnt)
+		mgr := NewJobHelper(clnt, "")
 		labels := mgr.JobLabels(mod.Name, "targetKernel", "jobType")
 
 		Expect(labels).To(HaveKeyWithValue(constants.ModuleNameLabel, "moduleName"))
@@ -52,7 +55,7 @@ var _ = Describe("GetModuleJobByKernel", func() {
 	BeforeEach(func() {
 		ctrl = gomock.NewController(GinkgoT())
 		clnt = client.NewMockClient(ctrl)
-		jh = NewJobHelper(clnt)
+		jh = NewJobHelper(clnt, "")
 	})
 
 	It("should return only one job", func() {
@@ -218,7 +221,7 @@ var _ = Describe("GetModuleJobs", func() {
 	BeforeEach(func() {
 		ctrl = gomock.NewController(GinkgoT())
 		clnt = client.NewMockClient(ctrl)
-		jh = NewJobHelper(clnt)
+		jh = NewJobHelper(clnt, "")
 	})
 
 	It("return all found jobs", func() {
@@ -327,7 +330,7 @@ var _ = Describe("DeleteJob", func() {
 	BeforeEach(func() {
 		ctrl = gomock.NewController(GinkgoT())
 		clnt = client.NewMockClient(ctrl)
-		jh = NewJobHelper(clnt)
+		jh = NewJobHelper(clnt, "")
 	})
 
 	It("good flow", func() {
@@ -371,7 +374,7 @@ var _ = Describe("CreateJob", func() {
 	BeforeEach(func() {
 		ctrl = gomock.NewController(GinkgoT())
 		clnt = client.NewMockClient(ctrl)
-		jh = NewJobHelper(clnt)
+		jh = NewJobHelper(clnt, "")
 	})
 
 	It("good flow", func() {
@@ -397,6 +400,26 @@ var _ = Describe("CreateJob", func() {
 		Expect(err).To(HaveOccurred())
 
 	})
+
+	It("should create the Job suspended in the queue if one is set", func() {
+		ctx := context.Background()
+
+		j := batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{constants.JobType: JobTypeBuild},
+			},
+		}
+
+		clnt.EXPECT().Create(ctx, &j).Return(nil)
+
+		err := NewJobHelper(clnt, "builds").CreateJob(ctx, &j)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(j.Labels).To(Equal(map[string]string{
+			constants.JobType:           JobTypeBuild,
+			constants.JobQueueNameLabel: "builds",
+		}))
+		Expect(j.Spec.Suspend).To(Equal(pointer.Bool(true)))
+	})
 })
 
 var _ = Describe("JobStatus", func() {
@@ -409,7 +432,7 @@ var _ = Describe("JobStatus", func() {
 	BeforeEach(func() {
 		ctrl = gomock.NewController(GinkgoT())
 		clnt = client.NewMockClient(ctrl)
-		jh = NewJobHelper(clnt)
+		jh = NewJobHelper(clnt, "")
 	})
 
 	DescribeTable("should return the correct status depending on the job status",
@@ -427,10 +450,39 @@ var _ = Describe("JobStatus", func() {
 		Entry("succeeded", &batchv1.Job{Status: batchv1.JobStatus{Succeeded: 1}}, StatusCompleted, false, false),
 		Entry("in progress", &batchv1.Job{Status: batchv1.JobStatus{Active: 1}}, StatusInProgress, true, false),
 		Entry("Failed", &batchv1.Job{Status: batchv1.JobStatus{Failed: 1}}, StatusFailed, false, true),
+		Entry("suspended", &batchv1.Job{Spec: batchv1.JobSpec{Suspend: pointer.Bool(true)}}, StatusInProgress, true, false),
 		Entry("unknown", &batchv1.Job{Status: batchv1.JobStatus{Failed: 2}}, StatusFailed, false, true),
 	)
 })
 
+var _ = Describe("newJobFailedError", func() {
+	It("should use the reason and time of the Failed condition", func() {
+		failureTime := metav1.NewTime(time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC))
+
+		job := batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "some-job"},
+			Status: batchv1.JobStatus{
+				Failed: 1,
+				Conditions: []batchv1.JobCondition{
+					{Type: batchv1.JobComplete, Status: v1.ConditionFalse},
+					{
+						Type:               batchv1.JobFailed,
+						Status:             v1.ConditionTrue,
+						Reason:             "BackoffLimitExceeded",
+						LastTransitionTime: failureTime,
+					},
+				},
+			},
+		}
+
+		Expect(newJobFailedError(&job)).To(Equal(&JobFailedError{
+			JobName:     "some-job",
+			Reason:      "BackoffLimitExceeded",
+			FailureTime: failureTime,
+		}))
+	})
+})
+
 var _ = Describe("IsJobChnaged", func() {
 	var (
 		ctrl *gomock.Controller
@@ -441,7 +493,7 @@ var _ = Describe("IsJobChnaged", func() {
 	BeforeEach(func() {
 		ctrl = gomock.NewController(GinkgoT())
 		clnt = client.NewMockClient(ctrl)
-		jh = NewJobHelper(clnt)
+		jh = NewJobHelper(clnt, "")
 	})
 
 	DescribeTable("should detect if a job has changed",
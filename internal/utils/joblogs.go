@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// logTailLines is the number of log lines that LogTail returns.
+	logTailLines = 20
+
+	// maxLogTailBytes caps the size of the log excerpt, so that it fits in Event and status messages.
+	maxLogTailBytes = 1024
+)
+
+//go:generate mockgen -source=joblogs.go -package=utils -destination=mock_joblogs.go
+
+// JobLogTailer returns the end of the logs of Jobs.
+type JobLogTailer interface {
+	LogTail(ctx context.Context, namespace, jobName string) (string, error)
+}
+
+type jobLogTailer struct {
+	client    client.Client
+	clientset kubernetes.Interface
+}
+
+// NewJobLogTailer returns a JobLogTailer reading logs through the API server.
+func NewJobLogTailer(client client.Client, clientset kubernetes.Interface) JobLogTailer {
+	return &jobLogTailer{
+		client:    client,
+		clientset: clientset,
+	}
+}
+
+// LogTail returns the last lines of the logs of the latest pod of a Job, capped to maxLogTailBytes.
+func (jlt *jobLogTailer) LogTail(ctx context.Context, namespace, jobName string) (string, error) {
+	pods := v1.PodList{}
+
+	// the Job controller sets the job-name label on the pods it creates
+	if err := jlt.client.List(ctx, &pods, client.InNamespace(namespace), client.MatchingLabels{"job-name": jobName}); err != nil {
+		return "", fmt.Errorf("could not list the pods of Job %s: %v", jobName, err)
+	}
+
+	if len(pods.Items) == 0 {
+		return "", fmt.Errorf("no pod found for Job %s", jobName)
+	}
+
+	latest := &pods.Items[0]
+
+	for i := 1; i < len(pods.Items); i++ {
+		if p := &pods.Items[i]; latest.CreationTimestamp.Before(&p.CreationTimestamp) {
+			latest = p
+		}
+	}
+
+	opts := v1.PodLogOptions{TailLines: pointer.Int64(logTailLines)}
+
+	logs, err := jlt.clientset.CoreV1().Pods(namespace).GetLogs(latest.Name, &opts).DoRaw(ctx)
+	if err != nil {
+		return "", fmt.Errorf("could not get the logs of pod %s: %v", latest.Name, err)
+	}
+
+	return capLogTail(string(logs)), nil
+}
+
+// capLogTail returns the end of logs, starting at a line boundary, that fits in maxLogTailBytes.
+func capLogTail(logs string) string {
+	logs = strings.TrimRight(logs, "\n")
+
+	if len(logs) <= maxLogTailBytes {
+		return logs
+	}
+
+	logs = logs[len(logs)-maxLogTailBytes:]
+
+	if i := strings.IndexByte(logs, '\n'); i >= 0 {
+		logs = logs[i+1:]
+	}
+
+	return logs
+}
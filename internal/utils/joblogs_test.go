@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/kubernetes-sigs/kernel-module-management/internal/client"
+)
+
+var _ = Describe("LogTail", func() {
+	const (
+		namespace = "some-namespace"
+		jobName   = "some-job"
+	)
+
+	var (
+		ctrl *gomock.Controller
+		clnt *client.MockClient
+		jlt  JobLogTailer
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		clnt = client.NewMockClient(ctrl)
+		jlt = NewJobLogTailer(clnt, fake.NewSimpleClientset())
+	})
+
+	ctx := context.Background()
+
+	It("should return an error if the pods cannot be listed", func() {
+		clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("some error"))
+
+		_, err := jlt.LogTail(ctx, namespace, jobName)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return an error if the Job has no pod", func() {
+		clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any(), gomock.Any())
+
+		_, err := jlt.LogTail(ctx, namespace, jobName)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return the logs of the pod", func() {
+		clnt.EXPECT().List(ctx, gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, list *v1.PodList, _ ...interface{}) error {
+				list.Items = []v1.Pod{
+					{ObjectMeta: metav1.ObjectMeta{Name: "old-pod", CreationTimestamp: metav1.Unix(1, 0)}},
+					{ObjectMeta: metav1.ObjectMeta{Name: "new-pod", CreationTimestamp: metav1.Unix(2, 0)}},
+				}
+				return nil
+			},
+		)
+
+		// the fake clientset returns the same logs for all pods
+		logs, err := jlt.LogTail(ctx, namespace, jobName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(logs).To(Equal("fake logs"))
+	})
+})
+
+var _ = Describe("capLogTail", func() {
+	It("should return short logs unchanged", func() {
+		Expect(capLogTail("line 1\nline 2\n")).To(Equal("line 1\nline 2"))
+	})
+
+	It("should keep the last complete lines of long logs", func() {
+		line := strings.Repeat("a", 99) + "\n"
+		logs := "first\n" + strings.Repeat(line, 11)
+
+		res := capLogTail(logs)
+		Expect(len(res)).To(BeNumerically("<=", maxLogTailBytes))
+		Expect(res).To(HavePrefix("a"))
+		Expect(res).To(HaveSuffix("a"))
+		Expect(strings.Count(res, "\n")).To(Equal(9))
+	})
+})
+
+var _ = Describe("JobFailedError", func() {
+	It("should match ErrJobFailed", func() {
+		err := &JobFailedError{JobName: "some-job"}
+
+		Expect(errors.Is(err, ErrJobFailed)).To(BeTrue())
+		Expect(err.Error()).To(Equal("job failed: some-job"))
+	})
+})
@@ -0,0 +1,23 @@
+package utils
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// StripNodeForCache is a cache transform function that drops the Node fields KMM never reads before the Node is
+// stored in the informer cache.
+// On large clusters, the list of container images and the managed fields make up most of a Node's size; not keeping
+// them reduces the memory used by the cache as well as the copies made each time Nodes are listed from it.
+// The controller-runtime cache does not support pagination (List ignores the continue token), so this is how the cost
+// of listing all Nodes matching a Module's selector is kept low.
+func StripNodeForCache(obj interface{}) (interface{}, error) {
+	node, ok := obj.(*v1.Node)
+	if !ok {
+		return obj, nil
+	}
+
+	node.ManagedFields = nil
+	node.Status.Images = nil
+
+	return node, nil
+}
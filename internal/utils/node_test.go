@@ -0,0 +1,54 @@
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+var _ = Describe("StripNodeForCache", func() {
+	It("should drop the images and managed fields but keep labels, taints and node info", func() {
+		node := &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:          "node",
+				Labels:        map[string]string{"key": "value"},
+				ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubelet"}},
+			},
+			Spec: v1.NodeSpec{
+				Taints: []v1.Taint{{Key: "taint", Effect: v1.TaintEffectNoSchedule}},
+			},
+			Status: v1.NodeStatus{
+				Images:   []v1.ContainerImage{{Names: []string{"some-image"}}},
+				NodeInfo: v1.NodeSystemInfo{KernelVersion: "1.2.3"},
+			},
+		}
+
+		res, err := StripNodeForCache(node)
+		Expect(err).NotTo(HaveOccurred())
+
+		expected := &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "node",
+				Labels: map[string]string{"key": "value"},
+			},
+			Spec: v1.NodeSpec{
+				Taints: []v1.Taint{{Key: "taint", Effect: v1.TaintEffectNoSchedule}},
+			},
+			Status: v1.NodeStatus{
+				NodeInfo: v1.NodeSystemInfo{KernelVersion: "1.2.3"},
+			},
+		}
+
+		Expect(res).To(Equal(expected))
+	})
+
+	It("should return objects that are not Nodes unchanged", func() {
+		obj := cache.DeletedFinalStateUnknown{Key: "node"}
+
+		res, err := StripNodeForCache(obj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res).To(Equal(obj))
+	})
+})
@@ -0,0 +1,384 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+)
+
+//go:generate mockgen -source=validation.go -package=validation -destination=mock_validation.go
+
+// The host directories mounted into module-loader containers.
+var hostMountPaths = []string{"/lib/modules", "/var/lib/firmware"}
+
+var (
+	moduleNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+	// The modprobe command of module-loader containers is run by a shell.
+	shellMetacharacters = " \t\n;&|$`<>()\\'\"*?[]{}~#!"
+)
+
+// Validator rejects the Module specs that would let a namespaced Module act on the host beyond loading its own
+// kernel modules.
+// Its errors aggregate field errors that point at the rejected field and suggest how to fix it.
+type Validator interface {
+	ValidateModule(mod *kmmv1beta1.Module) error
+}
+
+type validator struct {
+	allowedDevicePluginHostPaths []string
+	allowedNamespaces            []string
+	allowedImageRepositories     []string
+}
+
+// NewValidator returns a Validator.
+// If allowedDevicePluginHostPaths is not empty, device-plugin pods may only mount host paths that are equal to, or below
+// one of those.
+// If allowedNamespaces is not empty, Modules are only allowed in those namespaces.
+// If allowedImageRepositories is not empty, Modules may only reference images from those registries or repositories.
+func NewValidator(allowedDevicePluginHostPaths, allowedNamespaces, allowedImageRepositories []string) Validator {
+	return &validator{
+		allowedDevicePluginHostPaths: allowedDevicePluginHostPaths,
+		allowedNamespaces:            allowedNamespaces,
+		allowedImageRepositories:     allowedImageRepositories,
+	}
+}
+
+func (v *validator) ValidateModule(mod *kmmv1beta1.Module) error {
+	if !v.namespaceAllowed(mod.Namespace) {
+		// nothing else matters: the Module must not be deployed at all
+		detail := fmt.Sprintf(
+			"Modules are not allowed in namespace %s; create the Module in one of: %s",
+			mod.Namespace,
+			strings.Join(v.allowedNamespaces, ", "),
+		)
+
+		return field.ErrorList{field.Forbidden(field.NewPath("metadata", "namespace"), detail)}.ToAggregate()
+	}
+
+	specPath := field.NewPath("spec")
+
+	errs := ValidateModprobe(mod.Spec.ModuleLoader.Container.Modprobe, specPath.Child("moduleLoader", "container", "modprobe"))
+
+	for _, img := range ModuleImages(mod) {
+		if !v.imageAllowed(img.Image) {
+			detail := "the image is not in an allowed repository; use an image from one of: " +
+				strings.Join(v.allowedImageRepositories, ", ")
+
+			errs = append(errs, field.Invalid(img.Path, img.Image, detail))
+		}
+	}
+
+	errs = append(errs, v.validateBuilds(mod, specPath.Child("moduleLoader", "container"))...)
+
+	if dp := mod.Spec.DevicePlugin; dp != nil && len(v.allowedDevicePluginHostPaths) > 0 {
+		volumesPath := specPath.Child("devicePlugin", "volumes")
+
+		for i, vol := range dp.Volumes {
+			if vol.HostPath == nil {
+				continue
+			}
+
+			if !v.hostPathAllowed(vol.HostPath.Path) {
+				errs = append(errs, field.Invalid(volumesPath.Index(i).Child("hostPath", "path"), vol.HostPath.Path, v.hostPathDetail()))
+			}
+		}
+	}
+
+	return errs.ToAggregate()
+}
+
+func (v *validator) namespaceAllowed(namespace string) bool {
+	if len(v.allowedNamespaces) == 0 {
+		return true
+	}
+
+	for _, ns := range v.allowedNamespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ModuleImage is an image referenced by a Module.
+type ModuleImage struct {
+	// Path is the path of the field referencing Image in the Module.
+	Path  *field.Path
+	Image string
+}
+
+// ModuleImages returns all images referenced by mod, along with the field that references them.
+func ModuleImages(mod *kmmv1beta1.Module) []ModuleImage {
+	images := make([]ModuleImage, 0)
+
+	add := func(p *field.Path, image string) {
+		if image != "" {
+			images = append(images, ModuleImage{Path: p, Image: image})
+		}
+	}
+
+	container := mod.Spec.ModuleLoader.Container
+	containerPath := field.NewPath("spec", "moduleLoader", "container")
+
+	add(containerPath.Child("containerImage"), container.ContainerImage)
+
+	if container.Sign != nil {
+		add(containerPath.Child("sign", "unsignedImage"), container.Sign.UnsignedImage)
+	}
+
+	for i, km := range container.KernelMappings {
+		kmPath := containerPath.Child("kernelMappings").Index(i)
+
+		add(kmPath.Child("containerImage"), km.ContainerImage)
+
+		if km.Sign != nil {
+			add(kmPath.Child("sign", "unsignedImage"), km.Sign.UnsignedImage)
+		}
+	}
+
+	if mod.Spec.DevicePlugin != nil {
+		add(field.NewPath("spec", "devicePlugin", "container", "image"), mod.Spec.DevicePlugin.Container.Image)
+	}
+
+	for i, c := range mod.Spec.Companions {
+		add(field.NewPath("spec", "companions").Index(i).Child("container", "image"), c.Container.Image)
+	}
+
+	return images
+}
+
+// validateBuilds rejects the in-cluster builds of mod if images are restricted to some repositories.
+// The base images of a Dockerfile can come from anywhere, including build arguments, and its ConfigMap can be changed
+// after it was checked, so images built in the cluster are never considered to come from an allowed repository.
+func (v *validator) validateBuilds(mod *kmmv1beta1.Module, containerPath *field.Path) field.ErrorList {
+	if len(v.allowedImageRepositories) == 0 {
+		return nil
+	}
+
+	const detail = "in-cluster builds are not allowed when images are restricted to some repositories, as their " +
+		"Dockerfile may use any base image; build the image outside of the cluster and push it to an allowed repository"
+
+	errs := field.ErrorList{}
+
+	container := mod.Spec.ModuleLoader.Container
+
+	if container.Build != nil {
+		errs = append(errs, field.Forbidden(containerPath.Child("build"), detail))
+	}
+
+	for i, km := range container.KernelMappings {
+		if km.Build != nil {
+			errs = append(errs, field.Forbidden(containerPath.Child("kernelMappings").Index(i).Child("build"), detail))
+		}
+	}
+
+	return errs
+}
+
+// imageAllowed returns true if image belongs to one of the allowed registries or repositories.
+// Kernel variables such as ${KERNEL_FULL_VERSION} are left as is: they only match if they appear after the allowed
+// prefix.
+func (v *validator) imageAllowed(image string) bool {
+	if len(v.allowedImageRepositories) == 0 {
+		return true
+	}
+
+	image = normalizeImage(image)
+
+	for _, repo := range v.allowedImageRepositories {
+		repo = strings.TrimSuffix(repo, "/")
+
+		if image == repo || strings.HasPrefix(image, repo+"/") || strings.HasPrefix(image, repo+":") || strings.HasPrefix(image, repo+"@") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalizeImage makes the Docker Hub registry explicit in image, like container runtimes do.
+func normalizeImage(image string) string {
+	first, rest, found := strings.Cut(image, "/")
+
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		return image
+	}
+
+	if !found {
+		return "docker.io/library/" + image
+	}
+
+	return "docker.io/" + first + "/" + rest
+}
+
+func (v *validator) hostPathAllowed(p string) bool {
+	if !path.IsAbs(p) {
+		return false
+	}
+
+	p = path.Clean(p)
+
+	for _, allowed := range v.allowedDevicePluginHostPaths {
+		if isBelow(p, allowed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hostPathDetail describes the host paths that device-plugin pods may mount.
+func (v *validator) hostPathDetail() string {
+	return "must be an absolute path equal to, or below one of: " + strings.Join(v.allowedDevicePluginHostPaths, ", ")
+}
+
+// ValidateModprobe checks that the fields of spec, from which the modprobe commands are built, are not interpreted by
+// the shell and do not reference host files.
+func ValidateModprobe(spec kmmv1beta1.ModprobeSpec, fldPath *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if spec.ModuleName != "" && !moduleNameRegexp.MatchString(spec.ModuleName) {
+		detail := "must only contain letters, digits, '_' and '-'; use the name of the module without the .ko extension"
+		errs = append(errs, field.Invalid(fldPath.Child("moduleName"), spec.ModuleName, detail))
+	}
+
+	if err := validateImagePath(spec.DirName); err != nil {
+		errs = append(errs, field.Invalid(fldPath.Child("dirName"), spec.DirName, err.Error()))
+	}
+
+	if err := validateImagePath(spec.FirmwarePath); err != nil {
+		errs = append(errs, field.Invalid(fldPath.Child("firmwarePath"), spec.FirmwarePath, err.Error()))
+	}
+
+	for i, p := range spec.Parameters {
+		if err := validateArg(p); err != nil {
+			errs = append(errs, field.Invalid(fldPath.Child("parameters").Index(i), p, err.Error()))
+		}
+	}
+
+	argsFields := []struct {
+		name string
+		args *kmmv1beta1.ModprobeArgs
+	}{
+		{name: "args", args: spec.Args},
+		{name: "rawArgs", args: spec.RawArgs},
+	}
+
+	for _, f := range argsFields {
+		if f.args == nil {
+			continue
+		}
+
+		lists := []struct {
+			name string
+			args []string
+		}{
+			{name: "load", args: f.args.Load},
+			{name: "unload", args: f.args.Unload},
+		}
+
+		for _, l := range lists {
+			for i, a := range l.args {
+				if err := validateArg(a); err != nil {
+					errs = append(errs, field.Invalid(fldPath.Child(f.name, l.name).Index(i), a, err.Error()))
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateImagePath checks that p is a path of the module-loader image, and not one of the host's.
+func validateImagePath(p string) error {
+	if p == "" {
+		return nil
+	}
+
+	if !path.IsAbs(p) {
+		return errors.New("must be an absolute path of the module-loader image, such as /opt")
+	}
+
+	if strings.ContainsAny(p, shellMetacharacters) {
+		return errors.New("must not contain shell metacharacters, as the modprobe command is run by a shell")
+	}
+
+	if hasDotDot(p) {
+		return errors.New("must not contain ..")
+	}
+
+	if path.Clean(p) == "/" {
+		return errors.New("must not be /; use the directory of the module-loader image that holds the files, such as /opt")
+	}
+
+	for _, hostPath := range hostMountPaths {
+		if isBelow(p, hostPath) || isBelow(hostPath, p) {
+			return fmt.Errorf(
+				"overlaps with %s, which is mounted from the host; use another directory of the module-loader image, such as /opt",
+				hostPath,
+			)
+		}
+	}
+
+	return nil
+}
+
+// validateArg checks that a modprobe argument is not interpreted by the shell and does not reference host files.
+func validateArg(arg string) error {
+	if strings.ContainsAny(arg, shellMetacharacters) {
+		return errors.New("must not contain shell metacharacters, as the modprobe command is run by a shell")
+	}
+
+	candidates := []string{arg}
+
+	if _, value, ok := strings.Cut(arg, "="); ok {
+		candidates = append(candidates, value)
+	}
+
+	// short options such as -d/lib/modules
+	if len(arg) > 2 && arg[0] == '-' && arg[1] != '-' {
+		candidates = append(candidates, arg[2:])
+	}
+
+	for _, c := range candidates {
+		if hasDotDot(c) {
+			return errors.New("must not contain ..")
+		}
+
+		if !path.IsAbs(c) {
+			continue
+		}
+
+		if err := validateImagePath(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func hasDotDot(p string) bool {
+	for _, elem := range strings.Split(p, "/") {
+		if elem == ".." {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isBelow returns true if p is equal to, or below dir.
+func isBelow(p, dir string) bool {
+	p = path.Clean(p)
+	dir = path.Clean(dir)
+
+	return p == dir || dir == "/" || strings.HasPrefix(p, dir+"/")
+}
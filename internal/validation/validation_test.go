@@ -0,0 +1,260 @@
+package validation
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+
+	kmmv1beta1 "github.com/kubernetes-sigs/kernel-module-management/api/v1beta1"
+)
+
+var _ = Describe("ValidateModule", func() {
+	var v Validator
+
+	BeforeEach(func() {
+		v = NewValidator([]string{"/dev", "/sys/class"}, nil, nil)
+	})
+
+	modWithModprobe := func(spec kmmv1beta1.ModprobeSpec) *kmmv1beta1.Module {
+		return &kmmv1beta1.Module{
+			Spec: kmmv1beta1.ModuleSpec{
+				ModuleLoader: kmmv1beta1.ModuleLoaderSpec{
+					Container: kmmv1beta1.ModuleLoaderContainerSpec{Modprobe: spec},
+				},
+			},
+		}
+	}
+
+	It("should accept a regular Module", func() {
+		mod := modWithModprobe(kmmv1beta1.ModprobeSpec{
+			ModuleName:   "kmm_ci_a",
+			DirName:      "/opt",
+			FirmwarePath: "/firmware",
+			Parameters:   []string{"param=value", "other-param=1,2"},
+			Args:         &kmmv1beta1.ModprobeArgs{Load: []string{"-v", "-d", "/opt"}},
+		})
+		mod.Spec.DevicePlugin = &kmmv1beta1.DevicePluginSpec{
+			Volumes: []v1.Volume{
+				{
+					Name:         "dev",
+					VolumeSource: v1.VolumeSource{HostPath: &v1.HostPathVolumeSource{Path: "/dev/some-device"}},
+				},
+				{
+					Name:         "config",
+					VolumeSource: v1.VolumeSource{ConfigMap: &v1.ConfigMapVolumeSource{}},
+				},
+			},
+		}
+
+		Expect(v.ValidateModule(mod)).To(Succeed())
+	})
+
+	DescribeTable("should reject dangerous modprobe specs",
+		func(spec kmmv1beta1.ModprobeSpec) {
+			Expect(
+				v.ValidateModule(modWithModprobe(spec)),
+			).To(
+				HaveOccurred(),
+			)
+		},
+		Entry("shell in the module name", kmmv1beta1.ModprobeSpec{ModuleName: "mod; rm -rf /var/lib/firmware"}),
+		Entry("relative dirName", kmmv1beta1.ModprobeSpec{DirName: "opt"}),
+		Entry("path traversal in dirName", kmmv1beta1.ModprobeSpec{DirName: "/opt/../lib/modules"}),
+		Entry("host modules as dirName", kmmv1beta1.ModprobeSpec{DirName: "/"}),
+		Entry("parent of a host mount as firmwarePath", kmmv1beta1.ModprobeSpec{FirmwarePath: "/var/lib"}),
+		Entry("host firmware as firmwarePath", kmmv1beta1.ModprobeSpec{FirmwarePath: "/var/lib/firmware/some-fw"}),
+		Entry("shell in firmwarePath", kmmv1beta1.ModprobeSpec{FirmwarePath: "/firmware/$(id)"}),
+		Entry("shell in parameters", kmmv1beta1.ModprobeSpec{Parameters: []string{"a=b && reboot"}}),
+		Entry(
+			"host file in a long option",
+			kmmv1beta1.ModprobeSpec{Args: &kmmv1beta1.ModprobeArgs{Load: []string{"--config=/lib/modules/some.conf"}}},
+		),
+		Entry(
+			"host file in a short option",
+			kmmv1beta1.ModprobeSpec{RawArgs: &kmmv1beta1.ModprobeArgs{Load: []string{"-d/lib/modules"}}},
+		),
+		Entry(
+			"host file as an argument",
+			kmmv1beta1.ModprobeSpec{RawArgs: &kmmv1beta1.ModprobeArgs{Unload: []string{"-d", "/lib/modules"}}},
+		),
+		Entry(
+			"path traversal in an argument",
+			kmmv1beta1.ModprobeSpec{RawArgs: &kmmv1beta1.ModprobeArgs{Load: []string{"-d", "../../lib/modules"}}},
+		),
+	)
+
+	DescribeTable("should reject device-plugin host paths that are not allowed",
+		func(hostPath string) {
+			mod := modWithModprobe(kmmv1beta1.ModprobeSpec{})
+			mod.Spec.DevicePlugin = &kmmv1beta1.DevicePluginSpec{
+				Volumes: []v1.Volume{
+					{
+						Name:         "some-volume",
+						VolumeSource: v1.VolumeSource{HostPath: &v1.HostPathVolumeSource{Path: hostPath}},
+					},
+				},
+			}
+
+			Expect(v.ValidateModule(mod)).To(HaveOccurred())
+		},
+		Entry("root", "/"),
+		Entry("other directory", "/etc"),
+		Entry("prefix of an allowed directory", "/devices"),
+		Entry("path traversal", "/dev/../etc"),
+		Entry("relative path", "dev"),
+	)
+
+	It("should accept all device-plugin host paths if no directory is listed", func() {
+		v = NewValidator(nil, nil, nil)
+
+		mod := modWithModprobe(kmmv1beta1.ModprobeSpec{})
+		mod.Spec.DevicePlugin = &kmmv1beta1.DevicePluginSpec{
+			Volumes: []v1.Volume{
+				{
+					Name:         "kubelet",
+					VolumeSource: v1.VolumeSource{HostPath: &v1.HostPathVolumeSource{Path: "/var/lib/kubelet"}},
+				},
+			},
+		}
+
+		Expect(v.ValidateModule(mod)).To(Succeed())
+	})
+
+	It("should only accept Modules in the allowed namespaces", func() {
+		v = NewValidator(nil, []string{"kmm-modules", "other-modules"}, nil)
+
+		mod := modWithModprobe(kmmv1beta1.ModprobeSpec{})
+		mod.Namespace = "other-modules"
+
+		Expect(v.ValidateModule(mod)).To(Succeed())
+
+		mod.Namespace = "default"
+
+		Expect(v.ValidateModule(mod)).To(MatchError(ContainSubstring("namespace default")))
+	})
+
+	Describe("images", func() {
+		modWithImages := func(images ...string) *kmmv1beta1.Module {
+			mod := modWithModprobe(kmmv1beta1.ModprobeSpec{})
+
+			for _, img := range images {
+				mod.Spec.ModuleLoader.Container.KernelMappings = append(
+					mod.Spec.ModuleLoader.Container.KernelMappings,
+					kmmv1beta1.KernelMapping{ContainerImage: img},
+				)
+			}
+
+			return mod
+		}
+
+		BeforeEach(func() {
+			v = NewValidator(nil, nil, []string{"quay.io/kmm", "registry.example.com/", "docker.io/library"})
+		})
+
+		It("should accept all images if no repositories are listed", func() {
+			v = NewValidator(nil, nil, nil)
+
+			Expect(v.ValidateModule(modWithImages("some.registry/org/image:tag"))).To(Succeed())
+		})
+
+		DescribeTable("should accept images from the allowed repositories",
+			func(image string) {
+				Expect(v.ValidateModule(modWithImages(image))).To(Succeed())
+			},
+			Entry("repository", "quay.io/kmm/kmod:${KERNEL_FULL_VERSION}"),
+			Entry("repository with digest", "quay.io/kmm@sha256:0123456789abcdef"),
+			Entry("registry", "registry.example.com/org/kmod:latest"),
+			Entry("Docker Hub short name", "busybox:latest"),
+		)
+
+		DescribeTable("should reject images from other repositories",
+			func(image string) {
+				Expect(v.ValidateModule(modWithImages(image))).To(HaveOccurred())
+			},
+			Entry("other registry", "ghcr.io/kmm/kmod:latest"),
+			Entry("lookalike registry", "registry.example.com.evil.com/org/kmod:latest"),
+			Entry("repository sharing a prefix", "quay.io/kmm-fork/kmod:latest"),
+			Entry("other Docker Hub user", "someone/kmod:latest"),
+		)
+
+		It("should check all images of the Module", func() {
+			mod := modWithImages("quay.io/kmm/kmod:latest")
+			mod.Spec.ModuleLoader.Container.KernelMappings[0].Sign = &kmmv1beta1.Sign{UnsignedImage: "ghcr.io/unsigned"}
+			mod.Spec.ModuleLoader.Container.ContainerImage = "ghcr.io/default"
+			mod.Spec.DevicePlugin = &kmmv1beta1.DevicePluginSpec{
+				Container: kmmv1beta1.DevicePluginContainerSpec{Image: "ghcr.io/device-plugin"},
+			}
+			mod.Spec.Companions = []kmmv1beta1.CompanionSpec{
+				{Name: "licensing", Container: kmmv1beta1.CompanionContainerSpec{Image: "ghcr.io/licensing"}},
+			}
+
+			err := v.ValidateModule(mod)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("kernelMappings[0].sign.unsignedImage"))
+			Expect(err.Error()).To(ContainSubstring("moduleLoader.container.containerImage"))
+			Expect(err.Error()).To(ContainSubstring("devicePlugin.container.image"))
+			Expect(err.Error()).To(ContainSubstring("companions[0].container.image"))
+		})
+
+		It("should reject in-cluster builds", func() {
+			mod := modWithImages("quay.io/kmm/kmod:latest")
+			mod.Spec.ModuleLoader.Container.Build = &kmmv1beta1.Build{}
+			mod.Spec.ModuleLoader.Container.KernelMappings[0].Build = &kmmv1beta1.Build{}
+
+			err := v.ValidateModule(mod)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.moduleLoader.container.build"))
+			Expect(err.Error()).To(ContainSubstring("spec.moduleLoader.container.kernelMappings[0].build"))
+		})
+
+		It("should accept in-cluster builds if no repositories are listed", func() {
+			v = NewValidator(nil, nil, nil)
+
+			mod := modWithImages("some.registry/org/image:tag")
+			mod.Spec.ModuleLoader.Container.KernelMappings[0].Build = &kmmv1beta1.Build{}
+
+			Expect(v.ValidateModule(mod)).To(Succeed())
+		})
+	})
+
+	It("should point at the rejected field and suggest a fix", func() {
+		mod := modWithModprobe(kmmv1beta1.ModprobeSpec{DirName: "/lib/modules/kmm"})
+
+		Expect(
+			v.ValidateModule(mod),
+		).To(
+			MatchError(
+				`spec.moduleLoader.container.modprobe.dirName: Invalid value: "/lib/modules/kmm": overlaps with ` +
+					"/lib/modules, which is mounted from the host; use another directory of the module-loader image, such as /opt",
+			),
+		)
+
+		mod = modWithModprobe(kmmv1beta1.ModprobeSpec{})
+		mod.Spec.DevicePlugin = &kmmv1beta1.DevicePluginSpec{
+			Volumes: []v1.Volume{
+				{
+					Name:         "etc",
+					VolumeSource: v1.VolumeSource{HostPath: &v1.HostPathVolumeSource{Path: "/etc"}},
+				},
+			},
+		}
+
+		Expect(
+			v.ValidateModule(mod),
+		).To(
+			MatchError(
+				`spec.devicePlugin.volumes[0].hostPath.path: Invalid value: "/etc": must be an absolute path equal to, ` +
+					"or below one of: /dev, /sys/class",
+			),
+		)
+	})
+
+	It("should report all errors", func() {
+		mod := modWithModprobe(kmmv1beta1.ModprobeSpec{DirName: "/", FirmwarePath: "/lib"})
+
+		err := v.ValidateModule(mod)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("dirName"))
+		Expect(err.Error()).To(ContainSubstring("firmwarePath"))
+	})
+})